@@ -0,0 +1,40 @@
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+def test_get_admin_backup_without_admin_role_returns_403(auth_headers):
+    response = requests.get(f"{BASE_URL}/admin/backup", headers=auth_headers)
+
+    assert response.status_code == 403
+    assert response.json()["code"] == "forbidden"
+
+
+def test_post_admin_restore_without_admin_role_returns_403(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/admin/restore",
+        data=b"",
+        headers={**auth_headers, "Content-Type": "application/x-ndjson"},
+    )
+
+    assert response.status_code == 403
+
+
+def test_get_admin_backup_without_auth_returns_401():
+    response = requests.get(f"{BASE_URL}/admin/backup")
+
+    assert response.status_code == 401
+
+
+def test_post_admin_restore_without_auth_returns_401():
+    response = requests.post(f"{BASE_URL}/admin/restore", data=b"")
+
+    assert response.status_code == 401
@@ -0,0 +1,48 @@
+import uuid
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+def test_post_audits_without_librarian_role_returns_403(auth_headers):
+    response = requests.post(f"{BASE_URL}/audits", headers=auth_headers)
+
+    assert response.status_code == 403
+    assert response.json()["code"] == "forbidden"
+
+
+def test_get_audit_without_librarian_role_returns_403(auth_headers):
+    response = requests.get(f"{BASE_URL}/audits/{uuid.uuid4()}", headers=auth_headers)
+
+    assert response.status_code == 403
+
+
+def test_post_audits_scans_without_librarian_role_returns_403(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/audits/{uuid.uuid4()}/scans",
+        json={"code": "9784873119045"},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 403
+
+
+def test_post_audits_finish_without_librarian_role_returns_403(auth_headers):
+    response = requests.post(f"{BASE_URL}/audits/{uuid.uuid4()}/finish", headers=auth_headers)
+
+    assert response.status_code == 403
+
+
+def test_post_audits_without_auth_returns_401():
+    response = requests.post(f"{BASE_URL}/audits")
+
+    assert response.status_code == 401
@@ -0,0 +1,88 @@
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+from lib.random_string import random_string
+
+
+def create_borrowed_book(headers):
+    create_response = requests.post(
+        f"{BASE_URL}/books",
+        json={"title": random_string(), "authors": [random_string()]},
+        headers=headers,
+    )
+    assert create_response.status_code == 201
+    book_id = create_response.json()["id"]
+
+    borrow_response = requests.post(f"{BASE_URL}/books/{book_id}/borrow", headers=headers)
+    assert borrow_response.status_code == 200
+    return book_id
+
+
+def test_post_books_return_with_condition_records_condition():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    book_id = create_borrowed_book(headers)
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book_id}/return",
+        json={"condition": "damaged", "note": "表紙に水濡れ"},
+        headers=headers,
+    )
+    assert response.status_code == 200
+    assert response.json()["status"] == "available"
+
+    conditions = requests.get(f"{BASE_URL}/books/{book_id}/conditions", headers=headers)
+    assert conditions.status_code == 200
+    items = conditions.json()["items"]
+    assert len(items) == 1
+    assert items[0]["condition"] == "damaged"
+    assert items[0]["note"] == "表紙に水濡れ"
+
+    history = requests.get(f"{BASE_URL}/books/{book_id}/lendings", headers=headers)
+    assert history.status_code == 200
+    lending = history.json()["items"][0]
+    assert lending["condition"] == "damaged"
+    assert lending["conditionNote"] == "表紙に水濡れ"
+
+
+def test_post_books_return_with_invalid_condition_returns_400():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    book_id = create_borrowed_book(headers)
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book_id}/return",
+        json={"condition": "broken"},
+        headers=headers,
+    )
+
+    assert response.status_code == 400
+
+
+def test_post_books_report_lost_by_borrower_deletes_book():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    book_id = create_borrowed_book(headers)
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book_id}/report-lost",
+        json={"memo": "出張先で紛失"},
+        headers=headers,
+    )
+    assert response.status_code == 200
+    assert response.json()["bookId"] == book_id
+
+    get_response = requests.get(f"{BASE_URL}/books/{book_id}", headers=headers)
+    assert get_response.status_code == 404
+
+    history = requests.get(f"{BASE_URL}/books/{book_id}/lendings", headers=headers)
+    assert history.status_code == 200
+    assert history.json()["items"][0]["returnType"] == "lost"
+
+
+def test_post_books_report_lost_by_other_user_returns_403():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    book_id = create_borrowed_book(headers)
+    other_headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+
+    response = requests.post(f"{BASE_URL}/books/{book_id}/report-lost", headers=other_headers)
+
+    assert response.status_code == 403
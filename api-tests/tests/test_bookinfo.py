@@ -0,0 +1,73 @@
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+def test_get_bookinfo_with_valid_isbn_returns_200(auth_headers):
+    response = requests.get(
+        f"{BASE_URL}/bookinfo/9784873115658",
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["code"] == "9784873115658"
+    assert data["title"] == "リーダブルコード"
+    assert isinstance(data["authors"], list)
+    assert data["sources"]["title"] in ("holocron", "google_books", "openbd")
+    assert isinstance(data["exists"], bool)
+    assert isinstance(data["existingBookIds"], list)
+
+
+def test_get_bookinfo_does_not_register_book(auth_headers):
+    code = "9784873115658"
+    before = requests.get(
+        f"{BASE_URL}/books",
+        params={"code": code},
+        headers=auth_headers,
+    ).json()["total"]
+
+    response = requests.get(
+        f"{BASE_URL}/bookinfo/{code}",
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    after = requests.get(
+        f"{BASE_URL}/books",
+        params={"code": code},
+        headers=auth_headers,
+    ).json()["total"]
+    assert after == before
+
+
+def test_get_bookinfo_with_registered_code_reports_existing_book(auth_headers):
+    created = requests.post(
+        f"{BASE_URL}/books/code",
+        json={"code": "9784873115658"},
+        headers=auth_headers,
+    ).json()
+
+    response = requests.get(
+        f"{BASE_URL}/bookinfo/9784873115658",
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["exists"] is True
+    assert created["id"] in data["existingBookIds"]
+
+
+def test_get_bookinfo_without_auth_returns_401():
+    response = requests.get(f"{BASE_URL}/bookinfo/9784873115658")
+
+    assert response.status_code == 401
@@ -0,0 +1,107 @@
+import uuid
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+def test_post_books_import_with_csv_returns_report(auth_headers):
+    title = f"Import Book {uuid.uuid4()}"
+    body = f"title,authors\n{title},Author1;Author2\n,Author3\n"
+
+    response = requests.post(
+        f"{BASE_URL}/books/import",
+        data=body.encode("utf-8"),
+        headers={**auth_headers, "Content-Type": "text/csv"},
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["format"] == "csv"
+    assert data["total"] == 2
+    assert data["created"] == 1
+    assert data["invalid"] == 1
+    assert data["rows"][0]["status"] == "created"
+    assert data["rows"][1]["status"] == "invalid"
+
+    book = requests.get(
+        f"{BASE_URL}/books/{data['rows'][0]['bookId']}", headers=auth_headers
+    ).json()
+    assert book["title"] == title
+    assert book["authors"] == ["Author1", "Author2"]
+
+
+def test_post_books_import_with_dry_run_does_not_register(auth_headers):
+    title = f"Dry Run Book {uuid.uuid4()}"
+
+    response = requests.post(
+        f"{BASE_URL}/books/import",
+        params={"dryRun": "true", "format": "csv"},
+        data=f"title\n{title}\n".encode("utf-8"),
+        headers={**auth_headers, "Content-Type": "text/csv"},
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["dryRun"] is True
+    assert data["rows"][0]["status"] == "created"
+    assert "bookId" not in data["rows"][0]
+
+    books = requests.get(
+        f"{BASE_URL}/books", params={"q": title}, headers=auth_headers
+    ).json()
+    assert books["total"] == 0
+
+
+def test_post_books_import_same_data_again_resumes(auth_headers):
+    body = f"title\nResume Book {uuid.uuid4()}\n".encode("utf-8")
+    headers = {**auth_headers, "Content-Type": "text/csv"}
+
+    first = requests.post(f"{BASE_URL}/books/import", data=body, headers=headers).json()
+    second = requests.post(f"{BASE_URL}/books/import", data=body, headers=headers).json()
+
+    assert second["importId"] == first["importId"]
+    assert second["rows"][0]["resumed"] is True
+    assert second["rows"][0]["bookId"] == first["rows"][0]["bookId"]
+
+
+def test_post_books_import_with_invalid_isbn_returns_invalid_row(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/books/import",
+        params={"format": "isbn"},
+        data=b"12345\n",
+        headers={**auth_headers, "Content-Type": "text/plain"},
+    )
+
+    assert response.status_code == 200
+    assert response.json()["rows"][0]["status"] == "invalid"
+
+
+def test_post_books_import_without_title_column_returns_400(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/books/import",
+        params={"format": "csv"},
+        data=b"name,memo\na,b\n",
+        headers={**auth_headers, "Content-Type": "text/csv"},
+    )
+
+    assert response.status_code == 400
+    assert response.json()["code"] == "invalid_request"
+
+
+def test_post_books_import_without_auth_returns_401():
+    response = requests.post(
+        f"{BASE_URL}/books/import",
+        data=b"9784873115658\n",
+        headers={"Content-Type": "text/plain"},
+    )
+
+    assert response.status_code == 401
@@ -0,0 +1,88 @@
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+from lib.random_string import random_string
+
+
+def create_book(headers):
+    response = requests.post(
+        f"{BASE_URL}/books",
+        json={"title": random_string(), "authors": [random_string()]},
+        headers=headers,
+    )
+    assert response.status_code == 201
+    return response.json()["id"]
+
+
+def issue_feed(headers):
+    response = requests.post(f"{BASE_URL}/users/me/calendar-feed", headers=headers)
+    assert response.status_code == 201
+    return response.json()
+
+
+def get_lending_id(headers, book_id):
+    response = requests.get(f"{BASE_URL}/users/me/lendings", params={"state": "open"}, headers=headers)
+    assert response.status_code == 200
+    return next(item["id"] for item in response.json()["items"] if item["bookId"] == book_id)
+
+
+def test_post_users_me_calendar_feed_returns_feed_path():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+
+    body = issue_feed(headers)
+
+    assert body["path"] == f"/feeds/{body['token']}/due-dates.ics"
+    assert "createdAt" in body
+
+
+def test_get_feed_returns_event_per_loan_without_authentication():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    book_id = create_book(headers)
+    assert requests.post(f"{BASE_URL}/books/{book_id}/borrow", headers=headers).status_code == 200
+    lending_id = get_lending_id(headers, book_id)
+    feed = issue_feed(headers)
+
+    response = requests.get(f"{BASE_URL}{feed['path']}")
+
+    assert response.status_code == 200
+    assert response.headers["Content-Type"].startswith("text/calendar")
+    body = response.text
+    assert body.startswith("BEGIN:VCALENDAR\r\n")
+    assert body.count("BEGIN:VEVENT") == 1
+    assert f"UID:lending-{lending_id}@holocron\r\n" in body
+    assert "SEQUENCE:0\r\n" in body
+    assert "BEGIN:VALARM" in body
+
+
+def test_get_feed_after_extension_updates_event():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    book_id = create_book(headers)
+    assert requests.post(f"{BASE_URL}/books/{book_id}/borrow", headers=headers).status_code == 200
+    feed = issue_feed(headers)
+    before = requests.get(f"{BASE_URL}{feed['path']}").text
+
+    assert requests.post(f"{BASE_URL}/books/{book_id}/borrow", json={"dueDays": 7}, headers=headers).status_code == 200
+    after = requests.get(f"{BASE_URL}{feed['path']}").text
+
+    uid = next(line for line in before.split("\r\n") if line.startswith("UID:"))
+    assert uid in after
+    assert "SEQUENCE:1\r\n" in after
+
+
+def test_get_feed_after_reissue_or_delete_returns_404():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    first = issue_feed(headers)
+    second = issue_feed(headers)
+
+    assert requests.get(f"{BASE_URL}{first['path']}").status_code == 404
+    assert requests.get(f"{BASE_URL}{second['path']}").status_code == 200
+
+    assert requests.delete(f"{BASE_URL}/users/me/calendar-feed", headers=headers).status_code == 204
+    assert requests.get(f"{BASE_URL}{second['path']}").status_code == 404
+
+
+def test_post_users_me_calendar_feed_without_token_returns_401():
+    response = requests.post(f"{BASE_URL}/users/me/calendar-feed")
+
+    assert response.status_code == 401
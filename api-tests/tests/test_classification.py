@@ -0,0 +1,171 @@
+import uuid
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+from lib.random_string import random_string
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+@pytest.fixture
+def book(auth_headers):
+    return requests.post(
+        f"{BASE_URL}/books",
+        json={"title": "分類する本", "authors": ["Author1"]},
+        headers=auth_headers,
+    ).json()
+
+
+@pytest.fixture
+def category(auth_headers):
+    return requests.post(
+        f"{BASE_URL}/categories",
+        json={"name": f"分類-{random_string(8)}", "code": "913"},
+        headers=auth_headers,
+    ).json()
+
+
+def test_post_categories_with_parent_returns_201(auth_headers, category):
+    response = requests.post(
+        f"{BASE_URL}/categories",
+        json={"name": "子分類", "parentId": category["id"]},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 201
+    assert response.json()["parentId"] == category["id"]
+
+
+def test_post_categories_with_same_name_returns_409(auth_headers, category):
+    response = requests.post(
+        f"{BASE_URL}/categories",
+        json={"name": category["name"]},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 409
+
+
+def test_post_categories_with_unknown_parent_returns_404(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/categories",
+        json={"name": "子分類", "parentId": str(uuid.uuid4())},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 404
+
+
+def test_post_categories_seed_twice_creates_categories_once(auth_headers):
+    first = requests.post(
+        f"{BASE_URL}/categories/seed", json={"scheme": "ndc"}, headers=auth_headers
+    )
+    second = requests.post(
+        f"{BASE_URL}/categories/seed", json={"scheme": "ndc"}, headers=auth_headers
+    )
+
+    assert first.status_code == 200
+    assert second.status_code == 200
+    assert second.json()["rootId"] == first.json()["rootId"]
+    assert second.json()["created"] == 0
+
+    tree = requests.get(f"{BASE_URL}/categories", headers=auth_headers).json()["categories"]
+    root = next(c for c in tree if c["id"] == first.json()["rootId"])
+    assert len(root["children"]) == 10
+
+
+def test_post_categories_seed_with_unknown_scheme_returns_400(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/categories/seed", json={"scheme": "lcc"}, headers=auth_headers
+    )
+
+    assert response.status_code == 400
+
+
+def test_put_books_tags_returns_tags_on_get_book(auth_headers, book):
+    response = requests.put(
+        f"{BASE_URL}/books/{book['id']}/tags",
+        json={"tags": [" 小説 ", "SF", "小説"]},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    assert response.json()["tags"] == ["小説", "SF"]
+    assert requests.get(
+        f"{BASE_URL}/books/{book['id']}", headers=auth_headers
+    ).json()["tags"] == [
+        "小説",
+        "SF",
+    ]
+
+
+def test_put_books_tags_with_too_many_tags_returns_400(auth_headers, book):
+    response = requests.put(
+        f"{BASE_URL}/books/{book['id']}/tags",
+        json={"tags": [f"tag{i}" for i in range(21)]},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 400
+
+
+def test_put_books_category_returns_category_and_filters_list(
+    auth_headers, book, category
+):
+    response = requests.put(
+        f"{BASE_URL}/books/{book['id']}/category",
+        json={"categoryId": category["id"]},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    assert response.json()["category"]["id"] == category["id"]
+
+    listed = requests.get(
+        f"{BASE_URL}/books",
+        params={"category": category["id"]},
+        headers=auth_headers,
+    )
+    assert [item["id"] for item in listed.json()["items"]] == [book["id"]]
+
+
+def test_put_books_category_with_unknown_category_returns_404(auth_headers, book):
+    response = requests.put(
+        f"{BASE_URL}/books/{book['id']}/category",
+        json={"categoryId": str(uuid.uuid4())},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 404
+
+
+def test_put_books_shelf_returns_shelf_and_filters_list(auth_headers, book):
+    shelf = f"書斎 {random_string(8)}"
+    response = requests.put(
+        f"{BASE_URL}/books/{book['id']}/shelf",
+        json={"shelfLocation": shelf},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    assert response.json()["shelfLocation"] == shelf
+
+    listed = requests.get(
+        f"{BASE_URL}/books", params={"shelf": shelf}, headers=auth_headers
+    )
+    assert [item["id"] for item in listed.json()["items"]] == [book["id"]]
+
+
+def test_put_books_tags_without_auth_returns_401(book):
+    response = requests.put(
+        f"{BASE_URL}/books/{book['id']}/tags", json={"tags": ["SF"]}
+    )
+
+    assert response.status_code == 401
@@ -0,0 +1,134 @@
+import base64
+import uuid
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+
+
+PNG_1X1 = base64.b64decode(
+    "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mP8z8DwHwAFBQIAX8jx0gAAAABJRU5ErkJggg=="
+)
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+@pytest.fixture(scope="module")
+def book_without_cover(auth_headers):
+    return requests.post(
+        f"{BASE_URL}/books",
+        json={"title": "表紙のない本", "authors": ["Author1"]},
+        headers=auth_headers,
+    ).json()
+
+
+def test_get_books_cover_without_thumbnail_returns_placeholder(book_without_cover):
+    response = requests.get(f"{BASE_URL}/books/{book_without_cover['id']}/cover")
+
+    assert response.status_code == 200
+    assert response.headers["Content-Type"] == "image/svg+xml"
+    assert "Cache-Control" in response.headers
+    assert "表紙のない本" in response.text
+
+
+def test_get_books_cover_with_size_returns_200(book_without_cover):
+    response = requests.get(
+        f"{BASE_URL}/books/{book_without_cover['id']}/cover",
+        params={"size": "small"},
+    )
+
+    assert response.status_code == 200
+
+
+def test_get_books_cover_with_matching_etag_returns_304(book_without_cover):
+    first = requests.get(f"{BASE_URL}/books/{book_without_cover['id']}/cover")
+
+    response = requests.get(
+        f"{BASE_URL}/books/{book_without_cover['id']}/cover",
+        headers={"If-None-Match": first.headers["ETag"]},
+    )
+
+    assert response.status_code == 304
+
+
+def test_get_books_cover_with_invalid_size_returns_400(book_without_cover):
+    response = requests.get(
+        f"{BASE_URL}/books/{book_without_cover['id']}/cover",
+        params={"size": "huge"},
+    )
+
+    assert response.status_code == 400
+
+
+def test_get_books_cover_with_unknown_book_returns_404():
+    response = requests.get(f"{BASE_URL}/books/{uuid.uuid4()}/cover")
+
+    assert response.status_code == 404
+
+
+def test_put_books_cover_with_png_returns_200(auth_headers):
+    book = requests.post(
+        f"{BASE_URL}/books",
+        json={"title": "アップロード", "authors": ["Author1"]},
+        headers=auth_headers,
+    ).json()
+
+    response = requests.put(
+        f"{BASE_URL}/books/{book['id']}/cover",
+        data=PNG_1X1,
+        headers={**auth_headers, "Content-Type": "image/png"},
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["contentType"] == "image/png"
+    assert data["width"] == 1
+    assert data["height"] == 1
+    cover = requests.get(f"{BASE_URL}/books/{book['id']}/cover")
+    assert cover.headers["Content-Type"] == "image/png"
+
+
+def test_put_books_cover_with_multipart_returns_200(auth_headers, book_without_cover):
+    response = requests.put(
+        f"{BASE_URL}/books/{book_without_cover['id']}/cover",
+        files={"file": ("cover.png", PNG_1X1, "image/png")},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+
+
+def test_put_books_cover_with_non_image_returns_415(auth_headers, book_without_cover):
+    response = requests.put(
+        f"{BASE_URL}/books/{book_without_cover['id']}/cover",
+        data=b"<html></html>",
+        headers={**auth_headers, "Content-Type": "image/png"},
+    )
+
+    assert response.status_code == 415
+
+
+def test_put_books_cover_with_unknown_book_returns_404(auth_headers):
+    response = requests.put(
+        f"{BASE_URL}/books/{uuid.uuid4()}/cover",
+        data=PNG_1X1,
+        headers={**auth_headers, "Content-Type": "image/png"},
+    )
+
+    assert response.status_code == 404
+
+
+def test_put_books_cover_without_auth_returns_401(book_without_cover):
+    response = requests.put(
+        f"{BASE_URL}/books/{book_without_cover['id']}/cover",
+        data=PNG_1X1,
+        headers={"Content-Type": "image/png"},
+    )
+
+    assert response.status_code == 401
@@ -0,0 +1,104 @@
+import csv
+import io
+import json
+import uuid
+import xml.etree.ElementTree as ET
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+@pytest.fixture(scope="module")
+def book(auth_headers):
+    return requests.post(
+        f"{BASE_URL}/books",
+        json={"title": f"Export Book {uuid.uuid4()}", "authors": ["Author1", "Author2"]},
+        headers=auth_headers,
+    ).json()
+
+
+def test_get_export_books_default_returns_csv(auth_headers, book):
+    response = requests.get(f"{BASE_URL}/export/books", headers=auth_headers)
+
+    assert response.status_code == 200
+    assert response.headers["Content-Type"].startswith("text/csv")
+    assert "attachment" in response.headers["Content-Disposition"]
+    rows = list(csv.DictReader(io.StringIO(response.content.decode("utf-8"))))
+    row = next(r for r in rows if r["id"] == book["id"])
+    assert row["title"] == book["title"]
+    assert row["authors"] == "Author1;Author2"
+    assert row["status"] == "available"
+
+
+def test_get_export_books_jsonl_includes_lending_status(auth_headers, book):
+    requests.post(f"{BASE_URL}/books/{book['id']}/borrow", json={}, headers=auth_headers)
+
+    response = requests.get(
+        f"{BASE_URL}/export/books", params={"format": "jsonl"}, headers=auth_headers
+    )
+
+    assert response.status_code == 200
+    items = [json.loads(line) for line in response.text.splitlines() if line]
+    item = next(i for i in items if i["id"] == book["id"])
+    assert item["status"] == "borrowed"
+    assert item["borrower"]["dueDate"] is not None
+
+    requests.post(f"{BASE_URL}/books/{book['id']}/return", headers=auth_headers)
+
+
+def test_get_export_books_marc_returns_iso2709(auth_headers, book):
+    response = requests.get(
+        f"{BASE_URL}/export/books", params={"format": "marc"}, headers=auth_headers
+    )
+
+    assert response.status_code == 200
+    assert response.headers["Content-Type"] == "application/marc"
+    data = response.content
+    first_length = int(data[0:5])
+    assert data[first_length - 1] == 0x1D
+    assert book["id"].encode() in data
+
+
+def test_get_export_books_marcxml_returns_collection(auth_headers, book):
+    response = requests.get(
+        f"{BASE_URL}/export/books", params={"format": "marcxml"}, headers=auth_headers
+    )
+
+    assert response.status_code == 200
+    root = ET.fromstring(response.content)
+    ns = {"marc": "http://www.loc.gov/MARC21/slim"}
+    ids = [cf.text for cf in root.findall("marc:record/marc:controlfield[@tag='001']", ns)]
+    assert book["id"] in ids
+
+
+def test_get_export_books_bibtex_returns_entries(auth_headers, book):
+    response = requests.get(
+        f"{BASE_URL}/export/books", params={"format": "bibtex"}, headers=auth_headers
+    )
+
+    assert response.status_code == 200
+    assert f"@book{{holocron:{book['id']}," in response.text
+    assert "author = {Author1 and Author2}" in response.text
+
+
+def test_get_export_books_with_invalid_format_returns_400(auth_headers):
+    response = requests.get(
+        f"{BASE_URL}/export/books", params={"format": "xlsx"}, headers=auth_headers
+    )
+
+    assert response.status_code == 400
+
+
+def test_get_export_books_without_auth_returns_401():
+    response = requests.get(f"{BASE_URL}/export/books")
+
+    assert response.status_code == 401
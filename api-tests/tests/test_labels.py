@@ -0,0 +1,143 @@
+import re
+import uuid
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+from lib.random_string import random_string
+
+ACCESSION_NUMBER_PATTERN = re.compile(r"^HC-\d{6,}$")
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+def create_book(auth_headers, **fields):
+    response = requests.post(
+        f"{BASE_URL}/books",
+        json={"title": random_string(), "authors": [random_string()], **fields},
+        headers=auth_headers,
+    )
+    assert response.status_code == 201
+    return response.json()
+
+
+def test_post_books_without_code_assigns_accession_number(auth_headers):
+    book = create_book(auth_headers)
+
+    assert ACCESSION_NUMBER_PATTERN.match(book["accessionNumber"])
+
+    response = requests.get(f"{BASE_URL}/books/{book['id']}", headers=auth_headers)
+    assert response.json()["accessionNumber"] == book["accessionNumber"]
+
+
+def test_post_books_accession_number_with_numbered_book_returns_200(auth_headers):
+    book = create_book(auth_headers)
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book['id']}/accession-number", headers=auth_headers
+    )
+
+    assert response.status_code == 200
+    assert response.json()["accessionNumber"] == book["accessionNumber"]
+
+
+def test_post_books_accession_number_with_code_book_returns_201(auth_headers):
+    book = create_book(auth_headers, code=random_string())
+    assert "accessionNumber" not in book
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book['id']}/accession-number", headers=auth_headers
+    )
+
+    assert response.status_code == 201
+    assert ACCESSION_NUMBER_PATTERN.match(response.json()["accessionNumber"])
+
+
+def test_post_books_accession_number_with_nonexistent_book_returns_404(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/books/{uuid.uuid4()}/accession-number", headers=auth_headers
+    )
+
+    assert response.status_code == 404
+
+
+def test_get_books_label_returns_png(auth_headers):
+    book = create_book(auth_headers)
+
+    response = requests.get(f"{BASE_URL}/books/{book['id']}/label", headers=auth_headers)
+
+    assert response.status_code == 200
+    assert response.headers["Content-Type"] == "image/png"
+    assert response.content.startswith(b"\x89PNG")
+
+
+def test_get_books_label_with_svg_qr_returns_svg(auth_headers):
+    book = create_book(auth_headers)
+
+    response = requests.get(
+        f"{BASE_URL}/books/{book['id']}/label",
+        params={"format": "svg", "symbology": "qr"},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    assert response.headers["Content-Type"] == "image/svg+xml"
+    assert book["accessionNumber"] in response.text
+
+
+def test_get_books_label_with_invalid_format_returns_400(auth_headers):
+    book = create_book(auth_headers)
+
+    response = requests.get(
+        f"{BASE_URL}/books/{book['id']}/label",
+        params={"format": "gif"},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 400
+
+
+def test_get_books_labels_returns_pdf(auth_headers):
+    first = create_book(auth_headers)
+    second = create_book(auth_headers)
+
+    response = requests.get(
+        f"{BASE_URL}/books/labels",
+        params={"bookIds": f"{first['id']},{second['id']}", "stock": "a4-65", "skip": 3},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    assert response.headers["Content-Type"] == "application/pdf"
+    assert response.content.startswith(b"%PDF")
+
+
+def test_get_books_labels_with_nonexistent_book_returns_404(auth_headers):
+    response = requests.get(
+        f"{BASE_URL}/books/labels",
+        params={"bookIds": str(uuid.uuid4())},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 404
+
+
+def test_borrow_and_return_by_accession_number(auth_headers):
+    book = create_book(auth_headers)
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book['accessionNumber']}/borrow", headers=auth_headers
+    )
+    assert response.status_code == 200
+    assert response.json()["bookId"] == book["id"]
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book['accessionNumber'].lower()}/return", headers=auth_headers
+    )
+    assert response.status_code == 200
@@ -0,0 +1,112 @@
+import uuid
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+from lib.random_string import random_string
+
+
+@pytest.fixture
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+def create_book(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/books",
+        json={"title": random_string(), "authors": [random_string()]},
+        headers=auth_headers,
+    )
+    assert response.status_code == 201
+    return response.json()
+
+
+def test_post_lending_borrow_and_return_batch(auth_headers):
+    books = [create_book(auth_headers) for _ in range(3)]
+    refs = [books[0]["id"], books[1]["accessionNumber"], books[2]["id"]]
+
+    response = requests.post(
+        f"{BASE_URL}/lending/borrow",
+        json={"bookIds": refs, "dueDays": 14},
+        headers=auth_headers,
+    )
+    assert response.status_code == 200
+    data = response.json()
+    assert data["succeeded"] == 3
+    assert [item["ref"] for item in data["items"]] == refs
+    assert [item["bookId"] for item in data["items"]] == [book["id"] for book in books]
+
+    response = requests.post(
+        f"{BASE_URL}/lending/return",
+        json={"bookIds": refs},
+        headers=auth_headers,
+    )
+    assert response.status_code == 200
+    assert response.json()["succeeded"] == 3
+
+
+def test_post_lending_borrow_with_unknown_book_reports_item_failure(auth_headers):
+    book = create_book(auth_headers)
+
+    response = requests.post(
+        f"{BASE_URL}/lending/borrow",
+        json={"bookIds": [book["id"], str(uuid.uuid4())]},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["succeeded"] == 1
+    assert data["failed"] == 1
+    assert data["items"][1]["status"] == "failed"
+    assert data["items"][1]["error"]["code"] == "book_not_found"
+
+
+def test_post_lending_borrow_atomic_with_failure_borrows_nothing(auth_headers):
+    book = create_book(auth_headers)
+
+    response = requests.post(
+        f"{BASE_URL}/lending/borrow",
+        json={"bookIds": [book["id"], book["id"]], "atomic": True},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert [item["status"] for item in data["items"]] == ["rolled_back", "failed"]
+    assert data["items"][1]["error"]["code"] == "duplicate"
+
+    response = requests.get(f"{BASE_URL}/books/{book['id']}", headers=auth_headers)
+    assert response.json()["status"] == "available"
+
+
+def test_post_lending_return_with_book_not_borrowed_reports_item_failure(auth_headers):
+    book = create_book(auth_headers)
+
+    response = requests.post(
+        f"{BASE_URL}/lending/return",
+        json={"bookIds": [book["id"]]},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    assert response.json()["items"][0]["error"]["code"] == "not_borrowed"
+
+
+def test_post_lending_borrow_with_empty_batch_returns_400(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/lending/borrow",
+        json={"bookIds": []},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 400
+
+
+def test_post_lending_borrow_without_auth_returns_401():
+    response = requests.post(f"{BASE_URL}/lending/borrow", json={"bookIds": [str(uuid.uuid4())]})
+
+    assert response.status_code == 401
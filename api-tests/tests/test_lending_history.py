@@ -0,0 +1,105 @@
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+from lib.random_string import random_string
+
+
+def create_book(headers):
+    response = requests.post(
+        f"{BASE_URL}/books",
+        json={"title": random_string(), "authors": [random_string()]},
+        headers=headers,
+    )
+    assert response.status_code == 201
+    return response.json()["id"]
+
+
+def test_get_users_me_lendings_returns_loans_with_extensions():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    returned_book_id = create_book(headers)
+    open_book_id = create_book(headers)
+
+    assert requests.post(f"{BASE_URL}/books/{returned_book_id}/borrow", headers=headers).status_code == 200
+    extend_response = requests.post(
+        f"{BASE_URL}/books/{returned_book_id}/borrow",
+        json={"dueDays": 7},
+        headers=headers,
+    )
+    assert extend_response.status_code == 200
+    assert requests.post(f"{BASE_URL}/books/{returned_book_id}/return", headers=headers).status_code == 200
+    assert requests.post(f"{BASE_URL}/books/{open_book_id}/borrow", headers=headers).status_code == 200
+
+    response = requests.get(f"{BASE_URL}/users/me/lendings", headers=headers)
+
+    assert response.status_code == 200
+    body = response.json()
+    assert body["total"] == 2
+    items = {item["bookId"]: item for item in body["items"]}
+    returned = items[returned_book_id]
+    assert len(returned["extensions"]) == 1
+    assert returned["dueDate"] == extend_response.json()["dueDate"]
+    assert returned["returnType"] == "returned"
+    assert returned["late"] is False
+    assert "returnedAt" not in items[open_book_id]
+
+
+def test_get_users_me_lendings_with_state_filters_loans():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    returned_book_id = create_book(headers)
+    open_book_id = create_book(headers)
+    assert requests.post(f"{BASE_URL}/books/{returned_book_id}/borrow", headers=headers).status_code == 200
+    assert requests.post(f"{BASE_URL}/books/{returned_book_id}/return", headers=headers).status_code == 200
+    assert requests.post(f"{BASE_URL}/books/{open_book_id}/borrow", headers=headers).status_code == 200
+
+    open_response = requests.get(f"{BASE_URL}/users/me/lendings", params={"state": "open"}, headers=headers)
+    returned_response = requests.get(f"{BASE_URL}/users/me/lendings", params={"state": "returned"}, headers=headers)
+
+    assert open_response.status_code == 200
+    assert [item["bookId"] for item in open_response.json()["items"]] == [open_book_id]
+    assert returned_response.status_code == 200
+    assert [item["bookId"] for item in returned_response.json()["items"]] == [returned_book_id]
+
+
+def test_get_users_me_lendings_with_invalid_state_returns_400():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+
+    response = requests.get(f"{BASE_URL}/users/me/lendings", params={"state": "closed"}, headers=headers)
+
+    assert response.status_code == 400
+
+
+def test_get_users_me_lendings_without_auth_returns_401():
+    response = requests.get(f"{BASE_URL}/users/me/lendings")
+
+    assert response.status_code == 401
+
+
+def test_get_books_lendings_returns_loans_of_every_borrower():
+    first_headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    second_headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    book_id = create_book(first_headers)
+    assert requests.post(f"{BASE_URL}/books/{book_id}/borrow", headers=first_headers).status_code == 200
+    assert requests.post(f"{BASE_URL}/books/{book_id}/return", headers=first_headers).status_code == 200
+    assert requests.post(f"{BASE_URL}/books/{book_id}/borrow", headers=second_headers).status_code == 200
+
+    response = requests.get(f"{BASE_URL}/books/{book_id}/lendings", params={"limit": 1}, headers=first_headers)
+
+    assert response.status_code == 200
+    body = response.json()
+    assert body["total"] == 2
+    assert body["limit"] == 1
+    assert len(body["items"]) == 1
+    assert "returnedAt" not in body["items"][0]
+    assert "borrowerName" in body["items"][0]
+
+
+def test_get_books_lendings_with_unknown_book_returns_404():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+
+    response = requests.get(
+        f"{BASE_URL}/books/00000000-0000-0000-0000-000000000000/lendings",
+        headers=headers,
+    )
+
+    assert response.status_code == 404
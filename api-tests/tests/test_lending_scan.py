@@ -0,0 +1,99 @@
+import uuid
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+from lib.random_string import random_string
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+def create_book(auth_headers, **fields):
+    response = requests.post(
+        f"{BASE_URL}/books",
+        json={"title": random_string(), "authors": [random_string()], **fields},
+        headers=auth_headers,
+    )
+    assert response.status_code == 201
+    return response.json()
+
+
+def scan(headers, code):
+    return requests.post(f"{BASE_URL}/lending/scan", json={"code": code}, headers=headers)
+
+
+def test_post_lending_scan_borrows_then_returns(auth_headers):
+    code = random_string()
+    book = create_book(auth_headers, code=code)
+
+    response = scan(auth_headers, code)
+    assert response.status_code == 200
+    data = response.json()
+    assert data["action"] == "borrow"
+    assert data["bookId"] == book["id"]
+    assert "dueDate" in data
+
+    response = scan(auth_headers, code)
+    assert response.status_code == 200
+    assert response.json()["action"] == "return"
+    assert response.json()["lendingId"] == data["lendingId"]
+
+
+def test_post_lending_scan_with_accession_number_borrows_copy(auth_headers):
+    book = create_book(auth_headers)
+
+    response = scan(auth_headers, book["accessionNumber"])
+
+    assert response.status_code == 200
+    assert response.json()["bookId"] == book["id"]
+
+
+def test_post_lending_scan_with_several_copies_returns_409(auth_headers):
+    code = random_string()
+    title = random_string()
+    first = create_book(auth_headers, code=code, title=title)
+    second = create_book(auth_headers, code=code, title=title)
+
+    response = scan(auth_headers, code)
+
+    assert response.status_code == 409
+    data = response.json()
+    assert data["code"] == "ambiguous_scan"
+    assert data["reason"] == "multiple_copies"
+    assert {c["id"] for c in data["candidates"]} == {first["id"], second["id"]}
+
+
+def test_post_lending_scan_with_copy_lent_to_another_user_returns_409(auth_headers):
+    code = random_string()
+    create_book(auth_headers, code=code)
+    other_headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    assert scan(other_headers, code).status_code == 200
+
+    response = scan(auth_headers, code)
+
+    assert response.status_code == 409
+    assert response.json()["code"] == "book_already_borrowed"
+
+
+def test_post_lending_scan_with_unknown_code_returns_404(auth_headers):
+    response = scan(auth_headers, str(uuid.uuid4()))
+
+    assert response.status_code == 404
+
+
+def test_post_lending_scan_with_blank_code_returns_400(auth_headers):
+    response = scan(auth_headers, " ")
+
+    assert response.status_code == 400
+
+
+def test_post_lending_scan_without_auth_returns_401():
+    response = requests.post(f"{BASE_URL}/lending/scan", json={"code": "9784873119045"})
+
+    assert response.status_code == 401
@@ -0,0 +1,53 @@
+import uuid
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+def test_post_lend_without_librarian_role_returns_403(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/books/{uuid.uuid4()}/lend",
+        json={"borrowerId": str(uuid.uuid4())},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 403
+    assert response.json()["code"] == "forbidden"
+
+
+def test_post_force_return_without_librarian_role_returns_403(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/books/{uuid.uuid4()}/force-return",
+        json={"reason": "退職者の机に残っていた"},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 403
+    assert response.json()["code"] == "forbidden"
+
+
+def test_post_transfer_without_librarian_role_returns_403(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/books/{uuid.uuid4()}/transfer",
+        json={"borrowerId": str(uuid.uuid4())},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 403
+    assert response.json()["code"] == "forbidden"
+
+
+@pytest.mark.parametrize("action", ["lend", "force-return", "transfer"])
+def test_post_librarian_lending_without_auth_returns_401(action):
+    response = requests.post(f"{BASE_URL}/books/{uuid.uuid4()}/{action}", json={})
+
+    assert response.status_code == 401
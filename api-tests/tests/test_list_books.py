@@ -127,6 +127,64 @@ def test_get_books_with_search_query():
     assert unique_title in titles
 
 
+def test_get_books_with_search_query_matches_all_terms_and_publisher():
+    token = create_user_and_get_token()
+    client = AuthenticatedClient(base_url=BASE_URL, token=token)
+
+    title_word = random_string()
+    publisher = random_string()
+    result = post_books.sync_detailed(
+        client=client,
+        body=PostBooksBody(
+            title=f"{title_word}入門", authors=[random_string()], publisher=publisher
+        ),
+    )
+    assert result.status_code == 201
+    result = post_books.sync_detailed(
+        client=client,
+        body=PostBooksBody(title=f"{title_word}実践", authors=[random_string()]),
+    )
+    assert result.status_code == 201
+
+    response = requests.get(
+        f"{BASE_URL}/books",
+        params={"q": f"{title_word} {publisher}"},
+        headers={"Authorization": f"Bearer {token}"},
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["total"] == 1
+    item = data["items"][0]
+    assert item["title"] == f"{title_word}入門"
+    assert item["highlights"]["title"] == f"<mark>{title_word}</mark>入門"
+    assert item["highlights"]["publisher"] == f"<mark>{publisher}</mark>"
+    assert "authors" not in item["highlights"]
+
+
+def test_get_books_with_search_query_normalizes_width_case_and_kana():
+    token = create_user_and_get_token()
+
+    word = random_string()
+    result = post_books.sync_detailed(
+        client=AuthenticatedClient(base_url=BASE_URL, token=token),
+        body=PostBooksBody(title=f"{word}・ハリー", authors=[random_string()]),
+    )
+    assert result.status_code == 201
+
+    full_width = "".join(chr(ord(c) + 0xFEE0) for c in word.swapcase())
+    response = requests.get(
+        f"{BASE_URL}/books",
+        params={"q": f"{full_width}はりー"},
+        headers={"Authorization": f"Bearer {token}"},
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["total"] == 1
+    assert data["items"][0]["highlights"]["title"] == f"<mark>{word}・ハリー</mark>"
+
+
 def test_get_books_with_code_parameter():
     token = create_user_and_get_token()
 
@@ -228,3 +286,175 @@ def test_get_books_after_borrow_returns_borrower_info():
     assert UUID_PATTERN.match(borrowed_book["borrower"]["id"])
     assert isinstance(borrowed_book["borrower"]["name"], str)
     assert ISO8601_PATTERN.match(borrowed_book["borrower"]["borrowedAt"])
+
+
+def test_get_books_with_borrower_me_and_search_query_returns_my_borrowed_books():
+    token = create_user_and_get_token()
+    client = AuthenticatedClient(base_url=BASE_URL, token=token)
+
+    word = random_string()
+    borrowed = post_books.sync_detailed(
+        client=client,
+        body=PostBooksBody(title=f"{word} borrowed", authors=[random_string()]),
+    ).parsed
+    post_books.sync_detailed(
+        client=client,
+        body=PostBooksBody(title=f"{word} available", authors=[random_string()]),
+    )
+    borrow_response = requests.post(
+        f"{BASE_URL}/books/{borrowed.id}/borrow",
+        headers={"Authorization": f"Bearer {token}"},
+    )
+    assert borrow_response.status_code == 200
+
+    response = requests.get(
+        f"{BASE_URL}/books",
+        params={"q": word, "borrower": "me", "status": "borrowed"},
+        headers={"Authorization": f"Bearer {token}"},
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["total"] == 1
+    assert data["items"][0]["id"] == str(borrowed.id)
+
+
+def test_get_books_with_publisher_filter_and_title_sort():
+    token = create_user_and_get_token()
+    client = AuthenticatedClient(base_url=BASE_URL, token=token)
+
+    publisher = random_string()
+    for title in ["b", "a", "c"]:
+        result = post_books.sync_detailed(
+            client=client,
+            body=PostBooksBody(
+                title=title, authors=[random_string()], publisher=publisher
+            ),
+        )
+        assert result.status_code == 201
+
+    response = requests.get(
+        f"{BASE_URL}/books",
+        params={"publisher": publisher, "sort": "title", "order": "desc"},
+        headers={"Authorization": f"Bearer {token}"},
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["total"] == 3
+    assert [item["title"] for item in data["items"]] == ["c", "b", "a"]
+
+
+def test_get_books_with_invalid_filter_returns_400():
+    token = create_user_and_get_token()
+
+    for params in [
+        {"status": "lost"},
+        {"published_from": "2020/01"},
+        {"sort": "price"},
+        {"order": "up"},
+    ]:
+        response = requests.get(
+            f"{BASE_URL}/books",
+            params=params,
+            headers={"Authorization": f"Bearer {token}"},
+        )
+        assert response.status_code == 400, params
+
+
+def test_get_books_with_cursor_pages_through_filtered_books():
+    token = create_user_and_get_token()
+    client = AuthenticatedClient(base_url=BASE_URL, token=token)
+
+    publisher = random_string()
+    for title in ["a", "b", "c"]:
+        result = post_books.sync_detailed(
+            client=client,
+            body=PostBooksBody(
+                title=title, authors=[random_string()], publisher=publisher
+            ),
+        )
+        assert result.status_code == 201
+
+    params = {"publisher": publisher, "sort": "title", "limit": 2}
+    first = requests.get(
+        f"{BASE_URL}/books",
+        params={**params, "include_total": "false"},
+        headers={"Authorization": f"Bearer {token}"},
+    ).json()
+    assert [item["title"] for item in first["items"]] == ["a", "b"]
+    assert "total" not in first
+    assert "prevCursor" not in first
+
+    second = requests.get(
+        f"{BASE_URL}/books",
+        params={**params, "cursor": first["nextCursor"]},
+        headers={"Authorization": f"Bearer {token}"},
+    ).json()
+    assert [item["title"] for item in second["items"]] == ["c"]
+    assert second["total"] == 3
+    assert "nextCursor" not in second
+
+    back = requests.get(
+        f"{BASE_URL}/books",
+        params={**params, "cursor": second["prevCursor"]},
+        headers={"Authorization": f"Bearer {token}"},
+    ).json()
+    assert [item["title"] for item in back["items"]] == ["a", "b"]
+
+
+def test_get_books_with_tampered_cursor_returns_400():
+    token = create_user_and_get_token()
+
+    response = requests.get(
+        f"{BASE_URL}/books",
+        params={"cursor": "eyJwIjp7fX0.invalid"},
+        headers={"Authorization": f"Bearer {token}"},
+    )
+
+    assert response.status_code == 400
+
+
+def test_get_books_with_facets_counts_filtered_books():
+    token = create_user_and_get_token()
+    client = AuthenticatedClient(base_url=BASE_URL, token=token)
+
+    publisher = random_string()
+    author = random_string()
+    for authors in [[author], [author, random_string()]]:
+        result = post_books.sync_detailed(
+            client=client,
+            body=PostBooksBody(
+                title=random_string(), authors=authors, publisher=publisher
+            ),
+        )
+        assert result.status_code == 201
+
+    response = requests.get(
+        f"{BASE_URL}/books",
+        params={
+            "publisher": publisher,
+            "facets": "status,publisher,author",
+            "limit": 1,
+        },
+        headers={"Authorization": f"Bearer {token}"},
+    )
+
+    assert response.status_code == 200
+    facets = response.json()["facets"]
+    assert facets["status"] == [{"value": "available", "count": 2}]
+    assert facets["publisher"] == [{"value": publisher, "count": 2}]
+    assert facets["author"][0] == {"value": author, "count": 2}
+    assert len(facets["author"]) == 2
+
+
+def test_get_books_with_unknown_facet_returns_400():
+    token = create_user_and_get_token()
+
+    response = requests.get(
+        f"{BASE_URL}/books",
+        params={"facets": "shelf"},
+        headers={"Authorization": f"Bearer {token}"},
+    )
+
+    assert response.status_code == 400
@@ -0,0 +1,73 @@
+import uuid
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+def test_post_books_metadata_refresh_with_code_returns_200(auth_headers):
+    book = requests.post(
+        f"{BASE_URL}/books/code",
+        json={"code": "9784873115658"},
+        headers=auth_headers,
+    ).json()
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book['id']}/metadata-refresh",
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    data = response.json()
+    assert data["checked"] == 1
+    assert data["books"][0]["bookId"] == book["id"]
+    assert data["books"][0]["status"] in ("updated", "unchanged", "not_found")
+
+
+def test_post_books_metadata_refresh_without_code_returns_400(auth_headers):
+    book = requests.post(
+        f"{BASE_URL}/books",
+        json={"title": "Test Book", "authors": ["Author1"]},
+        headers=auth_headers,
+    ).json()
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book['id']}/metadata-refresh",
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 400
+    assert response.json()["code"] == "invalid_request"
+
+
+def test_post_books_metadata_refresh_with_unknown_book_returns_404(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/books/{uuid.uuid4()}/metadata-refresh",
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 404
+
+
+def test_post_metadata_refresh_without_librarian_role_returns_403(auth_headers):
+    response = requests.post(
+        f"{BASE_URL}/metadata-refresh",
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 403
+    assert response.json()["code"] == "forbidden"
+
+
+def test_post_metadata_refresh_without_auth_returns_401():
+    response = requests.post(f"{BASE_URL}/metadata-refresh")
+
+    assert response.status_code == 401
@@ -0,0 +1,160 @@
+import uuid
+
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+from lib.random_string import random_string
+
+
+def create_book(headers, title):
+    response = requests.post(
+        f"{BASE_URL}/books",
+        json={"title": title, "authors": [random_string()]},
+        headers=headers,
+    )
+    assert response.status_code == 201
+    return response.json()["id"]
+
+
+def borrow_book_due_tomorrow(headers, book_id):
+    response = requests.post(f"{BASE_URL}/books/{book_id}/borrow", json={"dueDays": 1}, headers=headers)
+    assert response.status_code == 200
+
+
+def list_notifications(headers, **params):
+    response = requests.get(f"{BASE_URL}/users/me/notifications", params=params, headers=headers)
+    assert response.status_code == 200
+    return response.json()
+
+
+def due_soon_notification(headers):
+    title = random_string()
+    book_id = create_book(headers, title)
+    borrow_book_due_tomorrow(headers, book_id)
+    body = list_notifications(headers)
+    return next(item for item in body["items"] if item.get("bookId") == book_id), title
+
+
+def test_get_notifications_reminds_of_loan_due_tomorrow():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+
+    item, title = due_soon_notification(headers)
+
+    assert item["type"] == "due_soon"
+    assert title in item["body"]
+    assert "readAt" not in item
+
+
+def test_get_notifications_starts_empty():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+
+    body = list_notifications(headers)
+
+    assert body == {"items": [], "total": 0, "unreadCount": 0, "limit": 20, "offset": 0}
+
+
+def test_post_notification_read_marks_it_read():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    item, _ = due_soon_notification(headers)
+
+    response = requests.post(f"{BASE_URL}/users/me/notifications/{item['id']}/read", headers=headers)
+
+    assert response.status_code == 204
+    body = list_notifications(headers)
+    assert body["unreadCount"] == 0
+    assert "readAt" in body["items"][0]
+    assert list_notifications(headers, unread="true")["items"] == []
+
+
+def test_post_notifications_read_all_marks_every_notification_read():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    due_soon_notification(headers)
+    due_soon_notification(headers)
+
+    response = requests.post(f"{BASE_URL}/users/me/notifications/read-all", headers=headers)
+
+    assert response.status_code == 204
+    body = list_notifications(headers)
+    assert body["total"] == 2
+    assert body["unreadCount"] == 0
+
+
+def test_delete_notification_removes_it_from_inbox():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    item, _ = due_soon_notification(headers)
+
+    response = requests.delete(f"{BASE_URL}/users/me/notifications/{item['id']}", headers=headers)
+
+    assert response.status_code == 204
+    assert list_notifications(headers)["total"] == 0
+
+
+def test_notification_of_other_user_returns_404():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    other_headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+    item, _ = due_soon_notification(headers)
+
+    read = requests.post(f"{BASE_URL}/users/me/notifications/{item['id']}/read", headers=other_headers)
+    delete = requests.delete(f"{BASE_URL}/users/me/notifications/{item['id']}", headers=other_headers)
+
+    assert read.status_code == 404
+    assert delete.status_code == 404
+    assert list_notifications(headers)["unreadCount"] == 1
+
+
+def test_delete_unknown_notification_returns_404():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+
+    response = requests.delete(f"{BASE_URL}/users/me/notifications/{uuid.uuid4()}", headers=headers)
+
+    assert response.status_code == 404
+    assert response.json()["code"] == "not_found"
+
+
+def test_get_notification_preferences_enables_every_type_by_default():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+
+    response = requests.get(f"{BASE_URL}/users/me/notification-preferences", headers=headers)
+
+    assert response.status_code == 200
+    assert response.json()["items"] == [
+        {"type": "due_soon", "enabled": True},
+        {"type": "librarian_action", "enabled": True},
+        {"type": "series_volume_added", "enabled": True},
+    ]
+
+
+def test_put_notification_preferences_stops_disabled_type():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+
+    response = requests.put(
+        f"{BASE_URL}/users/me/notification-preferences",
+        json={"preferences": [{"type": "due_soon", "enabled": False}]},
+        headers=headers,
+    )
+
+    assert response.status_code == 200
+    assert {"type": "due_soon", "enabled": False} in response.json()["items"]
+    book_id = create_book(headers, random_string())
+    borrow_book_due_tomorrow(headers, book_id)
+    assert list_notifications(headers)["total"] == 0
+
+
+def test_put_notification_preferences_with_unknown_type_returns_400():
+    headers = {"Authorization": f"Bearer {create_user_and_get_token()}"}
+
+    response = requests.put(
+        f"{BASE_URL}/users/me/notification-preferences",
+        json={"preferences": [{"type": "hold_ready", "enabled": False}]},
+        headers=headers,
+    )
+
+    assert response.status_code == 400
+    assert response.json()["code"] == "invalid_request"
+
+
+def test_get_notifications_without_auth_returns_401():
+    response = requests.get(f"{BASE_URL}/users/me/notifications")
+
+    assert response.status_code == 401
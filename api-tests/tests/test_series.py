@@ -0,0 +1,90 @@
+import uuid
+
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+from lib.random_string import random_string
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+@pytest.fixture
+def series_title():
+    return f"シリーズ-{random_string(8)}"
+
+
+def create_book(auth_headers, title):
+    return requests.post(
+        f"{BASE_URL}/books",
+        json={"title": title, "authors": ["Author1"]},
+        headers=auth_headers,
+    ).json()
+
+
+def test_post_books_with_volume_in_title_returns_series(auth_headers, series_title):
+    book = create_book(auth_headers, f"{series_title}（1）")
+
+    assert book["series"]["title"] == series_title
+    assert book["series"]["volume"] == 1
+
+
+def test_get_series_returns_missing_and_next_volume(auth_headers, series_title):
+    first = create_book(auth_headers, f"{series_title}（1）")
+    create_book(auth_headers, f"{series_title}（3）")
+    requests.post(f"{BASE_URL}/books/{first['id']}/borrow", headers=auth_headers)
+
+    response = requests.get(
+        f"{BASE_URL}/series/{first['series']['id']}", headers=auth_headers
+    )
+
+    assert response.status_code == 200
+    body = response.json()
+    assert [v["status"] for v in body["volumes"]] == ["borrowed", "missing", "available"]
+    assert body["missingVolumes"] == [2]
+    assert body["nextVolume"] == 3
+
+
+def test_post_book_with_series_sets_and_clears_series(auth_headers, series_title):
+    book = create_book(auth_headers, "シリーズに入れる本")
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book['id']}",
+        json={"series": {"title": series_title, "volume": 2}},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 200
+    assert response.json()["series"]["volume"] == 2
+    detail = requests.get(f"{BASE_URL}/books/{book['id']}", headers=auth_headers).json()
+    assert detail["series"]["title"] == series_title
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book['id']}", json={"series": None}, headers=auth_headers
+    )
+
+    assert response.status_code == 200
+    assert "series" not in response.json()
+
+
+def test_post_book_with_invalid_volume_returns_400(auth_headers, series_title):
+    book = create_book(auth_headers, "シリーズに入れる本")
+
+    response = requests.post(
+        f"{BASE_URL}/books/{book['id']}",
+        json={"series": {"title": series_title, "volume": 0}},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 400
+
+
+def test_get_series_with_unknown_id_returns_404(auth_headers):
+    response = requests.get(f"{BASE_URL}/series/{uuid.uuid4()}", headers=auth_headers)
+
+    assert response.status_code == 404
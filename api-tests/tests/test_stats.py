@@ -0,0 +1,44 @@
+import pytest
+import requests
+
+from lib.api_config import BASE_URL
+from lib.auth import create_user_and_get_token
+
+
+@pytest.fixture(scope="module")
+def auth_headers():
+    token = create_user_and_get_token()
+    return {"Authorization": f"Bearer {token}"}
+
+
+@pytest.mark.parametrize(
+    "path",
+    [
+        "/stats/summary",
+        "/stats/books/most-borrowed",
+        "/stats/borrowers/active",
+        "/stats/books/not-borrowed",
+        "/stats/timeseries",
+    ],
+)
+def test_get_stats_without_librarian_role_returns_403(auth_headers, path):
+    response = requests.get(f"{BASE_URL}{path}", headers=auth_headers)
+
+    assert response.status_code == 403
+    assert response.json()["code"] == "forbidden"
+
+
+def test_get_stats_summary_without_auth_returns_401():
+    response = requests.get(f"{BASE_URL}/stats/summary")
+
+    assert response.status_code == 401
+
+
+def test_get_stats_timeseries_with_invalid_date_returns_400(auth_headers):
+    response = requests.get(
+        f"{BASE_URL}/stats/timeseries",
+        params={"from": "2024-13-01"},
+        headers=auth_headers,
+    )
+
+    assert response.status_code == 400
@@ -0,0 +1,76 @@
+-- name: InsertAuditSession :exec
+INSERT INTO audit_sessions (audit_id, started_by, started_at)
+VALUES (?, ?, ?);
+
+-- name: CountOpenAuditSessions :one
+SELECT COUNT(*) AS cnt
+FROM audit_sessions
+WHERE finished_at IS NULL;
+
+-- name: GetAuditSession :one
+SELECT audit_id, started_by, started_at, finished_by, finished_at
+FROM audit_sessions
+WHERE audit_id = ?;
+
+-- name: FinishAuditSession :exec
+UPDATE audit_sessions
+SET finished_by = ?, finished_at = ?
+WHERE audit_id = ?;
+
+-- name: InsertExpectedAuditBooks :exec
+INSERT INTO audit_books (audit_id, book_id, code, title)
+SELECT ?, book_id, code, title
+FROM latest_books;
+
+-- name: ListAuditScanCandidates :many
+SELECT
+    lb.book_id,
+    lb.title,
+    ab.book_id IS NOT NULL as expected,
+    ab.seen_at IS NOT NULL as seen,
+    EXISTS (
+        SELECT 1 FROM open_lendings ol WHERE ol.book_id = lb.book_id
+    ) as borrowed
+FROM latest_books lb
+LEFT JOIN audit_books ab ON ab.audit_id = ? AND ab.book_id = lb.book_id
+WHERE lb.book_id = ? OR lb.code = ? OR lb.code = ?
+ORDER BY lb.book_id;
+
+-- name: MarkAuditBookSeen :exec
+UPDATE audit_books
+SET seen_at = ?
+WHERE audit_id = ?
+    AND book_id = ?
+    AND seen_at IS NULL;
+
+-- name: InsertAuditScan :exec
+INSERT INTO audit_scans (audit_id, scan_number, code, book_id, result, scanned_by, scanned_at)
+SELECT ?, COALESCE(MAX(scan_number), 0) + 1, ?, ?, ?, ?, ?
+FROM audit_scans
+WHERE audit_id = ?;
+
+-- name: ListAuditBooks :many
+SELECT
+    ab.book_id,
+    ab.code,
+    ab.title,
+    ab.seen_at,
+    ab.status,
+    EXISTS (
+        SELECT 1 FROM open_lendings ol WHERE ol.book_id = ab.book_id
+    ) as borrowed
+FROM audit_books ab
+WHERE ab.audit_id = ?
+ORDER BY ab.title, ab.book_id;
+
+-- name: SetAuditBookStatus :exec
+UPDATE audit_books
+SET status = ?
+WHERE audit_id = ?
+    AND book_id = ?;
+
+-- name: ListAuditScans :many
+SELECT scan_number, code, book_id, result, scanned_at
+FROM audit_scans
+WHERE audit_id = ?
+ORDER BY scan_number;
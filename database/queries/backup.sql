@@ -0,0 +1,136 @@
+-- name: ListUserEventsAfter :many
+SELECT event_id, user_id, event_type, name, occurred_at
+FROM user_events
+WHERE occurred_at > ?
+ORDER BY occurred_at, rowid
+LIMIT ?;
+
+-- name: ListUserEventsAt :many
+SELECT event_id, user_id, event_type, name, occurred_at
+FROM user_events
+WHERE occurred_at = ?
+ORDER BY rowid;
+
+-- name: ListCategoryEventsAfter :many
+SELECT event_id, category_id, event_type, parent_id, code, name, occurred_at
+FROM category_events
+WHERE occurred_at > ?
+ORDER BY occurred_at, rowid
+LIMIT ?;
+
+-- name: ListCategoryEventsAt :many
+SELECT event_id, category_id, event_type, parent_id, code, name, occurred_at
+FROM category_events
+WHERE occurred_at = ?
+ORDER BY rowid;
+
+-- name: ListSeriesEventsAfter :many
+SELECT event_id, series_id, event_type, title, occurred_at
+FROM series_events
+WHERE occurred_at > ?
+ORDER BY occurred_at, rowid
+LIMIT ?;
+
+-- name: ListSeriesEventsAt :many
+SELECT event_id, series_id, event_type, title, occurred_at
+FROM series_events
+WHERE occurred_at = ?
+ORDER BY rowid;
+
+-- name: ListBookEventsAfter :many
+SELECT event_id, book_id, event_type, code, title, authors, publisher, published_date, thumbnail_url, delete_reason, delete_memo, origin, cover_id, tags, category_id, shelf_location, series_id, volume_number, accession_number, condition, condition_note, lending_id, occurred_at
+FROM book_events
+WHERE occurred_at > ?
+ORDER BY occurred_at, CASE event_type WHEN 'created' THEN 0 WHEN 'updated' THEN 1 WHEN 'cover_changed' THEN 2 ELSE 3 END, rowid
+LIMIT ?;
+
+-- name: ListBookEventsAt :many
+SELECT event_id, book_id, event_type, code, title, authors, publisher, published_date, thumbnail_url, delete_reason, delete_memo, origin, cover_id, tags, category_id, shelf_location, series_id, volume_number, accession_number, condition, condition_note, lending_id, occurred_at
+FROM book_events
+WHERE occurred_at = ?
+ORDER BY CASE event_type WHEN 'created' THEN 0 WHEN 'updated' THEN 1 WHEN 'cover_changed' THEN 2 ELSE 3 END, rowid;
+
+-- name: ListLendingEventsAfter :many
+-- Events in the same second are listed in the order they were recorded, which
+-- keeps the return of a transferred loan ahead of the loan it opens.
+SELECT event_id, lending_id, book_id, borrower_id, event_type, due_date, actor_id, reason, occurred_at
+FROM lending_events
+WHERE occurred_at > ?
+ORDER BY occurred_at, rowid
+LIMIT ?;
+
+-- name: ListLendingEventsAt :many
+SELECT event_id, lending_id, book_id, borrower_id, event_type, due_date, actor_id, reason, occurred_at
+FROM lending_events
+WHERE occurred_at = ?
+ORDER BY rowid;
+
+-- name: CountAllEvents :one
+SELECT
+    (SELECT COUNT(*) FROM user_events)
+    + (SELECT COUNT(*) FROM category_events)
+    + (SELECT COUNT(*) FROM series_events)
+    + (SELECT COUNT(*) FROM book_events)
+    + (SELECT COUNT(*) FROM lending_events) AS cnt;
+
+-- name: CountOpenAuditSessions :one
+SELECT COUNT(*) AS cnt
+FROM audit_sessions
+WHERE finished_at IS NULL;
+
+-- name: DeleteAllUserEvents :exec
+DELETE FROM user_events;
+
+-- name: DeleteAllCategoryEvents :exec
+DELETE FROM category_events;
+
+-- name: DeleteAllSeriesEvents :exec
+DELETE FROM series_events;
+
+-- name: DeleteAllBookEvents :exec
+DELETE FROM book_events;
+
+-- name: DeleteAllLendingEvents :exec
+DELETE FROM lending_events;
+
+-- name: RestoreUserEvent :exec
+INSERT INTO user_events (event_id, user_id, event_type, name, occurred_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: RestoreCategoryEvent :exec
+INSERT INTO category_events (event_id, category_id, event_type, parent_id, code, name, occurred_at)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: RestoreSeriesEvent :exec
+INSERT INTO series_events (event_id, series_id, event_type, title, occurred_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: RestoreBookEvent :exec
+INSERT INTO book_events (event_id, book_id, event_type, code, title, authors, publisher, published_date, thumbnail_url, delete_reason, delete_memo, origin, cover_id, tags, category_id, shelf_location, series_id, volume_number, accession_number, condition, condition_note, lending_id, occurred_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: RestoreLendingEvent :exec
+INSERT INTO lending_events (event_id, lending_id, book_id, borrower_id, event_type, due_date, actor_id, reason, occurred_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListUploadedCoverSourcesAfter :many
+SELECT DISTINCT source
+FROM cover_images
+WHERE source LIKE 'upload:%' AND source > ?
+ORDER BY source
+LIMIT ?;
+
+-- name: ListCoverImagesBySource :many
+SELECT source, variant, content_type, data, etag, created_at
+FROM cover_images
+WHERE source = ?
+ORDER BY variant;
+
+-- name: RestoreCoverImage :exec
+INSERT INTO cover_images (source, variant, content_type, data, etag, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (source, variant) DO UPDATE SET
+    content_type = excluded.content_type,
+    data = excluded.data,
+    etag = excluded.etag,
+    created_at = excluded.created_at;
@@ -43,27 +43,153 @@ VALUES (?, ?, 'updated', ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetBookBorrowerInfo :one
 SELECT
-    le.lending_id,
-    le.borrower_id,
-    ue.name as borrower_name,
-    le.occurred_at as borrowed_at
-FROM lending_events le
-INNER JOIN user_events ue ON ue.user_id = le.borrower_id AND ue.event_type = 'created'
-WHERE le.book_id = ?
-    AND le.event_type = 'borrowed'
-    AND le.occurred_at > COALESCE(
-        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = le.book_id AND e2.event_type = 'deleted'),
+    lending_id,
+    borrower_id,
+    borrower_name,
+    borrowed_at
+FROM current_lendings
+WHERE book_id = ?;
+
+-- name: InsertBookDeleteEvent :exec
+INSERT INTO book_events (event_id, book_id, event_type, delete_reason, delete_memo, occurred_at)
+VALUES (?, ?, 'deleted', ?, ?, ?);
+
+-- name: InsertBookTagsChangedEvent :exec
+INSERT INTO book_events (event_id, book_id, event_type, tags, occurred_at)
+VALUES (?, ?, 'tags_changed', ?, ?);
+
+-- name: InsertBookCategoryChangedEvent :exec
+INSERT INTO book_events (event_id, book_id, event_type, category_id, occurred_at)
+VALUES (?, ?, 'category_changed', ?, ?);
+
+-- name: InsertBookShelfChangedEvent :exec
+INSERT INTO book_events (event_id, book_id, event_type, shelf_location, occurred_at)
+VALUES (?, ?, 'shelf_changed', ?, ?);
+
+-- name: InsertBookConditionEvent :exec
+INSERT INTO book_events (event_id, book_id, event_type, condition, condition_note, lending_id, occurred_at)
+VALUES (?, ?, 'condition_recorded', ?, ?, ?, ?);
+
+-- name: ListBookConditions :many
+SELECT e.condition, e.condition_note, e.occurred_at
+FROM book_events e
+WHERE e.book_id = sqlc.arg(book_id)
+    AND e.event_type = 'condition_recorded'
+    AND e.occurred_at > COALESCE(
+        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = sqlc.arg(book_id) AND e2.event_type = 'deleted'),
         '1970-01-01T00:00:00Z'
     )
-    AND NOT EXISTS (
-        SELECT 1
-        FROM lending_events returned
-        WHERE returned.lending_id = le.lending_id
-            AND returned.event_type = 'returned'
+ORDER BY e.occurred_at DESC, e.rowid DESC;
+
+-- name: GetBookClassification :one
+WITH deleted AS (
+    SELECT COALESCE(MAX(occurred_at), '1970-01-01T00:00:00Z') as deleted_at
+    FROM book_events
+    WHERE book_id = sqlc.arg(book_id) AND event_type = 'deleted'
+)
+SELECT
+    (SELECT e.tags
+     FROM book_events e, deleted d
+     WHERE e.book_id = sqlc.arg(book_id)
+       AND e.event_type = 'tags_changed'
+       AND e.occurred_at > d.deleted_at
+     ORDER BY e.occurred_at DESC, e.rowid DESC
+     LIMIT 1
+    ) as tags,
+    (SELECT e.category_id
+     FROM book_events e, deleted d
+     WHERE e.book_id = sqlc.arg(book_id)
+       AND e.event_type = 'category_changed'
+       AND e.occurred_at > d.deleted_at
+     ORDER BY e.occurred_at DESC, e.rowid DESC
+     LIMIT 1
+    ) as category_id,
+    (SELECT e.shelf_location
+     FROM book_events e, deleted d
+     WHERE e.book_id = sqlc.arg(book_id)
+       AND e.event_type = 'shelf_changed'
+       AND e.occurred_at > d.deleted_at
+     ORDER BY e.occurred_at DESC, e.rowid DESC
+     LIMIT 1
+    ) as shelf_location;
+
+-- name: GetBookSeries :one
+SELECT
+    latest.series_id,
+    latest.volume_number,
+    s.title
+FROM (
+    SELECT e.series_id, e.volume_number
+    FROM book_events e
+    WHERE e.book_id = sqlc.arg(book_id)
+        AND e.event_type = 'series_changed'
+        AND e.occurred_at > COALESCE(
+            (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = sqlc.arg(book_id) AND e2.event_type = 'deleted'),
+            '1970-01-01T00:00:00Z'
+        )
+    ORDER BY e.occurred_at DESC, e.rowid DESC
+    LIMIT 1
+) latest
+LEFT JOIN series_events s ON s.series_id = latest.series_id AND s.event_type = 'created';
+
+-- name: GetCategoryByCategoryId :one
+SELECT category_id, code, name
+FROM category_events
+WHERE category_id = ?
+    AND event_type = 'created'
+LIMIT 1;
+
+-- name: InsertBookAccessionAssignedEvent :one
+INSERT INTO book_events (event_id, book_id, event_type, accession_number, occurred_at)
+SELECT ?, ?, 'accession_assigned', 'HC-' || printf('%06d', COALESCE(MAX(CAST(SUBSTR(accession_number, 4) AS INTEGER)), 0) + 1), ?
+FROM book_events
+WHERE event_type = 'accession_assigned'
+RETURNING accession_number;
+
+-- name: GetBookAccessionNumber :one
+SELECT e.accession_number
+FROM book_events e
+WHERE e.book_id = sqlc.arg(book_id)
+    AND e.event_type = 'accession_assigned'
+    AND e.occurred_at > COALESCE(
+        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = sqlc.arg(book_id) AND e2.event_type = 'deleted'),
+        '1970-01-01T00:00:00Z'
     )
-ORDER BY le.occurred_at DESC
+ORDER BY e.occurred_at DESC, e.rowid DESC
 LIMIT 1;
 
--- name: InsertBookDeleteEvent :exec
-INSERT INTO book_events (event_id, book_id, event_type, delete_reason, delete_memo, occurred_at)
-VALUES (?, ?, 'deleted', ?, ?, ?);
+-- name: GetBookIdByAccessionNumber :one
+SELECT e1.book_id
+FROM book_events e1
+WHERE e1.accession_number = ?
+    AND e1.event_type = 'accession_assigned'
+    AND e1.occurred_at > COALESCE(
+        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = e1.book_id AND e2.event_type = 'deleted'),
+        '1970-01-01T00:00:00Z'
+    );
+
+-- name: ListBooksByCode :many
+WITH deleted_books AS (
+    SELECT book_id, MAX(occurred_at) as deleted_at
+    FROM book_events
+    WHERE event_type = 'deleted'
+    GROUP BY book_id
+),
+latest_books AS (
+    SELECT
+        e1.book_id,
+        e1.code,
+        e1.title,
+        ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+    FROM book_events e1
+    LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+    WHERE e1.event_type IN ('created', 'updated')
+        AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+)
+SELECT
+    book_id,
+    title
+FROM latest_books
+WHERE rn = 1
+    AND (code = sqlc.arg(code) OR code = sqlc.arg(normalized_code))
+ORDER BY book_id;
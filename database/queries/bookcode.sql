@@ -7,3 +7,58 @@ SELECT book_id, code, title, authors, publisher, published_date, thumbnail_url,
 FROM book_events
 WHERE code = ? AND event_type = 'created'
 LIMIT 1;
+
+-- name: ListBookIdsByCode :many
+SELECT DISTINCT e1.book_id
+FROM book_events e1
+WHERE e1.code = ?
+    AND e1.event_type IN ('created', 'updated')
+    AND e1.occurred_at > COALESCE(
+        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = e1.book_id AND e2.event_type = 'deleted'),
+        '1970-01-01T00:00:00Z'
+    )
+ORDER BY e1.book_id;
+
+-- name: ListBooksMissingMetadata :many
+SELECT
+    book_id,
+    code,
+    title,
+    authors,
+    publisher,
+    published_date,
+    thumbnail_url
+FROM latest_books
+WHERE code IS NOT NULL
+    AND code != ''
+    AND (
+        authors IS NULL OR authors IN ('', '[]', 'null')
+        OR publisher IS NULL OR publisher = ''
+        OR published_date IS NULL OR published_date = ''
+        OR thumbnail_url IS NULL OR thumbnail_url = ''
+    )
+ORDER BY book_id;
+
+-- name: ListBookSnapshots :many
+SELECT
+    e1.event_type,
+    e1.origin,
+    e1.code,
+    e1.title,
+    e1.authors,
+    e1.publisher,
+    e1.published_date,
+    e1.thumbnail_url,
+    e1.occurred_at
+FROM book_events e1
+WHERE e1.book_id = ?
+    AND e1.event_type IN ('created', 'updated')
+    AND e1.occurred_at > COALESCE(
+        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = e1.book_id AND e2.event_type = 'deleted'),
+        '1970-01-01T00:00:00Z'
+    )
+ORDER BY e1.occurred_at, e1.rowid;
+
+-- name: InsertBookRefreshEvent :exec
+INSERT INTO book_events (event_id, book_id, event_type, code, title, authors, publisher, published_date, thumbnail_url, origin, occurred_at)
+VALUES (?, ?, 'updated', ?, ?, ?, ?, ?, ?, 'metadata_refresh', ?);
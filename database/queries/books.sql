@@ -2,249 +2,319 @@
 INSERT INTO book_events (event_id, book_id, event_type, code, title, authors, publisher, published_date, thumbnail_url, occurred_at)
 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
+-- name: InsertBookAccessionAssignedEvent :one
+INSERT INTO book_events (event_id, book_id, event_type, accession_number, occurred_at)
+SELECT ?, ?, 'accession_assigned', 'HC-' || printf('%06d', COALESCE(MAX(CAST(SUBSTR(accession_number, 4) AS INTEGER)), 0) + 1), ?
+FROM book_events
+WHERE event_type = 'accession_assigned'
+RETURNING accession_number;
+
 -- name: ListBooks :many
-WITH deleted_books AS (
-    SELECT book_id, MAX(occurred_at) as deleted_at
-    FROM book_events
-    WHERE event_type = 'deleted'
-    GROUP BY book_id
-),
-latest_books AS (
+-- The book list, narrowed to the books matching book_search when it is given.
+-- CountBooks and ListBookFacets filter the books in the same way. matched_books
+-- is only read when book_search is given, since MATCH rejects NULL.
+-- overdue_lending_ids is a JSON array of the overdue loans without a due date,
+-- whose due date depends on the library calendar.
+WITH matched_books AS MATERIALIZED (
     SELECT
-        e1.book_id,
-        e1.code,
-        e1.title,
-        e1.authors,
-        e1.publisher,
-        e1.published_date,
-        e1.thumbnail_url,
-        (SELECT MIN(e_created.occurred_at)
-         FROM book_events e_created
-         WHERE e_created.book_id = e1.book_id
-           AND e_created.event_type = 'created'
-           AND e_created.occurred_at > COALESCE(
-               (SELECT MAX(e_del.occurred_at) FROM book_events e_del WHERE e_del.book_id = e1.book_id AND e_del.event_type = 'deleted'),
-               '1970-01-01T00:00:00Z'
-           )
-        ) as created_at,
-        e1.occurred_at as updated_at,
-        ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC) as rn
-    FROM book_events e1
-    LEFT JOIN deleted_books d ON e1.book_id = d.book_id
-    WHERE e1.event_type IN ('created', 'updated')
-        AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+        book_id,
+        bm25(book_search, 0.0, 10.0, 5.0, 2.0) as score
+    FROM book_search
+    WHERE book_search MATCH sqlc.narg(book_search)
 ),
-current_lendings AS (
+filtered_books AS (
     SELECT
-        le.book_id,
-        le.borrower_id,
-        ue.name as borrower_name,
-        le.occurred_at as borrowed_at,
-        ROW_NUMBER() OVER (PARTITION BY le.book_id ORDER BY le.occurred_at DESC) as rn
-    FROM lending_events le
-    INNER JOIN user_events ue ON ue.user_id = le.borrower_id AND ue.event_type = 'created'
-    WHERE le.event_type = 'borrowed'
-        AND le.occurred_at > COALESCE(
-            (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = le.book_id AND e2.event_type = 'deleted'),
-            '1970-01-01T00:00:00Z'
-        )
-        AND NOT EXISTS (
-            SELECT 1
-            FROM lending_events returned
-            WHERE returned.lending_id = le.lending_id
-                AND returned.event_type = 'returned'
-        )
+        b.book_id,
+        b.code,
+        b.title,
+        b.authors,
+        b.publisher,
+        b.published_date,
+        b.thumbnail_url,
+        b.created_at,
+        b.updated_at,
+        b.borrower_id,
+        b.borrower_name,
+        b.borrowed_at,
+        b.tags,
+        b.category_id,
+        b.category_name,
+        b.category_code,
+        b.shelf_location,
+        CASE sqlc.arg(sort_key)
+            WHEN 'title' THEN lower(b.title)
+            WHEN 'author' THEN lower(json_extract(b.authors, '$[0]'))
+            WHEN 'created_at' THEN b.created_at
+            WHEN 'published_date' THEN b.published_date
+            WHEN 'popularity' THEN b.borrow_count
+            WHEN 'relevance' THEN (SELECT m.score FROM matched_books m WHERE m.book_id = b.book_id)
+            ELSE b.updated_at
+        END as sort_value
+    FROM book_list_items b
+    WHERE (sqlc.narg(book_search) IS NULL OR b.book_id IN (SELECT book_id FROM matched_books))
+        AND (sqlc.narg(code) IS NULL OR b.code = sqlc.narg(code))
+        AND CASE sqlc.narg(status)
+            WHEN 'available' THEN b.lending_id IS NULL
+            WHEN 'borrowed' THEN b.lending_id IS NOT NULL
+            WHEN 'overdue' THEN (b.due_date < sqlc.arg(now) OR b.lending_id IN (SELECT value FROM json_each(sqlc.arg(overdue_lending_ids))))
+            ELSE 1
+        END
+        AND (sqlc.narg(borrower_id) IS NULL OR b.borrower_id = sqlc.narg(borrower_id))
+        AND (sqlc.narg(publisher) IS NULL OR b.publisher = sqlc.narg(publisher))
+        AND (sqlc.narg(author) IS NULL OR EXISTS (
+            SELECT 1 FROM json_each(b.authors) WHERE json_each.value = sqlc.narg(author)
+        ))
+        AND (sqlc.narg(published_from) IS NULL OR b.published_date >= sqlc.narg(published_from))
+        AND (sqlc.narg(published_to) IS NULL
+            OR substr(b.published_date, 1, length(sqlc.narg(published_to))) <= sqlc.narg(published_to))
+        AND (sqlc.narg(created_after) IS NULL OR b.created_at > sqlc.narg(created_after))
+        AND (sqlc.narg(tag) IS NULL OR EXISTS (
+            SELECT 1 FROM json_each(b.tags) WHERE json_each.value = sqlc.narg(tag)
+        ))
+        -- A category includes the books of all its descendants.
+        AND (sqlc.narg(category_id) IS NULL OR b.category_id IN (
+            WITH RECURSIVE category_scope(category_id) AS (
+                SELECT sqlc.narg(category_id)
+                UNION
+                SELECT child.category_id
+                FROM category_events child
+                INNER JOIN category_scope scope ON child.parent_id = scope.category_id
+                WHERE child.event_type = 'created'
+            )
+            SELECT category_id FROM category_scope
+        ))
+        AND (sqlc.narg(shelf_location) IS NULL OR b.shelf_location = sqlc.narg(shelf_location))
 )
 SELECT
-    lb.book_id,
-    lb.code,
-    lb.title,
-    lb.authors,
-    lb.publisher,
-    lb.published_date,
-    lb.thumbnail_url,
-    lb.created_at,
-    lb.updated_at,
-    cl.borrower_id,
-    cl.borrower_name,
-    cl.borrowed_at
-FROM latest_books lb
-LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id AND cl.rn = 1
-WHERE lb.rn = 1
-ORDER BY lb.updated_at DESC
+    book_id,
+    code,
+    title,
+    authors,
+    publisher,
+    published_date,
+    thumbnail_url,
+    created_at,
+    updated_at,
+    borrower_id,
+    borrower_name,
+    borrowed_at,
+    tags,
+    category_id,
+    category_name,
+    category_code,
+    shelf_location,
+    sort_value
+FROM filtered_books
+-- Keyset pagination: rows after the cursor in the scan order. Books without a sort
+-- value come last, ties are broken by updated_at DESC and book_id. A backward scan
+-- reverses every part of the order, and descending is already flipped for it.
+WHERE sqlc.narg(cursor_book_id) IS NULL
+    OR ((sort_value IS NULL) <> (sqlc.narg(cursor_value) IS NULL)
+        AND (sort_value IS NULL) <> sqlc.arg(backward))
+    OR (sort_value IS NOT NULL AND sort_value <> sqlc.narg(cursor_value)
+        AND (sort_value > sqlc.narg(cursor_value)) <> sqlc.arg(descending))
+    OR (sort_value IS sqlc.narg(cursor_value) AND updated_at <> sqlc.narg(cursor_updated_at)
+        AND (updated_at < sqlc.narg(cursor_updated_at)) <> sqlc.arg(backward))
+    OR (sort_value IS sqlc.narg(cursor_value) AND updated_at = sqlc.narg(cursor_updated_at)
+        AND book_id <> sqlc.narg(cursor_book_id) AND (book_id > sqlc.narg(cursor_book_id)) <> sqlc.arg(backward))
+ORDER BY
+    CASE WHEN sqlc.arg(backward) = 0 THEN sort_value IS NULL END ASC,
+    CASE WHEN sqlc.arg(backward) = 1 THEN sort_value IS NULL END DESC,
+    CASE WHEN sqlc.arg(descending) = 0 THEN sort_value END ASC,
+    CASE WHEN sqlc.arg(descending) = 1 THEN sort_value END DESC,
+    CASE WHEN sqlc.arg(backward) = 0 THEN updated_at END DESC,
+    CASE WHEN sqlc.arg(backward) = 1 THEN updated_at END ASC,
+    CASE WHEN sqlc.arg(backward) = 0 THEN book_id END ASC,
+    CASE WHEN sqlc.arg(backward) = 1 THEN book_id END DESC
 LIMIT ? OFFSET ?;
 
 -- name: CountBooks :one
-SELECT COUNT(DISTINCT e1.book_id) AS cnt
-FROM book_events e1
-WHERE e1.event_type IN ('created', 'updated')
-    AND e1.occurred_at > COALESCE(
-        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = e1.book_id AND e2.event_type = 'deleted'),
-        '1970-01-01T00:00:00Z'
-    );
-
--- name: FindBooksByCode :many
-WITH deleted_books AS (
-    SELECT book_id, MAX(occurred_at) as deleted_at
-    FROM book_events
-    WHERE event_type = 'deleted'
-    GROUP BY book_id
-),
-latest_books AS (
+WITH matched_books AS MATERIALIZED (
     SELECT
-        e1.book_id,
-        e1.code,
-        e1.title,
-        e1.authors,
-        e1.publisher,
-        e1.published_date,
-        e1.thumbnail_url,
-        (SELECT MIN(e_created.occurred_at)
-         FROM book_events e_created
-         WHERE e_created.book_id = e1.book_id
-           AND e_created.event_type = 'created'
-           AND e_created.occurred_at > COALESCE(
-               (SELECT MAX(e_del.occurred_at) FROM book_events e_del WHERE e_del.book_id = e1.book_id AND e_del.event_type = 'deleted'),
-               '1970-01-01T00:00:00Z'
-           )
-        ) as created_at,
-        e1.occurred_at as updated_at,
-        ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC) as rn
-    FROM book_events e1
-    LEFT JOIN deleted_books d ON e1.book_id = d.book_id
-    WHERE e1.event_type IN ('created', 'updated')
-        AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
-        AND e1.code = ?
+        book_id,
+        bm25(book_search, 0.0, 10.0, 5.0, 2.0) as score
+    FROM book_search
+    WHERE book_search MATCH sqlc.narg(book_search)
 ),
-current_lendings AS (
-    SELECT
-        le.book_id,
-        le.borrower_id,
-        ue.name as borrower_name,
-        le.occurred_at as borrowed_at,
-        ROW_NUMBER() OVER (PARTITION BY le.book_id ORDER BY le.occurred_at DESC) as rn
-    FROM lending_events le
-    INNER JOIN user_events ue ON ue.user_id = le.borrower_id AND ue.event_type = 'created'
-    WHERE le.event_type = 'borrowed'
-        AND le.occurred_at > COALESCE(
-            (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = le.book_id AND e2.event_type = 'deleted'),
-            '1970-01-01T00:00:00Z'
-        )
-        AND NOT EXISTS (
-            SELECT 1
-            FROM lending_events returned
-            WHERE returned.lending_id = le.lending_id
-                AND returned.event_type = 'returned'
-        )
+filtered_books AS (
+    SELECT b.book_id
+    FROM book_list_items b
+    WHERE (sqlc.narg(book_search) IS NULL OR b.book_id IN (SELECT book_id FROM matched_books))
+        AND (sqlc.narg(code) IS NULL OR b.code = sqlc.narg(code))
+        AND CASE sqlc.narg(status)
+            WHEN 'available' THEN b.lending_id IS NULL
+            WHEN 'borrowed' THEN b.lending_id IS NOT NULL
+            WHEN 'overdue' THEN (b.due_date < sqlc.arg(now) OR b.lending_id IN (SELECT value FROM json_each(sqlc.arg(overdue_lending_ids))))
+            ELSE 1
+        END
+        AND (sqlc.narg(borrower_id) IS NULL OR b.borrower_id = sqlc.narg(borrower_id))
+        AND (sqlc.narg(publisher) IS NULL OR b.publisher = sqlc.narg(publisher))
+        AND (sqlc.narg(author) IS NULL OR EXISTS (
+            SELECT 1 FROM json_each(b.authors) WHERE json_each.value = sqlc.narg(author)
+        ))
+        AND (sqlc.narg(published_from) IS NULL OR b.published_date >= sqlc.narg(published_from))
+        AND (sqlc.narg(published_to) IS NULL
+            OR substr(b.published_date, 1, length(sqlc.narg(published_to))) <= sqlc.narg(published_to))
+        AND (sqlc.narg(created_after) IS NULL OR b.created_at > sqlc.narg(created_after))
+        AND (sqlc.narg(tag) IS NULL OR EXISTS (
+            SELECT 1 FROM json_each(b.tags) WHERE json_each.value = sqlc.narg(tag)
+        ))
+        -- A category includes the books of all its descendants.
+        AND (sqlc.narg(category_id) IS NULL OR b.category_id IN (
+            WITH RECURSIVE category_scope(category_id) AS (
+                SELECT sqlc.narg(category_id)
+                UNION
+                SELECT child.category_id
+                FROM category_events child
+                INNER JOIN category_scope scope ON child.parent_id = scope.category_id
+                WHERE child.event_type = 'created'
+            )
+            SELECT category_id FROM category_scope
+        ))
+        AND (sqlc.narg(shelf_location) IS NULL OR b.shelf_location = sqlc.narg(shelf_location))
 )
-SELECT
-    lb.book_id,
-    lb.code,
-    lb.title,
-    lb.authors,
-    lb.publisher,
-    lb.published_date,
-    lb.thumbnail_url,
-    lb.created_at,
-    lb.updated_at,
-    cl.borrower_id,
-    cl.borrower_name,
-    cl.borrowed_at
-FROM latest_books lb
-LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id AND cl.rn = 1
-WHERE lb.rn = 1
-ORDER BY lb.updated_at DESC
-LIMIT ? OFFSET ?;
-
--- name: CountBooksByCode :one
-SELECT COUNT(DISTINCT e1.book_id) AS cnt
-FROM book_events e1
-WHERE e1.event_type IN ('created', 'updated')
-    AND e1.occurred_at > COALESCE(
-        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = e1.book_id AND e2.event_type = 'deleted'),
-        '1970-01-01T00:00:00Z'
-    )
-    AND e1.code = ?;
+SELECT COUNT(*) AS cnt
+FROM filtered_books;
 
--- name: SearchBooks :many
-WITH deleted_books AS (
-    SELECT book_id, MAX(occurred_at) as deleted_at
-    FROM book_events
-    WHERE event_type = 'deleted'
-    GROUP BY book_id
+-- name: ListBookFacets :many
+WITH matched_books AS MATERIALIZED (
+    SELECT
+        book_id,
+        bm25(book_search, 0.0, 10.0, 5.0, 2.0) as score
+    FROM book_search
+    WHERE book_search MATCH sqlc.narg(book_search)
 ),
-latest_books AS (
+filtered_books AS (
     SELECT
-        e1.book_id,
-        e1.code,
-        e1.title,
-        e1.authors,
-        e1.publisher,
-        e1.published_date,
-        e1.thumbnail_url,
-        (SELECT MIN(e_created.occurred_at)
-         FROM book_events e_created
-         WHERE e_created.book_id = e1.book_id
-           AND e_created.event_type = 'created'
-           AND e_created.occurred_at > COALESCE(
-               (SELECT MAX(e_del.occurred_at) FROM book_events e_del WHERE e_del.book_id = e1.book_id AND e_del.event_type = 'deleted'),
-               '1970-01-01T00:00:00Z'
-           )
-        ) as created_at,
-        e1.occurred_at as updated_at,
-        ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC) as rn
-    FROM book_events e1
-    LEFT JOIN deleted_books d ON e1.book_id = d.book_id
-    WHERE e1.event_type IN ('created', 'updated')
-        AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
-        AND (e1.title LIKE ? OR e1.authors LIKE ?)
+        b.book_id,
+        b.authors,
+        b.publisher,
+        b.published_date,
+        b.lending_id IS NOT NULL as borrowed,
+        (b.due_date < sqlc.arg(now) OR b.lending_id IN (SELECT value FROM json_each(sqlc.arg(overdue_lending_ids)))) as overdue,
+        b.tags
+    FROM book_list_items b
+    WHERE (sqlc.narg(book_search) IS NULL OR b.book_id IN (SELECT book_id FROM matched_books))
+        AND (sqlc.narg(code) IS NULL OR b.code = sqlc.narg(code))
+        AND CASE sqlc.narg(status)
+            WHEN 'available' THEN b.lending_id IS NULL
+            WHEN 'borrowed' THEN b.lending_id IS NOT NULL
+            WHEN 'overdue' THEN (b.due_date < sqlc.arg(now) OR b.lending_id IN (SELECT value FROM json_each(sqlc.arg(overdue_lending_ids))))
+            ELSE 1
+        END
+        AND (sqlc.narg(borrower_id) IS NULL OR b.borrower_id = sqlc.narg(borrower_id))
+        AND (sqlc.narg(publisher) IS NULL OR b.publisher = sqlc.narg(publisher))
+        AND (sqlc.narg(author) IS NULL OR EXISTS (
+            SELECT 1 FROM json_each(b.authors) WHERE json_each.value = sqlc.narg(author)
+        ))
+        AND (sqlc.narg(published_from) IS NULL OR b.published_date >= sqlc.narg(published_from))
+        AND (sqlc.narg(published_to) IS NULL
+            OR substr(b.published_date, 1, length(sqlc.narg(published_to))) <= sqlc.narg(published_to))
+        AND (sqlc.narg(created_after) IS NULL OR b.created_at > sqlc.narg(created_after))
+        AND (sqlc.narg(tag) IS NULL OR EXISTS (
+            SELECT 1 FROM json_each(b.tags) WHERE json_each.value = sqlc.narg(tag)
+        ))
+        -- A category includes the books of all its descendants.
+        AND (sqlc.narg(category_id) IS NULL OR b.category_id IN (
+            WITH RECURSIVE category_scope(category_id) AS (
+                SELECT sqlc.narg(category_id)
+                UNION
+                SELECT child.category_id
+                FROM category_events child
+                INNER JOIN category_scope scope ON child.parent_id = scope.category_id
+                WHERE child.event_type = 'created'
+            )
+            SELECT category_id FROM category_scope
+        ))
+        AND (sqlc.narg(shelf_location) IS NULL OR b.shelf_location = sqlc.narg(shelf_location))
+),
+facet_values AS (
+    SELECT 'status' as facet, CASE WHEN borrowed THEN 'borrowed' ELSE 'available' END as value
+    FROM filtered_books
+    WHERE sqlc.arg(include_status)
+    UNION ALL
+    -- Overdue books are counted as borrowed as well, like the status filter does.
+    SELECT 'status', 'overdue'
+    FROM filtered_books
+    WHERE sqlc.arg(include_status) AND overdue
+    UNION ALL
+    SELECT 'publisher', publisher
+    FROM filtered_books
+    WHERE sqlc.arg(include_publisher) AND publisher IS NOT NULL
+    UNION ALL
+    SELECT 'author', author
+    FROM (
+        SELECT DISTINCT fb.book_id, json_each.value as author
+        FROM filtered_books fb, json_each(fb.authors)
+        WHERE sqlc.arg(include_author)
+    )
+    UNION ALL
+    SELECT 'decade', substr(published_date, 1, 3) || '0'
+    FROM filtered_books
+    WHERE sqlc.arg(include_decade) AND published_date GLOB '[0-9][0-9][0-9][0-9]*'
+    UNION ALL
+    SELECT 'tag', json_each.value
+    FROM filtered_books fb, json_each(fb.tags)
+    WHERE sqlc.arg(include_tag)
 ),
-current_lendings AS (
+facet_counts AS (
     SELECT
-        le.book_id,
-        le.borrower_id,
-        ue.name as borrower_name,
-        le.occurred_at as borrowed_at,
-        ROW_NUMBER() OVER (PARTITION BY le.book_id ORDER BY le.occurred_at DESC) as rn
-    FROM lending_events le
-    INNER JOIN user_events ue ON ue.user_id = le.borrower_id AND ue.event_type = 'created'
-    WHERE le.event_type = 'borrowed'
-        AND le.occurred_at > COALESCE(
-            (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = le.book_id AND e2.event_type = 'deleted'),
-            '1970-01-01T00:00:00Z'
-        )
-        AND NOT EXISTS (
-            SELECT 1
-            FROM lending_events returned
-            WHERE returned.lending_id = le.lending_id
-                AND returned.event_type = 'returned'
-        )
+        facet,
+        value,
+        COUNT(*) as cnt,
+        ROW_NUMBER() OVER (PARTITION BY facet ORDER BY COUNT(*) DESC, value) as rn
+    FROM facet_values
+    GROUP BY facet, value
 )
-SELECT
-    lb.book_id,
-    lb.code,
-    lb.title,
-    lb.authors,
-    lb.publisher,
-    lb.published_date,
-    lb.thumbnail_url,
-    lb.created_at,
-    lb.updated_at,
-    cl.borrower_id,
-    cl.borrower_name,
-    cl.borrowed_at
-FROM latest_books lb
-LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id AND cl.rn = 1
-WHERE lb.rn = 1
-ORDER BY lb.updated_at DESC
-LIMIT ? OFFSET ?;
+SELECT facet, value, cnt
+FROM facet_counts
+WHERE rn <= sqlc.arg(value_limit)
+ORDER BY facet, rn;
+
+-- name: ListLoansWithoutDueDate :many
+SELECT lending_id, borrowed_at
+FROM current_lendings
+WHERE due_date IS NULL;
 
--- name: CountSearchBooks :one
-SELECT COUNT(DISTINCT e1.book_id) AS cnt
+-- name: GetBookSearchPosition :one
+SELECT CAST(COALESCE(
+    (SELECT last_event_rowid FROM book_search_state WHERE id = 1),
+    0
+) AS INTEGER) AS last_event_rowid;
+
+-- name: GetLatestBookEventRowid :one
+SELECT CAST(COALESCE(MAX(rowid), 0) AS INTEGER) AS last_event_rowid
+FROM book_events;
+
+-- name: ListBookIdsChangedBetween :many
+SELECT DISTINCT book_id
+FROM book_events
+WHERE rowid > sqlc.arg(after_rowid) AND rowid <= sqlc.arg(until_rowid)
+    AND event_type IN ('created', 'updated', 'deleted');
+
+-- name: GetSearchableBook :one
+SELECT e1.title, e1.authors, e1.publisher
 FROM book_events e1
-WHERE e1.event_type IN ('created', 'updated')
+WHERE e1.book_id = ?
+    AND e1.event_type IN ('created', 'updated')
     AND e1.occurred_at > COALESCE(
         (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = e1.book_id AND e2.event_type = 'deleted'),
         '1970-01-01T00:00:00Z'
     )
-    AND (e1.title LIKE ? OR e1.authors LIKE ?);
+ORDER BY e1.occurred_at DESC, e1.rowid DESC
+LIMIT 1;
+
+-- name: DeleteBookSearchEntry :exec
+DELETE FROM book_search WHERE book_id = ?;
+
+-- name: InsertBookSearchEntry :exec
+INSERT INTO book_search (book_id, title, authors, publisher)
+VALUES (?, ?, ?, ?);
+
+-- name: DeleteAllBookSearchEntries :exec
+DELETE FROM book_search;
+
+-- name: SetBookSearchPosition :exec
+INSERT INTO book_search_state (id, last_event_rowid)
+VALUES (1, ?)
+ON CONFLICT (id) DO UPDATE SET last_event_rowid = excluded.last_event_rowid;
@@ -0,0 +1,23 @@
+-- name: InsertBookEvent :exec
+INSERT INTO book_events (event_id, book_id, event_type, code, title, authors, publisher, published_date, thumbnail_url, occurred_at)
+VALUES (?, ?, 'created', ?, ?, ?, ?, ?, ?, ?);
+
+-- name: CountLiveBooksByCode :one
+SELECT COUNT(DISTINCT e1.book_id) AS cnt
+FROM book_events e1
+WHERE e1.code = ?
+    AND e1.event_type IN ('created', 'updated')
+    AND e1.occurred_at > COALESCE(
+        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = e1.book_id AND e2.event_type = 'deleted'),
+        '1970-01-01T00:00:00Z'
+    );
+
+-- name: ListImportRows :many
+SELECT row_number, code, title, status, book_id, message
+FROM import_rows
+WHERE import_id = ?
+ORDER BY row_number;
+
+-- name: InsertImportRow :exec
+INSERT INTO import_rows (import_id, row_number, code, title, status, book_id, message, processed_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
@@ -0,0 +1,24 @@
+-- name: InsertCategoryEvent :exec
+INSERT INTO category_events (event_id, category_id, event_type, parent_id, code, name, occurred_at)
+VALUES (?, ?, 'created', ?, ?, ?, ?);
+
+-- name: ListCategories :many
+SELECT category_id, parent_id, code, name
+FROM category_events
+WHERE event_type = 'created'
+ORDER BY occurred_at, rowid;
+
+-- name: CountCategoryByCategoryId :one
+SELECT COUNT(*) AS cnt
+FROM category_events
+WHERE category_id = ?
+    AND event_type = 'created';
+
+-- name: GetCategoryIdByParentAndName :one
+SELECT category_id
+FROM category_events
+WHERE event_type = 'created'
+    AND parent_id IS ?
+    AND name = ?
+ORDER BY occurred_at, rowid
+LIMIT 1;
@@ -0,0 +1,43 @@
+-- name: GetBookCoverSource :one
+SELECT
+    e1.title,
+    e1.thumbnail_url
+FROM book_events e1
+WHERE e1.book_id = ?
+    AND e1.event_type IN ('created', 'updated')
+    AND e1.occurred_at > COALESCE(
+        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = e1.book_id AND e2.event_type = 'deleted'),
+        '1970-01-01T00:00:00Z'
+    )
+ORDER BY e1.occurred_at DESC, e1.rowid DESC
+LIMIT 1;
+
+-- name: GetCoverImage :one
+SELECT content_type, data, etag, created_at
+FROM cover_images
+WHERE source = ? AND variant = ?;
+
+-- name: UpsertCoverImage :exec
+INSERT INTO cover_images (source, variant, content_type, data, etag, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (source, variant) DO UPDATE SET
+    content_type = excluded.content_type,
+    data = excluded.data,
+    etag = excluded.etag,
+    created_at = excluded.created_at;
+
+-- name: GetLatestUploadedCover :one
+SELECT e1.cover_id
+FROM book_events e1
+WHERE e1.book_id = ?
+    AND e1.event_type = 'cover_changed'
+    AND e1.occurred_at > COALESCE(
+        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = e1.book_id AND e2.event_type = 'deleted'),
+        '1970-01-01T00:00:00Z'
+    )
+ORDER BY e1.occurred_at DESC, e1.rowid DESC
+LIMIT 1;
+
+-- name: InsertCoverChangedEvent :exec
+INSERT INTO book_events (event_id, book_id, event_type, cover_id, occurred_at)
+VALUES (?, ?, 'cover_changed', ?, ?);
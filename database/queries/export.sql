@@ -0,0 +1,20 @@
+-- name: ListCatalogPage :many
+SELECT
+    lb.book_id,
+    lb.code,
+    lb.title,
+    lb.authors,
+    lb.publisher,
+    lb.published_date,
+    lb.thumbnail_url,
+    lb.created_at,
+    lb.updated_at,
+    cl.borrower_id,
+    cl.borrower_name,
+    cl.borrowed_at,
+    cl.due_date
+FROM latest_books lb
+LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id
+WHERE lb.book_id > ?
+ORDER BY lb.book_id
+LIMIT ?;
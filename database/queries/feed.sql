@@ -0,0 +1,12 @@
+-- name: UpsertCalendarFeed :exec
+INSERT INTO calendar_feeds (user_id, token_hash, created_at)
+VALUES (?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE SET
+    token_hash = excluded.token_hash,
+    created_at = excluded.created_at;
+
+-- name: DeleteCalendarFeed :exec
+DELETE FROM calendar_feeds WHERE user_id = ?;
+
+-- name: GetCalendarFeedUserID :one
+SELECT user_id FROM calendar_feeds WHERE token_hash = ?;
@@ -0,0 +1,34 @@
+-- name: GetLabelBook :one
+SELECT
+    e1.book_id,
+    (SELECT a.accession_number
+     FROM book_events a
+     WHERE a.book_id = e1.book_id
+       AND a.event_type = 'accession_assigned'
+       AND a.occurred_at > COALESCE(
+           (SELECT MAX(e_del.occurred_at) FROM book_events e_del WHERE e_del.book_id = e1.book_id AND e_del.event_type = 'deleted'),
+           '1970-01-01T00:00:00Z'
+       )
+     ORDER BY a.occurred_at DESC, a.rowid DESC
+     LIMIT 1
+    ) as accession_number
+FROM book_events e1
+WHERE e1.book_id = ?
+    AND e1.event_type IN ('created', 'updated')
+    AND e1.occurred_at > COALESCE(
+        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = e1.book_id AND e2.event_type = 'deleted'),
+        '1970-01-01T00:00:00Z'
+    )
+LIMIT 1;
+
+-- name: ListAccessionNumberedBooks :many
+SELECT
+    a.book_id,
+    a.accession_number
+FROM book_events a
+WHERE a.event_type = 'accession_assigned'
+    AND a.occurred_at > COALESCE(
+        (SELECT MAX(e2.occurred_at) FROM book_events e2 WHERE e2.book_id = a.book_id AND e2.event_type = 'deleted'),
+        '1970-01-01T00:00:00Z'
+    )
+ORDER BY a.accession_number;
@@ -6,8 +6,10 @@ INSERT INTO lending_events (
     borrower_id,
     event_type,
     due_date,
+    actor_id,
+    reason,
     occurred_at
-) VALUES (?, ?, ?, ?, ?, ?, ?);
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetCurrentLending :one
 SELECT
@@ -15,54 +17,33 @@ SELECT
     book_id,
     borrower_id,
     due_date,
-    occurred_at as borrowed_at
-FROM lending_events
+    borrowed_at
+FROM open_lendings
 WHERE book_id = ?
-    AND event_type = 'borrowed'
-    AND NOT EXISTS (
-        SELECT 1
-        FROM lending_events returned
-        WHERE returned.lending_id = lending_events.lending_id
-            AND returned.event_type = 'returned'
-    )
-ORDER BY occurred_at DESC
+ORDER BY borrowed_at DESC
 LIMIT 1;
 
 -- name: IsBookBorrowed :one
 SELECT EXISTS(
     SELECT 1
-    FROM lending_events
+    FROM open_lendings
     WHERE book_id = ?
-        AND event_type = 'borrowed'
-        AND NOT EXISTS (
-            SELECT 1
-            FROM lending_events returned
-            WHERE returned.lending_id = lending_events.lending_id
-                AND returned.event_type = 'returned'
-        )
 ) as is_borrowed;
 
 -- name: GetLatestDueDate :one
 SELECT due_date
 FROM lending_events
 WHERE lending_id = ?
-    AND event_type IN ('borrowed', 'due_date_extended')
+    AND event_type IN ('borrowed', 'lent', 'due_date_extended')
     AND due_date IS NOT NULL
 ORDER BY occurred_at DESC
 LIMIT 1;
 
 -- name: ListBorrowingBooksByBorrowerID :many
 WITH my_current_lendings AS (
-    SELECT le.book_id, le.lending_id, le.occurred_at as borrowed_at
-    FROM lending_events le
-    WHERE le.borrower_id = ?
-        AND le.event_type = 'borrowed'
-        AND NOT EXISTS (
-            SELECT 1
-            FROM lending_events returned
-            WHERE returned.lending_id = le.lending_id
-                AND returned.event_type = 'returned'
-        )
+    SELECT book_id, lending_id, borrowed_at
+    FROM open_lendings
+    WHERE borrower_id = ?
 ),
 deleted_books AS (
     SELECT book_id, MAX(occurred_at) as deleted_at
@@ -108,11 +89,108 @@ SELECT
         SELECT ldd.due_date
         FROM lending_events ldd
         WHERE ldd.lending_id = mcl.lending_id
-            AND ldd.event_type IN ('borrowed', 'due_date_extended')
+            AND ldd.event_type IN ('borrowed', 'lent', 'due_date_extended')
             AND ldd.due_date IS NOT NULL
         ORDER BY ldd.occurred_at DESC
         LIMIT 1
-    ) as due_date
+    ) as due_date,
+    mcl.lending_id,
+    (
+        SELECT COUNT(*)
+        FROM lending_events ext
+        WHERE ext.lending_id = mcl.lending_id
+            AND ext.event_type = 'due_date_extended'
+    ) as extensions
 FROM my_current_lendings mcl
 JOIN latest_books lb ON lb.book_id = mcl.book_id AND lb.rn = 1
 ORDER BY mcl.borrowed_at DESC;
+
+-- name: CountLendingsByBorrowerID :one
+SELECT COUNT(*) as cnt
+FROM lending_events le
+WHERE le.borrower_id = sqlc.arg(borrower_id)
+    AND le.event_type IN ('borrowed', 'lent')
+    AND (
+        sqlc.arg(state) = 'all'
+        OR (sqlc.arg(state) = 'open') = EXISTS (
+            SELECT 1 FROM open_lendings ol WHERE ol.lending_id = le.lending_id
+        )
+    );
+
+-- name: ListLendingHistoryByBorrowerID :many
+-- Every event of one page of the borrower's loans, newest loan first and the
+-- events of a loan in the order they were recorded. The state is all, open or
+-- returned, where returned is any loan that has been closed. Each event carries
+-- the condition the book was returned in, if it was recorded.
+WITH page AS (
+    SELECT le.lending_id, le.occurred_at, le.rowid as seq
+    FROM lending_events le
+    WHERE le.borrower_id = sqlc.arg(borrower_id)
+        AND le.event_type IN ('borrowed', 'lent')
+        AND (
+            sqlc.arg(state) = 'all'
+            OR (sqlc.arg(state) = 'open') = EXISTS (
+                SELECT 1 FROM open_lendings ol WHERE ol.lending_id = le.lending_id
+            )
+        )
+    ORDER BY le.occurred_at DESC, le.rowid DESC
+    LIMIT ? OFFSET ?
+)
+SELECT
+    e.lending_id,
+    e.book_id,
+    e.borrower_id,
+    e.event_type,
+    e.due_date,
+    e.occurred_at,
+    (
+        SELECT b.title
+        FROM book_events b
+        WHERE b.book_id = e.book_id
+            AND b.event_type IN ('created', 'updated')
+        ORDER BY b.occurred_at DESC
+        LIMIT 1
+    ) as title,
+    c.condition,
+    c.condition_note
+FROM lending_events e
+INNER JOIN page p ON p.lending_id = e.lending_id
+LEFT JOIN book_events c ON c.lending_id = e.lending_id AND c.event_type = 'condition_recorded'
+ORDER BY p.occurred_at DESC, p.seq DESC, e.occurred_at, e.rowid;
+
+-- name: CountLendingsByBookID :one
+SELECT COUNT(*) as cnt
+FROM lending_events
+WHERE book_id = ?
+    AND event_type IN ('borrowed', 'lent');
+
+-- name: ListLendingHistoryByBookID :many
+WITH page AS (
+    SELECT le.lending_id, le.occurred_at, le.rowid as seq
+    FROM lending_events le
+    WHERE le.book_id = ?
+        AND le.event_type IN ('borrowed', 'lent')
+    ORDER BY le.occurred_at DESC, le.rowid DESC
+    LIMIT ? OFFSET ?
+)
+SELECT
+    e.lending_id,
+    e.book_id,
+    e.borrower_id,
+    e.event_type,
+    e.due_date,
+    e.occurred_at,
+    (
+        SELECT u.name
+        FROM user_events u
+        WHERE u.user_id = e.borrower_id
+            AND u.event_type = 'created'
+        ORDER BY u.occurred_at DESC
+        LIMIT 1
+    ) as borrower_name,
+    c.condition,
+    c.condition_note
+FROM lending_events e
+INNER JOIN page p ON p.lending_id = e.lending_id
+LEFT JOIN book_events c ON c.lending_id = e.lending_id AND c.event_type = 'condition_recorded'
+ORDER BY p.occurred_at DESC, p.seq DESC, e.occurred_at, e.rowid;
@@ -0,0 +1,154 @@
+-- name: GetNotificationPosition :one
+SELECT last_rowid
+FROM notification_state
+WHERE stream = ?;
+
+-- name: SetNotificationPosition :exec
+INSERT INTO notification_state (stream, last_rowid)
+VALUES (?, ?)
+ON CONFLICT (stream) DO UPDATE SET last_rowid = excluded.last_rowid;
+
+-- name: GetLatestLendingEventRowid :one
+SELECT CAST(COALESCE(MAX(rowid), 0) AS INTEGER) AS last_rowid
+FROM lending_events;
+
+-- name: GetLatestBookEventRowid :one
+SELECT CAST(COALESCE(MAX(rowid), 0) AS INTEGER) AS last_rowid
+FROM book_events;
+
+-- name: ListLendingEventsBetween :many
+SELECT event_id, lending_id, book_id, borrower_id, event_type, due_date, actor_id, reason, occurred_at
+FROM lending_events
+WHERE rowid > sqlc.arg(after_rowid) AND rowid <= sqlc.arg(until_rowid)
+ORDER BY rowid;
+
+-- name: ListBookEventsBetween :many
+SELECT event_id, book_id, event_type, title, series_id, occurred_at
+FROM book_events
+WHERE rowid > sqlc.arg(after_rowid) AND rowid <= sqlc.arg(until_rowid)
+ORDER BY rowid;
+
+-- name: GetBookTitle :one
+SELECT title
+FROM book_events
+WHERE book_id = ? AND event_type IN ('created', 'updated')
+ORDER BY occurred_at DESC
+LIMIT 1;
+
+-- name: GetSeriesTitle :one
+SELECT title
+FROM series_events
+WHERE series_id = ?
+    AND event_type = 'created'
+LIMIT 1;
+
+-- name: ListSeriesReaders :many
+-- Users who have borrowed another book currently in the series.
+WITH book_series AS (
+    SELECT
+        book_id,
+        series_id,
+        ROW_NUMBER() OVER (PARTITION BY book_id ORDER BY occurred_at DESC, rowid DESC) as rn
+    FROM book_events
+    WHERE event_type = 'series_changed'
+)
+SELECT DISTINCT le.borrower_id
+FROM lending_events le
+JOIN book_series bs ON bs.book_id = le.book_id AND bs.rn = 1
+WHERE bs.series_id = sqlc.arg(series_id)
+    AND le.book_id != sqlc.arg(book_id)
+    AND le.event_type IN ('borrowed', 'lent')
+ORDER BY le.borrower_id;
+
+-- name: ListLoansDueBetween :many
+-- Open loans whose latest due date is in the range.
+WITH open_loans AS (
+    SELECT
+        ol.lending_id,
+        ol.book_id,
+        ol.borrower_id,
+        (
+            SELECT d.due_date
+            FROM lending_events d
+            WHERE d.lending_id = ol.lending_id
+                AND d.event_type IN ('borrowed', 'lent', 'due_date_extended')
+                AND d.due_date IS NOT NULL
+            ORDER BY d.occurred_at DESC
+            LIMIT 1
+        ) AS due_date
+    FROM open_lendings ol
+)
+SELECT
+    o.lending_id,
+    o.book_id,
+    o.borrower_id,
+    o.due_date,
+    (
+        SELECT b.title
+        FROM book_events b
+        WHERE b.book_id = o.book_id
+            AND b.event_type IN ('created', 'updated')
+        ORDER BY b.occurred_at DESC
+        LIMIT 1
+    ) AS title
+FROM open_loans o
+WHERE o.due_date >= sqlc.arg(from_at) AND o.due_date < sqlc.arg(to_at)
+ORDER BY o.due_date, o.lending_id;
+
+-- name: InsertNotification :exec
+-- Skips notifications the user has opted out of, and ones already created
+-- for the same dedupe key, so producers can be run again safely.
+INSERT OR IGNORE INTO notifications (
+    notification_id, user_id, type, title, body, book_id, dedupe_key, created_at
+)
+SELECT
+    sqlc.arg(notification_id), sqlc.arg(user_id), sqlc.arg(type), sqlc.arg(title),
+    sqlc.arg(body), sqlc.arg(book_id), sqlc.arg(dedupe_key), sqlc.arg(created_at)
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM notification_opt_outs o
+    WHERE o.user_id = sqlc.arg(user_id) AND o.type = sqlc.arg(type)
+);
+
+-- name: CountNotifications :one
+SELECT COUNT(*) AS cnt
+FROM notifications
+WHERE user_id = sqlc.arg(user_id)
+    AND deleted_at IS NULL
+    AND (sqlc.arg(filter) = 'all' OR read_at IS NULL);
+
+-- name: ListNotifications :many
+SELECT notification_id, type, title, body, book_id, created_at, read_at
+FROM notifications
+WHERE user_id = sqlc.arg(user_id)
+    AND deleted_at IS NULL
+    AND (sqlc.arg(filter) = 'all' OR read_at IS NULL)
+ORDER BY created_at DESC, rowid DESC
+LIMIT ? OFFSET ?;
+
+-- name: GetNotificationOwner :one
+SELECT user_id
+FROM notifications
+WHERE notification_id = ? AND deleted_at IS NULL;
+
+-- name: MarkNotificationRead :exec
+UPDATE notifications SET read_at = ? WHERE notification_id = ? AND read_at IS NULL;
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL AND deleted_at IS NULL;
+
+-- name: DeleteNotification :exec
+-- The row is kept so its dedupe key stops the notification from coming back.
+UPDATE notifications SET deleted_at = ? WHERE notification_id = ?;
+
+-- name: ListNotificationOptOuts :many
+SELECT type AS notification_type
+FROM notification_opt_outs
+WHERE user_id = ?
+ORDER BY type;
+
+-- name: InsertNotificationOptOut :exec
+INSERT OR IGNORE INTO notification_opt_outs (user_id, type) VALUES (?, ?);
+
+-- name: DeleteNotificationOptOut :exec
+DELETE FROM notification_opt_outs WHERE user_id = ? AND type = ?;
@@ -0,0 +1,89 @@
+-- name: InsertSeriesEvent :exec
+INSERT INTO series_events (event_id, series_id, event_type, title, occurred_at)
+VALUES (?, ?, 'created', ?, ?);
+
+-- name: GetSeriesIdByTitle :one
+SELECT series_id
+FROM series_events
+WHERE title = ?
+    AND event_type = 'created'
+ORDER BY occurred_at, rowid
+LIMIT 1;
+
+-- name: GetSeriesBySeriesId :one
+SELECT series_id, title
+FROM series_events
+WHERE series_id = ?
+    AND event_type = 'created'
+LIMIT 1;
+
+-- name: InsertBookSeriesChangedEvent :exec
+INSERT INTO book_events (event_id, book_id, event_type, series_id, volume_number, occurred_at)
+VALUES (?, ?, 'series_changed', ?, ?, ?);
+
+-- name: ListSeriesBooks :many
+WITH deleted_books AS (
+    SELECT book_id, MAX(occurred_at) as deleted_at
+    FROM book_events
+    WHERE event_type = 'deleted'
+    GROUP BY book_id
+),
+latest_books AS (
+    SELECT
+        e1.book_id,
+        e1.title,
+        ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+    FROM book_events e1
+    LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+    WHERE e1.event_type IN ('created', 'updated')
+        AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+),
+book_series AS (
+    SELECT
+        e1.book_id,
+        e1.series_id,
+        e1.volume_number,
+        ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+    FROM book_events e1
+    LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+    WHERE e1.event_type = 'series_changed'
+        AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+)
+SELECT
+    bs.book_id,
+    CAST(bs.volume_number AS INTEGER) as volume_number,
+    lb.title,
+    EXISTS (
+        SELECT 1 FROM open_lendings ol WHERE ol.book_id = bs.book_id
+    ) as borrowed
+FROM book_series bs
+INNER JOIN latest_books lb ON lb.book_id = bs.book_id AND lb.rn = 1
+WHERE bs.rn = 1
+    AND bs.series_id = ?
+    AND bs.volume_number IS NOT NULL
+ORDER BY bs.volume_number, lb.title, bs.book_id;
+
+-- name: GetLastBorrowedVolume :one
+WITH deleted_books AS (
+    SELECT book_id, MAX(occurred_at) as deleted_at
+    FROM book_events
+    WHERE event_type = 'deleted'
+    GROUP BY book_id
+),
+book_series AS (
+    SELECT
+        e1.book_id,
+        e1.series_id,
+        e1.volume_number,
+        ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+    FROM book_events e1
+    LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+    WHERE e1.event_type = 'series_changed'
+        AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+)
+SELECT CAST(COALESCE(MAX(bs.volume_number), 0) AS INTEGER) as volume_number
+FROM lending_events le
+INNER JOIN book_series bs ON bs.book_id = le.book_id AND bs.rn = 1
+WHERE le.event_type IN ('borrowed', 'lent')
+    AND le.borrower_id = ?
+    AND bs.series_id = ?;
@@ -0,0 +1,174 @@
+-- name: GetLoanStatsPosition :one
+SELECT CAST(COALESCE(
+    (SELECT last_event_rowid FROM loan_stats_state WHERE id = 1),
+    0
+) AS INTEGER) AS last_event_rowid;
+
+-- name: GetLatestLendingEventRowid :one
+SELECT CAST(COALESCE(MAX(rowid), 0) AS INTEGER) AS last_event_rowid
+FROM lending_events;
+
+-- name: ListLendingEventsBetween :many
+SELECT lending_id, book_id, borrower_id, event_type, due_date, occurred_at
+FROM lending_events
+WHERE rowid > sqlc.arg(after_rowid) AND rowid <= sqlc.arg(until_rowid)
+ORDER BY rowid;
+
+-- name: UpsertLoanStat :exec
+INSERT INTO loan_stats (lending_id, book_id, borrower_id, borrowed_at, due_date)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (lending_id) DO UPDATE SET
+    book_id = excluded.book_id,
+    borrower_id = excluded.borrower_id,
+    borrowed_at = excluded.borrowed_at,
+    due_date = excluded.due_date;
+
+-- name: UpdateLoanStatDueDate :exec
+UPDATE loan_stats SET due_date = ? WHERE lending_id = ?;
+
+-- name: CloseLoanStat :exec
+UPDATE loan_stats SET closed_at = ?, close_type = ? WHERE lending_id = ?;
+
+-- name: DeleteAllLoanStats :exec
+DELETE FROM loan_stats;
+
+-- name: SetLoanStatsPosition :exec
+INSERT INTO loan_stats_state (id, last_event_rowid)
+VALUES (1, ?)
+ON CONFLICT (id) DO UPDATE SET last_event_rowid = excluded.last_event_rowid;
+
+-- name: GetLoanSummary :one
+-- Loans and borrowers count loans borrowed in the range, the loan duration
+-- loans closed in it and the overdue rate loans that came due in it before now,
+-- which is the start of the closed days when the library is closed.
+-- A loan is overdue when it was closed after its due date or is still open past it.
+SELECT
+    (SELECT COUNT(*) FROM loan_stats
+     WHERE borrowed_at >= sqlc.arg(from_at) AND borrowed_at < sqlc.arg(to_at)) AS loans,
+    (SELECT COUNT(DISTINCT borrower_id) FROM loan_stats
+     WHERE borrowed_at >= sqlc.arg(from_at) AND borrowed_at < sqlc.arg(to_at)) AS borrowers,
+    (SELECT COUNT(*) FROM loan_stats
+     WHERE closed_at >= sqlc.arg(from_at) AND closed_at < sqlc.arg(to_at)) AS closed_loans,
+    (SELECT AVG(julianday(closed_at) - julianday(borrowed_at)) FROM loan_stats
+     WHERE closed_at >= sqlc.arg(from_at) AND closed_at < sqlc.arg(to_at)) AS average_loan_days,
+    (SELECT COUNT(*) FROM loan_stats
+     WHERE due_date >= sqlc.arg(from_at) AND due_date < sqlc.arg(to_at) AND due_date < sqlc.arg(now)) AS due_loans,
+    (SELECT COUNT(*) FROM loan_stats
+     WHERE due_date >= sqlc.arg(from_at) AND due_date < sqlc.arg(to_at) AND due_date < sqlc.arg(now)
+        AND COALESCE(closed_at, sqlc.arg(now)) > due_date) AS overdue_loans;
+
+-- name: ListMostBorrowedBooks :many
+SELECT
+    s.book_id,
+    (
+        SELECT b.title
+        FROM book_events b
+        WHERE b.book_id = s.book_id
+            AND b.event_type IN ('created', 'updated')
+        ORDER BY b.occurred_at DESC
+        LIMIT 1
+    ) AS title,
+    COUNT(*) AS loans
+FROM loan_stats s
+WHERE s.borrowed_at >= sqlc.arg(from_at) AND s.borrowed_at < sqlc.arg(to_at)
+GROUP BY s.book_id
+ORDER BY loans DESC, s.book_id
+LIMIT ?;
+
+-- name: ListActiveBorrowers :many
+SELECT
+    s.borrower_id,
+    (
+        SELECT u.name
+        FROM user_events u
+        WHERE u.user_id = s.borrower_id
+            AND u.event_type = 'created'
+        ORDER BY u.occurred_at DESC
+        LIMIT 1
+    ) AS name,
+    COUNT(*) AS loans
+FROM loan_stats s
+WHERE s.borrowed_at >= sqlc.arg(from_at) AND s.borrowed_at < sqlc.arg(to_at)
+GROUP BY s.borrower_id
+ORDER BY loans DESC, s.borrower_id
+LIMIT ?;
+
+-- name: CountBooksNotBorrowed :one
+SELECT COUNT(DISTINCT e.book_id) AS cnt
+FROM book_events e
+WHERE e.event_type = 'created'
+    AND e.occurred_at < sqlc.arg(to_at)
+    AND NOT EXISTS (
+        SELECT 1 FROM book_events d
+        WHERE d.book_id = e.book_id AND d.event_type = 'deleted' AND d.occurred_at >= e.occurred_at
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM loan_stats s
+        WHERE s.book_id = e.book_id AND s.borrowed_at >= sqlc.arg(from_at) AND s.borrowed_at < sqlc.arg(to_at)
+    );
+
+-- name: ListBooksNotBorrowed :many
+-- Books registered before the end of the range and not deleted that nobody
+-- borrowed in the range. Books never borrowed at all come first, then the
+-- ones left on the shelf the longest.
+WITH idle_books AS (
+    SELECT DISTINCT e.book_id
+    FROM book_events e
+    WHERE e.event_type = 'created'
+        AND e.occurred_at < sqlc.arg(to_at)
+        AND NOT EXISTS (
+            SELECT 1 FROM book_events d
+            WHERE d.book_id = e.book_id AND d.event_type = 'deleted' AND d.occurred_at >= e.occurred_at
+        )
+        AND NOT EXISTS (
+            SELECT 1 FROM loan_stats s
+            WHERE s.book_id = e.book_id AND s.borrowed_at >= sqlc.arg(from_at) AND s.borrowed_at < sqlc.arg(to_at)
+        )
+)
+SELECT
+    ib.book_id,
+    (
+        SELECT b.title
+        FROM book_events b
+        WHERE b.book_id = ib.book_id
+            AND b.event_type IN ('created', 'updated')
+        ORDER BY b.occurred_at DESC
+        LIMIT 1
+    ) AS title,
+    (
+        SELECT MAX(s.borrowed_at)
+        FROM loan_stats s
+        WHERE s.book_id = ib.book_id AND s.borrowed_at < sqlc.arg(from_at)
+    ) AS last_borrowed_at
+FROM idle_books ib
+ORDER BY last_borrowed_at IS NOT NULL, last_borrowed_at, ib.book_id
+LIMIT ? OFFSET ?;
+
+-- name: ListLoanCountsByBucket :many
+-- The bucket is day, week (starting on Monday) or month, named by its first day.
+SELECT
+    CASE sqlc.arg(bucket)
+        WHEN 'day' THEN date(borrowed_at)
+        WHEN 'week' THEN date(borrowed_at, 'weekday 0', '-6 days')
+        ELSE strftime('%Y-%m-01', borrowed_at)
+    END AS bucket_start,
+    COUNT(*) AS loans,
+    COUNT(DISTINCT borrower_id) AS borrowers
+FROM loan_stats
+WHERE borrowed_at >= sqlc.arg(from_at) AND borrowed_at < sqlc.arg(to_at)
+GROUP BY bucket_start
+ORDER BY bucket_start;
+
+-- name: ListReturnCountsByBucket :many
+SELECT
+    CASE sqlc.arg(bucket)
+        WHEN 'day' THEN date(closed_at)
+        WHEN 'week' THEN date(closed_at, 'weekday 0', '-6 days')
+        ELSE strftime('%Y-%m-01', closed_at)
+    END AS bucket_start,
+    COUNT(*) AS returns,
+    COUNT(CASE WHEN closed_at > due_date THEN 1 END) AS late_returns
+FROM loan_stats
+WHERE closed_at >= sqlc.arg(from_at) AND closed_at < sqlc.arg(to_at)
+GROUP BY bucket_start
+ORDER BY bucket_start;
@@ -0,0 +1,28 @@
+CREATE TABLE audit_sessions (
+    audit_id TEXT PRIMARY KEY,
+    started_by TEXT NOT NULL,
+    started_at TEXT NOT NULL,
+    finished_by TEXT,
+    finished_at TEXT
+);
+
+CREATE TABLE audit_books (
+    audit_id TEXT NOT NULL,
+    book_id TEXT NOT NULL,
+    code TEXT,
+    title TEXT,
+    seen_at TEXT,
+    status TEXT,
+    PRIMARY KEY (audit_id, book_id)
+);
+
+CREATE TABLE audit_scans (
+    audit_id TEXT NOT NULL,
+    scan_number INTEGER NOT NULL,
+    code TEXT NOT NULL,
+    book_id TEXT,
+    result TEXT NOT NULL,
+    scanned_by TEXT NOT NULL,
+    scanned_at TEXT NOT NULL,
+    PRIMARY KEY (audit_id, scan_number)
+);
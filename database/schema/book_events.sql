@@ -10,7 +10,20 @@ CREATE TABLE book_events (
     thumbnail_url TEXT,
     delete_reason TEXT,
     delete_memo TEXT,
+    origin TEXT,
+    cover_id TEXT,
+    tags TEXT,
+    category_id TEXT,
+    shelf_location TEXT,
+    series_id TEXT,
+    volume_number INTEGER,
+    accession_number TEXT,
+    condition TEXT,
+    condition_note TEXT,
+    lending_id TEXT,
     occurred_at TEXT NOT NULL
 );
 
 CREATE INDEX idx_book_events_book_id ON book_events(book_id);
+CREATE UNIQUE INDEX idx_book_events_accession_number ON book_events(accession_number);
+CREATE INDEX idx_book_events_lending_id ON book_events(lending_id);
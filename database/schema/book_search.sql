@@ -0,0 +1,12 @@
+CREATE VIRTUAL TABLE book_search USING fts5(
+    book_id UNINDEXED,
+    title,
+    authors,
+    publisher,
+    tokenize = 'unicode61 remove_diacritics 0'
+);
+
+CREATE TABLE book_search_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    last_event_rowid INTEGER NOT NULL
+);
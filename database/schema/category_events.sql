@@ -0,0 +1,12 @@
+CREATE TABLE category_events (
+    event_id TEXT PRIMARY KEY,
+    category_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    parent_id TEXT,
+    code TEXT,
+    name TEXT NOT NULL,
+    occurred_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_category_events_category_id ON category_events(category_id);
+CREATE INDEX idx_category_events_parent_id ON category_events(parent_id);
@@ -0,0 +1,9 @@
+CREATE TABLE cover_images (
+    source TEXT NOT NULL,
+    variant TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    data BLOB NOT NULL,
+    etag TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    PRIMARY KEY (source, variant)
+);
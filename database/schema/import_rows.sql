@@ -0,0 +1,11 @@
+CREATE TABLE import_rows (
+    import_id TEXT NOT NULL,
+    row_number INTEGER NOT NULL,
+    code TEXT,
+    title TEXT,
+    status TEXT NOT NULL,
+    book_id TEXT,
+    message TEXT,
+    processed_at TEXT NOT NULL,
+    PRIMARY KEY (import_id, row_number)
+);
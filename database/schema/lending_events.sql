@@ -5,6 +5,8 @@ CREATE TABLE lending_events (
     borrower_id TEXT NOT NULL,
     event_type TEXT NOT NULL,
     due_date TEXT,
+    actor_id TEXT,
+    reason TEXT,
     occurred_at TEXT NOT NULL
 );
 
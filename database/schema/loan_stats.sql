@@ -0,0 +1,17 @@
+CREATE TABLE loan_stats (
+    lending_id TEXT PRIMARY KEY,
+    book_id TEXT NOT NULL,
+    borrower_id TEXT NOT NULL,
+    borrowed_at TEXT NOT NULL,
+    due_date TEXT NOT NULL,
+    closed_at TEXT,
+    close_type TEXT
+);
+
+CREATE INDEX idx_loan_stats_borrowed_at ON loan_stats(borrowed_at);
+CREATE INDEX idx_loan_stats_book_id ON loan_stats(book_id);
+
+CREATE TABLE loan_stats_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    last_event_rowid INTEGER NOT NULL
+);
@@ -0,0 +1,25 @@
+CREATE TABLE notifications (
+    notification_id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    book_id TEXT,
+    dedupe_key TEXT NOT NULL UNIQUE,
+    created_at TEXT NOT NULL,
+    read_at TEXT,
+    deleted_at TEXT
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, created_at);
+
+CREATE TABLE notification_opt_outs (
+    user_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    PRIMARY KEY (user_id, type)
+);
+
+CREATE TABLE notification_state (
+    stream TEXT PRIMARY KEY,
+    last_rowid INTEGER NOT NULL
+);
@@ -0,0 +1,10 @@
+CREATE TABLE series_events (
+    event_id TEXT PRIMARY KEY,
+    series_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    title TEXT NOT NULL,
+    occurred_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_series_events_series_id ON series_events(series_id);
+CREATE INDEX idx_series_events_title ON series_events(title);
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"holocron/internal/backup"
+	"holocron/internal/bookcode"
+	"holocron/internal/books"
+	"holocron/internal/bulkimport"
+	"holocron/internal/export"
+	exportDomain "holocron/internal/export/domain"
+	"holocron/internal/notification"
+	"holocron/internal/stats"
+)
+
+func runCommand(name string, args []string) error {
+	switch name {
+	case "import":
+		return runImport(args)
+	case "export":
+		return runExport(args)
+	case "backup":
+		return runBackup(args)
+	case "restore":
+		return runRestore(args)
+	default:
+		return fmt.Errorf("unknown command: %s", name)
+	}
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "input format: csv, tsv or isbn (detected from the content if empty)")
+	dryRun := fs.Bool("dry-run", false, "report the result without registering books")
+	importID := fs.String("id", "", "import ID used to resume (derived from the content if empty)")
+	concurrency := fs.Int("concurrency", bulkimport.DefaultConcurrency, "number of concurrent lookups")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: holocron import [flags] FILE")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("import file is required")
+	}
+	if os.Getenv("DATABASE_PATH") == "" {
+		return errors.New("DATABASE_PATH is not set")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	database, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	if err := initDB(database); err != nil {
+		return err
+	}
+
+	sources, err := newBookInfoSources(bookcode.New(database))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	service := bulkimport.NewImportBooksService(database, sources)
+	report, err := service.ImportBooks(ctx, bulkimport.ImportBooksInput{
+		Format:      *format,
+		Data:        data,
+		ImportID:    *importID,
+		DryRun:      *dryRun,
+		Concurrency: *concurrency,
+		OnRow: func(row bulkimport.RowResult) {
+			line := fmt.Sprintf("%d\t%s\t%s\t%s", row.Row, row.Status, row.Code, row.Title)
+			if row.Message != "" {
+				line += "\t" + row.Message
+			}
+			if row.Resumed {
+				line += "\t(resumed)"
+			}
+			fmt.Println(line)
+		},
+	})
+	if report != nil {
+		fmt.Fprintf(os.Stderr, "import %s: %d rows, %d created, %d duplicate, %d not found, %d invalid\n",
+			report.ImportID, report.Total, report.Created, report.Duplicate, report.NotFound, report.Invalid)
+	}
+	if errors.Is(err, context.Canceled) {
+		// The report is nil when the import is canceled while the rows of an
+		// earlier run are still being loaded, before any row is imported. Then
+		// there is no summary above, and the error has no import ID to resume.
+		if report == nil {
+			return errors.New("import interrupted; run again to resume")
+		}
+		return fmt.Errorf("import interrupted; run again with -id %s to resume", report.ImportID)
+	}
+	return err
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", string(exportDomain.ExportFormatCSV), "output format: csv, jsonl, marc, marcxml or bibtex")
+	output := fs.String("o", "", "output file (standard output if empty)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: holocron export [flags]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	exportFormat, err := exportDomain.ParseExportFormat(*format)
+	if err != nil {
+		return err
+	}
+	if os.Getenv("DATABASE_PATH") == "" {
+		return errors.New("DATABASE_PATH is not set")
+	}
+
+	database, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	if err := initDB(database); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	count, err := export.NewExportBooksService(export.New(database)).ExportBooks(ctx, exportFormat, w)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d books\n", count)
+	return nil
+}
+
+func runBackup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := fs.String("o", "", "output file (standard output if empty)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: holocron backup [flags]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if os.Getenv("DATABASE_PATH") == "" {
+		return errors.New("DATABASE_PATH is not set")
+	}
+
+	database, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	if err := initDB(database); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	counts, err := backup.NewBackupService(database).Backup(context.Background(), w)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "backed up %d user events, %d category events, %d series events, %d book events, %d lending events, %d cover images\n",
+		counts.UserEvents, counts.CategoryEvents, counts.SeriesEvents, counts.BookEvents, counts.LendingEvents, counts.CoverImages)
+	return nil
+}
+
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	replace := fs.Bool("replace", false, "delete the existing events before restoring")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: holocron restore [flags] FILE")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("backup file is required")
+	}
+	if os.Getenv("DATABASE_PATH") == "" {
+		return errors.New("DATABASE_PATH is not set")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	database, err := openDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	if err := initDB(database); err != nil {
+		return err
+	}
+
+	libraryCalendar, err := parseLibraryCalendar()
+	if err != nil {
+		return fmt.Errorf("invalid library calendar: %w", err)
+	}
+
+	restoreService := backup.NewRestoreService(database, projections(
+		books.NewBookSearchIndex(database),
+		stats.NewLoanStatsProjection(database, libraryCalendar),
+		notification.NewDispatcher(database, notification.DefaultProducers()...),
+	)...)
+	output, err := restoreService.Restore(context.Background(), backup.RestoreInput{
+		Backup:  file,
+		Replace: *replace,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "restored %d user events, %d category events, %d series events, %d book events, %d lending events, %d cover images\n",
+		output.Counts.UserEvents, output.Counts.CategoryEvents, output.Counts.SeriesEvents, output.Counts.BookEvents, output.Counts.LendingEvents, output.Counts.CoverImages)
+	for _, name := range output.RebuiltProjections {
+		fmt.Fprintf(os.Stderr, "rebuilt %s\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"holocron/internal/audit/domain"
+	"holocron/internal/auth"
+	"holocron/internal/book"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type StartAuditHandler struct {
+	service *AuditService
+	roles   auth.Roles
+}
+
+func NewStartAuditHandler(service *AuditService, roles auth.Roles) *StartAuditHandler {
+	return &StartAuditHandler{service: service, roles: roles}
+}
+
+func (h *StartAuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := librarianID(w, r, h.roles)
+	if !ok {
+		return
+	}
+
+	audit, err := h.service.StartAudit(r.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAuditInProgress):
+			writeError(w, http.StatusConflict, "conflict", "another audit session is in progress")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(toAuditResponse(audit))
+}
+
+type GetAuditHandler struct {
+	service *AuditService
+	roles   auth.Roles
+}
+
+func NewGetAuditHandler(service *AuditService, roles auth.Roles) *GetAuditHandler {
+	return &GetAuditHandler{service: service, roles: roles}
+}
+
+func (h *GetAuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, auditId openapi_types.UUID) {
+	if _, ok := librarianID(w, r, h.roles); !ok {
+		return
+	}
+
+	audit, err := h.service.GetAudit(r.Context(), auditId.String())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAuditNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "audit session not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(toAuditResponse(audit))
+}
+
+type ScanAuditHandler struct {
+	service *AuditService
+	roles   auth.Roles
+}
+
+func NewScanAuditHandler(service *AuditService, roles auth.Roles) *ScanAuditHandler {
+	return &ScanAuditHandler{service: service, roles: roles}
+}
+
+func (h *ScanAuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, auditId openapi_types.UUID) {
+	userID, ok := librarianID(w, r, h.roles)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	output, err := h.service.Scan(r.Context(), ScanInput{
+		AuditID: auditId.String(),
+		Code:    req.Code,
+		UserID:  userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidScanCode):
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		case errors.Is(err, ErrAuditNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "audit session not found")
+		case errors.Is(err, ErrAuditFinished):
+			writeError(w, http.StatusConflict, "conflict", "audit session is already finished")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	resp := map[string]any{
+		"result": output.Result,
+	}
+	if output.BookID != "" {
+		resp["book"] = map[string]any{
+			"id":    output.BookID,
+			"title": output.BookTitle,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type FinishAuditHandler struct {
+	service *AuditService
+	roles   auth.Roles
+}
+
+func NewFinishAuditHandler(service *AuditService, roles auth.Roles) *FinishAuditHandler {
+	return &FinishAuditHandler{service: service, roles: roles}
+}
+
+func (h *FinishAuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, auditId openapi_types.UUID) {
+	userID, ok := librarianID(w, r, h.roles)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		LostBookIDs []openapi_types.UUID `json:"lostBookIds"`
+	}
+	// The body is optional: without it no book is deleted.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+	lost := make([]string, 0, len(req.LostBookIDs))
+	for _, id := range req.LostBookIDs {
+		lost = append(lost, id.String())
+	}
+
+	audit, err := h.service.FinishAudit(r.Context(), FinishAuditInput{
+		AuditID:     auditId.String(),
+		UserID:      userID,
+		LostBookIDs: lost,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidBookIDs), errors.Is(err, domain.ErrBookNotMissing):
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		case errors.Is(err, ErrAuditNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "audit session not found")
+		case errors.Is(err, ErrAuditFinished):
+			writeError(w, http.StatusConflict, "conflict", "audit session is already finished")
+		case errors.Is(err, book.ErrBookBorrowed):
+			writeError(w, http.StatusConflict, "conflict", "a book confirmed lost has been borrowed")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(toAuditResponse(audit))
+}
+
+func toAuditResponse(audit *Audit) map[string]any {
+	report := audit.Report
+	resp := map[string]any{
+		"id":        audit.ID,
+		"status":    audit.Status,
+		"startedBy": audit.StartedBy,
+		"startedAt": audit.StartedAt,
+		"summary": map[string]any{
+			"expected":           report.Summary.Expected,
+			"seen":               report.Summary.Seen,
+			"onLoan":             report.Summary.OnLoan,
+			"missing":            report.Summary.Missing,
+			"lost":               report.Summary.Lost,
+			"foundWhileBorrowed": report.Summary.FoundWhileBorrowed,
+			"unexpected":         report.Summary.Unexpected,
+			"unknown":            report.Summary.Unknown,
+			"duplicates":         report.Summary.Duplicates,
+		},
+		"missing":            toBooksResponse(report.Missing),
+		"onLoan":             toBooksResponse(report.OnLoan),
+		"lost":               toBooksResponse(report.Lost),
+		"foundWhileBorrowed": toScansResponse(report.FoundWhileBorrowed),
+		"unexpected":         toScansResponse(report.Unexpected),
+		"unknown":            toScansResponse(report.Unknown),
+	}
+	if audit.FinishedAt != nil {
+		resp["finishedBy"] = *audit.FinishedBy
+		resp["finishedAt"] = *audit.FinishedAt
+	}
+	return resp
+}
+
+func toBooksResponse(books []domain.AuditBook) []map[string]any {
+	resp := make([]map[string]any, 0, len(books))
+	for _, b := range books {
+		item := map[string]any{
+			"id":    b.ID,
+			"title": b.Title,
+		}
+		if b.Code != "" {
+			item["code"] = b.Code
+		}
+		resp = append(resp, item)
+	}
+	return resp
+}
+
+func toScansResponse(scans []domain.Scan) []map[string]any {
+	resp := make([]map[string]any, 0, len(scans))
+	for _, s := range scans {
+		item := map[string]any{
+			"code":      s.Code,
+			"scannedAt": s.ScannedAt,
+		}
+		if s.BookID != "" {
+			item["bookId"] = s.BookID
+		}
+		resp = append(resp, item)
+	}
+	return resp
+}
+
+// librarianID returns the requesting user when they are a librarian, and writes
+// the error response otherwise.
+func librarianID(w http.ResponseWriter, r *http.Request, roles auth.Roles) (string, bool) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return "", false
+	}
+	if !roles.IsLibrarian(userID) {
+		writeError(w, http.StatusForbidden, "forbidden", "librarian role is required")
+		return "", false
+	}
+	return userID, true
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
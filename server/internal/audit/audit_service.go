@@ -0,0 +1,347 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"holocron/internal/audit/domain"
+	"holocron/internal/book"
+	bookDomain "holocron/internal/book/domain"
+	bookcode "holocron/internal/bookcode/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAuditNotFound   = errors.New("audit session not found")
+	ErrAuditInProgress = errors.New("another audit session is in progress")
+	ErrAuditFinished   = errors.New("audit session is already finished")
+	ErrInvalidBookIDs  = errors.New("book IDs must not be duplicated")
+)
+
+const (
+	AuditStatusOpen     = "open"
+	AuditStatusFinished = "finished"
+)
+
+type Audit struct {
+	ID         string
+	Status     string
+	StartedBy  string
+	StartedAt  string
+	FinishedBy *string
+	FinishedAt *string
+	Report     domain.Report
+}
+
+type ScanInput struct {
+	AuditID string
+	Code    string
+	UserID  string
+}
+
+type ScanOutput struct {
+	Result    domain.ScanResult
+	BookID    string
+	BookTitle string
+}
+
+type FinishAuditInput struct {
+	AuditID string
+	UserID  string
+	// LostBookIDs are missing books the librarian has confirmed lost. They are
+	// deleted with the lost reason.
+	LostBookIDs []string
+}
+
+// AuditService runs inventory audits. A session takes a snapshot of the books in
+// the collection when it starts, and each scan marks one of them as seen.
+type AuditService struct {
+	db      *sql.DB
+	queries *Queries
+	now     func() time.Time
+}
+
+func NewAuditService(db *sql.DB) *AuditService {
+	return &AuditService{
+		db:      db,
+		queries: New(db),
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// StartAudit opens a session. Only one session can be open at a time, since the
+// scans of concurrent sessions would count the same shelves twice.
+func (s *AuditService) StartAudit(ctx context.Context, userID string) (*Audit, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries := s.queries.WithTx(tx)
+
+	open, err := queries.CountOpenAuditSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if open > 0 {
+		return nil, ErrAuditInProgress
+	}
+
+	auditID := uuid.New().String()
+	err = queries.InsertAuditSession(ctx, InsertAuditSessionParams{
+		AuditID:   auditID,
+		StartedBy: userID,
+		StartedAt: s.now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := queries.InsertExpectedAuditBooks(ctx, auditID); err != nil {
+		return nil, err
+	}
+
+	session, err := getAudit(ctx, queries, auditID)
+	if err != nil {
+		return nil, err
+	}
+	return session, tx.Commit()
+}
+
+func (s *AuditService) GetAudit(ctx context.Context, auditID string) (*Audit, error) {
+	return getAudit(ctx, s.queries, auditID)
+}
+
+// Scan records a scanned barcode, book ID or accession number. The code is matched
+// against book IDs and against book codes, both as scanned and normalized to
+// ISBN-13. An accession number read from a label is matched against the book it
+// was assigned to.
+func (s *AuditService) Scan(ctx context.Context, input ScanInput) (*ScanOutput, error) {
+	code, err := domain.ParseScanCode(input.Code)
+	if err != nil {
+		return nil, err
+	}
+	normalized := code
+	if n, err := bookcode.NormalizeCode(code); err == nil {
+		normalized = n
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries := s.queries.WithTx(tx)
+
+	if err := ensureOpen(ctx, queries, input.AuditID); err != nil {
+		return nil, err
+	}
+	bookRef, err := resolveBookID(ctx, book.New(tx), code)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := queries.ListAuditScanCandidates(ctx, ListAuditScanCandidatesParams{
+		AuditID:        input.AuditID,
+		BookID:         bookRef,
+		Code:           sql.NullString{String: code, Valid: true},
+		NormalizedCode: sql.NullString{String: normalized, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]domain.ScanCandidate, 0, len(rows))
+	titles := make(map[string]string, len(rows))
+	for _, r := range rows {
+		candidates = append(candidates, domain.ScanCandidate{
+			BookID:   r.BookID,
+			Expected: r.Expected != 0,
+			Seen:     r.Seen != 0,
+			Borrowed: r.Borrowed != 0,
+		})
+		titles[r.BookID] = r.Title.String
+	}
+	bookID, result := domain.ResolveScan(candidates)
+
+	now := s.now().Format(time.RFC3339)
+	if result == domain.ScanResultSeen || result == domain.ScanResultBorrowed {
+		err = queries.MarkAuditBookSeen(ctx, MarkAuditBookSeenParams{
+			SeenAt:  sql.NullString{String: now, Valid: true},
+			AuditID: input.AuditID,
+			BookID:  bookID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = queries.InsertAuditScan(ctx, InsertAuditScanParams{
+		AuditID:   input.AuditID,
+		Code:      code,
+		BookID:    sql.NullString{String: bookID, Valid: bookID != ""},
+		Result:    string(result),
+		ScannedBy: input.UserID,
+		ScannedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScanOutput{Result: result, BookID: bookID, BookTitle: titles[bookID]}, tx.Commit()
+}
+
+// FinishAudit closes a session and fixes its report. The books confirmed lost
+// are deleted in the same transaction, so either all of them are deleted and the
+// session is finished, or nothing changes.
+func (s *AuditService) FinishAudit(ctx context.Context, input FinishAuditInput) (*Audit, error) {
+	seen := make(map[string]bool, len(input.LostBookIDs))
+	for _, id := range input.LostBookIDs {
+		if seen[id] {
+			return nil, ErrInvalidBookIDs
+		}
+		seen[id] = true
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries := s.queries.WithTx(tx)
+
+	if err := ensureOpen(ctx, queries, input.AuditID); err != nil {
+		return nil, err
+	}
+	books, err := loadAuditBooks(ctx, queries, input.AuditID)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := domain.FinalStatuses(books, input.LostBookIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	memo := "inventory audit " + input.AuditID
+	bookQueries := book.New(tx)
+	for _, id := range input.LostBookIDs {
+		err := book.DeleteBook(ctx, bookQueries, book.DeleteBookInput{BookID: id, Reason: "lost", Memo: &memo})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, b := range books {
+		err := queries.SetAuditBookStatus(ctx, SetAuditBookStatusParams{
+			Status:  sql.NullString{String: string(statuses[b.ID]), Valid: true},
+			AuditID: input.AuditID,
+			BookID:  b.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = queries.FinishAuditSession(ctx, FinishAuditSessionParams{
+		FinishedBy: sql.NullString{String: input.UserID, Valid: true},
+		FinishedAt: sql.NullString{String: s.now().Format(time.RFC3339), Valid: true},
+		AuditID:    input.AuditID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := getAudit(ctx, queries, input.AuditID)
+	if err != nil {
+		return nil, err
+	}
+	return session, tx.Commit()
+}
+
+// resolveBookID returns the ID of the book an accession number was assigned to,
+// or the code itself when it is not an accession number. An accession number
+// of no book resolves to nothing, so the scan finds no book.
+func resolveBookID(ctx context.Context, queries *book.Queries, code string) (string, error) {
+	number, err := bookDomain.ParseAccessionNumber(code)
+	if err != nil {
+		return code, nil
+	}
+	bookID, err := queries.GetBookIdByAccessionNumber(ctx, sql.NullString{String: string(number), Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return bookID, err
+}
+
+func ensureOpen(ctx context.Context, queries *Queries, auditID string) error {
+	row, err := queries.GetAuditSession(ctx, auditID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAuditNotFound
+		}
+		return err
+	}
+	if row.FinishedAt.Valid {
+		return ErrAuditFinished
+	}
+	return nil
+}
+
+func getAudit(ctx context.Context, queries *Queries, auditID string) (*Audit, error) {
+	row, err := queries.GetAuditSession(ctx, auditID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAuditNotFound
+		}
+		return nil, err
+	}
+	books, err := loadAuditBooks(ctx, queries, auditID)
+	if err != nil {
+		return nil, err
+	}
+	scanRows, err := queries.ListAuditScans(ctx, auditID)
+	if err != nil {
+		return nil, err
+	}
+	scans := make([]domain.Scan, 0, len(scanRows))
+	for _, r := range scanRows {
+		scans = append(scans, domain.Scan{
+			Number:    r.ScanNumber,
+			Code:      r.Code,
+			BookID:    r.BookID.String,
+			Result:    domain.ScanResult(r.Result),
+			ScannedAt: r.ScannedAt,
+		})
+	}
+
+	session := &Audit{
+		ID:        row.AuditID,
+		Status:    AuditStatusOpen,
+		StartedBy: row.StartedBy,
+		StartedAt: row.StartedAt,
+		Report:    domain.BuildReport(books, scans),
+	}
+	if row.FinishedAt.Valid {
+		session.Status = AuditStatusFinished
+		session.FinishedBy = &row.FinishedBy.String
+		session.FinishedAt = &row.FinishedAt.String
+	}
+	return session, nil
+}
+
+func loadAuditBooks(ctx context.Context, queries *Queries, auditID string) ([]domain.AuditBook, error) {
+	rows, err := queries.ListAuditBooks(ctx, auditID)
+	if err != nil {
+		return nil, err
+	}
+	books := make([]domain.AuditBook, 0, len(rows))
+	for _, r := range rows {
+		books = append(books, domain.AuditBook{
+			ID:       r.BookID,
+			Code:     r.Code.String,
+			Title:    r.Title.String,
+			Seen:     r.SeenAt.Valid,
+			Borrowed: r.Borrowed != 0,
+			Status:   domain.BookStatus(r.Status.String),
+		})
+	}
+	return books, nil
+}
@@ -0,0 +1,506 @@
+//go:build medium
+
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"holocron/internal/audit/domain"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`
+		CREATE TABLE book_events (
+			event_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			authors TEXT,
+			publisher TEXT,
+			published_date TEXT,
+			thumbnail_url TEXT,
+			delete_reason TEXT,
+			delete_memo TEXT,
+			origin TEXT,
+			cover_id TEXT,
+			tags TEXT,
+			category_id TEXT,
+			shelf_location TEXT,
+			series_id TEXT,
+			volume_number INTEGER,
+			accession_number TEXT,
+			condition TEXT,
+			condition_note TEXT,
+			lending_id TEXT,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE INDEX idx_book_events_book_id ON book_events(book_id);
+		CREATE TABLE lending_events (
+			event_id TEXT PRIMARY KEY,
+			lending_id TEXT NOT NULL,
+			book_id TEXT NOT NULL,
+			borrower_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			due_date TEXT,
+			actor_id TEXT,
+			reason TEXT,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE TABLE user_events (
+			event_id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE TABLE audit_sessions (
+			audit_id TEXT PRIMARY KEY,
+			started_by TEXT NOT NULL,
+			started_at TEXT NOT NULL,
+			finished_by TEXT,
+			finished_at TEXT
+		);
+		CREATE TABLE audit_books (
+			audit_id TEXT NOT NULL,
+			book_id TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			seen_at TEXT,
+			status TEXT,
+			PRIMARY KEY (audit_id, book_id)
+		);
+		CREATE TABLE audit_scans (
+			audit_id TEXT NOT NULL,
+			scan_number INTEGER NOT NULL,
+			code TEXT NOT NULL,
+			book_id TEXT,
+			result TEXT NOT NULL,
+			scanned_by TEXT NOT NULL,
+			scanned_at TEXT NOT NULL,
+			PRIMARY KEY (audit_id, scan_number)
+		);
+
+		-- open_lendings has the loans that have not been returned, transferred or
+		-- reported lost. due_date is the one set when the loan was opened.
+		CREATE VIEW open_lendings AS
+		SELECT
+			le.lending_id,
+			le.book_id,
+			le.borrower_id,
+			le.due_date,
+			le.occurred_at as borrowed_at
+		FROM lending_events le
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND NOT EXISTS (
+				SELECT 1
+				FROM lending_events closed
+				WHERE closed.lending_id = le.lending_id
+					AND closed.event_type IN ('returned', 'force_returned', 'transferred', 'lost')
+			);
+
+		CREATE VIEW deleted_books AS
+		SELECT book_id, MAX(occurred_at) as deleted_at
+		FROM book_events
+		WHERE event_type = 'deleted'
+		GROUP BY book_id;
+
+		-- latest_books has the latest details of each book since it was last deleted.
+		-- Events in the same second are ordered as they were recorded.
+		CREATE VIEW latest_books AS
+		SELECT book_id, code, title, authors, publisher, published_date, thumbnail_url, created_at, updated_at
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.code,
+				e1.title,
+				e1.authors,
+				e1.publisher,
+				e1.published_date,
+				e1.thumbnail_url,
+				(SELECT MIN(e_created.occurred_at)
+				 FROM book_events e_created
+				 WHERE e_created.book_id = e1.book_id
+				   AND e_created.event_type = 'created'
+				   AND e_created.occurred_at > COALESCE(d.deleted_at, '1970-01-01T00:00:00Z')
+				) as created_at,
+				e1.occurred_at as updated_at,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('created', 'updated')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- book_classifications has the latest tags_changed, category_changed and
+		-- shelf_changed event of each book, one row per event type.
+		CREATE VIEW book_classifications AS
+		SELECT book_id, event_type, tags, category_id, shelf_location
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.event_type,
+				e1.tags,
+				e1.category_id,
+				e1.shelf_location,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id, e1.event_type ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('tags_changed', 'category_changed', 'shelf_changed')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- current_lendings has the open loan of each book, with its latest due date.
+		CREATE VIEW current_lendings AS
+		SELECT lending_id, book_id, borrower_id, borrower_name, borrowed_at, due_date
+		FROM (
+			SELECT
+				ol.lending_id,
+				ol.book_id,
+				ol.borrower_id,
+				ue.name as borrower_name,
+				ol.borrowed_at,
+				(SELECT due.due_date
+				 FROM lending_events due
+				 WHERE due.lending_id = ol.lending_id
+				   AND due.event_type IN ('borrowed', 'lent', 'due_date_extended')
+				   AND due.due_date IS NOT NULL
+				 ORDER BY due.occurred_at DESC
+				 LIMIT 1
+				) as due_date,
+				ROW_NUMBER() OVER (PARTITION BY ol.book_id ORDER BY ol.borrowed_at DESC) as rn
+			FROM open_lendings ol
+			INNER JOIN user_events ue ON ue.user_id = ol.borrower_id AND ue.event_type = 'created'
+			LEFT JOIN deleted_books d ON ol.book_id = d.book_id
+			WHERE d.deleted_at IS NULL OR ol.borrowed_at > d.deleted_at
+		)
+		WHERE rn = 1;
+
+		-- borrow_counts counts the loans of each book since it was last deleted.
+		CREATE VIEW borrow_counts AS
+		SELECT le.book_id, COUNT(*) as borrow_count
+		FROM lending_events le
+		LEFT JOIN deleted_books d ON le.book_id = d.book_id
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND (d.deleted_at IS NULL OR le.occurred_at > d.deleted_at)
+		GROUP BY le.book_id;
+
+		-- book_list_items joins the current state of each book for the book list, the
+		-- search and the facets, which filter it with the same conditions.
+		CREATE VIEW book_list_items AS
+		SELECT
+			lb.book_id,
+			lb.code,
+			lb.title,
+			lb.authors,
+			lb.publisher,
+			lb.published_date,
+			lb.thumbnail_url,
+			lb.created_at,
+			lb.updated_at,
+			cl.lending_id,
+			cl.borrower_id,
+			cl.borrower_name,
+			cl.borrowed_at,
+			cl.due_date,
+			bt.tags,
+			bcat.category_id,
+			ce.name as category_name,
+			ce.code as category_code,
+			bs.shelf_location,
+			COALESCE(bc.borrow_count, 0) as borrow_count
+		FROM latest_books lb
+		LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id
+		LEFT JOIN book_classifications bt ON lb.book_id = bt.book_id AND bt.event_type = 'tags_changed'
+		LEFT JOIN book_classifications bcat ON lb.book_id = bcat.book_id AND bcat.event_type = 'category_changed'
+		LEFT JOIN book_classifications bs ON lb.book_id = bs.book_id AND bs.event_type = 'shelf_changed'
+		LEFT JOIN category_events ce ON bcat.category_id = ce.category_id AND ce.event_type = 'created'
+		LEFT JOIN borrow_counts bc ON lb.book_id = bc.book_id;
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func insertBook(t *testing.T, db *sql.DB, code string) string {
+	t.Helper()
+	bookID := uuid.New().String()
+	_, err := db.Exec(`
+		INSERT INTO book_events (event_id, book_id, event_type, code, title, authors, occurred_at)
+		VALUES (?, ?, 'created', ?, ?, '["author"]', ?)
+	`, uuid.New().String(), bookID, code, "title "+bookID, time.Now().UTC().Add(-time.Hour).Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("failed to insert book: %v", err)
+	}
+	return bookID
+}
+
+func borrowBook(t *testing.T, db *sql.DB, bookID string) {
+	t.Helper()
+	_, err := db.Exec(`
+		INSERT INTO lending_events (event_id, lending_id, book_id, borrower_id, event_type, due_date, occurred_at)
+		VALUES (?, ?, ?, ?, 'borrowed', ?, ?)
+	`, uuid.New().String(), uuid.New().String(), bookID, uuid.New().String(),
+		time.Now().UTC().Add(14*24*time.Hour).Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("failed to insert lending: %v", err)
+	}
+}
+
+func scan(t *testing.T, service *AuditService, auditID string, code string) *ScanOutput {
+	t.Helper()
+	output, err := service.Scan(context.Background(), ScanInput{AuditID: auditID, Code: code, UserID: "librarian"})
+	if err != nil {
+		t.Fatalf("failed to scan %s: %v", code, err)
+	}
+	return output
+}
+
+func TestStartAudit_WithBooks_SnapshotsCollectionAsMissing(t *testing.T) {
+	db := setupTestDB(t)
+	insertBook(t, db, "9784873119045")
+	insertBook(t, db, "9784297127831")
+	service := NewAuditService(db)
+
+	audit, err := service.StartAudit(context.Background(), "librarian")
+	if err != nil {
+		t.Fatalf("failed to start audit: %v", err)
+	}
+
+	if audit.Status != AuditStatusOpen || audit.StartedBy != "librarian" {
+		t.Errorf("unexpected session: %+v", audit)
+	}
+	if audit.Report.Summary.Expected != 2 || audit.Report.Summary.Missing != 2 {
+		t.Errorf("expected 2 missing books, got %+v", audit.Report.Summary)
+	}
+}
+
+func TestStartAudit_WithOpenSession_ReturnsInProgress(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewAuditService(db)
+	if _, err := service.StartAudit(context.Background(), "librarian"); err != nil {
+		t.Fatalf("failed to start audit: %v", err)
+	}
+
+	_, err := service.StartAudit(context.Background(), "librarian")
+
+	if !errors.Is(err, ErrAuditInProgress) {
+		t.Errorf("expected ErrAuditInProgress, got %v", err)
+	}
+}
+
+func TestScan_WithCopiesSharingCode_CountsEachCopyOnce(t *testing.T) {
+	db := setupTestDB(t)
+	first := insertBook(t, db, "9784873119045")
+	second := insertBook(t, db, "9784873119045")
+	service := NewAuditService(db)
+	audit, err := service.StartAudit(context.Background(), "librarian")
+	if err != nil {
+		t.Fatalf("failed to start audit: %v", err)
+	}
+
+	results := []domain.ScanResult{
+		scan(t, service, audit.ID, "9784873119045").Result,
+		// Scanned as ISBN-10, normalized to the same ISBN-13.
+		scan(t, service, audit.ID, "4873119049").Result,
+		scan(t, service, audit.ID, "9784873119045").Result,
+	}
+
+	expected := []domain.ScanResult{domain.ScanResultSeen, domain.ScanResultSeen, domain.ScanResultDuplicate}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("scan %d: expected %s, got %s", i, expected[i], results[i])
+		}
+	}
+	got, err := service.GetAudit(context.Background(), audit.ID)
+	if err != nil {
+		t.Fatalf("failed to get audit: %v", err)
+	}
+	if got.Report.Summary.Seen != 2 || got.Report.Summary.Missing != 0 || got.Report.Summary.Duplicates != 1 {
+		t.Errorf("unexpected summary for %s and %s: %+v", first, second, got.Report.Summary)
+	}
+}
+
+func TestScan_WithBorrowedOrUnknownBooks_ReportsThem(t *testing.T) {
+	db := setupTestDB(t)
+	borrowed := insertBook(t, db, "9784297127831")
+	borrowBook(t, db, borrowed)
+	unseen := insertBook(t, db, "9784873119045")
+	borrowBook(t, db, unseen)
+	service := NewAuditService(db)
+	audit, err := service.StartAudit(context.Background(), "librarian")
+	if err != nil {
+		t.Fatalf("failed to start audit: %v", err)
+	}
+	added := insertBook(t, db, "9784065123454")
+
+	if out := scan(t, service, audit.ID, borrowed); out.Result != domain.ScanResultBorrowed || out.BookID != borrowed {
+		t.Errorf("expected borrowed %s, got %+v", borrowed, out)
+	}
+	if out := scan(t, service, audit.ID, "9784065123454"); out.Result != domain.ScanResultUnexpected || out.BookID != added {
+		t.Errorf("expected unexpected %s, got %+v", added, out)
+	}
+	if out := scan(t, service, audit.ID, "4901234567894"); out.Result != domain.ScanResultUnknown || out.BookID != "" {
+		t.Errorf("expected unknown, got %+v", out)
+	}
+
+	got, err := service.GetAudit(context.Background(), audit.ID)
+	if err != nil {
+		t.Fatalf("failed to get audit: %v", err)
+	}
+	summary := got.Report.Summary
+	if summary.Seen != 1 || summary.OnLoan != 1 || summary.Missing != 0 ||
+		summary.FoundWhileBorrowed != 1 || summary.Unexpected != 1 || summary.Unknown != 1 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if len(got.Report.OnLoan) != 1 || got.Report.OnLoan[0].ID != unseen {
+		t.Errorf("expected %s on loan, got %+v", unseen, got.Report.OnLoan)
+	}
+}
+
+func TestScan_WithAccessionNumber_CountsTheNumberedBook(t *testing.T) {
+	db := setupTestDB(t)
+	numbered := insertBook(t, db, "9784873119045")
+	insertBook(t, db, "9784873119045")
+	_, err := db.Exec(`
+		INSERT INTO book_events (event_id, book_id, event_type, accession_number, occurred_at)
+		VALUES (?, ?, 'accession_assigned', 'HC-000001', ?)
+	`, uuid.New().String(), numbered, time.Now().UTC().Add(-time.Minute).Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("failed to assign accession number: %v", err)
+	}
+	service := NewAuditService(db)
+	audit, err := service.StartAudit(context.Background(), "librarian")
+	if err != nil {
+		t.Fatalf("failed to start audit: %v", err)
+	}
+
+	// The label is read in lower case, and a number of no book is unknown.
+	if out := scan(t, service, audit.ID, "hc-000001"); out.Result != domain.ScanResultSeen || out.BookID != numbered {
+		t.Errorf("expected seen %s, got %+v", numbered, out)
+	}
+	if out := scan(t, service, audit.ID, "HC-000002"); out.Result != domain.ScanResultUnknown || out.BookID != "" {
+		t.Errorf("expected unknown, got %+v", out)
+	}
+}
+
+func TestScan_WithUnknownSession_ReturnsNotFound(t *testing.T) {
+	service := NewAuditService(setupTestDB(t))
+
+	_, err := service.Scan(context.Background(), ScanInput{AuditID: uuid.New().String(), Code: "9784873119045", UserID: "librarian"})
+
+	if !errors.Is(err, ErrAuditNotFound) {
+		t.Errorf("expected ErrAuditNotFound, got %v", err)
+	}
+}
+
+func TestFinishAudit_WithLostBooks_DeletesThemAndFixesReport(t *testing.T) {
+	db := setupTestDB(t)
+	lost := insertBook(t, db, "9784873119045")
+	missing := insertBook(t, db, "9784297127831")
+	seen := insertBook(t, db, "9784065123454")
+	service := NewAuditService(db)
+	audit, err := service.StartAudit(context.Background(), "librarian")
+	if err != nil {
+		t.Fatalf("failed to start audit: %v", err)
+	}
+	scan(t, service, audit.ID, seen)
+
+	finished, err := service.FinishAudit(context.Background(), FinishAuditInput{
+		AuditID:     audit.ID,
+		UserID:      "admin",
+		LostBookIDs: []string{lost},
+	})
+	if err != nil {
+		t.Fatalf("failed to finish audit: %v", err)
+	}
+
+	if finished.Status != AuditStatusFinished || finished.FinishedBy == nil || *finished.FinishedBy != "admin" {
+		t.Errorf("unexpected session: %+v", finished)
+	}
+	if len(finished.Report.Lost) != 1 || finished.Report.Lost[0].ID != lost {
+		t.Errorf("expected %s lost, got %+v", lost, finished.Report.Lost)
+	}
+	if len(finished.Report.Missing) != 1 || finished.Report.Missing[0].ID != missing {
+		t.Errorf("expected %s missing, got %+v", missing, finished.Report.Missing)
+	}
+	var reason string
+	err = db.QueryRow(`SELECT delete_reason FROM book_events WHERE book_id = ? AND event_type = 'deleted'`, lost).Scan(&reason)
+	if err != nil || reason != "lost" {
+		t.Errorf("expected book deleted as lost, got %q, %v", reason, err)
+	}
+
+	if _, err := service.Scan(context.Background(), ScanInput{AuditID: audit.ID, Code: seen, UserID: "librarian"}); !errors.Is(err, ErrAuditFinished) {
+		t.Errorf("expected ErrAuditFinished, got %v", err)
+	}
+	if _, err := service.StartAudit(context.Background(), "librarian"); err != nil {
+		t.Errorf("expected a new session to start, got %v", err)
+	}
+}
+
+func TestFinishAudit_WithSeenBookAsLost_ChangesNothing(t *testing.T) {
+	db := setupTestDB(t)
+	lost := insertBook(t, db, "9784873119045")
+	seen := insertBook(t, db, "9784297127831")
+	service := NewAuditService(db)
+	audit, err := service.StartAudit(context.Background(), "librarian")
+	if err != nil {
+		t.Fatalf("failed to start audit: %v", err)
+	}
+	scan(t, service, audit.ID, seen)
+
+	_, err = service.FinishAudit(context.Background(), FinishAuditInput{
+		AuditID:     audit.ID,
+		UserID:      "librarian",
+		LostBookIDs: []string{lost, seen},
+	})
+
+	if !errors.Is(err, domain.ErrBookNotMissing) {
+		t.Fatalf("expected ErrBookNotMissing, got %v", err)
+	}
+	var deleted int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM book_events WHERE event_type = 'deleted'`).Scan(&deleted); err != nil || deleted != 0 {
+		t.Errorf("expected no deletion, got %d, %v", deleted, err)
+	}
+	got, err := service.GetAudit(context.Background(), audit.ID)
+	if err != nil || got.Status != AuditStatusOpen {
+		t.Errorf("expected session to stay open, got %+v, %v", got, err)
+	}
+}
+
+func TestFinishAudit_WithDuplicatedBookIDs_ReturnsInvalid(t *testing.T) {
+	db := setupTestDB(t)
+	bookID := insertBook(t, db, "9784873119045")
+	service := NewAuditService(db)
+	audit, err := service.StartAudit(context.Background(), "librarian")
+	if err != nil {
+		t.Fatalf("failed to start audit: %v", err)
+	}
+
+	_, err = service.FinishAudit(context.Background(), FinishAuditInput{
+		AuditID:     audit.ID,
+		UserID:      "librarian",
+		LostBookIDs: []string{bookID, bookID},
+	})
+
+	if !errors.Is(err, ErrInvalidBookIDs) {
+		t.Errorf("expected ErrInvalidBookIDs, got %v", err)
+	}
+}
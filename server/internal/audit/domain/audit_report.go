@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrBookNotMissing = errors.New("only missing books can be deleted")
+
+type BookStatus string
+
+const (
+	BookStatusSeen    BookStatus = "seen"
+	BookStatusOnLoan  BookStatus = "on_loan"
+	BookStatusMissing BookStatus = "missing"
+	// BookStatusLost is a missing book that was deleted as lost when the session finished.
+	BookStatusLost BookStatus = "lost"
+)
+
+// AuditBook is a book that was in the collection when the session started.
+// Status is recorded when the session finishes; until then it follows the
+// scans and the current lendings.
+type AuditBook struct {
+	ID       string
+	Code     string
+	Title    string
+	Seen     bool
+	Borrowed bool
+	Status   BookStatus
+}
+
+func (b AuditBook) CurrentStatus() BookStatus {
+	switch {
+	case b.Status != "":
+		return b.Status
+	case b.Seen:
+		return BookStatusSeen
+	case b.Borrowed:
+		return BookStatusOnLoan
+	default:
+		return BookStatusMissing
+	}
+}
+
+type Scan struct {
+	Number    int64
+	Code      string
+	BookID    string
+	Result    ScanResult
+	ScannedAt string
+}
+
+type ReportSummary struct {
+	Expected           int
+	Seen               int
+	OnLoan             int
+	Missing            int
+	Lost               int
+	FoundWhileBorrowed int
+	Unexpected         int
+	Unknown            int
+	Duplicates         int
+}
+
+// Report reconciles the books expected on the shelves with the scans. Books that
+// were not seen but are lent out are not missing.
+type Report struct {
+	Summary            ReportSummary
+	Missing            []AuditBook
+	OnLoan             []AuditBook
+	Lost               []AuditBook
+	FoundWhileBorrowed []Scan
+	Unexpected         []Scan
+	Unknown            []Scan
+}
+
+func BuildReport(books []AuditBook, scans []Scan) Report {
+	report := Report{
+		Missing:            []AuditBook{},
+		OnLoan:             []AuditBook{},
+		Lost:               []AuditBook{},
+		FoundWhileBorrowed: []Scan{},
+		Unexpected:         []Scan{},
+		Unknown:            []Scan{},
+	}
+	report.Summary.Expected = len(books)
+	for _, b := range books {
+		switch b.CurrentStatus() {
+		case BookStatusSeen:
+			report.Summary.Seen++
+		case BookStatusOnLoan:
+			report.OnLoan = append(report.OnLoan, b)
+		case BookStatusMissing:
+			report.Missing = append(report.Missing, b)
+		case BookStatusLost:
+			report.Lost = append(report.Lost, b)
+		}
+	}
+	for _, s := range scans {
+		switch s.Result {
+		case ScanResultBorrowed:
+			report.FoundWhileBorrowed = append(report.FoundWhileBorrowed, s)
+		case ScanResultUnexpected:
+			report.Unexpected = append(report.Unexpected, s)
+		case ScanResultUnknown:
+			report.Unknown = append(report.Unknown, s)
+		case ScanResultDuplicate:
+			report.Summary.Duplicates++
+		}
+	}
+	report.Summary.OnLoan = len(report.OnLoan)
+	report.Summary.Missing = len(report.Missing)
+	report.Summary.Lost = len(report.Lost)
+	report.Summary.FoundWhileBorrowed = len(report.FoundWhileBorrowed)
+	report.Summary.Unexpected = len(report.Unexpected)
+	report.Summary.Unknown = len(report.Unknown)
+	return report
+}
+
+// FinalStatuses fixes the status of every book when the session finishes. The
+// books listed in lost must be missing; they are recorded as lost.
+func FinalStatuses(books []AuditBook, lost []string) (map[string]BookStatus, error) {
+	statuses := make(map[string]BookStatus, len(books))
+	for _, b := range books {
+		statuses[b.ID] = b.CurrentStatus()
+	}
+	for _, id := range lost {
+		if statuses[id] != BookStatusMissing {
+			return nil, fmt.Errorf("%w: %s", ErrBookNotMissing, id)
+		}
+		statuses[id] = BookStatusLost
+	}
+	return statuses, nil
+}
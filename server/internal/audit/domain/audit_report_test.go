@@ -0,0 +1,94 @@
+//go:build small
+
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestBuildReport_WithBooks_CountsEveryBookOnce(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("seen, on loan and missing add up to expected", prop.ForAll(
+		func(seen []bool, borrowed []bool) bool {
+			books := make([]AuditBook, 0, len(seen))
+			for i := range seen {
+				books = append(books, AuditBook{
+					ID:       fmt.Sprint(i),
+					Seen:     seen[i],
+					Borrowed: i < len(borrowed) && borrowed[i],
+				})
+			}
+			s := BuildReport(books, nil).Summary
+			return s.Expected == len(books) && s.Seen+s.OnLoan+s.Missing == s.Expected
+		},
+		gen.SliceOf(gen.Bool()),
+		gen.SliceOf(gen.Bool()),
+	))
+	properties.TestingRun(t)
+}
+
+func TestBuildReport_WithScans_GroupsThemByResult(t *testing.T) {
+	scans := []Scan{
+		{Number: 1, Code: "a", Result: ScanResultSeen},
+		{Number: 2, Code: "a", Result: ScanResultDuplicate},
+		{Number: 3, Code: "b", Result: ScanResultBorrowed},
+		{Number: 4, Code: "c", Result: ScanResultUnexpected},
+		{Number: 5, Code: "d", Result: ScanResultUnknown},
+		{Number: 6, Code: "d", Result: ScanResultUnknown},
+	}
+
+	report := BuildReport(nil, scans)
+
+	s := report.Summary
+	if s.Duplicates != 1 || s.FoundWhileBorrowed != 1 || s.Unexpected != 1 || s.Unknown != 2 {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+	if report.Missing == nil || report.OnLoan == nil || report.Lost == nil {
+		t.Errorf("expected empty lists, got %+v", report)
+	}
+}
+
+func TestFinalStatuses_WithMissingBookAsLost_RecordsLost(t *testing.T) {
+	books := []AuditBook{
+		{ID: "seen", Seen: true},
+		{ID: "on-loan", Borrowed: true},
+		{ID: "missing"},
+		{ID: "lost"},
+	}
+
+	statuses, err := FinalStatuses(books, []string{"lost"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]BookStatus{
+		"seen":    BookStatusSeen,
+		"on-loan": BookStatusOnLoan,
+		"missing": BookStatusMissing,
+		"lost":    BookStatusLost,
+	}
+	for id, status := range expected {
+		if statuses[id] != status {
+			t.Errorf("%s: expected %s, got %s", id, status, statuses[id])
+		}
+	}
+}
+
+func TestFinalStatuses_WithBookNotMissing_ReturnsError(t *testing.T) {
+	books := []AuditBook{
+		{ID: "seen", Seen: true},
+		{ID: "on-loan", Borrowed: true},
+	}
+
+	for _, id := range []string{"seen", "on-loan", "unknown"} {
+		if _, err := FinalStatuses(books, []string{id}); !errors.Is(err, ErrBookNotMissing) {
+			t.Errorf("%s: expected ErrBookNotMissing, got %v", id, err)
+		}
+	}
+}
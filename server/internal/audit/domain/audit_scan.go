@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidScanCode = errors.New("code must be 1-100 characters")
+
+type ScanResult string
+
+const (
+	// ScanResultSeen marks an expected book as found on the shelf.
+	ScanResultSeen ScanResult = "seen"
+	// ScanResultBorrowed marks an expected book as found on the shelf although it is lent out.
+	ScanResultBorrowed ScanResult = "borrowed"
+	// ScanResultDuplicate is a scan of a book that has already been seen in the session.
+	ScanResultDuplicate ScanResult = "duplicate"
+	// ScanResultUnexpected is a scan of a book registered after the session started.
+	ScanResultUnexpected ScanResult = "unexpected"
+	// ScanResultUnknown is a scan that matches no book in the collection.
+	ScanResultUnknown ScanResult = "unknown"
+)
+
+func ParseScanCode(s string) (string, error) {
+	code := strings.TrimSpace(s)
+	if code == "" || utf8.RuneCountInString(code) > 100 {
+		return "", ErrInvalidScanCode
+	}
+	return code, nil
+}
+
+// ScanCandidate is a book a scanned code may refer to. Several copies of a title
+// share an ISBN, so a code can match more than one book.
+type ScanCandidate struct {
+	BookID   string
+	Expected bool
+	Seen     bool
+	Borrowed bool
+}
+
+// ResolveScan decides which copy a scan counts for and what it found. A scan
+// counts for a copy that has not been seen yet, preferring one that is not lent
+// out, so that scanning each copy of a title once accounts for all of them.
+// The returned book ID is empty when the scan matches no book.
+func ResolveScan(candidates []ScanCandidate) (string, ScanResult) {
+	for _, c := range candidates {
+		if c.Expected && !c.Seen && !c.Borrowed {
+			return c.BookID, ScanResultSeen
+		}
+	}
+	for _, c := range candidates {
+		if c.Expected && !c.Seen {
+			return c.BookID, ScanResultBorrowed
+		}
+	}
+	for _, c := range candidates {
+		if !c.Expected {
+			return c.BookID, ScanResultUnexpected
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0].BookID, ScanResultDuplicate
+	}
+	return "", ScanResultUnknown
+}
@@ -0,0 +1,92 @@
+//go:build small
+
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestParseScanCode_WithSurroundingSpaces_TrimsThem(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("trimmed code is returned", prop.ForAll(
+		func(code string) bool {
+			got, err := ParseScanCode("  " + code + "\t")
+			return err == nil && got == code
+		},
+		gen.AlphaString().SuchThat(func(s string) bool { return s != "" && len(s) <= 100 }),
+	))
+	properties.TestingRun(t)
+}
+
+func TestParseScanCode_WithInvalidLength_ReturnsError(t *testing.T) {
+	for _, code := range []string{"", "   ", strings.Repeat("あ", 101)} {
+		if _, err := ParseScanCode(code); !errors.Is(err, ErrInvalidScanCode) {
+			t.Errorf("%q: expected ErrInvalidScanCode, got %v", code, err)
+		}
+	}
+}
+
+func TestResolveScan_WithCandidates_PrefersUnseenCopyOnShelf(t *testing.T) {
+	cases := []struct {
+		name       string
+		candidates []ScanCandidate
+		bookID     string
+		result     ScanResult
+	}{
+		{
+			name:   "no candidates",
+			result: ScanResultUnknown,
+		},
+		{
+			name: "unseen copy on the shelf wins over a borrowed one",
+			candidates: []ScanCandidate{
+				{BookID: "a", Expected: true, Borrowed: true},
+				{BookID: "b", Expected: true, Seen: true},
+				{BookID: "c", Expected: true},
+			},
+			bookID: "c",
+			result: ScanResultSeen,
+		},
+		{
+			name: "borrowed copy found on the shelf",
+			candidates: []ScanCandidate{
+				{BookID: "a", Expected: true, Seen: true},
+				{BookID: "b", Expected: true, Borrowed: true},
+			},
+			bookID: "b",
+			result: ScanResultBorrowed,
+		},
+		{
+			name: "book registered after the session started",
+			candidates: []ScanCandidate{
+				{BookID: "a", Expected: true, Seen: true},
+				{BookID: "b"},
+			},
+			bookID: "b",
+			result: ScanResultUnexpected,
+		},
+		{
+			name: "every copy already seen",
+			candidates: []ScanCandidate{
+				{BookID: "a", Expected: true, Seen: true},
+				{BookID: "b", Expected: true, Seen: true, Borrowed: true},
+			},
+			bookID: "a",
+			result: ScanResultDuplicate,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bookID, result := ResolveScan(tc.candidates)
+			if bookID != tc.bookID || result != tc.result {
+				t.Errorf("expected (%q, %s), got (%q, %s)", tc.bookID, tc.result, bookID, result)
+			}
+		})
+	}
+}
@@ -10,7 +10,7 @@ import (
 func AuthMiddleware(fa FirebaseAuth) api.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method == http.MethodPost && r.URL.Path == "/users" {
+			if isPublicRoute(r) {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -40,6 +40,22 @@ func AuthMiddleware(fa FirebaseAuth) api.MiddlewareFunc {
 	}
 }
 
+// isPublicRoute reports whether the route is served without a token.
+// Covers are referenced from img tags, which cannot send the Authorization header.
+// Calendar feeds are fetched by calendar apps and carry their own secret token.
+func isPublicRoute(r *http.Request) bool {
+	if r.Method == http.MethodPost && r.URL.Path == "/users" {
+		return true
+	}
+	if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/books/") && strings.HasSuffix(r.URL.Path, "/cover") {
+		return true
+	}
+	if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/feeds/") {
+		return true
+	}
+	return false
+}
+
 func writeUnauthorized(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
@@ -0,0 +1,35 @@
+package auth
+
+import "strings"
+
+// Roles holds the users allowed to perform administrative operations such as backup and restore,
+// and the librarians who manage the collection, such as running inventory audits.
+type Roles struct {
+	admins     map[string]bool
+	librarians map[string]bool
+}
+
+// NewRoles builds roles from comma separated lists of user IDs, as set in ADMIN_USER_IDS
+// and LIBRARIAN_USER_IDS.
+func NewRoles(adminUserIDs string, librarianUserIDs string) Roles {
+	return Roles{admins: parseUserIDs(adminUserIDs), librarians: parseUserIDs(librarianUserIDs)}
+}
+
+func parseUserIDs(userIDs string) map[string]bool {
+	ids := map[string]bool{}
+	for _, id := range strings.Split(userIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+func (r Roles) IsAdmin(userID string) bool {
+	return r.admins[userID]
+}
+
+// IsLibrarian reports whether the user can act as a librarian. Admins are librarians too.
+func (r Roles) IsLibrarian(userID string) bool {
+	return r.librarians[userID] || r.admins[userID]
+}
@@ -0,0 +1,132 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"holocron/internal/api"
+	"holocron/internal/auth"
+	"holocron/internal/backup/domain"
+)
+
+const MaxRestoreBytes = 1 << 30
+
+type BackupHandler struct {
+	service *BackupService
+	roles   auth.Roles
+}
+
+func NewBackupHandler(service *BackupService, roles auth.Roles) *BackupHandler {
+	return &BackupHandler{service: service, roles: roles}
+}
+
+func (h *BackupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !isAdmin(r, h.roles) {
+		writeError(w, http.StatusForbidden, "forbidden", "admin role is required")
+		return
+	}
+
+	filename := fmt.Sprintf("holocron-backup-%s.jsonl", time.Now().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := h.service.Backup(r.Context(), w); err != nil {
+		// The status has already been sent. Abort the connection so the client
+		// does not mistake the truncated body for a complete backup.
+		panic(http.ErrAbortHandler)
+	}
+}
+
+type RestoreHandler struct {
+	service *RestoreService
+	roles   auth.Roles
+}
+
+func NewRestoreHandler(service *RestoreService, roles auth.Roles) *RestoreHandler {
+	return &RestoreHandler{service: service, roles: roles}
+}
+
+func (h *RestoreHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.PostAdminRestoreParams) {
+	if !isAdmin(r, h.roles) {
+		writeError(w, http.StatusForbidden, "forbidden", "admin role is required")
+		return
+	}
+
+	// The backup is read twice, once to validate and once to write, so it is spooled to a file.
+	file, err := os.CreateTemp("", "holocron-restore-*.jsonl")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	if _, err := io.Copy(file, http.MaxBytesReader(w, r.Body, MaxRestoreBytes)); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "payload_too_large", "backup must not exceed 1GB")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	input := RestoreInput{Backup: file}
+	if params.Replace != nil {
+		input.Replace = *params.Replace
+	}
+	output, err := h.service.Restore(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidBackup):
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		case errors.Is(err, ErrDatabaseNotEmpty):
+			writeError(w, http.StatusConflict, "conflict", "database already has events; set replace=true to overwrite them")
+		case errors.Is(err, ErrAuditInProgress):
+			writeError(w, http.StatusConflict, "conflict", "an inventory audit is in progress; finish it before restoring")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"schemaVersion":      output.SchemaVersion,
+		"backupCreatedAt":    output.BackupCreatedAt,
+		"userEvents":         output.Counts.UserEvents,
+		"categoryEvents":     output.Counts.CategoryEvents,
+		"seriesEvents":       output.Counts.SeriesEvents,
+		"bookEvents":         output.Counts.BookEvents,
+		"lendingEvents":      output.Counts.LendingEvents,
+		"coverImages":        output.Counts.CoverImages,
+		"rebuiltProjections": output.RebuiltProjections,
+	})
+}
+
+func isAdmin(r *http.Request, roles auth.Roles) bool {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	return ok && roles.IsAdmin(userID)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,301 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"strings"
+	"time"
+
+	"holocron/internal/backup/domain"
+)
+
+const defaultBackupPageSize = 1000
+
+// uploadedCoverPrefix starts the cover_images source of the covers users
+// uploaded, followed by the cover ID of their cover_changed event.
+const uploadedCoverPrefix = "upload:"
+
+type BackupService struct {
+	db       *sql.DB
+	queries  *Queries
+	now      func() time.Time
+	pageSize int
+}
+
+func NewBackupService(db *sql.DB) *BackupService {
+	return &BackupService{
+		db:       db,
+		queries:  New(db),
+		now:      func() time.Time { return time.Now().UTC() },
+		pageSize: defaultBackupPageSize,
+	}
+}
+
+// Backup writes every user, category, series, book and lending event and the
+// uploaded cover images to w as JSON Lines, streaming page by page. Every table
+// is read in one transaction, so that a backup taken while events are written
+// never has an event that refers to a user or book missing from it.
+func (s *BackupService) Backup(ctx context.Context, w io.Writer) (domain.Counts, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return domain.Counts{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries := s.queries.WithTx(tx)
+
+	writer := domain.NewWriter(w)
+	flusher, _ := w.(interface{ Flush() })
+	flush := func() error {
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	if err := writer.WriteHeader(s.now()); err != nil {
+		return domain.Counts{}, err
+	}
+
+	err = dumpTable(ctx, s.pageSize,
+		func(ctx context.Context, after string, limit int64) ([]UserEvent, error) {
+			return queries.ListUserEventsAfter(ctx, ListUserEventsAfterParams{OccurredAt: after, Limit: limit})
+		},
+		queries.ListUserEventsAt,
+		func(e UserEvent) string { return e.OccurredAt },
+		func(e UserEvent) error {
+			return writer.WriteUserEvent(domain.UserEvent{
+				EventID:    e.EventID,
+				UserID:     e.UserID,
+				EventType:  e.EventType,
+				Name:       e.Name,
+				OccurredAt: e.OccurredAt,
+			})
+		},
+		flush,
+	)
+	if err != nil {
+		return domain.Counts{}, err
+	}
+
+	err = dumpTable(ctx, s.pageSize,
+		func(ctx context.Context, after string, limit int64) ([]CategoryEvent, error) {
+			return queries.ListCategoryEventsAfter(ctx, ListCategoryEventsAfterParams{OccurredAt: after, Limit: limit})
+		},
+		queries.ListCategoryEventsAt,
+		func(e CategoryEvent) string { return e.OccurredAt },
+		func(e CategoryEvent) error {
+			return writer.WriteCategoryEvent(domain.CategoryEvent{
+				EventID:    e.EventID,
+				CategoryID: e.CategoryID,
+				EventType:  e.EventType,
+				ParentID:   nullStringToPtr(e.ParentID),
+				Code:       nullStringToPtr(e.Code),
+				Name:       e.Name,
+				OccurredAt: e.OccurredAt,
+			})
+		},
+		flush,
+	)
+	if err != nil {
+		return domain.Counts{}, err
+	}
+
+	err = dumpTable(ctx, s.pageSize,
+		func(ctx context.Context, after string, limit int64) ([]SeriesEvent, error) {
+			return queries.ListSeriesEventsAfter(ctx, ListSeriesEventsAfterParams{OccurredAt: after, Limit: limit})
+		},
+		queries.ListSeriesEventsAt,
+		func(e SeriesEvent) string { return e.OccurredAt },
+		func(e SeriesEvent) error {
+			return writer.WriteSeriesEvent(domain.SeriesEvent{
+				EventID:    e.EventID,
+				SeriesID:   e.SeriesID,
+				EventType:  e.EventType,
+				Title:      e.Title,
+				OccurredAt: e.OccurredAt,
+			})
+		},
+		flush,
+	)
+	if err != nil {
+		return domain.Counts{}, err
+	}
+
+	err = dumpTable(ctx, s.pageSize,
+		func(ctx context.Context, after string, limit int64) ([]BookEvent, error) {
+			return queries.ListBookEventsAfter(ctx, ListBookEventsAfterParams{OccurredAt: after, Limit: limit})
+		},
+		queries.ListBookEventsAt,
+		func(e BookEvent) string { return e.OccurredAt },
+		func(e BookEvent) error {
+			return writer.WriteBookEvent(domain.BookEvent{
+				EventID:         e.EventID,
+				BookID:          e.BookID,
+				EventType:       e.EventType,
+				Code:            nullStringToPtr(e.Code),
+				Title:           nullStringToPtr(e.Title),
+				Authors:         nullStringToPtr(e.Authors),
+				Publisher:       nullStringToPtr(e.Publisher),
+				PublishedDate:   nullStringToPtr(e.PublishedDate),
+				ThumbnailURL:    nullStringToPtr(e.ThumbnailUrl),
+				DeleteReason:    nullStringToPtr(e.DeleteReason),
+				DeleteMemo:      nullStringToPtr(e.DeleteMemo),
+				Origin:          nullStringToPtr(e.Origin),
+				CoverID:         nullStringToPtr(e.CoverID),
+				Tags:            nullStringToPtr(e.Tags),
+				CategoryID:      nullStringToPtr(e.CategoryID),
+				ShelfLocation:   nullStringToPtr(e.ShelfLocation),
+				SeriesID:        nullStringToPtr(e.SeriesID),
+				VolumeNumber:    nullInt64ToPtr(e.VolumeNumber),
+				AccessionNumber: nullStringToPtr(e.AccessionNumber),
+				Condition:       nullStringToPtr(e.Condition),
+				ConditionNote:   nullStringToPtr(e.ConditionNote),
+				LendingID:       nullStringToPtr(e.LendingID),
+				OccurredAt:      e.OccurredAt,
+			})
+		},
+		flush,
+	)
+	if err != nil {
+		return domain.Counts{}, err
+	}
+
+	err = dumpTable(ctx, s.pageSize,
+		func(ctx context.Context, after string, limit int64) ([]LendingEvent, error) {
+			return queries.ListLendingEventsAfter(ctx, ListLendingEventsAfterParams{OccurredAt: after, Limit: limit})
+		},
+		queries.ListLendingEventsAt,
+		func(e LendingEvent) string { return e.OccurredAt },
+		func(e LendingEvent) error {
+			return writer.WriteLendingEvent(domain.LendingEvent{
+				EventID:    e.EventID,
+				LendingID:  e.LendingID,
+				BookID:     e.BookID,
+				BorrowerID: e.BorrowerID,
+				EventType:  e.EventType,
+				DueDate:    nullStringToPtr(e.DueDate),
+				ActorID:    nullStringToPtr(e.ActorID),
+				Reason:     nullStringToPtr(e.Reason),
+				OccurredAt: e.OccurredAt,
+			})
+		},
+		flush,
+	)
+	if err != nil {
+		return domain.Counts{}, err
+	}
+
+	if err := s.dumpUploadedCovers(ctx, queries, writer, flush); err != nil {
+		return domain.Counts{}, err
+	}
+
+	return writer.Close()
+}
+
+// dumpUploadedCovers writes the variants of the uploaded covers one cover at
+// a time, so that only a page of sources and a single cover are in memory.
+func (s *BackupService) dumpUploadedCovers(ctx context.Context, queries *Queries, writer *domain.Writer, flush func() error) error {
+	after := ""
+	for {
+		sources, err := queries.ListUploadedCoverSourcesAfter(ctx, ListUploadedCoverSourcesAfterParams{Source: after, Limit: int64(s.pageSize)})
+		if err != nil {
+			return err
+		}
+		for _, source := range sources {
+			images, err := queries.ListCoverImagesBySource(ctx, source)
+			if err != nil {
+				return err
+			}
+			for _, image := range images {
+				err := writer.WriteCoverImage(domain.CoverImage{
+					CoverID:     strings.TrimPrefix(image.Source, uploadedCoverPrefix),
+					Variant:     image.Variant,
+					ContentType: image.ContentType,
+					Data:        image.Data,
+					ETag:        image.Etag,
+					CreatedAt:   image.CreatedAt,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if len(sources) < s.pageSize {
+			return nil
+		}
+		after = sources[len(sources)-1]
+	}
+}
+
+// dumpTable writes a table in occurred_at order. Pages end on a timestamp boundary:
+// the events sharing the last timestamp of a full page are read together, so that
+// events within the same second keep the order given by the query.
+func dumpTable[T any](
+	ctx context.Context,
+	pageSize int,
+	listAfter func(ctx context.Context, after string, limit int64) ([]T, error),
+	listAt func(ctx context.Context, occurredAt string) ([]T, error),
+	occurredAt func(T) string,
+	write func(T) error,
+	flush func() error,
+) error {
+	after := ""
+	for {
+		page, err := listAfter(ctx, after, int64(pageSize))
+		if err != nil {
+			return err
+		}
+		if len(page) < pageSize {
+			for _, e := range page {
+				if err := write(e); err != nil {
+					return err
+				}
+			}
+			return flush()
+		}
+
+		last := occurredAt(page[len(page)-1])
+		for _, e := range page {
+			if occurredAt(e) == last {
+				break
+			}
+			if err := write(e); err != nil {
+				return err
+			}
+		}
+		same, err := listAt(ctx, last)
+		if err != nil {
+			return err
+		}
+		for _, e := range same {
+			if err := write(e); err != nil {
+				return err
+			}
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		after = last
+	}
+}
+
+func nullStringToPtr(ns sql.NullString) *string {
+	if !ns.Valid {
+		return nil
+	}
+	return &ns.String
+}
+
+func nullInt64ToPtr(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
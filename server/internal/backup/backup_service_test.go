@@ -0,0 +1,308 @@
+//go:build medium
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"holocron/internal/backup/domain"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const schema = `
+	CREATE TABLE user_events (
+		event_id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		name TEXT NOT NULL,
+		occurred_at TEXT NOT NULL
+	);
+	CREATE TABLE book_events (
+		event_id TEXT PRIMARY KEY,
+		book_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		code TEXT,
+		title TEXT,
+		authors TEXT,
+		publisher TEXT,
+		published_date TEXT,
+		thumbnail_url TEXT,
+		delete_reason TEXT,
+		delete_memo TEXT,
+		origin TEXT,
+		cover_id TEXT,
+		tags TEXT,
+		category_id TEXT,
+		shelf_location TEXT,
+		series_id TEXT,
+		volume_number INTEGER,
+		accession_number TEXT,
+		condition TEXT,
+		condition_note TEXT,
+		lending_id TEXT,
+		occurred_at TEXT NOT NULL
+	);
+	CREATE TABLE series_events (
+		event_id TEXT PRIMARY KEY,
+		series_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		title TEXT NOT NULL,
+		occurred_at TEXT NOT NULL
+	);
+	CREATE TABLE category_events (
+		event_id TEXT PRIMARY KEY,
+		category_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		parent_id TEXT,
+		code TEXT,
+		name TEXT NOT NULL,
+		occurred_at TEXT NOT NULL
+	);
+	CREATE TABLE lending_events (
+		event_id TEXT PRIMARY KEY,
+		lending_id TEXT NOT NULL,
+		book_id TEXT NOT NULL,
+		borrower_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		due_date TEXT,
+		actor_id TEXT,
+		reason TEXT,
+		occurred_at TEXT NOT NULL
+	);
+	CREATE TABLE cover_images (
+		source TEXT NOT NULL,
+		variant TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		data BLOB NOT NULL,
+		etag TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		PRIMARY KEY (source, variant)
+	);
+	CREATE TABLE audit_sessions (
+		audit_id TEXT PRIMARY KEY,
+		started_by TEXT NOT NULL,
+		started_at TEXT NOT NULL,
+		finished_by TEXT,
+		finished_at TEXT
+	);
+`
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func seedEvents(t *testing.T, db *sql.DB) {
+	t.Helper()
+	statements := []string{
+		`INSERT INTO user_events VALUES ('u1', 'user-1', 'created', '山田', '2024-01-01T00:00:00Z')`,
+		// created and updated in the same second, with event IDs sorting the other way round
+		`INSERT INTO book_events (event_id, book_id, event_type, code, title, authors, occurred_at) VALUES ('zz', 'book-1', 'created', '9784873115658', '本', '["著者"]', '2024-01-02T00:00:00Z')`,
+		`INSERT INTO book_events (event_id, book_id, event_type, code, title, authors, origin, occurred_at) VALUES ('aa', 'book-1', 'updated', '9784873115658', '本', '["著者"]', 'metadata_refresh', '2024-01-02T00:00:00Z')`,
+		// a child category created in the same second as its parent, with event IDs sorting the other way round
+		`INSERT INTO category_events VALUES ('c9', 'category-1', 'created', NULL, '900', '文学', '2024-01-01T00:00:00Z')`,
+		`INSERT INTO category_events VALUES ('c1', 'category-2', 'created', 'category-1', NULL, '日本文学', '2024-01-01T00:00:00Z')`,
+		`INSERT INTO book_events (event_id, book_id, event_type, cover_id, occurred_at) VALUES ('b3', 'book-1', 'cover_changed', 'cover-1', '2024-01-03T00:00:00Z')`,
+		`INSERT INTO book_events (event_id, book_id, event_type, tags, occurred_at) VALUES ('b6', 'book-1', 'tags_changed', '["小説"]', '2024-01-03T00:00:00Z')`,
+		`INSERT INTO book_events (event_id, book_id, event_type, category_id, occurred_at) VALUES ('b7', 'book-1', 'category_changed', 'category-2', '2024-01-03T00:00:00Z')`,
+		`INSERT INTO book_events (event_id, book_id, event_type, shelf_location, occurred_at) VALUES ('b8', 'book-1', 'shelf_changed', '書斎 A-3', '2024-01-03T00:00:00Z')`,
+		`INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at) VALUES ('b4', 'book-2', 'created', '別の本', '[]', '2024-01-03T00:00:00Z')`,
+		`INSERT INTO book_events (event_id, book_id, event_type, delete_reason, delete_memo, occurred_at) VALUES ('b5', 'book-2', 'deleted', 'transfer', '友人へ', '2024-01-04T00:00:00Z')`,
+		`INSERT INTO lending_events VALUES ('l2', 'lending-1', 'book-1', 'user-1', 'borrowed', '2024-01-12T00:00:00Z', 'user-1', NULL, '2024-01-05T00:00:00Z')`,
+		`INSERT INTO lending_events VALUES ('l1', 'lending-1', 'book-1', 'user-1', 'returned', NULL, 'user-1', NULL, '2024-01-05T00:00:00Z')`,
+		// a user created in the same second as user-1, with event IDs sorting the other way round
+		`INSERT INTO user_events VALUES ('u0', 'user-2', 'created', '佐藤', '2024-01-01T00:00:00Z')`,
+		`INSERT INTO book_events (event_id, book_id, event_type, condition, condition_note, lending_id, occurred_at) VALUES ('b9', 'book-1', 'condition_recorded', 'damaged', '表紙に水濡れ', 'lending-1', '2024-01-05T00:00:00Z')`,
+		`INSERT INTO lending_events VALUES ('l5', 'lending-2', 'book-1', 'user-1', 'lent', '2024-01-13T00:00:00Z', 'librarian-1', NULL, '2024-01-06T00:00:00Z')`,
+		// a transfer closes a loan and opens another in the same second, with event IDs sorting the other way round
+		`INSERT INTO lending_events VALUES ('l4', 'lending-2', 'book-1', 'user-1', 'transferred', NULL, 'librarian-1', '引き継ぎ', '2024-01-07T00:00:00Z')`,
+		`INSERT INTO lending_events VALUES ('l3', 'lending-3', 'book-1', 'user-2', 'lent', '2024-01-13T00:00:00Z', 'librarian-1', '引き継ぎ', '2024-01-07T00:00:00Z')`,
+		`INSERT INTO lending_events VALUES ('l6', 'lending-3', 'book-1', 'user-2', 'force_returned', NULL, 'librarian-1', '退職', '2024-01-08T00:00:00Z')`,
+		`INSERT INTO cover_images VALUES ('upload:cover-1', 'original', 'image/png', X'89504E47', '"a"', '2024-01-03T00:00:00Z')`,
+		`INSERT INTO cover_images VALUES ('upload:cover-1', 'small', 'image/jpeg', X'FFD8FF', '"b"', '2024-01-03T00:00:00Z')`,
+		// a cover fetched from a thumbnail URL is a cache and is not backed up
+		`INSERT INTO cover_images VALUES ('https://example.com/cover.jpg', 'original', 'image/jpeg', X'FFD8FF', '"c"', '2024-01-03T00:00:00Z')`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func dumpTables(t *testing.T, db *sql.DB) string {
+	t.Helper()
+	var b strings.Builder
+	for _, query := range []string{
+		`SELECT event_id, user_id, event_type, name, occurred_at, '', '', '', '', '', '', '', '', '', '', '', '', '', '', '' FROM user_events ORDER BY rowid`,
+		`SELECT event_id, category_id, event_type, IFNULL(parent_id, '<nil>'), IFNULL(code, '<nil>'), name, occurred_at, '', '', '', '', '', '', '', '', '', '', '', '', '' FROM category_events ORDER BY event_id`,
+		`SELECT event_id, book_id, event_type, IFNULL(code, '<nil>'), IFNULL(title, '<nil>'), IFNULL(authors, '<nil>'), IFNULL(publisher, '<nil>'), IFNULL(published_date, '<nil>'), IFNULL(thumbnail_url, '<nil>'), IFNULL(delete_reason, '<nil>'), IFNULL(delete_memo, '<nil>'), IFNULL(origin, '<nil>'), IFNULL(cover_id, '<nil>'), IFNULL(tags, '<nil>'), IFNULL(category_id, '<nil>'), IFNULL(shelf_location, '<nil>'), IFNULL(condition, '<nil>'), IFNULL(condition_note, '<nil>'), IFNULL(lending_id, '<nil>'), occurred_at FROM book_events ORDER BY event_id`,
+		`SELECT event_id, lending_id, book_id, borrower_id, event_type, IFNULL(due_date, '<nil>'), IFNULL(actor_id, '<nil>'), IFNULL(reason, '<nil>'), occurred_at, '', '', '', '', '', '', '', '', '', '', '' FROM lending_events ORDER BY event_id`,
+		`SELECT source, variant, content_type, hex(data), etag, created_at, '', '', '', '', '', '', '', '', '', '', '', '', '', '' FROM cover_images WHERE source LIKE 'upload:%' ORDER BY source, variant`,
+	} {
+		rows, err := db.Query(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rows.Next() {
+			values := make([]any, 20)
+			ptrs := make([]any, 20)
+			for i := range values {
+				ptrs[i] = &values[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				t.Fatal(err)
+			}
+			fmt.Fprintln(&b, values...)
+		}
+		rows.Close()
+	}
+	return b.String()
+}
+
+func TestBackupAndRestore_IntoFreshDatabase_RestoresEveryEvent(t *testing.T) {
+	source := setupTestDB(t)
+	seedEvents(t, source)
+	backupService := NewBackupService(source)
+	backupService.pageSize = 2
+
+	// When the events are backed up with small pages and restored into a fresh database
+	// then both databases hold the same events
+	var buf bytes.Buffer
+	counts, err := backupService.Backup(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if counts != (domain.Counts{UserEvents: 2, CategoryEvents: 2, BookEvents: 9, LendingEvents: 6, CoverImages: 2}) {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+
+	target := setupTestDB(t)
+	var rebuilt []string
+	restoreService := NewRestoreService(target, Projection{
+		Name:    "test_projection",
+		Rebuild: func(ctx context.Context) error { rebuilt = append(rebuilt, "test_projection"); return nil },
+	})
+	output, err := restoreService.Restore(context.Background(), RestoreInput{Backup: bytes.NewReader(buf.Bytes())})
+	if err != nil {
+		t.Fatalf("restore failed: %v\n%s", err, buf.String())
+	}
+	if output.Counts != counts {
+		t.Errorf("expected restored counts %+v, got %+v", counts, output.Counts)
+	}
+	if len(rebuilt) != 1 || len(output.RebuiltProjections) != 1 {
+		t.Errorf("expected projection to be rebuilt once, got %v", output.RebuiltProjections)
+	}
+	if got, want := dumpTables(t, target), dumpTables(t, source); got != want {
+		t.Errorf("restored events differ\nwant:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestRestore_WithOpenAudit_ReturnsErrAuditInProgress(t *testing.T) {
+	source := setupTestDB(t)
+	seedEvents(t, source)
+	var buf bytes.Buffer
+	if _, err := NewBackupService(source).Backup(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Exec(`INSERT INTO audit_sessions (audit_id, started_by, started_at) VALUES ('audit-1', 'librarian', '2024-02-01T00:00:00Z')`); err != nil {
+		t.Fatal(err)
+	}
+
+	// When restoring with replace while an audit session is open
+	// then ErrAuditInProgress is returned and the events are kept
+	_, err := NewRestoreService(source).Restore(context.Background(), RestoreInput{Backup: bytes.NewReader(buf.Bytes()), Replace: true})
+	if !errors.Is(err, ErrAuditInProgress) {
+		t.Errorf("expected ErrAuditInProgress, got %v", err)
+	}
+	var users int
+	if err := source.QueryRow(`SELECT COUNT(*) FROM user_events`).Scan(&users); err != nil {
+		t.Fatal(err)
+	}
+	if users == 0 {
+		t.Error("expected the existing events to be kept")
+	}
+
+	// When the session is finished
+	// then the restore succeeds
+	if _, err := source.Exec(`UPDATE audit_sessions SET finished_by = 'librarian', finished_at = '2024-02-02T00:00:00Z'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRestoreService(source).Restore(context.Background(), RestoreInput{Backup: bytes.NewReader(buf.Bytes()), Replace: true}); err != nil {
+		t.Errorf("restore failed: %v", err)
+	}
+}
+
+func TestRestore_IntoDatabaseWithEvents_ReturnsErrDatabaseNotEmpty(t *testing.T) {
+	source := setupTestDB(t)
+	seedEvents(t, source)
+	var buf bytes.Buffer
+	if _, err := NewBackupService(source).Backup(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// When restoring into a database that already has events without replace
+	// then ErrDatabaseNotEmpty is returned
+	_, err := NewRestoreService(source).Restore(context.Background(), RestoreInput{Backup: bytes.NewReader(buf.Bytes())})
+	if !errors.Is(err, ErrDatabaseNotEmpty) {
+		t.Errorf("expected ErrDatabaseNotEmpty, got %v", err)
+	}
+
+	// When restoring with replace
+	// then the existing events are replaced
+	if _, err := source.Exec(`INSERT INTO user_events VALUES ('u3', 'user-3', 'created', '鈴木', '2024-02-01T00:00:00Z')`); err != nil {
+		t.Fatal(err)
+	}
+	output, err := NewRestoreService(source).Restore(context.Background(), RestoreInput{Backup: bytes.NewReader(buf.Bytes()), Replace: true})
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	var users int
+	if err := source.QueryRow(`SELECT COUNT(*) FROM user_events`).Scan(&users); err != nil {
+		t.Fatal(err)
+	}
+	if users != output.Counts.UserEvents {
+		t.Errorf("expected %d users, got %d", output.Counts.UserEvents, users)
+	}
+}
+
+func TestRestore_WithInvalidEvent_WritesNothing(t *testing.T) {
+	source := setupTestDB(t)
+	seedEvents(t, source)
+	var buf bytes.Buffer
+	if _, err := NewBackupService(source).Backup(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	corrupted := strings.Replace(buf.String(), `"lending_id":"lending-1","book_id":"book-1","borrower_id":"user-1","event_type":"returned"`, `"lending_id":"lending-9","book_id":"book-1","borrower_id":"user-1","event_type":"returned"`, 1)
+
+	// When the last event of the backup refers to an unknown lending
+	// then the restore fails and no event is written
+	target := setupTestDB(t)
+	_, err := NewRestoreService(target).Restore(context.Background(), RestoreInput{Backup: strings.NewReader(corrupted)})
+	if !errors.Is(err, domain.ErrInvalidBackup) {
+		t.Fatalf("expected ErrInvalidBackup, got %v", err)
+	}
+	var count int
+	if err := target.QueryRow(`SELECT (SELECT COUNT(*) FROM user_events) + (SELECT COUNT(*) FROM book_events)`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("expected no events, got %d", count)
+	}
+}
@@ -0,0 +1,320 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	FormatName = "holocron-backup"
+	// SchemaVersion 2 added category events and the tags, category_id and
+	// shelf_location of book events. Version 3 added series events and the
+	// series_id and volume_number of book events. Version 4 added the accession_number
+	// of book events. Version 5 added the actor_id and reason of lending events and
+	// the lent, force_returned and transferred lending events. Version 6 added the
+	// condition_recorded book events and the lost lending events. Version 7 added
+	// the uploaded cover images. Version 8 added the lending_id of condition_recorded
+	// book events. Earlier backups can still be restored.
+	SchemaVersion    = 8
+	minSchemaVersion = 1
+
+	// maxLineBytes fits a cover image in base64: uploads are re-encoded, so a
+	// PNG can end up larger than the upload limit.
+	maxLineBytes = 64 << 20
+)
+
+const (
+	RecordTypeHeader = "header"
+	RecordTypeFooter = "footer"
+
+	TableUserEvents     = "user_events"
+	TableCategoryEvents = "category_events"
+	TableSeriesEvents   = "series_events"
+	TableBookEvents     = "book_events"
+	TableLendingEvents  = "lending_events"
+	TableCoverImages    = "cover_images"
+)
+
+// Tables lists the tables in the order they appear in a backup.
+// Book events refer to categories and series, lending events refer to books,
+// and cover images are uploaded by cover_changed book events, so the
+// referenced tables come first. Only the uploaded cover images are backed up:
+// covers fetched from a thumbnail URL are a cache and are fetched again.
+var Tables = []string{TableUserEvents, TableCategoryEvents, TableSeriesEvents, TableBookEvents, TableLendingEvents, TableCoverImages}
+
+type Header struct {
+	Format        string   `json:"format"`
+	SchemaVersion int      `json:"schema_version"`
+	CreatedAt     string   `json:"created_at"`
+	Tables        []string `json:"tables"`
+}
+
+type Counts struct {
+	UserEvents     int `json:"user_events"`
+	CategoryEvents int `json:"category_events"`
+	SeriesEvents   int `json:"series_events"`
+	BookEvents     int `json:"book_events"`
+	LendingEvents  int `json:"lending_events"`
+	CoverImages    int `json:"cover_images"`
+}
+
+type UserEvent struct {
+	EventID    string `json:"event_id"`
+	UserID     string `json:"user_id"`
+	EventType  string `json:"event_type"`
+	Name       string `json:"name"`
+	OccurredAt string `json:"occurred_at"`
+}
+
+type CategoryEvent struct {
+	EventID    string  `json:"event_id"`
+	CategoryID string  `json:"category_id"`
+	EventType  string  `json:"event_type"`
+	ParentID   *string `json:"parent_id"`
+	Code       *string `json:"code"`
+	Name       string  `json:"name"`
+	OccurredAt string  `json:"occurred_at"`
+}
+
+type SeriesEvent struct {
+	EventID    string `json:"event_id"`
+	SeriesID   string `json:"series_id"`
+	EventType  string `json:"event_type"`
+	Title      string `json:"title"`
+	OccurredAt string `json:"occurred_at"`
+}
+
+type BookEvent struct {
+	EventID         string  `json:"event_id"`
+	BookID          string  `json:"book_id"`
+	EventType       string  `json:"event_type"`
+	Code            *string `json:"code"`
+	Title           *string `json:"title"`
+	Authors         *string `json:"authors"`
+	Publisher       *string `json:"publisher"`
+	PublishedDate   *string `json:"published_date"`
+	ThumbnailURL    *string `json:"thumbnail_url"`
+	DeleteReason    *string `json:"delete_reason"`
+	DeleteMemo      *string `json:"delete_memo"`
+	Origin          *string `json:"origin"`
+	CoverID         *string `json:"cover_id"`
+	Tags            *string `json:"tags"`
+	CategoryID      *string `json:"category_id"`
+	ShelfLocation   *string `json:"shelf_location"`
+	SeriesID        *string `json:"series_id"`
+	VolumeNumber    *int64  `json:"volume_number"`
+	AccessionNumber *string `json:"accession_number"`
+	Condition       *string `json:"condition"`
+	ConditionNote   *string `json:"condition_note"`
+	LendingID       *string `json:"lending_id"`
+	OccurredAt      string  `json:"occurred_at"`
+}
+
+type LendingEvent struct {
+	EventID    string  `json:"event_id"`
+	LendingID  string  `json:"lending_id"`
+	BookID     string  `json:"book_id"`
+	BorrowerID string  `json:"borrower_id"`
+	EventType  string  `json:"event_type"`
+	DueDate    *string `json:"due_date"`
+	ActorID    *string `json:"actor_id"`
+	Reason     *string `json:"reason"`
+	OccurredAt string  `json:"occurred_at"`
+}
+
+// CoverImage is one stored variant of an uploaded cover. Data is encoded as
+// base64 in JSON.
+type CoverImage struct {
+	CoverID     string `json:"cover_id"`
+	Variant     string `json:"variant"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+	ETag        string `json:"etag"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// Record is one line of a backup. Exactly one of the pointer fields is set, according to Type.
+type Record struct {
+	Line          int
+	Type          string
+	Header        *Header
+	UserEvent     *UserEvent
+	CategoryEvent *CategoryEvent
+	SeriesEvent   *SeriesEvent
+	BookEvent     *BookEvent
+	LendingEvent  *LendingEvent
+	CoverImage    *CoverImage
+	Counts        *Counts
+}
+
+type envelope struct {
+	Type   string          `json:"type"`
+	Event  json.RawMessage `json:"event,omitempty"`
+	Image  json.RawMessage `json:"image,omitempty"`
+	Counts *Counts         `json:"counts,omitempty"`
+	Header
+}
+
+// Writer encodes a backup as JSON Lines: a header, the events of each table, the
+// uploaded cover images, and a footer with the counts.
+type Writer struct {
+	w      *bufio.Writer
+	counts Counts
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (w *Writer) WriteHeader(createdAt time.Time) error {
+	return w.writeLine(map[string]any{
+		"type":           RecordTypeHeader,
+		"format":         FormatName,
+		"schema_version": SchemaVersion,
+		"created_at":     createdAt.UTC().Format(time.RFC3339),
+		"tables":         Tables,
+	})
+}
+
+func (w *Writer) WriteUserEvent(event UserEvent) error {
+	w.counts.UserEvents++
+	return w.writeLine(map[string]any{"type": TableUserEvents, "event": event})
+}
+
+func (w *Writer) WriteCategoryEvent(event CategoryEvent) error {
+	w.counts.CategoryEvents++
+	return w.writeLine(map[string]any{"type": TableCategoryEvents, "event": event})
+}
+
+func (w *Writer) WriteSeriesEvent(event SeriesEvent) error {
+	w.counts.SeriesEvents++
+	return w.writeLine(map[string]any{"type": TableSeriesEvents, "event": event})
+}
+
+func (w *Writer) WriteBookEvent(event BookEvent) error {
+	w.counts.BookEvents++
+	return w.writeLine(map[string]any{"type": TableBookEvents, "event": event})
+}
+
+func (w *Writer) WriteLendingEvent(event LendingEvent) error {
+	w.counts.LendingEvents++
+	return w.writeLine(map[string]any{"type": TableLendingEvents, "event": event})
+}
+
+func (w *Writer) WriteCoverImage(image CoverImage) error {
+	w.counts.CoverImages++
+	return w.writeLine(map[string]any{"type": TableCoverImages, "image": image})
+}
+
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// Close writes the footer and flushes. It returns the number of events written.
+func (w *Writer) Close() (Counts, error) {
+	if err := w.writeLine(map[string]any{"type": RecordTypeFooter, "counts": w.counts}); err != nil {
+		return w.counts, err
+	}
+	return w.counts, w.Flush()
+}
+
+func (w *Writer) writeLine(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.w.Write(data)
+	return err
+}
+
+// Reader decodes the lines of a backup. It reports syntax errors as *ValidationError.
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineBytes)
+	return &Reader{scanner: scanner}
+}
+
+// Next returns the next record, or io.EOF when the input is exhausted.
+func (r *Reader) Next() (*Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		return decodeRecord(r.line, data)
+	}
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, invalid(r.line+1, "line is too long")
+		}
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func decodeRecord(line int, data []byte) (*Record, error) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, invalid(line, fmt.Sprintf("malformed JSON: %v", err))
+	}
+
+	record := &Record{Line: line, Type: env.Type}
+	switch env.Type {
+	case RecordTypeHeader:
+		header := env.Header
+		record.Header = &header
+	case RecordTypeFooter:
+		if env.Counts == nil {
+			return nil, invalid(line, "footer has no counts")
+		}
+		record.Counts = env.Counts
+	case TableUserEvents:
+		record.UserEvent = &UserEvent{}
+		return record, decodeEvent(line, env.Event, record.UserEvent)
+	case TableCategoryEvents:
+		record.CategoryEvent = &CategoryEvent{}
+		return record, decodeEvent(line, env.Event, record.CategoryEvent)
+	case TableSeriesEvents:
+		record.SeriesEvent = &SeriesEvent{}
+		return record, decodeEvent(line, env.Event, record.SeriesEvent)
+	case TableBookEvents:
+		record.BookEvent = &BookEvent{}
+		return record, decodeEvent(line, env.Event, record.BookEvent)
+	case TableLendingEvents:
+		record.LendingEvent = &LendingEvent{}
+		return record, decodeEvent(line, env.Event, record.LendingEvent)
+	case TableCoverImages:
+		if len(env.Image) == 0 {
+			return nil, invalid(line, "image is missing")
+		}
+		record.CoverImage = &CoverImage{}
+		return record, decodeEvent(line, env.Image, record.CoverImage)
+	default:
+		return nil, invalid(line, fmt.Sprintf("unknown record type %q", env.Type))
+	}
+	return record, nil
+}
+
+func decodeEvent(line int, data json.RawMessage, v any) error {
+	if len(data) == 0 {
+		return invalid(line, "event is missing")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return invalid(line, fmt.Sprintf("malformed event: %v", err))
+	}
+	return nil
+}
@@ -0,0 +1,483 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+var (
+	ErrInvalidBackup            = errors.New("invalid backup")
+	ErrUnsupportedSchemaVersion = errors.New("unsupported backup schema version")
+)
+
+// ValidationError reports the line of the backup that failed validation.
+type ValidationError struct {
+	Line    int
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidBackup
+}
+
+func invalid(line int, message string) error {
+	return &ValidationError{Line: line, Message: message}
+}
+
+var (
+	bookEventTypes    = []string{"created", "updated", "deleted", "cover_changed", "tags_changed", "category_changed", "shelf_changed", "series_changed", "accession_assigned", "condition_recorded"}
+	lendingEventTypes = []string{"borrowed", "lent", "due_date_extended", "returned", "force_returned", "transferred", "lost"}
+	// A loan is opened by a borrow or by a librarian lending on someone's behalf,
+	// and closed by a return, a librarian's check-in, a transfer to another user or
+	// a report that the book was lost.
+	openingLendingEvents = []string{"borrowed", "lent"}
+	closingLendingEvents = []string{"returned", "force_returned", "transferred", "lost"}
+	// librarianLendingEvents are only recorded by librarians, who are always named.
+	librarianLendingEvents = []string{"lent", "force_returned", "transferred"}
+	deleteReasons          = []string{"transfer", "disposal", "lost", "other"}
+	bookConditions         = []string{"good", "damaged", "missing_pages"}
+	bookEventOrigins       = []string{"metadata_refresh"}
+	coverVariants          = []string{"original", "small", "medium"}
+	coverContentTypes      = []string{"image/jpeg", "image/png"}
+
+	accessionNumberPattern = regexp.MustCompile(`^HC-[0-9]{6,}$`)
+)
+
+type openLending struct {
+	bookID     string
+	borrowerID string
+}
+
+// conditionLending is the loan a condition_recorded event refers to. Lending
+// events come after book events, so the loan is checked when the backup ends.
+type conditionLending struct {
+	lendingID string
+	bookID    string
+	line      int
+}
+
+// Validator checks that a backup is complete and that its events could have been produced
+// by the application: every event refers to an entity in a valid state at that point.
+// Records must be passed in file order.
+type Validator struct {
+	header     *Header
+	footerSeen bool
+	table      int
+	lastTime   string
+	lastLine   int
+	counts     Counts
+
+	eventIDs     map[string]bool
+	users        map[string]bool
+	categories   map[string]bool
+	series       map[string]bool
+	accessions   map[string]bool
+	liveBooks    map[string]bool
+	knownBooks   map[string]bool
+	lendings     map[string]string
+	openLendings map[string]openLending
+	bookLendings map[string]string
+	covers       map[string]bool
+	coverImages  map[string]bool
+
+	conditionLendings []conditionLending
+	conditioned       map[string]bool
+}
+
+func NewValidator() *Validator {
+	return &Validator{
+		table:        -1,
+		eventIDs:     map[string]bool{},
+		users:        map[string]bool{},
+		categories:   map[string]bool{},
+		series:       map[string]bool{},
+		accessions:   map[string]bool{},
+		liveBooks:    map[string]bool{},
+		knownBooks:   map[string]bool{},
+		lendings:     map[string]string{},
+		openLendings: map[string]openLending{},
+		bookLendings: map[string]string{},
+		covers:       map[string]bool{},
+		coverImages:  map[string]bool{},
+		conditioned:  map[string]bool{},
+	}
+}
+
+func (v *Validator) Check(record *Record) error {
+	v.lastLine = record.Line
+	if v.footerSeen {
+		return invalid(record.Line, "unexpected record after footer")
+	}
+	if v.header == nil {
+		if record.Type != RecordTypeHeader {
+			return invalid(record.Line, "backup must start with a header")
+		}
+		return v.checkHeader(record)
+	}
+
+	switch record.Type {
+	case RecordTypeHeader:
+		return invalid(record.Line, "duplicate header")
+	case RecordTypeFooter:
+		v.footerSeen = true
+		if *record.Counts != v.counts {
+			return invalid(record.Line, fmt.Sprintf("footer counts %+v do not match %+v", *record.Counts, v.counts))
+		}
+		return nil
+	}
+
+	if err := v.checkOrder(record); err != nil {
+		return err
+	}
+	switch {
+	case record.UserEvent != nil:
+		v.counts.UserEvents++
+		return v.checkUserEvent(record.Line, record.UserEvent)
+	case record.CategoryEvent != nil:
+		v.counts.CategoryEvents++
+		return v.checkCategoryEvent(record.Line, record.CategoryEvent)
+	case record.SeriesEvent != nil:
+		v.counts.SeriesEvents++
+		return v.checkSeriesEvent(record.Line, record.SeriesEvent)
+	case record.BookEvent != nil:
+		v.counts.BookEvents++
+		return v.checkBookEvent(record.Line, record.BookEvent)
+	case record.CoverImage != nil:
+		v.counts.CoverImages++
+		return v.checkCoverImage(record.Line, record.CoverImage)
+	default:
+		v.counts.LendingEvents++
+		return v.checkLendingEvent(record.Line, record.LendingEvent)
+	}
+}
+
+// Finish reports an error if the backup ended before its footer, or if a
+// recorded condition refers to a loan of another book or to no loan.
+func (v *Validator) Finish() (*Header, Counts, error) {
+	if v.header == nil {
+		return nil, v.counts, invalid(v.lastLine+1, "backup is empty")
+	}
+	if !v.footerSeen {
+		return nil, v.counts, invalid(v.lastLine+1, "backup is truncated: footer is missing")
+	}
+	for _, c := range v.conditionLendings {
+		if bookID, ok := v.lendings[c.lendingID]; !ok || bookID != c.bookID {
+			return nil, v.counts, invalid(c.line, fmt.Sprintf("lending %s is not a loan of book %s", c.lendingID, c.bookID))
+		}
+	}
+	return v.header, v.counts, nil
+}
+
+func (v *Validator) checkHeader(record *Record) error {
+	header := record.Header
+	if header.Format != FormatName {
+		return invalid(record.Line, fmt.Sprintf("format must be %q", FormatName))
+	}
+	if header.SchemaVersion < minSchemaVersion || header.SchemaVersion > SchemaVersion {
+		return &ValidationError{Line: record.Line, Message: fmt.Sprintf("%v: %d", ErrUnsupportedSchemaVersion, header.SchemaVersion)}
+	}
+	if _, err := time.Parse(time.RFC3339, header.CreatedAt); err != nil {
+		return invalid(record.Line, "created_at must be RFC3339")
+	}
+	v.header = header
+	return nil
+}
+
+// checkOrder enforces the table order and the occurred_at order within a table,
+// and that event IDs are unique across the backup. Cover images are not events
+// and are only checked for the table order.
+func (v *Validator) checkOrder(record *Record) error {
+	table := slices.Index(Tables, record.Type)
+	if table < v.table {
+		return invalid(record.Line, fmt.Sprintf("%s must come before %s", record.Type, Tables[v.table]))
+	}
+	if table > v.table {
+		v.table = table
+		v.lastTime = ""
+	}
+	if record.CoverImage != nil {
+		return nil
+	}
+
+	eventID, occurredAt := eventKey(record)
+	if eventID == "" {
+		return invalid(record.Line, "event_id is required")
+	}
+	if _, err := time.Parse(time.RFC3339, occurredAt); err != nil {
+		return invalid(record.Line, "occurred_at must be RFC3339")
+	}
+	if v.eventIDs[eventID] {
+		return invalid(record.Line, fmt.Sprintf("duplicate event_id %s", eventID))
+	}
+	v.eventIDs[eventID] = true
+
+	if occurredAt < v.lastTime {
+		return invalid(record.Line, "events must be ordered by occurred_at")
+	}
+	v.lastTime = occurredAt
+	return nil
+}
+
+func eventKey(record *Record) (string, string) {
+	switch {
+	case record.UserEvent != nil:
+		return record.UserEvent.EventID, record.UserEvent.OccurredAt
+	case record.CategoryEvent != nil:
+		return record.CategoryEvent.EventID, record.CategoryEvent.OccurredAt
+	case record.SeriesEvent != nil:
+		return record.SeriesEvent.EventID, record.SeriesEvent.OccurredAt
+	case record.BookEvent != nil:
+		return record.BookEvent.EventID, record.BookEvent.OccurredAt
+	default:
+		return record.LendingEvent.EventID, record.LendingEvent.OccurredAt
+	}
+}
+
+func (v *Validator) checkUserEvent(line int, e *UserEvent) error {
+	if e.UserID == "" {
+		return invalid(line, "user_id is required")
+	}
+	if e.EventType != "created" {
+		return invalid(line, fmt.Sprintf("unknown user event_type %q", e.EventType))
+	}
+	if e.Name == "" {
+		return invalid(line, "name is required")
+	}
+	if v.users[e.UserID] {
+		return invalid(line, fmt.Sprintf("user %s is created twice", e.UserID))
+	}
+	v.users[e.UserID] = true
+	return nil
+}
+
+func (v *Validator) checkCategoryEvent(line int, e *CategoryEvent) error {
+	if e.CategoryID == "" {
+		return invalid(line, "category_id is required")
+	}
+	if e.EventType != "created" {
+		return invalid(line, fmt.Sprintf("unknown category event_type %q", e.EventType))
+	}
+	if e.Name == "" {
+		return invalid(line, "name is required")
+	}
+	if v.categories[e.CategoryID] {
+		return invalid(line, fmt.Sprintf("category %s is created twice", e.CategoryID))
+	}
+	if e.ParentID != nil && !v.categories[*e.ParentID] {
+		return invalid(line, fmt.Sprintf("parent category %s does not exist", *e.ParentID))
+	}
+	v.categories[e.CategoryID] = true
+	return nil
+}
+
+func (v *Validator) checkSeriesEvent(line int, e *SeriesEvent) error {
+	if e.SeriesID == "" {
+		return invalid(line, "series_id is required")
+	}
+	if e.EventType != "created" {
+		return invalid(line, fmt.Sprintf("unknown series event_type %q", e.EventType))
+	}
+	if e.Title == "" {
+		return invalid(line, "title is required")
+	}
+	if v.series[e.SeriesID] {
+		return invalid(line, fmt.Sprintf("series %s is created twice", e.SeriesID))
+	}
+	v.series[e.SeriesID] = true
+	return nil
+}
+
+func (v *Validator) checkBookEvent(line int, e *BookEvent) error {
+	if e.BookID == "" {
+		return invalid(line, "book_id is required")
+	}
+	if !slices.Contains(bookEventTypes, e.EventType) {
+		return invalid(line, fmt.Sprintf("unknown book event_type %q", e.EventType))
+	}
+	if e.Origin != nil && !slices.Contains(bookEventOrigins, *e.Origin) {
+		return invalid(line, fmt.Sprintf("unknown origin %q", *e.Origin))
+	}
+
+	live := v.liveBooks[e.BookID]
+	switch e.EventType {
+	case "created", "updated":
+		if e.EventType == "created" && live {
+			return invalid(line, fmt.Sprintf("book %s is created twice", e.BookID))
+		}
+		if e.EventType == "updated" && !live {
+			return invalid(line, fmt.Sprintf("book %s is updated before it is created", e.BookID))
+		}
+		if e.Title == nil || *e.Title == "" {
+			return invalid(line, "title is required")
+		}
+		if e.Authors != nil {
+			var authors []string
+			if err := json.Unmarshal([]byte(*e.Authors), &authors); err != nil {
+				return invalid(line, "authors must be a JSON array of strings")
+			}
+		}
+		v.liveBooks[e.BookID] = true
+		v.knownBooks[e.BookID] = true
+	case "deleted":
+		if !live {
+			return invalid(line, fmt.Sprintf("book %s is deleted but does not exist", e.BookID))
+		}
+		if e.DeleteReason == nil || !slices.Contains(deleteReasons, *e.DeleteReason) {
+			return invalid(line, "delete_reason must be transfer, disposal, lost or other")
+		}
+		v.liveBooks[e.BookID] = false
+	case "cover_changed":
+		if !live {
+			return invalid(line, fmt.Sprintf("book %s does not exist", e.BookID))
+		}
+		if e.CoverID == nil || *e.CoverID == "" {
+			return invalid(line, "cover_id is required")
+		}
+		v.covers[*e.CoverID] = true
+	case "tags_changed":
+		if !live {
+			return invalid(line, fmt.Sprintf("book %s does not exist", e.BookID))
+		}
+		var tags []string
+		if e.Tags == nil || json.Unmarshal([]byte(*e.Tags), &tags) != nil {
+			return invalid(line, "tags must be a JSON array of strings")
+		}
+	case "category_changed":
+		if !live {
+			return invalid(line, fmt.Sprintf("book %s does not exist", e.BookID))
+		}
+		if e.CategoryID != nil && !v.categories[*e.CategoryID] {
+			return invalid(line, fmt.Sprintf("category %s does not exist", *e.CategoryID))
+		}
+	case "shelf_changed":
+		if !live {
+			return invalid(line, fmt.Sprintf("book %s does not exist", e.BookID))
+		}
+	case "series_changed":
+		if !live {
+			return invalid(line, fmt.Sprintf("book %s does not exist", e.BookID))
+		}
+		if e.SeriesID != nil {
+			if !v.series[*e.SeriesID] {
+				return invalid(line, fmt.Sprintf("series %s does not exist", *e.SeriesID))
+			}
+			if e.VolumeNumber == nil || *e.VolumeNumber < 1 {
+				return invalid(line, "volume_number must be positive")
+			}
+		}
+	case "accession_assigned":
+		if !live {
+			return invalid(line, fmt.Sprintf("book %s does not exist", e.BookID))
+		}
+		if e.AccessionNumber == nil || !accessionNumberPattern.MatchString(*e.AccessionNumber) {
+			return invalid(line, "accession_number must be HC- followed by at least 6 digits")
+		}
+		if v.accessions[*e.AccessionNumber] {
+			return invalid(line, fmt.Sprintf("accession number %s is assigned twice", *e.AccessionNumber))
+		}
+		v.accessions[*e.AccessionNumber] = true
+	case "condition_recorded":
+		if !live {
+			return invalid(line, fmt.Sprintf("book %s does not exist", e.BookID))
+		}
+		if e.Condition == nil || !slices.Contains(bookConditions, *e.Condition) {
+			return invalid(line, "condition must be good, damaged or missing_pages")
+		}
+		// Conditions recorded before version 8 do not name their loan.
+		if e.LendingID != nil {
+			if v.conditioned[*e.LendingID] {
+				return invalid(line, fmt.Sprintf("lending %s has its condition recorded twice", *e.LendingID))
+			}
+			v.conditioned[*e.LendingID] = true
+			v.conditionLendings = append(v.conditionLendings, conditionLending{lendingID: *e.LendingID, bookID: e.BookID, line: line})
+		}
+	}
+	return nil
+}
+
+func (v *Validator) checkLendingEvent(line int, e *LendingEvent) error {
+	if e.LendingID == "" || e.BookID == "" || e.BorrowerID == "" {
+		return invalid(line, "lending_id, book_id and borrower_id are required")
+	}
+	if !slices.Contains(lendingEventTypes, e.EventType) {
+		return invalid(line, fmt.Sprintf("unknown lending event_type %q", e.EventType))
+	}
+	if !v.knownBooks[e.BookID] {
+		return invalid(line, fmt.Sprintf("book %s does not exist", e.BookID))
+	}
+	if slices.Contains(librarianLendingEvents, e.EventType) && (e.ActorID == nil || *e.ActorID == "") {
+		return invalid(line, fmt.Sprintf("actor_id is required for %s", e.EventType))
+	}
+	if e.EventType == "force_returned" && (e.Reason == nil || *e.Reason == "") {
+		return invalid(line, "reason is required for force_returned")
+	}
+	if !slices.Contains(closingLendingEvents, e.EventType) {
+		if e.DueDate == nil {
+			return invalid(line, "due_date is required")
+		}
+		if _, err := time.Parse(time.RFC3339, *e.DueDate); err != nil {
+			return invalid(line, "due_date must be RFC3339")
+		}
+	}
+
+	if slices.Contains(openingLendingEvents, e.EventType) {
+		if _, ok := v.lendings[e.LendingID]; ok {
+			return invalid(line, fmt.Sprintf("lending %s is borrowed twice", e.LendingID))
+		}
+		if open, ok := v.bookLendings[e.BookID]; ok {
+			return invalid(line, fmt.Sprintf("book %s is already lent by %s", e.BookID, open))
+		}
+		v.lendings[e.LendingID] = e.BookID
+		v.openLendings[e.LendingID] = openLending{bookID: e.BookID, borrowerID: e.BorrowerID}
+		v.bookLendings[e.BookID] = e.LendingID
+		return nil
+	}
+
+	open, ok := v.openLendings[e.LendingID]
+	if !ok {
+		return invalid(line, fmt.Sprintf("lending %s is not open", e.LendingID))
+	}
+	if open.bookID != e.BookID || open.borrowerID != e.BorrowerID {
+		return invalid(line, fmt.Sprintf("lending %s refers to a different book or borrower", e.LendingID))
+	}
+	if slices.Contains(closingLendingEvents, e.EventType) {
+		delete(v.openLendings, e.LendingID)
+		delete(v.bookLendings, e.BookID)
+	}
+	return nil
+}
+
+func (v *Validator) checkCoverImage(line int, c *CoverImage) error {
+	if c.CoverID == "" {
+		return invalid(line, "cover_id is required")
+	}
+	if !v.covers[c.CoverID] {
+		return invalid(line, fmt.Sprintf("cover %s is not set by any cover_changed event", c.CoverID))
+	}
+	if !slices.Contains(coverVariants, c.Variant) {
+		return invalid(line, fmt.Sprintf("unknown cover variant %q", c.Variant))
+	}
+	if !slices.Contains(coverContentTypes, c.ContentType) {
+		return invalid(line, fmt.Sprintf("unsupported content_type %q", c.ContentType))
+	}
+	if len(c.Data) == 0 || c.ETag == "" {
+		return invalid(line, "data and etag are required")
+	}
+	if _, err := time.Parse(time.RFC3339, c.CreatedAt); err != nil {
+		return invalid(line, "created_at must be RFC3339")
+	}
+	key := c.CoverID + "/" + c.Variant
+	if v.coverImages[key] {
+		return invalid(line, fmt.Sprintf("cover %s has the %s variant twice", c.CoverID, c.Variant))
+	}
+	v.coverImages[key] = true
+	return nil
+}
@@ -0,0 +1,497 @@
+//go:build small
+
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func ptr(s string) *string {
+	return &s
+}
+
+func validate(data string) (Counts, error) {
+	reader := NewReader(strings.NewReader(data))
+	validator := NewValidator()
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			_, counts, err := validator.Finish()
+			return counts, err
+		}
+		if err != nil {
+			return Counts{}, err
+		}
+		if err := validator.Check(record); err != nil {
+			return Counts{}, err
+		}
+	}
+}
+
+type backupBuilder struct {
+	users      []UserEvent
+	categories []CategoryEvent
+	series     []SeriesEvent
+	books      []BookEvent
+	lendings   []LendingEvent
+	covers     []CoverImage
+}
+
+func (b backupBuilder) encode(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.WriteHeader(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range b.users {
+		if err := w.WriteUserEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range b.categories {
+		if err := w.WriteCategoryEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range b.series {
+		if err := w.WriteSeriesEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range b.books {
+		if err := w.WriteBookEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range b.lendings {
+		if err := w.WriteLendingEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, c := range b.covers {
+		if err := w.WriteCoverImage(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func validBackup() backupBuilder {
+	return backupBuilder{
+		users: []UserEvent{
+			{EventID: "u1", UserID: "user-1", EventType: "created", Name: "山田", OccurredAt: "2024-01-01T00:00:00Z"},
+		},
+		books: []BookEvent{
+			{EventID: "b1", BookID: "book-1", EventType: "created", Title: ptr("本"), Authors: ptr(`["著者"]`), OccurredAt: "2024-01-02T00:00:00Z"},
+			{EventID: "b0", BookID: "book-1", EventType: "updated", Title: ptr("本（改訂）"), Authors: ptr(`["著者"]`), OccurredAt: "2024-01-02T00:00:00Z"},
+			{EventID: "b2", BookID: "book-2", EventType: "created", Title: ptr("別の本"), OccurredAt: "2024-01-03T00:00:00Z"},
+			{EventID: "b3", BookID: "book-2", EventType: "deleted", DeleteReason: ptr("lost"), OccurredAt: "2024-01-04T00:00:00Z"},
+		},
+		lendings: []LendingEvent{
+			{EventID: "l1", LendingID: "lending-1", BookID: "book-1", BorrowerID: "user-1", EventType: "borrowed", DueDate: ptr("2024-01-12T00:00:00Z"), OccurredAt: "2024-01-05T00:00:00Z"},
+			{EventID: "l2", LendingID: "lending-1", BookID: "book-1", BorrowerID: "user-1", EventType: "due_date_extended", DueDate: ptr("2024-01-19T00:00:00Z"), OccurredAt: "2024-01-06T00:00:00Z"},
+			{EventID: "l3", LendingID: "lending-1", BookID: "book-1", BorrowerID: "user-1", EventType: "returned", OccurredAt: "2024-01-07T00:00:00Z"},
+			{EventID: "l4", LendingID: "lending-2", BookID: "book-1", BorrowerID: "user-1", EventType: "borrowed", DueDate: ptr("2024-01-15T00:00:00Z"), OccurredAt: "2024-01-08T00:00:00Z"},
+		},
+	}
+}
+
+// classifiedBackup is a backup with a category tree and a classified book.
+func classifiedBackup() backupBuilder {
+	return backupBuilder{
+		categories: []CategoryEvent{
+			{EventID: "c1", CategoryID: "category-1", EventType: "created", Code: ptr("900"), Name: "文学", OccurredAt: "2024-01-01T00:00:00Z"},
+			{EventID: "c2", CategoryID: "category-2", EventType: "created", ParentID: ptr("category-1"), Code: ptr("910"), Name: "日本文学", OccurredAt: "2024-01-01T00:00:00Z"},
+		},
+		books: []BookEvent{
+			{EventID: "b1", BookID: "book-1", EventType: "created", Title: ptr("本"), OccurredAt: "2024-01-02T00:00:00Z"},
+			{EventID: "b2", BookID: "book-1", EventType: "tags_changed", Tags: ptr(`["小説"]`), OccurredAt: "2024-01-03T00:00:00Z"},
+			{EventID: "b3", BookID: "book-1", EventType: "category_changed", CategoryID: ptr("category-2"), OccurredAt: "2024-01-03T00:00:00Z"},
+			{EventID: "b4", BookID: "book-1", EventType: "shelf_changed", ShelfLocation: ptr("A-1"), OccurredAt: "2024-01-03T00:00:00Z"},
+		},
+	}
+}
+
+func volume(n int64) *int64 {
+	return &n
+}
+
+// seriesBackup is a backup with a book that is put in a series and then taken out of it.
+func seriesBackup() backupBuilder {
+	return backupBuilder{
+		series: []SeriesEvent{
+			{EventID: "s1", SeriesID: "series-1", EventType: "created", Title: "進撃の巨人", OccurredAt: "2024-01-01T00:00:00Z"},
+		},
+		books: []BookEvent{
+			{EventID: "b1", BookID: "book-1", EventType: "created", Title: ptr("進撃の巨人（1）"), OccurredAt: "2024-01-02T00:00:00Z"},
+			{EventID: "b2", BookID: "book-1", EventType: "series_changed", SeriesID: ptr("series-1"), VolumeNumber: volume(1), OccurredAt: "2024-01-02T00:00:00Z"},
+			{EventID: "b3", BookID: "book-1", EventType: "series_changed", OccurredAt: "2024-01-03T00:00:00Z"},
+		},
+	}
+}
+
+// accessionBackup is a backup with two books that were given accession numbers.
+func accessionBackup() backupBuilder {
+	return backupBuilder{
+		books: []BookEvent{
+			{EventID: "b1", BookID: "book-1", EventType: "created", Title: ptr("社内報 2023"), OccurredAt: "2024-01-01T00:00:00Z"},
+			{EventID: "b2", BookID: "book-1", EventType: "accession_assigned", AccessionNumber: ptr("HC-000001"), OccurredAt: "2024-01-01T00:00:00Z"},
+			{EventID: "b3", BookID: "book-2", EventType: "created", Title: ptr("社内報 2024"), OccurredAt: "2024-01-02T00:00:00Z"},
+			{EventID: "b4", BookID: "book-2", EventType: "accession_assigned", AccessionNumber: ptr("HC-000002"), OccurredAt: "2024-01-02T00:00:00Z"},
+		},
+	}
+}
+
+// librarianBackup is a backup in which a librarian transferred a loan to another
+// user and then checked the book in.
+func librarianBackup() backupBuilder {
+	b := validBackup()
+	b.users = append(b.users, UserEvent{EventID: "u2", UserID: "user-2", EventType: "created", Name: "佐藤", OccurredAt: "2024-01-01T00:00:00Z"})
+	b.lendings = append(b.lendings,
+		LendingEvent{EventID: "l5", LendingID: "lending-2", BookID: "book-1", BorrowerID: "user-1", EventType: "transferred", ActorID: ptr("librarian-1"), OccurredAt: "2024-01-09T00:00:00Z"},
+		LendingEvent{EventID: "l6", LendingID: "lending-3", BookID: "book-1", BorrowerID: "user-2", EventType: "lent", DueDate: ptr("2024-01-15T00:00:00Z"), ActorID: ptr("librarian-1"), OccurredAt: "2024-01-09T00:00:00Z"},
+		LendingEvent{EventID: "l7", LendingID: "lending-3", BookID: "book-1", BorrowerID: "user-2", EventType: "force_returned", ActorID: ptr("librarian-1"), Reason: ptr("退職者の机に残っていた"), OccurredAt: "2024-01-10T00:00:00Z"},
+	)
+	return b
+}
+
+// lossBackup is a backup in which a book came back damaged and was then lost on
+// its next loan.
+func lossBackup() backupBuilder {
+	b := validBackup()
+	b.books = append(b.books,
+		BookEvent{EventID: "b4", BookID: "book-1", EventType: "condition_recorded", Condition: ptr("damaged"), ConditionNote: ptr("表紙に水濡れ"), LendingID: ptr("lending-1"), OccurredAt: "2024-01-07T00:00:00Z"},
+		BookEvent{EventID: "b5", BookID: "book-1", EventType: "deleted", DeleteReason: ptr("lost"), OccurredAt: "2024-01-09T00:00:00Z"},
+	)
+	b.lendings = append(b.lendings,
+		LendingEvent{EventID: "l5", LendingID: "lending-2", BookID: "book-1", BorrowerID: "user-1", EventType: "lost", ActorID: ptr("user-1"), OccurredAt: "2024-01-09T00:00:00Z"},
+	)
+	return b
+}
+
+// coverBackup is a backup with a book whose cover was uploaded.
+func coverBackup() backupBuilder {
+	return backupBuilder{
+		books: []BookEvent{
+			{EventID: "b1", BookID: "book-1", EventType: "created", Title: ptr("本"), OccurredAt: "2024-01-01T00:00:00Z"},
+			{EventID: "b2", BookID: "book-1", EventType: "cover_changed", CoverID: ptr("cover-1"), OccurredAt: "2024-01-02T00:00:00Z"},
+		},
+		covers: []CoverImage{
+			{CoverID: "cover-1", Variant: "original", ContentType: "image/png", Data: []byte("png"), ETag: `"a"`, CreatedAt: "2024-01-02T00:00:00Z"},
+			{CoverID: "cover-1", Variant: "small", ContentType: "image/jpeg", Data: []byte("jpeg"), ETag: `"b"`, CreatedAt: "2024-01-02T00:00:00Z"},
+		},
+	}
+}
+
+func expectInvalid(t *testing.T, data string, line int, message string) {
+	t.Helper()
+	_, err := validate(data)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidBackup) {
+		t.Error("expected error to wrap ErrInvalidBackup")
+	}
+	if line > 0 && validationErr.Line != line {
+		t.Errorf("expected line %d, got %d (%v)", line, validationErr.Line, err)
+	}
+	if !strings.Contains(validationErr.Message, message) {
+		t.Errorf("expected message containing %q, got %q", message, validationErr.Message)
+	}
+}
+
+func TestValidator_WithValidBackup_ReturnsCounts(t *testing.T) {
+	counts, err := validate(validBackup().encode(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts != (Counts{UserEvents: 1, BookEvents: 4, LendingEvents: 4}) {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+}
+
+func TestValidator_WithTruncatedBackup_ReturnsError(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("dropping trailing lines is always detected", prop.ForAll(
+		func(drop int) bool {
+			lines := strings.SplitAfter(strings.TrimSuffix(validBackup().encode(t), "\n"), "\n")
+			_, err := validate(strings.Join(lines[:len(lines)-drop], ""))
+			return errors.Is(err, ErrInvalidBackup)
+		},
+		gen.IntRange(1, 10),
+	))
+	properties.TestingRun(t)
+}
+
+func TestValidator_WithUnsupportedSchemaVersion_ReturnsError(t *testing.T) {
+	data := strings.Replace(validBackup().encode(t), `"schema_version":8`, `"schema_version":9`, 1)
+	expectInvalid(t, data, 1, ErrUnsupportedSchemaVersion.Error())
+}
+
+func TestValidator_WithVersion1Backup_Succeeds(t *testing.T) {
+	data := strings.Replace(validBackup().encode(t), `"schema_version":8`, `"schema_version":1`, 1)
+	if _, err := validate(data); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestValidator_WithoutHeader_ReturnsError(t *testing.T) {
+	data := validBackup().encode(t)
+	expectInvalid(t, data[strings.Index(data, "\n")+1:], 1, "must start with a header")
+}
+
+func TestValidator_WithWrongFooterCounts_ReturnsError(t *testing.T) {
+	data := strings.Replace(validBackup().encode(t), `"book_events":4`, `"book_events":5`, 1)
+	expectInvalid(t, data, 11, "footer counts")
+}
+
+func TestValidator_WithDuplicateEventID_ReturnsError(t *testing.T) {
+	b := validBackup()
+	b.lendings[1].EventID = "b2"
+	expectInvalid(t, b.encode(t), 8, "duplicate event_id")
+}
+
+func TestValidator_WithUnorderedEvents_ReturnsError(t *testing.T) {
+	b := validBackup()
+	b.books[2].OccurredAt = "2024-01-01T00:00:00Z"
+	expectInvalid(t, b.encode(t), 5, "ordered")
+}
+
+func TestValidator_WithUpdateOfUnknownBook_ReturnsError(t *testing.T) {
+	b := validBackup()
+	b.books[1].BookID = "book-9"
+	expectInvalid(t, b.encode(t), 4, "updated before it is created")
+}
+
+func TestValidator_WithUpdateOfDeletedBook_ReturnsError(t *testing.T) {
+	b := validBackup()
+	b.books = append(b.books, BookEvent{EventID: "b4", BookID: "book-2", EventType: "updated", Title: ptr("x"), OccurredAt: "2024-01-04T00:00:01Z"})
+	b.lendings = nil
+	expectInvalid(t, b.encode(t), 7, "updated before it is created")
+}
+
+func TestValidator_WithInvalidDeleteReason_ReturnsError(t *testing.T) {
+	b := validBackup()
+	b.books[3].DeleteReason = ptr("stolen")
+	expectInvalid(t, b.encode(t), 6, "delete_reason")
+}
+
+func TestValidator_WithInvalidAuthors_ReturnsError(t *testing.T) {
+	b := validBackup()
+	b.books[0].Authors = ptr("著者")
+	expectInvalid(t, b.encode(t), 3, "authors")
+}
+
+func TestValidator_WithLendingOfUnknownBook_ReturnsError(t *testing.T) {
+	b := validBackup()
+	b.lendings = []LendingEvent{{EventID: "l1", LendingID: "lending-1", BookID: "book-9", BorrowerID: "user-1", EventType: "borrowed", DueDate: ptr("2024-01-12T00:00:00Z"), OccurredAt: "2024-01-05T00:00:00Z"}}
+	expectInvalid(t, b.encode(t), 7, "does not exist")
+}
+
+func TestValidator_WithReturnOfClosedLending_ReturnsError(t *testing.T) {
+	b := validBackup()
+	b.lendings = append(b.lendings[:3], LendingEvent{EventID: "l5", LendingID: "lending-1", BookID: "book-1", BorrowerID: "user-1", EventType: "returned", OccurredAt: "2024-01-09T00:00:00Z"})
+	expectInvalid(t, b.encode(t), 10, "is not open")
+}
+
+func TestValidator_WithSecondLendingOfBorrowedBook_ReturnsError(t *testing.T) {
+	b := validBackup()
+	b.lendings = append(b.lendings[:1], LendingEvent{EventID: "l5", LendingID: "lending-3", BookID: "book-1", BorrowerID: "user-1", EventType: "borrowed", DueDate: ptr("2024-01-20T00:00:00Z"), OccurredAt: "2024-01-09T00:00:00Z"})
+	expectInvalid(t, b.encode(t), 8, "already lent")
+}
+
+func TestValidator_WithClassifiedBook_ReturnsCounts(t *testing.T) {
+	counts, err := validate(classifiedBackup().encode(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts != (Counts{CategoryEvents: 2, BookEvents: 4}) {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+}
+
+func TestValidator_WithCategoryOfUnknownParent_ReturnsError(t *testing.T) {
+	b := classifiedBackup()
+	b.categories = b.categories[1:]
+	expectInvalid(t, b.encode(t), 2, "parent category")
+}
+
+func TestValidator_WithUnknownBookCategory_ReturnsError(t *testing.T) {
+	b := classifiedBackup()
+	b.books[2].CategoryID = ptr("category-9")
+	expectInvalid(t, b.encode(t), 6, "category category-9 does not exist")
+}
+
+func TestValidator_WithInvalidTags_ReturnsError(t *testing.T) {
+	b := classifiedBackup()
+	b.books[1].Tags = ptr("小説")
+	expectInvalid(t, b.encode(t), 5, "tags")
+}
+
+func TestValidator_WithBookInSeries_ReturnsCounts(t *testing.T) {
+	counts, err := validate(seriesBackup().encode(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts != (Counts{SeriesEvents: 1, BookEvents: 3}) {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+}
+
+func TestValidator_WithUnknownBookSeries_ReturnsError(t *testing.T) {
+	b := seriesBackup()
+	b.books[1].SeriesID = ptr("series-9")
+	expectInvalid(t, b.encode(t), 4, "series series-9 does not exist")
+}
+
+func TestValidator_WithSeriesWithoutVolume_ReturnsError(t *testing.T) {
+	b := seriesBackup()
+	b.books[1].VolumeNumber = nil
+	expectInvalid(t, b.encode(t), 4, "volume_number")
+}
+
+func TestValidator_WithDuplicateSeries_ReturnsError(t *testing.T) {
+	b := seriesBackup()
+	b.series = append(b.series, SeriesEvent{EventID: "s2", SeriesID: "series-1", EventType: "created", Title: "進撃の巨人", OccurredAt: "2024-01-01T00:00:00Z"})
+	expectInvalid(t, b.encode(t), 3, "created twice")
+}
+
+func TestValidator_WithAccessionNumbers_ReturnsCounts(t *testing.T) {
+	counts, err := validate(accessionBackup().encode(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts != (Counts{BookEvents: 4}) {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+}
+
+func TestValidator_WithDuplicateAccessionNumber_ReturnsError(t *testing.T) {
+	b := accessionBackup()
+	b.books[3].AccessionNumber = ptr("HC-000001")
+	expectInvalid(t, b.encode(t), 5, "assigned twice")
+}
+
+func TestValidator_WithMalformedAccessionNumber_ReturnsError(t *testing.T) {
+	b := accessionBackup()
+	b.books[1].AccessionNumber = ptr("000001")
+	expectInvalid(t, b.encode(t), 3, "accession_number")
+}
+
+func TestValidator_WithLibrarianLendingEvents_ReturnsCounts(t *testing.T) {
+	counts, err := validate(librarianBackup().encode(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts != (Counts{UserEvents: 2, BookEvents: 4, LendingEvents: 7}) {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+}
+
+func TestValidator_WithForceReturnWithoutReason_ReturnsError(t *testing.T) {
+	b := librarianBackup()
+	b.lendings[6].Reason = nil
+	expectInvalid(t, b.encode(t), 14, "reason is required")
+}
+
+func TestValidator_WithLibrarianEventWithoutActor_ReturnsError(t *testing.T) {
+	b := librarianBackup()
+	b.lendings[5].ActorID = nil
+	expectInvalid(t, b.encode(t), 13, "actor_id is required")
+}
+
+func TestValidator_WithLoanOpenedBeforeTransfer_ReturnsError(t *testing.T) {
+	b := librarianBackup()
+	b.lendings[4], b.lendings[5] = b.lendings[5], b.lendings[4]
+	expectInvalid(t, b.encode(t), 12, "already lent")
+}
+
+func TestValidator_WithLostBook_ReturnsCounts(t *testing.T) {
+	counts, err := validate(lossBackup().encode(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts != (Counts{UserEvents: 1, BookEvents: 6, LendingEvents: 5}) {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+}
+
+func TestValidator_WithUnknownCondition_ReturnsError(t *testing.T) {
+	b := lossBackup()
+	b.books[4].Condition = ptr("broken")
+	expectInvalid(t, b.encode(t), 7, "condition must be")
+}
+
+func TestValidator_WithConditionOfUnknownLoan_ReturnsError(t *testing.T) {
+	b := lossBackup()
+	b.books[4].LendingID = ptr("lending-9")
+	expectInvalid(t, b.encode(t), 7, "is not a loan of book book-1")
+}
+
+func TestValidator_WithConditionWithoutLoan_Succeeds(t *testing.T) {
+	// Conditions recorded before version 8 do not name their loan.
+	b := lossBackup()
+	b.books[4].LendingID = nil
+	if _, err := validate(b.encode(t)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestValidator_WithEventAfterLost_ReturnsError(t *testing.T) {
+	b := lossBackup()
+	b.lendings = append(b.lendings, LendingEvent{EventID: "l6", LendingID: "lending-2", BookID: "book-1", BorrowerID: "user-1", EventType: "returned", OccurredAt: "2024-01-10T00:00:00Z"})
+	expectInvalid(t, b.encode(t), 14, "is not open")
+}
+
+func TestValidator_WithTablesOutOfOrder_ReturnsError(t *testing.T) {
+	lines := strings.Split(validBackup().encode(t), "\n")
+	lines[1], lines[2] = lines[2], lines[1]
+	expectInvalid(t, strings.Join(lines, "\n"), 3, "must come before")
+}
+
+func TestValidator_WithUploadedCover_ReturnsCounts(t *testing.T) {
+	counts, err := validate(coverBackup().encode(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts != (Counts{BookEvents: 2, CoverImages: 2}) {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+}
+
+func TestValidator_WithCoverOfNoEvent_ReturnsError(t *testing.T) {
+	b := coverBackup()
+	b.covers[1].CoverID = "cover-2"
+	expectInvalid(t, b.encode(t), 5, "not set by any cover_changed event")
+}
+
+func TestValidator_WithDuplicateCoverVariant_ReturnsError(t *testing.T) {
+	b := coverBackup()
+	b.covers[1].Variant = "original"
+	expectInvalid(t, b.encode(t), 5, "original variant twice")
+}
+
+func TestReader_WithUnknownField_ReturnsError(t *testing.T) {
+	data := strings.Replace(validBackup().encode(t), `"name":"山田"`, `"name":"山田","role":"admin"`, 1)
+	expectInvalid(t, data, 2, "malformed event")
+}
+
+func TestReader_WithMalformedJSON_ReturnsError(t *testing.T) {
+	lines := strings.Split(validBackup().encode(t), "\n")
+	lines[3] = lines[3][:20]
+	expectInvalid(t, strings.Join(lines, "\n"), 4, "malformed JSON")
+}
@@ -0,0 +1,252 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"holocron/internal/backup/domain"
+)
+
+var (
+	ErrDatabaseNotEmpty = errors.New("database already has events")
+	ErrAuditInProgress  = errors.New("an inventory audit is in progress")
+)
+
+// Projection is a read model derived from the event tables. It is rebuilt after a restore.
+type Projection struct {
+	Name    string
+	Rebuild func(ctx context.Context) error
+}
+
+type RestoreInput struct {
+	Backup io.ReadSeeker
+	// Replace deletes the existing events before restoring. Without it the database must have no events.
+	Replace bool
+}
+
+type RestoreOutput struct {
+	SchemaVersion      int
+	BackupCreatedAt    string
+	Counts             domain.Counts
+	RebuiltProjections []string
+}
+
+type RestoreService struct {
+	db          *sql.DB
+	queries     *Queries
+	projections []Projection
+}
+
+func NewRestoreService(db *sql.DB, projections ...Projection) *RestoreService {
+	return &RestoreService{db: db, queries: New(db), projections: projections}
+}
+
+// Restore validates the whole backup first and writes nothing if any event is invalid.
+// The events are then written in a single transaction and the projections are rebuilt.
+// Uploaded cover images overwrite the ones with the same cover ID, and replace
+// keeps the existing ones, which a backup older than version 7 still refers to.
+// Audit sessions are not in the backup. Restore refuses while one is open, since
+// its snapshot and scans refer to the books being replaced; finished sessions are
+// kept as they are.
+func (s *RestoreService) Restore(ctx context.Context, input RestoreInput) (*RestoreOutput, error) {
+	header, counts, err := validate(input.Backup)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := input.Backup.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if !input.Replace {
+		existing, err := s.queries.CountAllEvents(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if existing > 0 {
+			return nil, ErrDatabaseNotEmpty
+		}
+	}
+
+	if err := s.write(ctx, input.Backup, input.Replace); err != nil {
+		return nil, err
+	}
+
+	output := &RestoreOutput{
+		SchemaVersion:      header.SchemaVersion,
+		BackupCreatedAt:    header.CreatedAt,
+		Counts:             counts,
+		RebuiltProjections: []string{},
+	}
+	for _, projection := range s.projections {
+		if err := projection.Rebuild(ctx); err != nil {
+			return nil, fmt.Errorf("rebuild %s: %w", projection.Name, err)
+		}
+		output.RebuiltProjections = append(output.RebuiltProjections, projection.Name)
+	}
+	return output, nil
+}
+
+func validate(r io.Reader) (*domain.Header, domain.Counts, error) {
+	reader := domain.NewReader(r)
+	validator := domain.NewValidator()
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return validator.Finish()
+		}
+		if err != nil {
+			return nil, domain.Counts{}, err
+		}
+		if err := validator.Check(record); err != nil {
+			return nil, domain.Counts{}, err
+		}
+	}
+}
+
+func (s *RestoreService) write(ctx context.Context, r io.Reader, replace bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries := s.queries.WithTx(tx)
+
+	open, err := queries.CountOpenAuditSessions(ctx)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return ErrAuditInProgress
+	}
+
+	if replace {
+		for _, deleteAll := range []func(context.Context) error{
+			queries.DeleteAllLendingEvents,
+			queries.DeleteAllBookEvents,
+			queries.DeleteAllSeriesEvents,
+			queries.DeleteAllCategoryEvents,
+			queries.DeleteAllUserEvents,
+		} {
+			if err := deleteAll(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	reader := domain.NewReader(r)
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := insertRecord(ctx, queries, record); err != nil {
+			return fmt.Errorf("line %d: %w", record.Line, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func insertRecord(ctx context.Context, queries *Queries, record *domain.Record) error {
+	switch {
+	case record.UserEvent != nil:
+		e := record.UserEvent
+		return queries.RestoreUserEvent(ctx, RestoreUserEventParams{
+			EventID:    e.EventID,
+			UserID:     e.UserID,
+			EventType:  e.EventType,
+			Name:       e.Name,
+			OccurredAt: e.OccurredAt,
+		})
+	case record.CategoryEvent != nil:
+		e := record.CategoryEvent
+		return queries.RestoreCategoryEvent(ctx, RestoreCategoryEventParams{
+			EventID:    e.EventID,
+			CategoryID: e.CategoryID,
+			EventType:  e.EventType,
+			ParentID:   ptrToNullString(e.ParentID),
+			Code:       ptrToNullString(e.Code),
+			Name:       e.Name,
+			OccurredAt: e.OccurredAt,
+		})
+	case record.SeriesEvent != nil:
+		e := record.SeriesEvent
+		return queries.RestoreSeriesEvent(ctx, RestoreSeriesEventParams{
+			EventID:    e.EventID,
+			SeriesID:   e.SeriesID,
+			EventType:  e.EventType,
+			Title:      e.Title,
+			OccurredAt: e.OccurredAt,
+		})
+	case record.BookEvent != nil:
+		e := record.BookEvent
+		return queries.RestoreBookEvent(ctx, RestoreBookEventParams{
+			EventID:         e.EventID,
+			BookID:          e.BookID,
+			EventType:       e.EventType,
+			Code:            ptrToNullString(e.Code),
+			Title:           ptrToNullString(e.Title),
+			Authors:         ptrToNullString(e.Authors),
+			Publisher:       ptrToNullString(e.Publisher),
+			PublishedDate:   ptrToNullString(e.PublishedDate),
+			ThumbnailUrl:    ptrToNullString(e.ThumbnailURL),
+			DeleteReason:    ptrToNullString(e.DeleteReason),
+			DeleteMemo:      ptrToNullString(e.DeleteMemo),
+			Origin:          ptrToNullString(e.Origin),
+			CoverID:         ptrToNullString(e.CoverID),
+			Tags:            ptrToNullString(e.Tags),
+			CategoryID:      ptrToNullString(e.CategoryID),
+			ShelfLocation:   ptrToNullString(e.ShelfLocation),
+			SeriesID:        ptrToNullString(e.SeriesID),
+			VolumeNumber:    ptrToNullInt64(e.VolumeNumber),
+			AccessionNumber: ptrToNullString(e.AccessionNumber),
+			Condition:       ptrToNullString(e.Condition),
+			ConditionNote:   ptrToNullString(e.ConditionNote),
+			LendingID:       ptrToNullString(e.LendingID),
+			OccurredAt:      e.OccurredAt,
+		})
+	case record.LendingEvent != nil:
+		e := record.LendingEvent
+		return queries.RestoreLendingEvent(ctx, RestoreLendingEventParams{
+			EventID:    e.EventID,
+			LendingID:  e.LendingID,
+			BookID:     e.BookID,
+			BorrowerID: e.BorrowerID,
+			EventType:  e.EventType,
+			DueDate:    ptrToNullString(e.DueDate),
+			ActorID:    ptrToNullString(e.ActorID),
+			Reason:     ptrToNullString(e.Reason),
+			OccurredAt: e.OccurredAt,
+		})
+	case record.CoverImage != nil:
+		c := record.CoverImage
+		return queries.RestoreCoverImage(ctx, RestoreCoverImageParams{
+			Source:      uploadedCoverPrefix + c.CoverID,
+			Variant:     c.Variant,
+			ContentType: c.ContentType,
+			Data:        c.Data,
+			Etag:        c.ETag,
+			CreatedAt:   c.CreatedAt,
+		})
+	}
+	return nil
+}
+
+func ptrToNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func ptrToNullInt64(n *int64) sql.NullInt64 {
+	if n == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *n, Valid: true}
+}
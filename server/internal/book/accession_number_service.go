@@ -0,0 +1,53 @@
+package book
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AssignAccessionNumberOutput struct {
+	AccessionNumber string
+	// Assigned is false when the book already had a number, which is kept.
+	Assigned bool
+}
+
+// AssignAccessionNumber gives a book the next accession number with an
+// accession_assigned event. A book keeps its number once assigned, so labels
+// printed for it stay valid. The number is looked up and assigned in one
+// transaction, so that two concurrent requests cannot both give the book one.
+func AssignAccessionNumber(ctx context.Context, db *sql.DB, queries *Queries, bookID string) (*AssignAccessionNumberOutput, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries = queries.WithTx(tx)
+
+	if err := ensureBookExists(ctx, queries, bookID); err != nil {
+		return nil, err
+	}
+
+	existing, err := queries.GetBookAccessionNumber(ctx, bookID)
+	if err == nil && existing.Valid {
+		return &AssignAccessionNumberOutput{AccessionNumber: existing.String}, nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// The number is computed and inserted in one statement, so concurrent
+	// assignments cannot take the same number.
+	number, err := queries.InsertBookAccessionAssignedEvent(ctx, InsertBookAccessionAssignedEventParams{
+		EventID:    uuid.New().String(),
+		BookID:     bookID,
+		OccurredAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AssignAccessionNumberOutput{AccessionNumber: number.String, Assigned: true}, tx.Commit()
+}
@@ -0,0 +1,70 @@
+//go:build medium
+
+package book
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAssignAccessionNumber_WithBook_ReturnsNumberOnGetBook(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	insertClassifyTestBook(t, db, "book-1")
+
+	output, err := AssignAccessionNumber(ctx, db, queries, "book-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !output.Assigned || output.AccessionNumber != "HC-000001" {
+		t.Errorf("expected HC-000001 to be assigned, got %+v", output)
+	}
+
+	book, err := GetBook(ctx, queries, GetBookInput{BookID: "book-1"})
+	if err != nil {
+		t.Fatalf("failed to get book: %v", err)
+	}
+	if book.AccessionNumber == nil || *book.AccessionNumber != "HC-000001" {
+		t.Errorf("expected HC-000001 on GetBook, got %v", book.AccessionNumber)
+	}
+}
+
+func TestAssignAccessionNumber_WithNumberedBook_KeepsNumber(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	insertClassifyTestBook(t, db, "book-1")
+	insertClassifyTestBook(t, db, "book-2")
+
+	first, err := AssignAccessionNumber(ctx, db, queries, "book-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := AssignAccessionNumber(ctx, db, queries, "book-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again, err := AssignAccessionNumber(ctx, db, queries, "book-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if second.AccessionNumber != "HC-000002" {
+		t.Errorf("expected HC-000002, got %s", second.AccessionNumber)
+	}
+	if again.Assigned || again.AccessionNumber != first.AccessionNumber {
+		t.Errorf("expected %s to be kept, got %+v", first.AccessionNumber, again)
+	}
+}
+
+func TestAssignAccessionNumber_WithNonExistentBook_ReturnsNotFoundError(t *testing.T) {
+	db := setupTestDB(t)
+
+	_, err := AssignAccessionNumber(context.Background(), db, New(db), "book-9")
+
+	if !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package book
+
+import (
+	"context"
+	"time"
+)
+
+// BookConditionEntry is a condition recorded when a copy came back from a loan.
+type BookConditionEntry struct {
+	Condition  string
+	Note       *string
+	RecordedAt time.Time
+}
+
+// ListBookConditions returns the conditions recorded for a book, newest first.
+func ListBookConditions(ctx context.Context, queries *Queries, bookID string) ([]BookConditionEntry, error) {
+	if err := ensureBookExists(ctx, queries, bookID); err != nil {
+		return nil, err
+	}
+
+	rows, err := queries.ListBookConditions(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]BookConditionEntry, 0, len(rows))
+	for _, row := range rows {
+		recordedAt, err := time.Parse(time.RFC3339, row.OccurredAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, BookConditionEntry{
+			Condition:  row.Condition.String,
+			Note:       nullStringToPtr(row.ConditionNote),
+			RecordedAt: recordedAt,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,51 @@
+//go:build medium
+
+package book
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestListBookConditions_WithRecordedConditions_ReturnsNewestFirst(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	insertClassifyTestBook(t, db, "book-1")
+
+	for _, params := range []InsertBookConditionEventParams{
+		{EventID: "c1", BookID: "book-1", Condition: sql.NullString{String: "good", Valid: true}, OccurredAt: "2024-02-01T00:00:00Z"},
+		{EventID: "c2", BookID: "book-1", Condition: sql.NullString{String: "damaged", Valid: true}, ConditionNote: sql.NullString{String: "表紙に水濡れ", Valid: true}, OccurredAt: "2024-03-01T00:00:00Z"},
+	} {
+		if err := queries.InsertBookConditionEvent(ctx, params); err != nil {
+			t.Fatalf("failed to insert condition: %v", err)
+		}
+	}
+
+	entries, err := ListBookConditions(ctx, queries, "book-1")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	if entries[0].Condition != "damaged" || entries[0].Note == nil || *entries[0].Note != "表紙に水濡れ" {
+		t.Errorf("unexpected newest entry %+v", entries[0])
+	}
+	if entries[1].Condition != "good" || entries[1].Note != nil {
+		t.Errorf("unexpected oldest entry %+v", entries[1])
+	}
+}
+
+func TestListBookConditions_WithNonExistentBook_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+
+	_, err := ListBookConditions(context.Background(), New(db), "missing")
+
+	if !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+}
@@ -1,12 +1,14 @@
 package book
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"time"
 
 	"holocron/internal/book/domain"
+	"holocron/internal/series"
 
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
@@ -69,6 +71,19 @@ func (h *GetBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookI
 			"borrowedAt": output.Borrower.BorrowedAt.Format(time.RFC3339),
 		}
 	}
+	resp["tags"] = output.Tags
+	if output.Category != nil {
+		resp["category"] = toCategoryResponse(output.Category)
+	}
+	if output.ShelfLocation != nil {
+		resp["shelfLocation"] = *output.ShelfLocation
+	}
+	if output.Series != nil {
+		resp["series"] = toSeriesResponse(output.Series)
+	}
+	if output.AccessionNumber != nil {
+		resp["accessionNumber"] = *output.AccessionNumber
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -76,12 +91,14 @@ func (h *GetBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookI
 }
 
 type UpdateBookHandler struct {
-	queries *Queries
+	queries       *Queries
+	seriesQueries *series.Queries
 }
 
-func NewUpdateBookHandler(queries *Queries) *UpdateBookHandler {
+func NewUpdateBookHandler(queries *Queries, seriesQueries *series.Queries) *UpdateBookHandler {
 	return &UpdateBookHandler{
-		queries: queries,
+		queries:       queries,
+		seriesQueries: seriesQueries,
 	}
 }
 
@@ -93,6 +110,8 @@ func (h *UpdateBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bo
 		Publisher     *string   `json:"publisher"`
 		PublishedDate *string   `json:"publishedDate"`
 		ThumbnailURL  *string   `json:"thumbnailUrl"`
+		// Series is kept raw to tell an explicit null, which removes the series, from an absent field.
+		Series json.RawMessage `json:"series"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -100,7 +119,20 @@ func (h *UpdateBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bo
 		return
 	}
 
-	output, err := UpdateBook(r.Context(), h.queries, UpdateBookInput{
+	var seriesInput *SeriesInput
+	if len(req.Series) > 0 && string(req.Series) != "null" {
+		var s struct {
+			Title  string `json:"title"`
+			Volume int64  `json:"volume"`
+		}
+		if err := json.Unmarshal(req.Series, &s); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+			return
+		}
+		seriesInput = &SeriesInput{Title: s.Title, Volume: s.Volume}
+	}
+
+	output, err := UpdateBook(r.Context(), h.queries, h.seriesQueries, UpdateBookInput{
 		BookID:        bookId.String(),
 		Code:          req.Code,
 		Title:         req.Title,
@@ -108,6 +140,8 @@ func (h *UpdateBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bo
 		Publisher:     req.Publisher,
 		PublishedDate: req.PublishedDate,
 		ThumbnailURL:  req.ThumbnailURL,
+		SetSeries:     len(req.Series) > 0,
+		Series:        seriesInput,
 	})
 
 	if err != nil {
@@ -120,6 +154,8 @@ func (h *UpdateBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bo
 			writeError(w, http.StatusBadRequest, "invalid_request", "title must be 1-200 characters")
 		case errors.Is(err, domain.ErrInvalidAuthors):
 			writeError(w, http.StatusBadRequest, "invalid_request", "authors must have at least one author")
+		case errors.Is(err, domain.ErrInvalidSeries):
+			writeError(w, http.StatusBadRequest, "invalid_request", "series title must be 1-200 characters and volume must be 1-9999")
 		case errors.Is(err, ErrInvalidBookRow):
 			writeError(w, http.StatusInternalServerError, "internal_error", "invalid book data")
 		default:
@@ -148,12 +184,23 @@ func (h *UpdateBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bo
 	if output.ThumbnailURL != nil {
 		resp["thumbnailUrl"] = *output.ThumbnailURL
 	}
+	if output.Series != nil {
+		resp["series"] = toSeriesResponse(output.Series)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+func toSeriesResponse(s *series.BookSeries) map[string]any {
+	return map[string]any{
+		"id":     s.ID,
+		"title":  s.Title,
+		"volume": s.Volume,
+	}
+}
+
 type DeleteBookHandler struct {
 	queries *Queries
 }
@@ -203,6 +250,211 @@ func (h *DeleteBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bo
 	w.WriteHeader(http.StatusNoContent)
 }
 
+type SetBookTagsHandler struct {
+	queries *Queries
+}
+
+func NewSetBookTagsHandler(queries *Queries) *SetBookTagsHandler {
+	return &SetBookTagsHandler{queries: queries}
+}
+
+func (h *SetBookTagsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	var req struct {
+		Tags *[]string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Tags == nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	tags, err := SetBookTags(r.Context(), h.queries, SetBookTagsInput{
+		BookID: bookId.String(),
+		Tags:   *req.Tags,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTags):
+			writeError(w, http.StatusBadRequest, "invalid_request", "tags must be at most 20 tags of 1-50 characters")
+		case errors.Is(err, ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "book not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{"tags": tags})
+}
+
+type SetBookCategoryHandler struct {
+	queries *Queries
+}
+
+func NewSetBookCategoryHandler(queries *Queries) *SetBookCategoryHandler {
+	return &SetBookCategoryHandler{queries: queries}
+}
+
+func (h *SetBookCategoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	var req struct {
+		CategoryID *openapi_types.UUID `json:"categoryId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	input := SetBookCategoryInput{BookID: bookId.String()}
+	if req.CategoryID != nil {
+		categoryID := req.CategoryID.String()
+		input.CategoryID = &categoryID
+	}
+	category, err := SetBookCategory(r.Context(), h.queries, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "book not found")
+		case errors.Is(err, ErrCategoryNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "category not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	resp := map[string]any{}
+	if category != nil {
+		resp["category"] = toCategoryResponse(category)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type SetBookShelfLocationHandler struct {
+	queries *Queries
+}
+
+func NewSetBookShelfLocationHandler(queries *Queries) *SetBookShelfLocationHandler {
+	return &SetBookShelfLocationHandler{queries: queries}
+}
+
+func (h *SetBookShelfLocationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	var req struct {
+		ShelfLocation *string `json:"shelfLocation"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	location, err := SetBookShelfLocation(r.Context(), h.queries, SetBookShelfLocationInput{
+		BookID:        bookId.String(),
+		ShelfLocation: req.ShelfLocation,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidShelfLocation):
+			writeError(w, http.StatusBadRequest, "invalid_request", "shelfLocation must be at most 100 characters")
+		case errors.Is(err, ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "book not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	resp := map[string]any{}
+	if location != nil {
+		resp["shelfLocation"] = *location
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type AssignAccessionNumberHandler struct {
+	db      *sql.DB
+	queries *Queries
+}
+
+func NewAssignAccessionNumberHandler(db *sql.DB, queries *Queries) *AssignAccessionNumberHandler {
+	return &AssignAccessionNumberHandler{db: db, queries: queries}
+}
+
+func (h *AssignAccessionNumberHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	output, err := AssignAccessionNumber(r.Context(), h.db, h.queries, bookId.String())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "book not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if output.Assigned {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"accessionNumber": output.AccessionNumber,
+	})
+}
+
+type ListBookConditionsHandler struct {
+	queries *Queries
+}
+
+func NewListBookConditionsHandler(queries *Queries) *ListBookConditionsHandler {
+	return &ListBookConditionsHandler{queries: queries}
+}
+
+func (h *ListBookConditionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	entries, err := ListBookConditions(r.Context(), h.queries, bookId.String())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "book not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	items := make([]map[string]any, 0, len(entries))
+	for _, entry := range entries {
+		item := map[string]any{
+			"condition":  entry.Condition,
+			"recordedAt": entry.RecordedAt.Format(time.RFC3339),
+		}
+		if entry.Note != nil {
+			item["note"] = *entry.Note
+		}
+		items = append(items, item)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"items": items,
+	})
+}
+
+func toCategoryResponse(category *BookCategory) map[string]any {
+	m := map[string]any{
+		"id":   category.ID,
+		"name": category.Name,
+	}
+	if category.Code != nil {
+		m["code"] = *category.Code
+	}
+	return m
+}
+
 func writeError(w http.ResponseWriter, status int, code, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
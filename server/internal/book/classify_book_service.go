@@ -0,0 +1,145 @@
+package book
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"holocron/internal/book/domain"
+
+	"github.com/google/uuid"
+)
+
+var ErrCategoryNotFound = errors.New("category not found")
+
+type BookCategory struct {
+	ID   string
+	Name string
+	Code *string
+}
+
+type SetBookTagsInput struct {
+	BookID string
+	Tags   []string
+}
+
+// SetBookTags replaces the tags of a book with a tags_changed event.
+func SetBookTags(ctx context.Context, queries *Queries, input SetBookTagsInput) ([]string, error) {
+	tags, err := domain.ParseBookTags(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureBookExists(ctx, queries, input.BookID); err != nil {
+		return nil, err
+	}
+
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return nil, err
+	}
+	err = queries.InsertBookTagsChangedEvent(ctx, InsertBookTagsChangedEventParams{
+		EventID:    uuid.New().String(),
+		BookID:     input.BookID,
+		Tags:       sql.NullString{String: string(tagsJSON), Valid: true},
+		OccurredAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+type SetBookCategoryInput struct {
+	BookID     string
+	CategoryID *string
+}
+
+// SetBookCategory assigns a book to a category with a category_changed event.
+// Without a category ID the book is left uncategorized.
+func SetBookCategory(ctx context.Context, queries *Queries, input SetBookCategoryInput) (*BookCategory, error) {
+	if err := ensureBookExists(ctx, queries, input.BookID); err != nil {
+		return nil, err
+	}
+
+	var category *BookCategory
+	if input.CategoryID != nil {
+		c, err := getCategory(ctx, queries, *input.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		category = c
+	}
+
+	err := queries.InsertBookCategoryChangedEvent(ctx, InsertBookCategoryChangedEventParams{
+		EventID:    uuid.New().String(),
+		BookID:     input.BookID,
+		CategoryID: toNullString(input.CategoryID),
+		OccurredAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+type SetBookShelfLocationInput struct {
+	BookID        string
+	ShelfLocation *string
+}
+
+// SetBookShelfLocation records where a book is kept with a shelf_changed event.
+func SetBookShelfLocation(ctx context.Context, queries *Queries, input SetBookShelfLocationInput) (*string, error) {
+	location, err := domain.ParseShelfLocation(input.ShelfLocation)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureBookExists(ctx, queries, input.BookID); err != nil {
+		return nil, err
+	}
+
+	err = queries.InsertBookShelfChangedEvent(ctx, InsertBookShelfChangedEventParams{
+		EventID:       uuid.New().String(),
+		BookID:        input.BookID,
+		ShelfLocation: toNullString(location),
+		OccurredAt:    time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return location, nil
+}
+
+func ensureBookExists(ctx context.Context, queries *Queries, bookID string) error {
+	count, err := queries.CountBookByBookId(ctx, bookID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrBookNotFound
+	}
+	return nil
+}
+
+func getCategory(ctx context.Context, queries *Queries, categoryID string) (*BookCategory, error) {
+	row, err := queries.GetCategoryByCategoryId(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	category := &BookCategory{ID: row.CategoryID, Name: row.Name}
+	if row.Code.Valid {
+		category.Code = &row.Code.String
+	}
+	return category, nil
+}
+
+func toNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
@@ -0,0 +1,162 @@
+//go:build medium
+
+package book
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"holocron/internal/book/domain"
+)
+
+func insertClassifyTestBook(t *testing.T, db *sql.DB, bookID string) {
+	t.Helper()
+	_, err := db.Exec(`
+		INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at)
+		VALUES (?, ?, 'created', 'Go入門', '["山田太郎"]', '2024-01-01T00:00:00Z')
+	`, "event-"+bookID, bookID)
+	if err != nil {
+		t.Fatalf("failed to insert book: %v", err)
+	}
+}
+
+func TestSetBookTags_WithValidTags_ReturnsTagsOnGetBook(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	insertClassifyTestBook(t, db, "book-1")
+
+	tags, err := SetBookTags(ctx, queries, SetBookTagsInput{BookID: "book-1", Tags: []string{" 小説 ", "SF", "小説"}})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"小説", "SF"}) {
+		t.Errorf("unexpected tags %v", tags)
+	}
+
+	// When the tags are replaced
+	if _, err := SetBookTags(ctx, queries, SetBookTagsInput{BookID: "book-1", Tags: []string{"エッセイ"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then the book has only the latest tags
+	output, err := GetBook(ctx, queries, GetBookInput{BookID: "book-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(output.Tags, []string{"エッセイ"}) {
+		t.Errorf("unexpected tags %v", output.Tags)
+	}
+}
+
+func TestSetBookTags_WithInvalidTag_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	insertClassifyTestBook(t, db, "book-1")
+
+	_, err := SetBookTags(ctx, queries, SetBookTagsInput{BookID: "book-1", Tags: []string{""}})
+
+	if !errors.Is(err, domain.ErrInvalidTags) {
+		t.Errorf("expected ErrInvalidTags, got %v", err)
+	}
+}
+
+func TestSetBookTags_WithNonExistentBook_ReturnsNotFoundError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, err := SetBookTags(ctx, queries, SetBookTagsInput{BookID: "missing", Tags: []string{"SF"}})
+
+	if !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+}
+
+func TestSetBookCategory_WithExistingCategory_ReturnsCategoryOnGetBook(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	insertClassifyTestBook(t, db, "book-1")
+	_, err := db.Exec(`
+		INSERT INTO category_events (event_id, category_id, event_type, code, name, occurred_at)
+		VALUES ('cat-event-1', 'cat-1', 'created', '913', '小説', '2024-01-01T00:00:00Z')
+	`)
+	if err != nil {
+		t.Fatalf("failed to insert category: %v", err)
+	}
+
+	categoryID := "cat-1"
+	category, err := SetBookCategory(ctx, queries, SetBookCategoryInput{BookID: "book-1", CategoryID: &categoryID})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if category == nil || category.Name != "小説" || category.Code == nil || *category.Code != "913" {
+		t.Fatalf("unexpected category %+v", category)
+	}
+	output, err := GetBook(ctx, queries, GetBookInput{BookID: "book-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Category == nil || output.Category.ID != "cat-1" {
+		t.Errorf("unexpected category %+v", output.Category)
+	}
+
+	// When the category is cleared
+	if _, err := SetBookCategory(ctx, queries, SetBookCategoryInput{BookID: "book-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then the book is uncategorized
+	output, err = GetBook(ctx, queries, GetBookInput{BookID: "book-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Category != nil {
+		t.Errorf("expected no category, got %+v", output.Category)
+	}
+}
+
+func TestSetBookCategory_WithNonExistentCategory_ReturnsNotFoundError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	insertClassifyTestBook(t, db, "book-1")
+
+	categoryID := "missing"
+	_, err := SetBookCategory(ctx, queries, SetBookCategoryInput{BookID: "book-1", CategoryID: &categoryID})
+
+	if !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("expected ErrCategoryNotFound, got %v", err)
+	}
+}
+
+func TestSetBookShelfLocation_WithLocation_ReturnsLocationOnGetBook(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	insertClassifyTestBook(t, db, "book-1")
+
+	input := " 書斎 A-3 "
+	location, err := SetBookShelfLocation(ctx, queries, SetBookShelfLocationInput{BookID: "book-1", ShelfLocation: &input})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location == nil || *location != "書斎 A-3" {
+		t.Errorf("unexpected location %v", location)
+	}
+	output, err := GetBook(ctx, queries, GetBookInput{BookID: "book-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.ShelfLocation == nil || *output.ShelfLocation != "書斎 A-3" {
+		t.Errorf("unexpected location %v", output.ShelfLocation)
+	}
+}
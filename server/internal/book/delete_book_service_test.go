@@ -45,6 +45,8 @@ func setupDeleteTestDB(t *testing.T) *sql.DB {
 			borrower_id TEXT NOT NULL,
 			event_type TEXT NOT NULL,
 			due_date TEXT,
+			actor_id TEXT,
+			reason TEXT,
 			occurred_at TEXT NOT NULL
 		);
 		CREATE INDEX idx_lending_events_lending_id ON lending_events(lending_id);
@@ -58,6 +60,144 @@ func setupDeleteTestDB(t *testing.T) *sql.DB {
 			occurred_at TEXT NOT NULL
 		);
 		CREATE INDEX idx_user_events_user_id ON user_events(user_id);
+
+		-- open_lendings has the loans that have not been returned, transferred or
+		-- reported lost. due_date is the one set when the loan was opened.
+		CREATE VIEW open_lendings AS
+		SELECT
+			le.lending_id,
+			le.book_id,
+			le.borrower_id,
+			le.due_date,
+			le.occurred_at as borrowed_at
+		FROM lending_events le
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND NOT EXISTS (
+				SELECT 1
+				FROM lending_events closed
+				WHERE closed.lending_id = le.lending_id
+					AND closed.event_type IN ('returned', 'force_returned', 'transferred', 'lost')
+			);
+
+		CREATE VIEW deleted_books AS
+		SELECT book_id, MAX(occurred_at) as deleted_at
+		FROM book_events
+		WHERE event_type = 'deleted'
+		GROUP BY book_id;
+
+		-- latest_books has the latest details of each book since it was last deleted.
+		-- Events in the same second are ordered as they were recorded.
+		CREATE VIEW latest_books AS
+		SELECT book_id, code, title, authors, publisher, published_date, thumbnail_url, created_at, updated_at
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.code,
+				e1.title,
+				e1.authors,
+				e1.publisher,
+				e1.published_date,
+				e1.thumbnail_url,
+				(SELECT MIN(e_created.occurred_at)
+				 FROM book_events e_created
+				 WHERE e_created.book_id = e1.book_id
+				   AND e_created.event_type = 'created'
+				   AND e_created.occurred_at > COALESCE(d.deleted_at, '1970-01-01T00:00:00Z')
+				) as created_at,
+				e1.occurred_at as updated_at,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('created', 'updated')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- book_classifications has the latest tags_changed, category_changed and
+		-- shelf_changed event of each book, one row per event type.
+		CREATE VIEW book_classifications AS
+		SELECT book_id, event_type, tags, category_id, shelf_location
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.event_type,
+				e1.tags,
+				e1.category_id,
+				e1.shelf_location,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id, e1.event_type ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('tags_changed', 'category_changed', 'shelf_changed')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- current_lendings has the open loan of each book, with its latest due date.
+		CREATE VIEW current_lendings AS
+		SELECT lending_id, book_id, borrower_id, borrower_name, borrowed_at, due_date
+		FROM (
+			SELECT
+				ol.lending_id,
+				ol.book_id,
+				ol.borrower_id,
+				ue.name as borrower_name,
+				ol.borrowed_at,
+				(SELECT due.due_date
+				 FROM lending_events due
+				 WHERE due.lending_id = ol.lending_id
+				   AND due.event_type IN ('borrowed', 'lent', 'due_date_extended')
+				   AND due.due_date IS NOT NULL
+				 ORDER BY due.occurred_at DESC
+				 LIMIT 1
+				) as due_date,
+				ROW_NUMBER() OVER (PARTITION BY ol.book_id ORDER BY ol.borrowed_at DESC) as rn
+			FROM open_lendings ol
+			INNER JOIN user_events ue ON ue.user_id = ol.borrower_id AND ue.event_type = 'created'
+			LEFT JOIN deleted_books d ON ol.book_id = d.book_id
+			WHERE d.deleted_at IS NULL OR ol.borrowed_at > d.deleted_at
+		)
+		WHERE rn = 1;
+
+		-- borrow_counts counts the loans of each book since it was last deleted.
+		CREATE VIEW borrow_counts AS
+		SELECT le.book_id, COUNT(*) as borrow_count
+		FROM lending_events le
+		LEFT JOIN deleted_books d ON le.book_id = d.book_id
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND (d.deleted_at IS NULL OR le.occurred_at > d.deleted_at)
+		GROUP BY le.book_id;
+
+		-- book_list_items joins the current state of each book for the book list, the
+		-- search and the facets, which filter it with the same conditions.
+		CREATE VIEW book_list_items AS
+		SELECT
+			lb.book_id,
+			lb.code,
+			lb.title,
+			lb.authors,
+			lb.publisher,
+			lb.published_date,
+			lb.thumbnail_url,
+			lb.created_at,
+			lb.updated_at,
+			cl.lending_id,
+			cl.borrower_id,
+			cl.borrower_name,
+			cl.borrowed_at,
+			cl.due_date,
+			bt.tags,
+			bcat.category_id,
+			ce.name as category_name,
+			ce.code as category_code,
+			bs.shelf_location,
+			COALESCE(bc.borrow_count, 0) as borrow_count
+		FROM latest_books lb
+		LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id
+		LEFT JOIN book_classifications bt ON lb.book_id = bt.book_id AND bt.event_type = 'tags_changed'
+		LEFT JOIN book_classifications bcat ON lb.book_id = bcat.book_id AND bcat.event_type = 'category_changed'
+		LEFT JOIN book_classifications bs ON lb.book_id = bs.book_id AND bs.event_type = 'shelf_changed'
+		LEFT JOIN category_events ce ON bcat.category_id = ce.category_id AND ce.event_type = 'created'
+		LEFT JOIN borrow_counts bc ON lb.book_id = bc.book_id;
 	`)
 	if err != nil {
 		t.Fatalf("failed to create tables: %v", err)
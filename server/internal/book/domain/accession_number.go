@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidAccessionNumber = errors.New("accession number must be HC- followed by at least 6 digits")
+
+// accessionNumberPattern matches the numbers the database assigns in sequence:
+// HC- and the sequence zero padded to 6 digits, such as HC-000123.
+var accessionNumberPattern = regexp.MustCompile(`^HC-[0-9]{6,}$`)
+
+// AccessionNumber is the internal number given to a book that has no ISBN, so
+// that it can be labelled and scanned like any other book.
+type AccessionNumber string
+
+// ParseAccessionNumber accepts a scanned or typed accession number. Scanners and
+// people may drop the case of the prefix, so it is normalized.
+func ParseAccessionNumber(s string) (AccessionNumber, error) {
+	number := strings.ToUpper(strings.TrimSpace(s))
+	if !accessionNumberPattern.MatchString(number) {
+		return "", ErrInvalidAccessionNumber
+	}
+	return AccessionNumber(number), nil
+}
@@ -0,0 +1,45 @@
+//go:build small
+
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestParseAccessionNumber_WithAssignedNumber_ReturnsIt(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("HC- and the zero padded sequence is accepted", prop.ForAll(
+		func(sequence int) bool {
+			s := fmt.Sprintf("HC-%06d", sequence)
+			number, err := ParseAccessionNumber(s)
+			return err == nil && string(number) == s
+		},
+		gen.IntRange(1, 10_000_000),
+	))
+	properties.TestingRun(t)
+}
+
+func TestParseAccessionNumber_WithLowerCasePrefix_NormalizesIt(t *testing.T) {
+	number, err := ParseAccessionNumber(" hc-000123 ")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if number != "HC-000123" {
+		t.Errorf("expected HC-000123, got %s", number)
+	}
+}
+
+func TestParseAccessionNumber_WithOtherCodes_ReturnsError(t *testing.T) {
+	for _, s := range []string{"", "HC-123", "HC000123", "9784873119045", "550e8400-e29b-41d4-a716-446655440000", "HC-00012a"} {
+		if _, err := ParseAccessionNumber(s); !errors.Is(err, ErrInvalidAccessionNumber) {
+			t.Errorf("%q: expected ErrInvalidAccessionNumber, got %v", s, err)
+		}
+	}
+}
@@ -14,6 +14,8 @@ type BookInfo struct {
 	Publisher     string
 	PublishedDate string
 	ThumbnailURL  string
+	// Volume is the volume number the source reports for a book in a series.
+	Volume string
 }
 
 func BookInfoFromGoogleBooks(body []byte) (*BookInfo, error) {
@@ -28,6 +30,9 @@ func BookInfoFromGoogleBooks(body []byte) (*BookInfo, error) {
 				ImageLinks    struct {
 					Thumbnail string `json:"thumbnail"`
 				} `json:"imageLinks"`
+				SeriesInfo struct {
+					BookDisplayNumber string `json:"bookDisplayNumber"`
+				} `json:"seriesInfo"`
 			} `json:"volumeInfo"`
 		} `json:"items"`
 	}
@@ -47,6 +52,7 @@ func BookInfoFromGoogleBooks(body []byte) (*BookInfo, error) {
 		Publisher:     item.Publisher,
 		PublishedDate: item.PublishedDate,
 		ThumbnailURL:  item.ImageLinks.Thumbnail,
+		Volume:        item.SeriesInfo.BookDisplayNumber,
 	}, nil
 }
 
@@ -58,6 +64,7 @@ func BookInfoFromOpenBD(body []byte) (*BookInfo, error) {
 			Publisher string `json:"publisher"`
 			Pubdate   string `json:"pubdate"`
 			Cover     string `json:"cover"`
+			Volume    string `json:"volume"`
 		} `json:"summary"`
 	}
 
@@ -88,6 +95,7 @@ func BookInfoFromOpenBD(body []byte) (*BookInfo, error) {
 		Publisher:     summary.Publisher,
 		PublishedDate: normalizeDate(summary.Pubdate),
 		ThumbnailURL:  summary.Cover,
+		Volume:        summary.Volume,
 	}, nil
 }
 
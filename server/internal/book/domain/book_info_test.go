@@ -90,6 +90,32 @@ func TestBookInfoFromOpenBD_WithEmptyTitle_ReturnsNotFoundError(t *testing.T) {
 	}
 }
 
+func TestBookInfoFromGoogleBooks_WithSeriesInfo_ReturnsVolume(t *testing.T) {
+	body := []byte(`{"totalItems": 1, "items": [{"volumeInfo": {"title": "進撃の巨人", "seriesInfo": {"bookDisplayNumber": "3"}}}]}`)
+
+	info, err := BookInfoFromGoogleBooks(body)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Volume != "3" {
+		t.Errorf("expected volume 3, got %q", info.Volume)
+	}
+}
+
+func TestBookInfoFromOpenBD_WithVolume_ReturnsVolume(t *testing.T) {
+	body := []byte(`[{"summary": {"title": "進撃の巨人", "volume": "3"}}]`)
+
+	info, err := BookInfoFromOpenBD(body)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Volume != "3" {
+		t.Errorf("expected volume 3, got %q", info.Volume)
+	}
+}
+
 func genGoogleBooksJSON(title, author, publisher string) []byte {
 	resp := map[string]any{
 		"totalItems": 1,
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidSeries = errors.New("series title must be 1-200 characters and volume must be 1-9999")
+
+const maxVolume = 9999
+
+// BookSeries is the series a book belongs to and its volume number in it.
+type BookSeries struct {
+	Title  string
+	Volume int64
+}
+
+func ParseBookSeries(title string, volume int64) (*BookSeries, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > 200 {
+		return nil, ErrInvalidSeries
+	}
+	if volume < 1 || volume > maxVolume {
+		return nil, ErrInvalidSeries
+	}
+	return &BookSeries{Title: title, Volume: volume}, nil
+}
+
+// seriesTitlePatterns match a volume number at the end of a title, such as
+// "進撃の巨人（1）", "鬼滅の刃 23巻", "第3巻" or "Vol. 2". A bare trailing number
+// is not matched, since titles like "Java 17" name a version rather than a volume.
+var seriesTitlePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(.+?)[\s　]*[(（][\s　]*([0-9０-９]+)[\s　]*[)）]$`),
+	regexp.MustCompile(`^(.+?)[\s　]*第?[\s　]*([0-9０-９]+)[\s　]*巻$`),
+	regexp.MustCompile(`(?i)^(.+?)[\s　]*,?[\s　]*vol(?:ume)?\.?[\s　]*([0-9０-９]+)$`),
+}
+
+// DetectBookSeries finds the series of a book from its title, and from the volume
+// number reported by its metadata source when there is one. A metadata volume
+// takes precedence over the one in the title; without any volume the book is
+// not in a series.
+func DetectBookSeries(title string, volume string) *BookSeries {
+	seriesTitle := strings.TrimSpace(title)
+	var detected int64
+	for _, pattern := range seriesTitlePatterns {
+		if m := pattern.FindStringSubmatch(seriesTitle); m != nil {
+			seriesTitle = strings.TrimRight(m[1], " 　:：-－")
+			detected = parseVolume(m[2])
+			break
+		}
+	}
+	if v := parseVolume(volume); v > 0 {
+		if detected == 0 {
+			// The source may report the volume that also ends the title, as in "ONE PIECE 100".
+			if prefix, ok := strings.CutSuffix(seriesTitle, strconv.FormatInt(v, 10)); ok && strings.HasSuffix(prefix, " ") {
+				seriesTitle = strings.TrimRight(prefix, " 　:：-－")
+			}
+		}
+		detected = v
+	}
+	if detected == 0 {
+		return nil
+	}
+	series, err := ParseBookSeries(seriesTitle, detected)
+	if err != nil {
+		return nil
+	}
+	return series
+}
+
+// parseVolume reads a volume number written in half-width or full-width digits,
+// returning 0 when s is not one.
+func parseVolume(s string) int64 {
+	s = strings.Map(func(r rune) rune {
+		if r >= '０' && r <= '９' {
+			return r - '０' + '0'
+		}
+		return r
+	}, strings.TrimSpace(s))
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 1 || v > maxVolume {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,99 @@
+//go:build small
+
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectBookSeries_WithVolumeInTitle_ReturnsSeries(t *testing.T) {
+	tests := []struct {
+		title  string
+		series string
+		volume int64
+	}{
+		{"進撃の巨人（1）", "進撃の巨人", 1},
+		{"進撃の巨人 (12)", "進撃の巨人", 12},
+		{"鬼滅の刃 23巻", "鬼滅の刃", 23},
+		{"ハリー・ポッター 第3巻", "ハリー・ポッター", 3},
+		{"ONE PIECE（１０５）", "ONE PIECE", 105},
+		{"The Art of Computer Programming, Vol. 2", "The Art of Computer Programming", 2},
+		{"Clean Code Volume 1", "Clean Code", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			series := DetectBookSeries(tt.title, "")
+
+			if series == nil {
+				t.Fatal("expected series, got nil")
+			}
+			if series.Title != tt.series || series.Volume != tt.volume {
+				t.Errorf("expected %s %d, got %s %d", tt.series, tt.volume, series.Title, series.Volume)
+			}
+		})
+	}
+}
+
+func TestDetectBookSeries_WithoutVolume_ReturnsNil(t *testing.T) {
+	for _, title := range []string{"Go言語によるWebアプリケーション開発", "Java 17", "1984"} {
+		if series := DetectBookSeries(title, ""); series != nil {
+			t.Errorf("expected nil for %q, got %+v", title, series)
+		}
+	}
+}
+
+func TestDetectBookSeries_WithMetadataVolume_PrefersMetadata(t *testing.T) {
+	series := DetectBookSeries("進撃の巨人（1）", "2")
+
+	if series == nil || series.Title != "進撃の巨人" || series.Volume != 2 {
+		t.Errorf("expected 進撃の巨人 2, got %+v", series)
+	}
+}
+
+func TestDetectBookSeries_WithMetadataVolumeEndingTitle_StripsVolume(t *testing.T) {
+	series := DetectBookSeries("ONE PIECE 100", "100")
+
+	if series == nil || series.Title != "ONE PIECE" || series.Volume != 100 {
+		t.Errorf("expected ONE PIECE 100, got %+v", series)
+	}
+}
+
+func TestDetectBookSeries_WithInvalidMetadataVolume_IgnoresIt(t *testing.T) {
+	if series := DetectBookSeries("Java 17", "上"); series != nil {
+		t.Errorf("expected nil, got %+v", series)
+	}
+}
+
+func TestParseBookSeries_WithValidSeries_ReturnsTrimmedTitle(t *testing.T) {
+	series, err := ParseBookSeries(" 進撃の巨人 ", 9999)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if series.Title != "進撃の巨人" || series.Volume != 9999 {
+		t.Errorf("unexpected series %+v", series)
+	}
+}
+
+func TestParseBookSeries_WithInvalidSeries_ReturnsError(t *testing.T) {
+	tests := []struct {
+		name   string
+		title  string
+		volume int64
+	}{
+		{"blank title", " ", 1},
+		{"too long title", strings.Repeat("巻", 201), 1},
+		{"zero volume", "進撃の巨人", 0},
+		{"too large volume", "進撃の巨人", 10000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBookSeries(tt.title, tt.volume)
+
+			if err != ErrInvalidSeries {
+				t.Errorf("expected ErrInvalidSeries, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidTags = errors.New("tags must be at most 20 tags of 1-50 characters")
+
+const (
+	maxTags      = 20
+	maxTagLength = 50
+)
+
+// ParseBookTags trims each tag and drops repeated ones, keeping the first
+// occurrence. An empty list removes every tag.
+func ParseBookTags(tags []string) ([]string, error) {
+	parsed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, ErrInvalidTags
+		}
+		if !slices.Contains(parsed, tag) {
+			parsed = append(parsed, tag)
+		}
+	}
+	if len(parsed) > maxTags {
+		return nil, ErrInvalidTags
+	}
+	return parsed, nil
+}
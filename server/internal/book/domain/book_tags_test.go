@@ -0,0 +1,72 @@
+//go:build small
+
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBookTags_WithValidTags_ReturnsTrimmedTags(t *testing.T) {
+	tags, err := ParseBookTags([]string{" 小説 ", "SF"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"小説", "SF"}) {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestParseBookTags_WithDuplicateTags_KeepsFirstOccurrence(t *testing.T) {
+	tags, err := ParseBookTags([]string{"SF", "小説", "SF"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"SF", "小説"}) {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestParseBookTags_WithEmptySlice_ReturnsEmptyTags(t *testing.T) {
+	tags, err := ParseBookTags(nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tags == nil || len(tags) != 0 {
+		t.Errorf("expected empty tags, got %v", tags)
+	}
+}
+
+func TestParseBookTags_WithBlankTag_ReturnsError(t *testing.T) {
+	_, err := ParseBookTags([]string{"SF", "  "})
+
+	if err != ErrInvalidTags {
+		t.Errorf("expected ErrInvalidTags, got %v", err)
+	}
+}
+
+func TestParseBookTags_WithTooLongTag_ReturnsError(t *testing.T) {
+	_, err := ParseBookTags([]string{strings.Repeat("あ", 51)})
+
+	if err != ErrInvalidTags {
+		t.Errorf("expected ErrInvalidTags, got %v", err)
+	}
+}
+
+func TestParseBookTags_WithTooManyTags_ReturnsError(t *testing.T) {
+	tags := make([]string, 21)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag%d", i)
+	}
+
+	_, err := ParseBookTags(tags)
+
+	if err != ErrInvalidTags {
+		t.Errorf("expected ErrInvalidTags, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const MaxConditionNoteLength = 500
+
+var (
+	ErrInvalidCondition     = errors.New("condition must be one of: good, damaged, missing_pages")
+	ErrInvalidConditionNote = errors.New("condition note must be at most 500 characters")
+)
+
+// Condition is the state of a copy as checked when it comes back from a loan.
+type Condition string
+
+const (
+	ConditionGood         Condition = "good"
+	ConditionDamaged      Condition = "damaged"
+	ConditionMissingPages Condition = "missing_pages"
+)
+
+func ParseCondition(s string) (Condition, error) {
+	switch s {
+	case string(ConditionGood):
+		return ConditionGood, nil
+	case string(ConditionDamaged):
+		return ConditionDamaged, nil
+	case string(ConditionMissingPages):
+		return ConditionMissingPages, nil
+	default:
+		return "", ErrInvalidCondition
+	}
+}
+
+// ParseConditionNote returns the note without surrounding spaces. An empty note
+// is valid and means there is nothing to add to the condition.
+func ParseConditionNote(s string) (string, error) {
+	note := strings.TrimSpace(s)
+	if utf8.RuneCountInString(note) > MaxConditionNoteLength {
+		return "", ErrInvalidConditionNote
+	}
+	return note, nil
+}
@@ -0,0 +1,59 @@
+//go:build small
+
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+// When ParseCondition with valid condition then returns Condition
+func TestParseCondition_WithValidCondition_ReturnsCondition(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("returns Condition with same value", prop.ForAll(
+		func(s string) bool {
+			condition, err := ParseCondition(s)
+			return err == nil && string(condition) == s
+		},
+		gen.OneConstOf(string(ConditionGood), string(ConditionDamaged), string(ConditionMissingPages)),
+	))
+	properties.TestingRun(t)
+}
+
+// When ParseCondition with invalid condition then returns error
+func TestParseCondition_WithInvalidCondition_ReturnsError(t *testing.T) {
+	for _, s := range []string{"", "bad", "Good", "missing-pages", " damaged"} {
+		if _, err := ParseCondition(s); !errors.Is(err, ErrInvalidCondition) {
+			t.Errorf("expected ErrInvalidCondition for %q, got %v", s, err)
+		}
+	}
+}
+
+// When ParseConditionNote with note up to the limit then returns trimmed note
+func TestParseConditionNote_WithValidNote_ReturnsTrimmedNote(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("returns the note without surrounding spaces", prop.ForAll(
+		func(note string) bool {
+			parsed, err := ParseConditionNote(" " + note + "\n")
+			return err == nil && parsed == note
+		},
+		gen.AlphaString().SuchThat(func(s string) bool {
+			return utf8.RuneCountInString(s) <= MaxConditionNoteLength
+		}),
+	))
+	properties.TestingRun(t)
+}
+
+// When ParseConditionNote with too long note then returns error
+func TestParseConditionNote_WithTooLongNote_ReturnsError(t *testing.T) {
+	_, err := ParseConditionNote(strings.Repeat("頁", MaxConditionNoteLength+1))
+	if !errors.Is(err, ErrInvalidConditionNote) {
+		t.Errorf("expected ErrInvalidConditionNote, got %v", err)
+	}
+}
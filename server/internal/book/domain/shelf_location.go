@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidShelfLocation = errors.New("shelf location must be at most 100 characters")
+
+// ParseShelfLocation parses a free-form location such as "書斎 A-3". A blank
+// location clears it.
+func ParseShelfLocation(s *string) (*string, error) {
+	if s == nil {
+		return nil, nil
+	}
+	location := strings.TrimSpace(*s)
+	if location == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(location) > 100 {
+		return nil, ErrInvalidShelfLocation
+	}
+	return &location, nil
+}
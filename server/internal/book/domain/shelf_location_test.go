@@ -0,0 +1,44 @@
+//go:build small
+
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseShelfLocation_WithLocation_ReturnsTrimmedLocation(t *testing.T) {
+	input := " 書斎 A-3 "
+
+	location, err := ParseShelfLocation(&input)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location == nil || *location != "書斎 A-3" {
+		t.Errorf("expected 書斎 A-3, got %v", location)
+	}
+}
+
+func TestParseShelfLocation_WithBlankLocation_ReturnsNil(t *testing.T) {
+	input := "  "
+
+	location, err := ParseShelfLocation(&input)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location != nil {
+		t.Errorf("expected nil, got %q", *location)
+	}
+}
+
+func TestParseShelfLocation_WithTooLongLocation_ReturnsError(t *testing.T) {
+	input := strings.Repeat("棚", 101)
+
+	_, err := ParseShelfLocation(&input)
+
+	if err != ErrInvalidShelfLocation {
+		t.Errorf("expected ErrInvalidShelfLocation, got %v", err)
+	}
+}
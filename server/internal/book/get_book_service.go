@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"time"
+
+	"holocron/internal/series"
 )
 
 var (
@@ -26,16 +28,21 @@ type Borrower struct {
 }
 
 type GetBookOutput struct {
-	ID            string
-	Code          *string
-	Title         string
-	Authors       []string
-	Publisher     *string
-	PublishedDate *string
-	ThumbnailURL  *string
-	Status        string
-	Borrower      *Borrower
-	CreatedAt     time.Time
+	ID              string
+	Code            *string
+	Title           string
+	Authors         []string
+	Publisher       *string
+	PublishedDate   *string
+	ThumbnailURL    *string
+	Status          string
+	Borrower        *Borrower
+	Tags            []string
+	Category        *BookCategory
+	ShelfLocation   *string
+	Series          *series.BookSeries
+	AccessionNumber *string
+	CreatedAt       time.Time
 }
 
 func GetBook(ctx context.Context, queries *Queries, input GetBookInput) (*GetBookOutput, error) {
@@ -88,17 +95,70 @@ func GetBook(ctx context.Context, queries *Queries, input GetBookInput) (*GetBoo
 		return nil, err
 	}
 
+	classification, err := queries.GetBookClassification(ctx, input.BookID)
+	if err != nil {
+		return nil, err
+	}
+	tags := []string{}
+	if classification.Tags.Valid {
+		if err := json.Unmarshal([]byte(classification.Tags.String), &tags); err != nil {
+			return nil, ErrInvalidBookRow
+		}
+	}
+	var category *BookCategory
+	if classification.CategoryID.Valid {
+		category, err = getCategory(ctx, queries, classification.CategoryID.String)
+		if err != nil && !errors.Is(err, ErrCategoryNotFound) {
+			return nil, err
+		}
+	}
+
+	bookSeries, err := findBookSeries(ctx, queries, input.BookID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessionNumber, err := queries.GetBookAccessionNumber(ctx, input.BookID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
 	return &GetBookOutput{
-		ID:            row.BookID,
-		Code:          nullStringToPtr(row.Code),
-		Title:         row.Title.String,
-		Authors:       authors,
-		Publisher:     nullStringToPtr(row.Publisher),
-		PublishedDate: nullStringToPtr(row.PublishedDate),
-		ThumbnailURL:  nullStringToPtr(row.ThumbnailUrl),
-		Status:        status,
-		Borrower:      borrower,
-		CreatedAt:     createdAt,
+		ID:              row.BookID,
+		Code:            nullStringToPtr(row.Code),
+		Title:           row.Title.String,
+		Authors:         authors,
+		Publisher:       nullStringToPtr(row.Publisher),
+		PublishedDate:   nullStringToPtr(row.PublishedDate),
+		ThumbnailURL:    nullStringToPtr(row.ThumbnailUrl),
+		Status:          status,
+		Borrower:        borrower,
+		Tags:            tags,
+		Category:        category,
+		ShelfLocation:   nullStringToPtr(classification.ShelfLocation),
+		Series:          bookSeries,
+		AccessionNumber: nullStringToPtr(accessionNumber),
+		CreatedAt:       createdAt,
+	}, nil
+}
+
+// findBookSeries returns nil for a book that was never put in a series or was
+// taken out of it.
+func findBookSeries(ctx context.Context, queries *Queries, bookID string) (*series.BookSeries, error) {
+	row, err := queries.GetBookSeries(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !row.SeriesID.Valid || !row.Title.Valid {
+		return nil, nil
+	}
+	return &series.BookSeries{
+		ID:     row.SeriesID.String,
+		Title:  row.Title.String,
+		Volume: row.VolumeNumber.Int64,
 	}, nil
 }
 
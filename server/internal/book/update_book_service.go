@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"holocron/internal/book/domain"
+	"holocron/internal/series"
 
 	"github.com/google/uuid"
 )
@@ -20,6 +21,15 @@ type UpdateBookInput struct {
 	Publisher     *string
 	PublishedDate *string
 	ThumbnailURL  *string
+	// SetSeries replaces the series of the book with Series. A nil Series takes
+	// the book out of its series.
+	SetSeries bool
+	Series    *SeriesInput
+}
+
+type SeriesInput struct {
+	Title  string
+	Volume int64
 }
 
 type UpdateBookOutput struct {
@@ -31,11 +41,12 @@ type UpdateBookOutput struct {
 	PublishedDate *string
 	ThumbnailURL  *string
 	Status        string
+	Series        *series.BookSeries
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
 
-func UpdateBook(ctx context.Context, queries *Queries, input UpdateBookInput) (*UpdateBookOutput, error) {
+func UpdateBook(ctx context.Context, queries *Queries, seriesQueries *series.Queries, input UpdateBookInput) (*UpdateBookOutput, error) {
 	currentBook, err := queries.GetBookByBookId(ctx, input.BookID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -92,6 +103,14 @@ func UpdateBook(ctx context.Context, queries *Queries, input UpdateBookInput) (*
 		updatedThumbnailURL = sql.NullString{String: *input.ThumbnailURL, Valid: true}
 	}
 
+	var updatedSeries *domain.BookSeries
+	if input.SetSeries && input.Series != nil {
+		updatedSeries, err = domain.ParseBookSeries(input.Series.Title, input.Series.Volume)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	authorsJSON, err := json.Marshal(updatedAuthors)
 	if err != nil {
 		return nil, err
@@ -115,6 +134,16 @@ func UpdateBook(ctx context.Context, queries *Queries, input UpdateBookInput) (*
 		return nil, err
 	}
 
+	var bookSeries *series.BookSeries
+	if input.SetSeries {
+		bookSeries, err = series.AssignBookSeries(ctx, seriesQueries, input.BookID, updatedSeries)
+	} else {
+		bookSeries, err = findBookSeries(ctx, queries, input.BookID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	createdAtStr, ok := currentBook.CreatedAt.(string)
 	if !ok || createdAtStr == "" {
 		return nil, ErrInvalidBookRow
@@ -129,6 +158,7 @@ func UpdateBook(ctx context.Context, queries *Queries, input UpdateBookInput) (*
 		Title:     updatedTitle.String,
 		Authors:   updatedAuthors,
 		Status:    "available",
+		Series:    bookSeries,
 		CreatedAt: createdAt,
 		UpdatedAt: now,
 	}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"holocron/internal/book/domain"
+	"holocron/internal/series"
 
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
@@ -42,7 +43,7 @@ func TestUpdateBook_WithAllFields_UpdatesBook(t *testing.T) {
 	expectedPublishedDate := fmt.Sprintf("%04d-%02d-%02d", 2020+rand.Intn(5), 1+rand.Intn(12), 1+rand.Intn(28))
 	expectedThumbnailURL := "https://example.com/" + uuid.New().String() + ".jpg"
 
-	output, err := UpdateBook(ctx, queries, UpdateBookInput{
+	output, err := UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
 		BookID:        bookID,
 		Code:          &expectedCode,
 		Title:         &expectedTitle,
@@ -113,7 +114,7 @@ func TestUpdateBook_WithPartialFields_UpdatesOnlySpecifiedFields(t *testing.T) {
 	}
 
 	expectedTitle := uuid.New().String()
-	output, err := UpdateBook(ctx, queries, UpdateBookInput{
+	output, err := UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
 		BookID: bookID,
 		Title:  &expectedTitle,
 	})
@@ -144,7 +145,7 @@ func TestUpdateBook_WithNonExistentBook_ReturnsNotFoundError(t *testing.T) {
 
 	nonExistentBookID := uuid.New().String()
 	title := uuid.New().String()
-	_, err := UpdateBook(ctx, queries, UpdateBookInput{
+	_, err := UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
 		BookID: nonExistentBookID,
 		Title:  &title,
 	})
@@ -173,7 +174,7 @@ func TestUpdateBook_WithNoFields_CreatesNoChangeEvent(t *testing.T) {
 		t.Fatalf("failed to insert book: %v", err)
 	}
 
-	output, err := UpdateBook(ctx, queries, UpdateBookInput{
+	output, err := UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
 		BookID: bookID,
 	})
 
@@ -223,7 +224,7 @@ func TestUpdateBook_WithInvalidTitle_ReturnsInvalidTitleError(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := UpdateBook(ctx, queries, UpdateBookInput{
+			_, err := UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
 				BookID: bookID,
 				Title:  &tt.title,
 			})
@@ -254,7 +255,7 @@ func TestUpdateBook_WithInvalidAuthors_ReturnsInvalidAuthorsError(t *testing.T)
 	}
 
 	emptyAuthors := []string{}
-	_, err = UpdateBook(ctx, queries, UpdateBookInput{
+	_, err = UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
 		BookID:  bookID,
 		Authors: &emptyAuthors,
 	})
@@ -283,7 +284,7 @@ func TestUpdateBook_WithCodeWhenNoCode_SetsCode(t *testing.T) {
 	}
 
 	newCode := uuid.New().String()
-	output, err := UpdateBook(ctx, queries, UpdateBookInput{
+	output, err := UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
 		BookID: bookID,
 		Code:   &newCode,
 	})
@@ -316,7 +317,7 @@ func TestUpdateBook_WithCodeWhenCodeAlreadySet_ReturnsError(t *testing.T) {
 	}
 
 	newCode := uuid.New().String()
-	_, err = UpdateBook(ctx, queries, UpdateBookInput{
+	_, err = UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
 		BookID: bookID,
 		Code:   &newCode,
 	})
@@ -345,7 +346,7 @@ func TestUpdateBook_WithMultipleUpdates_AppliesLatestUpdate(t *testing.T) {
 	}
 
 	firstTitle := uuid.New().String()
-	_, err = UpdateBook(ctx, queries, UpdateBookInput{
+	_, err = UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
 		BookID: bookID,
 		Title:  &firstTitle,
 	})
@@ -354,7 +355,7 @@ func TestUpdateBook_WithMultipleUpdates_AppliesLatestUpdate(t *testing.T) {
 	}
 
 	secondTitle := uuid.New().String()
-	output, err := UpdateBook(ctx, queries, UpdateBookInput{
+	output, err := UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
 		BookID: bookID,
 		Title:  &secondTitle,
 	})
@@ -376,3 +377,74 @@ func TestUpdateBook_WithMultipleUpdates_AppliesLatestUpdate(t *testing.T) {
 		t.Errorf("expected %d update events, got %d", expectedEventCount, eventCount)
 	}
 }
+
+// When UpdateBook with a series then puts the book in the series, and with a nil series takes it out
+func TestUpdateBook_WithSeries_SetsAndClearsSeries(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	bookID := uuid.New().String()
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at)
+		VALUES (?, ?, 'created', ?, '[]', ?)
+	`, uuid.New().String(), bookID, "進撃の巨人", time.Now().Add(-time.Hour).Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("failed to insert book: %v", err)
+	}
+
+	output, err := UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
+		BookID:    bookID,
+		SetSeries: true,
+		Series:    &SeriesInput{Title: " 進撃の巨人 ", Volume: 3},
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Series == nil || output.Series.Title != "進撃の巨人" || output.Series.Volume != 3 {
+		t.Fatalf("expected 進撃の巨人 3, got %+v", output.Series)
+	}
+	got, err := GetBook(ctx, queries, GetBookInput{BookID: bookID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Series == nil || got.Series.ID != output.Series.ID {
+		t.Errorf("expected series %+v, got %+v", output.Series, got.Series)
+	}
+
+	output, err = UpdateBook(ctx, queries, series.New(db), UpdateBookInput{BookID: bookID, SetSeries: true})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Series != nil {
+		t.Errorf("expected no series, got %+v", output.Series)
+	}
+}
+
+// When UpdateBook with an invalid volume then returns ErrInvalidSeries
+func TestUpdateBook_WithInvalidVolume_ReturnsInvalidSeriesError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	bookID := uuid.New().String()
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at)
+		VALUES (?, ?, 'created', ?, '[]', ?)
+	`, uuid.New().String(), bookID, "進撃の巨人", time.Now().Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("failed to insert book: %v", err)
+	}
+
+	_, err = UpdateBook(ctx, queries, series.New(db), UpdateBookInput{
+		BookID:    bookID,
+		SetSeries: true,
+		Series:    &SeriesInput{Title: "進撃の巨人", Volume: 0},
+	})
+
+	if !errors.Is(err, domain.ErrInvalidSeries) {
+		t.Errorf("expected ErrInvalidSeries, got %v", err)
+	}
+}
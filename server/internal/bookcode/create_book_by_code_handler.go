@@ -1,6 +1,7 @@
 package bookcode
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -8,17 +9,22 @@ import (
 
 	book "holocron/internal/book/domain"
 	"holocron/internal/bookcode/domain"
+	"holocron/internal/series"
 )
 
 type CreateBookByCodeHandler struct {
-	queries *Queries
-	sources []domain.BookInfoSource
+	db            *sql.DB
+	queries       *Queries
+	seriesQueries *series.Queries
+	sources       []domain.BookInfoSource
 }
 
-func NewCreateBookByCodeHandler(queries *Queries, sources []domain.BookInfoSource) *CreateBookByCodeHandler {
+func NewCreateBookByCodeHandler(db *sql.DB, queries *Queries, seriesQueries *series.Queries, sources []domain.BookInfoSource) *CreateBookByCodeHandler {
 	return &CreateBookByCodeHandler{
-		queries: queries,
-		sources: sources,
+		db:            db,
+		queries:       queries,
+		seriesQueries: seriesQueries,
+		sources:       sources,
 	}
 }
 
@@ -31,7 +37,7 @@ func (h *CreateBookByCodeHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	output, err := CreateBookByCode(r.Context(), h.queries, h.sources, CreateBookByCodeInput{
+	output, err := CreateBookByCode(r.Context(), h.db, h.queries, h.seriesQueries, h.sources, CreateBookByCodeInput{
 		Code: req.Code,
 	})
 
@@ -51,9 +57,7 @@ func (h *CreateBookByCodeHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(map[string]any{
+	resp := map[string]any{
 		"id":            output.ID,
 		"code":          output.Code,
 		"title":         output.Title,
@@ -63,7 +67,18 @@ func (h *CreateBookByCodeHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 		"thumbnailUrl":  output.ThumbnailURL,
 		"status":        output.Status,
 		"createdAt":     output.CreatedAt.Format(time.RFC3339),
-	})
+	}
+	if output.Series != nil {
+		resp["series"] = map[string]any{
+			"id":     output.Series.ID,
+			"title":  output.Series.Title,
+			"volume": output.Series.Volume,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 func writeError(w http.ResponseWriter, status int, code, message string) {
@@ -9,6 +9,7 @@ import (
 
 	book "holocron/internal/book/domain"
 	"holocron/internal/bookcode/domain"
+	"holocron/internal/series"
 
 	"github.com/google/uuid"
 )
@@ -32,6 +33,7 @@ type CreateBookByCodeOutput struct {
 	PublishedDate *string
 	ThumbnailURL  *string
 	Status        string
+	Series        *series.BookSeries
 	CreatedAt     time.Time
 }
 
@@ -70,9 +72,14 @@ func ExternalAPISource(
 	}
 }
 
+// CreateBookByCode registers the book found for a code and puts it in the series
+// named by its title or its metadata, if any. The book and its series are written
+// in one transaction after the lookup, so a failure leaves no book behind.
 func CreateBookByCode(
 	ctx context.Context,
+	db *sql.DB,
 	queries *Queries,
+	seriesQueries *series.Queries,
 	sources []domain.BookInfoSource,
 	input CreateBookByCodeInput,
 ) (*CreateBookByCodeOutput, error) {
@@ -104,6 +111,14 @@ func CreateBookByCode(
 		return nil, err
 	}
 
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries = queries.WithTx(tx)
+	seriesQueries = seriesQueries.WithTx(tx)
+
 	err = queries.InsertBookEvent(ctx, InsertBookEventParams{
 		EventID:       uuid.New().String(),
 		BookID:        bookID,
@@ -120,6 +135,11 @@ func CreateBookByCode(
 		return nil, err
 	}
 
+	bookSeries, err := series.AssignDetectedSeries(ctx, seriesQueries, bookID, string(title), info.Volume)
+	if err != nil {
+		return nil, err
+	}
+
 	return &CreateBookByCodeOutput{
 		ID:            bookID,
 		Code:          string(code),
@@ -129,8 +149,9 @@ func CreateBookByCode(
 		PublishedDate: strPtr(info.PublishedDate),
 		ThumbnailURL:  strPtr(info.ThumbnailURL),
 		Status:        "available",
+		Series:        bookSeries,
 		CreatedAt:     now,
-	}, nil
+	}, tx.Commit()
 }
 
 func strPtr(s string) *string {
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+
+	book "holocron/internal/book/domain"
+)
+
+type NamedBookInfoSource struct {
+	Name   string
+	Lookup BookInfoSource
+}
+
+type BookInfoFieldSources struct {
+	Title         string
+	Authors       string
+	Publisher     string
+	PublishedDate string
+	ThumbnailURL  string
+}
+
+type MergedBookInfo struct {
+	Info    book.BookInfo
+	Sources BookInfoFieldSources
+}
+
+func SourceLookups(sources []NamedBookInfoSource) []BookInfoSource {
+	lookups := make([]BookInfoSource, 0, len(sources))
+	for _, src := range sources {
+		lookups = append(lookups, src.Lookup)
+	}
+	return lookups
+}
+
+func MergeBookInfo(ctx context.Context, sources []NamedBookInfoSource, code string) (*MergedBookInfo, error) {
+	var merged *MergedBookInfo
+	for _, src := range sources {
+		info, err := src.Lookup(ctx, code)
+		if err != nil || info == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &MergedBookInfo{}
+		}
+		mergeField(&merged.Info.Title, &merged.Sources.Title, info.Title, src.Name)
+		if len(merged.Info.Authors) == 0 && len(info.Authors) > 0 {
+			merged.Info.Authors = info.Authors
+			merged.Sources.Authors = src.Name
+		}
+		mergeField(&merged.Info.Publisher, &merged.Sources.Publisher, info.Publisher, src.Name)
+		mergeField(&merged.Info.PublishedDate, &merged.Sources.PublishedDate, info.PublishedDate, src.Name)
+		mergeField(&merged.Info.ThumbnailURL, &merged.Sources.ThumbnailURL, info.ThumbnailURL, src.Name)
+		if merged.Info.Volume == "" {
+			merged.Info.Volume = info.Volume
+		}
+		if merged.complete() {
+			break
+		}
+	}
+	if merged == nil {
+		return nil, book.ErrBookNotFound
+	}
+	return merged, nil
+}
+
+func mergeField(dst *string, dstSource *string, value string, sourceName string) {
+	if *dst == "" && value != "" {
+		*dst = value
+		*dstSource = sourceName
+	}
+}
+
+func (m *MergedBookInfo) complete() bool {
+	return m.Info.Title != "" &&
+		len(m.Info.Authors) > 0 &&
+		m.Info.Publisher != "" &&
+		m.Info.PublishedDate != "" &&
+		m.Info.ThumbnailURL != ""
+}
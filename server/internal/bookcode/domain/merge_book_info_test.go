@@ -0,0 +1,108 @@
+//go:build small
+
+package domain
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	book "holocron/internal/book/domain"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestMergeBookInfo_WithFieldsSpreadAcrossSources_TakesFirstNonEmptyPerField(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("each field comes from the first source that has it", prop.ForAll(
+		func(titleAt int, publisherAt int, n int) bool {
+			titleAt, publisherAt = titleAt%n, publisherAt%n
+			sources := make([]NamedBookInfoSource, n)
+			for i := range sources {
+				info := &book.BookInfo{}
+				if i >= titleAt {
+					info.Title = "title"
+				}
+				if i >= publisherAt {
+					info.Publisher = "publisher"
+				}
+				sources[i] = NamedBookInfoSource{
+					Name: string(rune('a' + i)),
+					Lookup: func(ctx context.Context, code string) (*book.BookInfo, error) {
+						return info, nil
+					},
+				}
+			}
+
+			merged, err := MergeBookInfo(context.Background(), sources, "code")
+			return err == nil &&
+				merged.Info.Title == "title" &&
+				merged.Sources.Title == string(rune('a'+titleAt)) &&
+				merged.Info.Publisher == "publisher" &&
+				merged.Sources.Publisher == string(rune('a'+publisherAt))
+		},
+		gen.IntRange(0, 9),
+		gen.IntRange(0, 9),
+		gen.IntRange(1, 10),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestMergeBookInfo_WithCompleteFirstSource_DoesNotQueryRest(t *testing.T) {
+	called := false
+	sources := []NamedBookInfoSource{
+		{Name: "first", Lookup: func(ctx context.Context, code string) (*book.BookInfo, error) {
+			return &book.BookInfo{
+				Title:         "title",
+				Authors:       []string{"author"},
+				Publisher:     "publisher",
+				PublishedDate: "2024-01-01",
+				ThumbnailURL:  "https://example.com/cover.jpg",
+			}, nil
+		}},
+		{Name: "second", Lookup: func(ctx context.Context, code string) (*book.BookInfo, error) {
+			called = true
+			return nil, errors.New("fail")
+		}},
+	}
+
+	merged, err := MergeBookInfo(context.Background(), sources, "code")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if merged.Sources.ThumbnailURL != "first" {
+		t.Errorf("expected thumbnail source %q, got %q", "first", merged.Sources.ThumbnailURL)
+	}
+	if called {
+		t.Error("expected second source not to be queried")
+	}
+}
+
+func TestMergeBookInfo_WithAllFailing_ReturnsNotFoundError(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("returns ErrBookNotFound when all sources fail", prop.ForAll(
+		func(n int) bool {
+			sources := make([]NamedBookInfoSource, n)
+			for i := range sources {
+				sources[i] = NamedBookInfoSource{
+					Name: "fail",
+					Lookup: func(ctx context.Context, code string) (*book.BookInfo, error) {
+						return nil, errors.New("fail")
+					},
+				}
+			}
+
+			_, err := MergeBookInfo(context.Background(), sources, "code")
+			return err == book.ErrBookNotFound
+		},
+		gen.IntRange(0, 10),
+	))
+
+	properties.TestingRun(t)
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"slices"
+
+	book "holocron/internal/book/domain"
+)
+
+type MetadataField string
+
+const (
+	MetadataFieldAuthors       MetadataField = "authors"
+	MetadataFieldPublisher     MetadataField = "publisher"
+	MetadataFieldPublishedDate MetadataField = "publishedDate"
+	MetadataFieldThumbnailURL  MetadataField = "thumbnailUrl"
+)
+
+var refreshableFields = []MetadataField{
+	MetadataFieldAuthors,
+	MetadataFieldPublisher,
+	MetadataFieldPublishedDate,
+	MetadataFieldThumbnailURL,
+}
+
+type BookSnapshot struct {
+	ByUser bool
+	Info   book.BookInfo
+}
+
+type MetadataChange struct {
+	Field  MetadataField
+	Value  any
+	Source string
+}
+
+type MetadataRefreshPlan struct {
+	Info    book.BookInfo
+	Changes []MetadataChange
+	Skipped []MetadataField
+}
+
+func MissingMetadataFields(info book.BookInfo) []MetadataField {
+	var missing []MetadataField
+	for _, field := range refreshableFields {
+		if isEmptyField(info, field) {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// ManuallyEditedFields returns the fields changed by user update events.
+// The first snapshot is the registration and does not count as an edit.
+func ManuallyEditedFields(history []BookSnapshot) map[MetadataField]bool {
+	edited := map[MetadataField]bool{}
+	for i := 1; i < len(history); i++ {
+		if !history[i].ByUser {
+			continue
+		}
+		for _, field := range refreshableFields {
+			if !fieldEqual(history[i-1].Info, history[i].Info, field) {
+				edited[field] = true
+			}
+		}
+	}
+	return edited
+}
+
+func PlanMetadataRefresh(current book.BookInfo, edited map[MetadataField]bool, found *MergedBookInfo) MetadataRefreshPlan {
+	plan := MetadataRefreshPlan{Info: current}
+	for _, field := range MissingMetadataFields(current) {
+		if edited[field] {
+			plan.Skipped = append(plan.Skipped, field)
+			continue
+		}
+		if found == nil || isEmptyField(found.Info, field) {
+			continue
+		}
+		switch field {
+		case MetadataFieldAuthors:
+			plan.Info.Authors = slices.Clone(found.Info.Authors)
+			plan.Changes = append(plan.Changes, MetadataChange{Field: field, Value: plan.Info.Authors, Source: found.Sources.Authors})
+		case MetadataFieldPublisher:
+			plan.Info.Publisher = found.Info.Publisher
+			plan.Changes = append(plan.Changes, MetadataChange{Field: field, Value: plan.Info.Publisher, Source: found.Sources.Publisher})
+		case MetadataFieldPublishedDate:
+			plan.Info.PublishedDate = found.Info.PublishedDate
+			plan.Changes = append(plan.Changes, MetadataChange{Field: field, Value: plan.Info.PublishedDate, Source: found.Sources.PublishedDate})
+		case MetadataFieldThumbnailURL:
+			plan.Info.ThumbnailURL = found.Info.ThumbnailURL
+			plan.Changes = append(plan.Changes, MetadataChange{Field: field, Value: plan.Info.ThumbnailURL, Source: found.Sources.ThumbnailURL})
+		}
+	}
+	return plan
+}
+
+func isEmptyField(info book.BookInfo, field MetadataField) bool {
+	switch field {
+	case MetadataFieldAuthors:
+		return len(info.Authors) == 0
+	case MetadataFieldPublisher:
+		return info.Publisher == ""
+	case MetadataFieldPublishedDate:
+		return info.PublishedDate == ""
+	case MetadataFieldThumbnailURL:
+		return info.ThumbnailURL == ""
+	}
+	return false
+}
+
+func fieldEqual(a, b book.BookInfo, field MetadataField) bool {
+	switch field {
+	case MetadataFieldAuthors:
+		return slices.Equal(a.Authors, b.Authors)
+	case MetadataFieldPublisher:
+		return a.Publisher == b.Publisher
+	case MetadataFieldPublishedDate:
+		return a.PublishedDate == b.PublishedDate
+	case MetadataFieldThumbnailURL:
+		return a.ThumbnailURL == b.ThumbnailURL
+	}
+	return true
+}
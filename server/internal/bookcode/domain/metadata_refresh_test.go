@@ -0,0 +1,93 @@
+//go:build small
+
+package domain
+
+import (
+	"testing"
+
+	book "holocron/internal/book/domain"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestManuallyEditedFields_WithUserUpdate_ReturnsChangedFields(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("fields changed by user updates are edited", prop.ForAll(
+		func(before string, after string) bool {
+			history := []BookSnapshot{
+				{Info: book.BookInfo{Title: "title", Publisher: before}},
+				{ByUser: true, Info: book.BookInfo{Title: "title", Publisher: after}},
+			}
+
+			edited := ManuallyEditedFields(history)
+			return edited[MetadataFieldPublisher] == (before != after) &&
+				!edited[MetadataFieldThumbnailURL]
+		},
+		gen.AlphaString(),
+		gen.AlphaString(),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestManuallyEditedFields_WithRefreshUpdate_ReturnsNoFields(t *testing.T) {
+	history := []BookSnapshot{
+		{Info: book.BookInfo{Title: "title"}},
+		{ByUser: false, Info: book.BookInfo{Title: "title", Publisher: "publisher"}},
+	}
+
+	edited := ManuallyEditedFields(history)
+
+	if len(edited) != 0 {
+		t.Errorf("expected no edited fields, got %v", edited)
+	}
+}
+
+func TestPlanMetadataRefresh_WithMissingFields_FillsOnlyUneditedFields(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("edited fields are skipped and others are filled", prop.ForAll(
+		func(publisherEdited bool, thumbnailEdited bool) bool {
+			current := book.BookInfo{Title: "title", Authors: []string{"author"}, PublishedDate: "2024-01-01"}
+			edited := map[MetadataField]bool{
+				MetadataFieldPublisher:    publisherEdited,
+				MetadataFieldThumbnailURL: thumbnailEdited,
+			}
+			found := &MergedBookInfo{
+				Info: book.BookInfo{
+					Title:         "other title",
+					Authors:       []string{"other author"},
+					Publisher:     "publisher",
+					PublishedDate: "2000-01-01",
+					ThumbnailURL:  "https://example.com/cover.jpg",
+				},
+				Sources: BookInfoFieldSources{Publisher: "openbd", ThumbnailURL: "google_books"},
+			}
+
+			plan := PlanMetadataRefresh(current, edited, found)
+			return plan.Info.Title == "title" &&
+				plan.Info.Authors[0] == "author" &&
+				plan.Info.PublishedDate == "2024-01-01" &&
+				(plan.Info.Publisher == "publisher") == !publisherEdited &&
+				(plan.Info.ThumbnailURL == "https://example.com/cover.jpg") == !thumbnailEdited &&
+				len(plan.Changes)+len(plan.Skipped) == 2
+		},
+		gen.Bool(),
+		gen.Bool(),
+	))
+
+	properties.TestingRun(t)
+}
+
+func TestPlanMetadataRefresh_WithNothingFound_ReturnsNoChanges(t *testing.T) {
+	current := book.BookInfo{Title: "title"}
+
+	plan := PlanMetadataRefresh(current, map[MetadataField]bool{}, nil)
+
+	if len(plan.Changes) != 0 {
+		t.Errorf("expected no changes, got %v", plan.Changes)
+	}
+}
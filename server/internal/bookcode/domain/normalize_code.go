@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidBookCode = errors.New("code must be a valid ISBN or EAN")
+
+// NormalizeCode removes hyphens and spaces, validates the check digit and
+// converts ISBN-10 to ISBN-13 so that the same book always has the same code.
+func NormalizeCode(s string) (string, error) {
+	code := strings.ToUpper(strings.NewReplacer("-", "", " ", "", "　", "").Replace(strings.TrimSpace(s)))
+	switch len(code) {
+	case 10:
+		if !validISBN10(code) {
+			return "", ErrInvalidBookCode
+		}
+		return isbn10To13(code), nil
+	case 8, 13:
+		if !validEAN(code) {
+			return "", ErrInvalidBookCode
+		}
+		return code, nil
+	}
+	return "", ErrInvalidBookCode
+}
+
+func validISBN10(code string) bool {
+	sum := 0
+	for i, r := range code {
+		var digit int
+		switch {
+		case r >= '0' && r <= '9':
+			digit = int(r - '0')
+		case r == 'X' && i == 9:
+			digit = 10
+		default:
+			return false
+		}
+		sum += digit * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+func validEAN(code string) bool {
+	sum := 0
+	for i, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+		digit := int(r - '0')
+		// Weights alternate 1 and 3, counted from the check digit on the right.
+		if (len(code)-1-i)%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+func isbn10To13(code string) string {
+	body := "978" + code[:9]
+	sum := 0
+	for i, r := range body {
+		digit := int(r - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	check := (10 - sum%10) % 10
+	return body + string(rune('0'+check))
+}
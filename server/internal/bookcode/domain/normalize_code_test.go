@@ -0,0 +1,86 @@
+//go:build small
+
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func ean13(body string) string {
+	sum := 0
+	for i, r := range body {
+		digit := int(r - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return fmt.Sprintf("%s%d", body, (10-sum%10)%10)
+}
+
+func TestNormalizeCode_WithValidISBN13_ReturnsSameCode(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("valid EAN-13 codes are accepted as-is", prop.ForAll(
+		func(n int64) bool {
+			code := ean13(fmt.Sprintf("978%09d", n))
+			normalized, err := NormalizeCode(code)
+			return err == nil && normalized == code
+		},
+		gen.Int64Range(0, 999999999),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNormalizeCode_WithWrongCheckDigit_ReturnsError(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("a changed check digit is rejected", prop.ForAll(
+		func(n int64, delta int) bool {
+			code := ean13(fmt.Sprintf("978%09d", n))
+			check := int(code[12] - '0')
+			wrong := code[:12] + fmt.Sprint((check+delta)%10)
+			_, err := NormalizeCode(wrong)
+			return err == ErrInvalidBookCode
+		},
+		gen.Int64Range(0, 999999999),
+		gen.IntRange(1, 9),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNormalizeCode_WithISBN10_ReturnsISBN13(t *testing.T) {
+	cases := map[string]string{
+		"4873115655":    "9784873115658",
+		"0-306-40615-2": "9780306406157",
+		"0-8044-2957-X": "9780804429573",
+	}
+	for input, expected := range cases {
+		normalized, err := NormalizeCode(input)
+		if err != nil || normalized != expected {
+			t.Errorf("%s: expected %s, got %q (%v)", input, expected, normalized, err)
+		}
+	}
+}
+
+func TestNormalizeCode_WithHyphensAndSpaces_IgnoresThem(t *testing.T) {
+	normalized, err := NormalizeCode(" 978-4-87311-565-8 ")
+	if err != nil || normalized != "9784873115658" {
+		t.Errorf("expected 9784873115658, got %q (%v)", normalized, err)
+	}
+}
+
+func TestNormalizeCode_WithNonDigits_ReturnsError(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("alphabetic codes are rejected", prop.ForAll(
+		func(s string) bool {
+			_, err := NormalizeCode(s)
+			return err == ErrInvalidBookCode
+		},
+		gen.AlphaString().SuchThat(func(s string) bool { return s != "" }),
+	))
+	properties.TestingRun(t)
+}
@@ -0,0 +1,67 @@
+package bookcode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	book "holocron/internal/book/domain"
+	"holocron/internal/bookcode/domain"
+)
+
+type GetBookInfoHandler struct {
+	queries *Queries
+	sources []domain.NamedBookInfoSource
+}
+
+func NewGetBookInfoHandler(queries *Queries, sources []domain.NamedBookInfoSource) *GetBookInfoHandler {
+	return &GetBookInfoHandler{
+		queries: queries,
+		sources: sources,
+	}
+}
+
+func (h *GetBookInfoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, code string) {
+	output, err := GetBookInfo(r.Context(), h.queries, h.sources, GetBookInfoInput{
+		Code: code,
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidCode):
+			writeError(w, http.StatusBadRequest, "invalid_request", "code must not be empty")
+		case errors.Is(err, book.ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "book not found in any source")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"code":          output.Code,
+		"title":         strPtr(output.Info.Title),
+		"authors":       nonNilAuthors(output.Info.Authors),
+		"publisher":     strPtr(output.Info.Publisher),
+		"publishedDate": strPtr(output.Info.PublishedDate),
+		"thumbnailUrl":  strPtr(output.Info.ThumbnailURL),
+		"sources": map[string]any{
+			"title":         strPtr(output.Sources.Title),
+			"authors":       strPtr(output.Sources.Authors),
+			"publisher":     strPtr(output.Sources.Publisher),
+			"publishedDate": strPtr(output.Sources.PublishedDate),
+			"thumbnailUrl":  strPtr(output.Sources.ThumbnailURL),
+		},
+		"exists":          output.Exists,
+		"existingBookIds": output.ExistingBookIDs,
+	})
+}
+
+func nonNilAuthors(authors []string) []string {
+	if authors == nil {
+		return []string{}
+	}
+	return authors
+}
@@ -0,0 +1,60 @@
+package bookcode
+
+import (
+	"context"
+	"database/sql"
+
+	book "holocron/internal/book/domain"
+	"holocron/internal/bookcode/domain"
+)
+
+const (
+	SourceHolocron    = "holocron"
+	SourceGoogleBooks = "google_books"
+	SourceOpenBD      = "openbd"
+)
+
+type GetBookInfoInput struct {
+	Code string
+}
+
+type GetBookInfoOutput struct {
+	Code            string
+	Info            book.BookInfo
+	Sources         domain.BookInfoFieldSources
+	Exists          bool
+	ExistingBookIDs []string
+}
+
+func GetBookInfo(
+	ctx context.Context,
+	queries *Queries,
+	sources []domain.NamedBookInfoSource,
+	input GetBookInfoInput,
+) (*GetBookInfoOutput, error) {
+	code, err := domain.ParseBookCode(input.Code)
+	if err != nil {
+		return nil, ErrInvalidCode
+	}
+
+	merged, err := domain.MergeBookInfo(ctx, sources, string(code))
+	if err != nil {
+		return nil, err
+	}
+
+	bookIDs, err := queries.ListBookIdsByCode(ctx, sql.NullString{String: string(code), Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	if bookIDs == nil {
+		bookIDs = []string{}
+	}
+
+	return &GetBookInfoOutput{
+		Code:            string(code),
+		Info:            merged.Info,
+		Sources:         merged.Sources,
+		Exists:          len(bookIDs) > 0,
+		ExistingBookIDs: bookIDs,
+	}, nil
+}
@@ -0,0 +1,98 @@
+//go:build medium
+
+package bookcode
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	book "holocron/internal/book/domain"
+	"holocron/internal/bookcode/domain"
+	"holocron/internal/series"
+)
+
+func staticSource(name string, info *book.BookInfo) domain.NamedBookInfoSource {
+	return domain.NamedBookInfoSource{
+		Name: name,
+		Lookup: func(ctx context.Context, code string) (*book.BookInfo, error) {
+			if info == nil {
+				return nil, errors.New("not found")
+			}
+			return info, nil
+		},
+	}
+}
+
+func TestGetBookInfo_WithPartialSources_ReturnsMergedInfoWithFieldSources(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	sources := []domain.NamedBookInfoSource{
+		staticSource(SourceGoogleBooks, &book.BookInfo{Title: "リーダブルコード", Authors: []string{"Dustin Boswell"}}),
+		staticSource(SourceOpenBD, &book.BookInfo{Title: "別タイトル", Publisher: "オライリー・ジャパン", ThumbnailURL: "https://example.com/cover.jpg"}),
+	}
+
+	output, err := GetBookInfo(context.Background(), queries, sources, GetBookInfoInput{
+		Code: "9784873115658",
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Info.Title != "リーダブルコード" || output.Sources.Title != SourceGoogleBooks {
+		t.Errorf("expected title from %s, got %q from %q", SourceGoogleBooks, output.Info.Title, output.Sources.Title)
+	}
+	if output.Info.Publisher != "オライリー・ジャパン" || output.Sources.Publisher != SourceOpenBD {
+		t.Errorf("expected publisher from %s, got %q from %q", SourceOpenBD, output.Info.Publisher, output.Sources.Publisher)
+	}
+	if output.Sources.PublishedDate != "" {
+		t.Errorf("expected no source for published date, got %q", output.Sources.PublishedDate)
+	}
+	if output.Exists {
+		t.Error("expected book not to exist")
+	}
+}
+
+func TestGetBookInfo_WithRegisteredCode_ReportsExistingBooks(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	sources := []domain.NamedBookInfoSource{
+		staticSource(SourceGoogleBooks, &book.BookInfo{Title: "リーダブルコード", Authors: []string{"Dustin Boswell"}}),
+	}
+	created, err := CreateBookByCode(context.Background(), db, queries, series.New(db), domain.SourceLookups(sources), CreateBookByCodeInput{
+		Code: "9784873115658",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := GetBookInfo(context.Background(), queries, sources, GetBookInfoInput{
+		Code: "9784873115658",
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !output.Exists {
+		t.Error("expected book to exist")
+	}
+	if len(output.ExistingBookIDs) != 1 || output.ExistingBookIDs[0] != created.ID {
+		t.Errorf("expected existing book IDs [%s], got %v", created.ID, output.ExistingBookIDs)
+	}
+}
+
+func TestGetBookInfo_WithNoSourceMatch_ReturnsNotFoundError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	sources := []domain.NamedBookInfoSource{
+		staticSource(SourceGoogleBooks, nil),
+	}
+
+	_, err := GetBookInfo(context.Background(), queries, sources, GetBookInfoInput{
+		Code: "9784873115658",
+	})
+
+	if !errors.Is(err, book.ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package bookcode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"holocron/internal/auth"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type RefreshAllBookMetadataHandler struct {
+	service *MetadataRefreshService
+	roles   auth.Roles
+}
+
+func NewRefreshAllBookMetadataHandler(service *MetadataRefreshService, roles auth.Roles) *RefreshAllBookMetadataHandler {
+	return &RefreshAllBookMetadataHandler{service: service, roles: roles}
+}
+
+func (h *RefreshAllBookMetadataHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+	if !h.roles.IsLibrarian(userID) {
+		writeError(w, http.StatusForbidden, "forbidden", "librarian role is required")
+		return
+	}
+
+	report, err := h.service.RefreshAll(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	writeRefreshReport(w, report)
+}
+
+type RefreshBookMetadataHandler struct {
+	service *MetadataRefreshService
+}
+
+func NewRefreshBookMetadataHandler(service *MetadataRefreshService) *RefreshBookMetadataHandler {
+	return &RefreshBookMetadataHandler{service: service}
+}
+
+func (h *RefreshBookMetadataHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	report, err := h.service.RefreshBook(r.Context(), bookId.String())
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotRegistered):
+			writeError(w, http.StatusNotFound, "not_found", "book not found")
+		case errors.Is(err, ErrBookHasNoCode):
+			writeError(w, http.StatusBadRequest, "invalid_request", "book has no code to look up")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+	writeRefreshReport(w, report)
+}
+
+func writeRefreshReport(w http.ResponseWriter, report *MetadataRefreshReport) {
+	books := make([]map[string]any, 0, len(report.Books))
+	for _, result := range report.Books {
+		changes := make([]map[string]any, 0, len(result.Changes))
+		for _, change := range result.Changes {
+			changes = append(changes, map[string]any{
+				"field":  change.Field,
+				"value":  change.Value,
+				"source": change.Source,
+			})
+		}
+		skipped := make([]string, 0, len(result.SkippedFields))
+		for _, field := range result.SkippedFields {
+			skipped = append(skipped, string(field))
+		}
+		books = append(books, map[string]any{
+			"bookId":        result.BookID,
+			"code":          result.Code,
+			"title":         result.Title,
+			"status":        result.Status,
+			"changes":       changes,
+			"skippedFields": skipped,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"startedAt":  report.StartedAt.Format(time.RFC3339),
+		"finishedAt": report.FinishedAt.Format(time.RFC3339),
+		"checked":    report.Checked,
+		"updated":    report.Updated,
+		"books":      books,
+	})
+}
@@ -0,0 +1,200 @@
+package bookcode
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	book "holocron/internal/book/domain"
+	"holocron/internal/bookcode/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBookNotRegistered = errors.New("book not registered")
+	ErrBookHasNoCode     = errors.New("book has no code")
+)
+
+const (
+	RefreshStatusUpdated   = "updated"
+	RefreshStatusUnchanged = "unchanged"
+	RefreshStatusNotFound  = "not_found"
+	RefreshStatusFailed    = "failed"
+)
+
+type BookMetadataRefreshResult struct {
+	BookID        string
+	Code          string
+	Title         string
+	Status        string
+	Changes       []domain.MetadataChange
+	SkippedFields []domain.MetadataField
+}
+
+type MetadataRefreshReport struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Checked    int
+	Updated    int
+	Books      []BookMetadataRefreshResult
+}
+
+type MetadataRefreshService struct {
+	queries *Queries
+	sources []domain.NamedBookInfoSource
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+func NewMetadataRefreshService(queries *Queries, sources []domain.NamedBookInfoSource) *MetadataRefreshService {
+	return &MetadataRefreshService{
+		queries: queries,
+		sources: sources,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *MetadataRefreshService) RefreshAll(ctx context.Context) (*MetadataRefreshReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &MetadataRefreshReport{StartedAt: s.now(), Books: []BookMetadataRefreshResult{}}
+
+	rows, err := s.queries.ListBooksMissingMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result, err := s.refresh(ctx, row.BookID)
+		if err != nil {
+			result = BookMetadataRefreshResult{
+				BookID: row.BookID,
+				Code:   row.Code.String,
+				Title:  row.Title.String,
+				Status: RefreshStatusFailed,
+			}
+		}
+		report.add(result)
+	}
+
+	report.FinishedAt = s.now()
+	return report, nil
+}
+
+func (s *MetadataRefreshService) RefreshBook(ctx context.Context, bookID string) (*MetadataRefreshReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &MetadataRefreshReport{StartedAt: s.now(), Books: []BookMetadataRefreshResult{}}
+
+	result, err := s.refresh(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	report.add(result)
+
+	report.FinishedAt = s.now()
+	return report, nil
+}
+
+func (s *MetadataRefreshService) refresh(ctx context.Context, bookID string) (BookMetadataRefreshResult, error) {
+	rows, err := s.queries.ListBookSnapshots(ctx, bookID)
+	if err != nil {
+		return BookMetadataRefreshResult{}, err
+	}
+	if len(rows) == 0 {
+		return BookMetadataRefreshResult{}, ErrBookNotRegistered
+	}
+
+	history := make([]domain.BookSnapshot, 0, len(rows))
+	for _, row := range rows {
+		history = append(history, domain.BookSnapshot{
+			ByUser: row.EventType == "updated" && !row.Origin.Valid,
+			Info:   snapshotInfo(row),
+		})
+	}
+	latest := rows[len(rows)-1]
+	current := history[len(history)-1].Info
+
+	if !latest.Code.Valid || latest.Code.String == "" {
+		return BookMetadataRefreshResult{}, ErrBookHasNoCode
+	}
+
+	result := BookMetadataRefreshResult{
+		BookID: bookID,
+		Code:   latest.Code.String,
+		Title:  current.Title,
+		Status: RefreshStatusUnchanged,
+	}
+	if len(domain.MissingMetadataFields(current)) == 0 {
+		return result, nil
+	}
+
+	found, err := domain.MergeBookInfo(ctx, s.sources, latest.Code.String)
+	if err != nil && !errors.Is(err, book.ErrBookNotFound) {
+		return BookMetadataRefreshResult{}, err
+	}
+
+	plan := domain.PlanMetadataRefresh(current, domain.ManuallyEditedFields(history), found)
+	result.Changes = plan.Changes
+	result.SkippedFields = plan.Skipped
+	if found == nil {
+		result.Status = RefreshStatusNotFound
+		return result, nil
+	}
+	if len(plan.Changes) == 0 {
+		return result, nil
+	}
+
+	authorsJSON, err := json.Marshal(plan.Info.Authors)
+	if err != nil {
+		return BookMetadataRefreshResult{}, err
+	}
+	err = s.queries.InsertBookRefreshEvent(ctx, InsertBookRefreshEventParams{
+		EventID:       uuid.New().String(),
+		BookID:        bookID,
+		Code:          latest.Code,
+		Title:         latest.Title,
+		Authors:       sql.NullString{String: string(authorsJSON), Valid: true},
+		Publisher:     toNullString(strPtr(plan.Info.Publisher)),
+		PublishedDate: toNullString(strPtr(plan.Info.PublishedDate)),
+		ThumbnailUrl:  toNullString(strPtr(plan.Info.ThumbnailURL)),
+		OccurredAt:    s.now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return BookMetadataRefreshResult{}, err
+	}
+
+	result.Status = RefreshStatusUpdated
+	return result, nil
+}
+
+func (r *MetadataRefreshReport) add(result BookMetadataRefreshResult) {
+	r.Checked++
+	if result.Status == RefreshStatusUpdated {
+		r.Updated++
+	}
+	r.Books = append(r.Books, result)
+}
+
+func snapshotInfo(row ListBookSnapshotsRow) book.BookInfo {
+	var authors []string
+	if row.Authors.Valid && row.Authors.String != "" {
+		_ = json.Unmarshal([]byte(row.Authors.String), &authors)
+	}
+	return book.BookInfo{
+		Title:         row.Title.String,
+		Authors:       authors,
+		Publisher:     row.Publisher.String,
+		PublishedDate: row.PublishedDate.String,
+		ThumbnailURL:  row.ThumbnailUrl.String,
+	}
+}
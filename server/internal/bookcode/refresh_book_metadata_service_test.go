@@ -0,0 +1,167 @@
+//go:build medium
+
+package bookcode
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	book "holocron/internal/book/domain"
+	"holocron/internal/bookcode/domain"
+
+	"github.com/google/uuid"
+)
+
+func insertBookSnapshot(t *testing.T, queries *Queries, bookID, eventType string, code *string, info book.BookInfo, at time.Time) {
+	t.Helper()
+	authors := `["` + info.Authors[0] + `"]`
+	err := queries.InsertBookEvent(context.Background(), InsertBookEventParams{
+		EventID:       uuid.New().String(),
+		BookID:        bookID,
+		EventType:     eventType,
+		Code:          toNullString(code),
+		Title:         sql.NullString{String: info.Title, Valid: true},
+		Authors:       sql.NullString{String: authors, Valid: true},
+		Publisher:     toNullString(strPtr(info.Publisher)),
+		PublishedDate: toNullString(strPtr(info.PublishedDate)),
+		ThumbnailUrl:  toNullString(strPtr(info.ThumbnailURL)),
+		OccurredAt:    at.Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fullInfoSources() []domain.NamedBookInfoSource {
+	return []domain.NamedBookInfoSource{
+		staticSource(SourceOpenBD, &book.BookInfo{
+			Title:         "リーダブルコード",
+			Authors:       []string{"Dustin Boswell"},
+			Publisher:     "オライリー・ジャパン",
+			PublishedDate: "2012-06-23",
+			ThumbnailURL:  "https://example.com/cover.jpg",
+		}),
+	}
+}
+
+// When RefreshBook with missing fields then appends updated event with filled fields
+func TestRefreshBook_WithMissingFields_FillsFields(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	bookID := uuid.New().String()
+	code := "9784873115658"
+	createdAt := time.Now().UTC().Add(-time.Hour)
+	insertBookSnapshot(t, queries, bookID, "created", &code, book.BookInfo{Title: "リーダブルコード", Authors: []string{"Dustin Boswell"}}, createdAt)
+	service := NewMetadataRefreshService(queries, fullInfoSources())
+
+	report, err := service.RefreshBook(ctx, bookID)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Checked != 1 || report.Updated != 1 {
+		t.Fatalf("expected 1 checked and 1 updated, got %d and %d", report.Checked, report.Updated)
+	}
+	if len(report.Books[0].Changes) != 3 {
+		t.Errorf("expected 3 changes, got %v", report.Books[0].Changes)
+	}
+
+	snapshots, err := queries.ListBookSnapshots(ctx, bookID)
+	if err != nil {
+		t.Fatalf("postcondition failed: %v", err)
+	}
+	latest := snapshots[len(snapshots)-1]
+	if latest.Publisher.String != "オライリー・ジャパン" {
+		t.Errorf("postcondition failed: expected publisher to be filled, got %q", latest.Publisher.String)
+	}
+	if latest.Origin.String != "metadata_refresh" {
+		t.Errorf("postcondition failed: expected origin metadata_refresh, got %q", latest.Origin.String)
+	}
+	if latest.Title.String != "リーダブルコード" {
+		t.Errorf("postcondition failed: expected title to be kept, got %q", latest.Title.String)
+	}
+}
+
+// When RefreshBook with manually cleared field then skips that field
+func TestRefreshBook_WithManuallyEditedField_SkipsField(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	bookID := uuid.New().String()
+	code := "9784873115658"
+	createdAt := time.Now().UTC().Add(-2 * time.Hour)
+	insertBookSnapshot(t, queries, bookID, "created", &code, book.BookInfo{
+		Title:        "リーダブルコード",
+		Authors:      []string{"Dustin Boswell"},
+		ThumbnailURL: "http://example.com/wrong.jpg",
+	}, createdAt)
+	insertBookSnapshot(t, queries, bookID, "updated", &code, book.BookInfo{
+		Title:   "リーダブルコード",
+		Authors: []string{"Dustin Boswell"},
+	}, createdAt.Add(time.Hour))
+	service := NewMetadataRefreshService(queries, fullInfoSources())
+
+	report, err := service.RefreshBook(ctx, bookID)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := report.Books[0]
+	if len(result.SkippedFields) != 1 || result.SkippedFields[0] != domain.MetadataFieldThumbnailURL {
+		t.Errorf("expected thumbnailUrl to be skipped, got %v", result.SkippedFields)
+	}
+	for _, change := range result.Changes {
+		if change.Field == domain.MetadataFieldThumbnailURL {
+			t.Errorf("expected thumbnailUrl not to be changed")
+		}
+	}
+}
+
+// When RefreshAll then checks only books with code and missing fields
+func TestRefreshAll_WithMixedBooks_ChecksOnlyIncompleteBooksWithCode(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	code := "9784873115658"
+	createdAt := time.Now().UTC().Add(-time.Hour)
+	incompleteID := uuid.New().String()
+	insertBookSnapshot(t, queries, incompleteID, "created", &code, book.BookInfo{Title: "リーダブルコード", Authors: []string{"Dustin Boswell"}}, createdAt)
+	insertBookSnapshot(t, queries, uuid.New().String(), "created", nil, book.BookInfo{Title: "同人誌", Authors: []string{"作者"}}, createdAt)
+	insertBookSnapshot(t, queries, uuid.New().String(), "created", &code, book.BookInfo{
+		Title:         "リーダブルコード",
+		Authors:       []string{"Dustin Boswell"},
+		Publisher:     "オライリー・ジャパン",
+		PublishedDate: "2012-06-23",
+		ThumbnailURL:  "https://example.com/cover.jpg",
+	}, createdAt)
+	service := NewMetadataRefreshService(queries, fullInfoSources())
+
+	report, err := service.RefreshAll(ctx)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Checked != 1 {
+		t.Fatalf("expected 1 checked book, got %d", report.Checked)
+	}
+	if report.Books[0].BookID != incompleteID {
+		t.Errorf("expected book %s, got %s", incompleteID, report.Books[0].BookID)
+	}
+}
+
+// When RefreshBook with unknown book then returns ErrBookNotRegistered
+func TestRefreshBook_WithUnknownBook_ReturnsNotRegisteredError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	service := NewMetadataRefreshService(queries, fullInfoSources())
+
+	_, err := service.RefreshBook(context.Background(), uuid.New().String())
+
+	if !errors.Is(err, ErrBookNotRegistered) {
+		t.Errorf("expected ErrBookNotRegistered, got %v", err)
+	}
+}
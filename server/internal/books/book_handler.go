@@ -1,20 +1,30 @@
 package books
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"holocron/internal/api"
+	"holocron/internal/auth"
+	"holocron/internal/books/domain"
+	"holocron/internal/calendar"
+	"holocron/internal/cursor"
+	"holocron/internal/series"
 	"net/http"
 	"time"
 )
 
 type CreateBookHandler struct {
-	queries *Queries
+	db            *sql.DB
+	queries       *Queries
+	seriesQueries *series.Queries
 }
 
-func NewCreateBookHandler(queries *Queries) *CreateBookHandler {
+func NewCreateBookHandler(db *sql.DB, queries *Queries, seriesQueries *series.Queries) *CreateBookHandler {
 	return &CreateBookHandler{
-		queries: queries,
+		db:            db,
+		queries:       queries,
+		seriesQueries: seriesQueries,
 	}
 }
 
@@ -32,7 +42,7 @@ func (h *CreateBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	output, err := CreateBook(r.Context(), h.queries, CreateBookInput{
+	output, err := CreateBook(r.Context(), h.db, h.queries, h.seriesQueries, CreateBookInput{
 		Code:          req.Code,
 		Title:         req.Title,
 		Authors:       req.Authors,
@@ -53,9 +63,7 @@ func (h *CreateBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(map[string]any{
+	resp := map[string]any{
 		"id":            output.ID,
 		"title":         output.Title,
 		"authors":       output.Authors,
@@ -64,29 +72,112 @@ func (h *CreateBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"thumbnailUrl":  output.ThumbnailURL,
 		"status":        output.Status,
 		"createdAt":     output.CreatedAt.Format(time.RFC3339),
-	})
+	}
+	if output.Series != nil {
+		resp["series"] = map[string]any{
+			"id":     output.Series.ID,
+			"title":  output.Series.Title,
+			"volume": output.Series.Volume,
+		}
+	}
+	if output.AccessionNumber != nil {
+		resp["accessionNumber"] = *output.AccessionNumber
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 type ListBooksHandler struct {
-	queries *Queries
+	queries  *Queries
+	index    *BookSearchIndex
+	codec    *cursor.Codec
+	calendar calendar.Calendar
 }
 
-func NewListBooksHandler(queries *Queries) *ListBooksHandler {
+func NewListBooksHandler(queries *Queries, index *BookSearchIndex, codec *cursor.Codec, cal calendar.Calendar) *ListBooksHandler {
 	return &ListBooksHandler{
-		queries: queries,
+		queries:  queries,
+		index:    index,
+		codec:    codec,
+		calendar: cal,
 	}
 }
 
 func (h *ListBooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.GetBooksParams) {
-	output, err := ListBooks(r.Context(), h.queries, ListBooksInput{
-		Q:      params.Q,
-		Code:   params.Code,
-		Limit:  params.Limit,
-		Offset: params.Offset,
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	var status, sort, order *string
+	if params.Status != nil {
+		s := string(*params.Status)
+		status = &s
+	}
+	if params.Sort != nil {
+		s := string(*params.Sort)
+		sort = &s
+	}
+	if params.Order != nil {
+		s := string(*params.Order)
+		order = &s
+	}
+	var category *string
+	if params.Category != nil {
+		s := params.Category.String()
+		category = &s
+	}
+	var facets []string
+	if params.Facets != nil {
+		for _, f := range *params.Facets {
+			facets = append(facets, string(f))
+		}
+	}
+
+	output, err := ListBooks(r.Context(), h.queries, h.index, h.codec, h.calendar, ListBooksInput{
+		Q:             params.Q,
+		Code:          params.Code,
+		Romaji:        params.Romaji,
+		Status:        status,
+		Borrower:      params.Borrower,
+		Publisher:     params.Publisher,
+		Author:        params.Author,
+		PublishedFrom: params.PublishedFrom,
+		PublishedTo:   params.PublishedTo,
+		CreatedAfter:  params.CreatedAfter,
+		Tag:           params.Tag,
+		Category:      category,
+		Shelf:         params.Shelf,
+		Sort:          sort,
+		Order:         order,
+		RequesterID:   userID,
+		Cursor:        params.Cursor,
+		IncludeTotal:  params.IncludeTotal,
+		Facets:        facets,
+		Limit:         params.Limit,
+		Offset:        params.Offset,
 	})
 
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		switch {
+		case errors.Is(err, domain.ErrInvalidBookStatus):
+			writeError(w, http.StatusBadRequest, "invalid_request", "status must be available, borrowed or overdue")
+		case errors.Is(err, domain.ErrInvalidPublishedDate):
+			writeError(w, http.StatusBadRequest, "invalid_request", "published_from and published_to must be YYYY, YYYY-MM or YYYY-MM-DD")
+		case errors.Is(err, domain.ErrInvalidBookSortKey):
+			writeError(w, http.StatusBadRequest, "invalid_request", "sort must be title, author, created_at, published_date or popularity")
+		case errors.Is(err, domain.ErrInvalidSortDirection):
+			writeError(w, http.StatusBadRequest, "invalid_request", "order must be asc or desc")
+		case errors.Is(err, domain.ErrInvalidBookFacet):
+			writeError(w, http.StatusBadRequest, "invalid_request", "facets must be status, publisher, author, decade or tag")
+		case errors.Is(err, cursor.ErrInvalidCursor):
+			writeError(w, http.StatusBadRequest, "invalid_request", "cursor is invalid or was issued for a different query")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
 		return
 	}
 
@@ -98,6 +189,7 @@ func (h *ListBooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, par
 			"authors":   item.Authors,
 			"status":    item.Status,
 			"createdAt": item.CreatedAt.Format(time.RFC3339),
+			"tags":      item.Tags,
 		}
 		if item.Code != nil {
 			m["code"] = *item.Code
@@ -118,15 +210,61 @@ func (h *ListBooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, par
 				"borrowedAt": item.Borrower.BorrowedAt.Format(time.RFC3339),
 			}
 		}
+		if item.Category != nil {
+			category := map[string]any{
+				"id":   item.Category.ID,
+				"name": item.Category.Name,
+			}
+			if item.Category.Code != nil {
+				category["code"] = *item.Category.Code
+			}
+			m["category"] = category
+		}
+		if item.ShelfLocation != nil {
+			m["shelfLocation"] = *item.ShelfLocation
+		}
+		if item.Highlights != nil {
+			highlights := map[string]any{}
+			if item.Highlights.Title != nil {
+				highlights["title"] = *item.Highlights.Title
+			}
+			if item.Highlights.Authors != nil {
+				highlights["authors"] = item.Highlights.Authors
+			}
+			if item.Highlights.Publisher != nil {
+				highlights["publisher"] = *item.Highlights.Publisher
+			}
+			m["highlights"] = highlights
+		}
 		respItems = append(respItems, m)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]any{
+	resp := map[string]any{
 		"items": respItems,
-		"total": output.Total,
-	})
+	}
+	if output.Total != nil {
+		resp["total"] = *output.Total
+	}
+	if output.NextCursor != nil {
+		resp["nextCursor"] = *output.NextCursor
+	}
+	if output.PrevCursor != nil {
+		resp["prevCursor"] = *output.PrevCursor
+	}
+	if output.Facets != nil {
+		facets := map[string]any{}
+		for facet, counts := range output.Facets {
+			respCounts := make([]map[string]any, 0, len(counts))
+			for _, c := range counts {
+				respCounts = append(respCounts, map[string]any{"value": c.Value, "count": c.Count})
+			}
+			facets[string(facet)] = respCounts
+		}
+		resp["facets"] = facets
+	}
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 func writeError(w http.ResponseWriter, status int, code, message string) {
@@ -0,0 +1,89 @@
+package books
+
+import (
+	"database/sql"
+	"slices"
+	"time"
+
+	"holocron/internal/books/domain"
+)
+
+// bookFilterArgs is a book filter as the arguments of the list and search queries,
+// where a NULL argument leaves its condition out.
+type bookFilterArgs struct {
+	code          sql.NullString
+	status        sql.NullString
+	borrowerID    sql.NullString
+	publisher     sql.NullString
+	author        sql.NullString
+	publishedFrom sql.NullString
+	publishedTo   sql.NullString
+	createdAfter  sql.NullString
+	tag           sql.NullString
+	categoryID    sql.NullString
+	shelfLocation sql.NullString
+}
+
+func toBookFilterArgs(filter domain.BookFilter) bookFilterArgs {
+	args := bookFilterArgs{
+		code:          toNullString(filter.Code),
+		borrowerID:    toNullString(filter.BorrowerID),
+		publisher:     toNullString(filter.Publisher),
+		author:        toNullString(filter.Author),
+		publishedFrom: toNullString(filter.PublishedFrom),
+		publishedTo:   toNullString(filter.PublishedTo),
+		tag:           toNullString(filter.Tag),
+		categoryID:    toNullString(filter.CategoryID),
+		shelfLocation: toNullString(filter.ShelfLocation),
+	}
+	if filter.Status != nil {
+		args.status = sql.NullString{String: string(*filter.Status), Valid: true}
+	}
+	if filter.CreatedAfter != nil {
+		args.createdAfter = sql.NullString{String: filter.CreatedAfter.UTC().Format(time.RFC3339), Valid: true}
+	}
+	return args
+}
+
+// bookKeysetArgs is a pagination cursor as the arguments of the list and search
+// queries. Without a position the queries start at the offset.
+type bookKeysetArgs struct {
+	bookID     sql.NullString
+	value      any
+	updatedAt  sql.NullString
+	backward   bool
+	descending bool
+}
+
+func toBookKeysetArgs(sort domain.BookSort, pagination domain.Pagination) bookKeysetArgs {
+	// A backward scan reverses the whole order, including the sort direction.
+	args := bookKeysetArgs{
+		backward:   pagination.Backward(),
+		descending: sort.Descending != pagination.Backward(),
+	}
+	if position := pagination.Position(); position != nil {
+		args.bookID = sql.NullString{String: position.BookID, Valid: true}
+		args.value = position.SortValue
+		args.updatedAt = sql.NullString{String: position.UpdatedAt, Valid: true}
+	}
+	return args
+}
+
+// bookFacetArgs tells the facet queries which facets to count.
+type bookFacetArgs struct {
+	status    bool
+	publisher bool
+	author    bool
+	decade    bool
+	tag       bool
+}
+
+func toBookFacetArgs(facets []domain.BookFacet) bookFacetArgs {
+	return bookFacetArgs{
+		status:    slices.Contains(facets, domain.BookFacetStatus),
+		publisher: slices.Contains(facets, domain.BookFacetPublisher),
+		author:    slices.Contains(facets, domain.BookFacetAuthor),
+		decade:    slices.Contains(facets, domain.BookFacetDecade),
+		tag:       slices.Contains(facets, domain.BookFacetTag),
+	}
+}
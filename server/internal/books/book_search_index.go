@@ -0,0 +1,89 @@
+package books
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"holocron/internal/books/domain"
+	"holocron/internal/projection"
+)
+
+// BookSearchIndex maintains the book_search full-text index from book_events. Events
+// are applied in the order they were appended, tracked by the rowid of the last applied
+// event, so books written by any service are picked up on the next catch-up.
+type BookSearchIndex struct {
+	*projection.Projection
+}
+
+func NewBookSearchIndex(db *sql.DB) *BookSearchIndex {
+	return &BookSearchIndex{projection.New(db, bookSearchReadModel{queries: New(db)})}
+}
+
+type bookSearchReadModel struct {
+	queries *Queries
+}
+
+func (m bookSearchReadModel) Position(ctx context.Context, tx *sql.Tx) (int64, error) {
+	return m.queries.WithTx(tx).GetBookSearchPosition(ctx)
+}
+
+func (m bookSearchReadModel) SetPosition(ctx context.Context, tx *sql.Tx, rowid int64) error {
+	return m.queries.WithTx(tx).SetBookSearchPosition(ctx, rowid)
+}
+
+func (m bookSearchReadModel) LatestRowid(ctx context.Context, tx *sql.Tx) (int64, error) {
+	return m.queries.WithTx(tx).GetLatestBookEventRowid(ctx)
+}
+
+func (m bookSearchReadModel) Reset(ctx context.Context, tx *sql.Tx, latest int64) (int64, error) {
+	return 0, m.queries.WithTx(tx).DeleteAllBookSearchEntries(ctx)
+}
+
+func (m bookSearchReadModel) Apply(ctx context.Context, tx *sql.Tx, afterRowid, untilRowid int64) error {
+	qtx := m.queries.WithTx(tx)
+	bookIDs, err := qtx.ListBookIdsChangedBetween(ctx, ListBookIdsChangedBetweenParams{
+		AfterRowid: afterRowid,
+		UntilRowid: untilRowid,
+	})
+	if err != nil {
+		return err
+	}
+	for _, bookID := range bookIDs {
+		if err := reindexBook(ctx, qtx, bookID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reindexBook(ctx context.Context, qtx *Queries, bookID string) error {
+	id := sql.NullString{String: bookID, Valid: true}
+	if err := qtx.DeleteBookSearchEntry(ctx, id); err != nil {
+		return err
+	}
+
+	row, err := qtx.GetSearchableBook(ctx, bookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The book has been deleted.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var authors []string
+	if row.Authors.String != "" {
+		if err := json.Unmarshal([]byte(row.Authors.String), &authors); err != nil {
+			return domain.ErrInvalidBookRow
+		}
+	}
+
+	return qtx.InsertBookSearchEntry(ctx, InsertBookSearchEntryParams{
+		BookID:    id,
+		Title:     sql.NullString{String: domain.SearchIndexText(row.Title.String), Valid: true},
+		Authors:   sql.NullString{String: domain.SearchIndexText(authors...), Valid: true},
+		Publisher: sql.NullString{String: domain.SearchIndexText(row.Publisher.String), Valid: true},
+	})
+}
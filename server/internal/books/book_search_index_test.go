@@ -0,0 +1,107 @@
+//go:build medium
+
+package books
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBookSearchIndex_CatchUp_WithUpdatedBook_IndexesLatestValues(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at) VALUES
+			('evt-1', 'book-1', 'created', '旧タイトル', '["著者"]', '2024-01-01T00:00:00Z'),
+			('evt-2', 'book-1', 'updated', '新タイトル', '["著者"]', '2024-01-02T00:00:00Z')
+	`)
+	if err != nil {
+		t.Fatalf("failed to insert book events: %v", err)
+	}
+
+	if _, total := runSearch(t, db, "旧タイトル"); total != 0 {
+		t.Errorf("expected the old title not to match, got %d", total)
+	}
+	if _, total := runSearch(t, db, "新タイトル"); total != 1 {
+		t.Errorf("expected the new title to match, got %d", total)
+	}
+}
+
+func TestBookSearchIndex_CatchUp_WithDeletedBook_RemovesBook(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	index := NewBookSearchIndex(db)
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at)
+		VALUES ('evt-1', 'book-1', 'created', '走れメロス', '["太宰治"]', '2024-01-01T00:00:00Z')
+	`)
+	if err != nil {
+		t.Fatalf("failed to insert book event: %v", err)
+	}
+	if err := index.CatchUp(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// When the book is deleted after it has been indexed
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO book_events (event_id, book_id, event_type, occurred_at)
+		VALUES ('evt-2', 'book-1', 'deleted', '2024-01-02T00:00:00Z')
+	`)
+	if err != nil {
+		t.Fatalf("failed to insert delete event: %v", err)
+	}
+	if err := index.CatchUp(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then it is removed from the index
+	var count int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM book_search`).Scan(&count); err != nil {
+		t.Fatalf("failed to count index entries: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected an empty index, got %d entries", count)
+	}
+}
+
+func TestBookSearchIndex_Rebuild_AfterEventsReplaced_IndexesCurrentBooks(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+	index := NewBookSearchIndex(db)
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at) VALUES
+			('evt-1', 'book-1', 'created', '雪国', '["川端康成"]', '2024-01-01T00:00:00Z'),
+			('evt-2', 'book-2', 'created', '伊豆の踊子', '["川端康成"]', '2024-01-01T00:00:00Z')
+	`)
+	if err != nil {
+		t.Fatalf("failed to insert book events: %v", err)
+	}
+	if err := index.CatchUp(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// When the event table is replaced by a restore with as many events
+	_, err = db.ExecContext(ctx, `
+		DELETE FROM book_events;
+		INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at) VALUES
+			('evt-3', 'book-3', 'created', '人間失格', '["太宰治"]', '2024-01-01T00:00:00Z'),
+			('evt-4', 'book-4', 'created', '斜陽', '["太宰治"]', '2024-01-01T00:00:00Z')
+	`)
+	if err != nil {
+		t.Fatalf("failed to replace book events: %v", err)
+	}
+	if err := index.Rebuild(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then only the restored books are found
+	if _, total := runSearch(t, db, "川端"); total != 0 {
+		t.Errorf("expected books from before the restore to be gone, got %d", total)
+	}
+	if _, total := runSearch(t, db, "太宰"); total != 2 {
+		t.Errorf("expected the restored books, got %d", total)
+	}
+}
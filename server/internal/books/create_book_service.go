@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	book "holocron/internal/book/domain"
+	"holocron/internal/series"
 
 	"github.com/google/uuid"
 )
@@ -34,10 +36,17 @@ type CreateBookOutput struct {
 	PublishedDate *string
 	ThumbnailURL  *string
 	Status        string
-	CreatedAt     time.Time
+	Series        *series.BookSeries
+	// AccessionNumber is assigned to books without a code, so that they can be labelled.
+	AccessionNumber *string
+	CreatedAt       time.Time
 }
 
-func CreateBook(ctx context.Context, queries *Queries, input CreateBookInput) (*CreateBookOutput, error) {
+// CreateBook registers a book and puts it in the series its title names, if any.
+// A book without a code is given an accession number. The book, its series and
+// its accession number are written in one transaction, so a failure leaves no
+// book behind.
+func CreateBook(ctx context.Context, db *sql.DB, queries *Queries, seriesQueries *series.Queries, input CreateBookInput) (*CreateBookOutput, error) {
 	title, err := book.ParseBookTitle(input.Title)
 	if err != nil {
 		return nil, ErrInvalidTitle
@@ -56,6 +65,14 @@ func CreateBook(ctx context.Context, queries *Queries, input CreateBookInput) (*
 		return nil, err
 	}
 
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries = queries.WithTx(tx)
+	seriesQueries = seriesQueries.WithTx(tx)
+
 	err = queries.InsertBookEvent(ctx, InsertBookEventParams{
 		EventID:       uuid.New().String(),
 		BookID:        bookID,
@@ -72,16 +89,36 @@ func CreateBook(ctx context.Context, queries *Queries, input CreateBookInput) (*
 		return nil, err
 	}
 
+	bookSeries, err := series.AssignDetectedSeries(ctx, seriesQueries, bookID, string(title), "")
+	if err != nil {
+		return nil, err
+	}
+
+	var accessionNumber *string
+	if input.Code == nil || strings.TrimSpace(*input.Code) == "" {
+		number, err := queries.InsertBookAccessionAssignedEvent(ctx, InsertBookAccessionAssignedEventParams{
+			EventID:    uuid.New().String(),
+			BookID:     bookID,
+			OccurredAt: now.Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, err
+		}
+		accessionNumber = &number.String
+	}
+
 	return &CreateBookOutput{
-		ID:            bookID,
-		Title:         string(title),
-		Authors:       authors,
-		Publisher:     input.Publisher,
-		PublishedDate: input.PublishedDate,
-		ThumbnailURL:  input.ThumbnailURL,
-		Status:        "available",
-		CreatedAt:     now,
-	}, nil
+		ID:              bookID,
+		Title:           string(title),
+		Authors:         authors,
+		Publisher:       input.Publisher,
+		PublishedDate:   input.PublishedDate,
+		ThumbnailURL:    input.ThumbnailURL,
+		Status:          "available",
+		Series:          bookSeries,
+		AccessionNumber: accessionNumber,
+		CreatedAt:       now,
+	}, tx.Commit()
 }
 
 func toNullString(s *string) sql.NullString {
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrInvalidBookFacet = errors.New("invalid book facet")
+
+type BookFacet string
+
+const (
+	BookFacetStatus    BookFacet = "status"
+	BookFacetPublisher BookFacet = "publisher"
+	BookFacetAuthor    BookFacet = "author"
+	BookFacetDecade    BookFacet = "decade"
+	BookFacetTag       BookFacet = "tag"
+)
+
+// BookFacetValueLimit is how many values a facet returns at most, the most
+// frequent first.
+const BookFacetValueLimit = 20
+
+type FacetCount struct {
+	Value string
+	Count int64
+}
+
+// BookFacets are the counts of each requested facet over a filtered book list.
+// A requested facet is always present, even when no book has a value for it.
+type BookFacets map[BookFacet][]FacetCount
+
+// ToBookFacets resolves the requested facets, ignoring duplicates.
+func ToBookFacets(names []string) ([]BookFacet, error) {
+	var facets []BookFacet
+	for _, name := range names {
+		switch facet := BookFacet(name); facet {
+		case BookFacetStatus, BookFacetPublisher, BookFacetAuthor, BookFacetDecade, BookFacetTag:
+			if !slices.Contains(facets, facet) {
+				facets = append(facets, facet)
+			}
+		default:
+			return nil, ErrInvalidBookFacet
+		}
+	}
+	return facets, nil
+}
+
+func NewBookFacets(requested []BookFacet) BookFacets {
+	if len(requested) == 0 {
+		return nil
+	}
+	facets := BookFacets{}
+	for _, facet := range requested {
+		facets[facet] = []FacetCount{}
+	}
+	return facets
+}
+
+// Add appends a count in the order the counts were computed. Counts for facets
+// that were not requested are dropped.
+func (f BookFacets) Add(facet string, value string, count int64) {
+	counts, ok := f[BookFacet(facet)]
+	if !ok {
+		return
+	}
+	f[BookFacet(facet)] = append(counts, FacetCount{Value: value, Count: count})
+}
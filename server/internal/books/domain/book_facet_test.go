@@ -0,0 +1,58 @@
+//go:build small
+
+package domain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestToBookFacets_WithKnownFacets_ReturnsFacetsWithoutDuplicates(t *testing.T) {
+	facets, err := ToBookFacets([]string{"author", "status", "author", "decade", "publisher", "tag"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []BookFacet{BookFacetAuthor, BookFacetStatus, BookFacetDecade, BookFacetPublisher, BookFacetTag}
+	if !reflect.DeepEqual(facets, expected) {
+		t.Errorf("expected %v, got %v", expected, facets)
+	}
+}
+
+func TestToBookFacets_WithUnknownFacet_ReturnsError(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("rejects unknown facet", prop.ForAll(
+		func(s string) bool {
+			_, err := ToBookFacets([]string{"status", s})
+			return err == ErrInvalidBookFacet
+		},
+		gen.AlphaString().SuchThat(func(s string) bool {
+			return s != "status" && s != "publisher" && s != "author" && s != "decade" && s != "tag"
+		}),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNewBookFacets_WithoutRequestedFacets_ReturnsNil(t *testing.T) {
+	if facets := NewBookFacets(nil); facets != nil {
+		t.Errorf("expected nil, got %v", facets)
+	}
+}
+
+func TestBookFacets_Add_KeepsOnlyRequestedFacets(t *testing.T) {
+	facets := NewBookFacets([]BookFacet{BookFacetPublisher, BookFacetDecade})
+	facets.Add("publisher", "Gihyo", 2)
+	facets.Add("author", "A", 1)
+	facets.Add("publisher", "Manning", 1)
+
+	expected := BookFacets{
+		BookFacetPublisher: {{Value: "Gihyo", Count: 2}, {Value: "Manning", Count: 1}},
+		BookFacetDecade:    {},
+	}
+	if !reflect.DeepEqual(facets, expected) {
+		t.Errorf("expected %v, got %v", expected, facets)
+	}
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrInvalidBookStatus    = errors.New("invalid book status")
+	ErrInvalidPublishedDate = errors.New("invalid published date")
+)
+
+type BookStatus string
+
+const (
+	BookStatusAvailable BookStatus = "available"
+	BookStatusBorrowed  BookStatus = "borrowed"
+	BookStatusOverdue   BookStatus = "overdue"
+)
+
+// BorrowerMe is the borrower filter value that stands for the requesting user.
+const BorrowerMe = "me"
+
+// BookFilter narrows a book list. Every condition is optional and the
+// conditions that are set must all hold.
+type BookFilter struct {
+	Code          *string
+	Status        *BookStatus
+	BorrowerID    *string
+	Publisher     *string
+	Author        *string
+	PublishedFrom *string
+	PublishedTo   *string
+	CreatedAfter  *time.Time
+	Tag           *string
+	CategoryID    *string
+	ShelfLocation *string
+}
+
+func ToBookStatus(s *string) (*BookStatus, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+	switch status := BookStatus(*s); status {
+	case BookStatusAvailable, BookStatusBorrowed, BookStatusOverdue:
+		return &status, nil
+	}
+	return nil, ErrInvalidBookStatus
+}
+
+// ToBorrowerID resolves the borrower filter, where "me" is the requesting user.
+func ToBorrowerID(borrower *string, requesterID string) *string {
+	if borrower == nil || *borrower == "" {
+		return nil
+	}
+	if *borrower == BorrowerMe {
+		return &requesterID
+	}
+	return borrower
+}
+
+var publishedDateBoundPattern = regexp.MustCompile(`^\d{4}(-\d{2}(-\d{2})?)?$`)
+
+// ToPublishedDateBound validates a bound on the published date. Published dates
+// are recorded as YYYY, YYYY-MM or YYYY-MM-DD, so a bound may use any of them and
+// is compared at its own precision: published_to=2020 includes 2020-12-31.
+func ToPublishedDateBound(s *string) (*string, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+	if !publishedDateBoundPattern.MatchString(*s) {
+		return nil, ErrInvalidPublishedDate
+	}
+	return s, nil
+}
+
+func ToOptionalString(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
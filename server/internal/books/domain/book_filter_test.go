@@ -0,0 +1,93 @@
+//go:build small
+
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestToBookStatus_WithKnownStatus_ReturnsStatus(t *testing.T) {
+	for _, s := range []string{"available", "borrowed", "overdue"} {
+		status, err := ToBookStatus(&s)
+		if err != nil || status == nil || string(*status) != s {
+			t.Errorf("%s: unexpected result %v, %v", s, status, err)
+		}
+	}
+}
+
+func TestToBookStatus_WithUnknownStatus_ReturnsError(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("rejects unknown status", prop.ForAll(
+		func(s string) bool {
+			_, err := ToBookStatus(&s)
+			return err == ErrInvalidBookStatus
+		},
+		gen.AlphaString().SuchThat(func(s string) bool {
+			return s != "" && s != "available" && s != "borrowed" && s != "overdue"
+		}),
+	))
+	properties.TestingRun(t)
+}
+
+func TestToBookStatus_WithNilOrEmpty_ReturnsNil(t *testing.T) {
+	empty := ""
+	for _, s := range []*string{nil, &empty} {
+		status, err := ToBookStatus(s)
+		if status != nil || err != nil {
+			t.Errorf("expected nil, got %v, %v", status, err)
+		}
+	}
+}
+
+func TestToBorrowerID_WithMe_ReturnsRequesterID(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("me is the requester", prop.ForAll(
+		func(requesterID string) bool {
+			me := "me"
+			id := ToBorrowerID(&me, requesterID)
+			return id != nil && *id == requesterID
+		},
+		gen.Identifier(),
+	))
+	properties.Property("any other value is a user ID", prop.ForAll(
+		func(borrower string) bool {
+			id := ToBorrowerID(&borrower, "requester")
+			return id != nil && *id == borrower
+		},
+		gen.Identifier().SuchThat(func(s string) bool { return s != "me" }),
+	))
+	properties.TestingRun(t)
+}
+
+func TestToPublishedDateBound_WithDateAtAnyPrecision_ReturnsBound(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("accepts YYYY, YYYY-MM and YYYY-MM-DD", prop.ForAll(
+		func(year, month, day, precision int) bool {
+			bound := []string{
+				fmt.Sprintf("%04d", year),
+				fmt.Sprintf("%04d-%02d", year, month),
+				fmt.Sprintf("%04d-%02d-%02d", year, month, day),
+			}[precision]
+			result, err := ToPublishedDateBound(&bound)
+			return err == nil && result != nil && *result == bound
+		},
+		gen.IntRange(1000, 9999),
+		gen.IntRange(1, 12),
+		gen.IntRange(1, 31),
+		gen.IntRange(0, 2),
+	))
+	properties.TestingRun(t)
+}
+
+func TestToPublishedDateBound_WithInvalidDate_ReturnsError(t *testing.T) {
+	for _, s := range []string{"20", "2020/01", "2020-1", "2020-01-01T00:00:00Z", "next year"} {
+		if _, err := ToPublishedDateBound(&s); err != ErrInvalidPublishedDate {
+			t.Errorf("%s: expected ErrInvalidPublishedDate, got %v", s, err)
+		}
+	}
+}
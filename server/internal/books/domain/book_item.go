@@ -14,6 +14,13 @@ type Borrower struct {
 	BorrowedAt time.Time
 }
 
+// BookCategory is the place of a book in the category tree.
+type BookCategory struct {
+	ID   string
+	Name string
+	Code *string
+}
+
 type BookItem struct {
 	ID            string
 	Code          *string
@@ -25,6 +32,10 @@ type BookItem struct {
 	Status        string
 	Borrower      *Borrower
 	CreatedAt     time.Time
+	Tags          []string
+	Category      *BookCategory
+	ShelfLocation *string
+	Highlights    *BookHighlights
 }
 
 func BookItemFromRow(
@@ -84,3 +95,26 @@ func BookItemFromRow(
 		CreatedAt:     createdAt,
 	}, nil
 }
+
+// SetClassification fills in the tags, category and shelf location, which come
+// from their own book events. A book without tags has an empty list.
+func (b *BookItem) SetClassification(
+	tagsJSON *string,
+	categoryID *string,
+	categoryName *string,
+	categoryCode *string,
+	shelfLocation *string,
+) error {
+	b.Tags = []string{}
+	if tagsJSON != nil {
+		if err := json.Unmarshal([]byte(*tagsJSON), &b.Tags); err != nil {
+			return ErrInvalidBookRow
+		}
+	}
+	b.Category = nil
+	if categoryID != nil && categoryName != nil {
+		b.Category = &BookCategory{ID: *categoryID, Name: *categoryName, Code: categoryCode}
+	}
+	b.ShelfLocation = shelfLocation
+	return nil
+}
@@ -236,3 +236,59 @@ func TestBookItemFromRow_WithInvalidBorrowedAt_ReturnsError(t *testing.T) {
 	))
 	properties.TestingRun(t)
 }
+
+// When SetClassification with tags, category and shelf then sets the same values
+func TestBookItem_SetClassification_WithValues_SetsClassification(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("sets the same tags, category and shelf location", prop.ForAll(
+		func(tags []string, categoryID, categoryName, shelf string) bool {
+			tagsJSON, _ := json.Marshal(tags)
+			tagsString := string(tagsJSON)
+			item := &BookItem{}
+
+			err := item.SetClassification(&tagsString, &categoryID, &categoryName, nil, &shelf)
+
+			if err != nil || len(item.Tags) != len(tags) {
+				return false
+			}
+			for i := range tags {
+				if item.Tags[i] != tags[i] {
+					return false
+				}
+			}
+			return item.Category != nil &&
+				item.Category.ID == categoryID &&
+				item.Category.Name == categoryName &&
+				item.Category.Code == nil &&
+				item.ShelfLocation != nil && *item.ShelfLocation == shelf
+		},
+		gen.SliceOf(gen.AnyString()),
+		gen.AnyString(),
+		gen.AnyString(),
+		gen.AnyString(),
+	))
+	properties.TestingRun(t)
+}
+
+func TestBookItem_SetClassification_WithoutValues_ReturnsEmptyTags(t *testing.T) {
+	item := &BookItem{}
+
+	if err := item.SetClassification(nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Tags == nil || len(item.Tags) != 0 {
+		t.Errorf("expected empty tags, got %v", item.Tags)
+	}
+	if item.Category != nil || item.ShelfLocation != nil {
+		t.Errorf("expected no category and shelf, got %+v %v", item.Category, item.ShelfLocation)
+	}
+}
+
+func TestBookItem_SetClassification_WithInvalidTagsJSON_ReturnsError(t *testing.T) {
+	invalid := "not json"
+	item := &BookItem{}
+
+	if err := item.SetClassification(&invalid, nil, nil, nil, nil); err != ErrInvalidBookRow {
+		t.Errorf("expected ErrInvalidBookRow, got %v", err)
+	}
+}
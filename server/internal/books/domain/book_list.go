@@ -8,14 +8,25 @@ import (
 var ErrBookListNotAvailable = errors.New("book list not available")
 var ErrNotMyResponsibility = errors.New("not my responsibility")
 
-type BookListSource func(ctx context.Context, keyword *SearchKeyword, pagination Pagination) ([]BookItem, int64, error)
+// BookListQuery is what a book list is asked for. Sources decide from the keyword
+// whether they are responsible, and apply the filter and sort themselves so that
+// they combine with the keyword. Facets only adds counts to the page, so it is left
+// out of the fingerprint that cursors are bound to.
+type BookListQuery struct {
+	Keyword *SearchKeyword
+	Filter  BookFilter
+	Sort    BookSort
+	Facets  []BookFacet `json:"-"`
+}
+
+type BookListSource func(ctx context.Context, query BookListQuery, pagination Pagination) (BookPage, error)
 
-func GetBookList(ctx context.Context, sources []BookListSource, keyword *SearchKeyword, pagination Pagination) ([]BookItem, int64, error) {
+func GetBookList(ctx context.Context, sources []BookListSource, query BookListQuery, pagination Pagination) (BookPage, error) {
 	for _, src := range sources {
-		items, total, err := src(ctx, keyword, pagination)
+		page, err := src(ctx, query, pagination)
 		if err == nil {
-			return items, total, nil
+			return page, nil
 		}
 	}
-	return nil, 0, ErrBookListNotAvailable
+	return BookPage{}, ErrBookListNotAvailable
 }
@@ -20,16 +20,16 @@ func TestGetBookList_WithFirstSourceSucceeds_ReturnsFirstSourceResult(t *testing
 		func(total int64) bool {
 			items := []BookItem{{ID: "first"}}
 			sources := []BookListSource{
-				func(ctx context.Context, keyword *SearchKeyword, pagination Pagination) ([]BookItem, int64, error) {
-					return items, total, nil
+				func(ctx context.Context, query BookListQuery, pagination Pagination) (BookPage, error) {
+					return BookPage{Items: items, Total: &total}, nil
 				},
-				func(ctx context.Context, keyword *SearchKeyword, pagination Pagination) ([]BookItem, int64, error) {
-					return []BookItem{{ID: "second"}}, 999, nil
+				func(ctx context.Context, query BookListQuery, pagination Pagination) (BookPage, error) {
+					return BookPage{Items: []BookItem{{ID: "second"}}}, nil
 				},
 			}
 
-			result, resultTotal, err := GetBookList(context.Background(), sources, nil, Pagination{})
-			return err == nil && len(result) == 1 && result[0].ID == "first" && resultTotal == total
+			page, err := GetBookList(context.Background(), sources, BookListQuery{}, Pagination{})
+			return err == nil && len(page.Items) == 1 && page.Items[0].ID == "first" && *page.Total == total
 		},
 		gen.Int64(),
 	))
@@ -39,37 +39,34 @@ func TestGetBookList_WithFirstSourceSucceeds_ReturnsFirstSourceResult(t *testing
 func TestGetBookList_WithFirstSourceFails_ReturnsSecondSourceResult(t *testing.T) {
 	items := []BookItem{{ID: "second"}}
 	sources := []BookListSource{
-		func(ctx context.Context, keyword *SearchKeyword, pagination Pagination) ([]BookItem, int64, error) {
-			return nil, 0, errNotMyResponsibility
+		func(ctx context.Context, query BookListQuery, pagination Pagination) (BookPage, error) {
+			return BookPage{}, errNotMyResponsibility
 		},
-		func(ctx context.Context, keyword *SearchKeyword, pagination Pagination) ([]BookItem, int64, error) {
-			return items, 1, nil
+		func(ctx context.Context, query BookListQuery, pagination Pagination) (BookPage, error) {
+			return BookPage{Items: items}, nil
 		},
 	}
 
-	result, total, err := GetBookList(context.Background(), sources, nil, Pagination{})
+	page, err := GetBookList(context.Background(), sources, BookListQuery{}, Pagination{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(result) != 1 || result[0].ID != "second" {
-		t.Errorf("expected second source result, got %v", result)
-	}
-	if total != 1 {
-		t.Errorf("expected total 1, got %d", total)
+	if len(page.Items) != 1 || page.Items[0].ID != "second" {
+		t.Errorf("expected second source result, got %v", page.Items)
 	}
 }
 
 func TestGetBookList_WithAllSourcesFail_ReturnsError(t *testing.T) {
 	sources := []BookListSource{
-		func(ctx context.Context, keyword *SearchKeyword, pagination Pagination) ([]BookItem, int64, error) {
-			return nil, 0, errNotMyResponsibility
+		func(ctx context.Context, query BookListQuery, pagination Pagination) (BookPage, error) {
+			return BookPage{}, errNotMyResponsibility
 		},
-		func(ctx context.Context, keyword *SearchKeyword, pagination Pagination) ([]BookItem, int64, error) {
-			return nil, 0, errNotMyResponsibility
+		func(ctx context.Context, query BookListQuery, pagination Pagination) (BookPage, error) {
+			return BookPage{}, errNotMyResponsibility
 		},
 	}
 
-	_, _, err := GetBookList(context.Background(), sources, nil, Pagination{})
+	_, err := GetBookList(context.Background(), sources, BookListQuery{}, Pagination{})
 	if err == nil {
 		t.Error("expected error, got nil")
 	}
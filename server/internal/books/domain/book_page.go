@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+)
+
+// BookPosition is the place of a book in a list order: its sort value, then
+// updated_at and the book ID that break ties.
+type BookPosition struct {
+	SortValue any    `json:"v"`
+	UpdatedAt string `json:"u"`
+	BookID    string `json:"id"`
+}
+
+func NewBookPosition(sortValue any, updatedAt string, bookID string) BookPosition {
+	if b, ok := sortValue.([]byte); ok {
+		sortValue = string(b)
+	}
+	return BookPosition{SortValue: sortValue, UpdatedAt: updatedAt, BookID: bookID}
+}
+
+// BookCursor is the content of a next or previous cursor. Query ties it to the
+// list it was issued for, because a position only makes sense in that order.
+type BookCursor struct {
+	Position BookPosition `json:"p"`
+	Backward bool         `json:"b,omitempty"`
+	Query    string       `json:"q"`
+}
+
+func (q BookListQuery) Fingerprint() string {
+	data, _ := json.Marshal(q)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+type BookPage struct {
+	Items    []BookItem
+	Total    *int64
+	Next     *BookPosition
+	Previous *BookPosition
+	Facets   BookFacets
+}
+
+// NewBookPage builds a page from books fetched in scan order. Sources fetch one book
+// more than the limit, which tells whether the list goes on in that direction.
+func NewBookPage(items []BookItem, positions []BookPosition, pagination Pagination, total *int64) BookPage {
+	hasMore := len(items) > pagination.Limit()
+	if hasMore {
+		items = items[:pagination.Limit()]
+		positions = positions[:pagination.Limit()]
+	}
+	if pagination.Backward() {
+		items = slices.Clone(items)
+		positions = slices.Clone(positions)
+		slices.Reverse(items)
+		slices.Reverse(positions)
+	}
+
+	page := BookPage{Items: items, Total: total}
+	start := pagination.Position()
+	if len(positions) == 0 {
+		// An empty page past either end can still page back to where it started.
+		if pagination.Backward() {
+			page.Next = start
+		} else {
+			page.Previous = start
+		}
+		return page
+	}
+
+	first, last := positions[0], positions[len(positions)-1]
+	if pagination.Backward() {
+		if hasMore {
+			page.Previous = &first
+		}
+		page.Next = &last
+		return page
+	}
+	if hasMore {
+		page.Next = &last
+	}
+	if start != nil || pagination.Offset() > 0 {
+		page.Previous = &first
+	}
+	return page
+}
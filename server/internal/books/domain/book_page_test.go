@@ -0,0 +1,126 @@
+//go:build small
+
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func fetchedBooks(n int) ([]BookItem, []BookPosition) {
+	items := make([]BookItem, n)
+	positions := make([]BookPosition, n)
+	for i := range n {
+		id := fmt.Sprintf("book-%d", i)
+		items[i] = BookItem{ID: id}
+		positions[i] = BookPosition{SortValue: int64(i), BookID: id}
+	}
+	return items, positions
+}
+
+func TestNewBookPage_Forward_TrimsToLimitAndPointsNextAtLastBook(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("next cursor only when more books were fetched", prop.ForAll(
+		func(limit, fetched int) bool {
+			items, positions := fetchedBooks(fetched)
+			page := NewBookPage(items, positions, ToPagination(&limit, nil), nil)
+
+			size := min(limit, fetched)
+			if len(page.Items) != size || page.Previous != nil {
+				return false
+			}
+			if fetched <= limit {
+				return page.Next == nil
+			}
+			return page.Next != nil && page.Next.BookID == page.Items[size-1].ID
+		},
+		gen.IntRange(1, 10),
+		gen.IntRange(0, 11),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNewBookPage_Backward_ReversesAndPointsPreviousAtFirstBook(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("backward page is in list order", prop.ForAll(
+		func(limit, fetched int) bool {
+			items, positions := fetchedBooks(fetched)
+			start := BookPosition{BookID: "start"}
+			pagination := ToPagination(&limit, nil).WithCursor(start, true)
+			page := NewBookPage(items, positions, pagination, nil)
+
+			size := min(limit, fetched)
+			if len(page.Items) != size {
+				return false
+			}
+			for i, item := range page.Items {
+				if item.ID != fmt.Sprintf("book-%d", size-1-i) {
+					return false
+				}
+			}
+			if size == 0 {
+				return page.Next != nil && page.Next.BookID == "start" && page.Previous == nil
+			}
+			if page.Next == nil || page.Next.BookID != page.Items[size-1].ID {
+				return false
+			}
+			if fetched <= limit {
+				return page.Previous == nil
+			}
+			return page.Previous != nil && page.Previous.BookID == page.Items[0].ID
+		},
+		gen.IntRange(1, 10),
+		gen.IntRange(0, 11),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNewBookPage_WithCursorOrOffset_HasPrevious(t *testing.T) {
+	items, positions := fetchedBooks(2)
+	limit, offset := 5, 3
+
+	page := NewBookPage(items, positions, ToPagination(&limit, &offset), nil)
+	if page.Previous == nil || page.Previous.BookID != "book-0" {
+		t.Errorf("expected previous cursor at the first book with an offset, got %+v", page.Previous)
+	}
+
+	start := BookPosition{BookID: "start"}
+	page = NewBookPage(nil, nil, ToPagination(&limit, nil).WithCursor(start, false), nil)
+	if page.Previous == nil || page.Previous.BookID != "start" {
+		t.Errorf("expected an empty page to page back from its start, got %+v", page.Previous)
+	}
+}
+
+func TestNewBookPosition_WithBytes_ReturnsString(t *testing.T) {
+	position := NewBookPosition([]byte("title"), "2024-01-01T00:00:00Z", "book-1")
+	if position.SortValue != "title" {
+		t.Errorf("expected string sort value, got %#v", position.SortValue)
+	}
+}
+
+func TestBookListQuery_Fingerprint_DependsOnQuery(t *testing.T) {
+	keyword := SearchKeyword("go")
+	publisher := "Gihyo"
+	queries := []BookListQuery{
+		{},
+		{Keyword: &keyword},
+		{Filter: BookFilter{Publisher: &publisher}},
+		{Sort: BookSort{Key: BookSortTitle}},
+		{Sort: BookSort{Key: BookSortTitle, Descending: true}},
+	}
+	seen := map[string]bool{}
+	for _, q := range queries {
+		fingerprint := q.Fingerprint()
+		if seen[fingerprint] {
+			t.Errorf("fingerprint of %+v is not unique", q)
+		}
+		if fingerprint != q.Fingerprint() {
+			t.Errorf("fingerprint of %+v is not stable", q)
+		}
+		seen[fingerprint] = true
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import "errors"
+
+var (
+	ErrInvalidBookSortKey   = errors.New("invalid book sort key")
+	ErrInvalidSortDirection = errors.New("invalid sort direction")
+)
+
+type BookSortKey string
+
+const (
+	BookSortTitle         BookSortKey = "title"
+	BookSortAuthor        BookSortKey = "author"
+	BookSortCreatedAt     BookSortKey = "created_at"
+	BookSortPublishedDate BookSortKey = "published_date"
+	BookSortPopularity    BookSortKey = "popularity"
+
+	// BookSortRelevance and BookSortUpdatedAt are the defaults with and without a
+	// search keyword. They cannot be requested explicitly.
+	BookSortRelevance BookSortKey = "relevance"
+	BookSortUpdatedAt BookSortKey = "updated_at"
+)
+
+type BookSort struct {
+	Key        BookSortKey
+	Descending bool
+}
+
+// ToBookSort resolves the requested sort. Without an order, text keys sort in
+// ascending order and dates and popularity in descending order. Books without a
+// value for the key, such as an unknown published date, always come last.
+func ToBookSort(sort, order *string, keyword *SearchKeyword) (BookSort, error) {
+	var key BookSortKey
+	switch {
+	case sort != nil && *sort != "":
+		switch k := BookSortKey(*sort); k {
+		case BookSortTitle, BookSortAuthor, BookSortCreatedAt, BookSortPublishedDate, BookSortPopularity:
+			key = k
+		default:
+			return BookSort{}, ErrInvalidBookSortKey
+		}
+	case keyword != nil:
+		key = BookSortRelevance
+	default:
+		key = BookSortUpdatedAt
+	}
+
+	descending := key != BookSortTitle && key != BookSortAuthor && key != BookSortRelevance
+	if order != nil && *order != "" {
+		switch *order {
+		case "asc":
+			descending = false
+		case "desc":
+			descending = true
+		default:
+			return BookSort{}, ErrInvalidSortDirection
+		}
+	}
+	return BookSort{Key: key, Descending: descending}, nil
+}
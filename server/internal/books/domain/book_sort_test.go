@@ -0,0 +1,66 @@
+//go:build small
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestToBookSort_WithoutSort_DefaultsByKeyword(t *testing.T) {
+	sort, err := ToBookSort(nil, nil, nil)
+	if err != nil || sort != (BookSort{Key: BookSortUpdatedAt, Descending: true}) {
+		t.Errorf("without keyword: unexpected sort %+v, %v", sort, err)
+	}
+
+	keyword := SearchKeyword("go")
+	sort, err = ToBookSort(nil, nil, &keyword)
+	if err != nil || sort != (BookSort{Key: BookSortRelevance, Descending: false}) {
+		t.Errorf("with keyword: unexpected sort %+v, %v", sort, err)
+	}
+}
+
+func TestToBookSort_WithoutOrder_UsesNaturalDirection(t *testing.T) {
+	cases := map[string]bool{
+		"title":          false,
+		"author":         false,
+		"created_at":     true,
+		"published_date": true,
+		"popularity":     true,
+	}
+	for key, descending := range cases {
+		sort, err := ToBookSort(&key, nil, nil)
+		if err != nil || string(sort.Key) != key || sort.Descending != descending {
+			t.Errorf("%s: unexpected sort %+v, %v", key, sort, err)
+		}
+	}
+}
+
+func TestToBookSort_WithOrder_OverridesDirection(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("order decides the direction", prop.ForAll(
+		func(key string, order string) bool {
+			sort, err := ToBookSort(&key, &order, nil)
+			return err == nil && sort.Descending == (order == "desc")
+		},
+		gen.OneConstOf("title", "author", "created_at", "published_date", "popularity"),
+		gen.OneConstOf("asc", "desc"),
+	))
+	properties.TestingRun(t)
+}
+
+func TestToBookSort_WithUnknownKeyOrOrder_ReturnsError(t *testing.T) {
+	for _, key := range []string{"relevance", "updated_at", "price"} {
+		if _, err := ToBookSort(&key, nil, nil); err != ErrInvalidBookSortKey {
+			t.Errorf("%s: expected ErrInvalidBookSortKey, got %v", key, err)
+		}
+	}
+
+	key, order := "title", "up"
+	if _, err := ToBookSort(&key, &order, nil); err != ErrInvalidSortDirection {
+		t.Errorf("expected ErrInvalidSortDirection, got %v", err)
+	}
+}
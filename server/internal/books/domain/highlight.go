@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"html"
+	"strings"
+)
+
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// BookHighlights holds the fields of a book that matched a search query, with the
+// matches wrapped in <mark> tags. Fields without a match are nil. Authors lists every
+// author, in order, when at least one of them matched.
+type BookHighlights struct {
+	Title     *string
+	Authors   []string
+	Publisher *string
+}
+
+// HighlightBook returns the highlighted fields of a book, or nil when no field matched.
+func HighlightBook(item BookItem, query SearchQuery) *BookHighlights {
+	var highlights BookHighlights
+	matched := false
+	if title, ok := Highlight(item.Title, query); ok {
+		highlights.Title = &title
+		matched = true
+	}
+	authors := make([]string, len(item.Authors))
+	authorMatched := false
+	for i, author := range item.Authors {
+		var ok bool
+		authors[i], ok = Highlight(author, query)
+		authorMatched = authorMatched || ok
+	}
+	if authorMatched {
+		highlights.Authors = authors
+		matched = true
+	}
+	if item.Publisher != nil {
+		if publisher, ok := Highlight(*item.Publisher, query); ok {
+			highlights.Publisher = &publisher
+			matched = true
+		}
+	}
+	if !matched {
+		return nil
+	}
+	return &highlights
+}
+
+// Highlight wraps every occurrence of the query terms in text with <mark> tags and
+// escapes the rest as HTML. Occurrences are found the same way the index matches
+// them: case-insensitively, with the words of a term next to each other and anything
+// but letters and numbers between them ignored. It reports whether any term occurred.
+func Highlight(text string, query SearchQuery) (string, bool) {
+	source := []rune(text)
+	runs := searchRuns(normalizeSearchText(text))
+	marked := make([]bool, len(source))
+	matched := false
+	for _, term := range query.Terms {
+		for _, text := range []string{term.Text, term.Kana} {
+			for _, span := range findTerm(runs, searchRuns(normalizeSearchText(text))) {
+				for i := span[0]; i <= span[1]; i++ {
+					marked[i] = true
+				}
+				matched = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	inMark := false
+	segment := 0
+	for i := range source {
+		if marked[i] == inMark {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(source[segment:i])))
+		if marked[i] {
+			b.WriteString(HighlightStart)
+		} else {
+			b.WriteString(HighlightEnd)
+		}
+		inMark = marked[i]
+		segment = i
+	}
+	b.WriteString(html.EscapeString(string(source[segment:])))
+	if inMark {
+		b.WriteString(HighlightEnd)
+	}
+	return b.String(), matched
+}
+
+// findTerm returns the first and last original rune of every occurrence of a term
+// in text, both given as runs. A term of one run may occur anywhere inside a run. A
+// term of several runs must start at the end of a run, cover the runs in between
+// entirely and end at the start of a run, like the phrase it is queried as.
+func findTerm(text, term [][]searchRune) [][2]int {
+	if len(term) == 0 {
+		return nil
+	}
+	var spans [][2]int
+	if len(term) == 1 {
+		needle := term[0]
+		for _, run := range text {
+			for start := 0; start+len(needle) <= len(run); start++ {
+				if equalRunes(run[start:start+len(needle)], needle) {
+					spans = append(spans, [2]int{run[start].from, run[start+len(needle)-1].to})
+				}
+			}
+		}
+		return spans
+	}
+	first, last := term[0], term[len(term)-1]
+	for j := 0; j+len(term) <= len(text); j++ {
+		head, tail := text[j], text[j+len(term)-1]
+		if len(head) < len(first) || !equalRunes(head[len(head)-len(first):], first) {
+			continue
+		}
+		if len(tail) < len(last) || !equalRunes(tail[:len(last)], last) {
+			continue
+		}
+		middle := true
+		for k := 1; k < len(term)-1; k++ {
+			if !equalRunes(text[j+k], term[k]) {
+				middle = false
+				break
+			}
+		}
+		if middle {
+			spans = append(spans, [2]int{head[len(head)-len(first)].from, tail[len(last)-1].to})
+		}
+	}
+	return spans
+}
+
+func equalRunes(a, b []searchRune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].r != b[i].r {
+			return false
+		}
+	}
+	return true
+}
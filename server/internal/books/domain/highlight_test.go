@@ -0,0 +1,82 @@
+//go:build small
+
+package domain
+
+import (
+	"html"
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestHighlight_WithoutMarks_ReturnsEscapedText(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("removing the marks gives back the escaped text", prop.ForAll(
+		func(text, term string) bool {
+			highlighted, _ := Highlight(text, ParseSearchQuery(SearchKeyword(term)))
+			unmarked := strings.NewReplacer(HighlightStart, "", HighlightEnd, "").Replace(highlighted)
+			return unmarked == html.EscapeString(text)
+		},
+		gen.AnyString(),
+		gen.AlphaString(),
+	))
+	properties.TestingRun(t)
+}
+
+func TestHighlight_WithContainedTerm_MarksTerm(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("a term surrounded by other text is marked", prop.ForAll(
+		func(before, term, after string) bool {
+			highlighted, ok := Highlight(before+" "+term+" "+after, ParseSearchQuery(SearchKeyword(term)))
+			return ok && strings.Contains(highlighted, HighlightStart+term+HighlightEnd)
+		},
+		genKanaString(0, 10),
+		genKanaString(1, 5),
+		genKanaString(0, 10),
+	))
+	properties.TestingRun(t)
+}
+
+func TestHighlight_IgnoresCaseAndPunctuation(t *testing.T) {
+	highlighted, ok := Highlight("Learning <Go>: The Go Way", ParseSearchQuery("go"))
+
+	if !ok || highlighted != "Learning &lt;<mark>Go</mark>&gt;: The <mark>Go</mark> Way" {
+		t.Errorf("unexpected highlight: %q", highlighted)
+	}
+}
+
+func TestHighlight_WithoutMatch_ReturnsFalse(t *testing.T) {
+	if _, ok := Highlight("羅生門", ParseSearchQuery("猫")); ok {
+		t.Error("expected no match")
+	}
+}
+
+func TestHighlightBook_WithAuthorMatch_ReturnsEveryAuthor(t *testing.T) {
+	item := BookItem{Title: "こころ", Authors: []string{"夏目漱石", "編集部"}}
+
+	highlights := HighlightBook(item, ParseSearchQuery("漱石"))
+
+	if highlights == nil || highlights.Title != nil || highlights.Publisher != nil {
+		t.Fatalf("expected only authors, got %+v", highlights)
+	}
+	if len(highlights.Authors) != 2 || highlights.Authors[0] != "夏目<mark>漱石</mark>" || highlights.Authors[1] != "編集部" {
+		t.Errorf("unexpected authors: %q", highlights.Authors)
+	}
+}
+
+func TestHighlight_WithTermSpanningWords_MarksOnlyAdjacentWords(t *testing.T) {
+	cases := map[string]string{
+		"あ ああ":    "あ <mark>ああ</mark>",
+		"ジョン・スミス": "<mark>ジョン・スミス</mark>",
+		"ジョン スミ":  "ジョン スミ",
+	}
+	for text, expected := range cases {
+		highlighted, _ := Highlight(text, ParseSearchQuery("ジョン・スミス ああ"))
+		if highlighted != expected {
+			t.Errorf("%s: expected %q, got %q", text, expected, highlighted)
+		}
+	}
+}
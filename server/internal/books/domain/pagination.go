@@ -1,8 +1,11 @@
 package domain
 
 type Pagination struct {
-	limit  int
-	offset int
+	limit     int
+	offset    int
+	position  *BookPosition
+	backward  bool
+	skipTotal bool
 }
 
 func ToPagination(limit, offset *int) Pagination {
@@ -17,6 +20,21 @@ func ToPagination(limit, offset *int) Pagination {
 	return Pagination{limit: l, offset: o}
 }
 
+// WithCursor continues a list from a position instead of an offset. A backward
+// cursor pages towards the start of the list.
+func (p Pagination) WithCursor(position BookPosition, backward bool) Pagination {
+	p.offset = 0
+	p.position = &position
+	p.backward = backward
+	return p
+}
+
+// WithoutTotal skips counting the whole list, which costs as much as listing it.
+func (p Pagination) WithoutTotal() Pagination {
+	p.skipTotal = true
+	return p
+}
+
 func (p Pagination) Limit() int {
 	return p.limit
 }
@@ -24,3 +42,15 @@ func (p Pagination) Limit() int {
 func (p Pagination) Offset() int {
 	return p.offset
 }
+
+func (p Pagination) Position() *BookPosition {
+	return p.position
+}
+
+func (p Pagination) Backward() bool {
+	return p.backward
+}
+
+func (p Pagination) IncludesTotal() bool {
+	return !p.skipTotal
+}
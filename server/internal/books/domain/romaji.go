@@ -0,0 +1,103 @@
+package domain
+
+import "strings"
+
+// romajiSyllables maps romaji to hiragana for Hepburn, Kunrei-shiki and the spellings
+// commonly typed with a Japanese input method.
+var romajiSyllables = map[string]string{
+	"a": "あ", "i": "い", "u": "う", "e": "え", "o": "お",
+	"ka": "か", "ki": "き", "ku": "く", "ke": "け", "ko": "こ",
+	"kya": "きゃ", "kyu": "きゅ", "kyo": "きょ",
+	"ga": "が", "gi": "ぎ", "gu": "ぐ", "ge": "げ", "go": "ご",
+	"gya": "ぎゃ", "gyu": "ぎゅ", "gyo": "ぎょ",
+	"sa": "さ", "shi": "し", "si": "し", "su": "す", "se": "せ", "so": "そ",
+	"sha": "しゃ", "shu": "しゅ", "she": "しぇ", "sho": "しょ",
+	"sya": "しゃ", "syu": "しゅ", "syo": "しょ",
+	"za": "ざ", "ji": "じ", "zi": "じ", "zu": "ず", "ze": "ぜ", "zo": "ぞ",
+	"ja": "じゃ", "ju": "じゅ", "je": "じぇ", "jo": "じょ",
+	"zya": "じゃ", "zyu": "じゅ", "zyo": "じょ", "jya": "じゃ", "jyu": "じゅ", "jyo": "じょ",
+	"ta": "た", "chi": "ち", "ti": "ち", "tsu": "つ", "tu": "つ", "te": "て", "to": "と",
+	"cha": "ちゃ", "chu": "ちゅ", "che": "ちぇ", "cho": "ちょ",
+	"tya": "ちゃ", "tyu": "ちゅ", "tyo": "ちょ",
+	"da": "だ", "di": "ぢ", "du": "づ", "de": "で", "do": "ど",
+	"dya": "ぢゃ", "dyu": "ぢゅ", "dyo": "ぢょ",
+	"na": "な", "ni": "に", "nu": "ぬ", "ne": "ね", "no": "の",
+	"nya": "にゃ", "nyu": "にゅ", "nyo": "にょ",
+	"ha": "は", "hi": "ひ", "fu": "ふ", "hu": "ふ", "he": "へ", "ho": "ほ",
+	"hya": "ひゃ", "hyu": "ひゅ", "hyo": "ひょ",
+	"fa": "ふぁ", "fi": "ふぃ", "fe": "ふぇ", "fo": "ふぉ",
+	"ba": "ば", "bi": "び", "bu": "ぶ", "be": "べ", "bo": "ぼ",
+	"bya": "びゃ", "byu": "びゅ", "byo": "びょ",
+	"pa": "ぱ", "pi": "ぴ", "pu": "ぷ", "pe": "ぺ", "po": "ぽ",
+	"pya": "ぴゃ", "pyu": "ぴゅ", "pyo": "ぴょ",
+	"ma": "ま", "mi": "み", "mu": "む", "me": "め", "mo": "も",
+	"mya": "みゃ", "myu": "みゅ", "myo": "みょ",
+	"ya": "や", "yu": "ゆ", "yo": "よ",
+	"ra": "ら", "ri": "り", "ru": "る", "re": "れ", "ro": "ろ",
+	"rya": "りゃ", "ryu": "りゅ", "ryo": "りょ",
+	"wa": "わ", "wo": "を",
+	"va": "ゔぁ", "vi": "ゔぃ", "vu": "ゔ", "ve": "ゔぇ", "vo": "ゔぉ",
+	"n'": "ん",
+	"xa": "ぁ", "xi": "ぃ", "xu": "ぅ", "xe": "ぇ", "xo": "ぉ",
+	"xya": "ゃ", "xyu": "ゅ", "xyo": "ょ", "xtu": "っ", "xtsu": "っ",
+	"-": "ー",
+}
+
+// RomajiToKana converts a romaji word into hiragana. It reports false unless the
+// whole word is romaji, so English words that happen to contain a valid syllable
+// are not read as Japanese.
+func RomajiToKana(word string) (string, bool) {
+	word = strings.ToLower(word)
+	var b strings.Builder
+	for i := 0; i < len(word); {
+		c := word[i]
+		next, afterNext := byte(0), byte(0)
+		if i+1 < len(word) {
+			next = word[i+1]
+		}
+		if i+2 < len(word) {
+			afterNext = word[i+2]
+		}
+		switch {
+		// A doubled consonant is a small tsu, as is the t of tch in Hepburn.
+		case isRomajiConsonant(c) && c != 'n' && (next == c || (c == 't' && next == 'c')):
+			b.WriteString("っ")
+			i++
+			continue
+		// n before a consonant or at the end of the word is the moraic nasal. nn is
+		// also read as one, unless the second n starts a syllable as in konnichiwa.
+		case c == 'n' && next == 'n':
+			b.WriteString("ん")
+			if afterNext == 0 || (isRomajiConsonant(afterNext) && afterNext != 'y') {
+				i += 2
+			} else {
+				i++
+			}
+			continue
+		case c == 'n' && (next == 0 || (isRomajiConsonant(next) && next != 'y')):
+			b.WriteString("ん")
+			i++
+			continue
+		}
+		matched := false
+		for size := 4; size > 0; size-- {
+			if i+size > len(word) {
+				continue
+			}
+			if kana, ok := romajiSyllables[word[i:i+size]]; ok {
+				b.WriteString(kana)
+				i += size
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "", false
+		}
+	}
+	return b.String(), b.Len() > 0
+}
+
+func isRomajiConsonant(c byte) bool {
+	return c >= 'a' && c <= 'z' && !strings.ContainsRune("aiueo", rune(c))
+}
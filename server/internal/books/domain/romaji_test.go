@@ -0,0 +1,95 @@
+//go:build small
+
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+var hepburnSyllables = [][2]string{
+	{"a", "あ"}, {"i", "い"}, {"u", "う"}, {"e", "え"}, {"o", "お"},
+	{"ka", "か"}, {"ki", "き"}, {"ku", "く"}, {"ke", "け"}, {"ko", "こ"},
+	{"sa", "さ"}, {"shi", "し"}, {"su", "す"}, {"se", "せ"}, {"so", "そ"},
+	{"ta", "た"}, {"chi", "ち"}, {"tsu", "つ"}, {"te", "て"}, {"to", "と"},
+	{"na", "な"}, {"ni", "に"}, {"nu", "ぬ"}, {"ne", "ね"}, {"no", "の"},
+	{"ha", "は"}, {"hi", "ひ"}, {"fu", "ふ"}, {"he", "へ"}, {"ho", "ほ"},
+	{"ma", "ま"}, {"mi", "み"}, {"mu", "む"}, {"me", "め"}, {"mo", "も"},
+	{"ya", "や"}, {"yu", "ゆ"}, {"yo", "よ"},
+	{"ra", "ら"}, {"ri", "り"}, {"ru", "る"}, {"re", "れ"}, {"ro", "ろ"},
+	{"wa", "わ"}, {"ga", "が"}, {"ji", "じ"}, {"zu", "ず"}, {"da", "だ"},
+	{"ba", "ば"}, {"pa", "ぱ"}, {"kya", "きゃ"}, {"sho", "しょ"}, {"cha", "ちゃ"},
+	{"ryu", "りゅ"}, {"ja", "じゃ"},
+}
+
+func TestRomajiToKana_WithHepburnSyllables_ReturnsKana(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("a sequence of syllables converts syllable by syllable", prop.ForAll(
+		func(indexes []int) bool {
+			var romaji, kana strings.Builder
+			for _, i := range indexes {
+				romaji.WriteString(hepburnSyllables[i][0])
+				kana.WriteString(hepburnSyllables[i][1])
+			}
+			converted, ok := RomajiToKana(romaji.String())
+			return ok && converted == kana.String()
+		},
+		gen.SliceOf(gen.IntRange(0, len(hepburnSyllables)-1)).SuchThat(func(v []int) bool { return len(v) > 0 }),
+	))
+	properties.TestingRun(t)
+}
+
+func TestRomajiToKana_IgnoresCase(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("upper case romaji converts like lower case", prop.ForAll(
+		func(i int) bool {
+			lower, _ := RomajiToKana(hepburnSyllables[i][0])
+			upper, ok := RomajiToKana(strings.ToUpper(hepburnSyllables[i][0]))
+			return ok && upper == lower
+		},
+		gen.IntRange(0, len(hepburnSyllables)-1),
+	))
+	properties.TestingRun(t)
+}
+
+func TestRomajiToKana_WithSpecialSpellings_ReturnsKana(t *testing.T) {
+	cases := map[string]string{
+		"konnichiwa": "こんにちわ",
+		"shinbun":    "しんぶん",
+		"honn":       "ほん",
+		"kon'ya":     "こんや",
+		"kitte":      "きって",
+		"matcha":     "まっちゃ",
+		"pokemon":    "ぽけもん",
+		"ra-men":     "らーめん",
+	}
+	for romaji, expected := range cases {
+		if kana, ok := RomajiToKana(romaji); !ok || kana != expected {
+			t.Errorf("%s: expected %s, got %q (%v)", romaji, expected, kana, ok)
+		}
+	}
+}
+
+func TestRomajiToKana_WithNonRomaji_ReturnsFalse(t *testing.T) {
+	for _, word := range []string{"", "english", "C++", "2024", "ハリー", "xyz"} {
+		if kana, ok := RomajiToKana(word); ok {
+			t.Errorf("%q: expected no reading, got %s", word, kana)
+		}
+	}
+}
+
+func TestWithRomaji_AddsKanaReadingOnlyToRomajiTerms(t *testing.T) {
+	query := ParseSearchQuery("Pokemon 図鑑 english").WithRomaji()
+
+	if len(query.Terms) != 3 || query.Terms[0].Kana != "ぽけもん" || query.Terms[1].Kana != "" || query.Terms[2].Kana != "" {
+		t.Fatalf("unexpected terms: %+v", query.Terms)
+	}
+	expected := `("po ok ke em mo on" OR "ぽけ けも もん") AND "図鑑" AND "en ng gl li is sh"`
+	if expression := query.MatchExpression(); expression != expected {
+		t.Errorf("expected %s, got %s", expected, expression)
+	}
+}
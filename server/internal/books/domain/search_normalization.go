@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// searchRune is a normalized character together with the range of runes in the
+// original text it came from, so matches can be mapped back for highlighting.
+type searchRune struct {
+	r    rune
+	from int
+	to   int
+}
+
+func (sr searchRune) isSeparator() bool {
+	return sr.r == ' '
+}
+
+// NormalizeSearchText applies the normalization used for both indexed text and
+// queries and returns the result with separators as single spaces.
+func NormalizeSearchText(text string) string {
+	var b strings.Builder
+	for _, sr := range normalizeSearchText(text) {
+		b.WriteRune(sr.r)
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// normalizeSearchText normalizes text for searching:
+//
+//   - NFKC, which turns full-width ASCII into ASCII and half-width katakana into
+//     full-width katakana, composing voiced sound marks
+//   - case folding
+//   - katakana folded into hiragana
+//   - long vowel marks dropped, so that コンピューター matches コンピュータ; dashes
+//     after kana are taken as mistyped long vowel marks
+//   - middle dots dropped, so that ハリー・ポッター matches ハリーポッター
+//   - every other character that is not a letter or a number replaced with a separator
+//
+// Combining marks are dropped as well, because the unicode61 tokenizer of the index
+// would split a token at them. A dropped character is attributed to the character
+// before it, so highlights cover it.
+func normalizeSearchText(text string) []searchRune {
+	runeIndex := make([]int, len(text)+1)
+	n := 0
+	for i := range text {
+		runeIndex[i] = n
+		n++
+	}
+	runeIndex[len(text)] = n
+
+	out := make([]searchRune, 0, n)
+	var iter norm.Iter
+	iter.InitString(norm.NFKC, text)
+	for !iter.Done() {
+		start := iter.Pos()
+		segment := iter.Next()
+		from, to := runeIndex[start], runeIndex[iter.Pos()]-1
+		for len(segment) > 0 {
+			r, size := utf8.DecodeRune(segment)
+			segment = segment[size:]
+			out = appendSearchRune(out, foldSearchRune(r), from, to)
+		}
+	}
+	return out
+}
+
+func appendSearchRune(out []searchRune, r rune, from, to int) []searchRune {
+	var previous *searchRune
+	if len(out) > 0 && !out[len(out)-1].isSeparator() {
+		previous = &out[len(out)-1]
+	}
+
+	dropped := isLongVowelMark(r) || isMiddleDot(r) || unicode.Is(unicode.M, r) ||
+		(isDash(r) && previous != nil && isKana(previous.r))
+	switch {
+	case dropped:
+		if previous != nil {
+			previous.to = to
+		}
+		return out
+	case unicode.IsLetter(r) || unicode.IsNumber(r):
+		return append(out, searchRune{r: r, from: from, to: to})
+	default:
+		return append(out, searchRune{r: ' ', from: from, to: to})
+	}
+}
+
+// foldSearchRune folds case and folds katakana into hiragana.
+func foldSearchRune(r rune) rune {
+	r = unicode.ToLower(r)
+	switch {
+	case r >= 'ァ' && r <= 'ヶ':
+		return r - ('ァ' - 'ぁ')
+	case r == 'ヽ' || r == 'ヾ':
+		return r - ('ヽ' - 'ゝ')
+	}
+	return r
+}
+
+func isKana(r rune) bool {
+	return unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r)
+}
+
+func isLongVowelMark(r rune) bool {
+	return r == 'ー'
+}
+
+func isMiddleDot(r rune) bool {
+	switch r {
+	case '・', '·', '‧':
+		return true
+	}
+	return false
+}
+
+func isDash(r rune) bool {
+	switch r {
+	case '-', '‐', '‑', '–', '—', '―', '−', '─', '━', '〜', '~':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+//go:build small
+
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func genStringOf(min, max int, runes gopter.Gen) gopter.Gen {
+	return gen.IntRange(min, max).FlatMap(func(v interface{}) gopter.Gen {
+		return gen.SliceOfN(v.(int), runes).Map(func(rs []rune) string {
+			return string(rs)
+		})
+	}, reflect.TypeOf(""))
+}
+
+func genSearchText() gopter.Gen {
+	return genStringOf(0, 20, gen.OneGenOf(
+		gen.AlphaNumChar(),
+		gen.RuneRange('Ａ', 'ｚ'),
+		gen.RuneRange('ぁ', 'ゖ'),
+		gen.RuneRange('ァ', 'ヶ'),
+		gen.RuneRange('ｦ', 'ﾟ'),
+		gen.OneConstOf('ー', '・', ' ', '　', '-', '、', '漢', '字'),
+	))
+}
+
+func toKatakana(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'ぁ' && r <= 'ゖ' {
+			return r + ('ァ' - 'ぁ')
+		}
+		return r
+	}, s)
+}
+
+func toFullWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '!' && r <= '~' {
+			return r + ('！' - '!')
+		}
+		return r
+	}, s)
+}
+
+func TestNormalizeSearchText_IsIdempotent(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("normalizing twice is the same as normalizing once", prop.ForAll(
+		func(s string) bool {
+			once := NormalizeSearchText(s)
+			return NormalizeSearchText(once) == once
+		},
+		genSearchText(),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNormalizeSearchText_FoldsKatakanaIntoHiragana(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("katakana and hiragana normalize the same", prop.ForAll(
+		func(s string) bool {
+			return NormalizeSearchText(toKatakana(s)) == NormalizeSearchText(s)
+		},
+		genKanaString(0, 20),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNormalizeSearchText_FoldsFullWidthAndCase(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("full-width and upper case ASCII normalize as lower case ASCII", prop.ForAll(
+		func(s string) bool {
+			expected := NormalizeSearchText(strings.ToLower(s))
+			return NormalizeSearchText(toFullWidth(s)) == expected &&
+				NormalizeSearchText(strings.ToUpper(s)) == expected &&
+				expected == strings.ToLower(s)
+		},
+		genStringOf(0, 20, gen.AlphaNumChar()),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNormalizeSearchText_DropsMiddleDots(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("words joined by a middle dot normalize as one word", prop.ForAll(
+		func(first, second string, dot rune) bool {
+			return NormalizeSearchText(first+string(dot)+second) == NormalizeSearchText(first+second)
+		},
+		genKanaString(1, 10),
+		genKanaString(1, 10),
+		gen.OneConstOf('・', '･', '·'),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNormalizeSearchText_DropsLongVowelMarksAfterKana(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("long vowel marks and dashes after kana are dropped", prop.ForAll(
+		func(kana string, mark rune) bool {
+			katakana := toKatakana(kana)
+			return NormalizeSearchText(katakana+string(mark)) == NormalizeSearchText(katakana)
+		},
+		genKanaString(1, 10),
+		gen.OneConstOf('ー', 'ｰ', '－', '-', '‐', '―', '〜'),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNormalizeSearchText_KeepsDashesBetweenOtherCharacters(t *testing.T) {
+	if got := NormalizeSearchText("X-Men"); got != "x men" {
+		t.Errorf("expected the dash to separate words, got %q", got)
+	}
+}
+
+func TestNormalizeSearchText_WithCompatibilityCharacters_ReturnsCanonicalText(t *testing.T) {
+	cases := map[string]string{
+		"ｺﾝﾋﾟｭｰﾀｰ": "こんぴゅた",
+		"ｶﾞｲﾄﾞ":    "がいど",
+		"ハリー・ポッター": "はりぽった",
+		"ＡＢＣ入門":    "abc入門",
+		"㈱オーム社":    "株 おむ社",
+	}
+	for input, expected := range cases {
+		if got := NormalizeSearchText(input); got != expected {
+			t.Errorf("%s: expected %q, got %q", input, expected, got)
+		}
+	}
+}
+
+func TestMatchExpression_WithKatakanaText_MatchesHiraganaQuery(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("a hiragana query finds the same word in katakana", prop.ForAll(
+		func(word string) bool {
+			expression := ParseSearchQuery(SearchKeyword(word)).MatchExpression()
+			return phraseMatches(expression, strings.Fields(SearchIndexText(toKatakana(word))))
+		},
+		genKanaString(1, 10),
+	))
+	properties.TestingRun(t)
+}
+
+func TestHighlight_WithNormalizedMatch_MarksOriginalText(t *testing.T) {
+	cases := map[string][2]string{
+		"ＡＢＣ入門":     {"abc", "<mark>ＡＢＣ</mark>入門"},
+		"ｶﾞｲﾄﾞﾌﾞｯｸ": {"ガイド", "<mark>ｶﾞｲﾄﾞ</mark>ﾌﾞｯｸ"},
+		"ハリー・ポッター":  {"はりーぽったー", "<mark>ハリー・ポッター</mark>"},
+		"コンピューター入門": {"コンピュータ", "<mark>コンピューター</mark>入門"},
+	}
+	for text, c := range cases {
+		highlighted, ok := Highlight(text, ParseSearchQuery(SearchKeyword(c[0])))
+		if !ok || highlighted != c[1] {
+			t.Errorf("%s: expected %q, got %q", text, c[1], highlighted)
+		}
+	}
+}
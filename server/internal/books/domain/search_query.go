@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SearchTerm is one term of a search query, matched as a phrase so its words must
+// appear next to each other.
+type SearchTerm struct {
+	Text string
+	// Kana is the reading of a romaji term, matched as an alternative to Text.
+	Kana string
+}
+
+// SearchQuery is a parsed search keyword. Every term must match for a book to be found.
+type SearchQuery struct {
+	Terms []SearchTerm
+}
+
+// ParseSearchQuery splits a keyword into terms at whitespace. Text enclosed in double
+// quotes is kept as a single term even if it contains whitespace. Terms without any
+// letters or numbers are dropped.
+func ParseSearchQuery(keyword SearchKeyword) SearchQuery {
+	var terms []SearchTerm
+	var current strings.Builder
+	quoted := false
+	flush := func() {
+		if len(searchRuns(normalizeSearchText(current.String()))) > 0 {
+			terms = append(terms, SearchTerm{Text: current.String()})
+		}
+		current.Reset()
+	}
+	for _, r := range norm.NFKC.String(string(keyword)) {
+		switch {
+		case r == '"':
+			flush()
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return SearchQuery{Terms: terms}
+}
+
+// WithRomaji adds the kana reading to every term that is written entirely in romaji,
+// so that "pokemon" also finds ポケモン.
+func (q SearchQuery) WithRomaji() SearchQuery {
+	terms := make([]SearchTerm, len(q.Terms))
+	for i, term := range q.Terms {
+		terms[i] = term
+		if kana, ok := RomajiToKana(term.Text); ok {
+			terms[i].Kana = kana
+		}
+	}
+	return SearchQuery{Terms: terms}
+}
+
+func (q SearchQuery) IsEmpty() bool {
+	return len(q.Terms) == 0
+}
+
+// MatchExpression returns the FTS5 query for the search index. Each term becomes a
+// phrase of the bigram tokens it was indexed as, or a choice between the phrases of
+// the term and its kana reading.
+func (q SearchQuery) MatchExpression() string {
+	expressions := make([]string, 0, len(q.Terms))
+	for _, term := range q.Terms {
+		expression := matchPhrase(term.Text)
+		if kana := matchPhrase(term.Kana); term.Kana != "" && kana != `""` {
+			expression = "(" + expression + " OR " + kana + ")"
+		}
+		expressions = append(expressions, expression)
+	}
+	return strings.Join(expressions, " AND ")
+}
+
+// matchPhrase returns the phrase for a term. A trailing single character is matched
+// as a prefix, since it may be the first half of an indexed bigram.
+func matchPhrase(term string) string {
+	runs := searchRuns(normalizeSearchText(term))
+	var tokens []string
+	prefix := false
+	for i, run := range runs {
+		switch {
+		case i < len(runs)-1:
+			tokens = append(tokens, runTokens(run)...)
+		case len(run) == 1:
+			tokens = append(tokens, string(run[0].r))
+			prefix = true
+		default:
+			tokens = append(tokens, bigrams(run)...)
+		}
+	}
+	phrase := `"` + strings.Join(tokens, " ") + `"`
+	if prefix {
+		phrase += "*"
+	}
+	return phrase
+}
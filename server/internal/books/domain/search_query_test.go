@@ -0,0 +1,119 @@
+//go:build small
+
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func genKanaString(min, max int) gopter.Gen {
+	return gen.IntRange(min, max).FlatMap(func(v interface{}) gopter.Gen {
+		return gen.SliceOfN(v.(int), gen.RuneRange('ぁ', 'ゖ')).Map(func(rs []rune) string {
+			return string(rs)
+		})
+	}, reflect.TypeOf(""))
+}
+
+// phraseMatches reports whether an FTS5 phrase produced by MatchExpression matches the
+// indexed tokens, the way FTS5 evaluates it.
+func phraseMatches(phrase string, indexed []string) bool {
+	prefix := strings.HasSuffix(phrase, "*")
+	tokens := strings.Fields(strings.Trim(strings.TrimSuffix(phrase, "*"), `"`))
+	for start := 0; start+len(tokens) <= len(indexed); start++ {
+		ok := true
+		for i, token := range tokens {
+			last := i == len(tokens)-1
+			if indexed[start+i] != token && !(last && prefix && strings.HasPrefix(indexed[start+i], token)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMatchExpression_WithSubstringOfIndexedText_Matches(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("every substring of a word is found", prop.ForAll(
+		func(text string, from, length int) bool {
+			runes := []rune(text)
+			from = from % len(runes)
+			to := from + 1 + length%(len(runes)-from)
+			term := string(runes[from:to])
+			expression := ParseSearchQuery(SearchKeyword(term)).MatchExpression()
+			return phraseMatches(expression, strings.Fields(SearchIndexText(text)))
+		},
+		genKanaString(1, 20),
+		gen.IntRange(0, 100),
+		gen.IntRange(0, 100),
+	))
+	properties.TestingRun(t)
+}
+
+func TestMatchExpression_WithWordsAcrossPunctuation_MatchesAsPhrase(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("a term spanning punctuation is matched as adjacent words", prop.ForAll(
+		func(first, second string) bool {
+			text := first + "、" + second
+			expression := ParseSearchQuery(SearchKeyword(text)).MatchExpression()
+			return phraseMatches(expression, strings.Fields(SearchIndexText("「"+text+"」")))
+		},
+		genKanaString(1, 10),
+		genKanaString(1, 10),
+	))
+	properties.TestingRun(t)
+}
+
+func TestParseSearchQuery_WithWhitespace_SplitsTerms(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("each whitespace-separated word is a term", prop.ForAll(
+		func(words []string) bool {
+			query := ParseSearchQuery(SearchKeyword(strings.Join(words, "　 ")))
+			if len(query.Terms) != len(words) {
+				return false
+			}
+			for i, word := range words {
+				if query.Terms[i].Text != word {
+					return false
+				}
+			}
+			return true
+		},
+		gen.SliceOfN(3, genKanaString(1, 5)),
+	))
+	properties.TestingRun(t)
+}
+
+func TestParseSearchQuery_WithQuotes_KeepsPhraseAsOneTerm(t *testing.T) {
+	query := ParseSearchQuery(`"go programming" 入門`)
+
+	if len(query.Terms) != 2 || query.Terms[0].Text != "go programming" || query.Terms[1].Text != "入門" {
+		t.Errorf("unexpected terms: %q", query.Terms)
+	}
+	if expression := query.MatchExpression(); expression != `"go o pr ro og gr ra am mm mi in ng" AND "入門"` {
+		t.Errorf("unexpected expression: %s", expression)
+	}
+}
+
+func TestParseSearchQuery_WithOnlyPunctuation_ReturnsEmptyQuery(t *testing.T) {
+	for _, keyword := range []SearchKeyword{`","`, "・", `""`, "  　"} {
+		if query := ParseSearchQuery(keyword); !query.IsEmpty() {
+			t.Errorf("%q: expected an empty query, got %q", keyword, query.Terms)
+		}
+	}
+}
+
+func TestSearchIndexText_LowercasesAndIgnoresPunctuation(t *testing.T) {
+	if got := SearchIndexText(`Go言語、["A"]`); got != "go o言 言語 語 a" {
+		t.Errorf("unexpected index text: %q", got)
+	}
+}
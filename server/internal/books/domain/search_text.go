@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"strings"
+)
+
+// searchRuns splits normalized text into runs of word characters.
+func searchRuns(text []searchRune) [][]searchRune {
+	var runs [][]searchRune
+	start := -1
+	for i, sr := range text {
+		if sr.isSeparator() {
+			if start >= 0 {
+				runs = append(runs, text[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		runs = append(runs, text[start:])
+	}
+	return runs
+}
+
+// bigrams returns the overlapping two-character tokens of a run.
+func bigrams(run []searchRune) []string {
+	tokens := make([]string, 0, len(run))
+	for i := 0; i+1 < len(run); i++ {
+		tokens = append(tokens, string([]rune{run[i].r, run[i+1].r}))
+	}
+	return tokens
+}
+
+// runTokens returns the tokens a run is indexed as: its bigrams followed by its last
+// character. The trailing unigram lets single-character prefix queries match the end
+// of a word and keeps phrases spanning several runs adjacent.
+func runTokens(run []searchRune) []string {
+	return append(bigrams(run), string(run[len(run)-1].r))
+}
+
+// SearchIndexText converts text into the space-separated bigram tokens stored in the
+// search index. Japanese has no spaces between words, so every run of word characters
+// is indexed as overlapping bigrams; the same applies to Latin text, which gives
+// substring matches in both scripts.
+func SearchIndexText(texts ...string) string {
+	var tokens []string
+	for _, text := range texts {
+		for _, run := range searchRuns(normalizeSearchText(text)) {
+			tokens = append(tokens, runTokens(run)...)
+		}
+	}
+	return strings.Join(tokens, " ")
+}
@@ -2,39 +2,137 @@ package books
 
 import (
 	"context"
+	"time"
 
 	"holocron/internal/books/domain"
+	"holocron/internal/calendar"
+	"holocron/internal/cursor"
 )
 
 type ListBooksInput struct {
-	Q      *string
-	Code   *string
-	Limit  *int
-	Offset *int
+	Q             *string
+	Code          *string
+	Romaji        *bool
+	Status        *string
+	Borrower      *string
+	Publisher     *string
+	Author        *string
+	PublishedFrom *string
+	PublishedTo   *string
+	CreatedAfter  *time.Time
+	Tag           *string
+	Category      *string
+	Shelf         *string
+	Sort          *string
+	Order         *string
+	RequesterID   string
+	Cursor        *string
+	IncludeTotal  *bool
+	Facets        []string
+	Limit         *int
+	Offset        *int
 }
 
 type ListBooksOutput struct {
-	Items []domain.BookItem
-	Total int64
+	Items      []domain.BookItem
+	Total      *int64
+	NextCursor *string
+	PrevCursor *string
+	Facets     domain.BookFacets
 }
 
 func ListBooks(
 	ctx context.Context,
 	queries *Queries,
+	index *BookSearchIndex,
+	codec *cursor.Codec,
+	cal calendar.Calendar,
 	input ListBooksInput,
 ) (*ListBooksOutput, error) {
 	keyword := domain.ToSearchKeyword(input.Q)
 	pagination := domain.ToPagination(input.Limit, input.Offset)
 
+	status, err := domain.ToBookStatus(input.Status)
+	if err != nil {
+		return nil, err
+	}
+	publishedFrom, err := domain.ToPublishedDateBound(input.PublishedFrom)
+	if err != nil {
+		return nil, err
+	}
+	publishedTo, err := domain.ToPublishedDateBound(input.PublishedTo)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := domain.ToBookSort(input.Sort, input.Order, keyword)
+	if err != nil {
+		return nil, err
+	}
+	facets, err := domain.ToBookFacets(input.Facets)
+	if err != nil {
+		return nil, err
+	}
+
+	query := domain.BookListQuery{
+		Keyword: keyword,
+		Filter: domain.BookFilter{
+			Code:          domain.ToOptionalString(input.Code),
+			Status:        status,
+			BorrowerID:    domain.ToBorrowerID(input.Borrower, input.RequesterID),
+			Publisher:     domain.ToOptionalString(input.Publisher),
+			Author:        domain.ToOptionalString(input.Author),
+			PublishedFrom: publishedFrom,
+			PublishedTo:   publishedTo,
+			CreatedAfter:  input.CreatedAfter,
+			Tag:           domain.ToOptionalString(input.Tag),
+			CategoryID:    domain.ToOptionalString(input.Category),
+			ShelfLocation: domain.ToOptionalString(input.Shelf),
+		},
+		Sort:   sort,
+		Facets: facets,
+	}
+
+	fingerprint := query.Fingerprint()
+	if input.Cursor != nil && *input.Cursor != "" {
+		var c domain.BookCursor
+		if err := codec.Decode(*input.Cursor, &c); err != nil {
+			return nil, err
+		}
+		if c.Query != fingerprint {
+			return nil, cursor.ErrInvalidCursor
+		}
+		pagination = pagination.WithCursor(c.Position, c.Backward)
+	}
+	if input.IncludeTotal != nil && !*input.IncludeTotal {
+		pagination = pagination.WithoutTotal()
+	}
+
+	// Loans falling due while the library is closed only become overdue
+	// when it opens again.
+	now := cal.OverdueCutoff(time.Now().UTC())
 	sources := []domain.BookListSource{
-		FindByCodeSource(queries, input.Code),
-		SearchBooksSource(queries),
-		ListAllBooksSource(queries),
+		SearchBooksSource(queries, index, input.Romaji != nil && *input.Romaji, now, cal),
+		ListBooksSource(queries, now, cal),
 	}
-	items, total, err := domain.GetBookList(ctx, sources, keyword, pagination)
+	page, err := domain.GetBookList(ctx, sources, query, pagination)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ListBooksOutput{Items: items, Total: total}, nil
+	output := &ListBooksOutput{Items: page.Items, Total: page.Total, Facets: page.Facets}
+	if page.Next != nil {
+		next, err := codec.Encode(domain.BookCursor{Position: *page.Next, Query: fingerprint})
+		if err != nil {
+			return nil, err
+		}
+		output.NextCursor = &next
+	}
+	if page.Previous != nil {
+		prev, err := codec.Encode(domain.BookCursor{Position: *page.Previous, Backward: true, Query: fingerprint})
+		if err != nil {
+			return nil, err
+		}
+		output.PrevCursor = &prev
+	}
+	return output, nil
 }
@@ -4,10 +4,15 @@ package books
 
 import (
 	"context"
+	"database/sql"
+	"reflect"
 	"testing"
 	"time"
 
 	"holocron/internal/books/domain"
+	"holocron/internal/calendar"
+	"holocron/internal/cursor"
+	"holocron/internal/series"
 )
 
 func TestSearchBooksSource_WithKeyword_ReturnsMatchingBooks(t *testing.T) {
@@ -15,11 +20,11 @@ func TestSearchBooksSource_WithKeyword_ReturnsMatchingBooks(t *testing.T) {
 	queries := New(db)
 	ctx := context.Background()
 
-	_, _ = CreateBook(ctx, queries, CreateBookInput{
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{
 		Title:   "Go Programming",
 		Authors: []string{"Author A"},
 	})
-	_, _ = CreateBook(ctx, queries, CreateBookInput{
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{
 		Title:   "Python Programming",
 		Authors: []string{"Author B"},
 	})
@@ -28,8 +33,8 @@ func TestSearchBooksSource_WithKeyword_ReturnsMatchingBooks(t *testing.T) {
 	keyword := domain.ToSearchKeyword(&query)
 	pagination := domain.ToPagination(nil, nil)
 
-	source := SearchBooksSource(queries)
-	items, total, err := source(ctx, keyword, pagination)
+	source := SearchBooksSource(queries, NewBookSearchIndex(db), false, time.Now(), calendar.Calendar{})
+	items, total, err := pageOf(source(ctx, listQuery(keyword), pagination))
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -52,32 +57,32 @@ func TestSearchBooksSource_WithNilKeyword_ReturnsError(t *testing.T) {
 
 	pagination := domain.ToPagination(nil, nil)
 
-	source := SearchBooksSource(queries)
-	_, _, err := source(ctx, nil, pagination)
+	source := SearchBooksSource(queries, NewBookSearchIndex(db), false, time.Now(), calendar.Calendar{})
+	_, _, err := pageOf(source(ctx, listQuery(nil), pagination))
 
 	if err != domain.ErrNotMyResponsibility {
 		t.Errorf("expected ErrNotMyResponsibility, got %v", err)
 	}
 }
 
-func TestListAllBooksSource_ReturnsAllBooks(t *testing.T) {
+func TestListBooksSource_ReturnsAllBooks(t *testing.T) {
 	db := setupTestDB(t)
 	queries := New(db)
 	ctx := context.Background()
 
-	_, _ = CreateBook(ctx, queries, CreateBookInput{
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{
 		Title:   "Book One",
 		Authors: []string{"Author A"},
 	})
-	_, _ = CreateBook(ctx, queries, CreateBookInput{
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{
 		Title:   "Book Two",
 		Authors: []string{"Author B"},
 	})
 
 	pagination := domain.ToPagination(nil, nil)
 
-	source := ListAllBooksSource(queries)
-	items, total, err := source(ctx, nil, pagination)
+	source := ListBooksSource(queries, time.Now(), calendar.Calendar{})
+	items, total, err := pageOf(source(ctx, listQuery(nil), pagination))
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -90,7 +95,7 @@ func TestListAllBooksSource_ReturnsAllBooks(t *testing.T) {
 	}
 }
 
-func TestFindByCodeSource_WithMatchingCode_ReturnsMatchingBook(t *testing.T) {
+func TestListBooksSource_WithCodeFilter_ReturnsMatchingBook(t *testing.T) {
 	db := setupTestDB(t)
 	queries := New(db)
 	ctx := context.Background()
@@ -103,15 +108,17 @@ func TestFindByCodeSource_WithMatchingCode_ReturnsMatchingBook(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to insert book event: %v", err)
 	}
-	_, _ = CreateBook(ctx, queries, CreateBookInput{
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{
 		Title:   "Python Programming",
 		Authors: []string{"Author B"},
 	})
 
 	pagination := domain.ToPagination(nil, nil)
+	query := listQuery(nil)
+	query.Filter.Code = &code
 
-	source := FindByCodeSource(queries, &code)
-	items, total, err := source(ctx, nil, pagination)
+	source := ListBooksSource(queries, time.Now(), calendar.Calendar{})
+	items, total, err := pageOf(source(ctx, query, pagination))
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -127,38 +134,23 @@ func TestFindByCodeSource_WithMatchingCode_ReturnsMatchingBook(t *testing.T) {
 	}
 }
 
-func TestFindByCodeSource_WithNilCode_ReturnsErrNotMyResponsibility(t *testing.T) {
-	db := setupTestDB(t)
-	queries := New(db)
-	ctx := context.Background()
-
-	pagination := domain.ToPagination(nil, nil)
-
-	source := FindByCodeSource(queries, nil)
-	_, _, err := source(ctx, nil, pagination)
-
-	if err != domain.ErrNotMyResponsibility {
-		t.Errorf("expected ErrNotMyResponsibility, got %v", err)
-	}
-}
-
 func TestGetBookList_WithSources_UsesChainOfResponsibility(t *testing.T) {
 	db := setupTestDB(t)
 	queries := New(db)
 	ctx := context.Background()
 
-	_, _ = CreateBook(ctx, queries, CreateBookInput{
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{
 		Title:   "Go Programming",
 		Authors: []string{"Author A"},
 	})
-	_, _ = CreateBook(ctx, queries, CreateBookInput{
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{
 		Title:   "Python Programming",
 		Authors: []string{"Author B"},
 	})
 
 	sources := []domain.BookListSource{
-		SearchBooksSource(queries),
-		ListAllBooksSource(queries),
+		SearchBooksSource(queries, NewBookSearchIndex(db), false, time.Now(), calendar.Calendar{}),
+		ListBooksSource(queries, time.Now(), calendar.Calendar{}),
 	}
 
 	// With keyword: SearchBooksSource handles
@@ -166,7 +158,7 @@ func TestGetBookList_WithSources_UsesChainOfResponsibility(t *testing.T) {
 	keyword := domain.ToSearchKeyword(&query)
 	pagination := domain.ToPagination(nil, nil)
 
-	items, total, err := domain.GetBookList(ctx, sources, keyword, pagination)
+	items, total, err := pageOf(domain.GetBookList(ctx, sources, listQuery(keyword), pagination))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -177,8 +169,8 @@ func TestGetBookList_WithSources_UsesChainOfResponsibility(t *testing.T) {
 		t.Errorf("expected total 1, got %d", total)
 	}
 
-	// Without keyword: ListAllBooksSource handles (fallback)
-	items, total, err = domain.GetBookList(ctx, sources, nil, pagination)
+	// Without keyword: ListBooksSource handles (fallback)
+	items, total, err = pageOf(domain.GetBookList(ctx, sources, listQuery(nil), pagination))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -195,11 +187,11 @@ func TestGetBookList_WithDeletedBook_ExcludesDeletedBook(t *testing.T) {
 	queries := New(db)
 	ctx := context.Background()
 
-	book1, _ := CreateBook(ctx, queries, CreateBookInput{
+	book1, _ := CreateBook(ctx, db, queries, series.New(db), CreateBookInput{
 		Title:   "Book One",
 		Authors: []string{"Author A"},
 	})
-	_, _ = CreateBook(ctx, queries, CreateBookInput{
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{
 		Title:   "Book Two",
 		Authors: []string{"Author B"},
 	})
@@ -213,12 +205,12 @@ func TestGetBookList_WithDeletedBook_ExcludesDeletedBook(t *testing.T) {
 	}
 
 	sources := []domain.BookListSource{
-		SearchBooksSource(queries),
-		ListAllBooksSource(queries),
+		SearchBooksSource(queries, NewBookSearchIndex(db), false, time.Now(), calendar.Calendar{}),
+		ListBooksSource(queries, time.Now(), calendar.Calendar{}),
 	}
 	pagination := domain.ToPagination(nil, nil)
 
-	items, total, err := domain.GetBookList(ctx, sources, nil, pagination)
+	items, total, err := pageOf(domain.GetBookList(ctx, sources, listQuery(nil), pagination))
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -232,3 +224,1063 @@ func TestGetBookList_WithDeletedBook_ExcludesDeletedBook(t *testing.T) {
 		t.Errorf("expected title 'Book Two', got %s", items[0].Title)
 	}
 }
+
+func pageOf(page domain.BookPage, err error) ([]domain.BookItem, int64, error) {
+	var total int64
+	if page.Total != nil {
+		total = *page.Total
+	}
+	return page.Items, total, err
+}
+
+func listQuery(keyword *domain.SearchKeyword) domain.BookListQuery {
+	sort, _ := domain.ToBookSort(nil, nil, keyword)
+	return domain.BookListQuery{Keyword: keyword, Sort: sort}
+}
+
+func runSearch(t *testing.T, db *sql.DB, q string) ([]domain.BookItem, int64) {
+	t.Helper()
+	source := SearchBooksSource(New(db), NewBookSearchIndex(db), false, time.Now(), calendar.Calendar{})
+	items, total, err := pageOf(source(context.Background(), listQuery(domain.ToSearchKeyword(&q)), domain.ToPagination(nil, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return items, total
+}
+
+func TestSearchBooksSource_WithTwoCharacterJapaneseKeyword_ReturnsMatchingBooks(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "吾輩は猫である", Authors: []string{"夏目漱石"}})
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "羅生門", Authors: []string{"芥川龍之介"}})
+
+	// When searching for the family name of an author written without spaces
+	items, total := runSearch(t, db, "夏目")
+
+	// Then the book is found from the bigrams of the full name
+	if total != 1 || len(items) != 1 || items[0].Title != "吾輩は猫である" {
+		t.Errorf("expected 吾輩は猫である, got %v (total %d)", items, total)
+	}
+}
+
+func TestSearchBooksSource_WithSingleCharacterKeyword_ReturnsMatchingBooks(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "吾輩は猫である", Authors: []string{"夏目漱石"}})
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "黒猫", Authors: []string{"ポー"}})
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "羅生門", Authors: []string{"芥川龍之介"}})
+
+	_, total := runSearch(t, db, "猫")
+
+	if total != 2 {
+		t.Errorf("expected 2 books, got %d", total)
+	}
+}
+
+func TestSearchBooksSource_WithMultipleTerms_ReturnsBooksMatchingAllTerms(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "こころ", Authors: []string{"夏目漱石"}})
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "坊っちゃん", Authors: []string{"夏目漱石"}})
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "こころの処方箋", Authors: []string{"河合隼雄"}})
+
+	items, total := runSearch(t, db, "夏目　こころ")
+
+	if total != 1 || len(items) != 1 || items[0].Title != "こころ" {
+		t.Errorf("expected こころ, got %v (total %d)", items, total)
+	}
+}
+
+func TestSearchBooksSource_WithQuotedPhrase_RequiresAdjacentWords(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "Go Programming", Authors: []string{"Author A"}})
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "Programming in Go", Authors: []string{"Author B"}})
+
+	_, total := runSearch(t, db, "go programming")
+	if total != 2 {
+		t.Errorf("expected both books for separate terms, got %d", total)
+	}
+
+	items, total := runSearch(t, db, `"go programming"`)
+	if total != 1 || len(items) != 1 || items[0].Title != "Go Programming" {
+		t.Errorf("expected Go Programming, got %v (total %d)", items, total)
+	}
+}
+
+func TestSearchBooksSource_WithPublisherKeyword_ReturnsMatchingBooks(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	publisher := "オライリー・ジャパン"
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "Go言語によるWebアプリケーション開発", Authors: []string{"Mat Ryer"}, Publisher: &publisher})
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "プロを目指す人のためのTypeScript入門", Authors: []string{"鈴木僚太"}})
+
+	items, total := runSearch(t, db, "オライリー")
+
+	if total != 1 || len(items) != 1 || items[0].Publisher == nil || *items[0].Publisher != publisher {
+		t.Errorf("expected the book published by %s, got %v (total %d)", publisher, items, total)
+	}
+}
+
+func TestSearchBooksSource_WithJSONPunctuation_DoesNotMatchAuthors(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "Book", Authors: []string{"Author A", "Author B"}})
+
+	_, total := runSearch(t, db, `","`)
+
+	if total != 0 {
+		t.Errorf("expected no match, got %d", total)
+	}
+}
+
+func TestSearchBooksSource_RanksTitleMatchesAboveAuthorMatches(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "宮沢賢治の世界", Authors: []string{"評論家"}})
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "図書館戦争", Authors: []string{"有川浩"}})
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "銀河鉄道の夜", Authors: []string{"宮沢賢治"}})
+
+	items, total := runSearch(t, db, "賢治")
+
+	if total != 2 || len(items) != 2 {
+		t.Fatalf("expected 2 books, got %d", total)
+	}
+	if items[0].Title != "宮沢賢治の世界" {
+		t.Errorf("expected the title match first, got %s", items[0].Title)
+	}
+}
+
+func TestSearchBooksSource_WithMatch_ReturnsHighlights(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "吾輩は猫である", Authors: []string{"夏目漱石", "編集部"}})
+
+	items, _ := runSearch(t, db, "猫")
+
+	if len(items) != 1 || items[0].Highlights == nil {
+		t.Fatalf("expected highlights, got %v", items)
+	}
+	highlights := items[0].Highlights
+	if highlights.Title == nil || *highlights.Title != "吾輩は<mark>猫</mark>である" {
+		t.Errorf("unexpected title highlight: %v", highlights.Title)
+	}
+	if highlights.Authors != nil || highlights.Publisher != nil {
+		t.Errorf("expected only the title to be highlighted, got %+v", highlights)
+	}
+}
+
+func TestSearchBooksSource_WithDifferentlyWrittenKeyword_ReturnsNormalizedMatches(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "ハリー・ポッターと賢者の石", Authors: []string{"J.K.ローリング"}})
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "ABCで学ぶコンピューター入門", Authors: []string{"著者"}})
+
+	cases := map[string]string{
+		"はりー":        "ハリー・ポッターと賢者の石",
+		"ハリーポッター":    "ハリー・ポッターと賢者の石",
+		"ﾎﾟｯﾀｰ":      "ハリー・ポッターと賢者の石",
+		"ＡＢＣ":        "ABCで学ぶコンピューター入門",
+		"abc コンピュータ": "ABCで学ぶコンピューター入門",
+	}
+	for q, expected := range cases {
+		items, total := runSearch(t, db, q)
+		if total != 1 || len(items) != 1 || items[0].Title != expected {
+			t.Errorf("%s: expected %s, got %v (total %d)", q, expected, items, total)
+		}
+	}
+}
+
+func TestSearchBooksSource_WithRomaji_MatchesKanaReading(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, _ = CreateBook(ctx, db, queries, series.New(db), CreateBookInput{Title: "ポケモン図鑑", Authors: []string{"編集部"}})
+
+	q := "pokemon"
+	keyword := domain.ToSearchKeyword(&q)
+	pagination := domain.ToPagination(nil, nil)
+
+	// When romaji is not enabled
+	_, total, err := pageOf(SearchBooksSource(queries, NewBookSearchIndex(db), false, time.Now(), calendar.Calendar{})(ctx, listQuery(keyword), pagination))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Then the romaji is only matched as written
+	if total != 0 {
+		t.Errorf("expected no match without romaji, got %d", total)
+	}
+
+	// When romaji is enabled
+	items, total, err := pageOf(SearchBooksSource(queries, NewBookSearchIndex(db), true, time.Now(), calendar.Calendar{})(ctx, listQuery(keyword), pagination))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Then the kana reading matches and is highlighted
+	if total != 1 || len(items) != 1 {
+		t.Fatalf("expected 1 match with romaji, got %d", total)
+	}
+	if items[0].Highlights == nil || items[0].Highlights.Title == nil || *items[0].Highlights.Title != "<mark>ポケモン</mark>図鑑" {
+		t.Errorf("unexpected highlights: %+v", items[0].Highlights)
+	}
+}
+
+func insertTestBook(t *testing.T, db *sql.DB, bookID, title, publisher, publishedDate, occurredAt string, authors string) {
+	t.Helper()
+	_, err := db.Exec(`
+		INSERT INTO book_events (event_id, book_id, event_type, title, authors, publisher, published_date, occurred_at)
+		VALUES (?, ?, 'created', ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?)
+	`, "evt-"+bookID, bookID, title, authors, publisher, publishedDate, occurredAt)
+	if err != nil {
+		t.Fatalf("failed to insert book event: %v", err)
+	}
+}
+
+func insertTestBorrowing(t *testing.T, db *sql.DB, lendingID, bookID, borrowerID, borrowedAt, dueDate string) {
+	t.Helper()
+	_, err := db.Exec(`
+		INSERT OR IGNORE INTO user_events (event_id, user_id, event_type, name, occurred_at)
+		VALUES (?, ?, 'created', ?, '2024-01-01T00:00:00Z')
+	`, "user-evt-"+borrowerID, borrowerID, "User "+borrowerID)
+	if err != nil {
+		t.Fatalf("failed to insert user event: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO lending_events (event_id, lending_id, book_id, borrower_id, event_type, due_date, occurred_at)
+		VALUES (?, ?, ?, ?, 'borrowed', ?, ?)
+	`, "evt-"+lendingID, lendingID, bookID, borrowerID, dueDate, borrowedAt)
+	if err != nil {
+		t.Fatalf("failed to insert lending event: %v", err)
+	}
+}
+
+func insertTestReturn(t *testing.T, db *sql.DB, lendingID, bookID, borrowerID, returnedAt string) {
+	t.Helper()
+	_, err := db.Exec(`
+		INSERT INTO lending_events (event_id, lending_id, book_id, borrower_id, event_type, occurred_at)
+		VALUES (?, ?, ?, ?, 'returned', ?)
+	`, "evt-return-"+lendingID, lendingID, bookID, borrowerID, returnedAt)
+	if err != nil {
+		t.Fatalf("failed to insert lending event: %v", err)
+	}
+}
+
+func bookIDs(items []domain.BookItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestListBooksSource_WithStatusFilter_ReturnsBooksInThatState(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	insertTestBook(t, db, "book-available", "Available", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-borrowed", "Borrowed", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-overdue", "Overdue", "", "", "2024-01-03T00:00:00Z", `["A"]`)
+	insertTestBorrowing(t, db, "lending-1", "book-borrowed", "user-1", "2024-02-25T00:00:00Z", "2024-03-03T00:00:00Z")
+	insertTestBorrowing(t, db, "lending-2", "book-overdue", "user-1", "2024-02-01T00:00:00Z", "2024-02-08T00:00:00Z")
+
+	cases := map[domain.BookStatus][]string{
+		domain.BookStatusAvailable: {"book-available"},
+		domain.BookStatusBorrowed:  {"book-overdue", "book-borrowed"},
+		domain.BookStatusOverdue:   {"book-overdue"},
+	}
+	for status, expected := range cases {
+		query := listQuery(nil)
+		query.Filter.Status = &status
+
+		// When listing books with the status filter
+		items, total, err := pageOf(ListBooksSource(queries, now, calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Then only books in that state are returned
+		if !equalIDs(bookIDs(items), expected) || total != int64(len(expected)) {
+			t.Errorf("%s: expected %v, got %v (total %d)", status, expected, bookIDs(items), total)
+		}
+	}
+}
+
+func TestListBooksSource_WithOverdueFilter_UsesExtendedDueDate(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	insertTestBook(t, db, "book-1", "Extended", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBorrowing(t, db, "lending-1", "book-1", "user-1", "2024-02-01T00:00:00Z", "2024-02-08T00:00:00Z")
+	_, err := db.Exec(`
+		INSERT INTO lending_events (event_id, lending_id, book_id, borrower_id, event_type, due_date, occurred_at)
+		VALUES ('evt-extended', 'lending-1', 'book-1', 'user-1', 'due_date_extended', '2024-03-08T00:00:00Z', '2024-02-07T00:00:00Z')
+	`)
+	if err != nil {
+		t.Fatalf("failed to insert lending event: %v", err)
+	}
+
+	status := domain.BookStatusOverdue
+	query := listQuery(nil)
+	query.Filter.Status = &status
+
+	// When the due date has been extended past now
+	_, total, err := pageOf(ListBooksSource(queries, now, calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then the book is not overdue
+	if total != 0 {
+		t.Errorf("expected no overdue book, got %d", total)
+	}
+}
+
+func TestListBooksSource_WithOverdueFilter_UsesLegacyDueDateForLoansWithoutDueDate(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	insertTestBook(t, db, "book-old", "Old loan", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-recent", "Recent loan", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBorrowing(t, db, "lending-old", "book-old", "user-1", "2024-02-01T00:00:00Z", "")
+	insertTestBorrowing(t, db, "lending-recent", "book-recent", "user-1", "2024-02-25T00:00:00Z", "")
+	_, err := db.Exec(`UPDATE lending_events SET due_date = NULL`)
+	if err != nil {
+		t.Fatalf("failed to clear due dates: %v", err)
+	}
+
+	status := domain.BookStatusOverdue
+	query := listQuery(nil)
+	query.Filter.Status = &status
+	query.Facets = []domain.BookFacet{domain.BookFacetStatus}
+
+	// When listing the overdue books recorded without a due date
+	page, err := ListBooksSource(queries, now, calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then the loan past its legacy due date is overdue, in the list and in the facet
+	if ids := bookIDs(page.Items); !equalIDs(ids, []string{"book-old"}) {
+		t.Errorf("expected [book-old], got %v", ids)
+	}
+	overdue := int64(0)
+	for _, count := range page.Facets[domain.BookFacetStatus] {
+		if count.Value == string(domain.BookStatusOverdue) {
+			overdue = count.Count
+		}
+	}
+	if overdue != 1 {
+		t.Errorf("expected 1 overdue book in the status facet, got %v", page.Facets[domain.BookFacetStatus])
+	}
+}
+
+func TestListBooksSource_WithBorrowerFilter_ReturnsBooksBorrowedByThatUser(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestBook(t, db, "book-1", "Mine", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Theirs", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-3", "Returned", "", "", "2024-01-03T00:00:00Z", `["A"]`)
+	insertTestBorrowing(t, db, "lending-1", "book-1", "user-1", "2024-02-01T00:00:00Z", "2024-02-08T00:00:00Z")
+	insertTestBorrowing(t, db, "lending-2", "book-2", "user-2", "2024-02-01T00:00:00Z", "2024-02-08T00:00:00Z")
+	insertTestBorrowing(t, db, "lending-3", "book-3", "user-1", "2024-01-10T00:00:00Z", "2024-01-17T00:00:00Z")
+	insertTestReturn(t, db, "lending-3", "book-3", "user-1", "2024-01-12T00:00:00Z")
+
+	borrowerID := "user-1"
+	query := listQuery(nil)
+	query.Filter.BorrowerID = &borrowerID
+
+	// When listing books borrowed by user-1
+	items, total, err := pageOf(ListBooksSource(queries, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then only the book user-1 currently borrows is returned
+	if !equalIDs(bookIDs(items), []string{"book-1"}) || total != 1 {
+		t.Errorf("expected [book-1], got %v (total %d)", bookIDs(items), total)
+	}
+}
+
+func TestListBooksSource_WithPublisherAndAuthorFilters_MatchesExactValues(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestBook(t, db, "book-1", "One", "オライリー・ジャパン", "", "2024-01-01T00:00:00Z", `["Dustin Boswell", "Trevor Foucher"]`)
+	insertTestBook(t, db, "book-2", "Two", "オライリー・ジャパン", "", "2024-01-02T00:00:00Z", `["Robert C. Martin"]`)
+	insertTestBook(t, db, "book-3", "Three", "技術評論社", "", "2024-01-03T00:00:00Z", `["Trevor Foucher"]`)
+
+	publisher := "オライリー・ジャパン"
+	author := "Trevor Foucher"
+	partialAuthor := "Trevor"
+	cases := []struct {
+		name     string
+		filter   domain.BookFilter
+		expected []string
+	}{
+		{"publisher", domain.BookFilter{Publisher: &publisher}, []string{"book-2", "book-1"}},
+		{"author", domain.BookFilter{Author: &author}, []string{"book-3", "book-1"}},
+		{"publisher and author", domain.BookFilter{Publisher: &publisher, Author: &author}, []string{"book-1"}},
+		{"partial author", domain.BookFilter{Author: &partialAuthor}, []string{}},
+	}
+	for _, c := range cases {
+		query := listQuery(nil)
+		query.Filter = c.filter
+
+		items, total, err := pageOf(ListBooksSource(queries, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !equalIDs(bookIDs(items), c.expected) || total != int64(len(c.expected)) {
+			t.Errorf("%s: expected %v, got %v (total %d)", c.name, c.expected, bookIDs(items), total)
+		}
+	}
+}
+
+func TestListBooksSource_WithPublishedDateRange_ComparesAtBoundPrecision(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestBook(t, db, "book-2019", "2019", "", "2019-12-31", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2020", "2020", "", "2020", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2020-06", "2020-06", "", "2020-06-15", "2024-01-03T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2021", "2021", "", "2021-01", "2024-01-04T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-unknown", "Unknown", "", "", "2024-01-05T00:00:00Z", `["A"]`)
+
+	from, to := "2020", "2020"
+	query := listQuery(nil)
+	query.Filter.PublishedFrom = &from
+	query.Filter.PublishedTo = &to
+
+	// When listing books published in 2020
+	items, total, err := pageOf(ListBooksSource(queries, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then books dated anywhere in 2020 are returned and books without a date are not
+	expected := []string{"book-2020-06", "book-2020"}
+	if !equalIDs(bookIDs(items), expected) || total != 2 {
+		t.Errorf("expected %v, got %v (total %d)", expected, bookIDs(items), total)
+	}
+}
+
+func TestListBooksSource_WithCreatedAfter_ReturnsNewerBooks(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestBook(t, db, "book-old", "Old", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-new", "New", "", "", "2024-02-01T00:00:00Z", `["A"]`)
+
+	createdAfter := time.Date(2024, 1, 15, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	query := listQuery(nil)
+	query.Filter.CreatedAfter = &createdAfter
+
+	items, total, err := pageOf(ListBooksSource(queries, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !equalIDs(bookIDs(items), []string{"book-new"}) || total != 1 {
+		t.Errorf("expected [book-new], got %v (total %d)", bookIDs(items), total)
+	}
+}
+
+func TestListBooksSource_WithSort_OrdersBooks(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestBook(t, db, "book-b", "banana", "", "2020-01-01", "2024-01-01T00:00:00Z", `["Carol"]`)
+	insertTestBook(t, db, "book-a", "Apple", "", "", "2024-01-02T00:00:00Z", `["bob"]`)
+	insertTestBook(t, db, "book-c", "Cherry", "", "2021", "2024-01-03T00:00:00Z", `["Alice"]`)
+	insertTestBorrowing(t, db, "lending-1", "book-a", "user-1", "2024-02-01T00:00:00Z", "2024-02-08T00:00:00Z")
+	insertTestReturn(t, db, "lending-1", "book-a", "user-1", "2024-02-02T00:00:00Z")
+	insertTestBorrowing(t, db, "lending-2", "book-a", "user-1", "2024-02-03T00:00:00Z", "2024-02-10T00:00:00Z")
+	insertTestBorrowing(t, db, "lending-3", "book-c", "user-1", "2024-02-01T00:00:00Z", "2024-02-08T00:00:00Z")
+
+	desc, asc := "desc", "asc"
+	cases := []struct {
+		sort     string
+		order    *string
+		expected []string
+	}{
+		{"title", nil, []string{"book-a", "book-b", "book-c"}},
+		{"title", &desc, []string{"book-c", "book-b", "book-a"}},
+		{"author", nil, []string{"book-c", "book-a", "book-b"}},
+		{"created_at", nil, []string{"book-c", "book-a", "book-b"}},
+		{"published_date", nil, []string{"book-c", "book-b", "book-a"}},
+		{"published_date", &asc, []string{"book-b", "book-c", "book-a"}},
+		{"popularity", nil, []string{"book-a", "book-c", "book-b"}},
+	}
+	for _, c := range cases {
+		sort, err := domain.ToBookSort(&c.sort, c.order, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		query := domain.BookListQuery{Sort: sort}
+
+		items, _, err := pageOf(ListBooksSource(queries, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !equalIDs(bookIDs(items), c.expected) {
+			t.Errorf("%s %+v: expected %v, got %v", c.sort, sort, c.expected, bookIDs(items))
+		}
+	}
+}
+
+func TestSearchBooksSource_WithFilterAndSort_CombinesWithKeyword(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestBook(t, db, "book-1", "Go Programming", "Gihyo", "2016", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Go in Action", "Manning", "2015", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-3", "Advanced Go", "Gihyo", "2023", "2024-01-03T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-4", "Python Programming", "Gihyo", "2020", "2024-01-04T00:00:00Z", `["A"]`)
+	insertTestBorrowing(t, db, "lending-1", "book-3", "user-1", "2024-02-01T00:00:00Z", "2024-02-08T00:00:00Z")
+
+	q := "Go"
+	publisher := "Gihyo"
+	sortKey := "published_date"
+	sort, _ := domain.ToBookSort(&sortKey, nil, domain.ToSearchKeyword(&q))
+	query := domain.BookListQuery{
+		Keyword: domain.ToSearchKeyword(&q),
+		Filter:  domain.BookFilter{Publisher: &publisher},
+		Sort:    sort,
+	}
+
+	// When searching with a publisher filter and a sort
+	items, total, err := pageOf(SearchBooksSource(queries, NewBookSearchIndex(db), false, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then only matching books of that publisher are returned in the requested order
+	if !equalIDs(bookIDs(items), []string{"book-3", "book-1"}) || total != 2 {
+		t.Errorf("expected [book-3 book-1], got %v (total %d)", bookIDs(items), total)
+	}
+
+	// When the status filter is added as well
+	status := domain.BookStatusAvailable
+	query.Filter.Status = &status
+	items, total, err = pageOf(SearchBooksSource(queries, NewBookSearchIndex(db), false, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then the borrowed book is left out
+	if !equalIDs(bookIDs(items), []string{"book-1"}) || total != 1 {
+		t.Errorf("expected [book-1], got %v (total %d)", bookIDs(items), total)
+	}
+}
+
+func TestListBooks_WithBorrowerMe_ReturnsBooksBorrowedByRequester(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestBook(t, db, "book-1", "Mine", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Theirs", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBorrowing(t, db, "lending-1", "book-1", "user-1", "2024-02-01T00:00:00Z", "2099-02-08T00:00:00Z")
+	insertTestBorrowing(t, db, "lending-2", "book-2", "user-2", "2024-02-01T00:00:00Z", "2099-02-08T00:00:00Z")
+
+	borrower := "me"
+	output, err := ListBooks(ctx, queries, NewBookSearchIndex(db), cursor.NewCodec("secret"), calendar.Calendar{}, ListBooksInput{
+		Borrower:    &borrower,
+		RequesterID: "user-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !equalIDs(bookIDs(output.Items), []string{"book-1"}) || *output.Total != 1 {
+		t.Errorf("expected [book-1], got %v (total %d)", bookIDs(output.Items), *output.Total)
+	}
+}
+
+func TestListBooks_WithInvalidStatus_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	status := "lost"
+	_, err := ListBooks(ctx, queries, NewBookSearchIndex(db), cursor.NewCodec("secret"), calendar.Calendar{}, ListBooksInput{Status: &status})
+	if err != domain.ErrInvalidBookStatus {
+		t.Errorf("expected ErrInvalidBookStatus, got %v", err)
+	}
+}
+
+func TestListBooks_WithOverdueStatusWhileClosed_ExcludesLoansDueDuringClosure(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+	today := time.Now().In(calendar.Location)
+	cal := calendar.New(nil, false, []calendar.Closure{{From: today.AddDate(0, 0, -2), To: today.AddDate(0, 0, 2)}})
+
+	insertTestBook(t, db, "book-1", "Due during closure", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Due before closure", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBorrowing(t, db, "lending-1", "book-1", "user-1", "2024-02-01T00:00:00Z", today.AddDate(0, 0, -1).UTC().Format(time.RFC3339))
+	insertTestBorrowing(t, db, "lending-2", "book-2", "user-1", "2024-02-01T00:00:00Z", today.AddDate(0, 0, -4).UTC().Format(time.RFC3339))
+
+	// When listing overdue books while the library is closed
+	status := "overdue"
+	output, err := ListBooks(ctx, queries, NewBookSearchIndex(db), cursor.NewCodec("secret"), cal, ListBooksInput{Status: &status})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then the book due during the closure is not overdue yet
+	if !equalIDs(bookIDs(output.Items), []string{"book-2"}) {
+		t.Errorf("expected [book-2], got %v", bookIDs(output.Items))
+	}
+}
+
+func walkPages(t *testing.T, db *sql.DB, codec *cursor.Codec, input ListBooksInput) ([][]string, []ListBooksOutput) {
+	t.Helper()
+	var pages [][]string
+	var outputs []ListBooksOutput
+	for i := 0; i < 20; i++ {
+		output, err := ListBooks(context.Background(), New(db), NewBookSearchIndex(db), codec, calendar.Calendar{}, input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		pages = append(pages, bookIDs(output.Items))
+		outputs = append(outputs, *output)
+		if output.NextCursor == nil {
+			return pages, outputs
+		}
+		input.Cursor = output.NextCursor
+		input.Offset = nil
+	}
+	t.Fatal("pagination did not end")
+	return nil, nil
+}
+
+func TestListBooks_WithCursors_PagesThroughEveryBookOnce(t *testing.T) {
+	db := setupTestDB(t)
+	codec := cursor.NewCodec("secret")
+
+	insertTestBook(t, db, "book-1", "e", "", "2020", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "d", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-3", "c", "", "2021", "2024-01-03T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-4", "b", "", "", "2024-01-04T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-5", "a", "", "2020", "2024-01-05T00:00:00Z", `["A"]`)
+	insertTestBorrowing(t, db, "lending-1", "book-2", "user-1", "2024-02-01T00:00:00Z", "2099-01-01T00:00:00Z")
+
+	limit := 2
+	cases := map[string][]string{
+		"":               {"book-5", "book-4", "book-3", "book-2", "book-1"},
+		"title":          {"book-5", "book-4", "book-3", "book-2", "book-1"},
+		"published_date": {"book-3", "book-5", "book-1", "book-4", "book-2"},
+		"popularity":     {"book-2", "book-5", "book-4", "book-3", "book-1"},
+	}
+	for sortKey, expected := range cases {
+		input := ListBooksInput{Limit: &limit}
+		if sortKey != "" {
+			input.Sort = &sortKey
+		}
+
+		// When following next cursors to the end
+		pages, outputs := walkPages(t, db, codec, input)
+
+		// Then every book appears once, in order
+		var all []string
+		for _, page := range pages {
+			all = append(all, page...)
+		}
+		if !equalIDs(all, expected) || len(pages) != 3 {
+			t.Errorf("%s: expected %v in 3 pages, got %v", sortKey, expected, pages)
+		}
+		if outputs[0].PrevCursor != nil {
+			t.Errorf("%s: expected no previous cursor on the first page", sortKey)
+		}
+
+		// When following previous cursors back from the last page
+		input.Cursor = outputs[len(outputs)-1].PrevCursor
+		for i := len(pages) - 2; i >= 0; i-- {
+			output, err := ListBooks(context.Background(), New(db), NewBookSearchIndex(db), codec, calendar.Calendar{}, input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// Then the same pages are returned in reverse
+			if !equalIDs(bookIDs(output.Items), pages[i]) {
+				t.Errorf("%s: expected page %v, got %v", sortKey, pages[i], bookIDs(output.Items))
+			}
+			if (output.PrevCursor == nil) != (i == 0) {
+				t.Errorf("%s: unexpected previous cursor on page %d", sortKey, i)
+			}
+			input.Cursor = output.PrevCursor
+		}
+	}
+}
+
+func TestListBooks_WithCursor_DoesNotShiftWhenBooksAreAdded(t *testing.T) {
+	db := setupTestDB(t)
+	codec := cursor.NewCodec("secret")
+
+	insertTestBook(t, db, "book-1", "One", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Two", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-3", "Three", "", "", "2024-01-03T00:00:00Z", `["A"]`)
+
+	limit := 2
+	first, err := ListBooks(context.Background(), New(db), NewBookSearchIndex(db), codec, calendar.Calendar{}, ListBooksInput{Limit: &limit})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// When a book is added before the next page is requested
+	insertTestBook(t, db, "book-4", "Four", "", "", "2024-01-04T00:00:00Z", `["A"]`)
+	second, err := ListBooks(context.Background(), New(db), NewBookSearchIndex(db), codec, calendar.Calendar{}, ListBooksInput{
+		Limit:  &limit,
+		Cursor: first.NextCursor,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then the next page continues after the last book seen
+	if !equalIDs(bookIDs(first.Items), []string{"book-3", "book-2"}) || !equalIDs(bookIDs(second.Items), []string{"book-1"}) {
+		t.Errorf("unexpected pages %v, %v", bookIDs(first.Items), bookIDs(second.Items))
+	}
+}
+
+func TestListBooks_WithSearchCursor_PagesByRelevance(t *testing.T) {
+	db := setupTestDB(t)
+	codec := cursor.NewCodec("secret")
+
+	insertTestBook(t, db, "book-1", "Go", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Learning Go", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-3", "Python", "", "", "2024-01-03T00:00:00Z", `["Go Team"]`)
+	insertTestBook(t, db, "book-4", "Rust", "", "", "2024-01-04T00:00:00Z", `["A"]`)
+
+	q := "go"
+	limit := 1
+	pages, _ := walkPages(t, db, codec, ListBooksInput{Q: &q, Limit: &limit})
+
+	var all []string
+	for _, page := range pages {
+		all = append(all, page...)
+	}
+	if len(all) != 3 || all[2] != "book-3" {
+		t.Errorf("expected the author match last among 3 books, got %v", all)
+	}
+}
+
+func TestListBooks_WithoutTotal_DoesNotCount(t *testing.T) {
+	db := setupTestDB(t)
+	insertTestBook(t, db, "book-1", "One", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+
+	includeTotal := false
+	output, err := ListBooks(context.Background(), New(db), NewBookSearchIndex(db), cursor.NewCodec("secret"), calendar.Calendar{}, ListBooksInput{
+		IncludeTotal: &includeTotal,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Total != nil || len(output.Items) != 1 {
+		t.Errorf("expected 1 item without total, got %v (total %v)", bookIDs(output.Items), output.Total)
+	}
+}
+
+func TestListBooks_WithCursorFromOtherQuery_ReturnsErrInvalidCursor(t *testing.T) {
+	db := setupTestDB(t)
+	codec := cursor.NewCodec("secret")
+	insertTestBook(t, db, "book-1", "One", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Two", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+
+	limit := 1
+	first, err := ListBooks(context.Background(), New(db), NewBookSearchIndex(db), codec, calendar.Calendar{}, ListBooksInput{Limit: &limit})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sortKey := "title"
+	_, err = ListBooks(context.Background(), New(db), NewBookSearchIndex(db), codec, calendar.Calendar{}, ListBooksInput{
+		Limit:  &limit,
+		Sort:   &sortKey,
+		Cursor: first.NextCursor,
+	})
+	if err != cursor.ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestListBooksSource_WithFacets_CountsFilteredBooks(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestBook(t, db, "book-1", "One", "Gihyo", "2016-05", "2024-01-01T00:00:00Z", `["A", "B"]`)
+	insertTestBook(t, db, "book-2", "Two", "Gihyo", "2019", "2024-01-02T00:00:00Z", `["A", "A"]`)
+	insertTestBook(t, db, "book-3", "Three", "Manning", "2021-01-01", "2024-01-03T00:00:00Z", `["B"]`)
+	insertTestBook(t, db, "book-4", "Four", "", "", "2024-01-04T00:00:00Z", `["C"]`)
+	insertTestBorrowing(t, db, "lending-1", "book-1", "user-1", "2024-02-01T00:00:00Z", "2024-02-08T00:00:00Z")
+	insertTestBorrowing(t, db, "lending-2", "book-3", "user-1", "2024-02-01T00:00:00Z", "2099-02-08T00:00:00Z")
+
+	query := listQuery(nil)
+	query.Facets = []domain.BookFacet{domain.BookFacetStatus, domain.BookFacetPublisher, domain.BookFacetAuthor, domain.BookFacetDecade}
+
+	// When facets are requested with a one-book page
+	limit := 1
+	page, err := ListBooksSource(queries, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(&limit, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then they count the whole result, most frequent first, each book once per value
+	expected := domain.BookFacets{
+		domain.BookFacetStatus:    {{Value: "available", Count: 2}, {Value: "borrowed", Count: 2}, {Value: "overdue", Count: 1}},
+		domain.BookFacetPublisher: {{Value: "Gihyo", Count: 2}, {Value: "Manning", Count: 1}},
+		domain.BookFacetAuthor:    {{Value: "A", Count: 2}, {Value: "B", Count: 2}, {Value: "C", Count: 1}},
+		domain.BookFacetDecade:    {{Value: "2010", Count: 2}, {Value: "2020", Count: 1}},
+	}
+	if !reflect.DeepEqual(page.Facets, expected) {
+		t.Errorf("expected %v, got %v", expected, page.Facets)
+	}
+
+	// When a filter is set
+	publisher := "Gihyo"
+	query.Filter.Publisher = &publisher
+	query.Facets = []domain.BookFacet{domain.BookFacetDecade, domain.BookFacetStatus}
+	page, err = ListBooksSource(queries, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then only the filtered books and the requested facets are counted
+	expected = domain.BookFacets{
+		domain.BookFacetStatus: {{Value: "available", Count: 1}, {Value: "borrowed", Count: 1}, {Value: "overdue", Count: 1}},
+		domain.BookFacetDecade: {{Value: "2010", Count: 2}},
+	}
+	if !reflect.DeepEqual(page.Facets, expected) {
+		t.Errorf("expected %v, got %v", expected, page.Facets)
+	}
+}
+
+func TestSearchBooksSource_WithFacets_CountsMatchingBooks(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestBook(t, db, "book-1", "Go Programming", "Gihyo", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Go in Action", "Manning", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-3", "Python Programming", "Gihyo", "", "2024-01-03T00:00:00Z", `["A"]`)
+
+	q := "Go"
+	query := listQuery(domain.ToSearchKeyword(&q))
+	query.Facets = []domain.BookFacet{domain.BookFacetPublisher, domain.BookFacetDecade}
+
+	// When searching with facets
+	page, err := SearchBooksSource(queries, NewBookSearchIndex(db), false, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then only matching books are counted, and a facet without values is still present
+	expected := domain.BookFacets{
+		domain.BookFacetPublisher: {{Value: "Gihyo", Count: 1}, {Value: "Manning", Count: 1}},
+		domain.BookFacetDecade:    {},
+	}
+	if !reflect.DeepEqual(page.Facets, expected) {
+		t.Errorf("expected %v, got %v", expected, page.Facets)
+	}
+}
+
+func TestListBooks_WithFacets_KeepsCursorsValid(t *testing.T) {
+	db := setupTestDB(t)
+	codec := cursor.NewCodec("secret")
+	insertTestBook(t, db, "book-1", "One", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Two", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+
+	limit := 1
+	first, err := ListBooks(context.Background(), New(db), NewBookSearchIndex(db), codec, calendar.Calendar{}, ListBooksInput{Limit: &limit})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// When the next page asks for facets as well
+	second, err := ListBooks(context.Background(), New(db), NewBookSearchIndex(db), codec, calendar.Calendar{}, ListBooksInput{
+		Limit:  &limit,
+		Cursor: first.NextCursor,
+		Facets: []string{"author"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then the cursor still applies and the facets count the whole list
+	if !equalIDs(bookIDs(second.Items), []string{"book-1"}) {
+		t.Errorf("expected [book-1], got %v", bookIDs(second.Items))
+	}
+	if len(second.Facets[domain.BookFacetAuthor]) != 1 || second.Facets[domain.BookFacetAuthor][0].Count != 2 {
+		t.Errorf("expected author A counted twice, got %v", second.Facets)
+	}
+}
+
+func insertTestClassification(t *testing.T, db *sql.DB, eventID, bookID, column, value, occurredAt string) {
+	t.Helper()
+	eventType := map[string]string{"tags": "tags_changed", "category_id": "category_changed", "shelf_location": "shelf_changed"}[column]
+	_, err := db.Exec(`
+		INSERT INTO book_events (event_id, book_id, event_type, `+column+`, occurred_at)
+		VALUES (?, ?, ?, NULLIF(?, ''), ?)
+	`, eventID, bookID, eventType, value, occurredAt)
+	if err != nil {
+		t.Fatalf("failed to insert book event: %v", err)
+	}
+}
+
+func insertTestCategory(t *testing.T, db *sql.DB, categoryID, parentID, code, name string) {
+	t.Helper()
+	_, err := db.Exec(`
+		INSERT INTO category_events (event_id, category_id, event_type, parent_id, code, name, occurred_at)
+		VALUES (?, ?, 'created', NULLIF(?, ''), NULLIF(?, ''), ?, '2024-01-01T00:00:00Z')
+	`, "evt-"+categoryID, categoryID, parentID, code, name)
+	if err != nil {
+		t.Fatalf("failed to insert category event: %v", err)
+	}
+}
+
+func TestListBooksSource_WithClassificationFilters_ReturnsClassifiedBooks(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestCategory(t, db, "cat-literature", "", "900", "文学")
+	insertTestCategory(t, db, "cat-japanese", "cat-literature", "910", "日本文学")
+	insertTestCategory(t, db, "cat-science", "", "400", "自然科学")
+	insertTestBook(t, db, "book-1", "One", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Two", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-3", "Three", "", "", "2024-01-03T00:00:00Z", `["A"]`)
+	insertTestClassification(t, db, "evt-tags-1", "book-1", "tags", `["小説", "SF"]`, "2024-01-05T00:00:00Z")
+	insertTestClassification(t, db, "evt-tags-2", "book-2", "tags", `["SF"]`, "2024-01-05T00:00:00Z")
+	// A later tags_changed event replaces the earlier tags
+	insertTestClassification(t, db, "evt-tags-3", "book-2", "tags", `["エッセイ"]`, "2024-01-06T00:00:00Z")
+	insertTestClassification(t, db, "evt-cat-1", "book-1", "category_id", "cat-japanese", "2024-01-05T00:00:00Z")
+	insertTestClassification(t, db, "evt-cat-2", "book-2", "category_id", "cat-literature", "2024-01-05T00:00:00Z")
+	insertTestClassification(t, db, "evt-cat-3", "book-3", "category_id", "cat-science", "2024-01-05T00:00:00Z")
+	insertTestClassification(t, db, "evt-shelf-1", "book-1", "shelf_location", "書斎 A-3", "2024-01-05T00:00:00Z")
+
+	tag := "SF"
+	literature := "cat-literature"
+	japanese := "cat-japanese"
+	shelf := "書斎 A-3"
+	cases := []struct {
+		name     string
+		filter   domain.BookFilter
+		expected []string
+	}{
+		{"tag", domain.BookFilter{Tag: &tag}, []string{"book-1"}},
+		{"category with descendants", domain.BookFilter{CategoryID: &literature}, []string{"book-2", "book-1"}},
+		{"leaf category", domain.BookFilter{CategoryID: &japanese}, []string{"book-1"}},
+		{"shelf location", domain.BookFilter{ShelfLocation: &shelf}, []string{"book-1"}},
+	}
+	for _, c := range cases {
+		query := listQuery(nil)
+		query.Filter = c.filter
+
+		// When listing books with the classification filter
+		items, total, err := pageOf(ListBooksSource(queries, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Then only the classified books are returned
+		if !equalIDs(bookIDs(items), c.expected) || total != int64(len(c.expected)) {
+			t.Errorf("%s: expected %v, got %v (total %d)", c.name, c.expected, bookIDs(items), total)
+		}
+	}
+
+	// When listing every book
+	items, _, err := pageOf(ListBooksSource(queries, time.Now(), calendar.Calendar{})(ctx, listQuery(nil), domain.ToPagination(nil, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then each book carries its current classification
+	book1 := items[2]
+	if !reflect.DeepEqual(book1.Tags, []string{"小説", "SF"}) {
+		t.Errorf("unexpected tags %v", book1.Tags)
+	}
+	if book1.Category == nil || book1.Category.ID != "cat-japanese" || book1.Category.Name != "日本文学" || *book1.Category.Code != "910" {
+		t.Errorf("unexpected category %+v", book1.Category)
+	}
+	if book1.ShelfLocation == nil || *book1.ShelfLocation != "書斎 A-3" {
+		t.Errorf("unexpected shelf location %v", book1.ShelfLocation)
+	}
+	book3 := items[0]
+	if len(book3.Tags) != 0 || book3.ShelfLocation != nil {
+		t.Errorf("expected book-3 without tags and shelf, got %v %v", book3.Tags, book3.ShelfLocation)
+	}
+}
+
+func TestListBooksSource_WithTagFacet_CountsCurrentTags(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	insertTestBook(t, db, "book-1", "One", "", "", "2024-01-01T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-2", "Two", "", "", "2024-01-02T00:00:00Z", `["A"]`)
+	insertTestBook(t, db, "book-3", "Three", "", "", "2024-01-03T00:00:00Z", `["A"]`)
+	insertTestClassification(t, db, "evt-tags-1", "book-1", "tags", `["小説", "SF"]`, "2024-01-05T00:00:00Z")
+	insertTestClassification(t, db, "evt-tags-2", "book-2", "tags", `["SF"]`, "2024-01-05T00:00:00Z")
+	insertTestClassification(t, db, "evt-tags-3", "book-3", "tags", `["SF"]`, "2024-01-05T00:00:00Z")
+	insertTestClassification(t, db, "evt-tags-4", "book-3", "tags", `[]`, "2024-01-06T00:00:00Z")
+
+	query := listQuery(nil)
+	query.Facets = []domain.BookFacet{domain.BookFacetTag}
+
+	// When the tag facet is requested
+	page, err := ListBooksSource(queries, time.Now(), calendar.Calendar{})(ctx, query, domain.ToPagination(nil, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then only the current tags of each book are counted
+	expected := domain.BookFacets{
+		domain.BookFacetTag: {{Value: "SF", Count: 2}, {Value: "小説", Count: 1}},
+	}
+	if !reflect.DeepEqual(page.Facets, expected) {
+		t.Errorf("expected %v, got %v", expected, page.Facets)
+	}
+}
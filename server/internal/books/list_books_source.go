@@ -0,0 +1,191 @@
+package books
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"holocron/internal/books/domain"
+	"holocron/internal/calendar"
+	lendingDomain "holocron/internal/lending/domain"
+)
+
+func ListBooksSource(queries *Queries, now time.Time, cal calendar.Calendar) domain.BookListSource {
+	return func(ctx context.Context, query domain.BookListQuery, pagination domain.Pagination) (domain.BookPage, error) {
+		return listBookPage(ctx, queries, query, pagination, sql.NullString{}, now, cal)
+	}
+}
+
+// listBookPage lists a page of the books matching the filter, and also the
+// FTS5 match expression when search is set. The list and the search share it.
+func listBookPage(
+	ctx context.Context,
+	queries *Queries,
+	query domain.BookListQuery,
+	pagination domain.Pagination,
+	search sql.NullString,
+	now time.Time,
+	cal calendar.Calendar,
+) (domain.BookPage, error) {
+	filter := toBookFilterArgs(query.Filter)
+	keyset := toBookKeysetArgs(query.Sort, pagination)
+	nowArg := now.UTC().Format(time.RFC3339)
+	overdueLendingIDs, err := listOverdueLendingIDs(ctx, queries, query, now, cal)
+	if err != nil {
+		return domain.BookPage{}, err
+	}
+
+	rows, err := queries.ListBooks(ctx, ListBooksParams{
+		BookSearch:        search,
+		SortKey:           string(query.Sort.Key),
+		Code:              filter.code,
+		Status:            filter.status,
+		Now:               nowArg,
+		OverdueLendingIds: overdueLendingIDs,
+		BorrowerID:        filter.borrowerID,
+		Publisher:         filter.publisher,
+		Author:            filter.author,
+		PublishedFrom:     filter.publishedFrom,
+		PublishedTo:       filter.publishedTo,
+		CreatedAfter:      filter.createdAfter,
+		Tag:               filter.tag,
+		CategoryID:        filter.categoryID,
+		ShelfLocation:     filter.shelfLocation,
+		CursorBookID:      keyset.bookID,
+		CursorValue:       keyset.value,
+		Backward:          keyset.backward,
+		Descending:        keyset.descending,
+		CursorUpdatedAt:   keyset.updatedAt,
+		Limit:             int64(pagination.Limit() + 1),
+		Offset:            int64(pagination.Offset()),
+	})
+	if err != nil {
+		return domain.BookPage{}, err
+	}
+
+	var total *int64
+	if pagination.IncludesTotal() {
+		count, err := queries.CountBooks(ctx, CountBooksParams{
+			BookSearch:        search,
+			Code:              filter.code,
+			Status:            filter.status,
+			Now:               nowArg,
+			OverdueLendingIds: overdueLendingIDs,
+			BorrowerID:        filter.borrowerID,
+			Publisher:         filter.publisher,
+			Author:            filter.author,
+			PublishedFrom:     filter.publishedFrom,
+			PublishedTo:       filter.publishedTo,
+			CreatedAfter:      filter.createdAfter,
+			Tag:               filter.tag,
+			CategoryID:        filter.categoryID,
+			ShelfLocation:     filter.shelfLocation,
+		})
+		if err != nil {
+			return domain.BookPage{}, err
+		}
+		total = &count
+	}
+
+	var facets domain.BookFacets
+	if len(query.Facets) > 0 {
+		include := toBookFacetArgs(query.Facets)
+		counts, err := queries.ListBookFacets(ctx, ListBookFacetsParams{
+			BookSearch:        search,
+			Now:               nowArg,
+			OverdueLendingIds: overdueLendingIDs,
+			Code:              filter.code,
+			Status:            filter.status,
+			BorrowerID:        filter.borrowerID,
+			Publisher:         filter.publisher,
+			Author:            filter.author,
+			PublishedFrom:     filter.publishedFrom,
+			PublishedTo:       filter.publishedTo,
+			CreatedAfter:      filter.createdAfter,
+			Tag:               filter.tag,
+			CategoryID:        filter.categoryID,
+			ShelfLocation:     filter.shelfLocation,
+			IncludeStatus:     include.status,
+			IncludePublisher:  include.publisher,
+			IncludeAuthor:     include.author,
+			IncludeDecade:     include.decade,
+			IncludeTag:        include.tag,
+			ValueLimit:        domain.BookFacetValueLimit,
+		})
+		if err != nil {
+			return domain.BookPage{}, err
+		}
+		facets = domain.NewBookFacets(query.Facets)
+		for _, count := range counts {
+			facets.Add(count.Facet, count.Value, count.Cnt)
+		}
+	}
+
+	items := make([]domain.BookItem, 0, len(rows))
+	positions := make([]domain.BookPosition, 0, len(rows))
+	for _, row := range rows {
+		item, err := domain.BookItemFromRow(
+			row.BookID,
+			nullStringToPtr(row.Code),
+			row.Title.String,
+			row.Authors.String,
+			nullStringToPtr(row.Publisher),
+			nullStringToPtr(row.PublishedDate),
+			nullStringToPtr(row.ThumbnailUrl),
+			row.CreatedAt,
+			nullStringToPtr(row.BorrowerID),
+			nullStringToPtr(row.BorrowerName),
+			nullStringToPtr(row.BorrowedAt),
+		)
+		if err != nil {
+			return domain.BookPage{}, err
+		}
+		err = item.SetClassification(
+			nullStringToPtr(row.Tags),
+			nullStringToPtr(row.CategoryID),
+			nullStringToPtr(row.CategoryName),
+			nullStringToPtr(row.CategoryCode),
+			nullStringToPtr(row.ShelfLocation),
+		)
+		if err != nil {
+			return domain.BookPage{}, err
+		}
+		items = append(items, *item)
+		positions = append(positions, domain.NewBookPosition(row.SortValue, row.UpdatedAt, row.BookID))
+	}
+	page := domain.NewBookPage(items, positions, pagination, total)
+	page.Facets = facets
+	return page, nil
+}
+
+// listOverdueLendingIDs returns the overdue loans without a due date as a JSON
+// array. They fall due on the legacy due date like everywhere else, which
+// depends on the library calendar, so the queries cannot tell them. They are
+// only looked up when the overdue status is filtered or counted.
+func listOverdueLendingIDs(ctx context.Context, queries *Queries, query domain.BookListQuery, now time.Time, cal calendar.Calendar) (string, error) {
+	overdue := query.Filter.Status != nil && *query.Filter.Status == domain.BookStatusOverdue
+	if !overdue && !slices.Contains(query.Facets, domain.BookFacetStatus) {
+		return "[]", nil
+	}
+	loans, err := queries.ListLoansWithoutDueDate(ctx)
+	if err != nil {
+		return "", err
+	}
+	ids := []string{}
+	for _, loan := range loans {
+		borrowedAt, err := time.Parse(time.RFC3339, loan.BorrowedAt)
+		if err != nil {
+			return "", err
+		}
+		if lendingDomain.LegacyDueDate(borrowedAt, cal).Before(now) {
+			ids = append(ids, loan.LendingID)
+		}
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -3,58 +3,42 @@ package books
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"holocron/internal/books/domain"
+	"holocron/internal/calendar"
 )
 
-func SearchBooksSource(queries *Queries) domain.BookListSource {
-	return func(ctx context.Context, keyword *domain.SearchKeyword, pagination domain.Pagination) ([]domain.BookItem, int64, error) {
-		if keyword == nil {
-			return nil, 0, domain.ErrNotMyResponsibility
+func SearchBooksSource(queries *Queries, index *BookSearchIndex, romaji bool, now time.Time, cal calendar.Calendar) domain.BookListSource {
+	return func(ctx context.Context, listQuery domain.BookListQuery, pagination domain.Pagination) (domain.BookPage, error) {
+		if listQuery.Keyword == nil {
+			return domain.BookPage{}, domain.ErrNotMyResponsibility
 		}
 
-		pattern := "%" + string(*keyword) + "%"
-		searchPattern := sql.NullString{String: pattern, Valid: true}
+		query := domain.ParseSearchQuery(*listQuery.Keyword)
+		if romaji {
+			query = query.WithRomaji()
+		}
+		if query.IsEmpty() {
+			var total int64
+			page := domain.NewBookPage([]domain.BookItem{}, nil, pagination, &total)
+			page.Facets = domain.NewBookFacets(listQuery.Facets)
+			return page, nil
+		}
 
-		rows, err := queries.SearchBooks(ctx, SearchBooksParams{
-			Title:   searchPattern,
-			Authors: searchPattern,
-			Limit:   int64(pagination.Limit()),
-			Offset:  int64(pagination.Offset()),
-		})
-		if err != nil {
-			return nil, 0, err
+		if err := index.CatchUp(ctx); err != nil {
+			return domain.BookPage{}, err
 		}
 
-		total, err := queries.CountSearchBooks(ctx, CountSearchBooksParams{
-			Title:   searchPattern,
-			Authors: searchPattern,
-		})
+		search := sql.NullString{String: query.MatchExpression(), Valid: true}
+		page, err := listBookPage(ctx, queries, listQuery, pagination, search, now, cal)
 		if err != nil {
-			return nil, 0, err
+			return domain.BookPage{}, err
 		}
-
-		items := make([]domain.BookItem, 0, len(rows))
-		for _, row := range rows {
-			item, err := domain.BookItemFromRow(
-				row.BookID,
-				nullStringToPtr(row.Code),
-				row.Title.String,
-				row.Authors.String,
-				nullStringToPtr(row.Publisher),
-				nullStringToPtr(row.PublishedDate),
-				nullStringToPtr(row.ThumbnailUrl),
-				row.CreatedAt,
-				nullStringToPtr(row.BorrowerID),
-				nullStringToPtr(row.BorrowerName),
-				nullStringToPtr(row.BorrowedAt),
-			)
-			if err != nil {
-				return nil, 0, err
-			}
-			items = append(items, *item)
+		for i := range page.Items {
+			page.Items[i].Highlights = domain.HighlightBook(page.Items[i], query)
 		}
-		return items, total, nil
+		return page, nil
 	}
 }
 
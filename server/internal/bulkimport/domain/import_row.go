@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrInvalidFormat = errors.New("format must be csv, tsv or isbn")
+	ErrMissingHeader = errors.New("header must include title or code")
+)
+
+type ImportFormat string
+
+const (
+	ImportFormatCSV  ImportFormat = "csv"
+	ImportFormatTSV  ImportFormat = "tsv"
+	ImportFormatISBN ImportFormat = "isbn"
+)
+
+type ImportRow struct {
+	Number        int
+	Code          string
+	Title         string
+	Authors       []string
+	Publisher     string
+	PublishedDate string
+	ThumbnailURL  string
+}
+
+var columnAliases = map[string]string{
+	"title":          "title",
+	"authors":        "authors",
+	"author":         "authors",
+	"publisher":      "publisher",
+	"published_date": "publishedDate",
+	"publisheddate":  "publishedDate",
+	"code":           "code",
+	"isbn":           "code",
+	"thumbnail_url":  "thumbnailUrl",
+	"thumbnailurl":   "thumbnailUrl",
+}
+
+// ParseImportFormat parses the format name. An empty name is detected from the data:
+// a first line with a known column name separated by tabs or commas is a header,
+// anything else is a list of codes.
+func ParseImportFormat(s string, data []byte) (ImportFormat, error) {
+	switch ImportFormat(s) {
+	case ImportFormatCSV, ImportFormatTSV, ImportFormatISBN:
+		return ImportFormat(s), nil
+	case "":
+		return detectFormat(data), nil
+	}
+	return "", ErrInvalidFormat
+}
+
+func detectFormat(data []byte) ImportFormat {
+	firstLine, _, _ := bytes.Cut(bytes.TrimPrefix(data, []byte("\uFEFF")), []byte("\n"))
+	line := strings.ToLower(strings.TrimSpace(string(firstLine)))
+	for _, sep := range []struct {
+		sep    string
+		format ImportFormat
+	}{{"\t", ImportFormatTSV}, {",", ImportFormatCSV}} {
+		for _, column := range strings.Split(line, sep.sep) {
+			if _, ok := columnAliases[strings.TrimSpace(column)]; ok {
+				return sep.format
+			}
+		}
+	}
+	return ImportFormatISBN
+}
+
+func ParseImportRows(format ImportFormat, data []byte) ([]ImportRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+	switch format {
+	case ImportFormatISBN:
+		return parseCodeList(data)
+	case ImportFormatCSV:
+		return parseTable(data, ',')
+	case ImportFormatTSV:
+		return parseTable(data, '\t')
+	}
+	return nil, ErrInvalidFormat
+}
+
+func parseCodeList(data []byte) ([]ImportRow, error) {
+	var rows []ImportRow
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rows = append(rows, ImportRow{Number: number, Code: line})
+	}
+	return rows, scanner.Err()
+}
+
+func parseTable(data []byte, comma rune) ([]ImportRow, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if comma == '\t' {
+		reader.LazyQuotes = true
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrMissingHeader
+		}
+		return nil, err
+	}
+	columns := make([]string, len(header))
+	hasKey := false
+	for i, name := range header {
+		columns[i] = columnAliases[strings.ToLower(strings.TrimSpace(name))]
+		if columns[i] == "title" || columns[i] == "code" {
+			hasKey = true
+		}
+	}
+	if !hasKey {
+		return nil, ErrMissingHeader
+	}
+
+	var rows []ImportRow
+	for number := 1; ; number++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := ImportRow{Number: number}
+		empty := true
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			if value != "" {
+				empty = false
+			}
+			switch columns[i] {
+			case "title":
+				row.Title = value
+			case "authors":
+				row.Authors = splitAuthors(value)
+			case "publisher":
+				row.Publisher = value
+			case "publishedDate":
+				row.PublishedDate = value
+			case "code":
+				row.Code = value
+			case "thumbnailUrl":
+				row.ThumbnailURL = value
+			}
+		}
+		if !empty {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func splitAuthors(s string) []string {
+	var authors []string
+	for _, author := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '|' || r == '、' }) {
+		if author = strings.TrimSpace(author); author != "" {
+			authors = append(authors, author)
+		}
+	}
+	return authors
+}
+
+// DeriveImportID identifies an import by its content, so re-running the
+// same file resumes the earlier import instead of starting a new one.
+func DeriveImportID(format ImportFormat, data []byte) string {
+	h := sha256.New()
+	h.Write([]byte(format))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
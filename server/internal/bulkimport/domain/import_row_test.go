@@ -0,0 +1,88 @@
+//go:build small
+
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestParseImportFormat_WithEmptyName_DetectsFormat(t *testing.T) {
+	cases := map[string]ImportFormat{
+		"title,authors\nリーダブルコード,Dustin Boswell\n":      ImportFormatCSV,
+		"\uFEFFcode\tpublisher\n9784873115658\tオライリー\n": ImportFormatTSV,
+		"9784873115658\n9784873119045\n":                ImportFormatISBN,
+	}
+	for data, expected := range cases {
+		format, err := ParseImportFormat("", []byte(data))
+		if err != nil || format != expected {
+			t.Errorf("expected %s, got %s (%v)", expected, format, err)
+		}
+	}
+}
+
+func TestParseImportRows_WithCodeList_SkipsBlankAndCommentLines(t *testing.T) {
+	data := "# shelf A\n9784873115658\n\n  9784873119045  \n"
+
+	rows, err := ParseImportRows(ImportFormatISBN, []byte(data))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Code != "9784873115658" || rows[1].Code != "9784873119045" {
+		t.Fatalf("expected 2 codes, got %+v", rows)
+	}
+	if rows[0].Number != 2 || rows[1].Number != 4 {
+		t.Errorf("expected line numbers 2 and 4, got %d and %d", rows[0].Number, rows[1].Number)
+	}
+}
+
+func TestParseImportRows_WithCSV_MapsColumnsAndSplitsAuthors(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("authors separated by ; are split", prop.ForAll(
+		func(authors []string) bool {
+			data := "Title,Author,ISBN\n\"本\"," + strings.Join(authors, ";") + ",9784873115658\n"
+			rows, err := ParseImportRows(ImportFormatCSV, []byte(data))
+			if err != nil || len(rows) != 1 {
+				return false
+			}
+			row := rows[0]
+			if row.Title != "本" || row.Code != "9784873115658" || len(row.Authors) != len(authors) {
+				return false
+			}
+			for i := range authors {
+				if row.Authors[i] != authors[i] {
+					return false
+				}
+			}
+			return true
+		},
+		gen.SliceOfN(3, gen.AlphaString().SuchThat(func(s string) bool { return s != "" })),
+	))
+	properties.TestingRun(t)
+}
+
+func TestParseImportRows_WithoutKeyColumn_ReturnsMissingHeaderError(t *testing.T) {
+	_, err := ParseImportRows(ImportFormatCSV, []byte("foo,bar\n1,2\n"))
+	if err != ErrMissingHeader {
+		t.Errorf("expected ErrMissingHeader, got %v", err)
+	}
+}
+
+func TestDeriveImportID_WithSameContent_ReturnsSameID(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("same content gives same ID and different content differs", prop.ForAll(
+		func(a, b string) bool {
+			same := DeriveImportID(ImportFormatISBN, []byte(a)) == DeriveImportID(ImportFormatISBN, []byte(a))
+			differs := a == b || DeriveImportID(ImportFormatISBN, []byte(a)) != DeriveImportID(ImportFormatISBN, []byte(b))
+			return same && differs
+		},
+		gen.AnyString(),
+		gen.AnyString(),
+	))
+	properties.TestingRun(t)
+}
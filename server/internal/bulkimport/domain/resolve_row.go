@@ -0,0 +1,60 @@
+package domain
+
+import (
+	book "holocron/internal/book/domain"
+)
+
+type RowStatus string
+
+const (
+	RowStatusCreated   RowStatus = "created"
+	RowStatusDuplicate RowStatus = "duplicate"
+	RowStatusNotFound  RowStatus = "not_found"
+	RowStatusInvalid   RowStatus = "invalid"
+)
+
+// ResolveRow combines the values written in the row with the looked up info.
+// Values written in the row take precedence over looked up ones.
+// It returns RowStatusCreated when the result can be registered.
+func ResolveRow(row ImportRow, found *book.BookInfo) (book.BookInfo, RowStatus, string) {
+	info := book.BookInfo{
+		Title:         row.Title,
+		Authors:       row.Authors,
+		Publisher:     row.Publisher,
+		PublishedDate: row.PublishedDate,
+		ThumbnailURL:  row.ThumbnailURL,
+	}
+	if found != nil {
+		if info.Title == "" {
+			// The volume number only describes the title it came with.
+			info.Title = found.Title
+			info.Volume = found.Volume
+		}
+		if len(info.Authors) == 0 {
+			info.Authors = found.Authors
+		}
+		if info.Publisher == "" {
+			info.Publisher = found.Publisher
+		}
+		if info.PublishedDate == "" {
+			info.PublishedDate = found.PublishedDate
+		}
+		if info.ThumbnailURL == "" {
+			info.ThumbnailURL = found.ThumbnailURL
+		}
+	}
+
+	if info.Title == "" {
+		if row.Code != "" && found == nil {
+			return info, RowStatusNotFound, "book not found in any source"
+		}
+		return info, RowStatusInvalid, "title is required"
+	}
+	if _, err := book.ParseBookTitle(info.Title); err != nil {
+		return info, RowStatusInvalid, err.Error()
+	}
+	if _, err := book.ParseBookAuthors(info.Authors); err != nil {
+		return info, RowStatusInvalid, err.Error()
+	}
+	return info, RowStatusCreated, ""
+}
@@ -0,0 +1,52 @@
+//go:build small
+
+package domain
+
+import (
+	"testing"
+
+	book "holocron/internal/book/domain"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestResolveRow_WithRowValues_TakesPrecedenceOverLookup(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("written values win and missing ones are filled", prop.ForAll(
+		func(title string, publisher string) bool {
+			row := ImportRow{Code: "9784873115658", Title: title}
+			found := &book.BookInfo{Title: "looked up", Authors: []string{"author"}, Publisher: publisher}
+
+			info, status, _ := ResolveRow(row, found)
+			return status == RowStatusCreated &&
+				info.Title == title &&
+				info.Authors[0] == "author" &&
+				info.Publisher == publisher
+		},
+		gen.AlphaString().SuchThat(func(s string) bool { return s != "" && len(s) <= 200 }),
+		gen.AlphaString(),
+	))
+	properties.TestingRun(t)
+}
+
+func TestResolveRow_WithCodeNotFound_ReturnsNotFound(t *testing.T) {
+	_, status, _ := ResolveRow(ImportRow{Code: "9784873115658"}, nil)
+	if status != RowStatusNotFound {
+		t.Errorf("expected not_found, got %s", status)
+	}
+}
+
+func TestResolveRow_WithoutTitleOrAuthors_ReturnsInvalid(t *testing.T) {
+	cases := []ImportRow{
+		{Authors: []string{"author"}},
+		{Title: "title"},
+	}
+	for _, row := range cases {
+		_, status, _ := ResolveRow(row, nil)
+		if status != RowStatusInvalid {
+			t.Errorf("%+v: expected invalid, got %s", row, status)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package bulkimport
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"holocron/internal/api"
+	"holocron/internal/bulkimport/domain"
+)
+
+const MaxImportBytes = 5 << 20
+
+type ImportBooksHandler struct {
+	service *ImportBooksService
+}
+
+func NewImportBooksHandler(service *ImportBooksService) *ImportBooksHandler {
+	return &ImportBooksHandler{service: service}
+}
+
+func (h *ImportBooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.PostBooksImportParams) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxImportBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "payload_too_large", "import data must not exceed 5MB")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+	if len(data) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "import data must not be empty")
+		return
+	}
+
+	input := ImportBooksInput{Data: data}
+	if params.Format != nil {
+		input.Format = string(*params.Format)
+	}
+	if params.DryRun != nil {
+		input.DryRun = *params.DryRun
+	}
+	if params.ImportId != nil {
+		input.ImportID = *params.ImportId
+	}
+	if params.Concurrency != nil {
+		if *params.Concurrency < 1 || *params.Concurrency > MaxConcurrency {
+			writeError(w, http.StatusBadRequest, "invalid_request", "concurrency must be between 1 and 16")
+			return
+		}
+		input.Concurrency = *params.Concurrency
+	}
+
+	report, err := h.service.ImportBooks(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidFormat):
+			writeError(w, http.StatusBadRequest, "invalid_request", "format must be csv, tsv or isbn")
+		case errors.Is(err, domain.ErrMissingHeader):
+			writeError(w, http.StatusBadRequest, "invalid_request", "header must contain a title or code column")
+		case errors.Is(err, ErrInvalidData):
+			writeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	rows := make([]map[string]any, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		item := map[string]any{
+			"row":     row.Row,
+			"status":  row.Status,
+			"resumed": row.Resumed,
+		}
+		if row.Code != "" {
+			item["code"] = row.Code
+		}
+		if row.Title != "" {
+			item["title"] = row.Title
+		}
+		if row.BookID != "" {
+			item["bookId"] = row.BookID
+		}
+		if row.Message != "" {
+			item["message"] = row.Message
+		}
+		rows = append(rows, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"importId":  report.ImportID,
+		"format":    report.Format,
+		"dryRun":    report.DryRun,
+		"total":     report.Total,
+		"created":   report.Created,
+		"duplicate": report.Duplicate,
+		"notFound":  report.NotFound,
+		"invalid":   report.Invalid,
+		"rows":      rows,
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
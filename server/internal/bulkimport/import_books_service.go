@@ -0,0 +1,339 @@
+package bulkimport
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	book "holocron/internal/book/domain"
+	bookcode "holocron/internal/bookcode/domain"
+	"holocron/internal/bulkimport/domain"
+	"holocron/internal/series"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidData = errors.New("invalid import data")
+
+const (
+	DefaultConcurrency = 4
+	MaxConcurrency     = 16
+)
+
+type ImportBooksInput struct {
+	Format      string
+	Data        []byte
+	ImportID    string
+	DryRun      bool
+	Concurrency int
+	// OnRow is called for each row in order as soon as its result is known.
+	OnRow func(RowResult)
+}
+
+type RowResult struct {
+	Row     int
+	Code    string
+	Title   string
+	Status  domain.RowStatus
+	BookID  string
+	Message string
+	// Resumed is true when the row was processed by an earlier run of the same import.
+	Resumed bool
+}
+
+type ImportReport struct {
+	ImportID  string
+	Format    domain.ImportFormat
+	DryRun    bool
+	Total     int
+	Created   int
+	Duplicate int
+	NotFound  int
+	Invalid   int
+	Rows      []RowResult
+}
+
+type ImportBooksService struct {
+	db      *sql.DB
+	queries *Queries
+	sources []bookcode.NamedBookInfoSource
+	now     func() time.Time
+}
+
+func NewImportBooksService(db *sql.DB, sources []bookcode.NamedBookInfoSource) *ImportBooksService {
+	return &ImportBooksService{
+		db:      db,
+		queries: New(db),
+		sources: sources,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+type preparedRow struct {
+	row     domain.ImportRow
+	code    string
+	info    book.BookInfo
+	status  domain.RowStatus
+	message string
+}
+
+// ImportBooks registers the rows in order. Lookups run concurrently, but rows are
+// committed one by one together with their result, so an interrupted import can be
+// resumed by running it again with the same import ID. When ctx is canceled the
+// report of the rows processed so far is returned along with the context error.
+func (s *ImportBooksService) ImportBooks(ctx context.Context, input ImportBooksInput) (*ImportReport, error) {
+	format, err := domain.ParseImportFormat(input.Format, input.Data)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := domain.ParseImportRows(format, input.Data)
+	if err != nil {
+		if errors.Is(err, domain.ErrMissingHeader) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+
+	importID := input.ImportID
+	if importID == "" {
+		importID = domain.DeriveImportID(format, input.Data)
+	}
+
+	processed, err := s.processedRows(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{
+		ImportID: importID,
+		Format:   format,
+		DryRun:   input.DryRun,
+		Total:    len(rows),
+		Rows:     []RowResult{},
+	}
+	record := func(result RowResult) {
+		report.add(result)
+		if input.OnRow != nil {
+			input.OnRow(result)
+		}
+	}
+
+	var pending []domain.ImportRow
+	seenCodes := map[string]bool{}
+	for _, row := range rows {
+		if done, ok := processed[row.Number]; ok {
+			if done.Code != "" && done.Status == domain.RowStatusCreated {
+				seenCodes[done.Code] = true
+			}
+			record(done)
+			continue
+		}
+		pending = append(pending, row)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := s.prepareAll(ctx, pending, clampConcurrency(input.Concurrency))
+
+	for _, ch := range results {
+		var prepared preparedRow
+		select {
+		case prepared = <-ch:
+		case <-ctx.Done():
+			return report, ctx.Err()
+		}
+
+		result, err := s.commit(ctx, importID, prepared, seenCodes, input.DryRun)
+		if err != nil {
+			return report, err
+		}
+		record(result)
+	}
+
+	return report, nil
+}
+
+func (s *ImportBooksService) processedRows(ctx context.Context, importID string) (map[int]RowResult, error) {
+	rows, err := s.queries.ListImportRows(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+	processed := make(map[int]RowResult, len(rows))
+	for _, row := range rows {
+		processed[int(row.RowNumber)] = RowResult{
+			Row:     int(row.RowNumber),
+			Code:    row.Code.String,
+			Title:   row.Title.String,
+			Status:  domain.RowStatus(row.Status),
+			BookID:  row.BookID.String,
+			Message: row.Message.String,
+			Resumed: true,
+		}
+	}
+	return processed, nil
+}
+
+// prepareAll resolves the rows with at most concurrency lookups in flight.
+// The returned channels are in row order and each receives exactly one value
+// unless ctx is canceled first.
+func (s *ImportBooksService) prepareAll(ctx context.Context, rows []domain.ImportRow, concurrency int) []chan preparedRow {
+	results := make([]chan preparedRow, len(rows))
+	for i := range results {
+		results[i] = make(chan preparedRow, 1)
+	}
+	go func() {
+		sem := make(chan struct{}, concurrency)
+		for i, row := range rows {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func() {
+				defer func() { <-sem }()
+				results[i] <- s.prepare(ctx, row)
+			}()
+		}
+	}()
+	return results
+}
+
+func (s *ImportBooksService) prepare(ctx context.Context, row domain.ImportRow) preparedRow {
+	prepared := preparedRow{row: row}
+	if row.Code != "" {
+		code, err := bookcode.NormalizeCode(row.Code)
+		if err != nil {
+			prepared.code = row.Code
+			prepared.status = domain.RowStatusInvalid
+			prepared.message = err.Error()
+			return prepared
+		}
+		prepared.code = code
+	}
+
+	var found *book.BookInfo
+	if prepared.code != "" && needsLookup(row) {
+		merged, err := bookcode.MergeBookInfo(ctx, s.sources, prepared.code)
+		if err == nil {
+			found = &merged.Info
+		}
+	}
+	prepared.info, prepared.status, prepared.message = domain.ResolveRow(row, found)
+	return prepared
+}
+
+func needsLookup(row domain.ImportRow) bool {
+	return row.Title == "" || len(row.Authors) == 0 || row.Publisher == "" || row.PublishedDate == "" || row.ThumbnailURL == ""
+}
+
+func (s *ImportBooksService) commit(ctx context.Context, importID string, prepared preparedRow, seenCodes map[string]bool, dryRun bool) (RowResult, error) {
+	result := RowResult{
+		Row:     prepared.row.Number,
+		Code:    prepared.code,
+		Title:   prepared.info.Title,
+		Status:  prepared.status,
+		Message: prepared.message,
+	}
+
+	if result.Status == domain.RowStatusCreated && prepared.code != "" {
+		count, err := s.queries.CountLiveBooksByCode(ctx, sql.NullString{String: prepared.code, Valid: true})
+		if err != nil {
+			return RowResult{}, err
+		}
+		if count > 0 || seenCodes[prepared.code] {
+			result.Status = domain.RowStatusDuplicate
+			result.Message = "a book with this code is already registered"
+		}
+	}
+	if result.Status == domain.RowStatusCreated && prepared.code != "" {
+		seenCodes[prepared.code] = true
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return RowResult{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	queries := s.queries.WithTx(tx)
+	now := s.now().Format(time.RFC3339)
+
+	if result.Status == domain.RowStatusCreated {
+		result.BookID = uuid.New().String()
+		authorsJSON, err := json.Marshal(prepared.info.Authors)
+		if err != nil {
+			return RowResult{}, err
+		}
+		err = queries.InsertBookEvent(ctx, InsertBookEventParams{
+			EventID:       uuid.New().String(),
+			BookID:        result.BookID,
+			Code:          nullString(prepared.code),
+			Title:         sql.NullString{String: prepared.info.Title, Valid: true},
+			Authors:       sql.NullString{String: string(authorsJSON), Valid: true},
+			Publisher:     nullString(prepared.info.Publisher),
+			PublishedDate: nullString(prepared.info.PublishedDate),
+			ThumbnailUrl:  nullString(prepared.info.ThumbnailURL),
+			OccurredAt:    now,
+		})
+		if err != nil {
+			return RowResult{}, err
+		}
+		_, err = series.AssignDetectedSeries(ctx, series.New(tx), result.BookID, prepared.info.Title, prepared.info.Volume)
+		if err != nil {
+			return RowResult{}, err
+		}
+	}
+
+	err = queries.InsertImportRow(ctx, InsertImportRowParams{
+		ImportID:    importID,
+		RowNumber:   int64(result.Row),
+		Code:        nullString(result.Code),
+		Title:       nullString(result.Title),
+		Status:      string(result.Status),
+		BookID:      nullString(result.BookID),
+		Message:     nullString(result.Message),
+		ProcessedAt: now,
+	})
+	if err != nil {
+		return RowResult{}, err
+	}
+
+	return result, tx.Commit()
+}
+
+func (r *ImportReport) add(result RowResult) {
+	switch result.Status {
+	case domain.RowStatusCreated:
+		r.Created++
+	case domain.RowStatusDuplicate:
+		r.Duplicate++
+	case domain.RowStatusNotFound:
+		r.NotFound++
+	case domain.RowStatusInvalid:
+		r.Invalid++
+	}
+	r.Rows = append(r.Rows, result)
+}
+
+func clampConcurrency(n int) int {
+	if n <= 0 {
+		return DefaultConcurrency
+	}
+	if n > MaxConcurrency {
+		return MaxConcurrency
+	}
+	return n
+}
+
+func nullString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
@@ -0,0 +1,312 @@
+//go:build medium
+
+package bulkimport
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	book "holocron/internal/book/domain"
+	bookcode "holocron/internal/bookcode/domain"
+	"holocron/internal/bulkimport/domain"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`
+		CREATE TABLE book_events (
+			event_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			authors TEXT,
+			publisher TEXT,
+			published_date TEXT,
+			thumbnail_url TEXT,
+			delete_reason TEXT,
+			delete_memo TEXT,
+			origin TEXT,
+			cover_id TEXT,
+			series_id TEXT,
+			volume_number INTEGER,
+			accession_number TEXT,
+			condition TEXT,
+			condition_note TEXT,
+			lending_id TEXT,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE INDEX idx_book_events_book_id ON book_events(book_id);
+		CREATE TABLE series_events (
+			event_id TEXT PRIMARY KEY,
+			series_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			title TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE TABLE import_rows (
+			import_id TEXT NOT NULL,
+			row_number INTEGER NOT NULL,
+			code TEXT,
+			title TEXT,
+			status TEXT NOT NULL,
+			book_id TEXT,
+			message TEXT,
+			processed_at TEXT NOT NULL,
+			PRIMARY KEY (import_id, row_number)
+		);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func catalogSource(books map[string]book.BookInfo, calls *atomic.Int32) bookcode.NamedBookInfoSource {
+	return bookcode.NamedBookInfoSource{
+		Name: "catalog",
+		Lookup: func(ctx context.Context, code string) (*book.BookInfo, error) {
+			if calls != nil {
+				calls.Add(1)
+			}
+			info, ok := books[code]
+			if !ok {
+				return nil, book.ErrBookNotFound
+			}
+			return &info, nil
+		},
+	}
+}
+
+func countBookEvents(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM book_events`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+var testCatalog = map[string]book.BookInfo{
+	"9784873115658": {Title: "リーダブルコード", Authors: []string{"Dustin Boswell", "Trevor Foucher"}, Publisher: "オライリージャパン"},
+	"9780804429573": {Title: "Test Book", Authors: []string{"Author"}},
+}
+
+func TestImportBooks_WithIsbnList_ReportsEachRow(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewImportBooksService(db, []bookcode.NamedBookInfoSource{catalogSource(testCatalog, nil)})
+
+	// When importing ISBNs that are found, unknown, malformed and repeated
+	// then each row gets its own status and only found books are registered
+	data := []byte("978-4-87311-565-8\n9784000000000\n12345\n080442957X\n9784873115658\n")
+	report, err := service.ImportBooks(context.Background(), ImportBooksInput{Data: data})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []domain.RowStatus{
+		domain.RowStatusCreated,
+		domain.RowStatusNotFound,
+		domain.RowStatusInvalid,
+		domain.RowStatusCreated,
+		domain.RowStatusDuplicate,
+	}
+	if len(report.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(report.Rows))
+	}
+	for i, status := range want {
+		if report.Rows[i].Row != i+1 {
+			t.Errorf("row %d: expected row number %d, got %d", i, i+1, report.Rows[i].Row)
+		}
+		if report.Rows[i].Status != status {
+			t.Errorf("row %d: expected %s, got %s", i+1, status, report.Rows[i].Status)
+		}
+	}
+	if report.Rows[3].Code != "9780804429573" {
+		t.Errorf("expected ISBN-10 to be converted, got %s", report.Rows[3].Code)
+	}
+	if report.Rows[0].BookID == "" {
+		t.Error("expected book ID for created row")
+	}
+	if report.Created != 2 || report.NotFound != 1 || report.Invalid != 1 || report.Duplicate != 1 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	if count := countBookEvents(t, db); count != 2 {
+		t.Errorf("expected 2 book events, got %d", count)
+	}
+}
+
+func TestImportBooks_WithCsv_PrefersFileValues(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewImportBooksService(db, []bookcode.NamedBookInfoSource{catalogSource(testCatalog, nil)})
+
+	// When importing CSV rows with and without codes
+	// then file values take precedence and missing values are looked up
+	data := []byte("title,authors,isbn\n私のリーダブルコード,,9784873115658\n手書きの本,山田太郎;佐藤花子,\n")
+	report, err := service.ImportBooks(context.Background(), ImportBooksInput{Data: data})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Format != domain.ImportFormatCSV {
+		t.Errorf("expected csv format, got %s", report.Format)
+	}
+	if report.Created != 2 {
+		t.Fatalf("expected 2 created, got %+v", report.Rows)
+	}
+
+	var title, authors, publisher string
+	err = db.QueryRow(`SELECT title, authors, publisher FROM book_events WHERE book_id = ?`, report.Rows[0].BookID).Scan(&title, &authors, &publisher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title != "私のリーダブルコード" {
+		t.Errorf("expected title from file, got %s", title)
+	}
+	if authors != `["Dustin Boswell","Trevor Foucher"]` {
+		t.Errorf("expected looked up authors, got %s", authors)
+	}
+	if publisher != "オライリージャパン" {
+		t.Errorf("expected looked up publisher, got %s", publisher)
+	}
+}
+
+func TestImportBooks_WithRegisteredCode_ReturnsDuplicate(t *testing.T) {
+	db := setupTestDB(t)
+	_, err := db.Exec(
+		`INSERT INTO book_events (event_id, book_id, event_type, code, title, authors, occurred_at) VALUES (?, ?, 'created', '9784873115658', 'リーダブルコード', '[]', '2024-01-01T00:00:00Z')`,
+		uuid.New().String(), uuid.New().String(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewImportBooksService(db, []bookcode.NamedBookInfoSource{catalogSource(testCatalog, nil)})
+
+	// When importing a code that is already registered
+	// then the row is reported as duplicate
+	report, err := service.ImportBooks(context.Background(), ImportBooksInput{Data: []byte("9784873115658\n")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Rows[0].Status != domain.RowStatusDuplicate {
+		t.Errorf("expected duplicate, got %s", report.Rows[0].Status)
+	}
+}
+
+func TestImportBooks_WithDryRun_WritesNothing(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewImportBooksService(db, []bookcode.NamedBookInfoSource{catalogSource(testCatalog, nil)})
+
+	// When importing with dry run
+	// then the report is returned but neither books nor progress are stored
+	data := []byte("9784873115658\n9784873115658\n")
+	report, err := service.ImportBooks(context.Background(), ImportBooksInput{Data: data, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Created != 1 || report.Duplicate != 1 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	if count := countBookEvents(t, db); count != 0 {
+		t.Errorf("expected no book events, got %d", count)
+	}
+	var rows int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM import_rows`).Scan(&rows); err != nil {
+		t.Fatal(err)
+	}
+	if rows != 0 {
+		t.Errorf("expected no import rows, got %d", rows)
+	}
+}
+
+func TestImportBooks_RunAgain_ResumesWithoutLookups(t *testing.T) {
+	db := setupTestDB(t)
+	var calls atomic.Int32
+	service := NewImportBooksService(db, []bookcode.NamedBookInfoSource{catalogSource(testCatalog, &calls)})
+	data := []byte("9784873115658\n080442957X\n")
+
+	first, err := service.ImportBooks(context.Background(), ImportBooksInput{Data: data})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calls.Store(0)
+
+	// When the same data is imported again
+	// then processed rows are reused without lookups or new books
+	second, err := service.ImportBooks(context.Background(), ImportBooksInput{Data: data})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.ImportID != first.ImportID {
+		t.Errorf("expected same import ID, got %s and %s", first.ImportID, second.ImportID)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("expected no lookups, got %d", calls.Load())
+	}
+	for i, row := range second.Rows {
+		if !row.Resumed {
+			t.Errorf("row %d: expected resumed", row.Row)
+		}
+		if row.BookID != first.Rows[i].BookID {
+			t.Errorf("row %d: expected book ID %s, got %s", row.Row, first.Rows[i].BookID, row.BookID)
+		}
+	}
+	if count := countBookEvents(t, db); count != 2 {
+		t.Errorf("expected 2 book events, got %d", count)
+	}
+}
+
+func TestImportBooks_WhenCanceled_ResumesFromNextRow(t *testing.T) {
+	db := setupTestDB(t)
+	data := []byte("9784873115658\n080442957X\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	service := NewImportBooksService(db, []bookcode.NamedBookInfoSource{catalogSource(testCatalog, nil)})
+
+	// When the import is canceled after the first row
+	// then the first row is kept and the next run processes only the rest
+	report, err := service.ImportBooks(ctx, ImportBooksInput{
+		Data:        data,
+		Concurrency: 1,
+		OnRow:       func(RowResult) { cancel() },
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(report.Rows) != 1 {
+		t.Fatalf("expected 1 processed row, got %d", len(report.Rows))
+	}
+
+	resumed, err := service.ImportBooks(context.Background(), ImportBooksInput{Data: data})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resumed.Rows[0].Resumed || resumed.Rows[1].Resumed {
+		t.Errorf("expected only first row to be resumed: %+v", resumed.Rows)
+	}
+	if resumed.Created != 2 {
+		t.Errorf("expected 2 created, got %d", resumed.Created)
+	}
+}
+
+func TestImportBooks_WithMissingHeader_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewImportBooksService(db, nil)
+
+	// When importing CSV without title or code columns
+	// then ErrMissingHeader is returned
+	_, err := service.ImportBooks(context.Background(), ImportBooksInput{Format: "csv", Data: []byte("name,memo\na,b\n")})
+	if !errors.Is(err, domain.ErrMissingHeader) {
+		t.Errorf("expected ErrMissingHeader, got %v", err)
+	}
+}
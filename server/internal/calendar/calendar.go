@@ -0,0 +1,196 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Location is the time zone the library's days are counted in. The shelf is
+// in the Japan office, so it is also the zone of the built-in holiday table.
+var Location = time.FixedZone("Asia/Tokyo", 9*60*60)
+
+// maxClosedDays bounds the search for an open day, so that a calendar closed
+// for good does not loop forever.
+const maxClosedDays = 366
+
+var (
+	ErrInvalidWeekday  = errors.New("invalid weekday")
+	ErrInvalidClosure  = errors.New("invalid closure")
+	ErrAlwaysClosed    = errors.New("every weekday is closed")
+	ErrHolidaysUnknown = errors.New("japanese holidays are not in the table")
+)
+
+// Closure is a range of days the library is closed, such as the year-end
+// holidays or an office move. Both From and To are included.
+type Closure struct {
+	From time.Time
+	To   time.Time
+}
+
+func (c Closure) contains(day string) bool {
+	return c.From.Format(time.DateOnly) <= day && day <= c.To.Format(time.DateOnly)
+}
+
+// Calendar tells the days the shelf can be accessed. The zero value is open
+// every day.
+type Calendar struct {
+	closedWeekdays   map[time.Weekday]bool
+	japaneseHolidays bool
+	closures         []Closure
+}
+
+func New(closedWeekdays []time.Weekday, japaneseHolidays bool, closures []Closure) Calendar {
+	weekdays := map[time.Weekday]bool{}
+	for _, w := range closedWeekdays {
+		weekdays[w] = true
+	}
+	return Calendar{closedWeekdays: weekdays, japaneseHolidays: japaneseHolidays, closures: closures}
+}
+
+// Parse builds a calendar from the settings in LIBRARY_CLOSED_WEEKDAYS,
+// LIBRARY_JAPANESE_HOLIDAYS and LIBRARY_CLOSURES. Weekdays are comma separated
+// names such as "sat,sun", and both an empty setting and "none" keep the
+// library open on every weekday. Japanese holidays are only closed when the
+// setting is "true". Closures are comma separated dates or ranges of dates
+// joined by "/", such as "2025-12-27/2026-01-04,2026-08-14".
+func Parse(closedWeekdays, japaneseHolidays, closures string) (Calendar, error) {
+	weekdays, err := parseWeekdays(closedWeekdays)
+	if err != nil {
+		return Calendar{}, err
+	}
+	ranges, err := parseClosures(closures)
+	if err != nil {
+		return Calendar{}, err
+	}
+	holidays := false
+	switch strings.ToLower(strings.TrimSpace(japaneseHolidays)) {
+	case "", "false":
+	case "true":
+		holidays = true
+	default:
+		return Calendar{}, fmt.Errorf("invalid japanese holidays setting %q", japaneseHolidays)
+	}
+	return New(weekdays, holidays, ranges), nil
+}
+
+// weekdayNames maps both full and three letter English names to weekdays.
+var weekdayNames = func() map[string]time.Weekday {
+	names := map[string]time.Weekday{}
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		name := strings.ToLower(w.String())
+		names[name] = w
+		names[name[:3]] = w
+	}
+	return names
+}()
+
+func parseWeekdays(s string) ([]time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "none" {
+		return nil, nil
+	}
+	seen := map[time.Weekday]bool{}
+	var weekdays []time.Weekday
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		w, ok := weekdayNames[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidWeekday, name)
+		}
+		if !seen[w] {
+			seen[w] = true
+			weekdays = append(weekdays, w)
+		}
+	}
+	if len(weekdays) == 7 {
+		return nil, ErrAlwaysClosed
+	}
+	return weekdays, nil
+}
+
+func parseClosures(s string) ([]Closure, error) {
+	var closures []Closure
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(item, "/")
+		if !isRange {
+			to = from
+		}
+		fromDate, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(from), Location)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidClosure, item)
+		}
+		toDate, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(to), Location)
+		if err != nil || toDate.Before(fromDate) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidClosure, item)
+		}
+		closures = append(closures, Closure{From: fromDate, To: toDate})
+	}
+	return closures, nil
+}
+
+// CheckHolidays returns ErrHolidaysUnknown when the calendar closes on
+// Japanese holidays but the built-in table ends before t, in which case the
+// holidays after the table are treated as open days.
+func (c Calendar) CheckHolidays(t time.Time) error {
+	if !c.japaneseHolidays {
+		return nil
+	}
+	if t.In(Location).Format(time.DateOnly) > japaneseHolidaysUntil {
+		return fmt.Errorf("%w after %s", ErrHolidaysUnknown, japaneseHolidaysUntil)
+	}
+	return nil
+}
+
+// IsOpen reports whether the library is open on the day t falls on.
+func (c Calendar) IsOpen(t time.Time) bool {
+	local := t.In(Location)
+	if c.closedWeekdays[local.Weekday()] {
+		return false
+	}
+	day := local.Format(time.DateOnly)
+	if c.japaneseHolidays && IsJapaneseHoliday(local) {
+		return false
+	}
+	for _, closure := range c.closures {
+		if closure.contains(day) {
+			return false
+		}
+	}
+	return true
+}
+
+// NextOpenDay returns t when the library is open that day, and otherwise the
+// same time of day on the first open day after it.
+func (c Calendar) NextOpenDay(t time.Time) time.Time {
+	next := t
+	for i := 0; i < maxClosedDays && !c.IsOpen(next); i++ {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// OverdueCutoff returns the time loans due before it are overdue at now. A
+// loan falling due while the library is closed cannot be returned, so it only
+// becomes overdue when the library opens again: on a closed day the cutoff is
+// the start of the closed days leading up to it.
+func (c Calendar) OverdueCutoff(now time.Time) time.Time {
+	if c.IsOpen(now) {
+		return now
+	}
+	local := now.In(Location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, Location)
+	for i := 0; i < maxClosedDays; i++ {
+		previous := start.AddDate(0, 0, -1)
+		if c.IsOpen(previous) {
+			break
+		}
+		start = previous
+	}
+	return start.In(now.Location())
+}
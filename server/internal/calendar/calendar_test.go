@@ -0,0 +1,248 @@
+//go:build small
+
+package calendar
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func day(year int, month time.Month, d, hour int) time.Time {
+	return time.Date(year, month, d, hour, 0, 0, 0, Location)
+}
+
+func TestCalendar_ZeroValue_IsOpenEveryDay(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("open every day", prop.ForAll(
+		func(t time.Time) bool {
+			var c Calendar
+			return c.IsOpen(t) && c.NextOpenDay(t).Equal(t) && c.OverdueCutoff(t).Equal(t)
+		},
+		gen.Time(),
+	))
+	properties.TestingRun(t)
+}
+
+func TestCalendar_WithClosedWeekdays_IsClosedOnThem(t *testing.T) {
+	c := New([]time.Weekday{time.Saturday, time.Sunday}, false, nil)
+
+	if c.IsOpen(day(2025, 6, 7, 12)) {
+		t.Error("expected Saturday to be closed")
+	}
+	if c.IsOpen(day(2025, 6, 8, 12)) {
+		t.Error("expected Sunday to be closed")
+	}
+	if !c.IsOpen(day(2025, 6, 9, 12)) {
+		t.Error("expected Monday to be open")
+	}
+}
+
+func TestCalendar_WithUTCTime_CountsDaysInJapan(t *testing.T) {
+	c := New([]time.Weekday{time.Saturday, time.Sunday}, false, nil)
+
+	// Friday 20:00 UTC is already Saturday in Japan.
+	if c.IsOpen(time.Date(2025, 6, 6, 20, 0, 0, 0, time.UTC)) {
+		t.Error("expected Saturday in Japan to be closed")
+	}
+}
+
+func TestCalendar_WithJapaneseHolidays_IsClosedOnHolidays(t *testing.T) {
+	c := New(nil, true, nil)
+
+	if c.IsOpen(day(2026, 5, 4, 10)) {
+		t.Error("expected みどりの日 to be closed")
+	}
+	if c.IsOpen(day(2026, 9, 22, 10)) {
+		t.Error("expected the day between holidays to be closed")
+	}
+	if !c.IsOpen(day(2026, 5, 7, 10)) {
+		t.Error("expected the day after Golden Week to be open")
+	}
+	if !New(nil, false, nil).IsOpen(day(2026, 5, 4, 10)) {
+		t.Error("expected holidays to be open when disabled")
+	}
+}
+
+func TestCalendar_WithClosure_IsClosedOnEveryDayOfIt(t *testing.T) {
+	c := New(nil, false, []Closure{{From: day(2025, 12, 27, 0), To: day(2026, 1, 4, 0)}})
+
+	for d := day(2025, 12, 27, 23); !d.After(day(2026, 1, 4, 23)); d = d.AddDate(0, 0, 1) {
+		if c.IsOpen(d) {
+			t.Errorf("expected %s to be closed", d.Format(time.DateOnly))
+		}
+	}
+	if !c.IsOpen(day(2025, 12, 26, 10)) || !c.IsOpen(day(2026, 1, 5, 10)) {
+		t.Error("expected the days around the closure to be open")
+	}
+}
+
+func TestCalendar_NextOpenDay_KeepsTimeOfDay(t *testing.T) {
+	c := New([]time.Weekday{time.Saturday, time.Sunday}, true, nil)
+
+	// Saturday, Sunday and the substitute holiday on Monday.
+	got := c.NextOpenDay(day(2025, 2, 22, 15))
+
+	if want := day(2025, 2, 25, 15); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestCalendar_NextOpenDay_ReturnsOpenDay(t *testing.T) {
+	c := New([]time.Weekday{time.Saturday, time.Sunday}, true, []Closure{{From: day(2025, 12, 27, 0), To: day(2026, 1, 4, 0)}})
+	properties := gopter.NewProperties(nil)
+	properties.Property("is open and not earlier", prop.ForAll(
+		func(hours int64) bool {
+			t := day(2024, 1, 1, 0).Add(time.Duration(hours) * time.Hour)
+			next := c.NextOpenDay(t)
+			return c.IsOpen(next) && !next.Before(t) && next.Sub(t) < 10*24*time.Hour
+		},
+		gen.Int64Range(0, 4*365*24),
+	))
+	properties.TestingRun(t)
+}
+
+func TestCalendar_OverdueCutoff_OnOpenDay_ReturnsNow(t *testing.T) {
+	c := New([]time.Weekday{time.Saturday, time.Sunday}, false, nil)
+	now := day(2025, 6, 9, 10)
+
+	if got := c.OverdueCutoff(now); !got.Equal(now) {
+		t.Errorf("expected %v, got %v", now, got)
+	}
+}
+
+func TestCalendar_OverdueCutoff_OnClosedDay_ReturnsStartOfClosedDays(t *testing.T) {
+	c := New([]time.Weekday{time.Saturday, time.Sunday}, false, nil)
+
+	got := c.OverdueCutoff(time.Date(2025, 6, 8, 3, 0, 0, 0, time.UTC))
+
+	if want := day(2025, 6, 7, 0); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got.Location() != time.UTC {
+		t.Errorf("expected the location of now, got %v", got.Location())
+	}
+}
+
+func TestJapaneseHolidays_SubstituteHolidays_FollowHoliday(t *testing.T) {
+	for date, name := range japaneseHolidays {
+		if name != "休日" {
+			continue
+		}
+		d, err := time.ParseInLocation(time.DateOnly, date, Location)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !IsJapaneseHoliday(d.AddDate(0, 0, -1)) {
+			t.Errorf("expected the day before %s to be a holiday", date)
+		}
+		if d.Weekday() == time.Sunday || d.Weekday() == time.Saturday {
+			t.Errorf("expected %s to be a weekday", date)
+		}
+	}
+}
+
+func TestParse_WithEmptySettings_OpensEveryDay(t *testing.T) {
+	c, err := Parse("", "", "")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, d := range []time.Time{day(2025, 6, 7, 12), day(2025, 6, 8, 12), day(2025, 7, 21, 12)} {
+		if !c.IsOpen(d) {
+			t.Errorf("expected %s to be open", d.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestParse_WithJapaneseHolidays_ClosesHolidays(t *testing.T) {
+	c, err := Parse("", "true", "")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.IsOpen(day(2025, 7, 21, 12)) {
+		t.Error("expected 海の日 to be closed")
+	}
+	if !c.IsOpen(day(2025, 6, 7, 12)) {
+		t.Error("expected Saturday to be open")
+	}
+}
+
+func TestParse_WithSettings_BuildsCalendar(t *testing.T) {
+	c, err := Parse(" Sunday, wed ", "false", "2025-08-13/2025-08-15, 2025-12-29")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	closed := []time.Time{day(2025, 6, 8, 12), day(2025, 6, 11, 12), day(2025, 8, 14, 12), day(2025, 12, 29, 12)}
+	for _, d := range closed {
+		if c.IsOpen(d) {
+			t.Errorf("expected %s to be closed", d.Format(time.DateOnly))
+		}
+	}
+	open := []time.Time{day(2025, 6, 7, 12), day(2025, 7, 21, 12), day(2025, 8, 16, 12)}
+	for _, d := range open {
+		if !c.IsOpen(d) {
+			t.Errorf("expected %s to be open", d.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestParse_WithNone_OpensEveryWeekday(t *testing.T) {
+	c, err := Parse("none", "true", "")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.IsOpen(day(2025, 6, 7, 12)) {
+		t.Error("expected Saturday to be open")
+	}
+}
+
+func TestParse_WithInvalidSettings_ReturnsError(t *testing.T) {
+	tests := []struct {
+		name           string
+		closedWeekdays string
+		holidays       string
+		closures       string
+		want           error
+	}{
+		{"unknown weekday", "sat,holiday", "", "", ErrInvalidWeekday},
+		{"every weekday", "sun,mon,tue,wed,thu,fri,sat", "", "", ErrAlwaysClosed},
+		{"invalid date", "", "", "2025-13-01", ErrInvalidClosure},
+		{"reversed range", "", "", "2025-08-15/2025-08-13", ErrInvalidClosure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.closedWeekdays, tt.holidays, tt.closures)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+	if _, err := Parse("", "yes", ""); err == nil {
+		t.Error("expected error for invalid holiday setting")
+	}
+}
+
+func TestCalendar_CheckHolidays_AfterTable_ReturnsError(t *testing.T) {
+	last, err := time.ParseInLocation(time.DateOnly, japaneseHolidaysUntil, Location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := New(nil, true, nil).CheckHolidays(last); err != nil {
+		t.Errorf("expected the last day of the table to be covered, got %v", err)
+	}
+	if err := New(nil, true, nil).CheckHolidays(last.AddDate(0, 0, 1)); !errors.Is(err, ErrHolidaysUnknown) {
+		t.Errorf("expected ErrHolidaysUnknown, got %v", err)
+	}
+	if err := New(nil, false, nil).CheckHolidays(last.AddDate(1, 0, 0)); err != nil {
+		t.Errorf("expected no error without holidays, got %v", err)
+	}
+}
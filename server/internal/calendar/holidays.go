@@ -0,0 +1,97 @@
+package calendar
+
+import "time"
+
+// japaneseHolidaysUntil is the last day the table below covers.
+const japaneseHolidaysUntil = "2027-12-31"
+
+// japaneseHolidays are the national holidays of Japan, including substitute
+// holidays and the days between two holidays. Equinox days are announced a
+// year ahead, so the table and japaneseHolidaysUntil have to be extended every
+// year; until then a closure can cover a missing holiday.
+var japaneseHolidays = map[string]string{
+	"2024-01-01": "元日",
+	"2024-01-08": "成人の日",
+	"2024-02-11": "建国記念の日",
+	"2024-02-12": "休日",
+	"2024-02-23": "天皇誕生日",
+	"2024-03-20": "春分の日",
+	"2024-04-29": "昭和の日",
+	"2024-05-03": "憲法記念日",
+	"2024-05-04": "みどりの日",
+	"2024-05-05": "こどもの日",
+	"2024-05-06": "休日",
+	"2024-07-15": "海の日",
+	"2024-08-11": "山の日",
+	"2024-08-12": "休日",
+	"2024-09-16": "敬老の日",
+	"2024-09-22": "秋分の日",
+	"2024-09-23": "休日",
+	"2024-10-14": "スポーツの日",
+	"2024-11-03": "文化の日",
+	"2024-11-04": "休日",
+	"2024-11-23": "勤労感謝の日",
+
+	"2025-01-01": "元日",
+	"2025-01-13": "成人の日",
+	"2025-02-11": "建国記念の日",
+	"2025-02-23": "天皇誕生日",
+	"2025-02-24": "休日",
+	"2025-03-20": "春分の日",
+	"2025-04-29": "昭和の日",
+	"2025-05-03": "憲法記念日",
+	"2025-05-04": "みどりの日",
+	"2025-05-05": "こどもの日",
+	"2025-05-06": "休日",
+	"2025-07-21": "海の日",
+	"2025-08-11": "山の日",
+	"2025-09-15": "敬老の日",
+	"2025-09-23": "秋分の日",
+	"2025-10-13": "スポーツの日",
+	"2025-11-03": "文化の日",
+	"2025-11-23": "勤労感謝の日",
+	"2025-11-24": "休日",
+
+	"2026-01-01": "元日",
+	"2026-01-12": "成人の日",
+	"2026-02-11": "建国記念の日",
+	"2026-02-23": "天皇誕生日",
+	"2026-03-20": "春分の日",
+	"2026-04-29": "昭和の日",
+	"2026-05-03": "憲法記念日",
+	"2026-05-04": "みどりの日",
+	"2026-05-05": "こどもの日",
+	"2026-05-06": "休日",
+	"2026-07-20": "海の日",
+	"2026-08-11": "山の日",
+	"2026-09-21": "敬老の日",
+	"2026-09-22": "休日",
+	"2026-09-23": "秋分の日",
+	"2026-10-12": "スポーツの日",
+	"2026-11-03": "文化の日",
+	"2026-11-23": "勤労感謝の日",
+
+	"2027-01-01": "元日",
+	"2027-01-11": "成人の日",
+	"2027-02-11": "建国記念の日",
+	"2027-02-23": "天皇誕生日",
+	"2027-03-21": "春分の日",
+	"2027-03-22": "休日",
+	"2027-04-29": "昭和の日",
+	"2027-05-03": "憲法記念日",
+	"2027-05-04": "みどりの日",
+	"2027-05-05": "こどもの日",
+	"2027-07-19": "海の日",
+	"2027-08-11": "山の日",
+	"2027-09-20": "敬老の日",
+	"2027-09-23": "秋分の日",
+	"2027-10-11": "スポーツの日",
+	"2027-11-03": "文化の日",
+	"2027-11-23": "勤労感謝の日",
+}
+
+// IsJapaneseHoliday reports whether t falls on a Japanese national holiday.
+func IsJapaneseHoliday(t time.Time) bool {
+	_, ok := japaneseHolidays[t.In(Location).Format(time.DateOnly)]
+	return ok
+}
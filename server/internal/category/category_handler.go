@@ -0,0 +1,146 @@
+package category
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"holocron/internal/category/domain"
+)
+
+type ListCategoriesHandler struct {
+	queries *Queries
+}
+
+func NewListCategoriesHandler(queries *Queries) *ListCategoriesHandler {
+	return &ListCategoriesHandler{queries: queries}
+}
+
+func (h *ListCategoriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tree, err := ListCategories(r.Context(), h.queries)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"categories": toCategoryNodesResponse(tree),
+	})
+}
+
+func toCategoryNodesResponse(nodes []domain.CategoryNode) []map[string]any {
+	resp := make([]map[string]any, 0, len(nodes))
+	for _, node := range nodes {
+		m := map[string]any{
+			"id":       node.ID,
+			"name":     node.Name,
+			"children": toCategoryNodesResponse(node.Children),
+		}
+		if node.Code != nil {
+			m["code"] = *node.Code
+		}
+		resp = append(resp, m)
+	}
+	return resp
+}
+
+type CreateCategoryHandler struct {
+	queries *Queries
+}
+
+func NewCreateCategoryHandler(queries *Queries) *CreateCategoryHandler {
+	return &CreateCategoryHandler{queries: queries}
+}
+
+func (h *CreateCategoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string  `json:"name"`
+		Code     *string `json:"code"`
+		ParentID *string `json:"parentId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	output, err := CreateCategory(r.Context(), h.queries, CreateCategoryInput{
+		Name:     req.Name,
+		Code:     req.Code,
+		ParentID: req.ParentID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidCategoryName):
+			writeError(w, http.StatusBadRequest, "invalid_request", "name must be 1-100 characters")
+		case errors.Is(err, domain.ErrInvalidCategoryCode):
+			writeError(w, http.StatusBadRequest, "invalid_request", "code must be at most 20 characters")
+		case errors.Is(err, ErrParentCategoryNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "parent category not found")
+		case errors.Is(err, ErrCategoryAlreadyExists):
+			writeError(w, http.StatusConflict, "conflict", "a category with the same name already exists under the parent")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	resp := map[string]any{
+		"id":   output.ID,
+		"name": output.Name,
+	}
+	if output.Code != nil {
+		resp["code"] = *output.Code
+	}
+	if output.ParentID != nil {
+		resp["parentId"] = *output.ParentID
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type SeedCategoriesHandler struct {
+	queries *Queries
+}
+
+func NewSeedCategoriesHandler(queries *Queries) *SeedCategoriesHandler {
+	return &SeedCategoriesHandler{queries: queries}
+}
+
+func (h *SeedCategoriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Scheme string `json:"scheme"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	output, err := SeedCategories(r.Context(), h.queries, req.Scheme)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownClassificationScheme) {
+			writeError(w, http.StatusBadRequest, "invalid_request", "scheme must be ndc or dewey")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"rootId":  output.RootID,
+		"created": output.Created,
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
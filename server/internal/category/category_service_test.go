@@ -0,0 +1,154 @@
+//go:build medium
+
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"holocron/internal/category/domain"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE category_events (
+			event_id TEXT PRIMARY KEY,
+			category_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			parent_id TEXT,
+			code TEXT,
+			name TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE INDEX idx_category_events_category_id ON category_events(category_id);
+		CREATE INDEX idx_category_events_parent_id ON category_events(parent_id);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestCreateCategory_WithParent_ReturnsCategoryInTree(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	code := "900"
+	parent, err := CreateCategory(ctx, queries, CreateCategoryInput{Name: "文学", Code: &code})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	child, err := CreateCategory(ctx, queries, CreateCategoryInput{Name: " 日本文学 ", ParentID: &parent.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if child.Name != "日本文学" {
+		t.Errorf("expected trimmed name, got %q", child.Name)
+	}
+
+	tree, err := ListCategories(ctx, queries)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tree) != 1 || tree[0].ID != parent.ID || *tree[0].Code != "900" {
+		t.Fatalf("unexpected roots %+v", tree)
+	}
+	if len(tree[0].Children) != 1 || tree[0].Children[0].ID != child.ID {
+		t.Errorf("unexpected children %+v", tree[0].Children)
+	}
+}
+
+func TestCreateCategory_WithNonExistentParent_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	parentID := "missing"
+	_, err := CreateCategory(ctx, queries, CreateCategoryInput{Name: "日本文学", ParentID: &parentID})
+
+	if !errors.Is(err, ErrParentCategoryNotFound) {
+		t.Errorf("expected ErrParentCategoryNotFound, got %v", err)
+	}
+}
+
+func TestCreateCategory_WithSameNameUnderSameParent_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	if _, err := CreateCategory(ctx, queries, CreateCategoryInput{Name: "文学"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := CreateCategory(ctx, queries, CreateCategoryInput{Name: "文学"})
+
+	if !errors.Is(err, ErrCategoryAlreadyExists) {
+		t.Errorf("expected ErrCategoryAlreadyExists, got %v", err)
+	}
+}
+
+func TestCreateCategory_WithInvalidName_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, err := CreateCategory(ctx, queries, CreateCategoryInput{Name: " "})
+
+	if !errors.Is(err, domain.ErrInvalidCategoryName) {
+		t.Errorf("expected ErrInvalidCategoryName, got %v", err)
+	}
+}
+
+func TestSeedCategories_WhenSeededTwice_CreatesCategoriesOnce(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	first, err := SeedCategories(ctx, queries, "ndc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Created != 11 {
+		t.Errorf("expected 11 categories to be created, got %d", first.Created)
+	}
+
+	second, err := SeedCategories(ctx, queries, "ndc")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.Created != 0 || second.RootID != first.RootID {
+		t.Errorf("expected nothing to be created under %s, got %+v", first.RootID, second)
+	}
+	tree, err := ListCategories(ctx, queries)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tree) != 1 || len(tree[0].Children) != 10 || *tree[0].Children[0].Code != "000" {
+		t.Errorf("unexpected tree %+v", tree)
+	}
+}
+
+func TestSeedCategories_WithUnknownScheme_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	_, err := SeedCategories(ctx, queries, "lcc")
+
+	if !errors.Is(err, domain.ErrUnknownClassificationScheme) {
+		t.Errorf("expected ErrUnknownClassificationScheme, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"holocron/internal/category/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrParentCategoryNotFound = errors.New("parent category not found")
+	ErrCategoryAlreadyExists  = errors.New("category already exists")
+)
+
+type CreateCategoryInput struct {
+	Name     string
+	Code     *string
+	ParentID *string
+}
+
+type CreateCategoryOutput struct {
+	ID       string
+	ParentID *string
+	Code     *string
+	Name     string
+}
+
+func CreateCategory(ctx context.Context, queries *Queries, input CreateCategoryInput) (*CreateCategoryOutput, error) {
+	name, err := domain.ParseCategoryName(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	code, err := domain.ParseCategoryCode(input.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.ParentID != nil {
+		count, err := queries.CountCategoryByCategoryId(ctx, *input.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, ErrParentCategoryNotFound
+		}
+	}
+
+	// Siblings are told apart by name, so a name is unique under its parent.
+	_, err = queries.GetCategoryIdByParentAndName(ctx, GetCategoryIdByParentAndNameParams{
+		ParentID: toNullString(input.ParentID),
+		Name:     string(name),
+	})
+	if err == nil {
+		return nil, ErrCategoryAlreadyExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	categoryID := uuid.New().String()
+	err = queries.InsertCategoryEvent(ctx, InsertCategoryEventParams{
+		EventID:    uuid.New().String(),
+		CategoryID: categoryID,
+		ParentID:   toNullString(input.ParentID),
+		Code:       toNullString(code),
+		Name:       string(name),
+		OccurredAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateCategoryOutput{
+		ID:       categoryID,
+		ParentID: input.ParentID,
+		Code:     code,
+		Name:     string(name),
+	}, nil
+}
+
+func toNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
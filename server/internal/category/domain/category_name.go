@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidCategoryName = errors.New("category name must be 1-100 characters")
+	ErrInvalidCategoryCode = errors.New("category code must be at most 20 characters")
+)
+
+type CategoryName string
+
+func ParseCategoryName(s string) (CategoryName, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || utf8.RuneCountInString(s) > 100 {
+		return "", ErrInvalidCategoryName
+	}
+	return CategoryName(s), nil
+}
+
+// ParseCategoryCode parses an optional class number such as "913" for NDC.
+// A blank code means no code.
+func ParseCategoryCode(s *string) (*string, error) {
+	if s == nil {
+		return nil, nil
+	}
+	code := strings.TrimSpace(*s)
+	if code == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(code) > 20 {
+		return nil, ErrInvalidCategoryCode
+	}
+	return &code, nil
+}
@@ -0,0 +1,58 @@
+//go:build small
+
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCategoryName_WithValidName_ReturnsTrimmedName(t *testing.T) {
+	name, err := ParseCategoryName(" 日本文学 ")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "日本文学" {
+		t.Errorf("expected 日本文学, got %s", name)
+	}
+}
+
+func TestParseCategoryName_WithBlankName_ReturnsError(t *testing.T) {
+	_, err := ParseCategoryName("  ")
+
+	if err != ErrInvalidCategoryName {
+		t.Errorf("expected ErrInvalidCategoryName, got %v", err)
+	}
+}
+
+func TestParseCategoryName_WithTooLongName_ReturnsError(t *testing.T) {
+	_, err := ParseCategoryName(strings.Repeat("あ", 101))
+
+	if err != ErrInvalidCategoryName {
+		t.Errorf("expected ErrInvalidCategoryName, got %v", err)
+	}
+}
+
+func TestParseCategoryCode_WithBlankCode_ReturnsNil(t *testing.T) {
+	input := " "
+
+	code, err := ParseCategoryCode(&input)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if code != nil {
+		t.Errorf("expected nil, got %q", *code)
+	}
+}
+
+func TestParseCategoryCode_WithTooLongCode_ReturnsError(t *testing.T) {
+	input := strings.Repeat("9", 21)
+
+	_, err := ParseCategoryCode(&input)
+
+	if err != ErrInvalidCategoryCode {
+		t.Errorf("expected ErrInvalidCategoryCode, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"cmp"
+	"slices"
+)
+
+type Category struct {
+	ID       string
+	ParentID *string
+	Code     *string
+	Name     string
+}
+
+type CategoryNode struct {
+	Category
+	Children []CategoryNode
+}
+
+// BuildCategoryTree arranges categories under their parents. Siblings are ordered
+// by code, then by name, with uncoded categories last. A category whose parent is
+// unknown becomes a root.
+func BuildCategoryTree(categories []Category) []CategoryNode {
+	known := map[string]bool{}
+	for _, c := range categories {
+		known[c.ID] = true
+	}
+	children := map[string][]Category{}
+	var roots []Category
+	for _, c := range categories {
+		if c.ParentID == nil || !known[*c.ParentID] {
+			roots = append(roots, c)
+			continue
+		}
+		children[*c.ParentID] = append(children[*c.ParentID], c)
+	}
+
+	var build func(level []Category) []CategoryNode
+	build = func(level []Category) []CategoryNode {
+		slices.SortStableFunc(level, compareCategories)
+		nodes := make([]CategoryNode, 0, len(level))
+		for _, c := range level {
+			nodes = append(nodes, CategoryNode{Category: c, Children: build(children[c.ID])})
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+func compareCategories(a, b Category) int {
+	switch {
+	case a.Code != nil && b.Code == nil:
+		return -1
+	case a.Code == nil && b.Code != nil:
+		return 1
+	case a.Code != nil && b.Code != nil:
+		if c := cmp.Compare(*a.Code, *b.Code); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.Name, b.Name)
+}
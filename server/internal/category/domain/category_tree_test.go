@@ -0,0 +1,94 @@
+//go:build small
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func ptr(s string) *string {
+	return &s
+}
+
+func names(nodes []CategoryNode) []string {
+	var result []string
+	for _, node := range nodes {
+		result = append(result, node.Name)
+	}
+	return result
+}
+
+func TestBuildCategoryTree_WithNestedCategories_ArrangesChildrenUnderParents(t *testing.T) {
+	tree := BuildCategoryTree([]Category{
+		{ID: "c2", ParentID: ptr("c1"), Code: ptr("910"), Name: "日本文学"},
+		{ID: "c1", Code: ptr("900"), Name: "文学"},
+		{ID: "c3", ParentID: ptr("c2"), Code: ptr("913"), Name: "小説"},
+	})
+
+	if len(tree) != 1 || tree[0].ID != "c1" {
+		t.Fatalf("expected a single root c1, got %v", names(tree))
+	}
+	if len(tree[0].Children) != 1 || tree[0].Children[0].ID != "c2" {
+		t.Fatalf("expected c2 under c1, got %v", names(tree[0].Children))
+	}
+	if len(tree[0].Children[0].Children) != 1 || tree[0].Children[0].Children[0].ID != "c3" {
+		t.Errorf("expected c3 under c2, got %v", names(tree[0].Children[0].Children))
+	}
+}
+
+func TestBuildCategoryTree_WithSiblings_OrdersByCodeThenNameWithUncodedLast(t *testing.T) {
+	tree := BuildCategoryTree([]Category{
+		{ID: "a", Name: "あ"},
+		{ID: "b", Code: ptr("200"), Name: "歴史"},
+		{ID: "c", Code: ptr("100"), Name: "哲学"},
+		{ID: "d", Name: "い"},
+	})
+
+	got := names(tree)
+	want := []string{"哲学", "歴史", "あ", "い"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestBuildCategoryTree_WithUnknownParent_ReturnsCategoryAsRoot(t *testing.T) {
+	tree := BuildCategoryTree([]Category{
+		{ID: "c1", ParentID: ptr("missing"), Name: "孤立"},
+	})
+
+	if len(tree) != 1 || tree[0].ID != "c1" {
+		t.Errorf("expected c1 as root, got %v", names(tree))
+	}
+}
+
+func TestBuildCategoryTree_WithFlatCategories_KeepsEveryCategory(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("every category appears once in the tree", prop.ForAll(
+		func(count int) bool {
+			categories := make([]Category, count)
+			for i := range categories {
+				categories[i] = Category{ID: string(rune('a' + i)), Name: string(rune('z' - i))}
+				if i > 0 {
+					categories[i].ParentID = ptr(string(rune('a' + i/2)))
+				}
+			}
+			var countNodes func(nodes []CategoryNode) int
+			countNodes = func(nodes []CategoryNode) int {
+				n := len(nodes)
+				for _, node := range nodes {
+					n += countNodes(node.Children)
+				}
+				return n
+			}
+			return countNodes(BuildCategoryTree(categories)) == count
+		},
+		gen.IntRange(0, 20),
+	))
+	properties.TestingRun(t)
+}
@@ -0,0 +1,70 @@
+package domain
+
+import "errors"
+
+var ErrUnknownClassificationScheme = errors.New("classification scheme must be ndc or dewey")
+
+// ClassificationScheme is a standard library classification that the category
+// tree can be seeded with.
+type ClassificationScheme string
+
+const (
+	ClassificationNDC   ClassificationScheme = "ndc"
+	ClassificationDewey ClassificationScheme = "dewey"
+)
+
+type SeedCategory struct {
+	Code string
+	Name string
+}
+
+// ClassificationSeed is a scheme as a root category and its main classes.
+type ClassificationSeed struct {
+	Root    SeedCategory
+	Classes []SeedCategory
+}
+
+var classificationSeeds = map[ClassificationScheme]ClassificationSeed{
+	ClassificationNDC: {
+		Root: SeedCategory{Code: "NDC", Name: "日本十進分類法"},
+		Classes: []SeedCategory{
+			{Code: "000", Name: "総記"},
+			{Code: "100", Name: "哲学"},
+			{Code: "200", Name: "歴史"},
+			{Code: "300", Name: "社会科学"},
+			{Code: "400", Name: "自然科学"},
+			{Code: "500", Name: "技術"},
+			{Code: "600", Name: "産業"},
+			{Code: "700", Name: "芸術"},
+			{Code: "800", Name: "言語"},
+			{Code: "900", Name: "文学"},
+		},
+	},
+	ClassificationDewey: {
+		Root: SeedCategory{Code: "DDC", Name: "Dewey Decimal Classification"},
+		Classes: []SeedCategory{
+			{Code: "000", Name: "Computer science, information and general works"},
+			{Code: "100", Name: "Philosophy and psychology"},
+			{Code: "200", Name: "Religion"},
+			{Code: "300", Name: "Social sciences"},
+			{Code: "400", Name: "Language"},
+			{Code: "500", Name: "Science"},
+			{Code: "600", Name: "Technology"},
+			{Code: "700", Name: "Arts and recreation"},
+			{Code: "800", Name: "Literature"},
+			{Code: "900", Name: "History and geography"},
+		},
+	},
+}
+
+func ParseClassificationScheme(s string) (ClassificationScheme, error) {
+	scheme := ClassificationScheme(s)
+	if _, ok := classificationSeeds[scheme]; !ok {
+		return "", ErrUnknownClassificationScheme
+	}
+	return scheme, nil
+}
+
+func (s ClassificationScheme) Seed() ClassificationSeed {
+	return classificationSeeds[s]
+}
@@ -0,0 +1,25 @@
+//go:build small
+
+package domain
+
+import "testing"
+
+func TestParseClassificationScheme_WithKnownScheme_ReturnsSeed(t *testing.T) {
+	for _, s := range []string{"ndc", "dewey"} {
+		scheme, err := ParseClassificationScheme(s)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", s, err)
+		}
+		if seed := scheme.Seed(); len(seed.Classes) != 10 {
+			t.Errorf("expected 10 main classes for %s, got %d", s, len(seed.Classes))
+		}
+	}
+}
+
+func TestParseClassificationScheme_WithUnknownScheme_ReturnsError(t *testing.T) {
+	_, err := ParseClassificationScheme("lcc")
+
+	if err != ErrUnknownClassificationScheme {
+		t.Errorf("expected ErrUnknownClassificationScheme, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package category
+
+import (
+	"context"
+
+	"holocron/internal/category/domain"
+)
+
+func ListCategories(ctx context.Context, queries *Queries) ([]domain.CategoryNode, error) {
+	rows, err := queries.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]domain.Category, 0, len(rows))
+	for _, row := range rows {
+		c := domain.Category{ID: row.CategoryID, Name: row.Name}
+		if row.ParentID.Valid {
+			c.ParentID = &row.ParentID.String
+		}
+		if row.Code.Valid {
+			c.Code = &row.Code.String
+		}
+		categories = append(categories, c)
+	}
+	return domain.BuildCategoryTree(categories), nil
+}
@@ -0,0 +1,77 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"holocron/internal/category/domain"
+
+	"github.com/google/uuid"
+)
+
+type SeedCategoriesOutput struct {
+	RootID  string
+	Created int
+}
+
+// SeedCategories adds a classification scheme as a root category with its main
+// classes below it. Categories that already exist are kept, so seeding again only
+// adds what is missing.
+func SeedCategories(ctx context.Context, queries *Queries, scheme string) (*SeedCategoriesOutput, error) {
+	parsed, err := domain.ParseClassificationScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	seed := parsed.Seed()
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	output := &SeedCategoriesOutput{}
+	rootID, created, err := ensureCategory(ctx, queries, nil, seed.Root, now)
+	if err != nil {
+		return nil, err
+	}
+	output.RootID = rootID
+	if created {
+		output.Created++
+	}
+
+	for _, class := range seed.Classes {
+		_, created, err := ensureCategory(ctx, queries, &rootID, class, now)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			output.Created++
+		}
+	}
+	return output, nil
+}
+
+func ensureCategory(ctx context.Context, queries *Queries, parentID *string, seed domain.SeedCategory, now string) (string, bool, error) {
+	categoryID, err := queries.GetCategoryIdByParentAndName(ctx, GetCategoryIdByParentAndNameParams{
+		ParentID: toNullString(parentID),
+		Name:     seed.Name,
+	})
+	if err == nil {
+		return categoryID, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", false, err
+	}
+
+	categoryID = uuid.New().String()
+	err = queries.InsertCategoryEvent(ctx, InsertCategoryEventParams{
+		EventID:    uuid.New().String(),
+		CategoryID: categoryID,
+		ParentID:   toNullString(parentID),
+		Code:       sql.NullString{String: seed.Code, Valid: true},
+		Name:       seed.Name,
+		OccurredAt: now,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return categoryID, true, nil
+}
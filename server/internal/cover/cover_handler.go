@@ -0,0 +1,151 @@
+package cover
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"holocron/internal/api"
+	"holocron/internal/cover/domain"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type GetCoverHandler struct {
+	service *GetCoverService
+}
+
+func NewGetCoverHandler(service *GetCoverService) *GetCoverHandler {
+	return &GetCoverHandler{service: service}
+}
+
+func (h *GetCoverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID, params api.GetBooksCoverParams) {
+	input := GetCoverInput{BookID: bookId.String()}
+	if params.Size != nil {
+		input.Size = string(*params.Size)
+	}
+
+	output, err := h.service.GetCover(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidCoverSize):
+			writeError(w, http.StatusBadRequest, "invalid_request", "size must be small, medium or original")
+		case errors.Is(err, ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "book not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", output.ContentType)
+	w.Header().Set("ETag", output.ETag)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	if output.Placeholder {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	}
+	http.ServeContent(w, r, "", output.ModifiedAt, bytes.NewReader(output.Data))
+}
+
+type UploadCoverHandler struct {
+	service *UploadCoverService
+}
+
+func NewUploadCoverHandler(service *UploadCoverService) *UploadCoverHandler {
+	return &UploadCoverHandler{service: service}
+}
+
+func (h *UploadCoverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	data, err := readUpload(w, r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "payload_too_large", "image must not exceed 5MB")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+	if len(data) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "image must not be empty")
+		return
+	}
+
+	output, err := h.service.UploadCover(r.Context(), UploadCoverInput{
+		BookID: bookId.String(),
+		Data:   data,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "book not found")
+		case errors.Is(err, domain.ErrUnsupportedImageType):
+			writeError(w, http.StatusUnsupportedMediaType, "unsupported_media_type", "image must be JPEG, PNG or WebP")
+		case errors.Is(err, ErrInvalidImage):
+			writeError(w, http.StatusBadRequest, "invalid_request", "image could not be decoded")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"coverId":     output.CoverID,
+		"contentType": output.ContentType,
+		"width":       output.Width,
+		"height":      output.Height,
+		"uploadedAt":  output.UploadedAt.Format(time.RFC3339),
+	})
+}
+
+// readUpload reads the image from a raw body or from the "file" part of a multipart form.
+func readUpload(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadBytes+1<<10)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return readLimited(r.Body)
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return readLimited(part)
+		}
+	}
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxUploadBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxUploadBytes {
+		return nil, &http.MaxBytesError{Limit: MaxUploadBytes}
+	}
+	return data, nil
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
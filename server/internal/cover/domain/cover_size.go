@@ -0,0 +1,49 @@
+package domain
+
+import "errors"
+
+var ErrInvalidCoverSize = errors.New("invalid cover size")
+
+type CoverSize string
+
+const (
+	CoverSizeSmall    CoverSize = "small"
+	CoverSizeMedium   CoverSize = "medium"
+	CoverSizeOriginal CoverSize = "original"
+)
+
+var ResizedCoverSizes = []CoverSize{CoverSizeSmall, CoverSizeMedium}
+
+func ParseCoverSize(s string) (CoverSize, error) {
+	switch CoverSize(s) {
+	case "":
+		return CoverSizeOriginal, nil
+	case CoverSizeSmall, CoverSizeMedium, CoverSizeOriginal:
+		return CoverSize(s), nil
+	}
+	return "", ErrInvalidCoverSize
+}
+
+// MaxWidth returns the width the size is scaled down to, or 0 for the original.
+func (s CoverSize) MaxWidth() int {
+	switch s {
+	case CoverSizeSmall:
+		return 128
+	case CoverSizeMedium:
+		return 320
+	}
+	return 0
+}
+
+// FitWidth scales width and height down to maxWidth keeping the aspect ratio.
+// Images narrower than maxWidth are never scaled up.
+func FitWidth(width, height, maxWidth int) (int, int) {
+	if maxWidth <= 0 || width <= maxWidth || width <= 0 {
+		return width, height
+	}
+	scaled := height * maxWidth / width
+	if scaled < 1 {
+		scaled = 1
+	}
+	return maxWidth, scaled
+}
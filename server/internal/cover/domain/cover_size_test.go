@@ -0,0 +1,61 @@
+//go:build small
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestParseCoverSize_WithEmpty_ReturnsOriginal(t *testing.T) {
+	size, err := ParseCoverSize("")
+	if err != nil || size != CoverSizeOriginal {
+		t.Errorf("expected original, got %q (%v)", size, err)
+	}
+}
+
+func TestParseCoverSize_WithUnknownSize_ReturnsError(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("returns ErrInvalidCoverSize for unknown sizes", prop.ForAll(
+		func(s string) bool {
+			_, err := ParseCoverSize(s)
+			return err == ErrInvalidCoverSize
+		},
+		gen.AlphaString().SuchThat(func(s string) bool {
+			return s != "" && s != "small" && s != "medium" && s != "original"
+		}),
+	))
+	properties.TestingRun(t)
+}
+
+func TestFitWidth_WithWideImage_ScalesDownToMaxWidth(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("width equals maxWidth and aspect ratio is kept", prop.ForAll(
+		func(maxWidth, extra, height int) bool {
+			width := maxWidth + extra
+			w, h := FitWidth(width, height, maxWidth)
+			return w == maxWidth && h >= 1 && h <= height
+		},
+		gen.IntRange(1, 1000),
+		gen.IntRange(1, 3000),
+		gen.IntRange(1, 4000),
+	))
+	properties.TestingRun(t)
+}
+
+func TestFitWidth_WithNarrowImage_KeepsSize(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("never scales up", prop.ForAll(
+		func(width, extra, height int) bool {
+			w, h := FitWidth(width, height, width+extra)
+			return w == width && h == height
+		},
+		gen.IntRange(1, 1000),
+		gen.IntRange(0, 1000),
+		gen.IntRange(1, 4000),
+	))
+	properties.TestingRun(t)
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+)
+
+var (
+	ErrUnsupportedCoverURL = errors.New("cover URL must be an absolute http or https URL")
+	ErrForbiddenAddress    = errors.New("cover host resolves to a non-public address")
+)
+
+// Ranges that are neither private nor loopback but still do not reach the
+// public internet.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// ValidateCoverURL checks that a thumbnail URL can be fetched. Anyone can set
+// the URL of a book, so other schemes are refused.
+func ValidateCoverURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrUnsupportedCoverURL
+	}
+	return nil
+}
+
+// IsPublicAddress reports whether covers may be fetched from addr. Loopback,
+// private and link-local addresses are refused, so that a thumbnail URL
+// cannot reach the server itself or its internal network.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+//go:build small
+
+package domain
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestValidateCoverURL_WithHTTPURL_ReturnsNil(t *testing.T) {
+	for _, raw := range []string{"http://books.google.com/cover.jpg", "https://example.com/a.png?x=1"} {
+		if err := ValidateCoverURL(raw); err != nil {
+			t.Errorf("%s: unexpected error: %v", raw, err)
+		}
+	}
+}
+
+func TestValidateCoverURL_WithOtherURL_ReturnsError(t *testing.T) {
+	for _, raw := range []string{"file:///etc/passwd", "gopher://example.com/", "ftp://example.com/a.jpg", "/relative.jpg", "http://", ":"} {
+		if err := ValidateCoverURL(raw); err != ErrUnsupportedCoverURL {
+			t.Errorf("%s: expected ErrUnsupportedCoverURL, got %v", raw, err)
+		}
+	}
+}
+
+func TestIsPublicAddress(t *testing.T) {
+	cases := map[string]bool{
+		"8.8.8.8":              true,
+		"2001:4860:4860::8888": true,
+		"127.0.0.1":            false,
+		"::1":                  false,
+		"10.0.0.1":             false,
+		"172.16.5.4":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"0.0.0.0":              false,
+		"100.64.0.1":           false,
+		"::ffff:127.0.0.1":     false,
+	}
+	for s, want := range cases {
+		if got := IsPublicAddress(netip.MustParseAddr(s)); got != want {
+			t.Errorf("%s: expected %v, got %v", s, want, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+//go:build small
+
+package domain
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func jpegWithOrientation(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 0x002A)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(data[4:], uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xDA, 0, 2, 0xFF, 0xD9)
+}
+
+func TestExifOrientation_WithOrientationTag_ReturnsValue(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("reads orientation in both byte orders", prop.ForAll(
+		func(orientation int, bigEndian bool) bool {
+			var order binary.ByteOrder = binary.LittleEndian
+			if bigEndian {
+				order = binary.BigEndian
+			}
+			return ExifOrientation(jpegWithOrientation(order, uint16(orientation))) == orientation
+		},
+		gen.IntRange(1, 8),
+		gen.Bool(),
+	))
+	properties.TestingRun(t)
+}
+
+func TestExifOrientation_WithBrokenData_ReturnsNormal(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("never panics and falls back to normal", prop.ForAll(
+		func(cut int) bool {
+			data := jpegWithOrientation(binary.BigEndian, 6)
+			if cut >= len(data) {
+				cut = len(data) - 1
+			}
+			result := ExifOrientation(data[:cut])
+			return result == OrientationNormal || result == 6
+		},
+		gen.IntRange(0, 64),
+	))
+	properties.Property("returns normal for non-JPEG data", prop.ForAll(
+		func(data []byte) bool {
+			if len(data) >= 2 && data[0] == 0xFF && data[1] == 0xD8 {
+				return true
+			}
+			return ExifOrientation(data) == OrientationNormal
+		},
+		gen.SliceOf(gen.UInt8()),
+	))
+	properties.TestingRun(t)
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"errors"
+	"net/http"
+)
+
+var ErrUnsupportedImageType = errors.New("unsupported image type")
+
+var supportedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// SniffImageType detects the content type from the leading bytes instead of
+// trusting the upstream Content-Type header.
+func SniffImageType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !supportedImageTypes[contentType] {
+		return "", ErrUnsupportedImageType
+	}
+	return contentType, nil
+}
@@ -0,0 +1,26 @@
+//go:build small
+
+package domain
+
+import "testing"
+
+func TestSniffImageType_WithImageHeaders_ReturnsContentType(t *testing.T) {
+	cases := map[string][]byte{
+		"image/jpeg": {0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F'},
+		"image/png":  []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+		"image/webp": []byte("RIFF\x00\x00\x00\x00WEBPVP8 "),
+	}
+	for expected, data := range cases {
+		contentType, err := SniffImageType(data)
+		if err != nil || contentType != expected {
+			t.Errorf("expected %s, got %q (%v)", expected, contentType, err)
+		}
+	}
+}
+
+func TestSniffImageType_WithNonImage_ReturnsError(t *testing.T) {
+	_, err := SniffImageType([]byte("<html><body>not found</body></html>"))
+	if err != ErrUnsupportedImageType {
+		t.Errorf("expected ErrUnsupportedImageType, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	placeholderWidth     = 200
+	placeholderHeight    = 280
+	placeholderLineRunes = 10
+	placeholderMaxLines  = 6
+)
+
+var placeholderColors = []string{
+	"#37474f", "#4e342e", "#1b5e20", "#0d47a1", "#4a148c", "#b71c1c", "#006064", "#3e2723",
+}
+
+// PlaceholderSVG renders a plain cover showing the title, for books without a cover image.
+// The background color is derived from the title so the same book always looks the same.
+func PlaceholderSVG(title string, size CoverSize) []byte {
+	width, height := placeholderWidth, placeholderHeight
+	if maxWidth := size.MaxWidth(); maxWidth > 0 {
+		width, height = maxWidth, placeholderHeight*maxWidth/placeholderWidth
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(title))
+	color := placeholderColors[h.Sum32()%uint32(len(placeholderColors))]
+
+	lines := WrapTitle(title, placeholderLineRunes, placeholderMaxLines)
+	lineHeight := 22
+	top := placeholderHeight/2 - (len(lines)-1)*lineHeight/2
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		width, height, placeholderWidth, placeholderHeight)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, placeholderWidth, placeholderHeight, color)
+	fmt.Fprintf(&buf, `<rect x="12" y="12" width="%d" height="%d" fill="none" stroke="#ffffff" stroke-opacity="0.4"/>`,
+		placeholderWidth-24, placeholderHeight-24)
+	buf.WriteString(`<text fill="#ffffff" font-family="sans-serif" font-size="18" text-anchor="middle">`)
+	for i, line := range lines {
+		fmt.Fprintf(&buf, `<tspan x="%d" y="%d">%s</tspan>`, placeholderWidth/2, top+i*lineHeight, html.EscapeString(line))
+	}
+	buf.WriteString(`</text></svg>`)
+	return buf.Bytes()
+}
+
+// WrapTitle splits title into lines of at most lineRunes runes, breaking at
+// spaces when possible, and truncates with an ellipsis after maxLines lines.
+func WrapTitle(title string, lineRunes, maxLines int) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(title) {
+		for utf8.RuneCountInString(word) > 0 {
+			candidate := word
+			if current != "" {
+				candidate = current + " " + word
+			}
+			if utf8.RuneCountInString(candidate) <= lineRunes {
+				current = candidate
+				word = ""
+				continue
+			}
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+				continue
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:lineRunes]))
+			word = string(runes[lineRunes:])
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	if len(lines) > maxLines {
+		last := []rune(lines[maxLines-1])
+		if len(last) >= lineRunes {
+			last = last[:lineRunes-1]
+		}
+		lines = append(lines[:maxLines-1], string(last)+"…")
+	}
+	return lines
+}
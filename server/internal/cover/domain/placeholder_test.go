@@ -0,0 +1,61 @@
+//go:build small
+
+package domain
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestWrapTitle_WithAnyTitle_ReturnsBoundedLines(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("lines fit within the limits", prop.ForAll(
+		func(title string, lineRunes, maxLines int) bool {
+			lines := WrapTitle(title, lineRunes, maxLines)
+			if len(lines) > maxLines {
+				return false
+			}
+			for _, line := range lines {
+				if utf8.RuneCountInString(line) > lineRunes {
+					return false
+				}
+			}
+			return true
+		},
+		gen.AnyString(),
+		gen.IntRange(2, 20),
+		gen.IntRange(1, 8),
+	))
+	properties.TestingRun(t)
+}
+
+func TestWrapTitle_WithShortTitle_ReturnsSingleLine(t *testing.T) {
+	lines := WrapTitle("リーダブルコード", 10, 6)
+	if len(lines) != 1 || lines[0] != "リーダブルコード" {
+		t.Errorf("expected single line, got %v", lines)
+	}
+}
+
+func TestPlaceholderSVG_WithAnyTitle_ReturnsWellFormedXML(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("output is well-formed XML", prop.ForAll(
+		func(title string) bool {
+			svg := PlaceholderSVG(title, CoverSizeMedium)
+			decoder := xml.NewDecoder(strings.NewReader(string(svg)))
+			for {
+				_, err := decoder.Token()
+				if err != nil {
+					return err.Error() == "EOF"
+				}
+			}
+		},
+		gen.OneConstOf("<script>", "a & b", `"quoted"`, "リーダブルコード", "Go言語によるWebアプリケーション開発"),
+	))
+	properties.TestingRun(t)
+}
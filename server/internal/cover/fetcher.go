@@ -0,0 +1,83 @@
+package cover
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"holocron/internal/cover/domain"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const maxFetchBytes = 10 << 20
+
+type CoverFetcher struct {
+	client *http.Client
+}
+
+func NewCoverFetcher() *CoverFetcher {
+	return newCoverFetcher(domain.IsPublicAddress)
+}
+
+// newCoverFetcher checks the address of every connection, including the ones
+// of redirects, after the host name has been resolved.
+func newCoverFetcher(allowAddress func(netip.Addr) bool) *CoverFetcher {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allowAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", domain.ErrForbiddenAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the cover host and defeat the check.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &CoverFetcher{
+		client: &http.Client{
+			Transport: otelhttp.NewTransport(transport),
+			Timeout:   10 * time.Second,
+		},
+	}
+}
+
+func (f *CoverFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	if err := domain.ValidateCoverURL(url); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cover host returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFetchBytes {
+		return nil, fmt.Errorf("cover image exceeds %d bytes", maxFetchBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,34 @@
+//go:build medium
+
+package cover
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"holocron/internal/cover/domain"
+)
+
+func TestCoverFetcher_Fetch_WithLoopbackHost_ReturnsForbiddenAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected no request to reach the server")
+	}))
+	defer server.Close()
+
+	_, err := NewCoverFetcher().Fetch(context.Background(), server.URL)
+
+	if !errors.Is(err, domain.ErrForbiddenAddress) {
+		t.Errorf("expected ErrForbiddenAddress, got %v", err)
+	}
+}
+
+func TestCoverFetcher_Fetch_WithUnsupportedScheme_ReturnsError(t *testing.T) {
+	_, err := NewCoverFetcher().Fetch(context.Background(), "file:///etc/passwd")
+
+	if !errors.Is(err, domain.ErrUnsupportedCoverURL) {
+		t.Errorf("expected ErrUnsupportedCoverURL, got %v", err)
+	}
+}
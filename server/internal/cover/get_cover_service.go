@@ -0,0 +1,238 @@
+package cover
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"holocron/internal/cover/domain"
+)
+
+var (
+	ErrBookNotFound = errors.New("book not found")
+	ErrInvalidImage = errors.New("invalid image")
+)
+
+const (
+	variantOriginal = string(domain.CoverSizeOriginal)
+	// maxImagePixels keeps a small file that decodes into a huge image from
+	// exhausting memory.
+	maxImagePixels = 40_000_000
+	// failedFetchTTL is how long a thumbnail that could not be fetched is
+	// served as a placeholder before it is fetched again.
+	failedFetchTTL = 10 * time.Minute
+)
+
+type GetCoverInput struct {
+	BookID string
+	Size   string
+}
+
+type GetCoverOutput struct {
+	ContentType string
+	Data        []byte
+	ETag        string
+	ModifiedAt  time.Time
+	Placeholder bool
+}
+
+type GetCoverService struct {
+	queries *Queries
+	fetch   func(ctx context.Context, url string) ([]byte, error)
+	now     func() time.Time
+
+	mu sync.Mutex
+	// failedAt records when fetching each thumbnail URL last failed.
+	failedAt map[string]time.Time
+}
+
+func NewGetCoverService(queries *Queries, fetch func(ctx context.Context, url string) ([]byte, error)) *GetCoverService {
+	return &GetCoverService{
+		queries:  queries,
+		fetch:    fetch,
+		now:      func() time.Time { return time.Now().UTC() },
+		failedAt: map[string]time.Time{},
+	}
+}
+
+func (s *GetCoverService) GetCover(ctx context.Context, input GetCoverInput) (*GetCoverOutput, error) {
+	size, err := domain.ParseCoverSize(input.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := s.queries.GetBookCoverSource(ctx, input.BookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+
+	uploaded, err := s.uploadedCover(ctx, input.BookID, size)
+	if err != nil {
+		return nil, err
+	}
+	if uploaded != nil {
+		return uploaded, nil
+	}
+
+	if !row.ThumbnailUrl.Valid || row.ThumbnailUrl.String == "" {
+		return placeholder(row.Title.String, size), nil
+	}
+	source := row.ThumbnailUrl.String
+
+	cached, err := s.cachedCover(ctx, source, size)
+	if err == nil {
+		return cached, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if s.recentlyFailed(source) {
+		return placeholder(row.Title.String, size), nil
+	}
+	data, err := s.fetch(ctx, source)
+	if err != nil {
+		s.recordFailure(source)
+		return placeholder(row.Title.String, size), nil
+	}
+	if err := storeCover(ctx, s.queries, source, data, s.now()); err != nil {
+		if errors.Is(err, ErrInvalidImage) {
+			s.recordFailure(source)
+			return placeholder(row.Title.String, size), nil
+		}
+		return nil, err
+	}
+
+	return s.cachedCover(ctx, source, size)
+}
+
+func (s *GetCoverService) recentlyFailed(source string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failedAt, ok := s.failedAt[source]
+	return ok && s.now().Sub(failedAt) < failedFetchTTL
+}
+
+func (s *GetCoverService) recordFailure(source string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	for url, failedAt := range s.failedAt {
+		if now.Sub(failedAt) >= failedFetchTTL {
+			delete(s.failedAt, url)
+		}
+	}
+	s.failedAt[source] = now
+}
+
+// uploadedCover returns the latest user-uploaded cover, which takes precedence
+// over thumbnailUrl. It returns nil when the book has no uploaded cover.
+func (s *GetCoverService) uploadedCover(ctx context.Context, bookID string, size domain.CoverSize) (*GetCoverOutput, error) {
+	coverID, err := s.queries.GetLatestUploadedCover(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !coverID.Valid {
+		return nil, nil
+	}
+	cached, err := s.cachedCover(ctx, uploadSource(coverID.String), size)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cached, nil
+}
+
+func (s *GetCoverService) cachedCover(ctx context.Context, source string, size domain.CoverSize) (*GetCoverOutput, error) {
+	row, err := s.queries.GetCoverImage(ctx, GetCoverImageParams{
+		Source:  source,
+		Variant: string(size),
+	})
+	if err != nil {
+		return nil, err
+	}
+	modifiedAt, err := time.Parse(time.RFC3339, row.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &GetCoverOutput{
+		ContentType: row.ContentType,
+		Data:        row.Data,
+		ETag:        row.Etag,
+		ModifiedAt:  modifiedAt,
+	}, nil
+}
+
+// storeCover validates the image and stores it with its resized variants under source.
+func storeCover(ctx context.Context, queries *Queries, source string, data []byte, now time.Time) error {
+	contentType, err := domain.SniffImageType(data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return fmt.Errorf("%w: image is too large", ErrInvalidImage)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if err := upsertVariant(ctx, queries, source, variantOriginal, contentType, data, now); err != nil {
+		return err
+	}
+	for _, size := range domain.ResizedCoverSizes {
+		resized, err := resizeImage(img, size.MaxWidth())
+		if err != nil {
+			return err
+		}
+		if err := upsertVariant(ctx, queries, source, string(size), "image/jpeg", resized, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upsertVariant(ctx context.Context, queries *Queries, source, variant, contentType string, data []byte, now time.Time) error {
+	return queries.UpsertCoverImage(ctx, UpsertCoverImageParams{
+		Source:      source,
+		Variant:     variant,
+		ContentType: contentType,
+		Data:        data,
+		Etag:        etag(data),
+		CreatedAt:   now.Format(time.RFC3339),
+	})
+}
+
+func placeholder(title string, size domain.CoverSize) *GetCoverOutput {
+	data := domain.PlaceholderSVG(title, size)
+	return &GetCoverOutput{
+		ContentType: "image/svg+xml",
+		Data:        data,
+		ETag:        etag(data),
+		Placeholder: true,
+	}
+}
+
+func etag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -0,0 +1,259 @@
+//go:build medium
+
+package cover
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"holocron/internal/cover/domain"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE book_events (
+			event_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			authors TEXT,
+			publisher TEXT,
+			published_date TEXT,
+			thumbnail_url TEXT,
+			delete_reason TEXT,
+			delete_memo TEXT,
+			origin TEXT,
+			cover_id TEXT,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE INDEX idx_book_events_book_id ON book_events(book_id);
+		CREATE TABLE cover_images (
+			source TEXT NOT NULL,
+			variant TEXT NOT NULL,
+			content_type TEXT NOT NULL,
+			data BLOB NOT NULL,
+			etag TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (source, variant)
+		);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func insertBook(t *testing.T, db *sql.DB, title string, thumbnailURL *string) string {
+	t.Helper()
+	bookID := uuid.New().String()
+	_, err := db.Exec(
+		`INSERT INTO book_events (event_id, book_id, event_type, title, authors, thumbnail_url, occurred_at) VALUES (?, ?, 'created', ?, '["author"]', ?, ?)`,
+		uuid.New().String(), bookID, title, thumbnailURL, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bookID
+}
+
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// When GetCover with thumbnail then fetches once and serves cached variants
+func TestGetCover_WithThumbnail_CachesOriginalAndVariants(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	url := "http://books.google.com/cover.png"
+	bookID := insertBook(t, db, "リーダブルコード", &url)
+	original := pngImage(t, 600, 800)
+	fetched := 0
+	service := NewGetCoverService(queries, func(ctx context.Context, u string) ([]byte, error) {
+		fetched++
+		return original, nil
+	})
+	ctx := context.Background()
+
+	first, err := service.GetCover(ctx, GetCoverInput{BookID: bookID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	small, err := service.GetCover(ctx, GetCoverInput{BookID: bookID, Size: "small"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fetched != 1 {
+		t.Errorf("expected 1 fetch, got %d", fetched)
+	}
+	if first.ContentType != "image/png" || !bytes.Equal(first.Data, original) {
+		t.Errorf("expected original PNG, got %s", first.ContentType)
+	}
+	if small.ContentType != "image/jpeg" {
+		t.Fatalf("expected JPEG variant, got %s", small.ContentType)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(small.Data))
+	if err != nil {
+		t.Fatalf("failed to decode variant: %v", err)
+	}
+	if cfg.Width != 128 || cfg.Height != 170 {
+		t.Errorf("expected 128x170, got %dx%d", cfg.Width, cfg.Height)
+	}
+	if first.ETag == small.ETag {
+		t.Error("expected variants to have different ETags")
+	}
+}
+
+// When GetCover without thumbnail then returns placeholder with title
+func TestGetCover_WithoutThumbnail_ReturnsPlaceholder(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	bookID := insertBook(t, db, "社内報告書", nil)
+	service := NewGetCoverService(queries, func(ctx context.Context, u string) ([]byte, error) {
+		t.Error("expected no fetch")
+		return nil, errors.New("unexpected")
+	})
+
+	output, err := service.GetCover(context.Background(), GetCoverInput{BookID: bookID})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !output.Placeholder || output.ContentType != "image/svg+xml" {
+		t.Errorf("expected SVG placeholder, got %s", output.ContentType)
+	}
+	if !strings.Contains(string(output.Data), "社内報告書") {
+		t.Error("expected placeholder to show the title")
+	}
+}
+
+// When GetCover with unreachable or non-image thumbnail then returns placeholder
+func TestGetCover_WithBrokenThumbnail_ReturnsPlaceholder(t *testing.T) {
+	fetchers := map[string]func(ctx context.Context, u string) ([]byte, error){
+		"fetch error": func(ctx context.Context, u string) ([]byte, error) { return nil, errors.New("timeout") },
+		"html body":   func(ctx context.Context, u string) ([]byte, error) { return []byte("<html></html>"), nil },
+	}
+	for name, fetch := range fetchers {
+		t.Run(name, func(t *testing.T) {
+			db := setupTestDB(t)
+			url := "http://example.com/missing.jpg"
+			bookID := insertBook(t, db, "タイトル", &url)
+			service := NewGetCoverService(New(db), fetch)
+
+			output, err := service.GetCover(context.Background(), GetCoverInput{BookID: bookID, Size: "medium"})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !output.Placeholder {
+				t.Error("expected placeholder")
+			}
+		})
+	}
+}
+
+// When GetCover with a failing thumbnail twice then fetches only once until the failure expires
+func TestGetCover_WithBrokenThumbnail_CachesFailure(t *testing.T) {
+	db := setupTestDB(t)
+	url := "http://example.com/missing.jpg"
+	bookID := insertBook(t, db, "タイトル", &url)
+	fetched := 0
+	service := NewGetCoverService(New(db), func(ctx context.Context, u string) ([]byte, error) {
+		fetched++
+		return nil, errors.New("timeout")
+	})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := service.GetCover(ctx, GetCoverInput{BookID: bookID}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if fetched != 1 {
+		t.Errorf("expected 1 fetch while the failure is cached, got %d", fetched)
+	}
+
+	now = now.Add(failedFetchTTL)
+	if _, err := service.GetCover(ctx, GetCoverInput{BookID: bookID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetched != 2 {
+		t.Errorf("expected a new fetch after the failure expired, got %d", fetched)
+	}
+}
+
+// pngHeader returns the start of a PNG that declares the given size, which is
+// all image.DecodeConfig reads.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12] = 8 // bit depth
+	ihdr[13] = 6 // RGBA
+	data := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d")
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+// When storing an image declaring too many pixels then refuses it before decoding
+func TestStoreCover_WithTooManyPixels_ReturnsInvalidImage(t *testing.T) {
+	db := setupTestDB(t)
+
+	err := storeCover(context.Background(), New(db), "http://example.com/bomb.png", pngHeader(50000, 50000), time.Now())
+
+	if !errors.Is(err, ErrInvalidImage) || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("expected the image to be refused as too large, got %v", err)
+	}
+}
+
+// When GetCover with unknown book or size then returns error
+func TestGetCover_WithInvalidInput_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewGetCoverService(New(db), nil)
+	bookID := insertBook(t, db, "タイトル", nil)
+
+	_, err := service.GetCover(context.Background(), GetCoverInput{BookID: uuid.New().String()})
+	if !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+
+	_, err = service.GetCover(context.Background(), GetCoverInput{BookID: bookID, Size: "huge"})
+	if !errors.Is(err, domain.ErrInvalidCoverSize) {
+		t.Errorf("expected ErrInvalidCoverSize, got %v", err)
+	}
+}
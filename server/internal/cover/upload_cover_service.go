@@ -0,0 +1,140 @@
+package cover
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"time"
+
+	"holocron/internal/cover/domain"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxUploadBytes = 5 << 20
+)
+
+var uploadableImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+type UploadCoverInput struct {
+	BookID string
+	Data   []byte
+}
+
+type UploadCoverOutput struct {
+	CoverID     string
+	ContentType string
+	Width       int
+	Height      int
+	UploadedAt  time.Time
+}
+
+type UploadCoverService struct {
+	queries *Queries
+	now     func() time.Time
+}
+
+func NewUploadCoverService(queries *Queries) *UploadCoverService {
+	return &UploadCoverService{
+		queries: queries,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *UploadCoverService) UploadCover(ctx context.Context, input UploadCoverInput) (*UploadCoverOutput, error) {
+	if _, err := s.queries.GetBookCoverSource(ctx, input.BookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+
+	contentType, err := domain.SniffImageType(input.Data)
+	if err != nil || !uploadableImageTypes[contentType] {
+		return nil, domain.ErrUnsupportedImageType
+	}
+
+	normalized, normalizedType, err := normalizeUpload(input.Data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	coverID := uuid.New().String()
+	if err := storeCover(ctx, s.queries, uploadSource(coverID), normalized.data, now); err != nil {
+		return nil, err
+	}
+
+	err = s.queries.InsertCoverChangedEvent(ctx, InsertCoverChangedEventParams{
+		EventID:    uuid.New().String(),
+		BookID:     input.BookID,
+		CoverID:    sql.NullString{String: coverID, Valid: true},
+		OccurredAt: now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadCoverOutput{
+		CoverID:     coverID,
+		ContentType: normalizedType,
+		Width:       normalized.width,
+		Height:      normalized.height,
+		UploadedAt:  now,
+	}, nil
+}
+
+type normalizedImage struct {
+	data   []byte
+	width  int
+	height int
+}
+
+// normalizeUpload decodes the upload, rotates it upright according to its EXIF
+// orientation and re-encodes it. Re-encoding drops EXIF and any other metadata.
+// WebP has no encoder in the standard library, so it is stored as JPEG.
+func normalizeUpload(data []byte, contentType string) (*normalizedImage, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, "", fmt.Errorf("%w: image is too large", ErrInvalidImage)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, domain.ExifOrientation(data))
+	}
+
+	var buf bytes.Buffer
+	outputType := "image/jpeg"
+	if contentType == "image/png" {
+		outputType = "image/png"
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	bounds := img.Bounds()
+	return &normalizedImage{data: buf.Bytes(), width: bounds.Dx(), height: bounds.Dy()}, outputType, nil
+}
+
+func uploadSource(coverID string) string {
+	return "upload:" + coverID
+}
@@ -0,0 +1,138 @@
+//go:build medium
+
+package cover
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+
+	"holocron/internal/cover/domain"
+
+	"github.com/google/uuid"
+)
+
+func jpegWithExifOrientation(t *testing.T, width, height int, orientation uint16) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+
+	tiff := make([]byte, 26)
+	copy(tiff, "MM")
+	binary.BigEndian.PutUint16(tiff[2:], 0x002A)
+	binary.BigEndian.PutUint32(tiff[4:], 8)
+	binary.BigEndian.PutUint16(tiff[8:], 1)
+	binary.BigEndian.PutUint16(tiff[10:], 0x0112)
+	binary.BigEndian.PutUint16(tiff[12:], 3)
+	binary.BigEndian.PutUint32(tiff[14:], 1)
+	binary.BigEndian.PutUint16(tiff[18:], orientation)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := append([]byte{}, encoded[:2]...)
+	data = append(data, app1...)
+	return append(data, encoded[2:]...)
+}
+
+// When UploadCover with rotated JPEG then stores upright image without EXIF
+func TestUploadCover_WithExifOrientation_StoresUprightImageWithoutExif(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	url := "http://example.com/cover.jpg"
+	bookID := insertBook(t, db, "同人誌", &url)
+	ctx := context.Background()
+	upload := jpegWithExifOrientation(t, 40, 20, 6)
+	service := NewUploadCoverService(queries)
+
+	output, err := service.UploadCover(ctx, UploadCoverInput{BookID: bookID, Data: upload})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Width != 20 || output.Height != 40 {
+		t.Errorf("expected 20x40 after rotation, got %dx%d", output.Width, output.Height)
+	}
+
+	getService := NewGetCoverService(queries, func(ctx context.Context, u string) ([]byte, error) {
+		t.Error("expected uploaded cover to take precedence over thumbnail")
+		return nil, errors.New("unexpected")
+	})
+	served, err := getService.GetCover(ctx, GetCoverInput{BookID: bookID})
+	if err != nil {
+		t.Fatalf("postcondition failed: %v", err)
+	}
+	if served.Placeholder || served.ContentType != "image/jpeg" {
+		t.Fatalf("postcondition failed: expected uploaded JPEG, got %s", served.ContentType)
+	}
+	if bytes.Contains(served.Data, []byte("Exif")) {
+		t.Error("postcondition failed: expected EXIF to be stripped")
+	}
+
+	var eventType string
+	err = db.QueryRow(`SELECT event_type FROM book_events WHERE book_id = ? AND cover_id = ?`, bookID, output.CoverID).Scan(&eventType)
+	if err != nil || eventType != "cover_changed" {
+		t.Errorf("postcondition failed: expected cover_changed event, got %q (%v)", eventType, err)
+	}
+}
+
+// When UploadCover with unsupported content then returns ErrUnsupportedImageType
+func TestUploadCover_WithUnsupportedContent_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	bookID := insertBook(t, db, "タイトル", nil)
+	service := NewUploadCoverService(New(db))
+	var gifBuf bytes.Buffer
+	palette := image.NewPaletted(image.Rect(0, 0, 4, 4), []color.Color{color.Black, color.White})
+	if err := gif.Encode(&gifBuf, palette, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string][]byte{
+		"gif":  gifBuf.Bytes(),
+		"html": []byte("<html><script>alert(1)</script></html>"),
+	} {
+		_, err := service.UploadCover(context.Background(), UploadCoverInput{BookID: bookID, Data: data})
+		if !errors.Is(err, domain.ErrUnsupportedImageType) {
+			t.Errorf("%s: expected ErrUnsupportedImageType, got %v", name, err)
+		}
+	}
+}
+
+// When UploadCover with PNG then keeps PNG format
+func TestUploadCover_WithPNG_KeepsPNG(t *testing.T) {
+	db := setupTestDB(t)
+	bookID := insertBook(t, db, "タイトル", nil)
+	service := NewUploadCoverService(New(db))
+
+	output, err := service.UploadCover(context.Background(), UploadCoverInput{BookID: bookID, Data: pngImage(t, 30, 40)})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.ContentType != "image/png" || output.Width != 30 || output.Height != 40 {
+		t.Errorf("expected 30x40 PNG, got %dx%d %s", output.Width, output.Height, output.ContentType)
+	}
+}
+
+// When UploadCover with unknown book then returns ErrBookNotFound
+func TestUploadCover_WithUnknownBook_ReturnsNotFoundError(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUploadCoverService(New(db))
+
+	_, err := service.UploadCover(context.Background(), UploadCoverInput{BookID: uuid.New().String(), Data: pngImage(t, 4, 4)})
+
+	if !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+}
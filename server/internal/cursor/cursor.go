@@ -0,0 +1,71 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursorEncoding rejects non-zero padding bits, so every cursor has a single spelling.
+var cursorEncoding = base64.RawURLEncoding.Strict()
+
+// Codec turns list positions into opaque cursors and back. A cursor is the JSON
+// payload and its HMAC-SHA256, both base64url encoded, so clients cannot craft or
+// alter one.
+type Codec struct {
+	key []byte
+}
+
+// NewCodec returns a codec signing with the given secret. Without a secret a random
+// key is used, which means cursors stop being valid when the server restarts.
+func NewCodec(secret string) *Codec {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return &Codec{key: key}
+}
+
+func (c *Codec) Encode(payload any) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return cursorEncoding.EncodeToString(data) + "." +
+		cursorEncoding.EncodeToString(c.sign(data)), nil
+}
+
+func (c *Codec) Decode(cursor string, payload any) error {
+	encodedData, encodedSignature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return ErrInvalidCursor
+	}
+	data, err := cursorEncoding.DecodeString(encodedData)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	signature, err := cursorEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if !hmac.Equal(signature, c.sign(data)) {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, payload); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+func (c *Codec) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,95 @@
+//go:build small
+
+package cursor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+type testPayload struct {
+	ID    string `json:"id"`
+	Value int    `json:"value"`
+}
+
+func TestCodec_WithEncodedPayload_DecodesSamePayload(t *testing.T) {
+	codec := NewCodec("secret")
+
+	properties := gopter.NewProperties(nil)
+	properties.Property("decode(encode(p)) == p", prop.ForAll(
+		func(id string, value int) bool {
+			cursor, err := codec.Encode(testPayload{ID: id, Value: value})
+			if err != nil {
+				return false
+			}
+			var decoded testPayload
+			err = codec.Decode(cursor, &decoded)
+			return err == nil && decoded == testPayload{ID: id, Value: value}
+		},
+		gen.AnyString(),
+		gen.Int(),
+	))
+	properties.TestingRun(t)
+}
+
+func TestCodec_WithTamperedCursor_ReturnsErrInvalidCursor(t *testing.T) {
+	codec := NewCodec("secret")
+	cursor, err := codec.Encode(testPayload{ID: "book-1", Value: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	properties := gopter.NewProperties(nil)
+	properties.Property("any changed character invalidates the cursor", prop.ForAll(
+		func(index int, replacement rune) bool {
+			runes := []rune(cursor)
+			if runes[index] == replacement {
+				return true
+			}
+			runes[index] = replacement
+			var decoded testPayload
+			return codec.Decode(string(runes), &decoded) == ErrInvalidCursor
+		},
+		gen.IntRange(0, len(cursor)-1),
+		gen.OneConstOf('A', 'z', '0', '-', '_', '.'),
+	))
+	properties.TestingRun(t)
+}
+
+func TestCodec_WithCursorFromOtherSecret_ReturnsErrInvalidCursor(t *testing.T) {
+	cursor, err := NewCodec("secret").Encode(testPayload{ID: "book-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded testPayload
+	if err := NewCodec("other").Decode(cursor, &decoded); err != ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestCodec_WithMalformedCursor_ReturnsErrInvalidCursor(t *testing.T) {
+	codec := NewCodec("secret")
+	for _, cursor := range []string{"", "abc", "abc.def", "!!!.???", strings.Repeat(".", 3)} {
+		var decoded testPayload
+		if err := codec.Decode(cursor, &decoded); err != ErrInvalidCursor {
+			t.Errorf("%q: expected ErrInvalidCursor, got %v", cursor, err)
+		}
+	}
+}
+
+func TestNewCodec_WithoutSecret_UsesRandomKey(t *testing.T) {
+	cursor, err := NewCodec("").Encode(testPayload{ID: "book-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded testPayload
+	if err := NewCodec("").Decode(cursor, &decoded); err != ErrInvalidCursor {
+		t.Errorf("expected a different key, got %v", err)
+	}
+}
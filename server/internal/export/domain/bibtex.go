@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// EscapeBibTeX escapes the characters that have a special meaning in BibTeX field values.
+func EscapeBibTeX(s string) string {
+	return bibtexEscaper.Replace(s)
+}
+
+// BibTeXAuthors joins authors with "and". Names that themselves contain " and "
+// are braced so BibTeX treats them as a single name.
+func BibTeXAuthors(authors []string) string {
+	names := make([]string, 0, len(authors))
+	for _, author := range authors {
+		name := EscapeBibTeX(author)
+		if strings.Contains(strings.ToLower(author), " and ") {
+			name = "{" + name + "}"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, " and ")
+}
+
+type bibtexWriter struct {
+	w *bufio.Writer
+}
+
+func newBibTeXWriter(w io.Writer) *bibtexWriter {
+	return &bibtexWriter{w: bufio.NewWriter(w)}
+}
+
+func (b *bibtexWriter) WriteEntry(entry CatalogEntry) error {
+	fields := [][2]string{{"title", EscapeBibTeX(entry.Title)}}
+	if len(entry.Authors) > 0 {
+		fields = append(fields, [2]string{"author", BibTeXAuthors(entry.Authors)})
+	}
+	if entry.Publisher != "" {
+		fields = append(fields, [2]string{"publisher", EscapeBibTeX(entry.Publisher)})
+	}
+	if year := entry.PublishedYear(); year != "" {
+		fields = append(fields, [2]string{"year", year})
+	}
+	if isISBN(entry.Code) {
+		fields = append(fields, [2]string{"isbn", entry.Code})
+	}
+	fields = append(fields, [2]string{"status", entry.Status})
+
+	if _, err := fmt.Fprintf(b.w, "@book{holocron:%s,\n", entry.ID); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if _, err := fmt.Fprintf(b.w, "  %s = {%s},\n", field[0], field[1]); err != nil {
+			return err
+		}
+	}
+	_, err := b.w.WriteString("}\n\n")
+	return err
+}
+
+func (b *bibtexWriter) Flush() error {
+	return b.w.Flush()
+}
+
+func (b *bibtexWriter) Close() error {
+	return b.Flush()
+}
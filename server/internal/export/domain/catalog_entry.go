@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCatalogRow = errors.New("invalid catalog row")
+
+const (
+	StatusAvailable = "available"
+	StatusBorrowed  = "borrowed"
+)
+
+type Borrower struct {
+	ID         string
+	Name       string
+	BorrowedAt time.Time
+	DueDate    *time.Time
+}
+
+type CatalogEntry struct {
+	ID            string
+	Code          string
+	Title         string
+	Authors       []string
+	Publisher     string
+	PublishedDate string
+	ThumbnailURL  string
+	Status        string
+	Borrower      *Borrower
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func CatalogEntryFromRow(
+	bookID string,
+	code string,
+	title string,
+	authorsJSON string,
+	publisher string,
+	publishedDate string,
+	thumbnailURL string,
+	createdAtRaw interface{},
+	updatedAt string,
+	borrowerID *string,
+	borrowerName *string,
+	borrowedAt *string,
+	dueDate *string,
+) (*CatalogEntry, error) {
+	var authors []string
+	if authorsJSON != "" {
+		if err := json.Unmarshal([]byte(authorsJSON), &authors); err != nil {
+			return nil, ErrInvalidCatalogRow
+		}
+	}
+
+	createdAtStr, ok := createdAtRaw.(string)
+	if !ok {
+		return nil, ErrInvalidCatalogRow
+	}
+	createdAt, err := time.Parse(time.RFC3339, createdAtStr)
+	if err != nil {
+		return nil, ErrInvalidCatalogRow
+	}
+	updated, err := time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		return nil, ErrInvalidCatalogRow
+	}
+
+	status := StatusAvailable
+	var borrower *Borrower
+	if borrowerID != nil && borrowerName != nil && borrowedAt != nil {
+		status = StatusBorrowed
+		borrowed, err := time.Parse(time.RFC3339, *borrowedAt)
+		if err != nil {
+			return nil, ErrInvalidCatalogRow
+		}
+		borrower = &Borrower{ID: *borrowerID, Name: *borrowerName, BorrowedAt: borrowed}
+		if dueDate != nil {
+			due, err := time.Parse(time.RFC3339, *dueDate)
+			if err != nil {
+				return nil, ErrInvalidCatalogRow
+			}
+			borrower.DueDate = &due
+		}
+	}
+
+	return &CatalogEntry{
+		ID:            bookID,
+		Code:          code,
+		Title:         title,
+		Authors:       authors,
+		Publisher:     publisher,
+		PublishedDate: publishedDate,
+		ThumbnailURL:  thumbnailURL,
+		Status:        status,
+		Borrower:      borrower,
+		CreatedAt:     createdAt,
+		UpdatedAt:     updated,
+	}, nil
+}
+
+// PublishedYear returns the leading four digit year of the published date, or "" if there is none.
+func (e CatalogEntry) PublishedYear() string {
+	if len(e.PublishedDate) < 4 {
+		return ""
+	}
+	for _, c := range e.PublishedDate[:4] {
+		if c < '0' || c > '9' {
+			return ""
+		}
+	}
+	return e.PublishedDate[:4]
+}
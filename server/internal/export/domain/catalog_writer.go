@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// CatalogWriter encodes catalog entries one at a time so that the catalog never has to be held in memory.
+type CatalogWriter interface {
+	WriteEntry(entry CatalogEntry) error
+	// Flush writes any buffered data to the underlying writer.
+	Flush() error
+	// Close writes the trailer of the format, if any, and flushes.
+	Close() error
+}
+
+func NewCatalogWriter(format ExportFormat, w io.Writer) CatalogWriter {
+	switch format {
+	case ExportFormatJSONL:
+		return newJSONLWriter(w)
+	case ExportFormatMARC:
+		return newMARCWriter(w)
+	case ExportFormatMARCXML:
+		return newMARCXMLWriter(w)
+	case ExportFormatBibTeX:
+		return newBibTeXWriter(w)
+	}
+	return newCSVWriter(w)
+}
+
+var csvHeader = []string{
+	"id", "code", "title", "authors", "publisher", "published_date", "thumbnail_url",
+	"status", "borrower_id", "borrower_name", "borrowed_at", "due_date", "created_at", "updated_at",
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+	return c.w.Write(csvHeader)
+}
+
+func (c *csvWriter) WriteEntry(entry CatalogEntry) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	var borrowerID, borrowerName, borrowedAt, dueDate string
+	if entry.Borrower != nil {
+		borrowerID = entry.Borrower.ID
+		borrowerName = entry.Borrower.Name
+		borrowedAt = entry.Borrower.BorrowedAt.Format(time.RFC3339)
+		if entry.Borrower.DueDate != nil {
+			dueDate = entry.Borrower.DueDate.Format(time.RFC3339)
+		}
+	}
+	return c.w.Write([]string{
+		entry.ID,
+		entry.Code,
+		entry.Title,
+		strings.Join(entry.Authors, ";"),
+		entry.Publisher,
+		entry.PublishedDate,
+		entry.ThumbnailURL,
+		entry.Status,
+		borrowerID,
+		borrowerName,
+		borrowedAt,
+		dueDate,
+		entry.CreatedAt.Format(time.RFC3339),
+		entry.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	return c.Flush()
+}
+
+type jsonlWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	return &jsonlWriter{w: bw, enc: enc}
+}
+
+func (j *jsonlWriter) WriteEntry(entry CatalogEntry) error {
+	authors := entry.Authors
+	if authors == nil {
+		authors = []string{}
+	}
+	var borrower map[string]any
+	if entry.Borrower != nil {
+		borrower = map[string]any{
+			"id":         entry.Borrower.ID,
+			"name":       entry.Borrower.Name,
+			"borrowedAt": entry.Borrower.BorrowedAt.Format(time.RFC3339),
+			"dueDate":    nil,
+		}
+		if entry.Borrower.DueDate != nil {
+			borrower["dueDate"] = entry.Borrower.DueDate.Format(time.RFC3339)
+		}
+	}
+	return j.enc.Encode(map[string]any{
+		"id":            entry.ID,
+		"code":          emptyToNil(entry.Code),
+		"title":         entry.Title,
+		"authors":       authors,
+		"publisher":     emptyToNil(entry.Publisher),
+		"publishedDate": emptyToNil(entry.PublishedDate),
+		"thumbnailUrl":  emptyToNil(entry.ThumbnailURL),
+		"status":        entry.Status,
+		"borrower":      borrower,
+		"createdAt":     entry.CreatedAt.Format(time.RFC3339),
+		"updatedAt":     entry.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (j *jsonlWriter) Flush() error {
+	return j.w.Flush()
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.Flush()
+}
+
+func emptyToNil(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
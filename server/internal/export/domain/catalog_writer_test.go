@@ -0,0 +1,149 @@
+//go:build small
+
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func borrowedEntry() CatalogEntry {
+	entry := sampleEntry()
+	due := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	entry.Status = StatusBorrowed
+	entry.Borrower = &Borrower{
+		ID:         "user-1",
+		Name:       "山田",
+		BorrowedAt: time.Date(2024, 1, 18, 9, 0, 0, 0, time.UTC),
+		DueDate:    &due,
+	}
+	return entry
+}
+
+func writeAll(t *testing.T, format ExportFormat, entries ...CatalogEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewCatalogWriter(format, &buf)
+	for _, entry := range entries {
+		if err := w.WriteEntry(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestCSVWriter_WritesHeaderAndRows(t *testing.T) {
+	out := writeAll(t, ExportFormatCSV, sampleEntry(), borrowedEntry())
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d", len(records))
+	}
+	if records[0][0] != "id" || records[0][3] != "authors" {
+		t.Errorf("unexpected header %v", records[0])
+	}
+	if records[1][3] != "Dustin Boswell;Trevor Foucher" {
+		t.Errorf("unexpected authors %q", records[1][3])
+	}
+	if records[1][7] != "available" || records[1][8] != "" {
+		t.Errorf("unexpected status columns %v", records[1][7:12])
+	}
+	if records[2][7] != "borrowed" || records[2][9] != "山田" || records[2][11] != "2024-02-01T00:00:00Z" {
+		t.Errorf("unexpected status columns %v", records[2][7:12])
+	}
+}
+
+func TestCSVWriter_WithoutEntries_WritesHeader(t *testing.T) {
+	out := writeAll(t, ExportFormatCSV)
+	if !strings.HasPrefix(out, "id,code,title,") || strings.Count(out, "\n") != 1 {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestJSONLWriter_WritesOneObjectPerLine(t *testing.T) {
+	entry := sampleEntry()
+	entry.Publisher = ""
+	out := writeAll(t, ExportFormatJSONL, entry, borrowedEntry())
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	var first, second map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if first["publisher"] != nil || first["borrower"] != nil || first["status"] != "available" {
+		t.Errorf("unexpected first line %v", first)
+	}
+	borrower, ok := second["borrower"].(map[string]any)
+	if !ok || borrower["dueDate"] != "2024-02-01T00:00:00Z" {
+		t.Errorf("unexpected borrower %v", second["borrower"])
+	}
+}
+
+func TestBibTeXWriter_WritesBookEntries(t *testing.T) {
+	out := writeAll(t, ExportFormatBibTeX, borrowedEntry())
+
+	for _, want := range []string{
+		"@book{holocron:550e8400-e29b-41d4-a716-446655440000,\n",
+		"  title = {リーダブルコード},\n",
+		"  author = {Dustin Boswell and Trevor Foucher},\n",
+		"  year = {2012},\n",
+		"  isbn = {9784873115658},\n",
+		"  status = {borrowed},\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in\n%s", want, out)
+		}
+	}
+}
+
+func TestEscapeBibTeX_EscapesSpecialCharacters(t *testing.T) {
+	got := EscapeBibTeX(`C# & {Go} 100% $_~^\`)
+	want := `C\# \& \{Go\} 100\% \$\_\textasciitilde{}\textasciicircum{}\textbackslash{}`
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestBibTeXAuthors_BracesNamesContainingAnd(t *testing.T) {
+	got := BibTeXAuthors([]string{"Simon and Schuster", "山田太郎"})
+	if got != "{Simon and Schuster} and 山田太郎" {
+		t.Errorf("unexpected authors %q", got)
+	}
+}
+
+func TestParseExportFormat_WithUnknownFormat_ReturnsError(t *testing.T) {
+	for _, s := range []string{"csv", "jsonl", "marc", "marcxml", "bibtex"} {
+		if _, err := ParseExportFormat(s); err != nil {
+			t.Errorf("expected %s to be accepted: %v", s, err)
+		}
+	}
+	if _, err := ParseExportFormat("xlsx"); err != ErrInvalidExportFormat {
+		t.Errorf("expected ErrInvalidExportFormat, got %v", err)
+	}
+}
+
+func TestCatalogEntry_PublishedYear(t *testing.T) {
+	cases := map[string]string{"2012-06-23": "2012", "20120623": "2012", "2012": "2012", "平成24年": "", "": ""}
+	for date, want := range cases {
+		entry := CatalogEntry{PublishedDate: date}
+		if got := entry.PublishedYear(); got != want {
+			t.Errorf("%q: expected %q, got %q", date, want, got)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "errors"
+
+var ErrInvalidExportFormat = errors.New("format must be csv, jsonl, marc, marcxml or bibtex")
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV     ExportFormat = "csv"
+	ExportFormatJSONL   ExportFormat = "jsonl"
+	ExportFormatMARC    ExportFormat = "marc"
+	ExportFormatMARCXML ExportFormat = "marcxml"
+	ExportFormatBibTeX  ExportFormat = "bibtex"
+)
+
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch f := ExportFormat(s); f {
+	case ExportFormatCSV, ExportFormatJSONL, ExportFormatMARC, ExportFormatMARCXML, ExportFormatBibTeX:
+		return f, nil
+	}
+	return "", ErrInvalidExportFormat
+}
+
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatCSV:
+		return "text/csv; charset=utf-8"
+	case ExportFormatJSONL:
+		return "application/x-ndjson"
+	case ExportFormatMARC:
+		return "application/marc"
+	case ExportFormatMARCXML:
+		return "application/marcxml+xml"
+	case ExportFormatBibTeX:
+		return "application/x-bibtex; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+func (f ExportFormat) FileExtension() string {
+	switch f {
+	case ExportFormatMARC:
+		return "mrc"
+	case ExportFormatMARCXML:
+		return "xml"
+	case ExportFormatBibTeX:
+		return "bib"
+	}
+	return string(f)
+}
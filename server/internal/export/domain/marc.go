@@ -0,0 +1,282 @@
+package domain
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var ErrMARCRecordTooLong = errors.New("marc record exceeds 99999 bytes")
+
+const (
+	marcSubfieldDelimiter = 0x1F
+	marcFieldTerminator   = 0x1E
+	marcRecordTerminator  = 0x1D
+	marcLeaderLength      = 24
+	marcDirectoryEntry    = 12
+)
+
+type MARCSubfield struct {
+	Code  byte
+	Value string
+}
+
+// MARCField is a control field when Subfields is empty (tags 00X) and a data field otherwise.
+type MARCField struct {
+	Tag       string
+	Value     string
+	Ind1      byte
+	Ind2      byte
+	Subfields []MARCSubfield
+}
+
+func (f MARCField) isControl() bool {
+	return strings.HasPrefix(f.Tag, "00")
+}
+
+type MARCRecord struct {
+	Fields []MARCField
+}
+
+// MARCRecordFromEntry maps a catalog entry to a minimal level MARC21 bibliographic record.
+// The lending status is recorded in the item information field (876 $j).
+func MARCRecordFromEntry(entry CatalogEntry) MARCRecord {
+	fields := []MARCField{
+		{Tag: "001", Value: entry.ID},
+		{Tag: "005", Value: entry.UpdatedAt.UTC().Format("20060102150405") + ".0"},
+		{Tag: "008", Value: marcFixedLengthData(entry)},
+	}
+
+	switch {
+	case isISBN(entry.Code):
+		fields = append(fields, dataField("020", ' ', ' ', MARCSubfield{'a', entry.Code}))
+	case entry.Code != "":
+		ind1 := byte('8')
+		if len(entry.Code) == 13 || len(entry.Code) == 8 {
+			ind1 = '3'
+		}
+		fields = append(fields, dataField("024", ind1, ' ', MARCSubfield{'a', entry.Code}))
+	}
+
+	titleInd1 := byte('0')
+	if len(entry.Authors) > 0 {
+		titleInd1 = '1'
+		fields = append(fields, dataField("100", '1', ' ', MARCSubfield{'a', entry.Authors[0]}))
+	}
+	fields = append(fields, dataField("245", titleInd1, '0', MARCSubfield{'a', entry.Title}))
+
+	var publication []MARCSubfield
+	if entry.Publisher != "" {
+		publication = append(publication, MARCSubfield{'b', entry.Publisher})
+	}
+	if entry.PublishedDate != "" {
+		publication = append(publication, MARCSubfield{'c', entry.PublishedDate})
+	}
+	if len(publication) > 0 {
+		fields = append(fields, dataField("264", ' ', '1', publication...))
+	}
+
+	if len(entry.Authors) > 1 {
+		for _, author := range entry.Authors[1:] {
+			fields = append(fields, dataField("700", '1', ' ', MARCSubfield{'a', author}))
+		}
+	}
+	if entry.ThumbnailURL != "" {
+		fields = append(fields, dataField("856", '4', '2', MARCSubfield{'3', "Cover image"}, MARCSubfield{'u', entry.ThumbnailURL}))
+	}
+	fields = append(fields, dataField("876", ' ', ' ', MARCSubfield{'a', entry.ID}, MARCSubfield{'j', entry.Status}))
+
+	return MARCRecord{Fields: fields}
+}
+
+func dataField(tag string, ind1, ind2 byte, subfields ...MARCSubfield) MARCField {
+	return MARCField{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: subfields}
+}
+
+func marcFixedLengthData(entry CatalogEntry) string {
+	dateType, date1 := "n", "uuuu"
+	if year := entry.PublishedYear(); year != "" {
+		dateType, date1 = "s", year
+	}
+	return entry.CreatedAt.UTC().Format("060102") + dateType + date1 + "    " + "xx " + strings.Repeat("|", 17) + "und" + "|" + "d"
+}
+
+func isISBN(code string) bool {
+	return len(code) == 10 || (len(code) == 13 && (strings.HasPrefix(code, "978") || strings.HasPrefix(code, "979")))
+}
+
+var marcControlStripper = strings.NewReplacer(
+	string(rune(marcSubfieldDelimiter)), "",
+	string(rune(marcFieldTerminator)), "",
+	string(rune(marcRecordTerminator)), "",
+)
+
+func (f MARCField) iso2709() []byte {
+	var b strings.Builder
+	if f.isControl() {
+		b.WriteString(marcControlStripper.Replace(f.Value))
+	} else {
+		b.WriteByte(f.Ind1)
+		b.WriteByte(f.Ind2)
+		for _, sf := range f.Subfields {
+			b.WriteByte(marcSubfieldDelimiter)
+			b.WriteByte(sf.Code)
+			b.WriteString(marcControlStripper.Replace(sf.Value))
+		}
+	}
+	b.WriteByte(marcFieldTerminator)
+	return []byte(b.String())
+}
+
+// marcLeader returns the leader of a UTF-8 encoded minimal level book record.
+func marcLeader(recordLength, baseAddress int) string {
+	return fmt.Sprintf("%05dnam a22%05d7u 4500", recordLength, baseAddress)
+}
+
+// ISO2709 encodes the record in the MARC21 exchange format with UTF-8 data.
+func (r MARCRecord) ISO2709() ([]byte, error) {
+	var directory, data []byte
+	for _, field := range r.Fields {
+		encoded := field.iso2709()
+		if len(encoded) > 9999 || len(data) > 99999 {
+			return nil, ErrMARCRecordTooLong
+		}
+		directory = append(directory, fmt.Sprintf("%s%04d%05d", field.Tag, len(encoded), len(data))...)
+		data = append(data, encoded...)
+	}
+	directory = append(directory, marcFieldTerminator)
+
+	baseAddress := marcLeaderLength + len(directory)
+	recordLength := baseAddress + len(data) + 1
+	if recordLength > 99999 {
+		return nil, ErrMARCRecordTooLong
+	}
+
+	record := make([]byte, 0, recordLength)
+	record = append(record, marcLeader(recordLength, baseAddress)...)
+	record = append(record, directory...)
+	record = append(record, data...)
+	record = append(record, marcRecordTerminator)
+	return record, nil
+}
+
+type marcWriter struct {
+	w *bufio.Writer
+}
+
+func newMARCWriter(w io.Writer) *marcWriter {
+	return &marcWriter{w: bufio.NewWriter(w)}
+}
+
+func (m *marcWriter) WriteEntry(entry CatalogEntry) error {
+	record, err := MARCRecordFromEntry(entry).ISO2709()
+	if err != nil {
+		return fmt.Errorf("book %s: %w", entry.ID, err)
+	}
+	_, err = m.w.Write(record)
+	return err
+}
+
+func (m *marcWriter) Flush() error {
+	return m.w.Flush()
+}
+
+func (m *marcWriter) Close() error {
+	return m.Flush()
+}
+
+const marcXMLNamespace = "http://www.loc.gov/MARC21/slim"
+
+type marcXMLWriter struct {
+	w       *bufio.Writer
+	enc     *xml.Encoder
+	started bool
+}
+
+func newMARCXMLWriter(w io.Writer) *marcXMLWriter {
+	bw := bufio.NewWriter(w)
+	return &marcXMLWriter{w: bw, enc: xml.NewEncoder(bw)}
+}
+
+func (m *marcXMLWriter) start() error {
+	if m.started {
+		return nil
+	}
+	m.started = true
+	if _, err := m.w.WriteString(xml.Header); err != nil {
+		return err
+	}
+	return m.enc.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "collection"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: marcXMLNamespace}},
+	})
+}
+
+func (m *marcXMLWriter) WriteEntry(entry CatalogEntry) error {
+	if err := m.start(); err != nil {
+		return err
+	}
+	record := MARCRecordFromEntry(entry)
+	encoded, err := record.ISO2709()
+	if err != nil {
+		return fmt.Errorf("book %s: %w", entry.ID, err)
+	}
+
+	x := marcXMLRecord{Leader: string(encoded[:marcLeaderLength])}
+	for _, field := range record.Fields {
+		if field.isControl() {
+			x.ControlFields = append(x.ControlFields, marcXMLControlField{Tag: field.Tag, Value: field.Value})
+			continue
+		}
+		df := marcXMLDataField{Tag: field.Tag, Ind1: string(field.Ind1), Ind2: string(field.Ind2)}
+		for _, sf := range field.Subfields {
+			df.Subfields = append(df.Subfields, marcXMLSubfield{Code: string(sf.Code), Value: sf.Value})
+		}
+		x.DataFields = append(x.DataFields, df)
+	}
+	return m.enc.Encode(x)
+}
+
+func (m *marcXMLWriter) Flush() error {
+	if err := m.enc.Flush(); err != nil {
+		return err
+	}
+	return m.w.Flush()
+}
+
+func (m *marcXMLWriter) Close() error {
+	if err := m.start(); err != nil {
+		return err
+	}
+	if err := m.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "collection"}}); err != nil {
+		return err
+	}
+	return m.Flush()
+}
+
+type marcXMLRecord struct {
+	XMLName       xml.Name              `xml:"record"`
+	Leader        string                `xml:"leader"`
+	ControlFields []marcXMLControlField `xml:"controlfield"`
+	DataFields    []marcXMLDataField    `xml:"datafield"`
+}
+
+type marcXMLControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcXMLDataField struct {
+	Tag       string            `xml:"tag,attr"`
+	Ind1      string            `xml:"ind1,attr"`
+	Ind2      string            `xml:"ind2,attr"`
+	Subfields []marcXMLSubfield `xml:"subfield"`
+}
+
+type marcXMLSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
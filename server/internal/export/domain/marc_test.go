@@ -0,0 +1,188 @@
+//go:build small
+
+package domain
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func sampleEntry() CatalogEntry {
+	return CatalogEntry{
+		ID:            "550e8400-e29b-41d4-a716-446655440000",
+		Code:          "9784873115658",
+		Title:         "リーダブルコード",
+		Authors:       []string{"Dustin Boswell", "Trevor Foucher"},
+		Publisher:     "オライリージャパン",
+		PublishedDate: "2012-06-23",
+		ThumbnailURL:  "https://example.com/cover.jpg",
+		Status:        StatusAvailable,
+		CreatedAt:     time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		UpdatedAt:     time.Date(2024, 1, 16, 8, 0, 5, 0, time.UTC),
+	}
+}
+
+// parseISO2709 splits a record into tag -> field data using its leader and directory.
+func parseISO2709(t *testing.T, record []byte) map[string][]string {
+	t.Helper()
+	length, err := strconv.Atoi(string(record[0:5]))
+	if err != nil || length != len(record) {
+		t.Fatalf("record length %q does not match %d", record[0:5], len(record))
+	}
+	if record[len(record)-1] != marcRecordTerminator {
+		t.Fatal("record must end with the record terminator")
+	}
+	base, err := strconv.Atoi(string(record[12:17]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record[base-1] != marcFieldTerminator {
+		t.Fatal("directory must end with the field terminator")
+	}
+	fields := map[string][]string{}
+	for dir := record[marcLeaderLength : base-1]; len(dir) > 0; dir = dir[marcDirectoryEntry:] {
+		tag := string(dir[0:3])
+		fieldLength, _ := strconv.Atoi(string(dir[3:7]))
+		start, _ := strconv.Atoi(string(dir[7:12]))
+		data := record[base+start : base+start+fieldLength]
+		if data[len(data)-1] != marcFieldTerminator {
+			t.Fatalf("field %s must end with the field terminator", tag)
+		}
+		fields[tag] = append(fields[tag], string(data[:len(data)-1]))
+	}
+	return fields
+}
+
+func TestMARCRecord_ISO2709_HasConsistentDirectory(t *testing.T) {
+	record, err := MARCRecordFromEntry(sampleEntry()).ISO2709()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(record[5:12]) != "nam a22" || string(record[20:24]) != "4500" {
+		t.Errorf("unexpected leader %q", record[:24])
+	}
+
+	fields := parseISO2709(t, record)
+	if fields["001"][0] != "550e8400-e29b-41d4-a716-446655440000" {
+		t.Errorf("unexpected 001 %q", fields["001"])
+	}
+	if fields["005"][0] != "20240116080005.0" {
+		t.Errorf("unexpected 005 %q", fields["005"])
+	}
+	if len(fields["008"][0]) != 40 || fields["008"][0][:11] != "240115s2012" {
+		t.Errorf("unexpected 008 %q", fields["008"])
+	}
+	if fields["020"][0] != "  \x1fa9784873115658" {
+		t.Errorf("unexpected 020 %q", fields["020"])
+	}
+	if fields["245"][0] != "10\x1faリーダブルコード" {
+		t.Errorf("unexpected 245 %q", fields["245"])
+	}
+	if fields["264"][0] != " 1\x1fbオライリージャパン\x1fc2012-06-23" {
+		t.Errorf("unexpected 264 %q", fields["264"])
+	}
+	if fields["700"][0] != "1 \x1faTrevor Foucher" {
+		t.Errorf("unexpected 700 %q", fields["700"])
+	}
+	if !strings.HasSuffix(fields["876"][0], "\x1fjavailable") {
+		t.Errorf("unexpected 876 %q", fields["876"])
+	}
+}
+
+func TestMARCRecord_ISO2709_WithAnyTitle_IsWellFormed(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("lengths and offsets match the encoded bytes", prop.ForAll(
+		func(title string, authors []string) bool {
+			entry := sampleEntry()
+			entry.Title = title
+			entry.Authors = authors
+			record, err := MARCRecordFromEntry(entry).ISO2709()
+			if err != nil {
+				return false
+			}
+			fields := parseISO2709(t, record)
+			return len(fields["245"]) == 1 && strings.Count(string(record), "\x1d") == 1
+		},
+		gen.AnyString(),
+		gen.SliceOf(gen.AlphaString()),
+	))
+	properties.TestingRun(t)
+}
+
+func TestMARCRecord_WithoutAuthors_UsesTitleMainEntry(t *testing.T) {
+	entry := sampleEntry()
+	entry.Authors = nil
+	entry.Code = ""
+	entry.PublishedDate = ""
+
+	record, err := MARCRecordFromEntry(entry).ISO2709()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := parseISO2709(t, record)
+	if _, ok := fields["100"]; ok {
+		t.Error("expected no 100 field")
+	}
+	if _, ok := fields["020"]; ok {
+		t.Error("expected no 020 field")
+	}
+	if !strings.HasPrefix(fields["245"][0], "00") {
+		t.Errorf("expected first indicator 0, got %q", fields["245"])
+	}
+	if fields["008"][0][6:11] != "nuuuu" {
+		t.Errorf("expected unknown date in 008, got %q", fields["008"])
+	}
+}
+
+func TestMARCXMLWriter_WritesCollection(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewCatalogWriter(ExportFormatMARCXML, &buf)
+	if err := w.WriteEntry(sampleEntry()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var collection struct {
+		XMLName xml.Name        `xml:"http://www.loc.gov/MARC21/slim collection"`
+		Records []marcXMLRecord `xml:"record"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &collection); err != nil {
+		t.Fatalf("invalid xml: %v\n%s", err, buf.String())
+	}
+	if len(collection.Records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(collection.Records))
+	}
+	record := collection.Records[0]
+	if len(record.Leader) != 24 {
+		t.Errorf("unexpected leader %q", record.Leader)
+	}
+	var title string
+	for _, df := range record.DataFields {
+		if df.Tag == "245" {
+			title = df.Subfields[0].Value
+		}
+	}
+	if title != "リーダブルコード" {
+		t.Errorf("unexpected title %q", title)
+	}
+}
+
+func TestMARCXMLWriter_WithoutEntries_WritesEmptyCollection(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewCatalogWriter(ExportFormatMARCXML, &buf).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), `<collection xmlns="http://www.loc.gov/MARC21/slim"></collection>`) {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,52 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"holocron/internal/api"
+	"holocron/internal/export/domain"
+)
+
+type ExportBooksHandler struct {
+	service *ExportBooksService
+}
+
+func NewExportBooksHandler(service *ExportBooksService) *ExportBooksHandler {
+	return &ExportBooksHandler{service: service}
+}
+
+func (h *ExportBooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.GetExportBooksParams) {
+	format := domain.ExportFormatCSV
+	if params.Format != nil {
+		parsed, err := domain.ParseExportFormat(string(*params.Format))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "format must be csv, jsonl, marc, marcxml or bibtex")
+			return
+		}
+		format = parsed
+	}
+
+	filename := fmt.Sprintf("holocron-books-%s.%s", time.Now().UTC().Format("20060102"), format.FileExtension())
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := h.service.ExportBooks(r.Context(), format, w); err != nil {
+		// The status has already been sent. Abort the connection so the client
+		// does not mistake the truncated body for a complete export.
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"context"
+	"database/sql"
+	"io"
+
+	"holocron/internal/export/domain"
+)
+
+const defaultExportPageSize = 500
+
+type ExportBooksService struct {
+	queries  *Queries
+	pageSize int
+}
+
+func NewExportBooksService(queries *Queries) *ExportBooksService {
+	return &ExportBooksService{queries: queries, pageSize: defaultExportPageSize}
+}
+
+// ExportBooks streams the current catalog to w page by page, flushing after each page,
+// and returns the number of exported books.
+func (s *ExportBooksService) ExportBooks(ctx context.Context, format domain.ExportFormat, w io.Writer) (int, error) {
+	writer := domain.NewCatalogWriter(format, w)
+	flusher, _ := w.(interface{ Flush() })
+
+	count := 0
+	after := ""
+	for {
+		rows, err := s.queries.ListCatalogPage(ctx, ListCatalogPageParams{
+			BookID: after,
+			Limit:  int64(s.pageSize),
+		})
+		if err != nil {
+			return count, err
+		}
+
+		for _, row := range rows {
+			entry, err := domain.CatalogEntryFromRow(
+				row.BookID,
+				row.Code.String,
+				row.Title.String,
+				row.Authors.String,
+				row.Publisher.String,
+				row.PublishedDate.String,
+				row.ThumbnailUrl.String,
+				row.CreatedAt,
+				row.UpdatedAt,
+				nullStringToPtr(row.BorrowerID),
+				nullStringToPtr(row.BorrowerName),
+				nullStringToPtr(row.BorrowedAt),
+				nullStringToPtr(row.DueDate),
+			)
+			if err != nil {
+				return count, err
+			}
+			if err := writer.WriteEntry(*entry); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(rows) < s.pageSize {
+			break
+		}
+		after = rows[len(rows)-1].BookID
+
+		if err := writer.Flush(); err != nil {
+			return count, err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	return count, writer.Close()
+}
+
+func nullStringToPtr(ns sql.NullString) *string {
+	if !ns.Valid {
+		return nil
+	}
+	return &ns.String
+}
@@ -0,0 +1,337 @@
+//go:build medium
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"holocron/internal/export/domain"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE user_events (
+			event_id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE TABLE book_events (
+			event_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			authors TEXT,
+			publisher TEXT,
+			published_date TEXT,
+			thumbnail_url TEXT,
+			delete_reason TEXT,
+			delete_memo TEXT,
+			origin TEXT,
+			cover_id TEXT,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE TABLE lending_events (
+			event_id TEXT PRIMARY KEY,
+			lending_id TEXT NOT NULL,
+			book_id TEXT NOT NULL,
+			borrower_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			due_date TEXT,
+			actor_id TEXT,
+			reason TEXT,
+			occurred_at TEXT NOT NULL
+		);
+
+		-- open_lendings has the loans that have not been returned, transferred or
+		-- reported lost. due_date is the one set when the loan was opened.
+		CREATE VIEW open_lendings AS
+		SELECT
+			le.lending_id,
+			le.book_id,
+			le.borrower_id,
+			le.due_date,
+			le.occurred_at as borrowed_at
+		FROM lending_events le
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND NOT EXISTS (
+				SELECT 1
+				FROM lending_events closed
+				WHERE closed.lending_id = le.lending_id
+					AND closed.event_type IN ('returned', 'force_returned', 'transferred', 'lost')
+			);
+
+		CREATE VIEW deleted_books AS
+		SELECT book_id, MAX(occurred_at) as deleted_at
+		FROM book_events
+		WHERE event_type = 'deleted'
+		GROUP BY book_id;
+
+		-- latest_books has the latest details of each book since it was last deleted.
+		-- Events in the same second are ordered as they were recorded.
+		CREATE VIEW latest_books AS
+		SELECT book_id, code, title, authors, publisher, published_date, thumbnail_url, created_at, updated_at
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.code,
+				e1.title,
+				e1.authors,
+				e1.publisher,
+				e1.published_date,
+				e1.thumbnail_url,
+				(SELECT MIN(e_created.occurred_at)
+				 FROM book_events e_created
+				 WHERE e_created.book_id = e1.book_id
+				   AND e_created.event_type = 'created'
+				   AND e_created.occurred_at > COALESCE(d.deleted_at, '1970-01-01T00:00:00Z')
+				) as created_at,
+				e1.occurred_at as updated_at,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('created', 'updated')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- book_classifications has the latest tags_changed, category_changed and
+		-- shelf_changed event of each book, one row per event type.
+		CREATE VIEW book_classifications AS
+		SELECT book_id, event_type, tags, category_id, shelf_location
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.event_type,
+				e1.tags,
+				e1.category_id,
+				e1.shelf_location,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id, e1.event_type ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('tags_changed', 'category_changed', 'shelf_changed')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- current_lendings has the open loan of each book, with its latest due date.
+		CREATE VIEW current_lendings AS
+		SELECT lending_id, book_id, borrower_id, borrower_name, borrowed_at, due_date
+		FROM (
+			SELECT
+				ol.lending_id,
+				ol.book_id,
+				ol.borrower_id,
+				ue.name as borrower_name,
+				ol.borrowed_at,
+				(SELECT due.due_date
+				 FROM lending_events due
+				 WHERE due.lending_id = ol.lending_id
+				   AND due.event_type IN ('borrowed', 'lent', 'due_date_extended')
+				   AND due.due_date IS NOT NULL
+				 ORDER BY due.occurred_at DESC
+				 LIMIT 1
+				) as due_date,
+				ROW_NUMBER() OVER (PARTITION BY ol.book_id ORDER BY ol.borrowed_at DESC) as rn
+			FROM open_lendings ol
+			INNER JOIN user_events ue ON ue.user_id = ol.borrower_id AND ue.event_type = 'created'
+			LEFT JOIN deleted_books d ON ol.book_id = d.book_id
+			WHERE d.deleted_at IS NULL OR ol.borrowed_at > d.deleted_at
+		)
+		WHERE rn = 1;
+
+		-- borrow_counts counts the loans of each book since it was last deleted.
+		CREATE VIEW borrow_counts AS
+		SELECT le.book_id, COUNT(*) as borrow_count
+		FROM lending_events le
+		LEFT JOIN deleted_books d ON le.book_id = d.book_id
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND (d.deleted_at IS NULL OR le.occurred_at > d.deleted_at)
+		GROUP BY le.book_id;
+
+		-- book_list_items joins the current state of each book for the book list, the
+		-- search and the facets, which filter it with the same conditions.
+		CREATE VIEW book_list_items AS
+		SELECT
+			lb.book_id,
+			lb.code,
+			lb.title,
+			lb.authors,
+			lb.publisher,
+			lb.published_date,
+			lb.thumbnail_url,
+			lb.created_at,
+			lb.updated_at,
+			cl.lending_id,
+			cl.borrower_id,
+			cl.borrower_name,
+			cl.borrowed_at,
+			cl.due_date,
+			bt.tags,
+			bcat.category_id,
+			ce.name as category_name,
+			ce.code as category_code,
+			bs.shelf_location,
+			COALESCE(bc.borrow_count, 0) as borrow_count
+		FROM latest_books lb
+		LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id
+		LEFT JOIN book_classifications bt ON lb.book_id = bt.book_id AND bt.event_type = 'tags_changed'
+		LEFT JOIN book_classifications bcat ON lb.book_id = bcat.book_id AND bcat.event_type = 'category_changed'
+		LEFT JOIN book_classifications bs ON lb.book_id = bs.book_id AND bs.event_type = 'shelf_changed'
+		LEFT JOIN category_events ce ON bcat.category_id = ce.category_id AND ce.event_type = 'created'
+		LEFT JOIN borrow_counts bc ON lb.book_id = bc.book_id;
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func insertBookEvent(t *testing.T, db *sql.DB, bookID, eventType, title, occurredAt string) {
+	t.Helper()
+	_, err := db.Exec(
+		`INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at) VALUES (?, ?, ?, ?, '["author"]', ?)`,
+		uuid.New().String(), bookID, eventType, title, occurredAt,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readJSONL(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	var items []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		var item map[string]any
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func TestExportBooks_WithManyBooks_ExportsEveryPage(t *testing.T) {
+	db := setupTestDB(t)
+	for i := 0; i < 7; i++ {
+		insertBookEvent(t, db, uuid.New().String(), "created", fmt.Sprintf("Book %d", i), "2024-01-01T00:00:00Z")
+	}
+	service := NewExportBooksService(New(db))
+	service.pageSize = 3
+
+	// When the catalog spans several pages
+	// then every book is exported exactly once
+	var buf bytes.Buffer
+	count, err := service.ExportBooks(context.Background(), domain.ExportFormatJSONL, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 7 {
+		t.Errorf("expected 7 books, got %d", count)
+	}
+	seen := map[string]bool{}
+	for _, item := range readJSONL(t, buf.String()) {
+		seen[item["id"].(string)] = true
+	}
+	if len(seen) != 7 {
+		t.Errorf("expected 7 distinct books, got %d", len(seen))
+	}
+}
+
+func TestExportBooks_ExportsLatestSnapshotWithoutDeletedBooks(t *testing.T) {
+	db := setupTestDB(t)
+	updated := uuid.New().String()
+	insertBookEvent(t, db, updated, "created", "Old Title", "2024-01-01T00:00:00Z")
+	insertBookEvent(t, db, updated, "updated", "New Title", "2024-01-02T00:00:00Z")
+	deleted := uuid.New().String()
+	insertBookEvent(t, db, deleted, "created", "Deleted", "2024-01-01T00:00:00Z")
+	insertBookEvent(t, db, deleted, "deleted", "", "2024-01-03T00:00:00Z")
+
+	// When a book was updated and another was deleted
+	// then only the latest snapshot of the remaining book is exported
+	var buf bytes.Buffer
+	count, err := NewExportBooksService(New(db)).ExportBooks(context.Background(), domain.ExportFormatJSONL, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	items := readJSONL(t, buf.String())
+	if count != 1 || len(items) != 1 {
+		t.Fatalf("expected 1 book, got %d", len(items))
+	}
+	if items[0]["title"] != "New Title" || items[0]["createdAt"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("unexpected item %v", items[0])
+	}
+}
+
+func TestExportBooks_WithBorrowedBook_IncludesLatestDueDate(t *testing.T) {
+	db := setupTestDB(t)
+	bookID := uuid.New().String()
+	insertBookEvent(t, db, bookID, "created", "Borrowed", "2024-01-01T00:00:00Z")
+	_, err := db.Exec(`INSERT INTO user_events (event_id, user_id, event_type, name, occurred_at) VALUES (?, 'user-1', 'created', '山田', '2024-01-01T00:00:00Z')`, uuid.New().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lendingID := uuid.New().String()
+	for _, e := range []struct{ eventType, dueDate, occurredAt string }{
+		{"borrowed", "2024-01-16T00:00:00Z", "2024-01-02T00:00:00Z"},
+		{"due_date_extended", "2024-01-30T00:00:00Z", "2024-01-10T00:00:00Z"},
+	} {
+		_, err := db.Exec(
+			`INSERT INTO lending_events (event_id, lending_id, book_id, borrower_id, event_type, due_date, occurred_at) VALUES (?, ?, ?, 'user-1', ?, ?, ?)`,
+			uuid.New().String(), lendingID, bookID, e.eventType, e.dueDate, e.occurredAt,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// When a borrowed book's due date was extended
+	// then the export shows the borrower and the extended due date
+	var buf bytes.Buffer
+	if _, err := NewExportBooksService(New(db)).ExportBooks(context.Background(), domain.ExportFormatCSV, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and 1 row, got %q", buf.String())
+	}
+	want := ",borrowed,user-1,山田,2024-01-02T00:00:00Z,2024-01-30T00:00:00Z,"
+	if !strings.Contains(lines[1], want) {
+		t.Errorf("expected %q in %q", want, lines[1])
+	}
+}
+
+func TestExportBooks_WithCanceledContext_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	insertBookEvent(t, db, uuid.New().String(), "created", "Book", time.Now().UTC().Format(time.RFC3339))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// When the request is canceled
+	// then the export stops with an error
+	var buf bytes.Buffer
+	if _, err := NewExportBooksService(New(db)).ExportBooks(ctx, domain.ExportFormatMARC, &buf); err == nil {
+		t.Error("expected error")
+	}
+}
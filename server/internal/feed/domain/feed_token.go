@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+// tokenBytes is the amount of randomness in a feed token.
+const tokenBytes = 32
+
+var ErrInvalidFeedToken = errors.New("invalid feed token")
+
+// FeedToken is the secret part of a calendar feed URL. Only its hash is
+// stored, so a token cannot be shown again and a lost one is reissued.
+type FeedToken string
+
+func GenerateFeedToken() (FeedToken, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return FeedToken(base64.RawURLEncoding.EncodeToString(b)), nil
+}
+
+// ParseFeedToken checks the token has the shape of a generated one, so that
+// other strings are rejected without a lookup.
+func ParseFeedToken(s string) (FeedToken, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) != tokenBytes {
+		return "", ErrInvalidFeedToken
+	}
+	return FeedToken(s), nil
+}
+
+// Hash returns the hex encoded SHA-256 of the token, which is what is stored.
+func (t FeedToken) Hash() string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:])
+}
+
+// FeedPath is the path the due date feed of the token is served at.
+func (t FeedToken) FeedPath() string {
+	return "/feeds/" + string(t) + "/due-dates.ics"
+}
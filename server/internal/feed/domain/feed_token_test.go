@@ -0,0 +1,50 @@
+//go:build small
+
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenerateFeedToken_ReturnsParsableUniqueToken(t *testing.T) {
+	seen := map[FeedToken]bool{}
+	for i := 0; i < 100; i++ {
+		token, err := GenerateFeedToken()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := ParseFeedToken(string(token)); err != nil {
+			t.Fatalf("expected generated token to parse, got %v", err)
+		}
+		if seen[token] {
+			t.Fatalf("duplicate token %q", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestParseFeedToken_WithInvalidToken_ReturnsError(t *testing.T) {
+	for _, s := range []string{"", "short", "not base64 !!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"} {
+		if _, err := ParseFeedToken(s); !errors.Is(err, ErrInvalidFeedToken) {
+			t.Errorf("%q: expected ErrInvalidFeedToken, got %v", s, err)
+		}
+	}
+}
+
+func TestFeedToken_Hash_IsStableAndDiffersFromToken(t *testing.T) {
+	token, err := GenerateFeedToken()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if token.Hash() != token.Hash() {
+		t.Error("expected the same hash for the same token")
+	}
+	if token.Hash() == string(token) || len(token.Hash()) != 64 {
+		t.Errorf("expected a hex SHA-256 hash, got %q", token.Hash())
+	}
+	if got, want := token.FeedPath(), "/feeds/"+string(token)+"/due-dates.ics"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
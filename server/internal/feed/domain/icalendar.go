@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"holocron/internal/calendar"
+)
+
+const (
+	productID = "-//holocron//due dates//JA"
+	// alarmTrigger rings the alarm at 9:00 the day before the due date, as
+	// the event starts at midnight of the due date.
+	alarmTrigger = "-PT15H"
+	// maxLineOctets is the longest content line RFC 5545 allows before it has
+	// to be folded, excluding the line break.
+	maxLineOctets = 75
+)
+
+// DueDateEvent is a loan shown as an all-day event on its due date.
+type DueDateEvent struct {
+	LendingID string
+	Title     string
+	DueDate   time.Time
+	// Sequence counts the revisions of the event, so that calendar apps
+	// replace it when the due date is extended.
+	Sequence int64
+}
+
+// UID identifies the event of a loan across every fetch of the feed.
+func (e DueDateEvent) UID() string {
+	return "lending-" + e.LendingID + "@holocron"
+}
+
+// RenderCalendar writes the events as an RFC 5545 calendar. The due date is
+// counted in the library's time zone, like the days it is open.
+func RenderCalendar(events []DueDateEvent, now time.Time) string {
+	var b calendarBuilder
+	b.line("BEGIN:VCALENDAR")
+	b.line("VERSION:2.0")
+	b.line("PRODID:" + productID)
+	b.line("CALSCALE:GREGORIAN")
+	b.line("METHOD:PUBLISH")
+	b.line("X-WR-CALNAME:" + escapeText("返却期限"))
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, event := range events {
+		due := event.DueDate.In(calendar.Location)
+		start := time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, calendar.Location)
+		b.line("BEGIN:VEVENT")
+		b.line("UID:" + event.UID())
+		b.line("DTSTAMP:" + stamp)
+		b.line("SEQUENCE:" + strconv.FormatInt(event.Sequence, 10))
+		b.line("DTSTART;VALUE=DATE:" + start.Format("20060102"))
+		b.line("DTEND;VALUE=DATE:" + start.AddDate(0, 0, 1).Format("20060102"))
+		b.line("SUMMARY:" + escapeText("返却期限: "+event.Title))
+		b.line("DESCRIPTION:" + escapeText(fmt.Sprintf("「%s」の返却期限は%sです。", event.Title, due.Format("2006-01-02 15:04"))))
+		b.line("TRANSP:TRANSPARENT")
+		b.line("BEGIN:VALARM")
+		b.line("ACTION:DISPLAY")
+		b.line("DESCRIPTION:" + escapeText("明日は「"+event.Title+"」の返却期限です"))
+		b.line("TRIGGER:" + alarmTrigger)
+		b.line("END:VALARM")
+		b.line("END:VEVENT")
+	}
+	b.line("END:VCALENDAR")
+	return b.String()
+}
+
+type calendarBuilder struct {
+	strings.Builder
+}
+
+// line writes a content line ending with CRLF, folding it into lines of at
+// most 75 octets without splitting a UTF-8 character.
+func (b *calendarBuilder) line(s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space of a continuation line counts towards its length.
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escapeText escapes a TEXT property value.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
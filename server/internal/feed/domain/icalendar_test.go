@@ -0,0 +1,95 @@
+//go:build small
+
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestRenderCalendar_WithLoan_ReturnsAllDayEventWithAlarm(t *testing.T) {
+	now := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	events := []DueDateEvent{{
+		LendingID: "lending-1",
+		Title:     "Go言語",
+		// 2024-01-22 19:30 in Japan
+		DueDate:  time.Date(2024, 1, 22, 10, 30, 0, 0, time.UTC),
+		Sequence: 2,
+	}}
+
+	got := RenderCalendar(events, now)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"BEGIN:VEVENT\r\nUID:lending-lending-1@holocron\r\n",
+		"DTSTAMP:20240116T000000Z\r\n",
+		"SEQUENCE:2\r\n",
+		"DTSTART;VALUE=DATE:20240122\r\nDTEND;VALUE=DATE:20240123\r\n",
+		"SUMMARY:返却期限: Go言語\r\n",
+		"BEGIN:VALARM\r\nACTION:DISPLAY\r\n",
+		"TRIGGER:-PT15H\r\nEND:VALARM\r\nEND:VEVENT\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in\n%s", want, got)
+		}
+	}
+	if !strings.HasSuffix(got, "END:VCALENDAR\r\n") {
+		t.Errorf("expected the calendar to end with END:VCALENDAR, got\n%s", got)
+	}
+}
+
+func TestRenderCalendar_WithDueDateAfterMidnightInJapan_UsesJapaneseDate(t *testing.T) {
+	events := []DueDateEvent{{LendingID: "l", Title: "t", DueDate: time.Date(2024, 1, 22, 16, 0, 0, 0, time.UTC)}}
+
+	got := RenderCalendar(events, time.Now())
+
+	if !strings.Contains(got, "DTSTART;VALUE=DATE:20240123\r\n") {
+		t.Errorf("expected the due date in Japan, got\n%s", got)
+	}
+}
+
+func TestRenderCalendar_WithNoLoans_ReturnsEmptyCalendar(t *testing.T) {
+	got := RenderCalendar(nil, time.Now())
+
+	if strings.Contains(got, "BEGIN:VEVENT") {
+		t.Errorf("expected no event, got\n%s", got)
+	}
+	if !strings.HasPrefix(got, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(got, "END:VCALENDAR\r\n") {
+		t.Errorf("expected a calendar, got\n%s", got)
+	}
+}
+
+func TestRenderCalendar_WithSpecialCharacters_EscapesText(t *testing.T) {
+	events := []DueDateEvent{{LendingID: "l", Title: "a,b;c\\d\ne", DueDate: time.Now()}}
+
+	got := RenderCalendar(events, time.Now())
+
+	if !strings.Contains(got, `SUMMARY:返却期限: a\,b\;c\\d\ne`+"\r\n") {
+		t.Errorf("expected escaped summary, got\n%s", got)
+	}
+}
+
+func TestRenderCalendar_WithAnyTitle_FoldsLinesWithinLimit(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("lines are at most 75 octets and unfold to the content", prop.ForAll(
+		func(title string) bool {
+			events := []DueDateEvent{{LendingID: "l", Title: title, DueDate: time.Now()}}
+			got := RenderCalendar(events, time.Now())
+			for _, line := range strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n") {
+				if len(line) > 75 || !utf8.ValidString(line) {
+					return false
+				}
+			}
+			unfolded := strings.ReplaceAll(got, "\r\n ", "")
+			return strings.Contains(unfolded, "SUMMARY:"+escapeText("返却期限: "+title)+"\r\n")
+		},
+		gen.AnyString(),
+	))
+	properties.TestingRun(t)
+}
@@ -0,0 +1,99 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"holocron/internal/auth"
+)
+
+type IssueFeedHandler struct {
+	service *FeedService
+}
+
+func NewIssueFeedHandler(service *FeedService) *IssueFeedHandler {
+	return &IssueFeedHandler{service: service}
+}
+
+func (h *IssueFeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	output, err := h.service.IssueFeed(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"token":     string(output.Token),
+		"path":      output.Token.FeedPath(),
+		"createdAt": output.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+type RevokeFeedHandler struct {
+	service *FeedService
+}
+
+func NewRevokeFeedHandler(service *FeedService) *RevokeFeedHandler {
+	return &RevokeFeedHandler{service: service}
+}
+
+func (h *RevokeFeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	if err := h.service.RevokeFeed(r.Context(), userID); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type DueDatesFeedHandler struct {
+	service *FeedService
+}
+
+func NewDueDatesFeedHandler(service *FeedService) *DueDatesFeedHandler {
+	return &DueDatesFeedHandler{service: service}
+}
+
+func (h *DueDatesFeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, token string) {
+	body, err := h.service.DueDates(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, ErrFeedNotFound) {
+			writeError(w, http.StatusNotFound, "not_found", "feed not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	// The URL is a credential, so the feed must not be kept by shared caches.
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(body))
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,109 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"holocron/internal/calendar"
+	"holocron/internal/feed/domain"
+	"holocron/internal/lending"
+	lendingDomain "holocron/internal/lending/domain"
+)
+
+var ErrFeedNotFound = errors.New("calendar feed not found")
+
+type IssueFeedOutput struct {
+	Token     domain.FeedToken
+	CreatedAt time.Time
+}
+
+// FeedService publishes the due dates of a user's loans as a calendar feed
+// at a secret URL, which calendar apps fetch without the user's credentials.
+type FeedService struct {
+	queries        *Queries
+	lendingQueries *lending.Queries
+	calendar       calendar.Calendar
+	now            func() time.Time
+}
+
+func NewFeedService(queries *Queries, lendingQueries *lending.Queries, cal calendar.Calendar) *FeedService {
+	return &FeedService{
+		queries:        queries,
+		lendingQueries: lendingQueries,
+		calendar:       cal,
+		now:            func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// IssueFeed creates the user's feed token, replacing the previous one so
+// that its URL stops working.
+func (s *FeedService) IssueFeed(ctx context.Context, userID string) (*IssueFeedOutput, error) {
+	token, err := domain.GenerateFeedToken()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	err = s.queries.UpsertCalendarFeed(ctx, UpsertCalendarFeedParams{
+		UserID:    userID,
+		TokenHash: token.Hash(),
+		CreatedAt: now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &IssueFeedOutput{Token: token, CreatedAt: now}, nil
+}
+
+func (s *FeedService) RevokeFeed(ctx context.Context, userID string) error {
+	return s.queries.DeleteCalendarFeed(ctx, userID)
+}
+
+// DueDates renders the feed of the token's user with one event per loan the
+// user currently has.
+func (s *FeedService) DueDates(ctx context.Context, tokenString string) (string, error) {
+	token, err := domain.ParseFeedToken(tokenString)
+	if err != nil {
+		return "", ErrFeedNotFound
+	}
+	userID, err := s.queries.GetCalendarFeedUserID(ctx, token.Hash())
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrFeedNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	rows, err := s.lendingQueries.ListBorrowingBooksByBorrowerID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	events := make([]domain.DueDateEvent, 0, len(rows))
+	for _, row := range rows {
+		dueDate, err := dueDateOf(row, s.calendar)
+		if err != nil {
+			return "", err
+		}
+		events = append(events, domain.DueDateEvent{
+			LendingID: row.LendingID,
+			Title:     row.Title.String,
+			DueDate:   dueDate,
+			Sequence:  row.Extensions,
+		})
+	}
+	return domain.RenderCalendar(events, s.now()), nil
+}
+
+// dueDateOf returns the latest due date of the loan. Loans recorded before
+// due dates were stored are due on their legacy due date.
+func dueDateOf(row lending.ListBorrowingBooksByBorrowerIDRow, cal calendar.Calendar) (time.Time, error) {
+	if row.DueDate.Valid {
+		return time.Parse(time.RFC3339, row.DueDate.String)
+	}
+	borrowedAt, err := time.Parse(time.RFC3339, row.BorrowedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return lendingDomain.LegacyDueDate(borrowedAt, cal), nil
+}
@@ -0,0 +1,356 @@
+//go:build medium
+
+package feed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"holocron/internal/calendar"
+	"holocron/internal/lending"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE lending_events (
+			event_id TEXT PRIMARY KEY,
+			lending_id TEXT NOT NULL,
+			book_id TEXT NOT NULL,
+			borrower_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			due_date TEXT,
+			actor_id TEXT,
+			reason TEXT,
+			occurred_at TEXT NOT NULL
+		);
+
+		CREATE TABLE book_events (
+			event_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			authors TEXT,
+			publisher TEXT,
+			published_date TEXT,
+			thumbnail_url TEXT,
+			delete_reason TEXT,
+			delete_memo TEXT,
+			occurred_at TEXT NOT NULL
+		);
+
+		CREATE TABLE calendar_feeds (
+			user_id TEXT PRIMARY KEY,
+			token_hash TEXT NOT NULL UNIQUE,
+			created_at TEXT NOT NULL
+		);
+
+		-- open_lendings has the loans that have not been returned, transferred or
+		-- reported lost. due_date is the one set when the loan was opened.
+		CREATE VIEW open_lendings AS
+		SELECT
+			le.lending_id,
+			le.book_id,
+			le.borrower_id,
+			le.due_date,
+			le.occurred_at as borrowed_at
+		FROM lending_events le
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND NOT EXISTS (
+				SELECT 1
+				FROM lending_events closed
+				WHERE closed.lending_id = le.lending_id
+					AND closed.event_type IN ('returned', 'force_returned', 'transferred', 'lost')
+			);
+
+		CREATE VIEW deleted_books AS
+		SELECT book_id, MAX(occurred_at) as deleted_at
+		FROM book_events
+		WHERE event_type = 'deleted'
+		GROUP BY book_id;
+
+		-- latest_books has the latest details of each book since it was last deleted.
+		-- Events in the same second are ordered as they were recorded.
+		CREATE VIEW latest_books AS
+		SELECT book_id, code, title, authors, publisher, published_date, thumbnail_url, created_at, updated_at
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.code,
+				e1.title,
+				e1.authors,
+				e1.publisher,
+				e1.published_date,
+				e1.thumbnail_url,
+				(SELECT MIN(e_created.occurred_at)
+				 FROM book_events e_created
+				 WHERE e_created.book_id = e1.book_id
+				   AND e_created.event_type = 'created'
+				   AND e_created.occurred_at > COALESCE(d.deleted_at, '1970-01-01T00:00:00Z')
+				) as created_at,
+				e1.occurred_at as updated_at,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('created', 'updated')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- book_classifications has the latest tags_changed, category_changed and
+		-- shelf_changed event of each book, one row per event type.
+		CREATE VIEW book_classifications AS
+		SELECT book_id, event_type, tags, category_id, shelf_location
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.event_type,
+				e1.tags,
+				e1.category_id,
+				e1.shelf_location,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id, e1.event_type ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('tags_changed', 'category_changed', 'shelf_changed')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- current_lendings has the open loan of each book, with its latest due date.
+		CREATE VIEW current_lendings AS
+		SELECT lending_id, book_id, borrower_id, borrower_name, borrowed_at, due_date
+		FROM (
+			SELECT
+				ol.lending_id,
+				ol.book_id,
+				ol.borrower_id,
+				ue.name as borrower_name,
+				ol.borrowed_at,
+				(SELECT due.due_date
+				 FROM lending_events due
+				 WHERE due.lending_id = ol.lending_id
+				   AND due.event_type IN ('borrowed', 'lent', 'due_date_extended')
+				   AND due.due_date IS NOT NULL
+				 ORDER BY due.occurred_at DESC
+				 LIMIT 1
+				) as due_date,
+				ROW_NUMBER() OVER (PARTITION BY ol.book_id ORDER BY ol.borrowed_at DESC) as rn
+			FROM open_lendings ol
+			INNER JOIN user_events ue ON ue.user_id = ol.borrower_id AND ue.event_type = 'created'
+			LEFT JOIN deleted_books d ON ol.book_id = d.book_id
+			WHERE d.deleted_at IS NULL OR ol.borrowed_at > d.deleted_at
+		)
+		WHERE rn = 1;
+
+		-- borrow_counts counts the loans of each book since it was last deleted.
+		CREATE VIEW borrow_counts AS
+		SELECT le.book_id, COUNT(*) as borrow_count
+		FROM lending_events le
+		LEFT JOIN deleted_books d ON le.book_id = d.book_id
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND (d.deleted_at IS NULL OR le.occurred_at > d.deleted_at)
+		GROUP BY le.book_id;
+
+		-- book_list_items joins the current state of each book for the book list, the
+		-- search and the facets, which filter it with the same conditions.
+		CREATE VIEW book_list_items AS
+		SELECT
+			lb.book_id,
+			lb.code,
+			lb.title,
+			lb.authors,
+			lb.publisher,
+			lb.published_date,
+			lb.thumbnail_url,
+			lb.created_at,
+			lb.updated_at,
+			cl.lending_id,
+			cl.borrower_id,
+			cl.borrower_name,
+			cl.borrowed_at,
+			cl.due_date,
+			bt.tags,
+			bcat.category_id,
+			ce.name as category_name,
+			ce.code as category_code,
+			bs.shelf_location,
+			COALESCE(bc.borrow_count, 0) as borrow_count
+		FROM latest_books lb
+		LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id
+		LEFT JOIN book_classifications bt ON lb.book_id = bt.book_id AND bt.event_type = 'tags_changed'
+		LEFT JOIN book_classifications bcat ON lb.book_id = bcat.book_id AND bcat.event_type = 'category_changed'
+		LEFT JOIN book_classifications bs ON lb.book_id = bs.book_id AND bs.event_type = 'shelf_changed'
+		LEFT JOIN category_events ce ON bcat.category_id = ce.category_id AND ce.event_type = 'created'
+		LEFT JOIN borrow_counts bc ON lb.book_id = bc.book_id;
+	`)
+	if err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func insertBook(t *testing.T, db *sql.DB, bookID, title string) {
+	t.Helper()
+	_, err := db.Exec(
+		`INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at) VALUES (?, ?, 'created', ?, '["A"]', '2024-01-01T00:00:00Z')`,
+		uuid.New().String(), bookID, title,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert book event: %v", err)
+	}
+}
+
+func insertLendingEvent(t *testing.T, db *sql.DB, lendingID, bookID, borrowerID, eventType string, dueDate *string, occurredAt string) {
+	t.Helper()
+	_, err := db.Exec(
+		`INSERT INTO lending_events (event_id, lending_id, book_id, borrower_id, event_type, due_date, occurred_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		uuid.New().String(), lendingID, bookID, borrowerID, eventType, dueDate, occurredAt,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert lending event: %v", err)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func newTestService(db *sql.DB) *FeedService {
+	service := NewFeedService(New(db), lending.New(db), calendar.Calendar{})
+	service.now = func() time.Time { return time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC) }
+	return service
+}
+
+func TestFeedService_DueDates_ReturnsEventPerCurrentLoan(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(db)
+	ctx := context.Background()
+	userID := uuid.New().String()
+
+	insertBook(t, db, "book-1", "Borrowed")
+	insertBook(t, db, "book-2", "Extended")
+	insertBook(t, db, "book-3", "Returned")
+	insertLendingEvent(t, db, "lending-1", "book-1", userID, "borrowed", ptr("2024-02-08T01:00:00Z"), "2024-02-01T01:00:00Z")
+	insertLendingEvent(t, db, "lending-2", "book-2", userID, "borrowed", ptr("2024-02-08T01:00:00Z"), "2024-02-01T01:00:00Z")
+	insertLendingEvent(t, db, "lending-2", "book-2", userID, "due_date_extended", ptr("2024-02-15T01:00:00Z"), "2024-02-02T01:00:00Z")
+	insertLendingEvent(t, db, "lending-3", "book-3", userID, "borrowed", ptr("2024-02-08T01:00:00Z"), "2024-02-01T01:00:00Z")
+	insertLendingEvent(t, db, "lending-3", "book-3", userID, "returned", nil, "2024-02-02T01:00:00Z")
+	insertLendingEvent(t, db, "lending-4", "book-3", uuid.New().String(), "borrowed", ptr("2024-02-10T01:00:00Z"), "2024-02-03T01:00:00Z")
+
+	issued, err := service.IssueFeed(ctx, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// When fetching the feed with the issued token
+	got, err := service.DueDates(ctx, string(issued.Token))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Then only the user's current loans are included, the extended one with
+	// its new due date and a new sequence
+	if n := strings.Count(got, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("expected 2 events, got %d\n%s", n, got)
+	}
+	for _, want := range []string{
+		"UID:lending-lending-1@holocron\r\nDTSTAMP:20240203T000000Z\r\nSEQUENCE:0\r\nDTSTART;VALUE=DATE:20240208\r\n",
+		"UID:lending-lending-2@holocron\r\nDTSTAMP:20240203T000000Z\r\nSEQUENCE:1\r\nDTSTART;VALUE=DATE:20240215\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "lending-3") || strings.Contains(got, "lending-4") {
+		t.Errorf("expected returned and other users' loans to be excluded, got\n%s", got)
+	}
+}
+
+func TestFeedService_DueDates_WithoutDueDate_UsesDefaultDueDays(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(db)
+	ctx := context.Background()
+	userID := uuid.New().String()
+
+	insertBook(t, db, "book-1", "Old loan")
+	insertLendingEvent(t, db, "lending-1", "book-1", userID, "borrowed", nil, "2024-02-01T01:00:00Z")
+	issued, err := service.IssueFeed(ctx, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := service.DueDates(ctx, string(issued.Token))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(got, "DTSTART;VALUE=DATE:20240208\r\n") {
+		t.Errorf("expected the default due date, got\n%s", got)
+	}
+}
+
+func TestFeedService_IssueFeed_Again_RevokesPreviousToken(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(db)
+	ctx := context.Background()
+	userID := uuid.New().String()
+
+	first, err := service.IssueFeed(ctx, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := service.IssueFeed(ctx, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.DueDates(ctx, string(first.Token)); !errors.Is(err, ErrFeedNotFound) {
+		t.Errorf("expected ErrFeedNotFound for the previous token, got %v", err)
+	}
+	if _, err := service.DueDates(ctx, string(second.Token)); err != nil {
+		t.Errorf("unexpected error for the new token: %v", err)
+	}
+}
+
+func TestFeedService_RevokeFeed_DisablesToken(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(db)
+	ctx := context.Background()
+	userID := uuid.New().String()
+
+	issued, err := service.IssueFeed(ctx, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.RevokeFeed(ctx, userID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.DueDates(ctx, string(issued.Token)); !errors.Is(err, ErrFeedNotFound) {
+		t.Errorf("expected ErrFeedNotFound, got %v", err)
+	}
+}
+
+func TestFeedService_DueDates_WithUnknownToken_ReturnsNotFound(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(db)
+
+	for _, token := range []string{"invalid", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"} {
+		if _, err := service.DueDates(context.Background(), token); !errors.Is(err, ErrFeedNotFound) {
+			t.Errorf("%q: expected ErrFeedNotFound, got %v", token, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+import "errors"
+
+var (
+	ErrInvalidSymbology   = errors.New("invalid symbology")
+	ErrInvalidImageFormat = errors.New("invalid image format")
+)
+
+type Symbology string
+
+const (
+	SymbologyCode128 Symbology = "code128"
+	SymbologyQR      Symbology = "qr"
+)
+
+// ParseSymbology defaults to Code128, which the barcode readers at the borrow
+// kiosk read like an ISBN barcode.
+func ParseSymbology(s string) (Symbology, error) {
+	switch Symbology(s) {
+	case "":
+		return SymbologyCode128, nil
+	case SymbologyCode128, SymbologyQR:
+		return Symbology(s), nil
+	}
+	return "", ErrInvalidSymbology
+}
+
+// QuietZone is the blank margin a reader needs around the symbol, in modules.
+func (s Symbology) QuietZone() int {
+	if s == SymbologyQR {
+		return 4
+	}
+	return 10
+}
+
+type ImageFormat string
+
+const (
+	ImageFormatPNG ImageFormat = "png"
+	ImageFormatSVG ImageFormat = "svg"
+)
+
+func ParseImageFormat(s string) (ImageFormat, error) {
+	switch ImageFormat(s) {
+	case "":
+		return ImageFormatPNG, nil
+	case ImageFormatPNG, ImageFormatSVG:
+		return ImageFormat(s), nil
+	}
+	return "", ErrInvalidImageFormat
+}
+
+func (f ImageFormat) ContentType() string {
+	if f == ImageFormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// LabelContent is what a book's label encodes: the accession number when the
+// book has one, since it is shorter to scan and to read, and the book ID otherwise.
+func LabelContent(bookID string, accessionNumber *string) string {
+	if accessionNumber != nil && *accessionNumber != "" {
+		return *accessionNumber
+	}
+	return bookID
+}
@@ -0,0 +1,64 @@
+//go:build small
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestParseSymbology_WithEmpty_ReturnsCode128(t *testing.T) {
+	symbology, err := ParseSymbology("")
+	if err != nil || symbology != SymbologyCode128 {
+		t.Errorf("expected code128, got %q (%v)", symbology, err)
+	}
+}
+
+func TestParseSymbology_WithUnknownSymbology_ReturnsError(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("returns ErrInvalidSymbology for unknown symbologies", prop.ForAll(
+		func(s string) bool {
+			_, err := ParseSymbology(s)
+			return err == ErrInvalidSymbology
+		},
+		gen.AlphaString().SuchThat(func(s string) bool {
+			return s != "" && s != "code128" && s != "qr"
+		}),
+	))
+	properties.TestingRun(t)
+}
+
+func TestParseImageFormat_WithEmpty_ReturnsPNG(t *testing.T) {
+	format, err := ParseImageFormat("")
+	if err != nil || format != ImageFormatPNG {
+		t.Errorf("expected png, got %q (%v)", format, err)
+	}
+	if format.ContentType() != "image/png" {
+		t.Errorf("expected image/png, got %q", format.ContentType())
+	}
+}
+
+func TestParseImageFormat_WithUnknownFormat_ReturnsError(t *testing.T) {
+	if _, err := ParseImageFormat("gif"); err != ErrInvalidImageFormat {
+		t.Errorf("expected ErrInvalidImageFormat, got %v", err)
+	}
+}
+
+func TestLabelContent_WithAccessionNumber_PrefersAccessionNumber(t *testing.T) {
+	accessionNumber := "HC-000001"
+	if got := LabelContent("book-id", &accessionNumber); got != accessionNumber {
+		t.Errorf("expected %q, got %q", accessionNumber, got)
+	}
+}
+
+func TestLabelContent_WithoutAccessionNumber_ReturnsBookID(t *testing.T) {
+	empty := ""
+	for _, accessionNumber := range []*string{nil, &empty} {
+		if got := LabelContent("book-id", accessionNumber); got != "book-id" {
+			t.Errorf("expected book-id, got %q", got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrInvalidLabelStock = errors.New("invalid label stock")
+
+// LabelStock is a sheet of labels in a grid. Lengths are in millimetres.
+type LabelStock struct {
+	Name        string
+	PageWidth   float64
+	PageHeight  float64
+	Columns     int
+	Rows        int
+	LabelWidth  float64
+	LabelHeight float64
+	// MarginLeft and MarginTop locate the first label; PitchX and PitchY are the
+	// distances between the left and top edges of neighbouring labels.
+	MarginLeft float64
+	MarginTop  float64
+	PitchX     float64
+	PitchY     float64
+}
+
+// LabelStocks are common sheets: 24 and 65 labels on A4, and 30 on US Letter.
+var LabelStocks = []LabelStock{
+	{Name: "a4-24", PageWidth: 210, PageHeight: 297, Columns: 3, Rows: 8, LabelWidth: 70, LabelHeight: 37, MarginLeft: 0, MarginTop: 0.5, PitchX: 70, PitchY: 37},
+	{Name: "a4-65", PageWidth: 210, PageHeight: 297, Columns: 5, Rows: 13, LabelWidth: 38.1, LabelHeight: 21.2, MarginLeft: 4.7, MarginTop: 10.7, PitchX: 40.6, PitchY: 21.2},
+	{Name: "letter-30", PageWidth: 215.9, PageHeight: 279.4, Columns: 3, Rows: 10, LabelWidth: 66.675, LabelHeight: 25.4, MarginLeft: 4.7625, MarginTop: 12.7, PitchX: 69.85, PitchY: 25.4},
+}
+
+func ParseLabelStock(s string) (LabelStock, error) {
+	if s == "" {
+		return LabelStocks[0], nil
+	}
+	i := slices.IndexFunc(LabelStocks, func(stock LabelStock) bool { return stock.Name == s })
+	if i < 0 {
+		return LabelStock{}, ErrInvalidLabelStock
+	}
+	return LabelStocks[i], nil
+}
+
+func (s LabelStock) PerPage() int {
+	return s.Columns * s.Rows
+}
+
+// Position returns the page and the top left corner of the label at index,
+// filling each page row by row.
+func (s LabelStock) Position(index int) (page int, x, y float64) {
+	page = index / s.PerPage()
+	cell := index % s.PerPage()
+	x = s.MarginLeft + float64(cell%s.Columns)*s.PitchX
+	y = s.MarginTop + float64(cell/s.Columns)*s.PitchY
+	return page, x, y
+}
@@ -0,0 +1,58 @@
+//go:build small
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestParseLabelStock_WithEmpty_ReturnsFirstStock(t *testing.T) {
+	stock, err := ParseLabelStock("")
+	if err != nil || stock.Name != "a4-24" {
+		t.Errorf("expected a4-24, got %q (%v)", stock.Name, err)
+	}
+}
+
+func TestParseLabelStock_WithUnknownStock_ReturnsError(t *testing.T) {
+	if _, err := ParseLabelStock("a5-8"); err != ErrInvalidLabelStock {
+		t.Errorf("expected ErrInvalidLabelStock, got %v", err)
+	}
+}
+
+func TestLabelStocks_FitOnPage(t *testing.T) {
+	for _, stock := range LabelStocks {
+		_, x, y := stock.Position(stock.PerPage() - 1)
+		if x+stock.LabelWidth > stock.PageWidth+0.01 || y+stock.LabelHeight > stock.PageHeight+0.01 {
+			t.Errorf("%s: last label at (%v, %v) does not fit on the page", stock.Name, x, y)
+		}
+	}
+}
+
+func TestLabelStock_Position_FillsPagesRowByRow(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("labels on a page do not overlap and the next page starts at the first position", prop.ForAll(
+		func(stockIndex, index int) bool {
+			stock := LabelStocks[stockIndex]
+			page, x, y := stock.Position(index)
+			nextPage, nextX, nextY := stock.Position(index + stock.PerPage())
+			if nextPage != page+1 || nextX != x || nextY != y {
+				return false
+			}
+			if page != index/stock.PerPage() {
+				return false
+			}
+			if (index+1)%stock.Columns == 0 {
+				return true
+			}
+			_, rightX, rightY := stock.Position(index + 1)
+			return rightY == y && rightX >= x+stock.LabelWidth
+		},
+		gen.IntRange(0, len(LabelStocks)-1),
+		gen.IntRange(0, 5000),
+	))
+	properties.TestingRun(t)
+}
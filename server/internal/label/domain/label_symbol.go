@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+// Symbol is an encoded barcode as a grid of modules. A linear barcode such as
+// Code128 has a single row.
+type Symbol struct {
+	Symbology Symbology
+	Width     int
+	Height    int
+	dark      []bool
+}
+
+func NewSymbol(symbology Symbology, width, height int, isDark func(x, y int) bool) Symbol {
+	dark := make([]bool, width*height)
+	for y := range height {
+		for x := range width {
+			dark[y*width+x] = isDark(x, y)
+		}
+	}
+	return Symbol{Symbology: symbology, Width: width, Height: height, dark: dark}
+}
+
+func (s Symbol) IsDark(x, y int) bool {
+	return s.dark[y*s.Width+x]
+}
+
+// Run is a horizontal stretch of dark modules, drawn as one rectangle.
+type Run struct {
+	X, Y, Length int
+}
+
+// Runs merges the dark modules of each row into runs, which keeps vector
+// output small.
+func (s Symbol) Runs() []Run {
+	var runs []Run
+	for y := range s.Height {
+		for x := 0; x < s.Width; {
+			if !s.IsDark(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < s.Width && s.IsDark(x, y) {
+				x++
+			}
+			runs = append(runs, Run{X: start, Y: y, Length: x - start})
+		}
+	}
+	return runs
+}
+
+// ImageLayout is the geometry of a single label image in pixels: the symbol
+// with its quiet zone, and the content printed below it.
+type ImageLayout struct {
+	Width, Height int
+	// Module is the size of a module; a linear symbol's bars are BarHeight tall.
+	Module    int
+	BarHeight int
+	SymbolX   int
+	SymbolY   int
+	TextY     int
+}
+
+const (
+	linearModulePixels = 2
+	linearBarPixels    = 80
+	matrixModulePixels = 6
+	labelTextPixels    = 20
+)
+
+func (s Symbol) ImageLayout() ImageLayout {
+	quiet := s.Symbology.QuietZone()
+	if s.Height == 1 {
+		module := linearModulePixels
+		width := (s.Width + 2*quiet) * module
+		return ImageLayout{
+			Width:     width,
+			Height:    module*quiet + linearBarPixels + labelTextPixels + module*quiet/2,
+			Module:    module,
+			BarHeight: linearBarPixels,
+			SymbolX:   quiet * module,
+			SymbolY:   quiet * module,
+			TextY:     quiet*module + linearBarPixels + labelTextPixels - 5,
+		}
+	}
+	module := matrixModulePixels
+	side := (s.Width + 2*quiet) * module
+	return ImageLayout{
+		Width:     side,
+		Height:    side + labelTextPixels,
+		Module:    module,
+		BarHeight: s.Height * module,
+		SymbolX:   quiet * module,
+		SymbolY:   quiet * module,
+		TextY:     side + labelTextPixels - 8,
+	}
+}
+
+// RowHeight is the height of one row of modules in the layout.
+func (l ImageLayout) RowHeight(s Symbol) int {
+	if s.Height == 1 {
+		return l.BarHeight
+	}
+	return l.Module
+}
+
+// LabelSVG renders a label with the symbol and, below it, the encoded text.
+func LabelSVG(s Symbol, text string) []byte {
+	l := s.ImageLayout()
+	rowHeight := l.RowHeight(s)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		l.Width, l.Height, l.Width, l.Height)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/>`, l.Width, l.Height)
+	buf.WriteString(`<path fill="#000" d="`)
+	for _, r := range s.Runs() {
+		fmt.Fprintf(&buf, "M%d %dh%dv%dh-%dz", l.SymbolX+r.X*l.Module, l.SymbolY+r.Y*rowHeight, r.Length*l.Module, rowHeight, r.Length*l.Module)
+	}
+	buf.WriteString(`"/>`)
+	fmt.Fprintf(&buf, `<text x="%d" y="%d" font-family="monospace" font-size="14" text-anchor="middle" fill="#000">%s</text>`,
+		l.Width/2, l.TextY, html.EscapeString(text))
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
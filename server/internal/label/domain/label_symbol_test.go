@@ -0,0 +1,67 @@
+//go:build small
+
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestSymbol_Runs_CoverExactlyTheDarkModules(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("every dark module is in exactly one run and runs are separated by light modules", prop.ForAll(
+		func(modules []bool) bool {
+			width := len(modules)
+			symbol := NewSymbol(SymbologyCode128, width, 1, func(x, _ int) bool { return modules[x] })
+			covered := make([]bool, width)
+			for _, r := range symbol.Runs() {
+				if r.Length < 1 || (r.X > 0 && modules[r.X-1]) || (r.X+r.Length < width && modules[r.X+r.Length]) {
+					return false
+				}
+				for x := r.X; x < r.X+r.Length; x++ {
+					if covered[x] {
+						return false
+					}
+					covered[x] = true
+				}
+			}
+			for x := range width {
+				if covered[x] != modules[x] {
+					return false
+				}
+			}
+			return true
+		},
+		gen.SliceOfN(64, gen.Bool()),
+	))
+	properties.TestingRun(t)
+}
+
+func TestSymbol_ImageLayout_KeepsQuietZone(t *testing.T) {
+	linear := NewSymbol(SymbologyCode128, 50, 1, func(x, _ int) bool { return x%2 == 0 })
+	l := linear.ImageLayout()
+	if l.SymbolX != 10*l.Module || l.Width != l.SymbolX*2+50*l.Module {
+		t.Errorf("unexpected linear layout: %+v", l)
+	}
+
+	matrix := NewSymbol(SymbologyQR, 21, 21, func(x, y int) bool { return (x+y)%2 == 0 })
+	l = matrix.ImageLayout()
+	if l.SymbolX != 4*l.Module || l.Width != l.SymbolX*2+21*l.Module || l.Height <= l.Width {
+		t.Errorf("unexpected matrix layout: %+v", l)
+	}
+}
+
+func TestLabelSVG_EscapesText(t *testing.T) {
+	symbol := NewSymbol(SymbologyCode128, 3, 1, func(x, _ int) bool { return x == 1 })
+	svg := string(LabelSVG(symbol, "<HC>"))
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, "&lt;HC&gt;") {
+		t.Errorf("unexpected SVG: %s", svg)
+	}
+	if !strings.Contains(svg, "M22 20h2v80h-2z") {
+		t.Errorf("expected a single bar after the quiet zone, got %s", svg)
+	}
+}
@@ -0,0 +1,114 @@
+package label
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"holocron/internal/api"
+	"holocron/internal/label/domain"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type GetLabelHandler struct {
+	service *LabelService
+}
+
+func NewGetLabelHandler(service *LabelService) *GetLabelHandler {
+	return &GetLabelHandler{service: service}
+}
+
+func (h *GetLabelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID, params api.GetBooksLabelParams) {
+	input := GetLabelInput{BookID: bookId.String()}
+	if params.Format != nil {
+		input.Format = string(*params.Format)
+	}
+	if params.Symbology != nil {
+		input.Symbology = string(*params.Symbology)
+	}
+
+	output, err := h.service.GetLabel(r.Context(), input)
+	if err != nil {
+		writeLabelError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", output.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(output.Data)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(output.Data)
+}
+
+type GetLabelSheetHandler struct {
+	service *LabelService
+}
+
+func NewGetLabelSheetHandler(service *LabelService) *GetLabelSheetHandler {
+	return &GetLabelSheetHandler{service: service}
+}
+
+func (h *GetLabelSheetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.GetBooksLabelsParams) {
+	var input GetLabelSheetInput
+	if params.BookIds != nil {
+		for _, id := range *params.BookIds {
+			input.BookIDs = append(input.BookIDs, id.String())
+		}
+	}
+	if params.Symbology != nil {
+		input.Symbology = string(*params.Symbology)
+	}
+	if params.Stock != nil {
+		input.Stock = string(*params.Stock)
+	}
+	if params.Skip != nil {
+		input.Skip = *params.Skip
+	}
+
+	output, err := h.service.GetLabelSheet(r.Context(), input)
+	if err != nil {
+		writeLabelError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", output.ContentType)
+	w.Header().Set("Content-Disposition", `inline; filename="labels.pdf"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(output.Data)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(output.Data)
+}
+
+func writeLabelError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidImageFormat):
+		writeError(w, http.StatusBadRequest, "invalid_request", "format must be png or svg")
+	case errors.Is(err, domain.ErrInvalidSymbology):
+		writeError(w, http.StatusBadRequest, "invalid_request", "symbology must be code128 or qr")
+	case errors.Is(err, domain.ErrInvalidLabelStock):
+		writeError(w, http.StatusBadRequest, "invalid_request", "unknown label stock")
+	case errors.Is(err, ErrInvalidSkip):
+		writeError(w, http.StatusBadRequest, "invalid_request", "skip must be less than the labels on a page")
+	case errors.Is(err, ErrTooManyLabels):
+		writeError(w, http.StatusBadRequest, "invalid_request", fmt.Sprintf("at most %d labels can be printed at once", MaxSheetLabels))
+	case errors.Is(err, ErrNoLabels):
+		writeError(w, http.StatusBadRequest, "invalid_request", "no books to label")
+	case errors.Is(err, ErrInvalidBookID):
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid book ID")
+	case errors.Is(err, ErrBookNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "book not found")
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,163 @@
+package label
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"holocron/internal/label/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBookNotFound  = errors.New("book not found")
+	ErrInvalidBookID = errors.New("invalid book ID")
+	ErrNoLabels      = errors.New("no books to label")
+	ErrTooManyLabels = errors.New("too many labels")
+	ErrInvalidSkip   = errors.New("skip must be between 0 and the labels on a page")
+)
+
+// MaxSheetLabels bounds a single sheet request, which renders in memory.
+const MaxSheetLabels = 1000
+
+type GetLabelInput struct {
+	BookID    string
+	Format    string
+	Symbology string
+}
+
+type GetLabelSheetInput struct {
+	// BookIDs are labelled in order. When empty, every book with an accession
+	// number is labelled in accession order.
+	BookIDs   []string
+	Symbology string
+	Stock     string
+	// Skip leaves the first positions of the first page blank, so a partly used
+	// sheet can be fed again.
+	Skip int
+}
+
+type LabelOutput struct {
+	ContentType string
+	Data        []byte
+}
+
+type LabelService struct {
+	queries *Queries
+}
+
+func NewLabelService(queries *Queries) *LabelService {
+	return &LabelService{queries: queries}
+}
+
+// GetLabel renders the label of a single book as an image.
+func (s *LabelService) GetLabel(ctx context.Context, input GetLabelInput) (*LabelOutput, error) {
+	format, err := domain.ParseImageFormat(input.Format)
+	if err != nil {
+		return nil, err
+	}
+	symbology, err := domain.ParseSymbology(input.Symbology)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.labelContent(ctx, input.BookID)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := encodeSymbol(content, symbology)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	switch format {
+	case domain.ImageFormatSVG:
+		data = domain.LabelSVG(symbol, content)
+	default:
+		data, err = renderPNG(symbol, content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &LabelOutput{ContentType: format.ContentType(), Data: data}, nil
+}
+
+// GetLabelSheet renders labels for printing on label stock as a PDF.
+func (s *LabelService) GetLabelSheet(ctx context.Context, input GetLabelSheetInput) (*LabelOutput, error) {
+	symbology, err := domain.ParseSymbology(input.Symbology)
+	if err != nil {
+		return nil, err
+	}
+	stock, err := domain.ParseLabelStock(input.Stock)
+	if err != nil {
+		return nil, err
+	}
+	if input.Skip < 0 || input.Skip >= stock.PerPage() {
+		return nil, ErrInvalidSkip
+	}
+
+	var contents []string
+	if len(input.BookIDs) == 0 {
+		rows, err := s.queries.ListAccessionNumberedBooks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			contents = append(contents, domain.LabelContent(row.BookID, nullStringPtr(row.AccessionNumber)))
+		}
+	} else {
+		if len(input.BookIDs) > MaxSheetLabels {
+			return nil, ErrTooManyLabels
+		}
+		for _, bookID := range input.BookIDs {
+			content, err := s.labelContent(ctx, bookID)
+			if err != nil {
+				return nil, err
+			}
+			contents = append(contents, content)
+		}
+	}
+	if len(contents) == 0 {
+		return nil, ErrNoLabels
+	}
+	if len(contents) > MaxSheetLabels {
+		return nil, ErrTooManyLabels
+	}
+
+	labels := make([]sheetLabel, 0, len(contents))
+	for _, content := range contents {
+		symbol, err := encodeSymbol(content, symbology)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, sheetLabel{symbol: symbol, text: content})
+	}
+	data, err := renderSheet(labels, stock, input.Skip)
+	if err != nil {
+		return nil, err
+	}
+	return &LabelOutput{ContentType: "application/pdf", Data: data}, nil
+}
+
+func (s *LabelService) labelContent(ctx context.Context, bookID string) (string, error) {
+	if _, err := uuid.Parse(bookID); err != nil {
+		return "", ErrInvalidBookID
+	}
+	row, err := s.queries.GetLabelBook(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrBookNotFound
+		}
+		return "", err
+	}
+	return domain.LabelContent(row.BookID, nullStringPtr(row.AccessionNumber)), nil
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
@@ -0,0 +1,219 @@
+//go:build medium
+
+package label
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"holocron/internal/label/domain"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE book_events (
+			event_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			authors TEXT,
+			publisher TEXT,
+			published_date TEXT,
+			thumbnail_url TEXT,
+			delete_reason TEXT,
+			delete_memo TEXT,
+			origin TEXT,
+			cover_id TEXT,
+			tags TEXT,
+			category_id TEXT,
+			shelf_location TEXT,
+			series_id TEXT,
+			volume_number INTEGER,
+			accession_number TEXT,
+			condition TEXT,
+			condition_note TEXT,
+			lending_id TEXT,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE INDEX idx_book_events_book_id ON book_events(book_id);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func insertBook(t *testing.T, db *sql.DB, accessionNumber *string) string {
+	t.Helper()
+	bookID := uuid.New().String()
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	_, err := db.Exec(
+		`INSERT INTO book_events (event_id, book_id, event_type, title, authors, occurred_at) VALUES (?, ?, 'created', 'title', '["author"]', ?)`,
+		uuid.New().String(), bookID, now,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert book: %v", err)
+	}
+	if accessionNumber != nil {
+		_, err = db.Exec(
+			`INSERT INTO book_events (event_id, book_id, event_type, accession_number, occurred_at) VALUES (?, ?, 'accession_assigned', ?, ?)`,
+			uuid.New().String(), bookID, *accessionNumber, now,
+		)
+		if err != nil {
+			t.Fatalf("failed to assign accession number: %v", err)
+		}
+	}
+	return bookID
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestGetLabel_WithAccessionNumber_RendersPNG(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewLabelService(New(db))
+	bookID := insertBook(t, db, ptr("HC-000001"))
+
+	output, err := service.GetLabel(context.Background(), GetLabelInput{BookID: bookID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.ContentType != "image/png" {
+		t.Errorf("expected image/png, got %s", output.ContentType)
+	}
+	img, err := png.Decode(bytes.NewReader(output.Data))
+	if err != nil {
+		t.Fatalf("failed to decode PNG: %v", err)
+	}
+	symbol, err := encodeSymbol("HC-000001", domain.SymbologyCode128)
+	if err != nil {
+		t.Fatalf("failed to encode symbol: %v", err)
+	}
+	if img.Bounds().Dx() != symbol.ImageLayout().Width {
+		t.Errorf("expected width %d, got %d", symbol.ImageLayout().Width, img.Bounds().Dx())
+	}
+}
+
+func TestGetLabel_WithoutAccessionNumber_EncodesBookIDAsSVG(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewLabelService(New(db))
+	bookID := insertBook(t, db, nil)
+
+	output, err := service.GetLabel(context.Background(), GetLabelInput{BookID: bookID, Format: "svg", Symbology: "qr"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svg := string(output.Data)
+	if output.ContentType != "image/svg+xml" || !strings.HasPrefix(svg, "<svg") {
+		t.Errorf("expected an SVG, got %s", output.ContentType)
+	}
+	if !strings.Contains(svg, bookID) {
+		t.Errorf("expected the book ID to be printed on the label")
+	}
+}
+
+func TestGetLabel_WithNonExistentBook_ReturnsNotFoundError(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewLabelService(New(db))
+
+	_, err := service.GetLabel(context.Background(), GetLabelInput{BookID: uuid.New().String()})
+
+	if !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+}
+
+func TestGetLabel_WithUnknownFormat_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewLabelService(New(db))
+	bookID := insertBook(t, db, nil)
+
+	_, err := service.GetLabel(context.Background(), GetLabelInput{BookID: bookID, Format: "gif"})
+
+	if !errors.Is(err, domain.ErrInvalidImageFormat) {
+		t.Errorf("expected ErrInvalidImageFormat, got %v", err)
+	}
+}
+
+func TestGetLabelSheet_WithoutBookIDs_LabelsNumberedBooksOnPages(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewLabelService(New(db))
+	for i := range 30 {
+		insertBook(t, db, ptr(fmt.Sprintf("HC-%06d", i+1)))
+	}
+	insertBook(t, db, nil)
+
+	output, err := service.GetLabelSheet(context.Background(), GetLabelSheetInput{Stock: "a4-24", Skip: 20})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.ContentType != "application/pdf" || !bytes.HasPrefix(output.Data, []byte("%PDF")) {
+		t.Fatalf("expected a PDF")
+	}
+	// 30 labels after 20 skipped positions fill the rest of the first page and
+	// one more page of 24.
+	if pages := bytes.Count(output.Data, []byte("/Type /Page\n")); pages != 3 {
+		t.Errorf("expected 3 pages, got %d", pages)
+	}
+}
+
+func TestGetLabelSheet_WithNoNumberedBooks_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewLabelService(New(db))
+	insertBook(t, db, nil)
+
+	_, err := service.GetLabelSheet(context.Background(), GetLabelSheetInput{})
+
+	if !errors.Is(err, ErrNoLabels) {
+		t.Errorf("expected ErrNoLabels, got %v", err)
+	}
+}
+
+func TestGetLabelSheet_WithSkipOfWholePage_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewLabelService(New(db))
+	bookID := insertBook(t, db, nil)
+
+	_, err := service.GetLabelSheet(context.Background(), GetLabelSheetInput{BookIDs: []string{bookID}, Stock: "a4-65", Skip: 65})
+
+	if !errors.Is(err, ErrInvalidSkip) {
+		t.Errorf("expected ErrInvalidSkip, got %v", err)
+	}
+}
+
+func TestGetLabelSheet_WithDeletedBook_ReturnsNotFoundError(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewLabelService(New(db))
+	bookID := insertBook(t, db, ptr("HC-000001"))
+	_, err := db.Exec(
+		`INSERT INTO book_events (event_id, book_id, event_type, occurred_at) VALUES (?, ?, 'deleted', ?)`,
+		uuid.New().String(), bookID, time.Now().UTC().Add(time.Second).Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		t.Fatalf("failed to delete book: %v", err)
+	}
+
+	_, err = service.GetLabelSheet(context.Background(), GetLabelSheetInput{BookIDs: []string{bookID}})
+
+	if !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+}
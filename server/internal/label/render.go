@@ -0,0 +1,137 @@
+package label
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+
+	"holocron/internal/label/domain"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+func encodeSymbol(content string, symbology domain.Symbology) (domain.Symbol, error) {
+	var code barcode.Barcode
+	var err error
+	switch symbology {
+	case domain.SymbologyQR:
+		code, err = qr.Encode(content, qr.M, qr.Auto)
+	default:
+		code, err = code128.Encode(content)
+	}
+	if err != nil {
+		return domain.Symbol{}, err
+	}
+
+	bounds := code.Bounds()
+	return domain.NewSymbol(symbology, bounds.Dx(), bounds.Dy(), func(x, y int) bool {
+		return color.GrayModel.Convert(code.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y < 128
+	}), nil
+}
+
+func renderPNG(symbol domain.Symbol, text string) ([]byte, error) {
+	l := symbol.ImageLayout()
+	rowHeight := l.RowHeight(symbol)
+
+	img := image.NewGray(image.Rect(0, 0, l.Width, l.Height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for _, r := range symbol.Runs() {
+		x0 := l.SymbolX + r.X*l.Module
+		y0 := l.SymbolY + r.Y*rowHeight
+		for y := y0; y < y0+rowHeight; y++ {
+			for x := x0; x < x0+r.Length*l.Module; x++ {
+				img.SetGray(x, y, color.Gray{})
+			}
+		}
+	}
+
+	d := &font.Drawer{Dst: img, Src: image.Black, Face: basicfont.Face7x13}
+	d.Dot = fixed.P((l.Width-d.MeasureString(text).Ceil())/2, l.TextY)
+	d.DrawString(text)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type sheetLabel struct {
+	symbol domain.Symbol
+	text   string
+}
+
+const (
+	// sheetPadding keeps the symbol clear of the label edges, which label
+	// printers do not place exactly.
+	sheetPadding  = 2.0
+	sheetTextSize = 7.0
+	sheetTextArea = 3.5
+)
+
+// renderSheet lays the labels out on the stock as a PDF, leaving the first skip
+// positions of the first page blank for partly used sheets.
+func renderSheet(labels []sheetLabel, stock domain.LabelStock, skip int) ([]byte, error) {
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		UnitStr: "mm",
+		Size:    gofpdf.SizeType{Wd: stock.PageWidth, Ht: stock.PageHeight},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetFont("Helvetica", "", sheetTextSize)
+	pdf.SetFillColor(0, 0, 0)
+
+	page := -1
+	for i, label := range labels {
+		p, x, y := stock.Position(skip + i)
+		for page < p {
+			pdf.AddPage()
+			page++
+		}
+		drawSheetLabel(pdf, label, x, y, stock.LabelWidth, stock.LabelHeight)
+	}
+	if page < 0 {
+		pdf.AddPage()
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawSheetLabel(pdf *gofpdf.Fpdf, label sheetLabel, x, y, width, height float64) {
+	symbol := label.symbol
+	quiet := float64(symbol.Symbology.QuietZone())
+	areaWidth := width - 2*sheetPadding
+	areaHeight := height - 2*sheetPadding - sheetTextArea
+
+	var module, rowHeight, left, top float64
+	if symbol.Height == 1 {
+		module = areaWidth / (float64(symbol.Width) + 2*quiet)
+		rowHeight = areaHeight
+		left = x + sheetPadding + quiet*module
+		top = y + sheetPadding
+	} else {
+		module = min(areaWidth, areaHeight) / (float64(symbol.Width) + 2*quiet)
+		rowHeight = module
+		left = x + (width-float64(symbol.Width)*module)/2
+		top = y + sheetPadding + quiet*module
+	}
+	for _, r := range symbol.Runs() {
+		pdf.Rect(left+float64(r.X)*module, top+float64(r.Y)*rowHeight, float64(r.Length)*module, rowHeight, "F")
+	}
+
+	textWidth := pdf.GetStringWidth(label.text)
+	pdf.Text(x+(width-textWidth)/2, y+height-sheetPadding, label.text)
+}
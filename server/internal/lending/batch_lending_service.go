@@ -0,0 +1,189 @@
+package lending
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"holocron/internal/lending/domain"
+)
+
+var ErrDuplicateBatchItem = errors.New("book appears more than once in the batch")
+
+type BatchBorrowInput struct {
+	// BookIDs are book IDs or accession numbers.
+	BookIDs    []string
+	BorrowerID string
+	DueDays    *int
+	// Atomic makes the batch all-or-nothing: if any item fails, no book is
+	// borrowed.
+	Atomic bool
+}
+
+type BatchReturnInput struct {
+	// BookIDs are book IDs or accession numbers.
+	BookIDs     []string
+	RequesterID string
+	// Atomic makes the batch all-or-nothing: if any item fails, no book is
+	// returned.
+	Atomic bool
+}
+
+// BatchItem is the result of one item, in the order of the request. Err is set
+// when Status is failed; the outputs are set when it is succeeded or, for an
+// all-or-nothing batch, rolled back.
+type BatchItem struct {
+	Ref      string
+	Status   domain.BatchItemStatus
+	Borrowed *BorrowBookOutput
+	Returned *ReturnBookOutput
+	Err      error
+}
+
+type BatchOutput struct {
+	Items []BatchItem
+}
+
+func (o *BatchOutput) Failed() bool {
+	for _, item := range o.Items {
+		if item.Status == domain.BatchItemFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// BatchLendingService borrows or returns several books in one request, one
+// item at a time with BorrowBookService and ReturnBookService. An all-or-nothing
+// batch runs every item in a single transaction and rolls it back if any item
+// fails; the other items are still attempted so that every failure is reported.
+type BatchLendingService struct {
+	db     *sql.DB
+	borrow *BorrowBookService
+	ret    *ReturnBookService
+	// bookQueriesTx returns the book queries to use in a transaction.
+	bookQueriesTx func(tx *sql.Tx) BookQueries
+}
+
+func NewBatchLendingService(db *sql.DB, borrow *BorrowBookService, ret *ReturnBookService, bookQueriesTx func(tx *sql.Tx) BookQueries) *BatchLendingService {
+	return &BatchLendingService{
+		db:            db,
+		borrow:        borrow,
+		ret:           ret,
+		bookQueriesTx: bookQueriesTx,
+	}
+}
+
+func (s *BatchLendingService) BorrowBooks(ctx context.Context, input BatchBorrowInput) (*BatchOutput, error) {
+	// The due days are the same for every item, so they are checked once
+	// instead of failing each item.
+	if input.DueDays != nil && *input.DueDays < 1 {
+		return nil, domain.ErrInvalidDueDays
+	}
+	return s.run(ctx, input.BookIDs, input.Atomic, func(tx *sql.Tx, bookQueries BookQueries, bookID string, item *BatchItem) error {
+		borrow := s.borrow
+		if tx != nil {
+			borrow = borrow.withTx(tx, bookQueries)
+		}
+		output, err := borrow.BorrowBook(ctx, BorrowBookInput{
+			BookID:     bookID,
+			BorrowerID: input.BorrowerID,
+			DueDays:    input.DueDays,
+		})
+		item.Borrowed = output
+		return err
+	})
+}
+
+func (s *BatchLendingService) ReturnBooks(ctx context.Context, input BatchReturnInput) (*BatchOutput, error) {
+	return s.run(ctx, input.BookIDs, input.Atomic, func(tx *sql.Tx, bookQueries BookQueries, bookID string, item *BatchItem) error {
+		ret := s.ret
+		if tx != nil {
+			ret = ret.withTx(tx, bookQueries)
+		}
+		output, err := ret.ReturnBook(ctx, ReturnBookInput{
+			BookID:      bookID,
+			RequesterID: input.RequesterID,
+		})
+		item.Returned = output
+		return err
+	})
+}
+
+type batchAction func(tx *sql.Tx, bookQueries BookQueries, bookID string, item *BatchItem) error
+
+// run applies action to each item. References are resolved to book IDs first,
+// so that a book given by both its ID and its accession number is caught as a
+// duplicate instead of being borrowed twice.
+func (s *BatchLendingService) run(ctx context.Context, refs []string, atomic bool, action batchAction) (*BatchOutput, error) {
+	refs, err := domain.ParseBatchRefs(refs)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx *sql.Tx
+	bookQueries := s.borrow.bookQueries
+	if atomic {
+		tx, err = s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = tx.Rollback() }()
+		bookQueries = s.bookQueriesTx(tx)
+	}
+
+	output := &BatchOutput{Items: make([]BatchItem, len(refs))}
+	seen := make(map[string]bool, len(refs))
+	for i, ref := range refs {
+		item := &output.Items[i]
+		item.Ref = ref
+
+		bookID, err := resolveBookID(ctx, bookQueries, ref)
+		if err == nil && seen[bookID] {
+			err = ErrDuplicateBatchItem
+		}
+		if err == nil {
+			seen[bookID] = true
+			err = action(tx, bookQueries, bookID, item)
+		}
+		if err != nil {
+			if !isBatchItemError(err) {
+				return nil, err
+			}
+			item.Status = domain.BatchItemFailed
+			item.Err = err
+			continue
+		}
+		item.Status = domain.BatchItemSucceeded
+	}
+
+	if !atomic {
+		return output, nil
+	}
+	if output.Failed() {
+		for i := range output.Items {
+			if output.Items[i].Status == domain.BatchItemSucceeded {
+				output.Items[i].Status = domain.BatchItemRolledBack
+			}
+		}
+		return output, nil
+	}
+	return output, tx.Commit()
+}
+
+// isBatchItemError reports whether err is a failure of the item itself rather
+// than of the batch, such as a database error.
+func isBatchItemError(err error) bool {
+	for _, target := range []error{
+		ErrBookNotFound,
+		ErrBookAlreadyBorrowed,
+		ErrBookNotBorrowed,
+		ErrNotBorrower,
+		ErrDuplicateBatchItem,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
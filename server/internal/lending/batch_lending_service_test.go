@@ -0,0 +1,220 @@
+//go:build medium
+
+package lending
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"holocron/internal/calendar"
+	"holocron/internal/lending/domain"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newBatchTestService(t *testing.T, bookQueries *fakeBookQueries) (*BatchLendingService, *Queries) {
+	t.Helper()
+	db := setupTestDB(t)
+	lendingQueries := New(db)
+	service := NewBatchLendingService(
+		db,
+		NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{}),
+		NewReturnBookService(lendingQueries, bookQueries, calendar.Calendar{}),
+		func(*sql.Tx) BookQueries { return bookQueries },
+	)
+	return service, lendingQueries
+}
+
+func newBatchTestBooks(n int) ([]string, *fakeBookQueries) {
+	bookIDs := make([]string, n)
+	bookQueries := &fakeBookQueries{countByBookId: map[string]int64{}}
+	for i := range bookIDs {
+		bookIDs[i] = uuid.New().String()
+		bookQueries.countByBookId[bookIDs[i]] = 1
+	}
+	return bookIDs, bookQueries
+}
+
+func statuses(output *BatchOutput) []domain.BatchItemStatus {
+	result := make([]domain.BatchItemStatus, len(output.Items))
+	for i, item := range output.Items {
+		result[i] = item.Status
+	}
+	return result
+}
+
+func TestBorrowBooks_WithUnknownBook_BorrowsTheOthers(t *testing.T) {
+	bookIDs, bookQueries := newBatchTestBooks(2)
+	service, lendingQueries := newBatchTestService(t, bookQueries)
+	ctx := context.Background()
+	userID := uuid.New().String()
+
+	output, err := service.BorrowBooks(ctx, BatchBorrowInput{
+		BookIDs:    []string{bookIDs[0], uuid.New().String(), bookIDs[1]},
+		BorrowerID: userID,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := statuses(output)
+	want := []domain.BatchItemStatus{domain.BatchItemSucceeded, domain.BatchItemFailed, domain.BatchItemSucceeded}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	if !errors.Is(output.Items[1].Err, ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", output.Items[1].Err)
+	}
+	for _, bookID := range bookIDs {
+		lending, err := lendingQueries.GetCurrentLending(ctx, bookID)
+		if err != nil || lending.BorrowerID != userID {
+			t.Errorf("expected %s to be borrowed by %s, got %+v (%v)", bookID, userID, lending, err)
+		}
+	}
+}
+
+func TestBorrowBooks_AtomicWithFailure_BorrowsNothing(t *testing.T) {
+	bookIDs, bookQueries := newBatchTestBooks(2)
+	service, lendingQueries := newBatchTestService(t, bookQueries)
+	ctx := context.Background()
+	if _, err := service.borrow.BorrowBook(ctx, BorrowBookInput{BookID: bookIDs[1], BorrowerID: uuid.New().String()}); err != nil {
+		t.Fatalf("failed to borrow book: %v", err)
+	}
+
+	output, err := service.BorrowBooks(ctx, BatchBorrowInput{
+		BookIDs:    bookIDs,
+		BorrowerID: uuid.New().String(),
+		Atomic:     true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if output.Items[0].Status != domain.BatchItemRolledBack || output.Items[0].Borrowed == nil {
+		t.Errorf("expected the first item to be rolled back, got %+v", output.Items[0])
+	}
+	if output.Items[1].Status != domain.BatchItemFailed || !errors.Is(output.Items[1].Err, ErrBookAlreadyBorrowed) {
+		t.Errorf("expected the second item to fail as already borrowed, got %+v", output.Items[1])
+	}
+	if _, err := lendingQueries.GetCurrentLending(ctx, bookIDs[0]); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected %s not to be borrowed, got %v", bookIDs[0], err)
+	}
+}
+
+func TestBorrowBooks_AtomicWithoutFailure_BorrowsEveryBook(t *testing.T) {
+	bookIDs, bookQueries := newBatchTestBooks(3)
+	service, lendingQueries := newBatchTestService(t, bookQueries)
+	ctx := context.Background()
+
+	output, err := service.BorrowBooks(ctx, BatchBorrowInput{
+		BookIDs:    bookIDs,
+		BorrowerID: uuid.New().String(),
+		Atomic:     true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if output.Failed() {
+		t.Fatalf("expected every item to succeed, got %v", statuses(output))
+	}
+	for _, bookID := range bookIDs {
+		if _, err := lendingQueries.GetCurrentLending(ctx, bookID); err != nil {
+			t.Errorf("expected %s to be borrowed, got %v", bookID, err)
+		}
+	}
+}
+
+func TestBorrowBooks_WithSameBookTwice_ReportsDuplicate(t *testing.T) {
+	bookIDs, bookQueries := newBatchTestBooks(1)
+	bookQueries.accessionNumbers = map[string]string{"HC-000001": bookIDs[0]}
+	service, _ := newBatchTestService(t, bookQueries)
+
+	output, err := service.BorrowBooks(context.Background(), BatchBorrowInput{
+		BookIDs:    []string{bookIDs[0], "HC-000001"},
+		BorrowerID: uuid.New().String(),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if output.Items[0].Status != domain.BatchItemSucceeded || !errors.Is(output.Items[1].Err, ErrDuplicateBatchItem) {
+		t.Errorf("expected the second item to be a duplicate, got %v", statuses(output))
+	}
+}
+
+func TestBorrowBooks_WithInvalidInput_ReturnsError(t *testing.T) {
+	bookIDs, bookQueries := newBatchTestBooks(1)
+	service, _ := newBatchTestService(t, bookQueries)
+	ctx := context.Background()
+	zero := 0
+
+	if _, err := service.BorrowBooks(ctx, BatchBorrowInput{BorrowerID: uuid.New().String()}); !errors.Is(err, domain.ErrInvalidBatchSize) {
+		t.Errorf("expected ErrInvalidBatchSize, got %v", err)
+	}
+	if _, err := service.BorrowBooks(ctx, BatchBorrowInput{BookIDs: bookIDs, BorrowerID: uuid.New().String(), DueDays: &zero}); !errors.Is(err, domain.ErrInvalidDueDays) {
+		t.Errorf("expected ErrInvalidDueDays, got %v", err)
+	}
+}
+
+func TestReturnBooks_WithBookNotBorrowed_ReturnsTheOthers(t *testing.T) {
+	bookIDs, bookQueries := newBatchTestBooks(2)
+	service, lendingQueries := newBatchTestService(t, bookQueries)
+	ctx := context.Background()
+	userID := uuid.New().String()
+	if _, err := service.borrow.BorrowBook(ctx, BorrowBookInput{BookID: bookIDs[0], BorrowerID: userID}); err != nil {
+		t.Fatalf("failed to borrow book: %v", err)
+	}
+
+	output, err := service.ReturnBooks(ctx, BatchReturnInput{
+		BookIDs:     bookIDs,
+		RequesterID: userID,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if output.Items[0].Status != domain.BatchItemSucceeded || output.Items[0].Returned == nil {
+		t.Errorf("expected the first book to be returned, got %+v", output.Items[0])
+	}
+	if !errors.Is(output.Items[1].Err, ErrBookNotBorrowed) {
+		t.Errorf("expected ErrBookNotBorrowed, got %v", output.Items[1].Err)
+	}
+	if _, err := lendingQueries.GetCurrentLending(ctx, bookIDs[0]); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected %s to be returned, got %v", bookIDs[0], err)
+	}
+}
+
+func TestReturnBooks_AtomicWithOtherUsersBook_ReturnsNothing(t *testing.T) {
+	bookIDs, bookQueries := newBatchTestBooks(2)
+	service, lendingQueries := newBatchTestService(t, bookQueries)
+	ctx := context.Background()
+	userID := uuid.New().String()
+	if _, err := service.borrow.BorrowBook(ctx, BorrowBookInput{BookID: bookIDs[0], BorrowerID: userID}); err != nil {
+		t.Fatalf("failed to borrow book: %v", err)
+	}
+	if _, err := service.borrow.BorrowBook(ctx, BorrowBookInput{BookID: bookIDs[1], BorrowerID: uuid.New().String()}); err != nil {
+		t.Fatalf("failed to borrow book: %v", err)
+	}
+
+	output, err := service.ReturnBooks(ctx, BatchReturnInput{
+		BookIDs:     bookIDs,
+		RequesterID: userID,
+		Atomic:      true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if output.Items[0].Status != domain.BatchItemRolledBack || !errors.Is(output.Items[1].Err, ErrNotBorrower) {
+		t.Errorf("expected the batch to be rolled back, got %v", statuses(output))
+	}
+	if _, err := lendingQueries.GetCurrentLending(ctx, bookIDs[0]); err != nil {
+		t.Errorf("expected %s to stay borrowed, got %v", bookIDs[0], err)
+	}
+}
@@ -0,0 +1,191 @@
+package lending
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"holocron/internal/book"
+	bookDomain "holocron/internal/book/domain"
+
+	"github.com/google/uuid"
+)
+
+// BookEventQueries are the book queries that record what happened to a copy
+// while it was on loan.
+type BookEventQueries interface {
+	BookQueries
+	InsertBookConditionEvent(ctx context.Context, arg book.InsertBookConditionEventParams) error
+	InsertBookDeleteEvent(ctx context.Context, arg book.InsertBookDeleteEventParams) error
+}
+
+type ConditionReturnInput struct {
+	ReturnBookInput
+	// Condition is good, damaged or missing_pages. Without it the return
+	// records no condition.
+	Condition *string
+	Note      *string
+}
+
+type ReportLostInput struct {
+	// BookID is a book ID or the accession number printed on a book's label.
+	BookID      string
+	RequesterID string
+	// AsLibrarian lets the requester report a book lost by someone else.
+	AsLibrarian bool
+	Memo        *string
+}
+
+type ReportLostOutput struct {
+	LendingID  string
+	BookID     string
+	BorrowerID string
+	ReportedAt time.Time
+}
+
+// BookConditionService records the state of a copy at the end of a loan: the
+// condition it came back in, or that it never came back. The lending and book
+// events of each operation are written in one transaction.
+type BookConditionService struct {
+	db  *sql.DB
+	ret *ReturnBookService
+	// bookQueriesTx returns the book queries to use in a transaction.
+	bookQueriesTx func(tx *sql.Tx) BookEventQueries
+}
+
+func NewBookConditionService(db *sql.DB, ret *ReturnBookService, bookQueriesTx func(tx *sql.Tx) BookEventQueries) *BookConditionService {
+	return &BookConditionService{
+		db:            db,
+		ret:           ret,
+		bookQueriesTx: bookQueriesTx,
+	}
+}
+
+// ReturnBook returns a book like ReturnBookService and records the condition
+// it came back in as a condition_recorded book event of the loan.
+func (s *BookConditionService) ReturnBook(ctx context.Context, input ConditionReturnInput) (*ReturnBookOutput, error) {
+	if input.Condition == nil {
+		if input.Note != nil {
+			return nil, bookDomain.ErrInvalidCondition
+		}
+		return s.ret.ReturnBook(ctx, input.ReturnBookInput)
+	}
+	condition, err := bookDomain.ParseCondition(*input.Condition)
+	if err != nil {
+		return nil, err
+	}
+	var note sql.NullString
+	if input.Note != nil {
+		parsed, err := bookDomain.ParseConditionNote(*input.Note)
+		if err != nil {
+			return nil, err
+		}
+		note = sql.NullString{String: parsed, Valid: parsed != ""}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	bookQueries := s.bookQueriesTx(tx)
+
+	output, err := s.ret.withTx(tx, bookQueries).ReturnBook(ctx, input.ReturnBookInput)
+	if err != nil {
+		return nil, err
+	}
+	err = bookQueries.InsertBookConditionEvent(ctx, book.InsertBookConditionEventParams{
+		EventID:       uuid.New().String(),
+		BookID:        output.BookID,
+		Condition:     sql.NullString{String: string(condition), Valid: true},
+		ConditionNote: note,
+		LendingID:     sql.NullString{String: output.LendingID, Valid: true},
+		OccurredAt:    output.ReturnedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// ReportLost closes the loan of a book that will not come back with a lost
+// event and deletes the book with the lost reason. Only the borrower or a
+// librarian can report it.
+func (s *BookConditionService) ReportLost(ctx context.Context, input ReportLostInput) (*ReportLostOutput, error) {
+	now := s.ret.now()
+
+	memo, err := parseOptionalReason(input.Memo)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	bookQueries := s.bookQueriesTx(tx)
+	lendingQueries := s.ret.lendingQueries.WithTx(tx)
+
+	bookID, err := resolveBookID(ctx, bookQueries, input.BookID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := bookQueries.CountBookByBookId(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrBookNotFound
+	}
+
+	currentLendingRow, err := lendingQueries.GetCurrentLending(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookNotBorrowed
+		}
+		return nil, err
+	}
+	if currentLendingRow.BorrowerID != input.RequesterID && !input.AsLibrarian {
+		return nil, ErrNotBorrower
+	}
+
+	err = lendingQueries.InsertLendingEvent(ctx, InsertLendingEventParams{
+		EventID:    uuid.New().String(),
+		LendingID:  currentLendingRow.LendingID,
+		BookID:     currentLendingRow.BookID,
+		BorrowerID: currentLendingRow.BorrowerID,
+		EventType:  "lost",
+		DueDate:    sql.NullString{Valid: false},
+		ActorID:    sql.NullString{String: input.RequesterID, Valid: true},
+		Reason:     memo,
+		OccurredAt: now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = bookQueries.InsertBookDeleteEvent(ctx, book.InsertBookDeleteEventParams{
+		EventID:      uuid.New().String(),
+		BookID:       currentLendingRow.BookID,
+		DeleteReason: sql.NullString{String: string(bookDomain.DeleteReasonLost), Valid: true},
+		DeleteMemo:   memo,
+		OccurredAt:   now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &ReportLostOutput{
+		LendingID:  currentLendingRow.LendingID,
+		BookID:     currentLendingRow.BookID,
+		BorrowerID: currentLendingRow.BorrowerID,
+		ReportedAt: now,
+	}, nil
+}
@@ -0,0 +1,182 @@
+//go:build medium
+
+package lending
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	bookDomain "holocron/internal/book/domain"
+	"holocron/internal/calendar"
+
+	"github.com/google/uuid"
+)
+
+type conditionFixture struct {
+	lendingQueries *Queries
+	bookQueries    *fakeBookQueries
+	bookID         string
+	borrowerID     string
+	service        *BookConditionService
+}
+
+func newConditionFixture(t *testing.T) *conditionFixture {
+	t.Helper()
+	db := setupTestDB(t)
+	f := &conditionFixture{
+		lendingQueries: New(db),
+		bookID:         uuid.New().String(),
+		borrowerID:     uuid.New().String(),
+	}
+	f.bookQueries = &fakeBookQueries{countByBookId: map[string]int64{f.bookID: 1}}
+	f.service = NewBookConditionService(
+		db,
+		NewReturnBookService(f.lendingQueries, f.bookQueries, calendar.Calendar{}),
+		func(*sql.Tx) BookEventQueries { return f.bookQueries },
+	)
+	_, err := NewBorrowBookService(f.lendingQueries, f.bookQueries, calendar.Calendar{}).BorrowBook(context.Background(), BorrowBookInput{
+		BookID:     f.bookID,
+		BorrowerID: f.borrowerID,
+	})
+	if err != nil {
+		t.Fatalf("precondition failed: %v", err)
+	}
+	return f
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestReturnBookWithCondition_WithCondition_RecordsConditionEvent(t *testing.T) {
+	f := newConditionFixture(t)
+
+	output, err := f.service.ReturnBook(context.Background(), ConditionReturnInput{
+		ReturnBookInput: ReturnBookInput{BookID: f.bookID, RequesterID: f.borrowerID},
+		Condition:       ptr("damaged"),
+		Note:            ptr(" 表紙に水濡れ "),
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f.lendingQueries.GetCurrentLending(context.Background(), f.bookID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("postcondition failed: expected the loan to be closed, got %v", err)
+	}
+	if len(f.bookQueries.conditionEvents) != 1 {
+		t.Fatalf("expected 1 condition event, got %+v", f.bookQueries.conditionEvents)
+	}
+	event := f.bookQueries.conditionEvents[0]
+	if event.BookID != f.bookID || event.LendingID.String != output.LendingID ||
+		event.Condition.String != "damaged" || event.ConditionNote.String != "表紙に水濡れ" {
+		t.Errorf("unexpected condition event %+v", event)
+	}
+	if event.OccurredAt != output.ReturnedAt.Format(time.RFC3339) {
+		t.Errorf("expected the condition to be recorded at the return, got %s", event.OccurredAt)
+	}
+}
+
+func TestReturnBookWithCondition_WithoutCondition_RecordsNothing(t *testing.T) {
+	f := newConditionFixture(t)
+
+	_, err := f.service.ReturnBook(context.Background(), ConditionReturnInput{
+		ReturnBookInput: ReturnBookInput{BookID: f.bookID, RequesterID: f.borrowerID},
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.bookQueries.conditionEvents) != 0 {
+		t.Errorf("expected no condition event, got %+v", f.bookQueries.conditionEvents)
+	}
+}
+
+func TestReturnBookWithCondition_WithInvalidCondition_KeepsLoanOpen(t *testing.T) {
+	for _, input := range []ConditionReturnInput{
+		{Condition: ptr("broken")},
+		{Note: ptr("condition なしのメモ")},
+	} {
+		f := newConditionFixture(t)
+		input.ReturnBookInput = ReturnBookInput{BookID: f.bookID, RequesterID: f.borrowerID}
+
+		_, err := f.service.ReturnBook(context.Background(), input)
+
+		if !errors.Is(err, bookDomain.ErrInvalidCondition) {
+			t.Errorf("expected ErrInvalidCondition, got %v", err)
+		}
+		if _, err := f.lendingQueries.GetCurrentLending(context.Background(), f.bookID); err != nil {
+			t.Errorf("postcondition failed: expected the loan to stay open, got %v", err)
+		}
+	}
+}
+
+func TestReportLost_ByBorrower_ClosesLoanAndDeletesBook(t *testing.T) {
+	f := newConditionFixture(t)
+
+	output, err := f.service.ReportLost(context.Background(), ReportLostInput{
+		BookID:      f.bookID,
+		RequesterID: f.borrowerID,
+		Memo:        ptr("出張先で紛失"),
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.BookID != f.bookID || output.BorrowerID != f.borrowerID {
+		t.Errorf("unexpected output %+v", output)
+	}
+	if _, err := f.lendingQueries.GetCurrentLending(context.Background(), f.bookID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("postcondition failed: expected the loan to be closed, got %v", err)
+	}
+	if len(f.bookQueries.deleteEvents) != 1 {
+		t.Fatalf("expected 1 delete event, got %+v", f.bookQueries.deleteEvents)
+	}
+	deleted := f.bookQueries.deleteEvents[0]
+	if deleted.DeleteReason.String != "lost" || deleted.DeleteMemo.String != "出張先で紛失" {
+		t.Errorf("unexpected delete event %+v", deleted)
+	}
+}
+
+func TestReportLost_ByOtherUser_ReturnsError(t *testing.T) {
+	f := newConditionFixture(t)
+
+	_, err := f.service.ReportLost(context.Background(), ReportLostInput{BookID: f.bookID, RequesterID: uuid.New().String()})
+
+	if !errors.Is(err, ErrNotBorrower) {
+		t.Errorf("expected ErrNotBorrower, got %v", err)
+	}
+	if len(f.bookQueries.deleteEvents) != 0 {
+		t.Errorf("expected the book to be kept, got %+v", f.bookQueries.deleteEvents)
+	}
+}
+
+func TestReportLost_ByLibrarian_ClosesLoan(t *testing.T) {
+	f := newConditionFixture(t)
+
+	_, err := f.service.ReportLost(context.Background(), ReportLostInput{BookID: f.bookID, RequesterID: uuid.New().String(), AsLibrarian: true})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := f.lendingQueries.GetCurrentLending(context.Background(), f.bookID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("postcondition failed: expected the loan to be closed, got %v", err)
+	}
+}
+
+func TestReportLost_WithReturnedBook_ReturnsError(t *testing.T) {
+	f := newConditionFixture(t)
+	if _, err := f.service.ReturnBook(context.Background(), ConditionReturnInput{
+		ReturnBookInput: ReturnBookInput{BookID: f.bookID, RequesterID: f.borrowerID},
+	}); err != nil {
+		t.Fatalf("precondition failed: %v", err)
+	}
+
+	_, err := f.service.ReportLost(context.Background(), ReportLostInput{BookID: f.bookID, RequesterID: f.borrowerID})
+
+	if !errors.Is(err, ErrBookNotBorrowed) {
+		t.Errorf("expected ErrBookNotBorrowed, got %v", err)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"errors"
 	"time"
 
+	"holocron/internal/book"
+	bookDomain "holocron/internal/book/domain"
+	"holocron/internal/calendar"
 	"holocron/internal/lending/domain"
 
 	"github.com/google/uuid"
@@ -17,6 +20,7 @@ var (
 )
 
 type BorrowBookInput struct {
+	// BookID is a book ID or the accession number printed on a book's label.
 	BookID     string
 	BorrowerID string
 	DueDays    *int
@@ -32,26 +36,58 @@ type BorrowBookOutput struct {
 
 type BookQueries interface {
 	CountBookByBookId(ctx context.Context, bookID string) (int64, error)
+	GetBookIdByAccessionNumber(ctx context.Context, accessionNumber sql.NullString) (string, error)
+	ListBooksByCode(ctx context.Context, arg book.ListBooksByCodeParams) ([]book.ListBooksByCodeRow, error)
+}
+
+// resolveBookID returns the ID of the book a borrow or return refers to, which
+// may be given by the accession number scanned from its label.
+func resolveBookID(ctx context.Context, queries BookQueries, ref string) (string, error) {
+	number, err := bookDomain.ParseAccessionNumber(ref)
+	if err != nil {
+		return ref, nil
+	}
+	bookID, err := queries.GetBookIdByAccessionNumber(ctx, sql.NullString{String: string(number), Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrBookNotFound
+	}
+	return bookID, err
 }
 
 type BorrowBookService struct {
 	lendingQueries *Queries
 	bookQueries    BookQueries
+	calendar       calendar.Calendar
 	now            func() time.Time
 }
 
-func NewBorrowBookService(lendingQueries *Queries, bookQueries BookQueries) *BorrowBookService {
+func NewBorrowBookService(lendingQueries *Queries, bookQueries BookQueries, cal calendar.Calendar) *BorrowBookService {
 	return &BorrowBookService{
 		lendingQueries: lendingQueries,
 		bookQueries:    bookQueries,
+		calendar:       cal,
 		now:            func() time.Time { return time.Now().UTC() },
 	}
 }
 
+// withTx returns a copy of the service that runs its queries in tx.
+func (s *BorrowBookService) withTx(tx *sql.Tx, bookQueries BookQueries) *BorrowBookService {
+	return &BorrowBookService{
+		lendingQueries: s.lendingQueries.WithTx(tx),
+		bookQueries:    bookQueries,
+		calendar:       s.calendar,
+		now:            s.now,
+	}
+}
+
 func (s *BorrowBookService) BorrowBook(ctx context.Context, input BorrowBookInput) (*BorrowBookOutput, error) {
 	now := s.now()
 
-	count, err := s.bookQueries.CountBookByBookId(ctx, input.BookID)
+	bookID, err := resolveBookID(ctx, s.bookQueries, input.BookID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.bookQueries.CountBookByBookId(ctx, bookID)
 	if err != nil {
 		return nil, err
 	}
@@ -59,7 +95,7 @@ func (s *BorrowBookService) BorrowBook(ctx context.Context, input BorrowBookInpu
 		return nil, ErrBookNotFound
 	}
 
-	currentLendingRow, err := s.lendingQueries.GetCurrentLending(ctx, input.BookID)
+	currentLendingRow, err := s.lendingQueries.GetCurrentLending(ctx, bookID)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, err
 	}
@@ -85,13 +121,13 @@ func (s *BorrowBookService) BorrowBook(ctx context.Context, input BorrowBookInpu
 			borrowedDueDatePtr = &currentLendingRow.DueDate.String
 		}
 
-		currentLending, err = domain.ParseCurrentLending(latestDueDatePtr, borrowedDueDatePtr, currentLendingRow.BorrowedAt)
+		currentLending, err = domain.ParseCurrentLending(latestDueDatePtr, borrowedDueDatePtr, currentLendingRow.BorrowedAt, s.calendar)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	dueDate, _, err := domain.CalculateDueDate(now, input.DueDays, currentLending)
+	dueDate, _, err := domain.CalculateDueDate(now, input.DueDays, currentLending, s.calendar)
 	if err != nil {
 		return nil, err
 	}
@@ -103,10 +139,11 @@ func (s *BorrowBookService) BorrowBook(ctx context.Context, input BorrowBookInpu
 		err := s.lendingQueries.InsertLendingEvent(ctx, InsertLendingEventParams{
 			EventID:    eventID,
 			LendingID:  lendingID,
-			BookID:     input.BookID,
+			BookID:     bookID,
 			BorrowerID: input.BorrowerID,
 			EventType:  "borrowed",
 			DueDate:    sql.NullString{String: dueDate.Format(time.RFC3339), Valid: true},
+			ActorID:    sql.NullString{String: input.BorrowerID, Valid: true},
 			OccurredAt: now.Format(time.RFC3339),
 		})
 		if err != nil {
@@ -115,7 +152,7 @@ func (s *BorrowBookService) BorrowBook(ctx context.Context, input BorrowBookInpu
 
 		return &BorrowBookOutput{
 			ID:         lendingID,
-			BookID:     input.BookID,
+			BookID:     bookID,
 			BorrowerID: input.BorrowerID,
 			BorrowedAt: now,
 			DueDate:    dueDate,
@@ -130,6 +167,7 @@ func (s *BorrowBookService) BorrowBook(ctx context.Context, input BorrowBookInpu
 		BorrowerID: currentLendingRow.BorrowerID,
 		EventType:  "due_date_extended",
 		DueDate:    sql.NullString{String: dueDate.Format(time.RFC3339), Valid: true},
+		ActorID:    sql.NullString{String: input.BorrowerID, Valid: true},
 		OccurredAt: now.Format(time.RFC3339),
 	})
 	if err != nil {
@@ -10,6 +10,9 @@ import (
 	"testing"
 	"time"
 
+	"holocron/internal/book"
+	"holocron/internal/calendar"
+
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -28,11 +31,151 @@ func setupTestDB(t *testing.T) *sql.DB {
 			borrower_id TEXT NOT NULL,
 			event_type TEXT NOT NULL,
 			due_date TEXT,
+			actor_id TEXT,
+			reason TEXT,
 			occurred_at TEXT NOT NULL
 		);
 		CREATE INDEX idx_lending_events_lending_id ON lending_events(lending_id);
 		CREATE INDEX idx_lending_events_book_id ON lending_events(book_id);
 		CREATE INDEX idx_lending_events_borrower_id ON lending_events(borrower_id);
+
+		-- open_lendings has the loans that have not been returned, transferred or
+		-- reported lost. due_date is the one set when the loan was opened.
+		CREATE VIEW open_lendings AS
+		SELECT
+			le.lending_id,
+			le.book_id,
+			le.borrower_id,
+			le.due_date,
+			le.occurred_at as borrowed_at
+		FROM lending_events le
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND NOT EXISTS (
+				SELECT 1
+				FROM lending_events closed
+				WHERE closed.lending_id = le.lending_id
+					AND closed.event_type IN ('returned', 'force_returned', 'transferred', 'lost')
+			);
+
+		CREATE VIEW deleted_books AS
+		SELECT book_id, MAX(occurred_at) as deleted_at
+		FROM book_events
+		WHERE event_type = 'deleted'
+		GROUP BY book_id;
+
+		-- latest_books has the latest details of each book since it was last deleted.
+		-- Events in the same second are ordered as they were recorded.
+		CREATE VIEW latest_books AS
+		SELECT book_id, code, title, authors, publisher, published_date, thumbnail_url, created_at, updated_at
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.code,
+				e1.title,
+				e1.authors,
+				e1.publisher,
+				e1.published_date,
+				e1.thumbnail_url,
+				(SELECT MIN(e_created.occurred_at)
+				 FROM book_events e_created
+				 WHERE e_created.book_id = e1.book_id
+				   AND e_created.event_type = 'created'
+				   AND e_created.occurred_at > COALESCE(d.deleted_at, '1970-01-01T00:00:00Z')
+				) as created_at,
+				e1.occurred_at as updated_at,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('created', 'updated')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- book_classifications has the latest tags_changed, category_changed and
+		-- shelf_changed event of each book, one row per event type.
+		CREATE VIEW book_classifications AS
+		SELECT book_id, event_type, tags, category_id, shelf_location
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.event_type,
+				e1.tags,
+				e1.category_id,
+				e1.shelf_location,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id, e1.event_type ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('tags_changed', 'category_changed', 'shelf_changed')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- current_lendings has the open loan of each book, with its latest due date.
+		CREATE VIEW current_lendings AS
+		SELECT lending_id, book_id, borrower_id, borrower_name, borrowed_at, due_date
+		FROM (
+			SELECT
+				ol.lending_id,
+				ol.book_id,
+				ol.borrower_id,
+				ue.name as borrower_name,
+				ol.borrowed_at,
+				(SELECT due.due_date
+				 FROM lending_events due
+				 WHERE due.lending_id = ol.lending_id
+				   AND due.event_type IN ('borrowed', 'lent', 'due_date_extended')
+				   AND due.due_date IS NOT NULL
+				 ORDER BY due.occurred_at DESC
+				 LIMIT 1
+				) as due_date,
+				ROW_NUMBER() OVER (PARTITION BY ol.book_id ORDER BY ol.borrowed_at DESC) as rn
+			FROM open_lendings ol
+			INNER JOIN user_events ue ON ue.user_id = ol.borrower_id AND ue.event_type = 'created'
+			LEFT JOIN deleted_books d ON ol.book_id = d.book_id
+			WHERE d.deleted_at IS NULL OR ol.borrowed_at > d.deleted_at
+		)
+		WHERE rn = 1;
+
+		-- borrow_counts counts the loans of each book since it was last deleted.
+		CREATE VIEW borrow_counts AS
+		SELECT le.book_id, COUNT(*) as borrow_count
+		FROM lending_events le
+		LEFT JOIN deleted_books d ON le.book_id = d.book_id
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND (d.deleted_at IS NULL OR le.occurred_at > d.deleted_at)
+		GROUP BY le.book_id;
+
+		-- book_list_items joins the current state of each book for the book list, the
+		-- search and the facets, which filter it with the same conditions.
+		CREATE VIEW book_list_items AS
+		SELECT
+			lb.book_id,
+			lb.code,
+			lb.title,
+			lb.authors,
+			lb.publisher,
+			lb.published_date,
+			lb.thumbnail_url,
+			lb.created_at,
+			lb.updated_at,
+			cl.lending_id,
+			cl.borrower_id,
+			cl.borrower_name,
+			cl.borrowed_at,
+			cl.due_date,
+			bt.tags,
+			bcat.category_id,
+			ce.name as category_name,
+			ce.code as category_code,
+			bs.shelf_location,
+			COALESCE(bc.borrow_count, 0) as borrow_count
+		FROM latest_books lb
+		LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id
+		LEFT JOIN book_classifications bt ON lb.book_id = bt.book_id AND bt.event_type = 'tags_changed'
+		LEFT JOIN book_classifications bcat ON lb.book_id = bcat.book_id AND bcat.event_type = 'category_changed'
+		LEFT JOIN book_classifications bs ON lb.book_id = bs.book_id AND bs.event_type = 'shelf_changed'
+		LEFT JOIN category_events ce ON bcat.category_id = ce.category_id AND ce.event_type = 'created'
+		LEFT JOIN borrow_counts bc ON lb.book_id = bc.book_id;
 	`)
 	if err != nil {
 		t.Fatalf("failed to create table: %v", err)
@@ -42,7 +185,11 @@ func setupTestDB(t *testing.T) *sql.DB {
 }
 
 type fakeBookQueries struct {
-	countByBookId map[string]int64
+	countByBookId    map[string]int64
+	accessionNumbers map[string]string
+	codes            map[string][]book.ListBooksByCodeRow
+	conditionEvents  []book.InsertBookConditionEventParams
+	deleteEvents     []book.InsertBookDeleteEventParams
 }
 
 func (f *fakeBookQueries) CountBookByBookId(_ context.Context, bookID string) (int64, error) {
@@ -53,6 +200,32 @@ func (f *fakeBookQueries) CountBookByBookId(_ context.Context, bookID string) (i
 	return count, nil
 }
 
+func (f *fakeBookQueries) GetBookIdByAccessionNumber(_ context.Context, accessionNumber sql.NullString) (string, error) {
+	bookID, ok := f.accessionNumbers[accessionNumber.String]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return bookID, nil
+}
+
+func (f *fakeBookQueries) ListBooksByCode(_ context.Context, arg book.ListBooksByCodeParams) ([]book.ListBooksByCodeRow, error) {
+	if rows, ok := f.codes[arg.Code.String]; ok {
+		return rows, nil
+	}
+	return f.codes[arg.NormalizedCode.String], nil
+}
+
+func (f *fakeBookQueries) InsertBookConditionEvent(_ context.Context, arg book.InsertBookConditionEventParams) error {
+	f.conditionEvents = append(f.conditionEvents, arg)
+	return nil
+}
+
+func (f *fakeBookQueries) InsertBookDeleteEvent(_ context.Context, arg book.InsertBookDeleteEventParams) error {
+	f.deleteEvents = append(f.deleteEvents, arg)
+	f.countByBookId[arg.BookID] = 0
+	return nil
+}
+
 // When BorrowBook with new book then returns output
 func TestBorrowBook_WithNewBook_ReturnsOutput(t *testing.T) {
 	db := setupTestDB(t)
@@ -70,7 +243,7 @@ func TestBorrowBook_WithNewBook_ReturnsOutput(t *testing.T) {
 	}
 
 	borrowTime := time.Now().Add(-time.Duration(rand.Intn(720)+1) * time.Hour)
-	service := NewBorrowBookService(lendingQueries, bookQueries)
+	service := NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{})
 	service.now = func() time.Time { return borrowTime }
 
 	input := BorrowBookInput{
@@ -125,7 +298,7 @@ func TestBorrowBook_WithCustomDueDays_ReturnsOutputWithCustomDueDate(t *testing.
 	ctx := context.Background()
 
 	borrowTime := time.Now().Add(-time.Duration(rand.Intn(720)+1) * time.Hour)
-	service := NewBorrowBookService(lendingQueries, bookQueries)
+	service := NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{})
 	service.now = func() time.Time { return borrowTime }
 
 	dueDays := rand.Intn(30) + 1
@@ -165,7 +338,7 @@ func TestBorrowBook_WithNonExistentBook_ReturnsError(t *testing.T) {
 		t.Fatalf("precondition failed: expected book count 0, got %d", count)
 	}
 
-	service := NewBorrowBookService(lendingQueries, bookQueries)
+	service := NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{})
 
 	input := BorrowBookInput{
 		BookID:     nonExistentBookID,
@@ -196,7 +369,7 @@ func TestBorrowBook_WithAlreadyBorrowedBookByDifferentUser_ReturnsError(t *testi
 	ctx := context.Background()
 
 	borrowTime := time.Now().Add(-time.Duration(rand.Intn(720)+1) * time.Hour)
-	service := NewBorrowBookService(lendingQueries, bookQueries)
+	service := NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{})
 	service.now = func() time.Time { return borrowTime }
 
 	firstBorrow, err := service.BorrowBook(ctx, BorrowBookInput{
@@ -250,7 +423,7 @@ func TestBorrowBook_WithAlreadyBorrowedBookBySameUser_ExtendsDueDate(t *testing.
 	ctx := context.Background()
 
 	borrowTime := time.Now().Add(-time.Duration(rand.Intn(720)+1) * time.Hour)
-	service := NewBorrowBookService(lendingQueries, bookQueries)
+	service := NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{})
 	service.now = func() time.Time { return borrowTime }
 
 	output1, err := service.BorrowBook(ctx, BorrowBookInput{
@@ -292,7 +465,7 @@ func TestBorrowBook_WithInvalidDueDays_ReturnsError(t *testing.T) {
 	}
 	ctx := context.Background()
 
-	service := NewBorrowBookService(lendingQueries, bookQueries)
+	service := NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{})
 
 	dueDays := 0
 	input := BorrowBookInput{
@@ -307,3 +480,51 @@ func TestBorrowBook_WithInvalidDueDays_ReturnsError(t *testing.T) {
 		t.Error("expected error, got nil")
 	}
 }
+
+// When BorrowBook with an accession number then borrows the numbered book
+func TestBorrowBook_WithAccessionNumber_BorrowsNumberedBook(t *testing.T) {
+	db := setupTestDB(t)
+	lendingQueries := New(db)
+	bookID := uuid.New().String()
+	userID := uuid.New().String()
+	bookQueries := &fakeBookQueries{
+		countByBookId:    map[string]int64{bookID: 1},
+		accessionNumbers: map[string]string{"HC-000042": bookID},
+	}
+	ctx := context.Background()
+	service := NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{})
+
+	output, err := service.BorrowBook(ctx, BorrowBookInput{
+		BookID:     "hc-000042",
+		BorrowerID: userID,
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.BookID != bookID {
+		t.Errorf("expected BookID %s, got %s", bookID, output.BookID)
+	}
+	currentLending, err := lendingQueries.GetCurrentLending(ctx, bookID)
+	if err != nil {
+		t.Fatalf("postcondition failed: expected current lending, got error: %v", err)
+	}
+	if currentLending.BorrowerID != userID {
+		t.Errorf("postcondition failed: expected borrower ID %s, got %s", userID, currentLending.BorrowerID)
+	}
+}
+
+// When BorrowBook with an unassigned accession number then returns ErrBookNotFound
+func TestBorrowBook_WithUnknownAccessionNumber_ReturnsError(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewBorrowBookService(New(db), &fakeBookQueries{}, calendar.Calendar{})
+
+	_, err := service.BorrowBook(context.Background(), BorrowBookInput{
+		BookID:     "HC-000042",
+		BorrowerID: uuid.New().String(),
+	})
+
+	if !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+}
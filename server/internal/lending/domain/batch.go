@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaxBatchItems bounds a batch to what fits on a kiosk's counter, so a batch
+// stays a short transaction.
+const MaxBatchItems = 50
+
+var ErrInvalidBatchSize = errors.New("batch must have 1 to 50 items")
+
+// BatchItemStatus is the outcome of one item in a batch.
+type BatchItemStatus string
+
+const (
+	BatchItemSucceeded BatchItemStatus = "succeeded"
+	BatchItemFailed    BatchItemStatus = "failed"
+	// BatchItemRolledBack is an item that would have succeeded but was undone
+	// because another item of an all-or-nothing batch failed.
+	BatchItemRolledBack BatchItemStatus = "rolled_back"
+)
+
+// ParseBatchRefs trims the book references of a batch. Blank references are
+// kept so that each item's result lines up with the request.
+func ParseBatchRefs(refs []string) ([]string, error) {
+	if len(refs) == 0 || len(refs) > MaxBatchItems {
+		return nil, ErrInvalidBatchSize
+	}
+	trimmed := make([]string, len(refs))
+	for i, ref := range refs {
+		trimmed[i] = strings.TrimSpace(ref)
+	}
+	return trimmed, nil
+}
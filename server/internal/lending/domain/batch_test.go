@@ -0,0 +1,46 @@
+//go:build small
+
+package domain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestParseBatchRefs_WithValidSize_KeepsEveryItem(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("returns one trimmed reference per item", prop.ForAll(
+		func(refs []string) bool {
+			padded := make([]string, len(refs))
+			for i, ref := range refs {
+				padded[i] = " " + ref + "\t"
+			}
+			parsed, err := ParseBatchRefs(padded)
+			if err != nil || len(parsed) != len(refs) {
+				return false
+			}
+			for i := range refs {
+				if parsed[i] != refs[i] {
+					return false
+				}
+			}
+			return true
+		},
+		gen.IntRange(1, MaxBatchItems).FlatMap(func(n any) gopter.Gen {
+			return gen.SliceOfN(n.(int), gen.AlphaString())
+		}, reflect.TypeOf([]string{})),
+	))
+	properties.TestingRun(t)
+}
+
+func TestParseBatchRefs_WithInvalidSize_ReturnsError(t *testing.T) {
+	for _, size := range []int{0, MaxBatchItems + 1} {
+		if _, err := ParseBatchRefs(make([]string, size)); err != ErrInvalidBatchSize {
+			t.Errorf("expected ErrInvalidBatchSize for %d items, got %v", size, err)
+		}
+	}
+}
@@ -2,13 +2,15 @@ package domain
 
 import (
 	"time"
+
+	"holocron/internal/calendar"
 )
 
 type CurrentLending struct {
 	DueDate time.Time
 }
 
-func ParseCurrentLending(latestDueDate, borrowedDueDate *string, borrowedAt string) (*CurrentLending, error) {
+func ParseCurrentLending(latestDueDate, borrowedDueDate *string, borrowedAt string, cal calendar.Calendar) (*CurrentLending, error) {
 	var dueDate time.Time
 	var err error
 
@@ -27,7 +29,7 @@ func ParseCurrentLending(latestDueDate, borrowedDueDate *string, borrowedAt stri
 		if err != nil {
 			return nil, err
 		}
-		dueDate = borrowedAtTime.AddDate(0, 0, DefaultDueDays)
+		dueDate = LegacyDueDate(borrowedAtTime, cal)
 	}
 
 	return &CurrentLending{DueDate: dueDate}, nil
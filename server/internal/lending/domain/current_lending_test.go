@@ -5,6 +5,8 @@ package domain
 import (
 	"testing"
 	"time"
+
+	"holocron/internal/calendar"
 )
 
 // When ParseCurrentLending with latestDueDate then returns CurrentLending with that due date
@@ -13,7 +15,7 @@ func TestParseCurrentLending_WithLatestDueDate_ReturnsCurrentLendingWithThatDueD
 	borrowedDueDate := "2024-01-08T00:00:00Z"
 	borrowedAt := "2024-01-01T00:00:00Z"
 
-	currentLending, err := ParseCurrentLending(&latestDueDate, &borrowedDueDate, borrowedAt)
+	currentLending, err := ParseCurrentLending(&latestDueDate, &borrowedDueDate, borrowedAt, calendar.Calendar{})
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -29,7 +31,7 @@ func TestParseCurrentLending_WithNilLatestDueDate_ReturnsCurrentLendingWithBorro
 	borrowedDueDate := "2024-01-08T00:00:00Z"
 	borrowedAt := "2024-01-01T00:00:00Z"
 
-	currentLending, err := ParseCurrentLending(nil, &borrowedDueDate, borrowedAt)
+	currentLending, err := ParseCurrentLending(nil, &borrowedDueDate, borrowedAt, calendar.Calendar{})
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -44,7 +46,7 @@ func TestParseCurrentLending_WithNilLatestDueDate_ReturnsCurrentLendingWithBorro
 func TestParseCurrentLending_WithNilLatestDueDateAndNilBorrowedDueDate_CalculatesFromBorrowedAt(t *testing.T) {
 	borrowedAt := "2024-01-01T00:00:00Z"
 
-	currentLending, err := ParseCurrentLending(nil, nil, borrowedAt)
+	currentLending, err := ParseCurrentLending(nil, nil, borrowedAt, calendar.Calendar{})
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -61,7 +63,7 @@ func TestParseCurrentLending_WithInvalidLatestDueDate_ReturnsError(t *testing.T)
 	borrowedDueDate := "2024-01-08T00:00:00Z"
 	borrowedAt := "2024-01-01T00:00:00Z"
 
-	_, err := ParseCurrentLending(&latestDueDate, &borrowedDueDate, borrowedAt)
+	_, err := ParseCurrentLending(&latestDueDate, &borrowedDueDate, borrowedAt, calendar.Calendar{})
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -73,7 +75,7 @@ func TestParseCurrentLending_WithInvalidBorrowedDueDate_ReturnsError(t *testing.
 	borrowedDueDate := "invalid-date"
 	borrowedAt := "2024-01-01T00:00:00Z"
 
-	_, err := ParseCurrentLending(nil, &borrowedDueDate, borrowedAt)
+	_, err := ParseCurrentLending(nil, &borrowedDueDate, borrowedAt, calendar.Calendar{})
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -84,7 +86,7 @@ func TestParseCurrentLending_WithInvalidBorrowedDueDate_ReturnsError(t *testing.
 func TestParseCurrentLending_WithInvalidBorrowedAt_ReturnsError(t *testing.T) {
 	borrowedAt := "invalid-date"
 
-	_, err := ParseCurrentLending(nil, nil, borrowedAt)
+	_, err := ParseCurrentLending(nil, nil, borrowedAt, calendar.Calendar{})
 
 	if err == nil {
 		t.Error("expected error, got nil")
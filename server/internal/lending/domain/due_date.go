@@ -3,13 +3,18 @@ package domain
 import (
 	"errors"
 	"time"
+
+	"holocron/internal/calendar"
 )
 
 const DefaultDueDays = 7
 
 var ErrInvalidDueDays = errors.New("due days must be at least 1")
 
-func CalculateDueDate(now time.Time, requestedDueDays *int, currentLending *CurrentLending) (dueDate time.Time, dueDays int, err error) {
+// CalculateDueDate adds the due days to now, or to the current due date when
+// the loan is extended. A due date falling on a day the library is closed is
+// pushed to the next open day.
+func CalculateDueDate(now time.Time, requestedDueDays *int, currentLending *CurrentLending, cal calendar.Calendar) (dueDate time.Time, dueDays int, err error) {
 	dueDays = DefaultDueDays
 	if requestedDueDays != nil {
 		if *requestedDueDays < 1 {
@@ -23,6 +28,13 @@ func CalculateDueDate(now time.Time, requestedDueDays *int, currentLending *Curr
 		baseDate = currentLending.DueDate
 	}
 
-	dueDate = baseDate.AddDate(0, 0, dueDays)
+	dueDate = cal.NextOpenDay(baseDate.AddDate(0, 0, dueDays))
 	return dueDate, dueDays, nil
 }
+
+// LegacyDueDate returns the due date of a loan recorded before due dates were
+// stored with it: the default due days after borrowing, pushed to the next
+// open day like the due date of a new loan.
+func LegacyDueDate(borrowedAt time.Time, cal calendar.Calendar) time.Time {
+	return cal.NextOpenDay(borrowedAt.AddDate(0, 0, DefaultDueDays))
+}
@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"holocron/internal/calendar"
+
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
@@ -16,7 +18,7 @@ import (
 func TestCalculateDueDate_WithNilCurrentLending_ReturnsDueDateFromNow(t *testing.T) {
 	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	dueDate, dueDays, err := CalculateDueDate(now, nil, nil)
+	dueDate, dueDays, err := CalculateDueDate(now, nil, nil, calendar.Calendar{})
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -36,7 +38,7 @@ func TestCalculateDueDate_WithCurrentLending_ReturnsDueDateFromCurrentDueDate(t
 	currentDueDate := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
 	currentLending := &CurrentLending{DueDate: currentDueDate}
 
-	dueDate, dueDays, err := CalculateDueDate(now, nil, currentLending)
+	dueDate, dueDays, err := CalculateDueDate(now, nil, currentLending, calendar.Calendar{})
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -57,7 +59,7 @@ func TestCalculateDueDate_WithCustomDueDays_ReturnsCorrectDueDate(t *testing.T)
 		func(now time.Time, dueDaysValue int) bool {
 			expectedDueDate := now.AddDate(0, 0, dueDaysValue)
 
-			dueDate, dueDays, err := CalculateDueDate(now, &dueDaysValue, nil)
+			dueDate, dueDays, err := CalculateDueDate(now, &dueDaysValue, nil, calendar.Calendar{})
 
 			return err == nil &&
 				dueDays == dueDaysValue &&
@@ -74,7 +76,7 @@ func TestCalculateDueDate_WithZeroDueDays_ReturnsError(t *testing.T) {
 	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	dueDays := 0
 
-	_, _, err := CalculateDueDate(now, &dueDays, nil)
+	_, _, err := CalculateDueDate(now, &dueDays, nil, calendar.Calendar{})
 
 	if !errors.Is(err, ErrInvalidDueDays) {
 		t.Errorf("expected ErrInvalidDueDays, got %v", err)
@@ -87,10 +89,59 @@ func TestCalculateDueDate_WithNegativeDueDays_ReturnsError(t *testing.T) {
 	properties.Property("returns ErrInvalidDueDays", prop.ForAll(
 		func(dueDays int) bool {
 			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
-			_, _, err := CalculateDueDate(now, &dueDays, nil)
+			_, _, err := CalculateDueDate(now, &dueDays, nil, calendar.Calendar{})
 			return errors.Is(err, ErrInvalidDueDays)
 		},
 		gen.IntRange(-10000, -1),
 	))
 	properties.TestingRun(t)
 }
+
+// When CalculateDueDate lands on a closed day then returns the next open day
+func TestCalculateDueDate_OnClosedDay_ReturnsNextOpenDay(t *testing.T) {
+	cal := calendar.New([]time.Weekday{time.Saturday, time.Sunday}, true, nil)
+	// Friday 10:00 in Japan, so one day later is a Saturday.
+	now := time.Date(2025, 7, 18, 1, 0, 0, 0, time.UTC)
+	dueDays := 1
+
+	dueDate, _, err := CalculateDueDate(now, &dueDays, nil, cal)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Saturday, Sunday and 海の日 on Monday are closed.
+	expectedDueDate := time.Date(2025, 7, 22, 1, 0, 0, 0, time.UTC)
+	if !dueDate.Equal(expectedDueDate) {
+		t.Errorf("expected dueDate %v, got %v", expectedDueDate, dueDate)
+	}
+}
+
+// When CalculateDueDate extends a loan then the due date is an open day
+func TestCalculateDueDate_WithCurrentLending_ReturnsOpenDay(t *testing.T) {
+	cal := calendar.New([]time.Weekday{time.Saturday, time.Sunday}, true, nil)
+	properties := gopter.NewProperties(nil)
+	properties.Property("returns open day", prop.ForAll(
+		func(hours int64, dueDaysValue int) bool {
+			currentDueDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(hours) * time.Hour)
+			dueDate, _, err := CalculateDueDate(currentDueDate, &dueDaysValue, &CurrentLending{DueDate: currentDueDate}, cal)
+			return err == nil && cal.IsOpen(dueDate) && !dueDate.Before(currentDueDate.AddDate(0, 0, dueDaysValue))
+		},
+		gen.Int64Range(0, 3*365*24),
+		gen.IntRange(1, 60),
+	))
+	properties.TestingRun(t)
+}
+
+// When LegacyDueDate lands on a closed day then returns the next open day
+func TestLegacyDueDate_OnClosedDay_ReturnsNextOpenDay(t *testing.T) {
+	cal := calendar.New([]time.Weekday{time.Saturday, time.Sunday}, false, nil)
+	// Saturday 10:00 in Japan, so the default due date is a Saturday too.
+	borrowedAt := time.Date(2025, 6, 7, 1, 0, 0, 0, time.UTC)
+
+	dueDate := LegacyDueDate(borrowedAt, cal)
+
+	expectedDueDate := time.Date(2025, 6, 16, 1, 0, 0, 0, time.UTC)
+	if !dueDate.Equal(expectedDueDate) {
+		t.Errorf("expected dueDate %v, got %v", expectedDueDate, dueDate)
+	}
+}
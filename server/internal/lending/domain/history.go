@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"holocron/internal/calendar"
+)
+
+type LendingState string
+
+const (
+	LendingStateAll      LendingState = "all"
+	LendingStateOpen     LendingState = "open"
+	LendingStateReturned LendingState = "returned"
+)
+
+var (
+	ErrInvalidLendingState   = errors.New("state must be all, open or returned")
+	ErrInvalidLendingHistory = errors.New("invalid lending history data")
+)
+
+// ParseLendingState reads the state filter of a lending history. Without it
+// every loan is listed.
+func ParseLendingState(s *string) (LendingState, error) {
+	if s == nil {
+		return LendingStateAll, nil
+	}
+	switch state := LendingState(*s); state {
+	case LendingStateAll, LendingStateOpen, LendingStateReturned:
+		return state, nil
+	}
+	return "", ErrInvalidLendingState
+}
+
+type HistoryPage struct {
+	Limit  int
+	Offset int
+}
+
+// ToHistoryPage reads limit and offset the way the book list does: a limit out
+// of 1 to 100 falls back to 20 and a negative offset to 0.
+func ToHistoryPage(limit, offset *int) HistoryPage {
+	page := HistoryPage{Limit: 20}
+	if limit != nil && *limit > 0 && *limit <= 100 {
+		page.Limit = *limit
+	}
+	if offset != nil && *offset >= 0 {
+		page.Offset = *offset
+	}
+	return page
+}
+
+// LendingEvent is one row of lending_events as the history reads it.
+type LendingEvent struct {
+	LendingID  string
+	BookID     string
+	BorrowerID string
+	EventType  string
+	DueDate    *string
+	OccurredAt string
+}
+
+type DueDateExtension struct {
+	ExtendedAt time.Time
+	DueDate    time.Time
+}
+
+// LendingRecord is a loan from its opening event to its closing one.
+type LendingRecord struct {
+	ID         string
+	BookID     string
+	BorrowerID string
+	BorrowedAt time.Time
+	// DueDate is the due date after every extension.
+	DueDate    time.Time
+	Extensions []DueDateExtension
+	// ReturnedAt and ReturnType are nil while the loan is open. ReturnType is
+	// the event that closed it: returned, force_returned, transferred or lost.
+	ReturnedAt *time.Time
+	ReturnType *string
+	// Condition and ConditionNote are what the book came back in, when it was
+	// recorded on return.
+	Condition     *string
+	ConditionNote *string
+}
+
+// Late tells whether the book came back after its due date. Only a return or
+// a librarian's check-in brings the book back, so an open loan and one closed
+// by a transfer or a loss are never late.
+func (r LendingRecord) Late() bool {
+	if r.ReturnType == nil || (*r.ReturnType != "returned" && *r.ReturnType != "force_returned") {
+		return false
+	}
+	return r.ReturnedAt.After(r.DueDate)
+}
+
+// BuildLendingRecords folds the events of each loan into a record. The events
+// of a loan must be contiguous and in the order they were recorded, starting
+// with its borrowed or lent event; the records keep the order of the loans.
+// Loans recorded without a due date are due on the LegacyDueDate in cal.
+func BuildLendingRecords(events []LendingEvent, cal calendar.Calendar) ([]LendingRecord, error) {
+	records := []LendingRecord{}
+	for _, event := range events {
+		occurredAt, err := time.Parse(time.RFC3339, event.OccurredAt)
+		if err != nil {
+			return nil, ErrInvalidLendingHistory
+		}
+		var dueDate *time.Time
+		if event.DueDate != nil {
+			t, err := time.Parse(time.RFC3339, *event.DueDate)
+			if err != nil {
+				return nil, ErrInvalidLendingHistory
+			}
+			dueDate = &t
+		}
+
+		if event.EventType == "borrowed" || event.EventType == "lent" {
+			record := LendingRecord{
+				ID:         event.LendingID,
+				BookID:     event.BookID,
+				BorrowerID: event.BorrowerID,
+				BorrowedAt: occurredAt,
+				DueDate:    LegacyDueDate(occurredAt, cal),
+				Extensions: []DueDateExtension{},
+			}
+			if dueDate != nil {
+				record.DueDate = *dueDate
+			}
+			records = append(records, record)
+			continue
+		}
+
+		if len(records) == 0 || records[len(records)-1].ID != event.LendingID {
+			return nil, ErrInvalidLendingHistory
+		}
+		record := &records[len(records)-1]
+		switch event.EventType {
+		case "due_date_extended":
+			if dueDate == nil {
+				return nil, ErrInvalidLendingHistory
+			}
+			record.Extensions = append(record.Extensions, DueDateExtension{ExtendedAt: occurredAt, DueDate: *dueDate})
+			record.DueDate = *dueDate
+		case "returned", "force_returned", "transferred", "lost":
+			returnType := event.EventType
+			record.ReturnedAt = &occurredAt
+			record.ReturnType = &returnType
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,138 @@
+//go:build small
+
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"holocron/internal/calendar"
+)
+
+func TestParseLendingState_WithNil_ReturnsAll(t *testing.T) {
+	state, err := ParseLendingState(nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != LendingStateAll {
+		t.Errorf("expected %q, got %q", LendingStateAll, state)
+	}
+}
+
+func TestParseLendingState_WithUnknownState_ReturnsError(t *testing.T) {
+	s := "closed"
+
+	_, err := ParseLendingState(&s)
+
+	if !errors.Is(err, ErrInvalidLendingState) {
+		t.Errorf("expected ErrInvalidLendingState, got %v", err)
+	}
+}
+
+func TestToHistoryPage_WithOutOfRangeValues_ReturnsDefaults(t *testing.T) {
+	limit := 101
+	offset := -1
+
+	page := ToHistoryPage(&limit, &offset)
+
+	if page.Limit != 20 || page.Offset != 0 {
+		t.Errorf("expected limit 20 and offset 0, got %+v", page)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+// When BuildLendingRecords with an extended and late returned loan then returns a late record
+func TestBuildLendingRecords_WithExtendedLateReturn_ReturnsLateRecord(t *testing.T) {
+	events := []LendingEvent{
+		{LendingID: "l1", BookID: "b1", BorrowerID: "u1", EventType: "borrowed", DueDate: ptr("2024-01-08T00:00:00Z"), OccurredAt: "2024-01-01T00:00:00Z"},
+		{LendingID: "l1", BookID: "b1", BorrowerID: "u1", EventType: "due_date_extended", DueDate: ptr("2024-01-15T00:00:00Z"), OccurredAt: "2024-01-07T00:00:00Z"},
+		{LendingID: "l1", BookID: "b1", BorrowerID: "u1", EventType: "returned", OccurredAt: "2024-01-16T00:00:00Z"},
+	}
+
+	records, err := BuildLendingRecords(events, calendar.Calendar{})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	record := records[0]
+	if !record.DueDate.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the extended due date, got %v", record.DueDate)
+	}
+	if len(record.Extensions) != 1 || !record.Extensions[0].ExtendedAt.Equal(time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected extensions %+v", record.Extensions)
+	}
+	if record.ReturnType == nil || *record.ReturnType != "returned" {
+		t.Errorf("expected return type returned, got %v", record.ReturnType)
+	}
+	if !record.Late() {
+		t.Error("expected the return to be late")
+	}
+}
+
+// When a loan is closed after its due date by a transfer or a loss then it is not late
+func TestLendingRecord_Late_WithTransferOrLoss_ReturnsFalse(t *testing.T) {
+	for _, eventType := range []string{"transferred", "lost"} {
+		t.Run(eventType, func(t *testing.T) {
+			events := []LendingEvent{
+				{LendingID: "l1", BookID: "b1", BorrowerID: "u1", EventType: "borrowed", DueDate: ptr("2024-01-08T00:00:00Z"), OccurredAt: "2024-01-01T00:00:00Z"},
+				{LendingID: "l1", BookID: "b1", BorrowerID: "u1", EventType: eventType, OccurredAt: "2024-01-16T00:00:00Z"},
+			}
+
+			records, err := BuildLendingRecords(events, calendar.Calendar{})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if records[0].Late() {
+				t.Errorf("expected a %s loan not to be late", eventType)
+			}
+		})
+	}
+}
+
+// When BuildLendingRecords with an open loan without a due date then returns the default due date
+func TestBuildLendingRecords_WithOpenLoanWithoutDueDate_ReturnsDefaultDueDate(t *testing.T) {
+	events := []LendingEvent{
+		{LendingID: "l2", BookID: "b1", BorrowerID: "u1", EventType: "lent", OccurredAt: "2024-02-01T00:00:00Z"},
+		{LendingID: "l1", BookID: "b1", BorrowerID: "u1", EventType: "borrowed", DueDate: ptr("2024-01-08T00:00:00Z"), OccurredAt: "2024-01-01T00:00:00Z"},
+		{LendingID: "l1", BookID: "b1", BorrowerID: "u1", EventType: "returned", OccurredAt: "2024-01-05T00:00:00Z"},
+	}
+
+	records, err := BuildLendingRecords(events, calendar.Calendar{})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 2 || records[0].ID != "l2" || records[1].ID != "l1" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if !records[0].DueDate.Equal(time.Date(2024, 2, 8, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the default due date, got %v", records[0].DueDate)
+	}
+	if records[0].ReturnedAt != nil || records[0].Late() {
+		t.Errorf("expected an open loan that is not late, got %+v", records[0])
+	}
+	if records[1].Late() {
+		t.Error("expected the return on time not to be late")
+	}
+}
+
+func TestBuildLendingRecords_WithEventBeforeOpening_ReturnsError(t *testing.T) {
+	events := []LendingEvent{
+		{LendingID: "l1", BookID: "b1", BorrowerID: "u1", EventType: "returned", OccurredAt: "2024-01-05T00:00:00Z"},
+	}
+
+	_, err := BuildLendingRecords(events, calendar.Calendar{})
+
+	if !errors.Is(err, ErrInvalidLendingHistory) {
+		t.Errorf("expected ErrInvalidLendingHistory, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const MaxReasonLength = 500
+
+var (
+	ErrInvalidReason = errors.New("reason must be 1 to 500 characters")
+	ErrSameBorrower  = errors.New("loan is already held by the user")
+)
+
+// ParseLendingReason returns the reason a librarian gave for acting on a loan.
+func ParseLendingReason(s string) (string, error) {
+	reason := strings.TrimSpace(s)
+	if reason == "" || utf8.RuneCountInString(reason) > MaxReasonLength {
+		return "", ErrInvalidReason
+	}
+	return reason, nil
+}
+
+// ValidateTransfer checks that a loan is moved to someone other than its
+// current borrower.
+func ValidateTransfer(currentBorrowerID string, newBorrowerID string) error {
+	if currentBorrowerID == newBorrowerID {
+		return ErrSameBorrower
+	}
+	return nil
+}
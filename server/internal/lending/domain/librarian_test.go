@@ -0,0 +1,51 @@
+//go:build small
+
+package domain
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestParseLendingReason_WithValidReason_ReturnsTrimmedReason(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("returns the reason without surrounding spaces", prop.ForAll(
+		func(reason string) bool {
+			parsed, err := ParseLendingReason("  " + reason + "\n")
+			return err == nil && parsed == reason
+		},
+		gen.AlphaString().SuchThat(func(s string) bool {
+			return s != "" && utf8.RuneCountInString(s) <= MaxReasonLength
+		}),
+	))
+	properties.TestingRun(t)
+}
+
+func TestParseLendingReason_WithInvalidReason_ReturnsError(t *testing.T) {
+	for _, reason := range []string{"", " \t\n", strings.Repeat("あ", MaxReasonLength+1)} {
+		if _, err := ParseLendingReason(reason); err != ErrInvalidReason {
+			t.Errorf("expected ErrInvalidReason for %q, got %v", reason, err)
+		}
+	}
+}
+
+func TestValidateTransfer_WithSameBorrower_ReturnsError(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("rejects a transfer to the current borrower only", prop.ForAll(
+		func(current, next string) bool {
+			err := ValidateTransfer(current, next)
+			if current == next {
+				return err == ErrSameBorrower
+			}
+			return err == nil
+		},
+		gen.OneConstOf("user-1", "user-2"),
+		gen.OneConstOf("user-1", "user-2"),
+	))
+	properties.TestingRun(t)
+}
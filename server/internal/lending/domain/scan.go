@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidScanCode = errors.New("scan code must be 1 to 100 characters")
+
+func ParseScanCode(s string) (string, error) {
+	code := strings.TrimSpace(s)
+	if code == "" || utf8.RuneCountInString(code) > 100 {
+		return "", ErrInvalidScanCode
+	}
+	return code, nil
+}
+
+type ScanAction string
+
+const (
+	ScanActionBorrow ScanAction = "borrow"
+	ScanActionReturn ScanAction = "return"
+)
+
+// Ambiguity is why a scan could not be resolved to a single copy.
+type Ambiguity string
+
+const (
+	// AmbiguityMultipleCopies is a code shared by several available copies of
+	// the same title.
+	AmbiguityMultipleCopies Ambiguity = "multiple_copies"
+	// AmbiguityMultipleBooks is a code shared by several different books.
+	AmbiguityMultipleBooks Ambiguity = "multiple_books"
+	// AmbiguityMultipleLoans is a code of which the requester has borrowed
+	// several copies.
+	AmbiguityMultipleLoans Ambiguity = "multiple_loans"
+)
+
+// ScanCandidate is a copy a scanned code may refer to. BorrowerID is empty
+// when the copy is not lent out.
+type ScanCandidate struct {
+	BookID     string
+	Title      string
+	BorrowerID string
+}
+
+// ScanDecision is what a scan does. When Ambiguity is set, BookID is empty and
+// Candidates are the copies the scan could refer to.
+type ScanDecision struct {
+	Action     ScanAction
+	BookID     string
+	Ambiguity  Ambiguity
+	Candidates []ScanCandidate
+}
+
+// ResolveScan decides which copy a scan refers to and whether it is borrowed
+// or returned. A copy the requester has borrowed is returned, since the copy in
+// their hand is most likely that one; otherwise an available copy is borrowed.
+// When every copy is lent to someone else, the first is chosen so that the
+// borrow reports it as already borrowed. The candidates must not be empty.
+func ResolveScan(candidates []ScanCandidate, requesterID string) ScanDecision {
+	var held, available []ScanCandidate
+	for _, c := range candidates {
+		switch c.BorrowerID {
+		case requesterID:
+			held = append(held, c)
+		case "":
+			available = append(available, c)
+		}
+	}
+
+	switch {
+	case len(held) == 1:
+		return ScanDecision{Action: ScanActionReturn, BookID: held[0].BookID}
+	case len(held) > 1:
+		return ScanDecision{Ambiguity: AmbiguityMultipleLoans, Candidates: held}
+	case len(available) == 1:
+		return ScanDecision{Action: ScanActionBorrow, BookID: available[0].BookID}
+	case len(available) > 1:
+		ambiguity := AmbiguityMultipleCopies
+		for _, c := range available[1:] {
+			if c.Title != available[0].Title {
+				ambiguity = AmbiguityMultipleBooks
+				break
+			}
+		}
+		return ScanDecision{Ambiguity: ambiguity, Candidates: available}
+	}
+	return ScanDecision{Action: ScanActionBorrow, BookID: candidates[0].BookID}
+}
@@ -0,0 +1,97 @@
+//go:build small
+
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestParseScanCode_WithBlankOrLongCode_ReturnsError(t *testing.T) {
+	for _, code := range []string{"", "   ", strings.Repeat("1", 101)} {
+		if _, err := ParseScanCode(code); err != ErrInvalidScanCode {
+			t.Errorf("expected ErrInvalidScanCode for %q, got %v", code, err)
+		}
+	}
+}
+
+func TestParseScanCode_TrimsSpaces(t *testing.T) {
+	code, err := ParseScanCode(" 9784873119045\n")
+	if err != nil || code != "9784873119045" {
+		t.Errorf("expected trimmed code, got %q (%v)", code, err)
+	}
+}
+
+func TestResolveScan_WithSingleAvailableCopy_Borrows(t *testing.T) {
+	decision := ResolveScan([]ScanCandidate{{BookID: "book-1"}}, "user-1")
+
+	if decision.Action != ScanActionBorrow || decision.BookID != "book-1" {
+		t.Errorf("expected to borrow book-1, got %+v", decision)
+	}
+}
+
+func TestResolveScan_WithCopyHeldByRequester_ReturnsIt(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("the held copy is returned however many copies are available", prop.ForAll(
+		func(available int) bool {
+			candidates := []ScanCandidate{{BookID: "held", BorrowerID: "user-1"}, {BookID: "other", BorrowerID: "user-2"}}
+			for range available {
+				candidates = append(candidates, ScanCandidate{BookID: "available"})
+			}
+			decision := ResolveScan(candidates, "user-1")
+			return decision.Action == ScanActionReturn && decision.BookID == "held" && decision.Ambiguity == ""
+		},
+		gen.IntRange(0, 5),
+	))
+	properties.TestingRun(t)
+}
+
+func TestResolveScan_WithSeveralHeldCopies_ReportsMultipleLoans(t *testing.T) {
+	decision := ResolveScan([]ScanCandidate{
+		{BookID: "book-1", BorrowerID: "user-1"},
+		{BookID: "book-2", BorrowerID: "user-1"},
+		{BookID: "book-3"},
+	}, "user-1")
+
+	if decision.Ambiguity != AmbiguityMultipleLoans || decision.BookID != "" || len(decision.Candidates) != 2 {
+		t.Errorf("expected multiple loans of 2 copies, got %+v", decision)
+	}
+}
+
+func TestResolveScan_WithSeveralAvailableCopies_ReportsMultipleCopies(t *testing.T) {
+	decision := ResolveScan([]ScanCandidate{
+		{BookID: "book-1", Title: "Title"},
+		{BookID: "book-2", Title: "Title"},
+		{BookID: "book-3", Title: "Title", BorrowerID: "user-2"},
+	}, "user-1")
+
+	if decision.Ambiguity != AmbiguityMultipleCopies || len(decision.Candidates) != 2 {
+		t.Errorf("expected multiple copies of 2 available copies, got %+v", decision)
+	}
+}
+
+func TestResolveScan_WithDifferentBooks_ReportsMultipleBooks(t *testing.T) {
+	decision := ResolveScan([]ScanCandidate{
+		{BookID: "book-1", Title: "Title"},
+		{BookID: "book-2", Title: "Another title"},
+	}, "user-1")
+
+	if decision.Ambiguity != AmbiguityMultipleBooks {
+		t.Errorf("expected multiple books, got %+v", decision)
+	}
+}
+
+func TestResolveScan_WithAllCopiesLentToOthers_BorrowsFirst(t *testing.T) {
+	decision := ResolveScan([]ScanCandidate{
+		{BookID: "book-1", BorrowerID: "user-2"},
+		{BookID: "book-2", BorrowerID: "user-3"},
+	}, "user-1")
+
+	if decision.Action != ScanActionBorrow || decision.BookID != "book-1" {
+		t.Errorf("expected to borrow book-1, got %+v", decision)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"time"
 
+	"holocron/internal/api"
 	"holocron/internal/auth"
 	"holocron/internal/book"
+	bookDomain "holocron/internal/book/domain"
 	"holocron/internal/lending/domain"
 )
 
@@ -79,11 +81,11 @@ func (h *BorrowBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 type ReturnBookHandler struct {
-	service     *ReturnBookService
+	service     *BookConditionService
 	bookQueries *book.Queries
 }
 
-func NewReturnBookHandler(service *ReturnBookService, bookQueries *book.Queries) *ReturnBookHandler {
+func NewReturnBookHandler(service *BookConditionService, bookQueries *book.Queries) *ReturnBookHandler {
 	return &ReturnBookHandler{
 		service:     service,
 		bookQueries: bookQueries,
@@ -103,13 +105,34 @@ func (h *ReturnBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := h.service.ReturnBook(r.Context(), ReturnBookInput{
-		BookID:      bookID,
-		RequesterID: userID,
+	var req struct {
+		Condition *string `json:"condition"`
+		Note      *string `json:"note"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			if err != io.EOF {
+				writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+				return
+			}
+		}
+	}
+
+	output, err := h.service.ReturnBook(r.Context(), ConditionReturnInput{
+		ReturnBookInput: ReturnBookInput{
+			BookID:      bookID,
+			RequesterID: userID,
+		},
+		Condition: req.Condition,
+		Note:      req.Note,
 	})
 
 	if err != nil {
 		switch {
+		case errors.Is(err, bookDomain.ErrInvalidCondition):
+			writeError(w, http.StatusBadRequest, "invalid_request", "condition must be one of: good, damaged, missing_pages")
+		case errors.Is(err, bookDomain.ErrInvalidConditionNote):
+			writeError(w, http.StatusBadRequest, "invalid_request", "note must be at most 500 characters")
 		case errors.Is(err, ErrBookNotBorrowed):
 			writeError(w, http.StatusConflict, "not_borrowed", "this book is not currently borrowed")
 		case errors.Is(err, ErrNotBorrower):
@@ -123,7 +146,7 @@ func (h *ReturnBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	bookDetails, err := book.GetBook(r.Context(), h.bookQueries, book.GetBookInput{
-		BookID: bookID,
+		BookID: output.BookID,
 	})
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal_error", "failed to retrieve book details")
@@ -155,6 +178,621 @@ func (h *ReturnBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+type ReportLostHandler struct {
+	service *BookConditionService
+	roles   auth.Roles
+}
+
+func NewReportLostHandler(service *BookConditionService, roles auth.Roles) *ReportLostHandler {
+	return &ReportLostHandler{service: service, roles: roles}
+}
+
+func (h *ReportLostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bookID := r.PathValue("bookId")
+	if bookID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "bookId is required")
+		return
+	}
+
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	var req struct {
+		Memo *string `json:"memo"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			if err != io.EOF {
+				writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+				return
+			}
+		}
+	}
+
+	output, err := h.service.ReportLost(r.Context(), ReportLostInput{
+		BookID:      bookID,
+		RequesterID: userID,
+		AsLibrarian: h.roles.IsLibrarian(userID),
+		Memo:        req.Memo,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidReason):
+			writeError(w, http.StatusBadRequest, "invalid_request", "memo must be 1 to 500 characters")
+		case errors.Is(err, ErrBookNotBorrowed):
+			writeError(w, http.StatusConflict, "not_borrowed", "this book is not currently borrowed")
+		case errors.Is(err, ErrNotBorrower):
+			writeError(w, http.StatusForbidden, "forbidden", "only the borrower or a librarian can report this book lost")
+		case errors.Is(err, ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "book_not_found", "book not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"lendingId":  output.LendingID,
+		"bookId":     output.BookID,
+		"borrowerId": output.BorrowerID,
+		"reportedAt": output.ReportedAt.Format(time.RFC3339),
+	})
+}
+
+type ScanHandler struct {
+	service *ScanService
+}
+
+func NewScanHandler(service *ScanService) *ScanHandler {
+	return &ScanHandler{
+		service: service,
+	}
+}
+
+func (h *ScanHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	var req struct {
+		Code    string `json:"code"`
+		DueDays *int   `json:"dueDays"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	output, err := h.service.Scan(r.Context(), ScanInput{
+		Code:        req.Code,
+		RequesterID: userID,
+		DueDays:     req.DueDays,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidScanCode):
+			writeError(w, http.StatusBadRequest, "invalid_request", "code must be 1 to 100 characters")
+		case errors.Is(err, domain.ErrInvalidDueDays):
+			writeError(w, http.StatusBadRequest, "invalid_request", "due days must be at least 1")
+		case errors.Is(err, ErrBookAlreadyBorrowed):
+			writeError(w, http.StatusConflict, "book_already_borrowed", "book is already borrowed by another user")
+		case errors.Is(err, ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "book_not_found", "no book matches the code")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	if output.Ambiguity != "" {
+		candidates := make([]map[string]any, 0, len(output.Candidates))
+		for _, c := range output.Candidates {
+			status := "available"
+			if c.BorrowerID != "" {
+				status = "borrowed"
+			}
+			candidates = append(candidates, map[string]any{
+				"id":     c.BookID,
+				"title":  c.Title,
+				"status": status,
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"code":       "ambiguous_scan",
+			"message":    "the code matches more than one copy",
+			"reason":     output.Ambiguity,
+			"candidates": candidates,
+		})
+		return
+	}
+
+	resp := map[string]any{"action": output.Action}
+	if output.Borrowed != nil {
+		resp["lendingId"] = output.Borrowed.ID
+		resp["bookId"] = output.Borrowed.BookID
+		resp["borrowerId"] = output.Borrowed.BorrowerID
+		resp["borrowedAt"] = output.Borrowed.BorrowedAt.Format(time.RFC3339)
+		resp["dueDate"] = output.Borrowed.DueDate.Format(time.RFC3339)
+	}
+	if output.Returned != nil {
+		resp["lendingId"] = output.Returned.LendingID
+		resp["bookId"] = output.Returned.BookID
+		resp["borrowerId"] = output.Returned.BorrowerID
+		resp["returnedAt"] = output.Returned.ReturnedAt.Format(time.RFC3339)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type BatchBorrowHandler struct {
+	service *BatchLendingService
+}
+
+func NewBatchBorrowHandler(service *BatchLendingService) *BatchBorrowHandler {
+	return &BatchBorrowHandler{
+		service: service,
+	}
+}
+
+func (h *BatchBorrowHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	var req struct {
+		BookIDs []string `json:"bookIds"`
+		DueDays *int     `json:"dueDays"`
+		Atomic  bool     `json:"atomic"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	output, err := h.service.BorrowBooks(r.Context(), BatchBorrowInput{
+		BookIDs:    req.BookIDs,
+		BorrowerID: userID,
+		DueDays:    req.DueDays,
+		Atomic:     req.Atomic,
+	})
+	writeBatchOutput(w, output, err)
+}
+
+type BatchReturnHandler struct {
+	service *BatchLendingService
+}
+
+func NewBatchReturnHandler(service *BatchLendingService) *BatchReturnHandler {
+	return &BatchReturnHandler{
+		service: service,
+	}
+}
+
+func (h *BatchReturnHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	var req struct {
+		BookIDs []string `json:"bookIds"`
+		Atomic  bool     `json:"atomic"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	output, err := h.service.ReturnBooks(r.Context(), BatchReturnInput{
+		BookIDs:     req.BookIDs,
+		RequesterID: userID,
+		Atomic:      req.Atomic,
+	})
+	writeBatchOutput(w, output, err)
+}
+
+func writeBatchOutput(w http.ResponseWriter, output *BatchOutput, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidBatchSize):
+			writeError(w, http.StatusBadRequest, "invalid_request", "bookIds must have 1 to 50 items")
+		case errors.Is(err, domain.ErrInvalidDueDays):
+			writeError(w, http.StatusBadRequest, "invalid_request", "due days must be at least 1")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	items := make([]map[string]any, 0, len(output.Items))
+	counts := map[domain.BatchItemStatus]int{}
+	for _, item := range output.Items {
+		resp := map[string]any{
+			"ref":    item.Ref,
+			"status": item.Status,
+		}
+		counts[item.Status]++
+		if item.Borrowed != nil {
+			resp["bookId"] = item.Borrowed.BookID
+			resp["lendingId"] = item.Borrowed.ID
+			resp["borrowedAt"] = item.Borrowed.BorrowedAt.Format(time.RFC3339)
+			resp["dueDate"] = item.Borrowed.DueDate.Format(time.RFC3339)
+		}
+		if item.Returned != nil {
+			resp["bookId"] = item.Returned.BookID
+			resp["lendingId"] = item.Returned.LendingID
+			resp["returnedAt"] = item.Returned.ReturnedAt.Format(time.RFC3339)
+		}
+		if item.Err != nil {
+			code, message := batchItemError(item.Err)
+			resp["error"] = map[string]string{
+				"code":    code,
+				"message": message,
+			}
+		}
+		items = append(items, resp)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"succeeded":  counts[domain.BatchItemSucceeded],
+		"failed":     counts[domain.BatchItemFailed],
+		"rolledBack": counts[domain.BatchItemRolledBack],
+		"items":      items,
+	})
+}
+
+// batchItemError returns the error code and message of a failed item, matching
+// the errors of the single borrow and return endpoints.
+func batchItemError(err error) (string, string) {
+	switch {
+	case errors.Is(err, ErrBookAlreadyBorrowed):
+		return "book_already_borrowed", "book is already borrowed by another user"
+	case errors.Is(err, ErrBookNotBorrowed):
+		return "not_borrowed", "this book is not currently borrowed"
+	case errors.Is(err, ErrNotBorrower):
+		return "forbidden", "only the borrower can return this book"
+	case errors.Is(err, ErrDuplicateBatchItem):
+		return "duplicate", "book appears more than once in the batch"
+	default:
+		return "book_not_found", "book not found"
+	}
+}
+
+type LendBookHandler struct {
+	service *LibrarianLendingService
+	roles   auth.Roles
+}
+
+func NewLendBookHandler(service *LibrarianLendingService, roles auth.Roles) *LendBookHandler {
+	return &LendBookHandler{service: service, roles: roles}
+}
+
+func (h *LendBookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bookID := r.PathValue("bookId")
+	if bookID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "bookId is required")
+		return
+	}
+
+	actorID, ok := librarianID(w, r, h.roles)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		BorrowerID string  `json:"borrowerId"`
+		DueDays    *int    `json:"dueDays"`
+		Reason     *string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+	if req.BorrowerID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "borrowerId is required")
+		return
+	}
+
+	output, err := h.service.LendBook(r.Context(), LendBookInput{
+		BookID:     bookID,
+		BorrowerID: req.BorrowerID,
+		ActorID:    actorID,
+		DueDays:    req.DueDays,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		writeLibrarianLendingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":         output.ID,
+		"bookId":     output.BookID,
+		"borrowerId": output.BorrowerID,
+		"borrowedAt": output.BorrowedAt.Format(time.RFC3339),
+		"dueDate":    output.DueDate.Format(time.RFC3339),
+	})
+}
+
+type ForceReturnHandler struct {
+	service *LibrarianLendingService
+	roles   auth.Roles
+}
+
+func NewForceReturnHandler(service *LibrarianLendingService, roles auth.Roles) *ForceReturnHandler {
+	return &ForceReturnHandler{service: service, roles: roles}
+}
+
+func (h *ForceReturnHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bookID := r.PathValue("bookId")
+	if bookID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "bookId is required")
+		return
+	}
+
+	actorID, ok := librarianID(w, r, h.roles)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+
+	output, err := h.service.ForceReturnBook(r.Context(), ForceReturnInput{
+		BookID:  bookID,
+		ActorID: actorID,
+		Reason:  req.Reason,
+	})
+	if err != nil {
+		writeLibrarianLendingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"lendingId":  output.LendingID,
+		"bookId":     output.BookID,
+		"borrowerId": output.BorrowerID,
+		"returnedAt": output.ReturnedAt.Format(time.RFC3339),
+	})
+}
+
+type TransferLoanHandler struct {
+	service *LibrarianLendingService
+	roles   auth.Roles
+}
+
+func NewTransferLoanHandler(service *LibrarianLendingService, roles auth.Roles) *TransferLoanHandler {
+	return &TransferLoanHandler{service: service, roles: roles}
+}
+
+func (h *TransferLoanHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bookID := r.PathValue("bookId")
+	if bookID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "bookId is required")
+		return
+	}
+
+	actorID, ok := librarianID(w, r, h.roles)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		BorrowerID string  `json:"borrowerId"`
+		Reason     *string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+	if req.BorrowerID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "borrowerId is required")
+		return
+	}
+
+	output, err := h.service.TransferLoan(r.Context(), TransferLoanInput{
+		BookID:     bookID,
+		BorrowerID: req.BorrowerID,
+		ActorID:    actorID,
+		Reason:     req.Reason,
+	})
+	if err != nil {
+		writeLibrarianLendingError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":                 output.Borrowed.ID,
+		"bookId":             output.Borrowed.BookID,
+		"borrowerId":         output.Borrowed.BorrowerID,
+		"borrowedAt":         output.Borrowed.BorrowedAt.Format(time.RFC3339),
+		"dueDate":            output.Borrowed.DueDate.Format(time.RFC3339),
+		"previousLendingId":  output.Returned.LendingID,
+		"previousBorrowerId": output.Returned.BorrowerID,
+	})
+}
+
+type ListMyLendingsHandler struct {
+	service *LendingHistoryService
+}
+
+func NewListMyLendingsHandler(service *LendingHistoryService) *ListMyLendingsHandler {
+	return &ListMyLendingsHandler{service: service}
+}
+
+func (h *ListMyLendingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.GetUsersMeLendingsParams) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	var state *string
+	if params.State != nil {
+		s := string(*params.State)
+		state = &s
+	}
+	output, err := h.service.ListUserLendings(r.Context(), ListUserLendingsInput{
+		BorrowerID: userID,
+		State:      state,
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidLendingState):
+			writeError(w, http.StatusBadRequest, "invalid_request", "state must be all, open or returned")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+	writeLendingHistory(w, output)
+}
+
+type ListBookLendingsHandler struct {
+	service *LendingHistoryService
+}
+
+func NewListBookLendingsHandler(service *LendingHistoryService) *ListBookLendingsHandler {
+	return &ListBookLendingsHandler{service: service}
+}
+
+func (h *ListBookLendingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, bookID string, params api.GetBooksLendingsParams) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	output, err := h.service.ListBookLendings(r.Context(), ListBookLendingsInput{
+		BookID: bookID,
+		Limit:  params.Limit,
+		Offset: params.Offset,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBookNotFound):
+			writeError(w, http.StatusNotFound, "book_not_found", "book not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+	writeLendingHistory(w, output)
+}
+
+func writeLendingHistory(w http.ResponseWriter, output *LendingHistoryOutput) {
+	items := make([]map[string]any, 0, len(output.Items))
+	for _, item := range output.Items {
+		extensions := make([]map[string]any, 0, len(item.Extensions))
+		for _, extension := range item.Extensions {
+			extensions = append(extensions, map[string]any{
+				"extendedAt": extension.ExtendedAt.Format(time.RFC3339),
+				"dueDate":    extension.DueDate.Format(time.RFC3339),
+			})
+		}
+		m := map[string]any{
+			"id":         item.ID,
+			"bookId":     item.BookID,
+			"borrowerId": item.BorrowerID,
+			"borrowedAt": item.BorrowedAt.Format(time.RFC3339),
+			"dueDate":    item.DueDate.Format(time.RFC3339),
+			"extensions": extensions,
+			"late":       item.Late(),
+		}
+		if item.Title != nil {
+			m["title"] = *item.Title
+		}
+		if item.BorrowerName != nil {
+			m["borrowerName"] = *item.BorrowerName
+		}
+		if item.ReturnedAt != nil {
+			m["returnedAt"] = item.ReturnedAt.Format(time.RFC3339)
+			m["returnType"] = *item.ReturnType
+		}
+		if item.Condition != nil {
+			m["condition"] = *item.Condition
+		}
+		if item.ConditionNote != nil {
+			m["conditionNote"] = *item.ConditionNote
+		}
+		items = append(items, m)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"items":  items,
+		"total":  output.Total,
+		"limit":  output.Limit,
+		"offset": output.Offset,
+	})
+}
+
+func writeLibrarianLendingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDueDays):
+		writeError(w, http.StatusBadRequest, "invalid_request", "due days must be at least 1")
+	case errors.Is(err, domain.ErrInvalidReason):
+		writeError(w, http.StatusBadRequest, "invalid_request", "reason must be 1 to 500 characters")
+	case errors.Is(err, ErrBookNotFound):
+		writeError(w, http.StatusNotFound, "book_not_found", "book not found")
+	case errors.Is(err, ErrUserNotFound):
+		writeError(w, http.StatusNotFound, "user_not_found", "user not found")
+	case errors.Is(err, ErrBookAlreadyBorrowed):
+		writeError(w, http.StatusConflict, "book_already_borrowed", "book is already borrowed")
+	case errors.Is(err, ErrBookNotBorrowed):
+		writeError(w, http.StatusConflict, "not_borrowed", "this book is not currently borrowed")
+	case errors.Is(err, domain.ErrSameBorrower):
+		writeError(w, http.StatusConflict, "same_borrower", "the loan is already held by the user")
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
+
+// librarianID returns the ID of the authenticated user, writing an error
+// response unless the user is a librarian.
+func librarianID(w http.ResponseWriter, r *http.Request, roles auth.Roles) (string, bool) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return "", false
+	}
+	if !roles.IsLibrarian(userID) {
+		writeError(w, http.StatusForbidden, "forbidden", "librarian role is required")
+		return "", false
+	}
+	return userID, true
+}
+
 func writeError(w http.ResponseWriter, status int, code, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
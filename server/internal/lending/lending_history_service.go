@@ -0,0 +1,191 @@
+package lending
+
+import (
+	"context"
+	"database/sql"
+
+	"holocron/internal/calendar"
+	"holocron/internal/lending/domain"
+)
+
+type ListUserLendingsInput struct {
+	BorrowerID string
+	State      *string
+	Limit      *int
+	Offset     *int
+}
+
+type ListBookLendingsInput struct {
+	// BookID is a book ID or the accession number printed on a book's label.
+	BookID string
+	Limit  *int
+	Offset *int
+}
+
+// LendingHistoryItem is a loan with the name of the other side: the book title
+// in a user's history and the borrower name in a book's history.
+type LendingHistoryItem struct {
+	domain.LendingRecord
+	Title        *string
+	BorrowerName *string
+}
+
+type LendingHistoryOutput struct {
+	Items  []LendingHistoryItem
+	Total  int64
+	Limit  int
+	Offset int
+}
+
+// LendingHistoryService lists past and current loans, rebuilt from the events
+// of each loan.
+type LendingHistoryService struct {
+	lendingQueries *Queries
+	bookQueries    BookQueries
+	calendar       calendar.Calendar
+}
+
+func NewLendingHistoryService(lendingQueries *Queries, bookQueries BookQueries, cal calendar.Calendar) *LendingHistoryService {
+	return &LendingHistoryService{
+		lendingQueries: lendingQueries,
+		bookQueries:    bookQueries,
+		calendar:       cal,
+	}
+}
+
+// ListUserLendings returns the loans of a user, newest first.
+func (s *LendingHistoryService) ListUserLendings(ctx context.Context, input ListUserLendingsInput) (*LendingHistoryOutput, error) {
+	state, err := domain.ParseLendingState(input.State)
+	if err != nil {
+		return nil, err
+	}
+	page := domain.ToHistoryPage(input.Limit, input.Offset)
+
+	total, err := s.lendingQueries.CountLendingsByBorrowerID(ctx, CountLendingsByBorrowerIDParams{
+		BorrowerID: input.BorrowerID,
+		State:      string(state),
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.lendingQueries.ListLendingHistoryByBorrowerID(ctx, ListLendingHistoryByBorrowerIDParams{
+		BorrowerID: input.BorrowerID,
+		State:      string(state),
+		Limit:      int64(page.Limit),
+		Offset:     int64(page.Offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.LendingEvent, 0, len(rows))
+	titles := map[string]*string{}
+	conditions := map[string]lendingCondition{}
+	for _, row := range rows {
+		events = append(events, toLendingEvent(row.LendingID, row.BookID, row.BorrowerID, row.EventType, row.DueDate, row.OccurredAt))
+		if row.Title.Valid {
+			title := row.Title.String
+			titles[row.LendingID] = &title
+		}
+		conditions[row.LendingID] = lendingCondition{row.Condition, row.ConditionNote}
+	}
+	records, err := domain.BuildLendingRecords(events, s.calendar)
+	if err != nil {
+		return nil, err
+	}
+	setConditions(records, conditions)
+
+	items := make([]LendingHistoryItem, 0, len(records))
+	for _, record := range records {
+		items = append(items, LendingHistoryItem{LendingRecord: record, Title: titles[record.ID]})
+	}
+	return &LendingHistoryOutput{Items: items, Total: total, Limit: page.Limit, Offset: page.Offset}, nil
+}
+
+// ListBookLendings returns the loans of a book, newest first, including the
+// loans of a book that has since been deleted.
+func (s *LendingHistoryService) ListBookLendings(ctx context.Context, input ListBookLendingsInput) (*LendingHistoryOutput, error) {
+	page := domain.ToHistoryPage(input.Limit, input.Offset)
+
+	bookID, err := resolveBookID(ctx, s.bookQueries, input.BookID)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.lendingQueries.CountLendingsByBookID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	// A deleted book, such as one reported lost, keeps the history of its loans.
+	if total == 0 {
+		count, err := s.bookQueries.CountBookByBookId(ctx, bookID)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, ErrBookNotFound
+		}
+	}
+	rows, err := s.lendingQueries.ListLendingHistoryByBookID(ctx, ListLendingHistoryByBookIDParams{
+		BookID: bookID,
+		Limit:  int64(page.Limit),
+		Offset: int64(page.Offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.LendingEvent, 0, len(rows))
+	names := map[string]*string{}
+	conditions := map[string]lendingCondition{}
+	for _, row := range rows {
+		events = append(events, toLendingEvent(row.LendingID, row.BookID, row.BorrowerID, row.EventType, row.DueDate, row.OccurredAt))
+		if row.BorrowerName.Valid {
+			name := row.BorrowerName.String
+			names[row.LendingID] = &name
+		}
+		conditions[row.LendingID] = lendingCondition{row.Condition, row.ConditionNote}
+	}
+	records, err := domain.BuildLendingRecords(events, s.calendar)
+	if err != nil {
+		return nil, err
+	}
+	setConditions(records, conditions)
+
+	items := make([]LendingHistoryItem, 0, len(records))
+	for _, record := range records {
+		items = append(items, LendingHistoryItem{LendingRecord: record, BorrowerName: names[record.ID]})
+	}
+	return &LendingHistoryOutput{Items: items, Total: total, Limit: page.Limit, Offset: page.Offset}, nil
+}
+
+// lendingCondition is the condition recorded when a loan's book came back.
+type lendingCondition struct {
+	condition sql.NullString
+	note      sql.NullString
+}
+
+func setConditions(records []domain.LendingRecord, conditions map[string]lendingCondition) {
+	for i := range records {
+		c := conditions[records[i].ID]
+		if c.condition.Valid {
+			records[i].Condition = &c.condition.String
+		}
+		if c.note.Valid {
+			records[i].ConditionNote = &c.note.String
+		}
+	}
+}
+
+func toLendingEvent(lendingID, bookID, borrowerID, eventType string, dueDate sql.NullString, occurredAt string) domain.LendingEvent {
+	event := domain.LendingEvent{
+		LendingID:  lendingID,
+		BookID:     bookID,
+		BorrowerID: borrowerID,
+		EventType:  eventType,
+		OccurredAt: occurredAt,
+	}
+	if dueDate.Valid {
+		event.DueDate = &dueDate.String
+	}
+	return event
+}
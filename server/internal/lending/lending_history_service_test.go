@@ -0,0 +1,287 @@
+//go:build medium
+
+package lending
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"holocron/internal/calendar"
+	"holocron/internal/lending/domain"
+
+	"github.com/google/uuid"
+)
+
+type historyFixture struct {
+	db             *sql.DB
+	lendingQueries *Queries
+	bookID         string
+	borrowerID     string
+	otherUserID    string
+	service        *LendingHistoryService
+}
+
+func newHistoryFixture(t *testing.T) *historyFixture {
+	t.Helper()
+	db := setupTestDB(t)
+	_, err := db.Exec(`
+		CREATE TABLE user_events (
+			event_id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE TABLE book_events (
+			event_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			authors TEXT,
+			publisher TEXT,
+			published_date TEXT,
+			thumbnail_url TEXT,
+			delete_reason TEXT,
+			delete_memo TEXT,
+			origin TEXT,
+			cover_id TEXT,
+			tags TEXT,
+			category_id TEXT,
+			shelf_location TEXT,
+			series_id TEXT,
+			volume_number INTEGER,
+			accession_number TEXT,
+			condition TEXT,
+			condition_note TEXT,
+			lending_id TEXT,
+			occurred_at TEXT NOT NULL
+		);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+
+	f := &historyFixture{
+		db:             db,
+		lendingQueries: New(db),
+		bookID:         uuid.New().String(),
+		borrowerID:     uuid.New().String(),
+		otherUserID:    uuid.New().String(),
+	}
+	_, err = db.Exec(`INSERT INTO book_events (event_id, book_id, event_type, title, occurred_at) VALUES (?, ?, 'created', '旧題', '2023-12-01T00:00:00Z'), (?, ?, 'updated', 'Go言語入門', '2023-12-02T00:00:00Z')`,
+		uuid.New().String(), f.bookID, uuid.New().String(), f.bookID)
+	if err != nil {
+		t.Fatalf("failed to insert book: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO user_events VALUES (?, ?, 'created', '山田太郎', '2023-12-01T00:00:00Z'), (?, ?, 'created', '鈴木花子', '2023-12-01T00:00:00Z')`,
+		uuid.New().String(), f.borrowerID, uuid.New().String(), f.otherUserID)
+	if err != nil {
+		t.Fatalf("failed to insert users: %v", err)
+	}
+	bookQueries := &fakeBookQueries{
+		countByBookId:    map[string]int64{f.bookID: 1},
+		accessionNumbers: map[string]string{"HC-000001": f.bookID},
+	}
+	f.service = NewLendingHistoryService(f.lendingQueries, bookQueries, calendar.Calendar{})
+	return f
+}
+
+func (f *historyFixture) insert(t *testing.T, lendingID, borrowerID, eventType, dueDate, occurredAt string) {
+	t.Helper()
+	err := f.lendingQueries.InsertLendingEvent(context.Background(), InsertLendingEventParams{
+		EventID:    uuid.New().String(),
+		LendingID:  lendingID,
+		BookID:     f.bookID,
+		BorrowerID: borrowerID,
+		EventType:  eventType,
+		DueDate:    sql.NullString{String: dueDate, Valid: dueDate != ""},
+		OccurredAt: occurredAt,
+	})
+	if err != nil {
+		t.Fatalf("precondition failed: %v", err)
+	}
+}
+
+// seed records a late return with an extension, a loan of the other user
+// returned on time and an open loan, oldest first.
+func (f *historyFixture) seed(t *testing.T) (returned, other, open string) {
+	t.Helper()
+	returned, other, open = uuid.New().String(), uuid.New().String(), uuid.New().String()
+	f.insert(t, returned, f.borrowerID, "borrowed", "2024-01-08T00:00:00Z", "2024-01-01T00:00:00Z")
+	f.insert(t, returned, f.borrowerID, "due_date_extended", "2024-01-15T00:00:00Z", "2024-01-07T00:00:00Z")
+	f.insert(t, returned, f.borrowerID, "returned", "", "2024-01-16T00:00:00Z")
+	f.insert(t, other, f.otherUserID, "lent", "2024-01-27T00:00:00Z", "2024-01-20T00:00:00Z")
+	f.insert(t, other, f.otherUserID, "returned", "", "2024-01-25T00:00:00Z")
+	f.insert(t, open, f.borrowerID, "borrowed", "2024-02-08T00:00:00Z", "2024-02-01T00:00:00Z")
+	return returned, other, open
+}
+
+func TestListUserLendings_WithLoans_ReturnsNewestFirst(t *testing.T) {
+	f := newHistoryFixture(t)
+	returned, _, open := f.seed(t)
+
+	output, err := f.service.ListUserLendings(context.Background(), ListUserLendingsInput{BorrowerID: f.borrowerID})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Total != 2 || len(output.Items) != 2 {
+		t.Fatalf("expected 2 loans, got total %d and %+v", output.Total, output.Items)
+	}
+	if output.Items[0].ID != open || output.Items[1].ID != returned {
+		t.Errorf("expected the open loan first, got %s then %s", output.Items[0].ID, output.Items[1].ID)
+	}
+	if output.Items[0].ReturnedAt != nil || output.Items[0].Late() {
+		t.Errorf("expected an open loan, got %+v", output.Items[0])
+	}
+	late := output.Items[1]
+	if late.Title == nil || *late.Title != "Go言語入門" {
+		t.Errorf("expected the latest title, got %v", late.Title)
+	}
+	if len(late.Extensions) != 1 || !late.DueDate.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the extended due date, got %v and %+v", late.DueDate, late.Extensions)
+	}
+	if late.ReturnType == nil || *late.ReturnType != "returned" || !late.Late() {
+		t.Errorf("expected a late return, got %+v", late)
+	}
+}
+
+func TestListUserLendings_WithState_ReturnsMatchingLoans(t *testing.T) {
+	f := newHistoryFixture(t)
+	returned, _, open := f.seed(t)
+
+	tests := []struct {
+		state    string
+		expected string
+	}{
+		{state: "open", expected: open},
+		{state: "returned", expected: returned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			state := tt.state
+
+			output, err := f.service.ListUserLendings(context.Background(), ListUserLendingsInput{BorrowerID: f.borrowerID, State: &state})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if output.Total != 1 || len(output.Items) != 1 || output.Items[0].ID != tt.expected {
+				t.Errorf("expected only %s, got total %d and %+v", tt.expected, output.Total, output.Items)
+			}
+		})
+	}
+}
+
+func TestListUserLendings_WithLimitAndOffset_ReturnsPage(t *testing.T) {
+	f := newHistoryFixture(t)
+	returned, _, _ := f.seed(t)
+	limit, offset := 1, 1
+
+	output, err := f.service.ListUserLendings(context.Background(), ListUserLendingsInput{BorrowerID: f.borrowerID, Limit: &limit, Offset: &offset})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Total != 2 || output.Limit != 1 || output.Offset != 1 {
+		t.Errorf("unexpected page %d/%d of %d", output.Limit, output.Offset, output.Total)
+	}
+	if len(output.Items) != 1 || output.Items[0].ID != returned || len(output.Items[0].Extensions) != 1 {
+		t.Errorf("expected the returned loan with all its events, got %+v", output.Items)
+	}
+}
+
+func TestListUserLendings_WithInvalidState_ReturnsError(t *testing.T) {
+	f := newHistoryFixture(t)
+	state := "closed"
+
+	_, err := f.service.ListUserLendings(context.Background(), ListUserLendingsInput{BorrowerID: f.borrowerID, State: &state})
+
+	if !errors.Is(err, domain.ErrInvalidLendingState) {
+		t.Errorf("expected ErrInvalidLendingState, got %v", err)
+	}
+}
+
+func TestListBookLendings_WithAccessionNumber_ReturnsLoansOfEveryBorrower(t *testing.T) {
+	f := newHistoryFixture(t)
+	returned, other, open := f.seed(t)
+
+	output, err := f.service.ListBookLendings(context.Background(), ListBookLendingsInput{BookID: "HC-000001"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Total != 3 || len(output.Items) != 3 {
+		t.Fatalf("expected 3 loans, got total %d and %+v", output.Total, output.Items)
+	}
+	if output.Items[0].ID != open || output.Items[1].ID != other || output.Items[2].ID != returned {
+		t.Errorf("unexpected order %s, %s, %s", output.Items[0].ID, output.Items[1].ID, output.Items[2].ID)
+	}
+	if output.Items[1].BorrowerName == nil || *output.Items[1].BorrowerName != "鈴木花子" {
+		t.Errorf("expected the borrower name, got %v", output.Items[1].BorrowerName)
+	}
+	if output.Items[1].Late() {
+		t.Error("expected the return on time not to be late")
+	}
+}
+
+func TestListUserLendings_WithRecordedCondition_ReturnsItOnTheLoan(t *testing.T) {
+	f := newHistoryFixture(t)
+	returned, _, _ := f.seed(t)
+	_, err := f.db.Exec(`
+		INSERT INTO book_events (event_id, book_id, event_type, condition, condition_note, lending_id, occurred_at)
+		VALUES (?, ?, 'condition_recorded', 'damaged', '表紙に水濡れ', ?, '2024-01-16T00:00:00Z')
+	`, uuid.New().String(), f.bookID, returned)
+	if err != nil {
+		t.Fatalf("failed to insert condition: %v", err)
+	}
+
+	output, err := f.service.ListUserLendings(context.Background(), ListUserLendingsInput{BorrowerID: f.borrowerID})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, item := range output.Items {
+		if item.ID != returned {
+			if item.Condition != nil || item.ConditionNote != nil {
+				t.Errorf("expected no condition on %s, got %v", item.ID, *item.Condition)
+			}
+			continue
+		}
+		if item.Condition == nil || *item.Condition != "damaged" || item.ConditionNote == nil || *item.ConditionNote != "表紙に水濡れ" {
+			t.Errorf("expected the damaged condition with its note, got %v and %v", item.Condition, item.ConditionNote)
+		}
+	}
+}
+
+func TestListBookLendings_WithLostBook_ReturnsLoans(t *testing.T) {
+	f := newHistoryFixture(t)
+	lost := uuid.New().String()
+	f.insert(t, lost, f.borrowerID, "borrowed", "2024-01-08T00:00:00Z", "2024-01-01T00:00:00Z")
+	f.insert(t, lost, f.borrowerID, "lost", "", "2024-01-05T00:00:00Z")
+	// The book is deleted when it is reported lost.
+	f.service = NewLendingHistoryService(f.lendingQueries, &fakeBookQueries{countByBookId: map[string]int64{}}, calendar.Calendar{})
+
+	output, err := f.service.ListBookLendings(context.Background(), ListBookLendingsInput{BookID: f.bookID})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Total != 1 || len(output.Items) != 1 || output.Items[0].ReturnType == nil || *output.Items[0].ReturnType != "lost" {
+		t.Errorf("expected the lost loan, got total %d and %+v", output.Total, output.Items)
+	}
+}
+
+func TestListBookLendings_WithUnknownBook_ReturnsError(t *testing.T) {
+	f := newHistoryFixture(t)
+
+	_, err := f.service.ListBookLendings(context.Background(), ListBookLendingsInput{BookID: uuid.New().String()})
+
+	if !errors.Is(err, ErrBookNotFound) {
+		t.Errorf("expected ErrBookNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,316 @@
+package lending
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"holocron/internal/calendar"
+	"holocron/internal/lending/domain"
+
+	"github.com/google/uuid"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+type UserQueries interface {
+	CountUserByUserId(ctx context.Context, userID string) (int64, error)
+}
+
+type LendBookInput struct {
+	// BookID is a book ID or the accession number printed on a book's label.
+	BookID     string
+	BorrowerID string
+	ActorID    string
+	DueDays    *int
+	Reason     *string
+}
+
+type ForceReturnInput struct {
+	// BookID is a book ID or the accession number printed on a book's label.
+	BookID  string
+	ActorID string
+	Reason  string
+}
+
+type TransferLoanInput struct {
+	// BookID is a book ID or the accession number printed on a book's label.
+	BookID     string
+	BorrowerID string
+	ActorID    string
+	Reason     *string
+}
+
+type TransferLoanOutput struct {
+	// Returned is the loan that was closed and Borrowed is the loan opened for
+	// the new borrower, which keeps the due date of the closed one.
+	Returned *ReturnBookOutput
+	Borrowed *BorrowBookOutput
+}
+
+// LibrarianLendingService lets a librarian act on loans on behalf of other
+// users. Every operation is recorded as its own event type with the
+// librarian as the actor, so it can be told apart from a borrower's own
+// borrow or return.
+type LibrarianLendingService struct {
+	db             *sql.DB
+	lendingQueries *Queries
+	bookQueries    BookQueries
+	userQueries    UserQueries
+	calendar       calendar.Calendar
+	now            func() time.Time
+}
+
+func NewLibrarianLendingService(db *sql.DB, lendingQueries *Queries, bookQueries BookQueries, userQueries UserQueries, cal calendar.Calendar) *LibrarianLendingService {
+	return &LibrarianLendingService{
+		db:             db,
+		lendingQueries: lendingQueries,
+		bookQueries:    bookQueries,
+		userQueries:    userQueries,
+		calendar:       cal,
+		now:            func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *LibrarianLendingService) LendBook(ctx context.Context, input LendBookInput) (*BorrowBookOutput, error) {
+	now := s.now()
+
+	reason, err := parseOptionalReason(input.Reason)
+	if err != nil {
+		return nil, err
+	}
+	dueDate, _, err := domain.CalculateDueDate(now, input.DueDays, nil, s.calendar)
+	if err != nil {
+		return nil, err
+	}
+	bookID, err := s.findBook(ctx, input.BookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.findUser(ctx, input.BorrowerID); err != nil {
+		return nil, err
+	}
+
+	_, err = s.lendingQueries.GetCurrentLending(ctx, bookID)
+	if err == nil {
+		return nil, ErrBookAlreadyBorrowed
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	lendingID := uuid.New().String()
+	err = s.lendingQueries.InsertLendingEvent(ctx, InsertLendingEventParams{
+		EventID:    uuid.New().String(),
+		LendingID:  lendingID,
+		BookID:     bookID,
+		BorrowerID: input.BorrowerID,
+		EventType:  "lent",
+		DueDate:    sql.NullString{String: dueDate.Format(time.RFC3339), Valid: true},
+		ActorID:    sql.NullString{String: input.ActorID, Valid: true},
+		Reason:     reason,
+		OccurredAt: now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BorrowBookOutput{
+		ID:         lendingID,
+		BookID:     bookID,
+		BorrowerID: input.BorrowerID,
+		BorrowedAt: now,
+		DueDate:    dueDate,
+	}, nil
+}
+
+// ForceReturnBook closes the loan of a book whoever holds it. Unlike a return
+// by the borrower it needs a reason.
+func (s *LibrarianLendingService) ForceReturnBook(ctx context.Context, input ForceReturnInput) (*ReturnBookOutput, error) {
+	now := s.now()
+
+	reason, err := domain.ParseLendingReason(input.Reason)
+	if err != nil {
+		return nil, err
+	}
+	bookID, err := s.findBook(ctx, input.BookID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentLendingRow, err := s.lendingQueries.GetCurrentLending(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookNotBorrowed
+		}
+		return nil, err
+	}
+
+	err = s.lendingQueries.InsertLendingEvent(ctx, InsertLendingEventParams{
+		EventID:    uuid.New().String(),
+		LendingID:  currentLendingRow.LendingID,
+		BookID:     currentLendingRow.BookID,
+		BorrowerID: currentLendingRow.BorrowerID,
+		EventType:  "force_returned",
+		DueDate:    sql.NullString{Valid: false},
+		ActorID:    sql.NullString{String: input.ActorID, Valid: true},
+		Reason:     sql.NullString{String: reason, Valid: true},
+		OccurredAt: now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReturnBookOutput{
+		LendingID:  currentLendingRow.LendingID,
+		BookID:     currentLendingRow.BookID,
+		BorrowerID: currentLendingRow.BorrowerID,
+		ReturnedAt: now,
+	}, nil
+}
+
+// TransferLoan moves the loan of a book to another user. The current loan is
+// closed with a transferred event and a new one is lent to the user in the
+// same transaction.
+func (s *LibrarianLendingService) TransferLoan(ctx context.Context, input TransferLoanInput) (*TransferLoanOutput, error) {
+	now := s.now()
+
+	reason, err := parseOptionalReason(input.Reason)
+	if err != nil {
+		return nil, err
+	}
+	bookID, err := s.findBook(ctx, input.BookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.findUser(ctx, input.BorrowerID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	lendingQueries := s.lendingQueries.WithTx(tx)
+
+	currentLendingRow, err := lendingQueries.GetCurrentLending(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookNotBorrowed
+		}
+		return nil, err
+	}
+	if err := domain.ValidateTransfer(currentLendingRow.BorrowerID, input.BorrowerID); err != nil {
+		return nil, err
+	}
+
+	latestDueDate, err := lendingQueries.GetLatestDueDate(ctx, currentLendingRow.LendingID)
+	if err != nil {
+		return nil, err
+	}
+
+	var latestDueDatePtr *string
+	if latestDueDate.Valid {
+		latestDueDatePtr = &latestDueDate.String
+	}
+
+	var borrowedDueDatePtr *string
+	if currentLendingRow.DueDate.Valid {
+		borrowedDueDatePtr = &currentLendingRow.DueDate.String
+	}
+
+	currentLending, err := domain.ParseCurrentLending(latestDueDatePtr, borrowedDueDatePtr, currentLendingRow.BorrowedAt, s.calendar)
+	if err != nil {
+		return nil, err
+	}
+
+	err = lendingQueries.InsertLendingEvent(ctx, InsertLendingEventParams{
+		EventID:    uuid.New().String(),
+		LendingID:  currentLendingRow.LendingID,
+		BookID:     currentLendingRow.BookID,
+		BorrowerID: currentLendingRow.BorrowerID,
+		EventType:  "transferred",
+		DueDate:    sql.NullString{Valid: false},
+		ActorID:    sql.NullString{String: input.ActorID, Valid: true},
+		Reason:     reason,
+		OccurredAt: now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lendingID := uuid.New().String()
+	err = lendingQueries.InsertLendingEvent(ctx, InsertLendingEventParams{
+		EventID:    uuid.New().String(),
+		LendingID:  lendingID,
+		BookID:     currentLendingRow.BookID,
+		BorrowerID: input.BorrowerID,
+		EventType:  "lent",
+		DueDate:    sql.NullString{String: currentLending.DueDate.Format(time.RFC3339), Valid: true},
+		ActorID:    sql.NullString{String: input.ActorID, Valid: true},
+		Reason:     reason,
+		OccurredAt: now.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &TransferLoanOutput{
+		Returned: &ReturnBookOutput{
+			LendingID:  currentLendingRow.LendingID,
+			BookID:     currentLendingRow.BookID,
+			BorrowerID: currentLendingRow.BorrowerID,
+			ReturnedAt: now,
+		},
+		Borrowed: &BorrowBookOutput{
+			ID:         lendingID,
+			BookID:     currentLendingRow.BookID,
+			BorrowerID: input.BorrowerID,
+			BorrowedAt: now,
+			DueDate:    currentLending.DueDate,
+		},
+	}, nil
+}
+
+func (s *LibrarianLendingService) findBook(ctx context.Context, ref string) (string, error) {
+	bookID, err := resolveBookID(ctx, s.bookQueries, ref)
+	if err != nil {
+		return "", err
+	}
+	count, err := s.bookQueries.CountBookByBookId(ctx, bookID)
+	if err != nil {
+		return "", err
+	}
+	if count == 0 {
+		return "", ErrBookNotFound
+	}
+	return bookID, nil
+}
+
+func (s *LibrarianLendingService) findUser(ctx context.Context, userID string) error {
+	count, err := s.userQueries.CountUserByUserId(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func parseOptionalReason(s *string) (sql.NullString, error) {
+	if s == nil {
+		return sql.NullString{Valid: false}, nil
+	}
+	reason, err := domain.ParseLendingReason(*s)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: reason, Valid: true}, nil
+}
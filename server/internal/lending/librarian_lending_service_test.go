@@ -0,0 +1,276 @@
+//go:build medium
+
+package lending
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"holocron/internal/calendar"
+	"holocron/internal/lending/domain"
+
+	"github.com/google/uuid"
+)
+
+type fakeUserQueries struct {
+	users map[string]bool
+}
+
+func (f *fakeUserQueries) CountUserByUserId(_ context.Context, userID string) (int64, error) {
+	if f.users[userID] {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+type librarianFixture struct {
+	db             *sql.DB
+	lendingQueries *Queries
+	bookID         string
+	borrowerID     string
+	otherUserID    string
+	librarianID    string
+	now            time.Time
+	service        *LibrarianLendingService
+}
+
+func newLibrarianFixture(t *testing.T) *librarianFixture {
+	t.Helper()
+	db := setupTestDB(t)
+	f := &librarianFixture{
+		db:             db,
+		lendingQueries: New(db),
+		bookID:         uuid.New().String(),
+		borrowerID:     uuid.New().String(),
+		otherUserID:    uuid.New().String(),
+		librarianID:    uuid.New().String(),
+		now:            time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+	bookQueries := &fakeBookQueries{
+		countByBookId:    map[string]int64{f.bookID: 1},
+		accessionNumbers: map[string]string{"HC-000001": f.bookID},
+	}
+	userQueries := &fakeUserQueries{
+		users: map[string]bool{f.borrowerID: true, f.otherUserID: true, f.librarianID: true},
+	}
+	f.service = NewLibrarianLendingService(db, f.lendingQueries, bookQueries, userQueries, calendar.Calendar{})
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+// borrow has the borrower check out the book themselves with the default due days.
+func (f *librarianFixture) borrow(t *testing.T) *BorrowBookOutput {
+	t.Helper()
+	service := NewBorrowBookService(f.lendingQueries, f.service.bookQueries, calendar.Calendar{})
+	service.now = func() time.Time { return f.now.AddDate(0, 0, -3) }
+	output, err := service.BorrowBook(context.Background(), BorrowBookInput{BookID: f.bookID, BorrowerID: f.borrowerID})
+	if err != nil {
+		t.Fatalf("precondition failed: %v", err)
+	}
+	return output
+}
+
+type recordedLendingEvent struct {
+	eventType  string
+	borrowerID string
+	actorID    sql.NullString
+	reason     sql.NullString
+}
+
+func (f *librarianFixture) events(t *testing.T, lendingID string) []recordedLendingEvent {
+	t.Helper()
+	rows, err := f.db.Query(`SELECT event_type, borrower_id, actor_id, reason FROM lending_events WHERE lending_id = ? ORDER BY rowid`, lendingID)
+	if err != nil {
+		t.Fatalf("failed to query events: %v", err)
+	}
+	defer rows.Close()
+	var events []recordedLendingEvent
+	for rows.Next() {
+		var e recordedLendingEvent
+		if err := rows.Scan(&e.eventType, &e.borrowerID, &e.actorID, &e.reason); err != nil {
+			t.Fatalf("failed to scan event: %v", err)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestLendBook_WithAvailableBook_LendsToBorrower(t *testing.T) {
+	f := newLibrarianFixture(t)
+	dueDays := 14
+	reason := " 出張中のため "
+
+	output, err := f.service.LendBook(context.Background(), LendBookInput{
+		BookID:     "HC-000001",
+		BorrowerID: f.borrowerID,
+		ActorID:    f.librarianID,
+		DueDays:    &dueDays,
+		Reason:     &reason,
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.BookID != f.bookID || output.BorrowerID != f.borrowerID {
+		t.Errorf("unexpected output %+v", output)
+	}
+	if !output.DueDate.Equal(f.now.AddDate(0, 0, 14)) {
+		t.Errorf("expected DueDate %v, got %v", f.now.AddDate(0, 0, 14), output.DueDate)
+	}
+	current, err := f.lendingQueries.GetCurrentLending(context.Background(), f.bookID)
+	if err != nil {
+		t.Fatalf("postcondition failed: %v", err)
+	}
+	if current.BorrowerID != f.borrowerID {
+		t.Errorf("postcondition failed: expected borrower %s, got %s", f.borrowerID, current.BorrowerID)
+	}
+	events := f.events(t, output.ID)
+	if len(events) != 1 || events[0].eventType != "lent" || events[0].actorID.String != f.librarianID || events[0].reason.String != "出張中のため" {
+		t.Errorf("unexpected events %+v", events)
+	}
+}
+
+func TestLendBook_WithBorrowedBook_ReturnsError(t *testing.T) {
+	f := newLibrarianFixture(t)
+	f.borrow(t)
+
+	_, err := f.service.LendBook(context.Background(), LendBookInput{BookID: f.bookID, BorrowerID: f.otherUserID, ActorID: f.librarianID})
+
+	if !errors.Is(err, ErrBookAlreadyBorrowed) {
+		t.Errorf("expected ErrBookAlreadyBorrowed, got %v", err)
+	}
+}
+
+func TestLendBook_WithUnknownUser_ReturnsError(t *testing.T) {
+	f := newLibrarianFixture(t)
+
+	_, err := f.service.LendBook(context.Background(), LendBookInput{BookID: f.bookID, BorrowerID: uuid.New().String(), ActorID: f.librarianID})
+
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+	if _, err := f.lendingQueries.GetCurrentLending(context.Background(), f.bookID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("postcondition failed: expected no current lending, got %v", err)
+	}
+}
+
+func TestForceReturnBook_WithBorrowedBook_ClosesLoan(t *testing.T) {
+	f := newLibrarianFixture(t)
+	borrowed := f.borrow(t)
+
+	output, err := f.service.ForceReturnBook(context.Background(), ForceReturnInput{
+		BookID:  f.bookID,
+		ActorID: f.librarianID,
+		Reason:  "退職者の机に残っていた",
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.LendingID != borrowed.ID || output.BorrowerID != f.borrowerID {
+		t.Errorf("unexpected output %+v", output)
+	}
+	if _, err := f.lendingQueries.GetCurrentLending(context.Background(), f.bookID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("postcondition failed: expected no current lending, got %v", err)
+	}
+	events := f.events(t, borrowed.ID)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if events[0].actorID.String != f.borrowerID {
+		t.Errorf("expected the borrow to be acted by the borrower, got %+v", events[0])
+	}
+	if events[1].eventType != "force_returned" || events[1].actorID.String != f.librarianID || events[1].reason.String != "退職者の机に残っていた" {
+		t.Errorf("unexpected force return event %+v", events[1])
+	}
+}
+
+func TestForceReturnBook_WithoutReason_ReturnsError(t *testing.T) {
+	f := newLibrarianFixture(t)
+	f.borrow(t)
+
+	_, err := f.service.ForceReturnBook(context.Background(), ForceReturnInput{BookID: f.bookID, ActorID: f.librarianID, Reason: "  "})
+
+	if !errors.Is(err, domain.ErrInvalidReason) {
+		t.Errorf("expected ErrInvalidReason, got %v", err)
+	}
+	if _, err := f.lendingQueries.GetCurrentLending(context.Background(), f.bookID); err != nil {
+		t.Errorf("postcondition failed: expected the loan to stay open, got %v", err)
+	}
+}
+
+func TestForceReturnBook_WithAvailableBook_ReturnsError(t *testing.T) {
+	f := newLibrarianFixture(t)
+
+	_, err := f.service.ForceReturnBook(context.Background(), ForceReturnInput{BookID: f.bookID, ActorID: f.librarianID, Reason: "回収"})
+
+	if !errors.Is(err, ErrBookNotBorrowed) {
+		t.Errorf("expected ErrBookNotBorrowed, got %v", err)
+	}
+}
+
+func TestTransferLoan_WithBorrowedBook_MovesLoanKeepingDueDate(t *testing.T) {
+	f := newLibrarianFixture(t)
+	borrowed := f.borrow(t)
+
+	output, err := f.service.TransferLoan(context.Background(), TransferLoanInput{
+		BookID:     f.bookID,
+		BorrowerID: f.otherUserID,
+		ActorID:    f.librarianID,
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Returned.LendingID != borrowed.ID || output.Returned.BorrowerID != f.borrowerID {
+		t.Errorf("unexpected returned loan %+v", output.Returned)
+	}
+	if output.Borrowed.ID == borrowed.ID || output.Borrowed.BorrowerID != f.otherUserID {
+		t.Errorf("unexpected new loan %+v", output.Borrowed)
+	}
+	if !output.Borrowed.DueDate.Equal(borrowed.DueDate) {
+		t.Errorf("expected DueDate %v, got %v", borrowed.DueDate, output.Borrowed.DueDate)
+	}
+	current, err := f.lendingQueries.GetCurrentLending(context.Background(), f.bookID)
+	if err != nil {
+		t.Fatalf("postcondition failed: %v", err)
+	}
+	if current.LendingID != output.Borrowed.ID || current.BorrowerID != f.otherUserID {
+		t.Errorf("postcondition failed: unexpected current lending %+v", current)
+	}
+	closing := f.events(t, borrowed.ID)
+	if len(closing) != 2 || closing[1].eventType != "transferred" || closing[1].actorID.String != f.librarianID {
+		t.Errorf("unexpected events of the old loan %+v", closing)
+	}
+	opening := f.events(t, output.Borrowed.ID)
+	if len(opening) != 1 || opening[0].eventType != "lent" || opening[0].actorID.String != f.librarianID {
+		t.Errorf("unexpected events of the new loan %+v", opening)
+	}
+}
+
+func TestTransferLoan_WithSameBorrower_ReturnsError(t *testing.T) {
+	f := newLibrarianFixture(t)
+	borrowed := f.borrow(t)
+
+	_, err := f.service.TransferLoan(context.Background(), TransferLoanInput{BookID: f.bookID, BorrowerID: f.borrowerID, ActorID: f.librarianID})
+
+	if !errors.Is(err, domain.ErrSameBorrower) {
+		t.Errorf("expected ErrSameBorrower, got %v", err)
+	}
+	if events := f.events(t, borrowed.ID); len(events) != 1 {
+		t.Errorf("postcondition failed: expected no new event, got %+v", events)
+	}
+}
+
+func TestTransferLoan_WithAvailableBook_ReturnsError(t *testing.T) {
+	f := newLibrarianFixture(t)
+
+	_, err := f.service.TransferLoan(context.Background(), TransferLoanInput{BookID: f.bookID, BorrowerID: f.otherUserID, ActorID: f.librarianID})
+
+	if !errors.Is(err, ErrBookNotBorrowed) {
+		t.Errorf("expected ErrBookNotBorrowed, got %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"time"
 
+	"holocron/internal/calendar"
 	"holocron/internal/lending/domain"
 
 	"github.com/google/uuid"
@@ -17,6 +18,7 @@ var (
 )
 
 type ReturnBookInput struct {
+	// BookID is a book ID or the accession number printed on a book's label.
 	BookID      string
 	RequesterID string
 }
@@ -31,21 +33,37 @@ type ReturnBookOutput struct {
 type ReturnBookService struct {
 	lendingQueries *Queries
 	bookQueries    BookQueries
+	calendar       calendar.Calendar
 	now            func() time.Time
 }
 
-func NewReturnBookService(lendingQueries *Queries, bookQueries BookQueries) *ReturnBookService {
+func NewReturnBookService(lendingQueries *Queries, bookQueries BookQueries, cal calendar.Calendar) *ReturnBookService {
 	return &ReturnBookService{
 		lendingQueries: lendingQueries,
 		bookQueries:    bookQueries,
+		calendar:       cal,
 		now:            func() time.Time { return time.Now().UTC() },
 	}
 }
 
+// withTx returns a copy of the service that runs its queries in tx.
+func (s *ReturnBookService) withTx(tx *sql.Tx, bookQueries BookQueries) *ReturnBookService {
+	return &ReturnBookService{
+		lendingQueries: s.lendingQueries.WithTx(tx),
+		bookQueries:    bookQueries,
+		calendar:       s.calendar,
+		now:            s.now,
+	}
+}
+
 func (s *ReturnBookService) ReturnBook(ctx context.Context, input ReturnBookInput) (*ReturnBookOutput, error) {
 	now := s.now()
 
-	count, err := s.bookQueries.CountBookByBookId(ctx, input.BookID)
+	bookID, err := resolveBookID(ctx, s.bookQueries, input.BookID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.bookQueries.CountBookByBookId(ctx, bookID)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +71,7 @@ func (s *ReturnBookService) ReturnBook(ctx context.Context, input ReturnBookInpu
 		return nil, ErrBookNotFound
 	}
 
-	currentLendingRow, err := s.lendingQueries.GetCurrentLending(ctx, input.BookID)
+	currentLendingRow, err := s.lendingQueries.GetCurrentLending(ctx, bookID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrBookNotBorrowed
@@ -76,7 +94,7 @@ func (s *ReturnBookService) ReturnBook(ctx context.Context, input ReturnBookInpu
 		borrowedDueDatePtr = &currentLendingRow.DueDate.String
 	}
 
-	currentLending, err := domain.ParseCurrentLending(latestDueDatePtr, borrowedDueDatePtr, currentLendingRow.BorrowedAt)
+	currentLending, err := domain.ParseCurrentLending(latestDueDatePtr, borrowedDueDatePtr, currentLendingRow.BorrowedAt, s.calendar)
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +118,7 @@ func (s *ReturnBookService) ReturnBook(ctx context.Context, input ReturnBookInpu
 		BorrowerID: currentLendingRow.BorrowerID,
 		EventType:  "returned",
 		DueDate:    sql.NullString{Valid: false},
+		ActorID:    sql.NullString{String: input.RequesterID, Valid: true},
 		OccurredAt: now.Format(time.RFC3339),
 	})
 	if err != nil {
@@ -10,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"holocron/internal/calendar"
+
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -25,7 +27,7 @@ func TestReturnBook_WithBorrowedBook_ReturnsOutput(t *testing.T) {
 	ctx := context.Background()
 
 	borrowTime := time.Now().Add(-time.Duration(rand.Intn(720)+1) * time.Hour)
-	borrowService := NewBorrowBookService(lendingQueries, bookQueries)
+	borrowService := NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{})
 	borrowService.now = func() time.Time { return borrowTime }
 
 	borrowOutput, err := borrowService.BorrowBook(ctx, BorrowBookInput{
@@ -49,7 +51,7 @@ func TestReturnBook_WithBorrowedBook_ReturnsOutput(t *testing.T) {
 	}
 
 	returnTime := borrowTime.Add(time.Duration(rand.Intn(24)+1) * time.Hour)
-	returnService := NewReturnBookService(lendingQueries, bookQueries)
+	returnService := NewReturnBookService(lendingQueries, bookQueries, calendar.Calendar{})
 	returnService.now = func() time.Time { return returnTime }
 
 	output, err := returnService.ReturnBook(ctx, ReturnBookInput{
@@ -97,7 +99,7 @@ func TestReturnBook_WithNonExistentBook_ReturnsError(t *testing.T) {
 		t.Fatalf("precondition failed: expected book count 0, got %d", count)
 	}
 
-	service := NewReturnBookService(lendingQueries, bookQueries)
+	service := NewReturnBookService(lendingQueries, bookQueries, calendar.Calendar{})
 
 	_, err = service.ReturnBook(ctx, ReturnBookInput{
 		BookID:      nonExistentBookID,
@@ -124,7 +126,7 @@ func TestReturnBook_WithNotBorrowedBook_ReturnsError(t *testing.T) {
 		t.Fatalf("precondition failed: expected no current lending, got error: %v", err)
 	}
 
-	service := NewReturnBookService(lendingQueries, bookQueries)
+	service := NewReturnBookService(lendingQueries, bookQueries, calendar.Calendar{})
 
 	_, err = service.ReturnBook(ctx, ReturnBookInput{
 		BookID:      bookID,
@@ -148,7 +150,7 @@ func TestReturnBook_WithDifferentUser_ReturnsError(t *testing.T) {
 	ctx := context.Background()
 
 	borrowTime := time.Now().Add(-time.Duration(rand.Intn(720)+1) * time.Hour)
-	borrowService := NewBorrowBookService(lendingQueries, bookQueries)
+	borrowService := NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{})
 	borrowService.now = func() time.Time { return borrowTime }
 
 	borrowOutput, err := borrowService.BorrowBook(ctx, BorrowBookInput{
@@ -168,7 +170,7 @@ func TestReturnBook_WithDifferentUser_ReturnsError(t *testing.T) {
 		t.Fatalf("precondition failed: expected borrower %s, got %s", borrower, currentLending.BorrowerID)
 	}
 
-	returnService := NewReturnBookService(lendingQueries, bookQueries)
+	returnService := NewReturnBookService(lendingQueries, bookQueries, calendar.Calendar{})
 
 	_, err = returnService.ReturnBook(ctx, ReturnBookInput{
 		BookID:      bookID,
@@ -187,3 +189,35 @@ func TestReturnBook_WithDifferentUser_ReturnsError(t *testing.T) {
 		t.Errorf("postcondition failed: expected lending ID %s to remain, got %s", borrowOutput.ID, stillBorrowed.LendingID)
 	}
 }
+
+// When ReturnBook with an accession number then returns the numbered book
+func TestReturnBook_WithAccessionNumber_ReturnsNumberedBook(t *testing.T) {
+	db := setupTestDB(t)
+	lendingQueries := New(db)
+	bookID := uuid.New().String()
+	userID := uuid.New().String()
+	bookQueries := &fakeBookQueries{
+		countByBookId:    map[string]int64{bookID: 1},
+		accessionNumbers: map[string]string{"HC-000042": bookID},
+	}
+	ctx := context.Background()
+	if _, err := NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{}).BorrowBook(ctx, BorrowBookInput{BookID: bookID, BorrowerID: userID}); err != nil {
+		t.Fatalf("failed to borrow book: %v", err)
+	}
+
+	output, err := NewReturnBookService(lendingQueries, bookQueries, calendar.Calendar{}).ReturnBook(ctx, ReturnBookInput{
+		BookID:      "HC-000042",
+		RequesterID: userID,
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.BookID != bookID {
+		t.Errorf("expected BookID %s, got %s", bookID, output.BookID)
+	}
+	_, err = lendingQueries.GetCurrentLending(ctx, bookID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("postcondition failed: expected no current lending, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package lending
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"holocron/internal/book"
+	bookDomain "holocron/internal/book/domain"
+	bookcode "holocron/internal/bookcode/domain"
+	"holocron/internal/lending/domain"
+
+	"github.com/google/uuid"
+)
+
+type ScanInput struct {
+	// Code is a scanned ISBN or other book code, an accession number, or the
+	// book ID encoded in a label's QR code.
+	Code        string
+	RequesterID string
+	// DueDays applies when the scan borrows a book.
+	DueDays *int
+}
+
+// ScanOutput is the result of a scan. Exactly one of Borrowed and Returned is
+// set, unless Ambiguity is set, in which case nothing was borrowed or returned.
+type ScanOutput struct {
+	Action     domain.ScanAction
+	Borrowed   *BorrowBookOutput
+	Returned   *ReturnBookOutput
+	Ambiguity  domain.Ambiguity
+	Candidates []domain.ScanCandidate
+}
+
+// ScanService borrows or returns the copy a scanned code refers to, so that a
+// kiosk can handle both with a single scan.
+type ScanService struct {
+	lendingQueries *Queries
+	bookQueries    BookQueries
+	borrow         *BorrowBookService
+	ret            *ReturnBookService
+}
+
+func NewScanService(lendingQueries *Queries, bookQueries BookQueries, borrow *BorrowBookService, ret *ReturnBookService) *ScanService {
+	return &ScanService{
+		lendingQueries: lendingQueries,
+		bookQueries:    bookQueries,
+		borrow:         borrow,
+		ret:            ret,
+	}
+}
+
+func (s *ScanService) Scan(ctx context.Context, input ScanInput) (*ScanOutput, error) {
+	code, err := domain.ParseScanCode(input.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.findCandidates(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, ErrBookNotFound
+	}
+
+	decision := domain.ResolveScan(candidates, input.RequesterID)
+	if decision.Ambiguity != "" {
+		return &ScanOutput{Ambiguity: decision.Ambiguity, Candidates: decision.Candidates}, nil
+	}
+
+	if decision.Action == domain.ScanActionReturn {
+		returned, err := s.ret.ReturnBook(ctx, ReturnBookInput{
+			BookID:      decision.BookID,
+			RequesterID: input.RequesterID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &ScanOutput{Action: decision.Action, Returned: returned}, nil
+	}
+
+	borrowed, err := s.borrow.BorrowBook(ctx, BorrowBookInput{
+		BookID:     decision.BookID,
+		BorrowerID: input.RequesterID,
+		DueDays:    input.DueDays,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ScanOutput{Action: decision.Action, Borrowed: borrowed}, nil
+}
+
+// findCandidates returns the copies a code may refer to. An accession number or
+// a book ID identifies a single copy; any other code is looked up as a book code,
+// which several copies of a title share.
+func (s *ScanService) findCandidates(ctx context.Context, code string) ([]domain.ScanCandidate, error) {
+	var candidates []domain.ScanCandidate
+	if _, err := bookDomain.ParseAccessionNumber(code); err == nil {
+		bookID, err := resolveBookID(ctx, s.bookQueries, code)
+		if errors.Is(err, ErrBookNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, domain.ScanCandidate{BookID: bookID})
+	} else if _, err := uuid.Parse(code); err == nil {
+		count, err := s.bookQueries.CountBookByBookId(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			candidates = append(candidates, domain.ScanCandidate{BookID: code})
+		}
+	} else {
+		normalized := code
+		if n, err := bookcode.NormalizeCode(code); err == nil {
+			normalized = n
+		}
+		rows, err := s.bookQueries.ListBooksByCode(ctx, book.ListBooksByCodeParams{
+			Code:           sql.NullString{String: code, Valid: true},
+			NormalizedCode: sql.NullString{String: normalized, Valid: true},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			candidates = append(candidates, domain.ScanCandidate{BookID: row.BookID, Title: row.Title.String})
+		}
+	}
+
+	for i, c := range candidates {
+		lending, err := s.lendingQueries.GetCurrentLending(ctx, c.BookID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		candidates[i].BorrowerID = lending.BorrowerID
+	}
+	return candidates, nil
+}
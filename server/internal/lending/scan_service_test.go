@@ -0,0 +1,179 @@
+//go:build medium
+
+package lending
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"holocron/internal/book"
+	"holocron/internal/calendar"
+	"holocron/internal/lending/domain"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const scanTestISBN = "9784873119045"
+
+func newScanTestService(t *testing.T, bookQueries *fakeBookQueries) *ScanService {
+	t.Helper()
+	lendingQueries := New(setupTestDB(t))
+	return NewScanService(
+		lendingQueries,
+		bookQueries,
+		NewBorrowBookService(lendingQueries, bookQueries, calendar.Calendar{}),
+		NewReturnBookService(lendingQueries, bookQueries, calendar.Calendar{}),
+	)
+}
+
+func copiesOf(title string, bookIDs ...string) []book.ListBooksByCodeRow {
+	rows := make([]book.ListBooksByCodeRow, 0, len(bookIDs))
+	for _, bookID := range bookIDs {
+		rows = append(rows, book.ListBooksByCodeRow{BookID: bookID, Title: sql.NullString{String: title, Valid: true}})
+	}
+	return rows
+}
+
+func TestScan_WithAvailableBook_BorrowsThenReturns(t *testing.T) {
+	bookID := uuid.New().String()
+	userID := uuid.New().String()
+	service := newScanTestService(t, &fakeBookQueries{
+		countByBookId: map[string]int64{bookID: 1},
+		codes:         map[string][]book.ListBooksByCodeRow{scanTestISBN: copiesOf("Title", bookID)},
+	})
+	ctx := context.Background()
+
+	borrowed, err := service.Scan(ctx, ScanInput{Code: "978-4-87311-904-5", RequesterID: userID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if borrowed.Action != domain.ScanActionBorrow || borrowed.Borrowed == nil || borrowed.Borrowed.BookID != bookID {
+		t.Fatalf("expected the book to be borrowed, got %+v", borrowed)
+	}
+
+	returned, err := service.Scan(ctx, ScanInput{Code: scanTestISBN, RequesterID: userID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if returned.Action != domain.ScanActionReturn || returned.Returned == nil || returned.Returned.LendingID != borrowed.Borrowed.ID {
+		t.Errorf("expected the loan to be returned, got %+v", returned)
+	}
+}
+
+func TestScan_WithAccessionNumberAndBookID_ResolvesSingleCopy(t *testing.T) {
+	numbered := uuid.New().String()
+	other := uuid.New().String()
+	userID := uuid.New().String()
+	service := newScanTestService(t, &fakeBookQueries{
+		countByBookId:    map[string]int64{numbered: 1, other: 1},
+		accessionNumbers: map[string]string{"HC-000001": numbered},
+	})
+	ctx := context.Background()
+
+	output, err := service.Scan(ctx, ScanInput{Code: "hc-000001", RequesterID: userID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Borrowed == nil || output.Borrowed.BookID != numbered {
+		t.Errorf("expected %s to be borrowed, got %+v", numbered, output)
+	}
+
+	output, err = service.Scan(ctx, ScanInput{Code: other, RequesterID: userID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Borrowed == nil || output.Borrowed.BookID != other {
+		t.Errorf("expected %s to be borrowed, got %+v", other, output)
+	}
+}
+
+func TestScan_WithSeveralAvailableCopies_ReportsAmbiguity(t *testing.T) {
+	first := uuid.New().String()
+	second := uuid.New().String()
+	lent := uuid.New().String()
+	service := newScanTestService(t, &fakeBookQueries{
+		countByBookId: map[string]int64{first: 1, second: 1, lent: 1},
+		codes:         map[string][]book.ListBooksByCodeRow{scanTestISBN: copiesOf("Title", first, second, lent)},
+	})
+	ctx := context.Background()
+	if _, err := service.borrow.BorrowBook(ctx, BorrowBookInput{BookID: lent, BorrowerID: uuid.New().String()}); err != nil {
+		t.Fatalf("failed to borrow book: %v", err)
+	}
+
+	output, err := service.Scan(ctx, ScanInput{Code: scanTestISBN, RequesterID: uuid.New().String()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Ambiguity != domain.AmbiguityMultipleCopies || output.Action != "" || len(output.Candidates) != 2 {
+		t.Errorf("expected two available copies to be reported, got %+v", output)
+	}
+	for _, bookID := range []string{first, second} {
+		if _, err := service.lendingQueries.GetCurrentLending(ctx, bookID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected %s not to be borrowed, got %v", bookID, err)
+		}
+	}
+}
+
+func TestScan_WithOneCopyHeldAmongSeveral_ReturnsHeldCopy(t *testing.T) {
+	first := uuid.New().String()
+	second := uuid.New().String()
+	userID := uuid.New().String()
+	service := newScanTestService(t, &fakeBookQueries{
+		countByBookId: map[string]int64{first: 1, second: 1},
+		codes:         map[string][]book.ListBooksByCodeRow{scanTestISBN: copiesOf("Title", first, second)},
+	})
+	ctx := context.Background()
+	if _, err := service.borrow.BorrowBook(ctx, BorrowBookInput{BookID: second, BorrowerID: userID}); err != nil {
+		t.Fatalf("failed to borrow book: %v", err)
+	}
+
+	output, err := service.Scan(ctx, ScanInput{Code: scanTestISBN, RequesterID: userID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Returned == nil || output.Returned.BookID != second {
+		t.Errorf("expected %s to be returned, got %+v", second, output)
+	}
+}
+
+func TestScan_WithBookLentToAnotherUser_ReturnsAlreadyBorrowedError(t *testing.T) {
+	bookID := uuid.New().String()
+	service := newScanTestService(t, &fakeBookQueries{
+		countByBookId: map[string]int64{bookID: 1},
+		codes:         map[string][]book.ListBooksByCodeRow{scanTestISBN: copiesOf("Title", bookID)},
+	})
+	ctx := context.Background()
+	if _, err := service.borrow.BorrowBook(ctx, BorrowBookInput{BookID: bookID, BorrowerID: uuid.New().String()}); err != nil {
+		t.Fatalf("failed to borrow book: %v", err)
+	}
+
+	_, err := service.Scan(ctx, ScanInput{Code: scanTestISBN, RequesterID: uuid.New().String()})
+
+	if !errors.Is(err, ErrBookAlreadyBorrowed) {
+		t.Errorf("expected ErrBookAlreadyBorrowed, got %v", err)
+	}
+}
+
+func TestScan_WithUnknownCode_ReturnsNotFoundError(t *testing.T) {
+	service := newScanTestService(t, &fakeBookQueries{})
+
+	for _, code := range []string{scanTestISBN, "HC-000009", uuid.New().String()} {
+		_, err := service.Scan(context.Background(), ScanInput{Code: code, RequesterID: uuid.New().String()})
+		if !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("expected ErrBookNotFound for %s, got %v", code, err)
+		}
+	}
+}
+
+func TestScan_WithBlankCode_ReturnsInvalidCodeError(t *testing.T) {
+	service := newScanTestService(t, &fakeBookQueries{})
+
+	_, err := service.Scan(context.Background(), ScanInput{Code: "  ", RequesterID: uuid.New().String()})
+
+	if !errors.Is(err, domain.ErrInvalidScanCode) {
+		t.Errorf("expected ErrInvalidScanCode, got %v", err)
+	}
+}
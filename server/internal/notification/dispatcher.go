@@ -0,0 +1,214 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"holocron/internal/notification/domain"
+	"holocron/internal/projection"
+
+	"github.com/google/uuid"
+)
+
+// Dispatcher runs the producers and stores the notifications they create.
+// It follows lending_events and book_events like a read model, tracking the
+// rowid of the last event of each it has handed to the producers.
+type Dispatcher struct {
+	db                 *sql.DB
+	queries            *Queries
+	lendingEvents      projection.ReadModel
+	bookEvents         projection.ReadModel
+	scheduledProducers []ScheduledProducer
+	now                func() time.Time
+	mu                 sync.Mutex
+}
+
+func NewDispatcher(db *sql.DB, producers ...Producer) *Dispatcher {
+	d := &Dispatcher{
+		db:      db,
+		queries: New(db),
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+	var lendingProducers []LendingEventProducer
+	var bookProducers []BookEventProducer
+	for _, p := range producers {
+		if p, ok := p.(LendingEventProducer); ok {
+			lendingProducers = append(lendingProducers, p)
+		}
+		if p, ok := p.(BookEventProducer); ok {
+			bookProducers = append(bookProducers, p)
+		}
+		if p, ok := p.(ScheduledProducer); ok {
+			d.scheduledProducers = append(d.scheduledProducers, p)
+		}
+	}
+	d.lendingEvents = lendingEventStream{eventStream: eventStream{dispatcher: d, name: "lending_events"}, producers: lendingProducers}
+	d.bookEvents = bookEventStream{eventStream: eventStream{dispatcher: d, name: "book_events"}, producers: bookProducers}
+	return d
+}
+
+// Dispatch runs the producers on the events recorded since the last dispatch
+// and stores their notifications, skipping the types each user opted out of.
+// The first dispatch starts from the current events, so that history recorded
+// before notifications existed is not announced.
+func (d *Dispatcher) Dispatch(ctx context.Context) error {
+	return d.run(ctx, false)
+}
+
+// Rebuild marks every event as dispatched without producing notifications.
+// It runs after a restore, as the restored events are not new to anyone.
+func (d *Dispatcher) Rebuild(ctx context.Context) error {
+	return d.run(ctx, true)
+}
+
+func (d *Dispatcher) run(ctx context.Context, rebuild bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := projection.Advance(ctx, tx, d.lendingEvents, rebuild); err != nil {
+		return err
+	}
+	if err := projection.Advance(ctx, tx, d.bookEvents, rebuild); err != nil {
+		return err
+	}
+	if !rebuild {
+		qtx := d.queries.WithTx(tx)
+		now := d.now()
+		for _, p := range d.scheduledProducers {
+			drafts, err := p.ProduceAt(ctx, qtx, now)
+			if err != nil {
+				return err
+			}
+			if err := insertDrafts(ctx, qtx, drafts, now); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// eventStream is the part of a read model the lending and book event
+// streams share. Resetting skips to the latest event instead of producing
+// notifications for every event again.
+type eventStream struct {
+	dispatcher *Dispatcher
+	name       string
+}
+
+func (s eventStream) Position(ctx context.Context, tx *sql.Tx) (int64, error) {
+	return s.dispatcher.queries.WithTx(tx).GetNotificationPosition(ctx, s.name)
+}
+
+func (s eventStream) SetPosition(ctx context.Context, tx *sql.Tx, rowid int64) error {
+	return s.dispatcher.queries.WithTx(tx).SetNotificationPosition(ctx, SetNotificationPositionParams{
+		Stream:    s.name,
+		LastRowid: rowid,
+	})
+}
+
+func (s eventStream) Reset(ctx context.Context, tx *sql.Tx, latest int64) (int64, error) {
+	return latest, nil
+}
+
+type lendingEventStream struct {
+	eventStream
+	producers []LendingEventProducer
+}
+
+func (s lendingEventStream) LatestRowid(ctx context.Context, tx *sql.Tx) (int64, error) {
+	return s.dispatcher.queries.WithTx(tx).GetLatestLendingEventRowid(ctx)
+}
+
+func (s lendingEventStream) Apply(ctx context.Context, tx *sql.Tx, afterRowid, untilRowid int64) error {
+	if len(s.producers) == 0 {
+		return nil
+	}
+	qtx := s.dispatcher.queries.WithTx(tx)
+	events, err := qtx.ListLendingEventsBetween(ctx, ListLendingEventsBetweenParams{
+		AfterRowid: afterRowid,
+		UntilRowid: untilRowid,
+	})
+	if err != nil {
+		return err
+	}
+	now := s.dispatcher.now()
+	for _, event := range events {
+		for _, p := range s.producers {
+			drafts, err := p.ProduceForLendingEvent(ctx, qtx, event)
+			if err != nil {
+				return err
+			}
+			if err := insertDrafts(ctx, qtx, drafts, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type bookEventStream struct {
+	eventStream
+	producers []BookEventProducer
+}
+
+func (s bookEventStream) LatestRowid(ctx context.Context, tx *sql.Tx) (int64, error) {
+	return s.dispatcher.queries.WithTx(tx).GetLatestBookEventRowid(ctx)
+}
+
+func (s bookEventStream) Apply(ctx context.Context, tx *sql.Tx, afterRowid, untilRowid int64) error {
+	if len(s.producers) == 0 {
+		return nil
+	}
+	qtx := s.dispatcher.queries.WithTx(tx)
+	events, err := qtx.ListBookEventsBetween(ctx, ListBookEventsBetweenParams{
+		AfterRowid: afterRowid,
+		UntilRowid: untilRowid,
+	})
+	if err != nil {
+		return err
+	}
+	now := s.dispatcher.now()
+	for _, event := range events {
+		for _, p := range s.producers {
+			drafts, err := p.ProduceForBookEvent(ctx, qtx, event)
+			if err != nil {
+				return err
+			}
+			if err := insertDrafts(ctx, qtx, drafts, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func insertDrafts(ctx context.Context, qtx *Queries, drafts []domain.Draft, now time.Time) error {
+	for _, draft := range drafts {
+		bookID := sql.NullString{}
+		if draft.BookID != nil {
+			bookID = sql.NullString{String: *draft.BookID, Valid: true}
+		}
+		err := qtx.InsertNotification(ctx, InsertNotificationParams{
+			NotificationID: uuid.New().String(),
+			UserID:         draft.UserID,
+			Type:           string(draft.Type),
+			Title:          draft.Title,
+			Body:           draft.Body,
+			BookID:         bookID,
+			DedupeKey:      draft.DedupeKey,
+			CreatedAt:      now.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
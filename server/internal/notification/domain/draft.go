@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"holocron/internal/calendar"
+)
+
+// Draft is a notification a producer wants to deliver. Drafts with the same
+// dedupe key are delivered once, so producers may see an event or a loan
+// again without notifying twice.
+type Draft struct {
+	UserID    string
+	Type      NotificationType
+	Title     string
+	Body      string
+	BookID    *string
+	DedupeKey string
+}
+
+// DueSoonDraft reminds the borrower of a loan's due date. The due date is
+// part of the key, so an extended loan is reminded of again.
+func DueSoonDraft(userID, lendingID, bookID, bookTitle string, dueDate time.Time) Draft {
+	return Draft{
+		UserID:    userID,
+		Type:      TypeDueSoon,
+		Title:     "返却期限が近づいています",
+		Body:      fmt.Sprintf("「%s」の返却期限は%sです。", bookTitle, dueDate.In(calendar.Location).Format("2006-01-02 15:04")),
+		BookID:    &bookID,
+		DedupeKey: fmt.Sprintf("%s:%s:%s", TypeDueSoon, lendingID, dueDate.UTC().Format(time.RFC3339)),
+	}
+}
+
+// LibrarianActionDraft tells the borrower of a lending event recorded by
+// someone else. It returns false for events the borrower recorded
+// themselves and for events that are not librarian actions.
+func LibrarianActionDraft(eventID, eventType, borrowerID, actorID, bookID, bookTitle, reason string) (Draft, bool) {
+	if actorID == "" || actorID == borrowerID {
+		return Draft{}, false
+	}
+	var title, body string
+	switch eventType {
+	case "lent":
+		title = "本が貸し出されました"
+		body = fmt.Sprintf("司書が「%s」をあなたに貸し出しました。", bookTitle)
+	case "force_returned":
+		title = "本が返却されました"
+		body = fmt.Sprintf("司書が「%s」を返却済みにしました。", bookTitle)
+	case "transferred":
+		title = "貸出が移されました"
+		body = fmt.Sprintf("司書が「%s」の貸出を別の利用者に移しました。", bookTitle)
+	case "lost":
+		title = "紛失が登録されました"
+		body = fmt.Sprintf("「%s」が紛失として登録されました。", bookTitle)
+	default:
+		return Draft{}, false
+	}
+	if reason != "" {
+		body += "理由: " + reason
+	}
+	return Draft{
+		UserID:    borrowerID,
+		Type:      TypeLibrarianAction,
+		Title:     title,
+		Body:      body,
+		BookID:    &bookID,
+		DedupeKey: fmt.Sprintf("%s:%s", TypeLibrarianAction, eventID),
+	}, true
+}
+
+// SeriesVolumeDraft tells a reader of a series that a book joined it.
+func SeriesVolumeDraft(userID, bookID, bookTitle, seriesID, seriesTitle string) Draft {
+	return Draft{
+		UserID:    userID,
+		Type:      TypeSeriesVolumeAdded,
+		Title:     "シリーズに本が追加されました",
+		Body:      fmt.Sprintf("「%s」に「%s」が追加されました。", seriesTitle, bookTitle),
+		BookID:    &bookID,
+		DedupeKey: fmt.Sprintf("%s:%s:%s:%s", TypeSeriesVolumeAdded, seriesID, bookID, userID),
+	}
+}
@@ -0,0 +1,88 @@
+//go:build small
+
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDueSoonDraft_ShowsDueDateInJapan(t *testing.T) {
+	got := DueSoonDraft("user-1", "lending-1", "book-1", "Go言語", time.Date(2024, 1, 22, 16, 0, 0, 0, time.UTC))
+
+	if got.UserID != "user-1" || got.Type != TypeDueSoon {
+		t.Errorf("unexpected draft: %+v", got)
+	}
+	if want := "「Go言語」の返却期限は2024-01-23 01:00です。"; got.Body != want {
+		t.Errorf("expected %q, got %q", want, got.Body)
+	}
+	if got.BookID == nil || *got.BookID != "book-1" {
+		t.Errorf("expected book-1, got %v", got.BookID)
+	}
+}
+
+func TestDueSoonDraft_WithExtendedDueDate_ChangesDedupeKey(t *testing.T) {
+	due := time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC)
+
+	first := DueSoonDraft("user-1", "lending-1", "book-1", "t", due)
+	again := DueSoonDraft("user-1", "lending-1", "book-1", "t", due)
+	extended := DueSoonDraft("user-1", "lending-1", "book-1", "t", due.AddDate(0, 0, 7))
+
+	if first.DedupeKey != again.DedupeKey {
+		t.Errorf("expected the same key for the same due date, got %q and %q", first.DedupeKey, again.DedupeKey)
+	}
+	if first.DedupeKey == extended.DedupeKey {
+		t.Errorf("expected a new key for the extended due date, got %q", extended.DedupeKey)
+	}
+}
+
+func TestLibrarianActionDraft_WithLibrarianEvent_NotifiesBorrower(t *testing.T) {
+	for _, eventType := range []string{"lent", "force_returned", "transferred", "lost"} {
+		got, ok := LibrarianActionDraft("event-1", eventType, "borrower", "librarian", "book-1", "t", "")
+		if !ok {
+			t.Errorf("%s: expected a draft", eventType)
+			continue
+		}
+		if got.UserID != "borrower" || got.Type != TypeLibrarianAction || got.DedupeKey != "librarian_action:event-1" {
+			t.Errorf("%s: unexpected draft: %+v", eventType, got)
+		}
+	}
+}
+
+func TestLibrarianActionDraft_WithReason_AppendsReason(t *testing.T) {
+	got, _ := LibrarianActionDraft("event-1", "force_returned", "borrower", "librarian", "book-1", "Go言語", "棚卸しで発見")
+
+	if want := "司書が「Go言語」を返却済みにしました。理由: 棚卸しで発見"; got.Body != want {
+		t.Errorf("expected %q, got %q", want, got.Body)
+	}
+}
+
+func TestLibrarianActionDraft_WithOwnOrOtherEvent_ReturnsFalse(t *testing.T) {
+	cases := []struct {
+		name      string
+		eventType string
+		actorID   string
+	}{
+		{"borrowed by the user", "borrowed", ""},
+		{"reported lost by the borrower", "lost", "borrower"},
+		{"returned", "returned", "librarian"},
+		{"extended", "due_date_extended", "librarian"},
+	}
+	for _, tc := range cases {
+		if _, ok := LibrarianActionDraft("event-1", tc.eventType, "borrower", tc.actorID, "book-1", "t", ""); ok {
+			t.Errorf("%s: expected no draft", tc.name)
+		}
+	}
+}
+
+func TestSeriesVolumeDraft_KeysByReader(t *testing.T) {
+	a := SeriesVolumeDraft("user-a", "book-1", "3巻", "series-1", "シリーズ")
+	b := SeriesVolumeDraft("user-b", "book-1", "3巻", "series-1", "シリーズ")
+
+	if a.DedupeKey == b.DedupeKey {
+		t.Errorf("expected a key per reader, got %q", a.DedupeKey)
+	}
+	if want := "「シリーズ」に「3巻」が追加されました。"; a.Body != want {
+		t.Errorf("expected %q, got %q", want, a.Body)
+	}
+}
@@ -0,0 +1,67 @@
+package domain
+
+import "errors"
+
+var ErrInvalidNotificationType = errors.New("unknown notification type")
+
+type NotificationType string
+
+const (
+	// TypeDueSoon reminds the borrower of a loan due within a day.
+	TypeDueSoon NotificationType = "due_soon"
+	// TypeLibrarianAction tells the borrower that a librarian acted on their
+	// loan, such as lending a book to them or returning it on their behalf.
+	TypeLibrarianAction NotificationType = "librarian_action"
+	// TypeSeriesVolumeAdded tells the readers of a series that another book
+	// was added to it.
+	TypeSeriesVolumeAdded NotificationType = "series_volume_added"
+)
+
+// NotificationTypes lists every type in the order preferences are shown.
+var NotificationTypes = []NotificationType{
+	TypeDueSoon,
+	TypeLibrarianAction,
+	TypeSeriesVolumeAdded,
+}
+
+func ParseNotificationType(s string) (NotificationType, error) {
+	for _, t := range NotificationTypes {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return "", ErrInvalidNotificationType
+}
+
+// Preference tells whether a user receives notifications of a type.
+type Preference struct {
+	Type    NotificationType
+	Enabled bool
+}
+
+// Preferences returns a preference for every type. Types are enabled unless
+// the user opted out of them, so types added later reach everyone.
+func Preferences(optOuts []string) []Preference {
+	disabled := make(map[string]bool, len(optOuts))
+	for _, t := range optOuts {
+		disabled[t] = true
+	}
+	preferences := make([]Preference, 0, len(NotificationTypes))
+	for _, t := range NotificationTypes {
+		preferences = append(preferences, Preference{Type: t, Enabled: !disabled[string(t)]})
+	}
+	return preferences
+}
+
+// ToPage reads limit and offset the way the book list does: a limit out of 1
+// to 100 falls back to 20 and a negative offset to 0.
+func ToPage(limit, offset *int) (int, int) {
+	l, o := 20, 0
+	if limit != nil && *limit > 0 && *limit <= 100 {
+		l = *limit
+	}
+	if offset != nil && *offset >= 0 {
+		o = *offset
+	}
+	return l, o
+}
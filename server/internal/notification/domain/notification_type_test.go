@@ -0,0 +1,57 @@
+//go:build small
+
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseNotificationType_WithKnownType_ReturnsType(t *testing.T) {
+	for _, want := range NotificationTypes {
+		got, err := ParseNotificationType(string(want))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", want, err)
+		}
+		if got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	}
+}
+
+func TestParseNotificationType_WithUnknownType_ReturnsError(t *testing.T) {
+	for _, s := range []string{"", "hold_ready", "DUE_SOON"} {
+		if _, err := ParseNotificationType(s); !errors.Is(err, ErrInvalidNotificationType) {
+			t.Errorf("%q: expected ErrInvalidNotificationType, got %v", s, err)
+		}
+	}
+}
+
+func TestPreferences_WithOptOuts_DisablesOnlyThoseTypes(t *testing.T) {
+	got := Preferences([]string{string(TypeDueSoon), "removed_type"})
+
+	want := []Preference{
+		{Type: TypeDueSoon, Enabled: false},
+		{Type: TypeLibrarianAction, Enabled: true},
+		{Type: TypeSeriesVolumeAdded, Enabled: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want[i], got[i])
+		}
+	}
+}
+
+func TestToPage_WithOutOfRangeValues_ReturnsDefaults(t *testing.T) {
+	zero, over, negative := 0, 101, -1
+
+	if l, o := ToPage(&zero, &negative); l != 20 || o != 0 {
+		t.Errorf("expected (20, 0), got (%d, %d)", l, o)
+	}
+	if l, _ := ToPage(&over, nil); l != 20 {
+		t.Errorf("expected 20, got %d", l)
+	}
+}
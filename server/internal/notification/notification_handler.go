@@ -0,0 +1,234 @@
+package notification
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"holocron/internal/api"
+	"holocron/internal/auth"
+	"holocron/internal/notification/domain"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type ListNotificationsHandler struct {
+	service *NotificationService
+}
+
+func NewListNotificationsHandler(service *NotificationService) *ListNotificationsHandler {
+	return &ListNotificationsHandler{service: service}
+}
+
+func (h *ListNotificationsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.GetUsersMeNotificationsParams) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	output, err := h.service.List(r.Context(), ListNotificationsInput{
+		UserID:     userID,
+		UnreadOnly: params.Unread != nil && *params.Unread,
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+
+	items := make([]map[string]any, 0, len(output.Items))
+	for _, item := range output.Items {
+		response := map[string]any{
+			"id":        item.NotificationID,
+			"type":      item.Type,
+			"title":     item.Title,
+			"body":      item.Body,
+			"createdAt": item.CreatedAt,
+		}
+		if item.BookID != nil {
+			response["bookId"] = *item.BookID
+		}
+		if item.ReadAt != nil {
+			response["readAt"] = *item.ReadAt
+		}
+		items = append(items, response)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"items":       items,
+		"total":       output.Total,
+		"unreadCount": output.UnreadCount,
+		"limit":       output.Limit,
+		"offset":      output.Offset,
+	})
+}
+
+type MarkNotificationReadHandler struct {
+	service *NotificationService
+}
+
+func NewMarkNotificationReadHandler(service *NotificationService) *MarkNotificationReadHandler {
+	return &MarkNotificationReadHandler{service: service}
+}
+
+func (h *MarkNotificationReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, notificationId openapi_types.UUID) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	if err := h.service.MarkRead(r.Context(), userID, notificationId.String()); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type MarkAllNotificationsReadHandler struct {
+	service *NotificationService
+}
+
+func NewMarkAllNotificationsReadHandler(service *NotificationService) *MarkAllNotificationsReadHandler {
+	return &MarkAllNotificationsReadHandler{service: service}
+}
+
+func (h *MarkAllNotificationsReadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	if err := h.service.MarkAllRead(r.Context(), userID); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type DeleteNotificationHandler struct {
+	service *NotificationService
+}
+
+func NewDeleteNotificationHandler(service *NotificationService) *DeleteNotificationHandler {
+	return &DeleteNotificationHandler{service: service}
+}
+
+func (h *DeleteNotificationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, notificationId openapi_types.UUID) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), userID, notificationId.String()); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type GetNotificationPreferencesHandler struct {
+	service *NotificationService
+}
+
+func NewGetNotificationPreferencesHandler(service *NotificationService) *GetNotificationPreferencesHandler {
+	return &GetNotificationPreferencesHandler{service: service}
+}
+
+func (h *GetNotificationPreferencesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	preferences, err := h.service.Preferences(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	writePreferences(w, preferences)
+}
+
+type UpdateNotificationPreferencesHandler struct {
+	service *NotificationService
+}
+
+func NewUpdateNotificationPreferencesHandler(service *NotificationService) *UpdateNotificationPreferencesHandler {
+	return &UpdateNotificationPreferencesHandler{service: service}
+}
+
+func (h *UpdateNotificationPreferencesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	var req struct {
+		Preferences *[]struct {
+			Type    string `json:"type"`
+			Enabled *bool  `json:"enabled"`
+		} `json:"preferences"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Preferences == nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "invalid request body")
+		return
+	}
+	changes := make([]domain.Preference, 0, len(*req.Preferences))
+	for _, p := range *req.Preferences {
+		notificationType, err := domain.ParseNotificationType(p.Type)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "unknown notification type: "+p.Type)
+			return
+		}
+		if p.Enabled == nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "enabled is required")
+			return
+		}
+		changes = append(changes, domain.Preference{Type: notificationType, Enabled: *p.Enabled})
+	}
+
+	preferences, err := h.service.UpdatePreferences(r.Context(), userID, changes)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		return
+	}
+	writePreferences(w, preferences)
+}
+
+func writePreferences(w http.ResponseWriter, preferences []domain.Preference) {
+	items := make([]map[string]any, 0, len(preferences))
+	for _, p := range preferences {
+		items = append(items, map[string]any{
+			"type":    string(p.Type),
+			"enabled": p.Enabled,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+}
+
+func writeNotificationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNotificationNotFound) {
+		writeError(w, http.StatusNotFound, "not_found", "notification not found")
+		return
+	}
+	writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,186 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"holocron/internal/notification/domain"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// InboxItem is a notification as the user sees it in their inbox.
+type InboxItem struct {
+	NotificationID string
+	Type           string
+	Title          string
+	Body           string
+	BookID         *string
+	CreatedAt      string
+	ReadAt         *string
+}
+
+type ListNotificationsInput struct {
+	UserID     string
+	UnreadOnly bool
+	Limit      *int
+	Offset     *int
+}
+
+type ListNotificationsOutput struct {
+	Items       []InboxItem
+	Total       int64
+	UnreadCount int64
+	Limit       int
+	Offset      int
+}
+
+// NotificationService manages the inbox of each user, which the dispatcher
+// fills from the producers.
+type NotificationService struct {
+	queries    *Queries
+	dispatcher *Dispatcher
+	now        func() time.Time
+}
+
+func NewNotificationService(queries *Queries, dispatcher *Dispatcher) *NotificationService {
+	return &NotificationService{
+		queries:    queries,
+		dispatcher: dispatcher,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// List returns the user's notifications, newest first. It dispatches first
+// so the inbox shows the events recorded since the last scheduled dispatch.
+func (s *NotificationService) List(ctx context.Context, input ListNotificationsInput) (*ListNotificationsOutput, error) {
+	if err := s.dispatcher.Dispatch(ctx); err != nil {
+		return nil, err
+	}
+	limit, offset := domain.ToPage(input.Limit, input.Offset)
+	filter := "all"
+	if input.UnreadOnly {
+		filter = "unread"
+	}
+
+	total, err := s.queries.CountNotifications(ctx, CountNotificationsParams{UserID: input.UserID, Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	unreadCount, err := s.queries.CountNotifications(ctx, CountNotificationsParams{UserID: input.UserID, Filter: "unread"})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListNotifications(ctx, ListNotificationsParams{
+		UserID: input.UserID,
+		Filter: filter,
+		Limit:  int64(limit),
+		Offset: int64(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]InboxItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, InboxItem{
+			NotificationID: row.NotificationID,
+			Type:           row.Type,
+			Title:          row.Title,
+			Body:           row.Body,
+			BookID:         nullStringPtr(row.BookID),
+			CreatedAt:      row.CreatedAt,
+			ReadAt:         nullStringPtr(row.ReadAt),
+		})
+	}
+	return &ListNotificationsOutput{
+		Items:       items,
+		Total:       total,
+		UnreadCount: unreadCount,
+		Limit:       limit,
+		Offset:      offset,
+	}, nil
+}
+
+// MarkRead marks a notification of the user as read. Marking it again keeps
+// the time it was first read.
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID string) error {
+	if err := s.findOwn(ctx, userID, notificationID); err != nil {
+		return err
+	}
+	return s.queries.MarkNotificationRead(ctx, MarkNotificationReadParams{
+		ReadAt:         sql.NullString{String: s.now().Format(time.RFC3339), Valid: true},
+		NotificationID: notificationID,
+	})
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) error {
+	return s.queries.MarkAllNotificationsRead(ctx, MarkAllNotificationsReadParams{
+		ReadAt: sql.NullString{String: s.now().Format(time.RFC3339), Valid: true},
+		UserID: userID,
+	})
+}
+
+// Delete removes a notification from the user's inbox. The notification is
+// kept hidden rather than removed so that its producer does not create it
+// again.
+func (s *NotificationService) Delete(ctx context.Context, userID, notificationID string) error {
+	if err := s.findOwn(ctx, userID, notificationID); err != nil {
+		return err
+	}
+	return s.queries.DeleteNotification(ctx, DeleteNotificationParams{
+		DeletedAt:      sql.NullString{String: s.now().Format(time.RFC3339), Valid: true},
+		NotificationID: notificationID,
+	})
+}
+
+func (s *NotificationService) Preferences(ctx context.Context, userID string) ([]domain.Preference, error) {
+	optOuts, err := s.queries.ListNotificationOptOuts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.Preferences(optOuts), nil
+}
+
+// UpdatePreferences enables or disables the given types and leaves the
+// others as they are. Disabling a type stops new notifications of it and
+// keeps the ones already in the inbox.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID string, preferences []domain.Preference) ([]domain.Preference, error) {
+	for _, preference := range preferences {
+		var err error
+		if preference.Enabled {
+			err = s.queries.DeleteNotificationOptOut(ctx, DeleteNotificationOptOutParams{UserID: userID, Type: string(preference.Type)})
+		} else {
+			err = s.queries.InsertNotificationOptOut(ctx, InsertNotificationOptOutParams{UserID: userID, Type: string(preference.Type)})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.Preferences(ctx, userID)
+}
+
+// findOwn reports a notification of another user as not found, so that
+// notification IDs of others cannot be probed.
+func (s *NotificationService) findOwn(ctx context.Context, userID, notificationID string) error {
+	ownerID, err := s.queries.GetNotificationOwner(ctx, notificationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotificationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if ownerID != userID {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
@@ -0,0 +1,595 @@
+//go:build medium
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"holocron/internal/backup"
+	"holocron/internal/notification/domain"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE lending_events (
+			event_id TEXT PRIMARY KEY,
+			lending_id TEXT NOT NULL,
+			book_id TEXT NOT NULL,
+			borrower_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			due_date TEXT,
+			actor_id TEXT,
+			reason TEXT,
+			occurred_at TEXT NOT NULL
+		);
+
+		CREATE TABLE book_events (
+			event_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			authors TEXT,
+			publisher TEXT,
+			published_date TEXT,
+			thumbnail_url TEXT,
+			delete_reason TEXT,
+			delete_memo TEXT,
+			origin TEXT,
+			cover_id TEXT,
+			tags TEXT,
+			category_id TEXT,
+			shelf_location TEXT,
+			series_id TEXT,
+			volume_number INTEGER,
+			accession_number TEXT,
+			condition TEXT,
+			condition_note TEXT,
+			lending_id TEXT,
+			occurred_at TEXT NOT NULL
+		);
+
+		CREATE TABLE user_events (
+			event_id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+
+		CREATE TABLE category_events (
+			event_id TEXT PRIMARY KEY,
+			category_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			parent_id TEXT,
+			code TEXT,
+			name TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+
+		CREATE TABLE series_events (
+			event_id TEXT PRIMARY KEY,
+			series_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			title TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+
+		CREATE TABLE cover_images (
+			source TEXT NOT NULL,
+			variant TEXT NOT NULL,
+			content_type TEXT NOT NULL,
+			data BLOB NOT NULL,
+			etag TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (source, variant)
+		);
+
+		CREATE TABLE audit_sessions (
+			audit_id TEXT PRIMARY KEY,
+			started_by TEXT NOT NULL,
+			started_at TEXT NOT NULL,
+			finished_by TEXT,
+			finished_at TEXT
+		);
+
+		CREATE TABLE notifications (
+			notification_id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			title TEXT NOT NULL,
+			body TEXT NOT NULL,
+			book_id TEXT,
+			dedupe_key TEXT NOT NULL UNIQUE,
+			created_at TEXT NOT NULL,
+			read_at TEXT,
+			deleted_at TEXT
+		);
+
+		CREATE TABLE notification_opt_outs (
+			user_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			PRIMARY KEY (user_id, type)
+		);
+
+		CREATE TABLE notification_state (
+			stream TEXT PRIMARY KEY,
+			last_rowid INTEGER NOT NULL
+		);
+
+		-- open_lendings has the loans that have not been returned, transferred or
+		-- reported lost. due_date is the one set when the loan was opened.
+		CREATE VIEW open_lendings AS
+		SELECT
+			le.lending_id,
+			le.book_id,
+			le.borrower_id,
+			le.due_date,
+			le.occurred_at as borrowed_at
+		FROM lending_events le
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND NOT EXISTS (
+				SELECT 1
+				FROM lending_events closed
+				WHERE closed.lending_id = le.lending_id
+					AND closed.event_type IN ('returned', 'force_returned', 'transferred', 'lost')
+			);
+
+		CREATE VIEW deleted_books AS
+		SELECT book_id, MAX(occurred_at) as deleted_at
+		FROM book_events
+		WHERE event_type = 'deleted'
+		GROUP BY book_id;
+
+		-- latest_books has the latest details of each book since it was last deleted.
+		-- Events in the same second are ordered as they were recorded.
+		CREATE VIEW latest_books AS
+		SELECT book_id, code, title, authors, publisher, published_date, thumbnail_url, created_at, updated_at
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.code,
+				e1.title,
+				e1.authors,
+				e1.publisher,
+				e1.published_date,
+				e1.thumbnail_url,
+				(SELECT MIN(e_created.occurred_at)
+				 FROM book_events e_created
+				 WHERE e_created.book_id = e1.book_id
+				   AND e_created.event_type = 'created'
+				   AND e_created.occurred_at > COALESCE(d.deleted_at, '1970-01-01T00:00:00Z')
+				) as created_at,
+				e1.occurred_at as updated_at,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('created', 'updated')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- book_classifications has the latest tags_changed, category_changed and
+		-- shelf_changed event of each book, one row per event type.
+		CREATE VIEW book_classifications AS
+		SELECT book_id, event_type, tags, category_id, shelf_location
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.event_type,
+				e1.tags,
+				e1.category_id,
+				e1.shelf_location,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id, e1.event_type ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('tags_changed', 'category_changed', 'shelf_changed')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- current_lendings has the open loan of each book, with its latest due date.
+		CREATE VIEW current_lendings AS
+		SELECT lending_id, book_id, borrower_id, borrower_name, borrowed_at, due_date
+		FROM (
+			SELECT
+				ol.lending_id,
+				ol.book_id,
+				ol.borrower_id,
+				ue.name as borrower_name,
+				ol.borrowed_at,
+				(SELECT due.due_date
+				 FROM lending_events due
+				 WHERE due.lending_id = ol.lending_id
+				   AND due.event_type IN ('borrowed', 'lent', 'due_date_extended')
+				   AND due.due_date IS NOT NULL
+				 ORDER BY due.occurred_at DESC
+				 LIMIT 1
+				) as due_date,
+				ROW_NUMBER() OVER (PARTITION BY ol.book_id ORDER BY ol.borrowed_at DESC) as rn
+			FROM open_lendings ol
+			INNER JOIN user_events ue ON ue.user_id = ol.borrower_id AND ue.event_type = 'created'
+			LEFT JOIN deleted_books d ON ol.book_id = d.book_id
+			WHERE d.deleted_at IS NULL OR ol.borrowed_at > d.deleted_at
+		)
+		WHERE rn = 1;
+
+		-- borrow_counts counts the loans of each book since it was last deleted.
+		CREATE VIEW borrow_counts AS
+		SELECT le.book_id, COUNT(*) as borrow_count
+		FROM lending_events le
+		LEFT JOIN deleted_books d ON le.book_id = d.book_id
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND (d.deleted_at IS NULL OR le.occurred_at > d.deleted_at)
+		GROUP BY le.book_id;
+
+		-- book_list_items joins the current state of each book for the book list, the
+		-- search and the facets, which filter it with the same conditions.
+		CREATE VIEW book_list_items AS
+		SELECT
+			lb.book_id,
+			lb.code,
+			lb.title,
+			lb.authors,
+			lb.publisher,
+			lb.published_date,
+			lb.thumbnail_url,
+			lb.created_at,
+			lb.updated_at,
+			cl.lending_id,
+			cl.borrower_id,
+			cl.borrower_name,
+			cl.borrowed_at,
+			cl.due_date,
+			bt.tags,
+			bcat.category_id,
+			ce.name as category_name,
+			ce.code as category_code,
+			bs.shelf_location,
+			COALESCE(bc.borrow_count, 0) as borrow_count
+		FROM latest_books lb
+		LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id
+		LEFT JOIN book_classifications bt ON lb.book_id = bt.book_id AND bt.event_type = 'tags_changed'
+		LEFT JOIN book_classifications bcat ON lb.book_id = bcat.book_id AND bcat.event_type = 'category_changed'
+		LEFT JOIN book_classifications bs ON lb.book_id = bs.book_id AND bs.event_type = 'shelf_changed'
+		LEFT JOIN category_events ce ON bcat.category_id = ce.category_id AND ce.event_type = 'created'
+		LEFT JOIN borrow_counts bc ON lb.book_id = bc.book_id;
+	`)
+	if err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+var testNow = time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)
+
+func newTestService(t *testing.T, db *sql.DB) *NotificationService {
+	t.Helper()
+	dispatcher := NewDispatcher(db, DefaultProducers()...)
+	dispatcher.now = func() time.Time { return testNow }
+	service := NewNotificationService(New(db), dispatcher)
+	service.now = func() time.Time { return testNow }
+	// Start from an empty event history so the events of each test are new.
+	if err := dispatcher.Dispatch(context.Background()); err != nil {
+		t.Fatalf("failed to dispatch: %v", err)
+	}
+	return service
+}
+
+func insertBook(t *testing.T, db *sql.DB, bookID, title string) {
+	t.Helper()
+	_, err := db.Exec(
+		`INSERT INTO book_events (event_id, book_id, event_type, title, occurred_at) VALUES (?, ?, 'created', ?, '2024-01-01T00:00:00Z')`,
+		uuid.New().String(), bookID, title,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert book event: %v", err)
+	}
+}
+
+func insertSeries(t *testing.T, db *sql.DB, seriesID, title string) {
+	t.Helper()
+	_, err := db.Exec(
+		`INSERT INTO series_events (event_id, series_id, event_type, title, occurred_at) VALUES (?, ?, 'created', ?, '2024-01-01T00:00:00Z')`,
+		uuid.New().String(), seriesID, title,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert series event: %v", err)
+	}
+}
+
+func insertSeriesChanged(t *testing.T, db *sql.DB, bookID, seriesID string, volume int) {
+	t.Helper()
+	_, err := db.Exec(
+		`INSERT INTO book_events (event_id, book_id, event_type, series_id, volume_number, occurred_at) VALUES (?, ?, 'series_changed', ?, ?, '2024-01-02T00:00:00Z')`,
+		uuid.New().String(), bookID, seriesID, volume,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert book event: %v", err)
+	}
+}
+
+type lendingEvent struct {
+	lendingID  string
+	bookID     string
+	borrowerID string
+	eventType  string
+	dueDate    *string
+	actorID    *string
+	reason     *string
+	occurredAt string
+}
+
+func insertLendingEvent(t *testing.T, db *sql.DB, e lendingEvent) {
+	t.Helper()
+	if e.occurredAt == "" {
+		e.occurredAt = "2024-02-01T00:00:00Z"
+	}
+	_, err := db.Exec(
+		`INSERT INTO lending_events (event_id, lending_id, book_id, borrower_id, event_type, due_date, actor_id, reason, occurred_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		uuid.New().String(), e.lendingID, e.bookID, e.borrowerID, e.eventType, e.dueDate, e.actorID, e.reason, e.occurredAt,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert lending event: %v", err)
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func listAll(t *testing.T, service *NotificationService, userID string) *ListNotificationsOutput {
+	t.Helper()
+	output, err := service.List(context.Background(), ListNotificationsInput{UserID: userID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return output
+}
+
+func TestNotificationService_List_WithLoanDueWithinADay_RemindsOnce(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(t, db)
+	insertBook(t, db, "book-1", "Go言語")
+	insertBook(t, db, "book-2", "Later")
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "borrowed", dueDate: ptr("2024-02-03T12:00:00Z")})
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-2", bookID: "book-2", borrowerID: "user-1", eventType: "borrowed", dueDate: ptr("2024-02-05T12:00:00Z")})
+
+	listAll(t, service, "user-1")
+	got := listAll(t, service, "user-1")
+
+	if got.Total != 1 || got.UnreadCount != 1 {
+		t.Fatalf("expected one unread notification, got %+v", got)
+	}
+	item := got.Items[0]
+	if item.Type != string(domain.TypeDueSoon) || item.BookID == nil || *item.BookID != "book-1" {
+		t.Errorf("unexpected notification: %+v", item)
+	}
+	if want := "「Go言語」の返却期限は2024-02-03 21:00です。"; item.Body != want {
+		t.Errorf("expected %q, got %q", want, item.Body)
+	}
+}
+
+func TestNotificationService_List_WithExtendedLoan_RemindsOfNewDueDate(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(t, db)
+	insertBook(t, db, "book-1", "Go言語")
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "borrowed", dueDate: ptr("2024-02-03T12:00:00Z")})
+	listAll(t, service, "user-1")
+
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "due_date_extended", dueDate: ptr("2024-02-03T18:00:00Z"), occurredAt: "2024-02-02T00:00:00Z"})
+	got := listAll(t, service, "user-1")
+
+	if got.Total != 2 {
+		t.Errorf("expected a reminder per due date, got %d", got.Total)
+	}
+}
+
+func TestNotificationService_List_WithReturnedLoan_DoesNotRemind(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(t, db)
+	insertBook(t, db, "book-1", "Go言語")
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "borrowed", dueDate: ptr("2024-02-03T12:00:00Z")})
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "returned", occurredAt: "2024-02-02T00:00:00Z"})
+
+	if got := listAll(t, service, "user-1"); got.Total != 0 {
+		t.Errorf("expected no notification, got %+v", got.Items)
+	}
+}
+
+func TestNotificationService_List_WithLibrarianAction_NotifiesBorrower(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(t, db)
+	insertBook(t, db, "book-1", "Go言語")
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "borrowed", dueDate: ptr("2024-02-20T00:00:00Z")})
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "force_returned", actorID: ptr("librarian"), reason: ptr("棚卸しで発見"), occurredAt: "2024-02-02T00:00:00Z"})
+
+	got := listAll(t, service, "user-1")
+
+	if got.Total != 1 {
+		t.Fatalf("expected one notification, got %+v", got.Items)
+	}
+	if want := "司書が「Go言語」を返却済みにしました。理由: 棚卸しで発見"; got.Items[0].Body != want {
+		t.Errorf("expected %q, got %q", want, got.Items[0].Body)
+	}
+}
+
+func TestNotificationService_List_WithEventsBeforeFirstDispatch_DoesNotNotify(t *testing.T) {
+	db := setupTestDB(t)
+	insertBook(t, db, "book-1", "Go言語")
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "lent", dueDate: ptr("2024-02-20T00:00:00Z"), actorID: ptr("librarian")})
+	service := newTestService(t, db)
+
+	if got := listAll(t, service, "user-1"); got.Total != 0 {
+		t.Errorf("expected no notification, got %+v", got.Items)
+	}
+}
+
+func TestNotificationService_List_WithBookAddedToSeries_NotifiesReaders(t *testing.T) {
+	db := setupTestDB(t)
+	insertSeries(t, db, "series-1", "シリーズ")
+	insertBook(t, db, "book-1", "1巻")
+	insertSeriesChanged(t, db, "book-1", "series-1", 1)
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "reader", eventType: "borrowed", dueDate: ptr("2024-01-20T00:00:00Z"), occurredAt: "2024-01-05T00:00:00Z"})
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "reader", eventType: "returned", occurredAt: "2024-01-10T00:00:00Z"})
+	service := newTestService(t, db)
+
+	insertBook(t, db, "book-2", "2巻")
+	insertSeriesChanged(t, db, "book-2", "series-1", 2)
+
+	got := listAll(t, service, "reader")
+	if got.Total != 1 || got.Items[0].Type != string(domain.TypeSeriesVolumeAdded) {
+		t.Fatalf("expected a series notification, got %+v", got.Items)
+	}
+	if want := "「シリーズ」に「2巻」が追加されました。"; got.Items[0].Body != want {
+		t.Errorf("expected %q, got %q", want, got.Items[0].Body)
+	}
+	if other := listAll(t, service, "someone-else"); other.Total != 0 {
+		t.Errorf("expected no notification for other users, got %+v", other.Items)
+	}
+}
+
+func TestNotificationService_UpdatePreferences_WithDisabledType_StopsNotifications(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(t, db)
+	ctx := context.Background()
+
+	preferences, err := service.UpdatePreferences(ctx, "user-1", []domain.Preference{{Type: domain.TypeDueSoon, Enabled: false}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if preferences[0] != (domain.Preference{Type: domain.TypeDueSoon, Enabled: false}) || !preferences[1].Enabled {
+		t.Errorf("unexpected preferences: %v", preferences)
+	}
+	insertBook(t, db, "book-1", "Go言語")
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "borrowed", dueDate: ptr("2024-02-03T12:00:00Z")})
+
+	if got := listAll(t, service, "user-1"); got.Total != 0 {
+		t.Errorf("expected no notification, got %+v", got.Items)
+	}
+	preferences, err = service.Preferences(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if preferences[0].Enabled {
+		t.Errorf("expected due_soon to stay disabled, got %v", preferences)
+	}
+}
+
+func TestNotificationService_MarkRead_UpdatesUnreadCount(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(t, db)
+	ctx := context.Background()
+	insertBook(t, db, "book-1", "Go言語")
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "borrowed", dueDate: ptr("2024-02-03T12:00:00Z")})
+	id := listAll(t, service, "user-1").Items[0].NotificationID
+
+	if err := service.MarkRead(ctx, "user-1", id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := listAll(t, service, "user-1")
+	if got.Total != 1 || got.UnreadCount != 0 || got.Items[0].ReadAt == nil {
+		t.Errorf("expected a read notification, got %+v", got)
+	}
+	unread, err := service.List(ctx, ListNotificationsInput{UserID: "user-1", UnreadOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if unread.Total != 0 || len(unread.Items) != 0 {
+		t.Errorf("expected no unread notification, got %+v", unread.Items)
+	}
+}
+
+func TestNotificationService_Delete_DoesNotCreateNotificationAgain(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(t, db)
+	insertBook(t, db, "book-1", "Go言語")
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "borrowed", dueDate: ptr("2024-02-03T12:00:00Z")})
+	id := listAll(t, service, "user-1").Items[0].NotificationID
+
+	if err := service.Delete(context.Background(), "user-1", id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := listAll(t, service, "user-1"); got.Total != 0 {
+		t.Errorf("expected the notification to stay deleted, got %+v", got.Items)
+	}
+}
+
+func TestNotificationService_WithOtherUsersNotification_ReturnsNotFound(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestService(t, db)
+	ctx := context.Background()
+	insertBook(t, db, "book-1", "Go言語")
+	insertLendingEvent(t, db, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "borrowed", dueDate: ptr("2024-02-03T12:00:00Z")})
+	id := listAll(t, service, "user-1").Items[0].NotificationID
+
+	if err := service.MarkRead(ctx, "user-2", id); !errors.Is(err, ErrNotificationNotFound) {
+		t.Errorf("expected ErrNotificationNotFound, got %v", err)
+	}
+	if err := service.Delete(ctx, "user-2", id); !errors.Is(err, ErrNotificationNotFound) {
+		t.Errorf("expected ErrNotificationNotFound, got %v", err)
+	}
+	if err := service.MarkRead(ctx, "user-1", uuid.New().String()); !errors.Is(err, ErrNotificationNotFound) {
+		t.Errorf("expected ErrNotificationNotFound, got %v", err)
+	}
+	if got := listAll(t, service, "user-1"); got.UnreadCount != 1 {
+		t.Errorf("expected the notification to stay unread, got %+v", got)
+	}
+}
+
+func TestDispatcher_Rebuild_AfterRestore_DoesNotNotifyOfRestoredEvents(t *testing.T) {
+	source := setupTestDB(t)
+	insertSeries(t, source, "series-1", "シリーズ")
+	insertBook(t, source, "book-1", "1巻")
+	insertSeriesChanged(t, source, "book-1", "series-1", 1)
+	insertLendingEvent(t, source, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "lent", dueDate: ptr("2024-01-20T00:00:00Z"), actorID: ptr("librarian"), occurredAt: "2024-01-05T00:00:00Z"})
+	insertLendingEvent(t, source, lendingEvent{lendingID: "lending-1", bookID: "book-1", borrowerID: "user-1", eventType: "force_returned", actorID: ptr("librarian"), reason: ptr("退職"), occurredAt: "2024-01-10T00:00:00Z"})
+	insertBook(t, source, "book-2", "2巻")
+	insertSeriesChanged(t, source, "book-2", "series-1", 2)
+	var buf bytes.Buffer
+	if _, err := backup.NewBackupService(source).Backup(context.Background(), &buf); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+
+	// Given a running server that has already dispatched
+	target := setupTestDB(t)
+	target.SetMaxOpenConns(1)
+	service := newTestService(t, target)
+
+	// When the backup is restored with the dispatcher as a projection
+	_, err := backup.NewRestoreService(target, backup.Projection{
+		Name:    "notifications",
+		Rebuild: service.dispatcher.Rebuild,
+	}).Restore(context.Background(), backup.RestoreInput{Backup: bytes.NewReader(buf.Bytes())})
+	if err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	// Then none of the restored events is announced
+	if got := listAll(t, service, "user-1"); got.Total != 0 {
+		t.Errorf("expected no notification, got %+v", got.Items)
+	}
+	var count int
+	if err := target.QueryRow(`SELECT COUNT(*) FROM notifications`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("expected no notification, got %d", count)
+	}
+}
@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"holocron/internal/notification/domain"
+)
+
+// Producer creates notifications of one type. A producer also implements
+// LendingEventProducer, BookEventProducer or ScheduledProducer, which tell
+// the dispatcher when to run it.
+type Producer interface {
+	Type() domain.NotificationType
+}
+
+// LendingEventProducer reacts to each lending event recorded since the last
+// dispatch.
+type LendingEventProducer interface {
+	Producer
+	ProduceForLendingEvent(ctx context.Context, q *Queries, event ListLendingEventsBetweenRow) ([]domain.Draft, error)
+}
+
+// BookEventProducer reacts to each book event recorded since the last
+// dispatch.
+type BookEventProducer interface {
+	Producer
+	ProduceForBookEvent(ctx context.Context, q *Queries, event ListBookEventsBetweenRow) ([]domain.Draft, error)
+}
+
+// ScheduledProducer runs on every dispatch to notify of what becomes due
+// with time rather than with an event.
+type ScheduledProducer interface {
+	Producer
+	ProduceAt(ctx context.Context, q *Queries, now time.Time) ([]domain.Draft, error)
+}
+
+// DefaultProducers returns the producers of every notification type.
+func DefaultProducers() []Producer {
+	return []Producer{
+		NewDueSoonProducer(24 * time.Hour),
+		LibrarianActionProducer{},
+		SeriesVolumeProducer{},
+	}
+}
+
+// DueSoonProducer reminds borrowers of the loans due within the window.
+type DueSoonProducer struct {
+	window time.Duration
+}
+
+func NewDueSoonProducer(window time.Duration) DueSoonProducer {
+	return DueSoonProducer{window: window}
+}
+
+func (DueSoonProducer) Type() domain.NotificationType {
+	return domain.TypeDueSoon
+}
+
+func (p DueSoonProducer) ProduceAt(ctx context.Context, q *Queries, now time.Time) ([]domain.Draft, error) {
+	rows, err := q.ListLoansDueBetween(ctx, ListLoansDueBetweenParams{
+		FromAt: sql.NullString{String: now.UTC().Format(time.RFC3339), Valid: true},
+		ToAt:   sql.NullString{String: now.Add(p.window).UTC().Format(time.RFC3339), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	drafts := make([]domain.Draft, 0, len(rows))
+	for _, row := range rows {
+		dueDate, err := time.Parse(time.RFC3339, row.DueDate.String)
+		if err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, domain.DueSoonDraft(row.BorrowerID, row.LendingID, row.BookID, row.Title.String, dueDate))
+	}
+	return drafts, nil
+}
+
+// LibrarianActionProducer tells borrowers when a librarian lends them a book
+// or closes their loan.
+type LibrarianActionProducer struct{}
+
+func (LibrarianActionProducer) Type() domain.NotificationType {
+	return domain.TypeLibrarianAction
+}
+
+func (LibrarianActionProducer) ProduceForLendingEvent(ctx context.Context, q *Queries, event ListLendingEventsBetweenRow) ([]domain.Draft, error) {
+	if !event.ActorID.Valid || event.ActorID.String == event.BorrowerID {
+		return nil, nil
+	}
+	title, err := bookTitle(ctx, q, event.BookID)
+	if err != nil {
+		return nil, err
+	}
+	draft, ok := domain.LibrarianActionDraft(event.EventID, event.EventType, event.BorrowerID, event.ActorID.String, event.BookID, title, event.Reason.String)
+	if !ok {
+		return nil, nil
+	}
+	return []domain.Draft{draft}, nil
+}
+
+// SeriesVolumeProducer tells the users who borrowed a book of a series when
+// another book is added to it.
+type SeriesVolumeProducer struct{}
+
+func (SeriesVolumeProducer) Type() domain.NotificationType {
+	return domain.TypeSeriesVolumeAdded
+}
+
+func (SeriesVolumeProducer) ProduceForBookEvent(ctx context.Context, q *Queries, event ListBookEventsBetweenRow) ([]domain.Draft, error) {
+	if event.EventType != "series_changed" || !event.SeriesID.Valid {
+		return nil, nil
+	}
+	seriesTitle, err := q.GetSeriesTitle(ctx, event.SeriesID.String)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	readers, err := q.ListSeriesReaders(ctx, ListSeriesReadersParams{
+		SeriesID: event.SeriesID.String,
+		BookID:   event.BookID,
+	})
+	if err != nil || len(readers) == 0 {
+		return nil, err
+	}
+	title, err := bookTitle(ctx, q, event.BookID)
+	if err != nil {
+		return nil, err
+	}
+	drafts := make([]domain.Draft, 0, len(readers))
+	for _, userID := range readers {
+		drafts = append(drafts, domain.SeriesVolumeDraft(userID, event.BookID, title, event.SeriesID.String, seriesTitle))
+	}
+	return drafts, nil
+}
+
+func bookTitle(ctx context.Context, q *Queries, bookID string) (string, error) {
+	title, err := q.GetBookTitle(ctx, bookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return title.String, err
+}
@@ -0,0 +1,93 @@
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// ReadModel is derived from an append-only event table. It stores the rowid
+// of the last event it has applied, so that only the events appended since
+// have to be applied next time.
+type ReadModel interface {
+	// Position returns the rowid of the last applied event. sql.ErrNoRows
+	// means the read model has never been built and is reset.
+	Position(ctx context.Context, tx *sql.Tx) (int64, error)
+	SetPosition(ctx context.Context, tx *sql.Tx, rowid int64) error
+	// LatestRowid returns the rowid of the last event in the event table.
+	LatestRowid(ctx context.Context, tx *sql.Tx) (int64, error)
+	// Reset clears the read model and returns the rowid to apply events
+	// after: 0 to apply every event again, or latest to skip them.
+	Reset(ctx context.Context, tx *sql.Tx, latest int64) (int64, error)
+	// Apply applies the events with rowids in (afterRowid, untilRowid].
+	Apply(ctx context.Context, tx *sql.Tx, afterRowid, untilRowid int64) error
+}
+
+// Advance brings the read model up to the latest event within tx. With
+// rebuild, the read model is reset first.
+func Advance(ctx context.Context, tx *sql.Tx, model ReadModel, rebuild bool) error {
+	latest, err := model.LatestRowid(ctx, tx)
+	if err != nil {
+		return err
+	}
+	position, err := model.Position(ctx, tx)
+	if errors.Is(err, sql.ErrNoRows) {
+		rebuild = true
+	} else if err != nil {
+		return err
+	}
+
+	// Rowids only go backwards when the event table has been replaced.
+	if rebuild || latest < position {
+		position, err = model.Reset(ctx, tx, latest)
+		if err != nil {
+			return err
+		}
+	} else if position == latest {
+		return nil
+	}
+
+	if position < latest {
+		if err := model.Apply(ctx, tx, position, latest); err != nil {
+			return err
+		}
+	}
+	return model.SetPosition(ctx, tx, latest)
+}
+
+// Projection keeps a read model up to date, one catch-up at a time.
+type Projection struct {
+	db    *sql.DB
+	model ReadModel
+	mu    sync.Mutex
+}
+
+func New(db *sql.DB, model ReadModel) *Projection {
+	return &Projection{db: db, model: model}
+}
+
+// CatchUp applies the events appended since the last catch-up.
+func (p *Projection) CatchUp(ctx context.Context) error {
+	return p.run(ctx, false)
+}
+
+// Rebuild resets the read model and brings it up to date again.
+func (p *Projection) Rebuild(ctx context.Context) error {
+	return p.run(ctx, true)
+}
+
+func (p *Projection) run(ctx context.Context, rebuild bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := Advance(ctx, tx, p.model, rebuild); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
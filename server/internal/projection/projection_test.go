@@ -0,0 +1,105 @@
+//go:build medium
+
+package projection
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// countingModel counts the events it applies, with its position in memory.
+type countingModel struct {
+	position *int64
+	latest   int64
+	applied  int64
+	resets   int
+}
+
+func (m *countingModel) Position(ctx context.Context, tx *sql.Tx) (int64, error) {
+	if m.position == nil {
+		return 0, sql.ErrNoRows
+	}
+	return *m.position, nil
+}
+
+func (m *countingModel) SetPosition(ctx context.Context, tx *sql.Tx, rowid int64) error {
+	m.position = &rowid
+	return nil
+}
+
+func (m *countingModel) LatestRowid(ctx context.Context, tx *sql.Tx) (int64, error) {
+	return m.latest, nil
+}
+
+func (m *countingModel) Reset(ctx context.Context, tx *sql.Tx, latest int64) (int64, error) {
+	m.applied = 0
+	m.resets++
+	return 0, nil
+}
+
+func (m *countingModel) Apply(ctx context.Context, tx *sql.Tx, afterRowid, untilRowid int64) error {
+	m.applied += untilRowid - afterRowid
+	return nil
+}
+
+func setup(t *testing.T, model ReadModel) *Projection {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return New(db, model)
+}
+
+func TestProjection_CatchUp_AppliesOnlyNewEvents(t *testing.T) {
+	model := &countingModel{latest: 3}
+	p := setup(t, model)
+	ctx := context.Background()
+
+	if err := p.CatchUp(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	model.latest = 5
+	if err := p.CatchUp(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if model.applied != 5 || *model.position != 5 {
+		t.Errorf("expected 5 events applied up to 5, got %d up to %d", model.applied, *model.position)
+	}
+}
+
+func TestProjection_CatchUp_WithReplacedEvents_Resets(t *testing.T) {
+	position := int64(10)
+	model := &countingModel{position: &position, latest: 4}
+	p := setup(t, model)
+
+	if err := p.CatchUp(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if model.resets != 1 || model.applied != 4 {
+		t.Errorf("expected a reset and 4 events applied, got %d resets and %d events", model.resets, model.applied)
+	}
+}
+
+func TestProjection_Rebuild_AppliesEveryEventAgain(t *testing.T) {
+	model := &countingModel{latest: 3}
+	p := setup(t, model)
+	ctx := context.Background()
+	if err := p.CatchUp(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := p.Rebuild(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if model.resets != 2 || model.applied != 3 {
+		t.Errorf("expected the events to be applied again, got %d resets and %d events", model.resets, model.applied)
+	}
+}
@@ -0,0 +1,82 @@
+package series
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	book "holocron/internal/book/domain"
+
+	"github.com/google/uuid"
+)
+
+type BookSeries struct {
+	ID     string
+	Title  string
+	Volume int64
+}
+
+// AssignBookSeries puts a book in a series with a series_changed event. The series
+// is looked up by title and created when no series has that title yet. A nil
+// series takes the book out of its series.
+func AssignBookSeries(ctx context.Context, queries *Queries, bookID string, series *book.BookSeries) (*BookSeries, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	if series == nil {
+		err := queries.InsertBookSeriesChangedEvent(ctx, InsertBookSeriesChangedEventParams{
+			EventID:    uuid.New().String(),
+			BookID:     bookID,
+			OccurredAt: now,
+		})
+		return nil, err
+	}
+
+	seriesID, err := ensureSeries(ctx, queries, series.Title, now)
+	if err != nil {
+		return nil, err
+	}
+	err = queries.InsertBookSeriesChangedEvent(ctx, InsertBookSeriesChangedEventParams{
+		EventID:      uuid.New().String(),
+		BookID:       bookID,
+		SeriesID:     sql.NullString{String: seriesID, Valid: true},
+		VolumeNumber: sql.NullInt64{Int64: series.Volume, Valid: true},
+		OccurredAt:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &BookSeries{ID: seriesID, Title: series.Title, Volume: series.Volume}, nil
+}
+
+// AssignDetectedSeries puts a newly registered book in the series detected from
+// its title and the volume its metadata source reports. Nothing is recorded when
+// no series is detected.
+func AssignDetectedSeries(ctx context.Context, queries *Queries, bookID string, title string, volume string) (*BookSeries, error) {
+	detected := book.DetectBookSeries(title, volume)
+	if detected == nil {
+		return nil, nil
+	}
+	return AssignBookSeries(ctx, queries, bookID, detected)
+}
+
+func ensureSeries(ctx context.Context, queries *Queries, title string, now string) (string, error) {
+	seriesID, err := queries.GetSeriesIdByTitle(ctx, title)
+	if err == nil {
+		return seriesID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	seriesID = uuid.New().String()
+	err = queries.InsertSeriesEvent(ctx, InsertSeriesEventParams{
+		EventID:    uuid.New().String(),
+		SeriesID:   seriesID,
+		Title:      title,
+		OccurredAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return seriesID, nil
+}
@@ -0,0 +1,73 @@
+package domain
+
+type VolumeStatus string
+
+const (
+	VolumeStatusAvailable VolumeStatus = "available"
+	VolumeStatusBorrowed  VolumeStatus = "borrowed"
+	VolumeStatusMissing   VolumeStatus = "missing"
+)
+
+// OwnedBook is a book in the library that belongs to a series.
+type OwnedBook struct {
+	ID       string
+	Title    string
+	Volume   int64
+	Borrowed bool
+}
+
+// SeriesVolume is a volume of a series with the copies the library owns. A volume
+// is available when any of its copies can be borrowed.
+type SeriesVolume struct {
+	Number int64
+	Status VolumeStatus
+	Books  []OwnedBook
+}
+
+// BuildSeriesVolumes lists every volume from the first to the highest owned one,
+// so that the volumes the library lacks show up as missing. Books must be
+// ordered by volume.
+func BuildSeriesVolumes(books []OwnedBook) []SeriesVolume {
+	if len(books) == 0 {
+		return []SeriesVolume{}
+	}
+	last := books[len(books)-1].Volume
+	volumes := make([]SeriesVolume, last)
+	for i := range volumes {
+		volumes[i] = SeriesVolume{Number: int64(i + 1), Status: VolumeStatusMissing, Books: []OwnedBook{}}
+	}
+	for _, b := range books {
+		v := &volumes[b.Volume-1]
+		v.Books = append(v.Books, b)
+		switch {
+		case !b.Borrowed:
+			v.Status = VolumeStatusAvailable
+		case v.Status == VolumeStatusMissing:
+			v.Status = VolumeStatusBorrowed
+		}
+	}
+	return volumes
+}
+
+func MissingVolumes(volumes []SeriesVolume) []int64 {
+	missing := []int64{}
+	for _, v := range volumes {
+		if v.Status == VolumeStatusMissing {
+			missing = append(missing, v.Number)
+		}
+	}
+	return missing
+}
+
+// NextVolume is the first owned volume after the last one a reader has borrowed,
+// or the first owned volume for a reader who has not started the series. It is
+// nil when the reader has reached the last owned volume.
+func NextVolume(volumes []SeriesVolume, lastBorrowed int64) *int64 {
+	for _, v := range volumes {
+		if v.Number > lastBorrowed && v.Status != VolumeStatusMissing {
+			next := v.Number
+			return &next
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+//go:build small
+
+package domain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+)
+
+func TestBuildSeriesVolumes_WithGap_ReturnsMissingVolume(t *testing.T) {
+	books := []OwnedBook{
+		{ID: "book-1", Volume: 1, Borrowed: true},
+		{ID: "book-3", Volume: 3},
+	}
+
+	volumes := BuildSeriesVolumes(books)
+
+	statuses := []VolumeStatus{}
+	for _, v := range volumes {
+		statuses = append(statuses, v.Status)
+	}
+	expected := []VolumeStatus{VolumeStatusBorrowed, VolumeStatusMissing, VolumeStatusAvailable}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected %v, got %v", expected, statuses)
+	}
+	if missing := MissingVolumes(volumes); !reflect.DeepEqual(missing, []int64{2}) {
+		t.Errorf("expected [2], got %v", missing)
+	}
+}
+
+func TestBuildSeriesVolumes_WithAvailableCopy_ReturnsAvailable(t *testing.T) {
+	books := []OwnedBook{
+		{ID: "book-1", Volume: 1, Borrowed: true},
+		{ID: "book-2", Volume: 1},
+	}
+
+	volumes := BuildSeriesVolumes(books)
+
+	if len(volumes) != 1 || volumes[0].Status != VolumeStatusAvailable || len(volumes[0].Books) != 2 {
+		t.Errorf("expected one available volume with two books, got %+v", volumes)
+	}
+}
+
+func TestBuildSeriesVolumes_ListsEveryVolumeUpToTheLast(t *testing.T) {
+	properties := gopter.NewProperties(nil)
+	properties.Property("volumes are numbered from 1 to the highest owned volume", prop.ForAll(
+		func(last int64) bool {
+			volumes := BuildSeriesVolumes([]OwnedBook{{ID: "book", Volume: last}})
+			if int64(len(volumes)) != last {
+				return false
+			}
+			for i, v := range volumes {
+				if v.Number != int64(i+1) {
+					return false
+				}
+			}
+			return int64(len(MissingVolumes(volumes))) == last-1
+		},
+		gen.Int64Range(1, 200),
+	))
+	properties.TestingRun(t)
+}
+
+func TestNextVolume_ReturnsFirstOwnedVolumeAfterLastBorrowed(t *testing.T) {
+	volumes := BuildSeriesVolumes([]OwnedBook{
+		{ID: "book-1", Volume: 1},
+		{ID: "book-2", Volume: 2},
+		{ID: "book-4", Volume: 4},
+	})
+
+	tests := []struct {
+		lastBorrowed int64
+		expected     *int64
+	}{
+		{0, ptr(1)},
+		{1, ptr(2)},
+		{2, ptr(4)},
+		{4, nil},
+	}
+	for _, tt := range tests {
+		next := NextVolume(volumes, tt.lastBorrowed)
+		if !reflect.DeepEqual(next, tt.expected) {
+			t.Errorf("lastBorrowed %d: expected %v, got %v", tt.lastBorrowed, tt.expected, next)
+		}
+	}
+}
+
+func ptr(n int64) *int64 {
+	return &n
+}
@@ -0,0 +1,66 @@
+package series
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"holocron/internal/series/domain"
+)
+
+var ErrSeriesNotFound = errors.New("series not found")
+
+type GetSeriesInput struct {
+	SeriesID string
+	// UserID is the reader whose next volume is suggested.
+	UserID string
+}
+
+type GetSeriesOutput struct {
+	ID             string
+	Title          string
+	Volumes        []domain.SeriesVolume
+	MissingVolumes []int64
+	NextVolume     *int64
+}
+
+func GetSeries(ctx context.Context, queries *Queries, input GetSeriesInput) (*GetSeriesOutput, error) {
+	row, err := queries.GetSeriesBySeriesId(ctx, input.SeriesID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSeriesNotFound
+		}
+		return nil, err
+	}
+
+	rows, err := queries.ListSeriesBooks(ctx, input.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	books := make([]domain.OwnedBook, 0, len(rows))
+	for _, r := range rows {
+		books = append(books, domain.OwnedBook{
+			ID:       r.BookID,
+			Title:    r.Title.String,
+			Volume:   r.VolumeNumber,
+			Borrowed: r.Borrowed != 0,
+		})
+	}
+	volumes := domain.BuildSeriesVolumes(books)
+
+	lastBorrowed, err := queries.GetLastBorrowedVolume(ctx, GetLastBorrowedVolumeParams{
+		BorrowerID: input.UserID,
+		SeriesID:   input.SeriesID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetSeriesOutput{
+		ID:             row.SeriesID,
+		Title:          row.Title,
+		Volumes:        volumes,
+		MissingVolumes: domain.MissingVolumes(volumes),
+		NextVolume:     domain.NextVolume(volumes, lastBorrowed),
+	}, nil
+}
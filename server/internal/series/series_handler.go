@@ -0,0 +1,89 @@
+package series
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"holocron/internal/auth"
+	"holocron/internal/series/domain"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type GetSeriesHandler struct {
+	queries *Queries
+}
+
+func NewGetSeriesHandler(queries *Queries) *GetSeriesHandler {
+	return &GetSeriesHandler{queries: queries}
+}
+
+func (h *GetSeriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, seriesId openapi_types.UUID) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return
+	}
+
+	output, err := GetSeries(r.Context(), h.queries, GetSeriesInput{
+		SeriesID: seriesId.String(),
+		UserID:   userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSeriesNotFound):
+			writeError(w, http.StatusNotFound, "not_found", "series not found")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+		}
+		return
+	}
+
+	resp := map[string]any{
+		"id":             output.ID,
+		"title":          output.Title,
+		"volumes":        toVolumesResponse(output.Volumes),
+		"missingVolumes": output.MissingVolumes,
+	}
+	if output.NextVolume != nil {
+		resp["nextVolume"] = *output.NextVolume
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func toVolumesResponse(volumes []domain.SeriesVolume) []map[string]any {
+	resp := make([]map[string]any, 0, len(volumes))
+	for _, v := range volumes {
+		books := make([]map[string]any, 0, len(v.Books))
+		for _, b := range v.Books {
+			status := "available"
+			if b.Borrowed {
+				status = "borrowed"
+			}
+			books = append(books, map[string]any{
+				"id":     b.ID,
+				"title":  b.Title,
+				"status": status,
+			})
+		}
+		resp = append(resp, map[string]any{
+			"volume": v.Number,
+			"status": string(v.Status),
+			"books":  books,
+		})
+	}
+	return resp
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,368 @@
+//go:build medium
+
+package series
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	book "holocron/internal/book/domain"
+	"holocron/internal/series/domain"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE book_events (
+			event_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			authors TEXT,
+			publisher TEXT,
+			published_date TEXT,
+			thumbnail_url TEXT,
+			delete_reason TEXT,
+			delete_memo TEXT,
+			origin TEXT,
+			cover_id TEXT,
+			tags TEXT,
+			category_id TEXT,
+			shelf_location TEXT,
+			series_id TEXT,
+			volume_number INTEGER,
+			accession_number TEXT,
+			condition TEXT,
+			condition_note TEXT,
+			lending_id TEXT,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE INDEX idx_book_events_book_id ON book_events(book_id);
+
+		CREATE TABLE series_events (
+			event_id TEXT PRIMARY KEY,
+			series_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			title TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE INDEX idx_series_events_title ON series_events(title);
+
+		CREATE TABLE lending_events (
+			event_id TEXT PRIMARY KEY,
+			lending_id TEXT NOT NULL,
+			book_id TEXT NOT NULL,
+			borrower_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			due_date TEXT,
+			actor_id TEXT,
+			reason TEXT,
+			occurred_at TEXT NOT NULL
+		);
+
+		-- open_lendings has the loans that have not been returned, transferred or
+		-- reported lost. due_date is the one set when the loan was opened.
+		CREATE VIEW open_lendings AS
+		SELECT
+			le.lending_id,
+			le.book_id,
+			le.borrower_id,
+			le.due_date,
+			le.occurred_at as borrowed_at
+		FROM lending_events le
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND NOT EXISTS (
+				SELECT 1
+				FROM lending_events closed
+				WHERE closed.lending_id = le.lending_id
+					AND closed.event_type IN ('returned', 'force_returned', 'transferred', 'lost')
+			);
+
+		CREATE VIEW deleted_books AS
+		SELECT book_id, MAX(occurred_at) as deleted_at
+		FROM book_events
+		WHERE event_type = 'deleted'
+		GROUP BY book_id;
+
+		-- latest_books has the latest details of each book since it was last deleted.
+		-- Events in the same second are ordered as they were recorded.
+		CREATE VIEW latest_books AS
+		SELECT book_id, code, title, authors, publisher, published_date, thumbnail_url, created_at, updated_at
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.code,
+				e1.title,
+				e1.authors,
+				e1.publisher,
+				e1.published_date,
+				e1.thumbnail_url,
+				(SELECT MIN(e_created.occurred_at)
+				 FROM book_events e_created
+				 WHERE e_created.book_id = e1.book_id
+				   AND e_created.event_type = 'created'
+				   AND e_created.occurred_at > COALESCE(d.deleted_at, '1970-01-01T00:00:00Z')
+				) as created_at,
+				e1.occurred_at as updated_at,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('created', 'updated')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- book_classifications has the latest tags_changed, category_changed and
+		-- shelf_changed event of each book, one row per event type.
+		CREATE VIEW book_classifications AS
+		SELECT book_id, event_type, tags, category_id, shelf_location
+		FROM (
+			SELECT
+				e1.book_id,
+				e1.event_type,
+				e1.tags,
+				e1.category_id,
+				e1.shelf_location,
+				ROW_NUMBER() OVER (PARTITION BY e1.book_id, e1.event_type ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+			FROM book_events e1
+			LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+			WHERE e1.event_type IN ('tags_changed', 'category_changed', 'shelf_changed')
+				AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+		)
+		WHERE rn = 1;
+
+		-- current_lendings has the open loan of each book, with its latest due date.
+		CREATE VIEW current_lendings AS
+		SELECT lending_id, book_id, borrower_id, borrower_name, borrowed_at, due_date
+		FROM (
+			SELECT
+				ol.lending_id,
+				ol.book_id,
+				ol.borrower_id,
+				ue.name as borrower_name,
+				ol.borrowed_at,
+				(SELECT due.due_date
+				 FROM lending_events due
+				 WHERE due.lending_id = ol.lending_id
+				   AND due.event_type IN ('borrowed', 'lent', 'due_date_extended')
+				   AND due.due_date IS NOT NULL
+				 ORDER BY due.occurred_at DESC
+				 LIMIT 1
+				) as due_date,
+				ROW_NUMBER() OVER (PARTITION BY ol.book_id ORDER BY ol.borrowed_at DESC) as rn
+			FROM open_lendings ol
+			INNER JOIN user_events ue ON ue.user_id = ol.borrower_id AND ue.event_type = 'created'
+			LEFT JOIN deleted_books d ON ol.book_id = d.book_id
+			WHERE d.deleted_at IS NULL OR ol.borrowed_at > d.deleted_at
+		)
+		WHERE rn = 1;
+
+		-- borrow_counts counts the loans of each book since it was last deleted.
+		CREATE VIEW borrow_counts AS
+		SELECT le.book_id, COUNT(*) as borrow_count
+		FROM lending_events le
+		LEFT JOIN deleted_books d ON le.book_id = d.book_id
+		WHERE le.event_type IN ('borrowed', 'lent')
+			AND (d.deleted_at IS NULL OR le.occurred_at > d.deleted_at)
+		GROUP BY le.book_id;
+
+		-- book_list_items joins the current state of each book for the book list, the
+		-- search and the facets, which filter it with the same conditions.
+		CREATE VIEW book_list_items AS
+		SELECT
+			lb.book_id,
+			lb.code,
+			lb.title,
+			lb.authors,
+			lb.publisher,
+			lb.published_date,
+			lb.thumbnail_url,
+			lb.created_at,
+			lb.updated_at,
+			cl.lending_id,
+			cl.borrower_id,
+			cl.borrower_name,
+			cl.borrowed_at,
+			cl.due_date,
+			bt.tags,
+			bcat.category_id,
+			ce.name as category_name,
+			ce.code as category_code,
+			bs.shelf_location,
+			COALESCE(bc.borrow_count, 0) as borrow_count
+		FROM latest_books lb
+		LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id
+		LEFT JOIN book_classifications bt ON lb.book_id = bt.book_id AND bt.event_type = 'tags_changed'
+		LEFT JOIN book_classifications bcat ON lb.book_id = bcat.book_id AND bcat.event_type = 'category_changed'
+		LEFT JOIN book_classifications bs ON lb.book_id = bs.book_id AND bs.event_type = 'shelf_changed'
+		LEFT JOIN category_events ce ON bcat.category_id = ce.category_id AND ce.event_type = 'created'
+		LEFT JOIN borrow_counts bc ON lb.book_id = bc.book_id;
+	`)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func insertBook(t *testing.T, db *sql.DB, title string) string {
+	t.Helper()
+	bookID := uuid.New().String()
+	_, err := db.Exec(
+		`INSERT INTO book_events (event_id, book_id, event_type, title, occurred_at) VALUES (?, ?, 'created', ?, ?)`,
+		uuid.New().String(), bookID, title, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		t.Fatalf("failed to insert book: %v", err)
+	}
+	return bookID
+}
+
+func insertLending(t *testing.T, db *sql.DB, bookID, borrowerID, eventType string) {
+	t.Helper()
+	_, err := db.Exec(
+		`INSERT INTO lending_events (event_id, lending_id, book_id, borrower_id, event_type, due_date, occurred_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		uuid.New().String(), bookID, bookID, borrowerID, eventType,
+		time.Now().Add(14*24*time.Hour).UTC().Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		t.Fatalf("failed to insert lending: %v", err)
+	}
+}
+
+func TestAssignBookSeries_WithSameTitle_ReusesSeries(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	first, err := AssignBookSeries(ctx, queries, insertBook(t, db, "進撃の巨人（1）"), &book.BookSeries{Title: "進撃の巨人", Volume: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := AssignBookSeries(ctx, queries, insertBook(t, db, "進撃の巨人（2）"), &book.BookSeries{Title: "進撃の巨人", Volume: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first.ID != second.ID {
+		t.Errorf("expected the same series, got %s and %s", first.ID, second.ID)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM series_events`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 series, got %d", count)
+	}
+}
+
+func TestAssignDetectedSeries_WithoutVolume_RecordsNothing(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	series, err := AssignDetectedSeries(ctx, queries, insertBook(t, db, "Java 17"), "Java 17", "")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if series != nil {
+		t.Errorf("expected nil, got %+v", series)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM book_events WHERE event_type = 'series_changed'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("expected no series_changed event, got %d", count)
+	}
+}
+
+func TestGetSeries_ReturnsVolumesAndNextVolume(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	var books []string
+	for _, title := range []string{"進撃の巨人（1）", "進撃の巨人（2）", "進撃の巨人（4）"} {
+		bookID := insertBook(t, db, title)
+		if _, err := AssignDetectedSeries(ctx, queries, bookID, title, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		books = append(books, bookID)
+	}
+	insertLending(t, db, books[0], "user-1", "borrowed")
+	insertLending(t, db, books[0], "user-1", "returned")
+	insertLending(t, db, books[1], "user-2", "borrowed")
+	seriesID, err := queries.GetSeriesIdByTitle(ctx, "進撃の巨人")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := GetSeries(ctx, queries, GetSeriesInput{SeriesID: seriesID, UserID: "user-1"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statuses := []domain.VolumeStatus{}
+	for _, v := range output.Volumes {
+		statuses = append(statuses, v.Status)
+	}
+	expected := []domain.VolumeStatus{domain.VolumeStatusAvailable, domain.VolumeStatusBorrowed, domain.VolumeStatusMissing, domain.VolumeStatusAvailable}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected %v, got %v", expected, statuses)
+	}
+	if !reflect.DeepEqual(output.MissingVolumes, []int64{3}) {
+		t.Errorf("expected missing [3], got %v", output.MissingVolumes)
+	}
+	if output.NextVolume == nil || *output.NextVolume != 2 {
+		t.Errorf("expected next volume 2, got %v", output.NextVolume)
+	}
+}
+
+func TestGetSeries_WithBookTakenOutOfSeries_OmitsBook(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+	ctx := context.Background()
+
+	bookID := insertBook(t, db, "進撃の巨人（1）")
+	series, err := AssignDetectedSeries(ctx, queries, bookID, "進撃の巨人（1）", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := AssignBookSeries(ctx, queries, bookID, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output, err := GetSeries(ctx, queries, GetSeriesInput{SeriesID: series.ID, UserID: "user-1"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output.Volumes) != 0 || output.NextVolume != nil {
+		t.Errorf("expected an empty series, got %+v", output)
+	}
+}
+
+func TestGetSeries_WithUnknownSeries_ReturnsNotFound(t *testing.T) {
+	db := setupTestDB(t)
+	queries := New(db)
+
+	_, err := GetSeries(context.Background(), queries, GetSeriesInput{SeriesID: uuid.New().String(), UserID: "user-1"})
+
+	if !errors.Is(err, ErrSeriesNotFound) {
+		t.Errorf("expected ErrSeriesNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type Bucket string
+
+const (
+	BucketDay   Bucket = "day"
+	BucketWeek  Bucket = "week"
+	BucketMonth Bucket = "month"
+)
+
+var ErrInvalidBucket = errors.New("bucket must be day, week or month")
+
+// ParseBucket reads the bucket of a time series. Without it the series is
+// daily.
+func ParseBucket(s *string) (Bucket, error) {
+	if s == nil {
+		return BucketDay, nil
+	}
+	switch bucket := Bucket(*s); bucket {
+	case BucketDay, BucketWeek, BucketMonth:
+		return bucket, nil
+	}
+	return "", ErrInvalidBucket
+}
+
+// Start returns the first day of the bucket t falls in. Weeks start on Monday.
+func (b Bucket) Start(t time.Time) time.Time {
+	day := truncateToDay(t)
+	switch b {
+	case BucketWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case BucketMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func (b Bucket) next(start time.Time) time.Time {
+	switch b {
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	case BucketMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Starts returns the first day of every bucket that overlaps the range, so a
+// series has an entry for buckets without any loan. The first and last
+// buckets may extend beyond the range.
+func (b Bucket) Starts(r DateRange) []time.Time {
+	starts := []time.Time{}
+	for start := b.Start(r.From); start.Before(r.To); start = b.next(start) {
+		starts = append(starts, start)
+	}
+	return starts
+}
@@ -0,0 +1,48 @@
+//go:build small
+
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseBucket_WithUnknownBucket_ReturnsError(t *testing.T) {
+	s := "year"
+
+	_, err := ParseBucket(&s)
+
+	if !errors.Is(err, ErrInvalidBucket) {
+		t.Errorf("expected ErrInvalidBucket, got %v", err)
+	}
+}
+
+func TestBucketStart_WithWeek_ReturnsMonday(t *testing.T) {
+	tests := []struct {
+		day      time.Time
+		expected time.Time
+	}{
+		{day: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), expected: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{day: time.Date(2024, 1, 7, 23, 0, 0, 0, time.UTC), expected: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{day: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), expected: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := BucketWeek.Start(tt.day); !got.Equal(tt.expected) {
+			t.Errorf("Start(%v): expected %v, got %v", tt.day, tt.expected, got)
+		}
+	}
+}
+
+func TestBucketStarts_WithMonth_ReturnsEveryOverlappingMonth(t *testing.T) {
+	r := DateRange{From: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)}
+
+	starts := BucketMonth.Starts(r)
+
+	if len(starts) != 3 {
+		t.Fatalf("expected 3 months, got %v", starts)
+	}
+	if !starts[0].Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !starts[2].Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected months %v", starts)
+	}
+}
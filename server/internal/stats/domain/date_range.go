@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	DefaultRangeDays = 30
+	MaxRangeDays     = 366
+)
+
+var ErrInvalidRange = errors.New("from must not be after to and the range must be at most 366 days")
+
+// DateRange is a range of whole days in UTC. From is the first day and To is
+// the start of the day after the last one.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseDateRange reads the first and last day of a range, both inclusive.
+// Without the last day the range ends today, and without the first day it
+// covers the 30 days up to the last one.
+func ParseDateRange(from, to *time.Time, now time.Time) (DateRange, error) {
+	last := truncateToDay(now)
+	if to != nil {
+		last = truncateToDay(*to)
+	}
+	first := last.AddDate(0, 0, 1-DefaultRangeDays)
+	if from != nil {
+		first = truncateToDay(*from)
+	}
+	if first.After(last) || last.Sub(first) >= MaxRangeDays*24*time.Hour {
+		return DateRange{}, ErrInvalidRange
+	}
+	return DateRange{From: first, To: last.AddDate(0, 0, 1)}, nil
+}
+
+// LastDay returns the last day in the range.
+func (r DateRange) LastDay() time.Time {
+	return r.To.AddDate(0, 0, -1)
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,58 @@
+//go:build small
+
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDateRange_WithoutDays_ReturnsLast30DaysUpToToday(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+	r, err := ParseDateRange(nil, nil, now)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !r.From.Equal(time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)) || !r.To.Equal(time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected range %v to %v", r.From, r.To)
+	}
+	if !r.LastDay().Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the last day to be today, got %v", r.LastDay())
+	}
+}
+
+func TestParseDateRange_WithSameDay_ReturnsOneDay(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r, err := ParseDateRange(&day, &day, time.Now())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.To.Sub(r.From) != 24*time.Hour {
+		t.Errorf("expected one day, got %v", r.To.Sub(r.From))
+	}
+}
+
+func TestParseDateRange_WithInvalidRange_ReturnsError(t *testing.T) {
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+	}{
+		{name: "from after to", from: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{name: "longer than 366 days", from: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), to: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDateRange(&tt.from, &tt.to, time.Now())
+
+			if !errors.Is(err, ErrInvalidRange) {
+				t.Errorf("expected ErrInvalidRange, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+// OverdueRate returns the share of loans that came due and were overdue, or
+// nil when no loan came due.
+func OverdueRate(dueLoans, overdueLoans int64) *float64 {
+	if dueLoans == 0 {
+		return nil
+	}
+	rate := float64(overdueLoans) / float64(dueLoans)
+	return &rate
+}
+
+// ToTopLimit reads the number of entries of a ranking: 10 unless 1 to 100 is
+// given.
+func ToTopLimit(limit *int) int {
+	if limit != nil && *limit > 0 && *limit <= 100 {
+		return *limit
+	}
+	return 10
+}
+
+// ToPage reads limit and offset the way the book list does: a limit out of 1
+// to 100 falls back to 20 and a negative offset to 0.
+func ToPage(limit, offset *int) (int, int) {
+	l, o := 20, 0
+	if limit != nil && *limit > 0 && *limit <= 100 {
+		l = *limit
+	}
+	if offset != nil && *offset >= 0 {
+		o = *offset
+	}
+	return l, o
+}
@@ -0,0 +1,19 @@
+//go:build small
+
+package domain
+
+import "testing"
+
+func TestOverdueRate_WithoutDueLoans_ReturnsNil(t *testing.T) {
+	if rate := OverdueRate(0, 0); rate != nil {
+		t.Errorf("expected nil, got %v", *rate)
+	}
+}
+
+func TestOverdueRate_WithDueLoans_ReturnsShareOfOverdueLoans(t *testing.T) {
+	rate := OverdueRate(4, 1)
+
+	if rate == nil || *rate != 0.25 {
+		t.Errorf("expected 0.25, got %v", rate)
+	}
+}
@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"holocron/internal/calendar"
+	lendingDomain "holocron/internal/lending/domain"
+	"holocron/internal/projection"
+)
+
+// LoanStatsProjection maintains loan_stats, one row per loan, from
+// lending_events so the statistics do not fold every event on each request.
+// Like the book search index it tracks the rowid of the last applied event
+// and catches up with the events appended since.
+type LoanStatsProjection struct {
+	*projection.Projection
+}
+
+func NewLoanStatsProjection(db *sql.DB, cal calendar.Calendar) *LoanStatsProjection {
+	return &LoanStatsProjection{projection.New(db, loanStatsReadModel{queries: New(db), calendar: cal})}
+}
+
+type loanStatsReadModel struct {
+	queries  *Queries
+	calendar calendar.Calendar
+}
+
+func (m loanStatsReadModel) Position(ctx context.Context, tx *sql.Tx) (int64, error) {
+	return m.queries.WithTx(tx).GetLoanStatsPosition(ctx)
+}
+
+func (m loanStatsReadModel) SetPosition(ctx context.Context, tx *sql.Tx, rowid int64) error {
+	return m.queries.WithTx(tx).SetLoanStatsPosition(ctx, rowid)
+}
+
+func (m loanStatsReadModel) LatestRowid(ctx context.Context, tx *sql.Tx) (int64, error) {
+	return m.queries.WithTx(tx).GetLatestLendingEventRowid(ctx)
+}
+
+func (m loanStatsReadModel) Reset(ctx context.Context, tx *sql.Tx, latest int64) (int64, error) {
+	return 0, m.queries.WithTx(tx).DeleteAllLoanStats(ctx)
+}
+
+func (m loanStatsReadModel) Apply(ctx context.Context, tx *sql.Tx, afterRowid, untilRowid int64) error {
+	qtx := m.queries.WithTx(tx)
+	events, err := qtx.ListLendingEventsBetween(ctx, ListLendingEventsBetweenParams{
+		AfterRowid: afterRowid,
+		UntilRowid: untilRowid,
+	})
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := applyLendingEvent(ctx, qtx, event, m.calendar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyLendingEvent(ctx context.Context, qtx *Queries, event ListLendingEventsBetweenRow, cal calendar.Calendar) error {
+	switch event.EventType {
+	case "borrowed", "lent":
+		dueDate := event.DueDate.String
+		if !event.DueDate.Valid {
+			borrowedAt, err := time.Parse(time.RFC3339, event.OccurredAt)
+			if err != nil {
+				return err
+			}
+			dueDate = lendingDomain.LegacyDueDate(borrowedAt, cal).Format(time.RFC3339)
+		}
+		return qtx.UpsertLoanStat(ctx, UpsertLoanStatParams{
+			LendingID:  event.LendingID,
+			BookID:     event.BookID,
+			BorrowerID: event.BorrowerID,
+			BorrowedAt: event.OccurredAt,
+			DueDate:    dueDate,
+		})
+	case "due_date_extended":
+		if !event.DueDate.Valid {
+			return nil
+		}
+		return qtx.UpdateLoanStatDueDate(ctx, UpdateLoanStatDueDateParams{
+			DueDate:   event.DueDate.String,
+			LendingID: event.LendingID,
+		})
+	case "returned", "force_returned", "transferred", "lost":
+		return qtx.CloseLoanStat(ctx, CloseLoanStatParams{
+			ClosedAt:  sql.NullString{String: event.OccurredAt, Valid: true},
+			CloseType: sql.NullString{String: event.EventType, Valid: true},
+			LendingID: event.LendingID,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"holocron/internal/api"
+	"holocron/internal/auth"
+	"holocron/internal/stats/domain"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type SummaryHandler struct {
+	service *StatsService
+	roles   auth.Roles
+}
+
+func NewSummaryHandler(service *StatsService, roles auth.Roles) *SummaryHandler {
+	return &SummaryHandler{service: service, roles: roles}
+}
+
+func (h *SummaryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.GetStatsSummaryParams) {
+	if _, ok := librarianID(w, r, h.roles); !ok {
+		return
+	}
+
+	summary, err := h.service.Summary(r.Context(), toRangeInput(params.From, params.To))
+	if err != nil {
+		writeStatsError(w, err)
+		return
+	}
+
+	response := rangeResponse(summary.Range)
+	response["loans"] = summary.Loans
+	response["activeBorrowers"] = summary.ActiveBorrowers
+	response["closedLoans"] = summary.ClosedLoans
+	response["dueLoans"] = summary.DueLoans
+	response["overdueLoans"] = summary.OverdueLoans
+	response["booksNotBorrowed"] = summary.BooksNotBorrowed
+	if summary.AverageLoanDays != nil {
+		response["averageLoanDays"] = *summary.AverageLoanDays
+	}
+	if summary.OverdueRate != nil {
+		response["overdueRate"] = *summary.OverdueRate
+	}
+	writeJSON(w, response)
+}
+
+type MostBorrowedBooksHandler struct {
+	service *StatsService
+	roles   auth.Roles
+}
+
+func NewMostBorrowedBooksHandler(service *StatsService, roles auth.Roles) *MostBorrowedBooksHandler {
+	return &MostBorrowedBooksHandler{service: service, roles: roles}
+}
+
+func (h *MostBorrowedBooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.GetStatsBooksMostBorrowedParams) {
+	if _, ok := librarianID(w, r, h.roles); !ok {
+		return
+	}
+
+	dateRange, books, err := h.service.MostBorrowedBooks(r.Context(), toRangeInput(params.From, params.To), params.Limit)
+	if err != nil {
+		writeStatsError(w, err)
+		return
+	}
+
+	items := make([]map[string]any, 0, len(books))
+	for _, book := range books {
+		item := map[string]any{
+			"bookId": book.BookID,
+			"loans":  book.Loans,
+		}
+		if book.Title != nil {
+			item["title"] = *book.Title
+		}
+		items = append(items, item)
+	}
+	response := rangeResponse(dateRange)
+	response["items"] = items
+	writeJSON(w, response)
+}
+
+type ActiveBorrowersHandler struct {
+	service *StatsService
+	roles   auth.Roles
+}
+
+func NewActiveBorrowersHandler(service *StatsService, roles auth.Roles) *ActiveBorrowersHandler {
+	return &ActiveBorrowersHandler{service: service, roles: roles}
+}
+
+func (h *ActiveBorrowersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.GetStatsBorrowersActiveParams) {
+	if _, ok := librarianID(w, r, h.roles); !ok {
+		return
+	}
+
+	dateRange, borrowers, err := h.service.ActiveBorrowers(r.Context(), toRangeInput(params.From, params.To), params.Limit)
+	if err != nil {
+		writeStatsError(w, err)
+		return
+	}
+
+	items := make([]map[string]any, 0, len(borrowers))
+	for _, borrower := range borrowers {
+		item := map[string]any{
+			"userId": borrower.UserID,
+			"loans":  borrower.Loans,
+		}
+		if borrower.Name != nil {
+			item["name"] = *borrower.Name
+		}
+		items = append(items, item)
+	}
+	response := rangeResponse(dateRange)
+	response["items"] = items
+	writeJSON(w, response)
+}
+
+type BooksNotBorrowedHandler struct {
+	service *StatsService
+	roles   auth.Roles
+}
+
+func NewBooksNotBorrowedHandler(service *StatsService, roles auth.Roles) *BooksNotBorrowedHandler {
+	return &BooksNotBorrowedHandler{service: service, roles: roles}
+}
+
+func (h *BooksNotBorrowedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.GetStatsBooksNotBorrowedParams) {
+	if _, ok := librarianID(w, r, h.roles); !ok {
+		return
+	}
+
+	output, err := h.service.IdleBooks(r.Context(), IdleBooksInput{
+		RangeInput: toRangeInput(params.From, params.To),
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+	})
+	if err != nil {
+		writeStatsError(w, err)
+		return
+	}
+
+	items := make([]map[string]any, 0, len(output.Items))
+	for _, book := range output.Items {
+		item := map[string]any{"bookId": book.BookID}
+		if book.Title != nil {
+			item["title"] = *book.Title
+		}
+		if book.LastBorrowedAt != nil {
+			item["lastBorrowedAt"] = book.LastBorrowedAt.Format(time.RFC3339)
+		}
+		items = append(items, item)
+	}
+	response := rangeResponse(output.Range)
+	response["items"] = items
+	response["total"] = output.Total
+	response["limit"] = output.Limit
+	response["offset"] = output.Offset
+	writeJSON(w, response)
+}
+
+type TimeSeriesHandler struct {
+	service *StatsService
+	roles   auth.Roles
+}
+
+func NewTimeSeriesHandler(service *StatsService, roles auth.Roles) *TimeSeriesHandler {
+	return &TimeSeriesHandler{service: service, roles: roles}
+}
+
+func (h *TimeSeriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, params api.GetStatsTimeseriesParams) {
+	if _, ok := librarianID(w, r, h.roles); !ok {
+		return
+	}
+
+	var bucket *string
+	if params.Bucket != nil {
+		s := string(*params.Bucket)
+		bucket = &s
+	}
+	output, err := h.service.TimeSeries(r.Context(), TimeSeriesInput{
+		RangeInput: toRangeInput(params.From, params.To),
+		Bucket:     bucket,
+	})
+	if err != nil {
+		writeStatsError(w, err)
+		return
+	}
+
+	items := make([]map[string]any, 0, len(output.Points))
+	for _, point := range output.Points {
+		items = append(items, map[string]any{
+			"start":       point.Start.Format(time.DateOnly),
+			"loans":       point.Loans,
+			"borrowers":   point.Borrowers,
+			"returns":     point.Returns,
+			"lateReturns": point.LateReturns,
+		})
+	}
+	response := rangeResponse(output.Range)
+	response["bucket"] = string(output.Bucket)
+	response["items"] = items
+	writeJSON(w, response)
+}
+
+func toRangeInput(from, to *openapi_types.Date) RangeInput {
+	var input RangeInput
+	if from != nil {
+		input.From = &from.Time
+	}
+	if to != nil {
+		input.To = &to.Time
+	}
+	return input
+}
+
+func rangeResponse(r domain.DateRange) map[string]any {
+	return map[string]any{
+		"from": r.From.Format(time.DateOnly),
+		"to":   r.LastDay().Format(time.DateOnly),
+	}
+}
+
+func writeStatsError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidRange):
+		writeError(w, http.StatusBadRequest, "invalid_request", "from must not be after to and the range must be at most 366 days")
+	case errors.Is(err, domain.ErrInvalidBucket):
+		writeError(w, http.StatusBadRequest, "invalid_request", "bucket must be day, week or month")
+	default:
+		writeError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, response map[string]any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// librarianID returns the ID of the authenticated user, writing an error
+// response unless the user is a librarian.
+func librarianID(w http.ResponseWriter, r *http.Request, roles auth.Roles) (string, bool) {
+	userID, ok := auth.UserIDFromContext(r.Context())
+	if !ok || userID == "" {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "authentication required")
+		return "", false
+	}
+	if !roles.IsLibrarian(userID) {
+		writeError(w, http.StatusForbidden, "forbidden", "librarian role is required")
+		return "", false
+	}
+	return userID, true
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,287 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"holocron/internal/calendar"
+	"holocron/internal/stats/domain"
+)
+
+type RangeInput struct {
+	// From and To are the first and last day of the range. Only the date is used.
+	From *time.Time
+	To   *time.Time
+}
+
+type LoanSummary struct {
+	Range            domain.DateRange
+	Loans            int64
+	ActiveBorrowers  int64
+	ClosedLoans      int64
+	AverageLoanDays  *float64
+	DueLoans         int64
+	OverdueLoans     int64
+	OverdueRate      *float64
+	BooksNotBorrowed int64
+}
+
+type BookLoanCount struct {
+	BookID string
+	Title  *string
+	Loans  int64
+}
+
+type BorrowerLoanCount struct {
+	UserID string
+	Name   *string
+	Loans  int64
+}
+
+type IdleBook struct {
+	BookID string
+	Title  *string
+	// LastBorrowedAt is the last loan before the range, nil when the book has
+	// never been borrowed.
+	LastBorrowedAt *time.Time
+}
+
+type IdleBooksInput struct {
+	RangeInput
+	Limit  *int
+	Offset *int
+}
+
+type IdleBooksOutput struct {
+	Range  domain.DateRange
+	Items  []IdleBook
+	Total  int64
+	Limit  int
+	Offset int
+}
+
+type TimeSeriesInput struct {
+	RangeInput
+	Bucket *string
+}
+
+type TimeSeriesPoint struct {
+	Start       time.Time
+	Loans       int64
+	Borrowers   int64
+	Returns     int64
+	LateReturns int64
+}
+
+type TimeSeriesOutput struct {
+	Range  domain.DateRange
+	Bucket domain.Bucket
+	Points []TimeSeriesPoint
+}
+
+// StatsService answers the lending statistics from the loan_stats projection,
+// catching it up before each query.
+type StatsService struct {
+	queries    *Queries
+	projection *LoanStatsProjection
+	calendar   calendar.Calendar
+	now        func() time.Time
+}
+
+func NewStatsService(queries *Queries, projection *LoanStatsProjection, cal calendar.Calendar) *StatsService {
+	return &StatsService{
+		queries:    queries,
+		projection: projection,
+		calendar:   cal,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (s *StatsService) prepare(ctx context.Context, input RangeInput) (domain.DateRange, error) {
+	r, err := domain.ParseDateRange(input.From, input.To, s.now())
+	if err != nil {
+		return domain.DateRange{}, err
+	}
+	if err := s.projection.CatchUp(ctx); err != nil {
+		return domain.DateRange{}, err
+	}
+	return r, nil
+}
+
+func (s *StatsService) Summary(ctx context.Context, input RangeInput) (*LoanSummary, error) {
+	r, err := s.prepare(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	from, to := r.From.Format(time.RFC3339), r.To.Format(time.RFC3339)
+
+	row, err := s.queries.GetLoanSummary(ctx, GetLoanSummaryParams{
+		FromAt: from,
+		ToAt:   to,
+		Now:    s.calendar.OverdueCutoff(s.now()).Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	notBorrowed, err := s.queries.CountBooksNotBorrowed(ctx, CountBooksNotBorrowedParams{FromAt: from, ToAt: to})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &LoanSummary{
+		Range:            r,
+		Loans:            row.Loans,
+		ActiveBorrowers:  row.Borrowers,
+		ClosedLoans:      row.ClosedLoans,
+		DueLoans:         row.DueLoans,
+		OverdueLoans:     row.OverdueLoans,
+		OverdueRate:      domain.OverdueRate(row.DueLoans, row.OverdueLoans),
+		BooksNotBorrowed: notBorrowed,
+	}
+	if row.AverageLoanDays.Valid {
+		days := row.AverageLoanDays.Float64
+		summary.AverageLoanDays = &days
+	}
+	return summary, nil
+}
+
+func (s *StatsService) MostBorrowedBooks(ctx context.Context, input RangeInput, limit *int) (domain.DateRange, []BookLoanCount, error) {
+	r, err := s.prepare(ctx, input)
+	if err != nil {
+		return domain.DateRange{}, nil, err
+	}
+	rows, err := s.queries.ListMostBorrowedBooks(ctx, ListMostBorrowedBooksParams{
+		FromAt: r.From.Format(time.RFC3339),
+		ToAt:   r.To.Format(time.RFC3339),
+		Limit:  int64(domain.ToTopLimit(limit)),
+	})
+	if err != nil {
+		return domain.DateRange{}, nil, err
+	}
+	books := make([]BookLoanCount, 0, len(rows))
+	for _, row := range rows {
+		books = append(books, BookLoanCount{BookID: row.BookID, Title: nullStringToPtr(row.Title), Loans: row.Loans})
+	}
+	return r, books, nil
+}
+
+func (s *StatsService) ActiveBorrowers(ctx context.Context, input RangeInput, limit *int) (domain.DateRange, []BorrowerLoanCount, error) {
+	r, err := s.prepare(ctx, input)
+	if err != nil {
+		return domain.DateRange{}, nil, err
+	}
+	rows, err := s.queries.ListActiveBorrowers(ctx, ListActiveBorrowersParams{
+		FromAt: r.From.Format(time.RFC3339),
+		ToAt:   r.To.Format(time.RFC3339),
+		Limit:  int64(domain.ToTopLimit(limit)),
+	})
+	if err != nil {
+		return domain.DateRange{}, nil, err
+	}
+	borrowers := make([]BorrowerLoanCount, 0, len(rows))
+	for _, row := range rows {
+		borrowers = append(borrowers, BorrowerLoanCount{UserID: row.BorrowerID, Name: nullStringToPtr(row.Name), Loans: row.Loans})
+	}
+	return r, borrowers, nil
+}
+
+// IdleBooks lists the books nobody borrowed in the range, the ones never
+// borrowed at all first.
+func (s *StatsService) IdleBooks(ctx context.Context, input IdleBooksInput) (*IdleBooksOutput, error) {
+	r, err := s.prepare(ctx, input.RangeInput)
+	if err != nil {
+		return nil, err
+	}
+	limit, offset := domain.ToPage(input.Limit, input.Offset)
+	from, to := r.From.Format(time.RFC3339), r.To.Format(time.RFC3339)
+
+	total, err := s.queries.CountBooksNotBorrowed(ctx, CountBooksNotBorrowedParams{FromAt: from, ToAt: to})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListBooksNotBorrowed(ctx, ListBooksNotBorrowedParams{
+		ToAt:   to,
+		FromAt: from,
+		Limit:  int64(limit),
+		Offset: int64(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]IdleBook, 0, len(rows))
+	for _, row := range rows {
+		book := IdleBook{BookID: row.BookID, Title: nullStringToPtr(row.Title)}
+		if lastBorrowedAt, ok := row.LastBorrowedAt.(string); ok {
+			t, err := time.Parse(time.RFC3339, lastBorrowedAt)
+			if err != nil {
+				return nil, err
+			}
+			book.LastBorrowedAt = &t
+		}
+		items = append(items, book)
+	}
+	return &IdleBooksOutput{Range: r, Items: items, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// TimeSeries counts loans, borrowers and returns per bucket. Every bucket
+// overlapping the range is listed, including the ones without any loan.
+func (s *StatsService) TimeSeries(ctx context.Context, input TimeSeriesInput) (*TimeSeriesOutput, error) {
+	bucket, err := domain.ParseBucket(input.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.prepare(ctx, input.RangeInput)
+	if err != nil {
+		return nil, err
+	}
+	from, to := r.From.Format(time.RFC3339), r.To.Format(time.RFC3339)
+
+	starts := bucket.Starts(r)
+	points := make([]TimeSeriesPoint, len(starts))
+	index := map[string]int{}
+	for i, start := range starts {
+		points[i].Start = start
+		index[start.Format(time.DateOnly)] = i
+	}
+
+	loanRows, err := s.queries.ListLoanCountsByBucket(ctx, ListLoanCountsByBucketParams{Bucket: string(bucket), FromAt: from, ToAt: to})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range loanRows {
+		if i, ok := bucketIndex(index, row.BucketStart); ok {
+			points[i].Loans = row.Loans
+			points[i].Borrowers = row.Borrowers
+		}
+	}
+	returnRows, err := s.queries.ListReturnCountsByBucket(ctx, ListReturnCountsByBucketParams{Bucket: string(bucket), FromAt: from, ToAt: to})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range returnRows {
+		if i, ok := bucketIndex(index, row.BucketStart); ok {
+			points[i].Returns = row.Returns
+			points[i].LateReturns = row.LateReturns
+		}
+	}
+
+	return &TimeSeriesOutput{Range: r, Bucket: bucket, Points: points}, nil
+}
+
+func bucketIndex(index map[string]int, bucketStart interface{}) (int, bool) {
+	start, ok := bucketStart.(string)
+	if !ok {
+		return 0, false
+	}
+	i, ok := index[start]
+	return i, ok
+}
+
+func nullStringToPtr(ns sql.NullString) *string {
+	if !ns.Valid {
+		return nil
+	}
+	return &ns.String
+}
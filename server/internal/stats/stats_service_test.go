@@ -0,0 +1,310 @@
+//go:build medium
+
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"holocron/internal/calendar"
+	"holocron/internal/stats/domain"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE user_events (
+			event_id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			name TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE TABLE book_events (
+			event_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			code TEXT,
+			title TEXT,
+			authors TEXT,
+			publisher TEXT,
+			published_date TEXT,
+			thumbnail_url TEXT,
+			delete_reason TEXT,
+			delete_memo TEXT,
+			origin TEXT,
+			cover_id TEXT,
+			tags TEXT,
+			category_id TEXT,
+			shelf_location TEXT,
+			series_id TEXT,
+			volume_number INTEGER,
+			accession_number TEXT,
+			condition TEXT,
+			condition_note TEXT,
+			lending_id TEXT,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE TABLE lending_events (
+			event_id TEXT PRIMARY KEY,
+			lending_id TEXT NOT NULL,
+			book_id TEXT NOT NULL,
+			borrower_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			due_date TEXT,
+			actor_id TEXT,
+			reason TEXT,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE TABLE loan_stats (
+			lending_id TEXT PRIMARY KEY,
+			book_id TEXT NOT NULL,
+			borrower_id TEXT NOT NULL,
+			borrowed_at TEXT NOT NULL,
+			due_date TEXT NOT NULL,
+			closed_at TEXT,
+			close_type TEXT
+		);
+		CREATE TABLE loan_stats_state (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			last_event_rowid INTEGER NOT NULL
+		);
+	`)
+	if err != nil {
+		t.Fatalf("failed to create tables: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type statsFixture struct {
+	db         *sql.DB
+	service    *StatsService
+	projection *LoanStatsProjection
+	popular    string
+	idle       string
+	never      string
+	alice      string
+	bob        string
+}
+
+// newStatsFixture registers three books in 2023 and records loans in January
+// 2024: the popular book is borrowed three times, the idle one only in 2023
+// and the last one never.
+func newStatsFixture(t *testing.T) *statsFixture {
+	t.Helper()
+	db := setupTestDB(t)
+	projection := NewLoanStatsProjection(db, calendar.Calendar{})
+	f := &statsFixture{
+		db:         db,
+		service:    NewStatsService(New(db), projection, calendar.Calendar{}),
+		projection: projection,
+		popular:    uuid.New().String(),
+		idle:       uuid.New().String(),
+		never:      uuid.New().String(),
+		alice:      uuid.New().String(),
+		bob:        uuid.New().String(),
+	}
+	f.service.now = func() time.Time { return time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC) }
+
+	for bookID, title := range map[string]string{f.popular: "人気の本", f.idle: "昔の本", f.never: "新しい本"} {
+		f.exec(t, `INSERT INTO book_events (event_id, book_id, event_type, title, occurred_at) VALUES (?, ?, 'created', ?, '2023-06-01T00:00:00Z')`, uuid.New().String(), bookID, title)
+	}
+	f.exec(t, `INSERT INTO user_events VALUES (?, ?, 'created', 'アリス', '2023-01-01T00:00:00Z')`, uuid.New().String(), f.alice)
+	f.exec(t, `INSERT INTO user_events VALUES (?, ?, 'created', 'ボブ', '2023-01-01T00:00:00Z')`, uuid.New().String(), f.bob)
+
+	// The idle book was borrowed and returned before the range.
+	f.loan(t, f.idle, f.alice, "2023-12-01T00:00:00Z", "2023-12-08T00:00:00Z", "returned", "2023-12-05T00:00:00Z")
+	// Returned after 4 days, on time.
+	f.loan(t, f.popular, f.alice, "2024-01-01T09:00:00Z", "2024-01-08T09:00:00Z", "returned", "2024-01-05T09:00:00Z")
+	// Extended and returned late after 12 days.
+	l := f.loan(t, f.popular, f.bob, "2024-01-09T09:00:00Z", "2024-01-16T09:00:00Z", "", "")
+	f.event(t, l, f.popular, f.bob, "due_date_extended", "2024-01-18T09:00:00Z", "2024-01-15T09:00:00Z")
+	f.event(t, l, f.popular, f.bob, "returned", "", "2024-01-21T09:00:00Z")
+	// Still open past its due date.
+	f.loan(t, f.popular, f.alice, "2024-01-22T09:00:00Z", "2024-01-29T09:00:00Z", "", "")
+	return f
+}
+
+func (f *statsFixture) exec(t *testing.T, query string, args ...any) {
+	t.Helper()
+	if _, err := f.db.Exec(query, args...); err != nil {
+		t.Fatalf("precondition failed: %v", err)
+	}
+}
+
+func (f *statsFixture) event(t *testing.T, lendingID, bookID, borrowerID, eventType, dueDate, occurredAt string) {
+	t.Helper()
+	f.exec(t, `INSERT INTO lending_events (event_id, lending_id, book_id, borrower_id, event_type, due_date, occurred_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		uuid.New().String(), lendingID, bookID, borrowerID, eventType, sql.NullString{String: dueDate, Valid: dueDate != ""}, occurredAt)
+}
+
+func (f *statsFixture) loan(t *testing.T, bookID, borrowerID, borrowedAt, dueDate, closeType, closedAt string) string {
+	t.Helper()
+	lendingID := uuid.New().String()
+	f.event(t, lendingID, bookID, borrowerID, "borrowed", dueDate, borrowedAt)
+	if closeType != "" {
+		f.event(t, lendingID, bookID, borrowerID, closeType, "", closedAt)
+	}
+	return lendingID
+}
+
+func january() RangeInput {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	return RangeInput{From: &from, To: &to}
+}
+
+func TestSummary_WithLoansInRange_ReturnsAggregates(t *testing.T) {
+	f := newStatsFixture(t)
+
+	summary, err := f.service.Summary(context.Background(), january())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Loans != 3 || summary.ActiveBorrowers != 2 || summary.ClosedLoans != 2 {
+		t.Errorf("unexpected counts %+v", summary)
+	}
+	if summary.AverageLoanDays == nil || *summary.AverageLoanDays != 8 {
+		t.Errorf("expected an average of 8 days, got %v", summary.AverageLoanDays)
+	}
+	if summary.DueLoans != 3 || summary.OverdueLoans != 2 {
+		t.Errorf("expected 2 of 3 due loans overdue, got %d of %d", summary.OverdueLoans, summary.DueLoans)
+	}
+	if summary.BooksNotBorrowed != 2 {
+		t.Errorf("expected 2 books not borrowed, got %d", summary.BooksNotBorrowed)
+	}
+}
+
+func TestSummary_WithEventsAfterCatchUp_AppliesNewEvents(t *testing.T) {
+	f := newStatsFixture(t)
+	if _, err := f.service.Summary(context.Background(), january()); err != nil {
+		t.Fatalf("precondition failed: %v", err)
+	}
+	f.loan(t, f.never, f.bob, "2024-01-30T09:00:00Z", "2024-02-06T09:00:00Z", "", "")
+
+	summary, err := f.service.Summary(context.Background(), january())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Loans != 4 || summary.BooksNotBorrowed != 1 {
+		t.Errorf("expected the new loan to be counted, got %+v", summary)
+	}
+}
+
+func TestMostBorrowedBooks_WithLoans_ReturnsBooksByLoanCount(t *testing.T) {
+	f := newStatsFixture(t)
+
+	_, books, err := f.service.MostBorrowedBooks(context.Background(), january(), nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(books) != 1 || books[0].BookID != f.popular || books[0].Loans != 3 || *books[0].Title != "人気の本" {
+		t.Errorf("unexpected books %+v", books)
+	}
+}
+
+func TestActiveBorrowers_WithLoans_ReturnsBorrowersByLoanCount(t *testing.T) {
+	f := newStatsFixture(t)
+
+	_, borrowers, err := f.service.ActiveBorrowers(context.Background(), january(), nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(borrowers) != 2 || borrowers[0].UserID != f.alice || borrowers[0].Loans != 2 || *borrowers[0].Name != "アリス" {
+		t.Errorf("unexpected borrowers %+v", borrowers)
+	}
+}
+
+func TestIdleBooks_WithBooksNotBorrowed_ReturnsNeverBorrowedFirst(t *testing.T) {
+	f := newStatsFixture(t)
+
+	output, err := f.service.IdleBooks(context.Background(), IdleBooksInput{RangeInput: january()})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.Total != 2 || len(output.Items) != 2 {
+		t.Fatalf("expected 2 books, got total %d and %+v", output.Total, output.Items)
+	}
+	if output.Items[0].BookID != f.never || output.Items[0].LastBorrowedAt != nil {
+		t.Errorf("expected the book never borrowed first, got %+v", output.Items[0])
+	}
+	expected := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	if output.Items[1].BookID != f.idle || output.Items[1].LastBorrowedAt == nil || !output.Items[1].LastBorrowedAt.Equal(expected) {
+		t.Errorf("expected the idle book with its last loan, got %+v", output.Items[1])
+	}
+}
+
+func TestTimeSeries_WithWeekBucket_ReturnsEveryWeek(t *testing.T) {
+	f := newStatsFixture(t)
+	bucket := "week"
+
+	output, err := f.service.TimeSeries(context.Background(), TimeSeriesInput{RangeInput: january(), Bucket: &bucket})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// January 2024 starts on a Monday and spans five weeks.
+	if len(output.Points) != 5 {
+		t.Fatalf("expected 5 weeks, got %+v", output.Points)
+	}
+	week := func(i int) TimeSeriesPoint { return output.Points[i] }
+	if week(0).Loans != 1 || week(0).Returns != 1 || week(0).LateReturns != 0 {
+		t.Errorf("unexpected first week %+v", week(0))
+	}
+	if week(1).Loans != 1 || week(1).Returns != 0 {
+		t.Errorf("unexpected second week %+v", week(1))
+	}
+	if week(2).Returns != 1 || week(2).LateReturns != 1 {
+		t.Errorf("unexpected third week %+v", week(2))
+	}
+	if week(3).Loans != 1 || week(4).Loans != 0 {
+		t.Errorf("unexpected last weeks %+v %+v", week(3), week(4))
+	}
+}
+
+func TestTimeSeries_WithInvalidBucket_ReturnsError(t *testing.T) {
+	f := newStatsFixture(t)
+	bucket := "year"
+
+	_, err := f.service.TimeSeries(context.Background(), TimeSeriesInput{RangeInput: january(), Bucket: &bucket})
+
+	if !errors.Is(err, domain.ErrInvalidBucket) {
+		t.Errorf("expected ErrInvalidBucket, got %v", err)
+	}
+}
+
+func TestRebuild_AfterEventsReplaced_RecountsLoans(t *testing.T) {
+	f := newStatsFixture(t)
+	if err := f.projection.CatchUp(context.Background()); err != nil {
+		t.Fatalf("precondition failed: %v", err)
+	}
+	f.exec(t, `DELETE FROM lending_events`)
+
+	if err := f.projection.Rebuild(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var count int
+	if err := f.db.QueryRow(`SELECT COUNT(*) FROM loan_stats`).Scan(&count); err != nil {
+		t.Fatalf("failed to count: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected no loans, got %d", count)
+	}
+}
@@ -13,13 +13,26 @@ import (
 	"time"
 
 	"holocron/internal/api"
+	"holocron/internal/audit"
 	"holocron/internal/auth"
+	"holocron/internal/backup"
 	"holocron/internal/book"
 	bookDomain "holocron/internal/book/domain"
 	"holocron/internal/bookcode"
 	bookcodeDomain "holocron/internal/bookcode/domain"
 	"holocron/internal/books"
+	"holocron/internal/bulkimport"
+	"holocron/internal/calendar"
+	"holocron/internal/category"
+	"holocron/internal/cover"
+	"holocron/internal/cursor"
+	"holocron/internal/export"
+	"holocron/internal/feed"
+	"holocron/internal/label"
 	"holocron/internal/lending"
+	"holocron/internal/notification"
+	"holocron/internal/series"
+	"holocron/internal/stats"
 	"holocron/internal/tracing"
 	"holocron/internal/user"
 
@@ -30,16 +43,63 @@ import (
 )
 
 type server struct {
-	createUserHandler       *user.CreateUserHandler
-	getMyBorrowingHandler   *user.GetMyBorrowingHandler
-	createBookHandler       *books.CreateBookHandler
-	createBookByCodeHandler *bookcode.CreateBookByCodeHandler
-	listBooksHandler        *books.ListBooksHandler
-	getBookHandler          *book.GetBookHandler
-	updateBookHandler       *book.UpdateBookHandler
-	deleteBookHandler       *book.DeleteBookHandler
-	borrowBookHandler       *lending.BorrowBookHandler
-	returnBookHandler       *lending.ReturnBookHandler
+	createUserHandler          *user.CreateUserHandler
+	getMyBorrowingHandler      *user.GetMyBorrowingHandler
+	issueFeedHandler           *feed.IssueFeedHandler
+	revokeFeedHandler          *feed.RevokeFeedHandler
+	dueDatesFeedHandler        *feed.DueDatesFeedHandler
+	listNotificationsHandler   *notification.ListNotificationsHandler
+	readNotificationHandler    *notification.MarkNotificationReadHandler
+	markAllReadHandler         *notification.MarkAllNotificationsReadHandler
+	deleteNotificationHandler  *notification.DeleteNotificationHandler
+	getPreferencesHandler      *notification.GetNotificationPreferencesHandler
+	updatePreferencesHandler   *notification.UpdateNotificationPreferencesHandler
+	createBookHandler          *books.CreateBookHandler
+	createBookByCodeHandler    *bookcode.CreateBookByCodeHandler
+	getBookInfoHandler         *bookcode.GetBookInfoHandler
+	importBooksHandler         *bulkimport.ImportBooksHandler
+	exportBooksHandler         *export.ExportBooksHandler
+	refreshAllMetadataHandler  *bookcode.RefreshAllBookMetadataHandler
+	refreshBookMetadataHandler *bookcode.RefreshBookMetadataHandler
+	uploadCoverHandler         *cover.UploadCoverHandler
+	getCoverHandler            *cover.GetCoverHandler
+	listBooksHandler           *books.ListBooksHandler
+	getBookHandler             *book.GetBookHandler
+	updateBookHandler          *book.UpdateBookHandler
+	deleteBookHandler          *book.DeleteBookHandler
+	setBookTagsHandler         *book.SetBookTagsHandler
+	setBookCategoryHandler     *book.SetBookCategoryHandler
+	setBookShelfHandler        *book.SetBookShelfLocationHandler
+	assignAccessionHandler     *book.AssignAccessionNumberHandler
+	listBookConditionsHandler  *book.ListBookConditionsHandler
+	getLabelHandler            *label.GetLabelHandler
+	getLabelSheetHandler       *label.GetLabelSheetHandler
+	listCategoriesHandler      *category.ListCategoriesHandler
+	createCategoryHandler      *category.CreateCategoryHandler
+	seedCategoriesHandler      *category.SeedCategoriesHandler
+	getSeriesHandler           *series.GetSeriesHandler
+	borrowBookHandler          *lending.BorrowBookHandler
+	returnBookHandler          *lending.ReturnBookHandler
+	scanLendingHandler         *lending.ScanHandler
+	batchBorrowHandler         *lending.BatchBorrowHandler
+	batchReturnHandler         *lending.BatchReturnHandler
+	lendBookHandler            *lending.LendBookHandler
+	forceReturnHandler         *lending.ForceReturnHandler
+	transferLoanHandler        *lending.TransferLoanHandler
+	reportLostHandler          *lending.ReportLostHandler
+	listMyLendingsHandler      *lending.ListMyLendingsHandler
+	listBookLendingsHandler    *lending.ListBookLendingsHandler
+	startAuditHandler          *audit.StartAuditHandler
+	getAuditHandler            *audit.GetAuditHandler
+	scanAuditHandler           *audit.ScanAuditHandler
+	finishAuditHandler         *audit.FinishAuditHandler
+	statsSummaryHandler        *stats.SummaryHandler
+	mostBorrowedBooksHandler   *stats.MostBorrowedBooksHandler
+	activeBorrowersHandler     *stats.ActiveBorrowersHandler
+	booksNotBorrowedHandler    *stats.BooksNotBorrowedHandler
+	statsTimeSeriesHandler     *stats.TimeSeriesHandler
+	backupHandler              *backup.BackupHandler
+	restoreHandler             *backup.RestoreHandler
 }
 
 func (s *server) GetBooks(w http.ResponseWriter, r *http.Request, params api.GetBooksParams) {
@@ -51,6 +111,27 @@ func (s *server) PostBooks(w http.ResponseWriter, r *http.Request) {
 func (s *server) PostBooksCode(w http.ResponseWriter, r *http.Request) {
 	s.createBookByCodeHandler.ServeHTTP(w, r)
 }
+func (s *server) PostBooksImport(w http.ResponseWriter, r *http.Request, params api.PostBooksImportParams) {
+	s.importBooksHandler.ServeHTTP(w, r, params)
+}
+func (s *server) GetExportBooks(w http.ResponseWriter, r *http.Request, params api.GetExportBooksParams) {
+	s.exportBooksHandler.ServeHTTP(w, r, params)
+}
+func (s *server) GetBookInfo(w http.ResponseWriter, r *http.Request, code string) {
+	s.getBookInfoHandler.ServeHTTP(w, r, code)
+}
+func (s *server) PostMetadataRefresh(w http.ResponseWriter, r *http.Request) {
+	s.refreshAllMetadataHandler.ServeHTTP(w, r)
+}
+func (s *server) PostBooksMetadataRefresh(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	s.refreshBookMetadataHandler.ServeHTTP(w, r, bookId)
+}
+func (s *server) GetBooksCover(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID, params api.GetBooksCoverParams) {
+	s.getCoverHandler.ServeHTTP(w, r, bookId, params)
+}
+func (s *server) PutBooksCover(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	s.uploadCoverHandler.ServeHTTP(w, r, bookId)
+}
 func (s *server) GetBook(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
 	s.getBookHandler.ServeHTTP(w, r, bookId)
 }
@@ -60,12 +141,71 @@ func (s *server) PostBooksBookId(w http.ResponseWriter, r *http.Request, bookId
 func (s *server) DeleteBook(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
 	s.deleteBookHandler.ServeHTTP(w, r, bookId)
 }
-func (s *server) PostBooksBorrow(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+func (s *server) PutBooksTags(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	s.setBookTagsHandler.ServeHTTP(w, r, bookId)
+}
+func (s *server) PutBooksCategory(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	s.setBookCategoryHandler.ServeHTTP(w, r, bookId)
+}
+func (s *server) PutBooksShelf(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	s.setBookShelfHandler.ServeHTTP(w, r, bookId)
+}
+func (s *server) PostBooksAccessionNumber(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	s.assignAccessionHandler.ServeHTTP(w, r, bookId)
+}
+func (s *server) GetBooksConditions(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+	s.listBookConditionsHandler.ServeHTTP(w, r, bookId)
+}
+func (s *server) GetBooksLabel(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID, params api.GetBooksLabelParams) {
+	s.getLabelHandler.ServeHTTP(w, r, bookId, params)
+}
+func (s *server) GetBooksLabels(w http.ResponseWriter, r *http.Request, params api.GetBooksLabelsParams) {
+	s.getLabelSheetHandler.ServeHTTP(w, r, params)
+}
+func (s *server) PostBooksBorrow(w http.ResponseWriter, r *http.Request, bookId string) {
 	s.borrowBookHandler.ServeHTTP(w, r)
 }
-func (s *server) PostBooksReturn(w http.ResponseWriter, r *http.Request, bookId openapi_types.UUID) {
+func (s *server) PostBooksReturn(w http.ResponseWriter, r *http.Request, bookId string) {
 	s.returnBookHandler.ServeHTTP(w, r)
 }
+func (s *server) PostBooksLend(w http.ResponseWriter, r *http.Request, bookId string) {
+	s.lendBookHandler.ServeHTTP(w, r)
+}
+func (s *server) PostBooksForceReturn(w http.ResponseWriter, r *http.Request, bookId string) {
+	s.forceReturnHandler.ServeHTTP(w, r)
+}
+func (s *server) PostBooksTransfer(w http.ResponseWriter, r *http.Request, bookId string) {
+	s.transferLoanHandler.ServeHTTP(w, r)
+}
+func (s *server) PostBooksReportLost(w http.ResponseWriter, r *http.Request, bookId string) {
+	s.reportLostHandler.ServeHTTP(w, r)
+}
+func (s *server) GetBooksLendings(w http.ResponseWriter, r *http.Request, bookId string, params api.GetBooksLendingsParams) {
+	s.listBookLendingsHandler.ServeHTTP(w, r, bookId, params)
+}
+func (s *server) PostLendingScan(w http.ResponseWriter, r *http.Request) {
+	s.scanLendingHandler.ServeHTTP(w, r)
+}
+func (s *server) PostLendingBorrow(w http.ResponseWriter, r *http.Request) {
+	s.batchBorrowHandler.ServeHTTP(w, r)
+}
+func (s *server) PostLendingReturn(w http.ResponseWriter, r *http.Request) {
+	s.batchReturnHandler.ServeHTTP(w, r)
+}
+
+func (s *server) GetCategories(w http.ResponseWriter, r *http.Request) {
+	s.listCategoriesHandler.ServeHTTP(w, r)
+}
+func (s *server) PostCategories(w http.ResponseWriter, r *http.Request) {
+	s.createCategoryHandler.ServeHTTP(w, r)
+}
+func (s *server) PostCategoriesSeed(w http.ResponseWriter, r *http.Request) {
+	s.seedCategoriesHandler.ServeHTTP(w, r)
+}
+
+func (s *server) GetSeries(w http.ResponseWriter, r *http.Request, seriesId openapi_types.UUID) {
+	s.getSeriesHandler.ServeHTTP(w, r, seriesId)
+}
 
 func (s *server) PostUsers(w http.ResponseWriter, r *http.Request) {
 	s.createUserHandler.ServeHTTP(w, r)
@@ -74,6 +214,73 @@ func (s *server) PostUsers(w http.ResponseWriter, r *http.Request) {
 func (s *server) GetUsersMeBorrowings(w http.ResponseWriter, r *http.Request) {
 	s.getMyBorrowingHandler.ServeHTTP(w, r)
 }
+func (s *server) GetUsersMeLendings(w http.ResponseWriter, r *http.Request, params api.GetUsersMeLendingsParams) {
+	s.listMyLendingsHandler.ServeHTTP(w, r, params)
+}
+func (s *server) PostUsersMeCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	s.issueFeedHandler.ServeHTTP(w, r)
+}
+func (s *server) DeleteUsersMeCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	s.revokeFeedHandler.ServeHTTP(w, r)
+}
+func (s *server) GetFeedsDueDates(w http.ResponseWriter, r *http.Request, token string) {
+	s.dueDatesFeedHandler.ServeHTTP(w, r, token)
+}
+
+func (s *server) GetUsersMeNotifications(w http.ResponseWriter, r *http.Request, params api.GetUsersMeNotificationsParams) {
+	s.listNotificationsHandler.ServeHTTP(w, r, params)
+}
+func (s *server) PostUsersMeNotificationsRead(w http.ResponseWriter, r *http.Request, notificationId openapi_types.UUID) {
+	s.readNotificationHandler.ServeHTTP(w, r, notificationId)
+}
+func (s *server) PostUsersMeNotificationsReadAll(w http.ResponseWriter, r *http.Request) {
+	s.markAllReadHandler.ServeHTTP(w, r)
+}
+func (s *server) DeleteUsersMeNotification(w http.ResponseWriter, r *http.Request, notificationId openapi_types.UUID) {
+	s.deleteNotificationHandler.ServeHTTP(w, r, notificationId)
+}
+func (s *server) GetUsersMeNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	s.getPreferencesHandler.ServeHTTP(w, r)
+}
+func (s *server) PutUsersMeNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	s.updatePreferencesHandler.ServeHTTP(w, r)
+}
+
+func (s *server) PostAudits(w http.ResponseWriter, r *http.Request) {
+	s.startAuditHandler.ServeHTTP(w, r)
+}
+func (s *server) GetAudit(w http.ResponseWriter, r *http.Request, auditId openapi_types.UUID) {
+	s.getAuditHandler.ServeHTTP(w, r, auditId)
+}
+func (s *server) PostAuditsScans(w http.ResponseWriter, r *http.Request, auditId openapi_types.UUID) {
+	s.scanAuditHandler.ServeHTTP(w, r, auditId)
+}
+func (s *server) PostAuditsFinish(w http.ResponseWriter, r *http.Request, auditId openapi_types.UUID) {
+	s.finishAuditHandler.ServeHTTP(w, r, auditId)
+}
+
+func (s *server) GetStatsSummary(w http.ResponseWriter, r *http.Request, params api.GetStatsSummaryParams) {
+	s.statsSummaryHandler.ServeHTTP(w, r, params)
+}
+func (s *server) GetStatsBooksMostBorrowed(w http.ResponseWriter, r *http.Request, params api.GetStatsBooksMostBorrowedParams) {
+	s.mostBorrowedBooksHandler.ServeHTTP(w, r, params)
+}
+func (s *server) GetStatsBorrowersActive(w http.ResponseWriter, r *http.Request, params api.GetStatsBorrowersActiveParams) {
+	s.activeBorrowersHandler.ServeHTTP(w, r, params)
+}
+func (s *server) GetStatsBooksNotBorrowed(w http.ResponseWriter, r *http.Request, params api.GetStatsBooksNotBorrowedParams) {
+	s.booksNotBorrowedHandler.ServeHTTP(w, r, params)
+}
+func (s *server) GetStatsTimeseries(w http.ResponseWriter, r *http.Request, params api.GetStatsTimeseriesParams) {
+	s.statsTimeSeriesHandler.ServeHTTP(w, r, params)
+}
+
+func (s *server) GetAdminBackup(w http.ResponseWriter, r *http.Request) {
+	s.backupHandler.ServeHTTP(w, r)
+}
+func (s *server) PostAdminRestore(w http.ResponseWriter, r *http.Request, params api.PostAdminRestoreParams) {
+	s.restoreHandler.ServeHTTP(w, r, params)
+}
 
 func initDB(database *sql.DB) error {
 	schema := `
@@ -98,9 +305,108 @@ func initDB(database *sql.DB) error {
 		thumbnail_url TEXT,
 		delete_reason TEXT,
 		delete_memo TEXT,
+		origin TEXT,
+		cover_id TEXT,
+		tags TEXT,
+		category_id TEXT,
+		shelf_location TEXT,
+		series_id TEXT,
+		volume_number INTEGER,
+		accession_number TEXT,
+		condition TEXT,
+		condition_note TEXT,
+		lending_id TEXT,
 		occurred_at TEXT NOT NULL
 	);
 	CREATE INDEX IF NOT EXISTS idx_book_events_book_id ON book_events(book_id);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_book_events_accession_number ON book_events(accession_number);
+	CREATE INDEX IF NOT EXISTS idx_book_events_lending_id ON book_events(lending_id);
+
+	CREATE TABLE IF NOT EXISTS category_events (
+		event_id TEXT PRIMARY KEY,
+		category_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		parent_id TEXT,
+		code TEXT,
+		name TEXT NOT NULL,
+		occurred_at TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_category_events_category_id ON category_events(category_id);
+	CREATE INDEX IF NOT EXISTS idx_category_events_parent_id ON category_events(parent_id);
+
+	CREATE TABLE IF NOT EXISTS series_events (
+		event_id TEXT PRIMARY KEY,
+		series_id TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		title TEXT NOT NULL,
+		occurred_at TEXT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_series_events_series_id ON series_events(series_id);
+	CREATE INDEX IF NOT EXISTS idx_series_events_title ON series_events(title);
+
+	CREATE TABLE IF NOT EXISTS cover_images (
+		source TEXT NOT NULL,
+		variant TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		data BLOB NOT NULL,
+		etag TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		PRIMARY KEY (source, variant)
+	);
+
+	CREATE TABLE IF NOT EXISTS import_rows (
+		import_id TEXT NOT NULL,
+		row_number INTEGER NOT NULL,
+		code TEXT,
+		title TEXT,
+		status TEXT NOT NULL,
+		book_id TEXT,
+		message TEXT,
+		processed_at TEXT NOT NULL,
+		PRIMARY KEY (import_id, row_number)
+	);
+
+	CREATE TABLE IF NOT EXISTS audit_sessions (
+		audit_id TEXT PRIMARY KEY,
+		started_by TEXT NOT NULL,
+		started_at TEXT NOT NULL,
+		finished_by TEXT,
+		finished_at TEXT
+	);
+
+	CREATE TABLE IF NOT EXISTS audit_books (
+		audit_id TEXT NOT NULL,
+		book_id TEXT NOT NULL,
+		code TEXT,
+		title TEXT,
+		seen_at TEXT,
+		status TEXT,
+		PRIMARY KEY (audit_id, book_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS audit_scans (
+		audit_id TEXT NOT NULL,
+		scan_number INTEGER NOT NULL,
+		code TEXT NOT NULL,
+		book_id TEXT,
+		result TEXT NOT NULL,
+		scanned_by TEXT NOT NULL,
+		scanned_at TEXT NOT NULL,
+		PRIMARY KEY (audit_id, scan_number)
+	);
+
+	CREATE VIRTUAL TABLE IF NOT EXISTS book_search USING fts5(
+		book_id UNINDEXED,
+		title,
+		authors,
+		publisher,
+		tokenize = 'unicode61 remove_diacritics 0'
+	);
+
+	CREATE TABLE IF NOT EXISTS book_search_state (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		last_event_rowid INTEGER NOT NULL
+	);
 
 	CREATE TABLE IF NOT EXISTS lending_events (
 		event_id TEXT PRIMARY KEY,
@@ -109,17 +415,290 @@ func initDB(database *sql.DB) error {
 		borrower_id TEXT NOT NULL,
 		event_type TEXT NOT NULL,
 		due_date TEXT,
+		actor_id TEXT,
+		reason TEXT,
 		occurred_at TEXT NOT NULL
 	);
 	CREATE INDEX IF NOT EXISTS idx_lending_events_lending_id ON lending_events(lending_id);
 	CREATE INDEX IF NOT EXISTS idx_lending_events_book_id ON lending_events(book_id);
 	CREATE INDEX IF NOT EXISTS idx_lending_events_borrower_id ON lending_events(borrower_id);
+
+	CREATE TABLE IF NOT EXISTS loan_stats (
+		lending_id TEXT PRIMARY KEY,
+		book_id TEXT NOT NULL,
+		borrower_id TEXT NOT NULL,
+		borrowed_at TEXT NOT NULL,
+		due_date TEXT NOT NULL,
+		closed_at TEXT,
+		close_type TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_loan_stats_borrowed_at ON loan_stats(borrowed_at);
+	CREATE INDEX IF NOT EXISTS idx_loan_stats_book_id ON loan_stats(book_id);
+
+	CREATE TABLE IF NOT EXISTS loan_stats_state (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		last_event_rowid INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS calendar_feeds (
+		user_id TEXT PRIMARY KEY,
+		token_hash TEXT NOT NULL UNIQUE,
+		created_at TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS notifications (
+		notification_id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		title TEXT NOT NULL,
+		body TEXT NOT NULL,
+		book_id TEXT,
+		dedupe_key TEXT NOT NULL UNIQUE,
+		created_at TEXT NOT NULL,
+		read_at TEXT,
+		deleted_at TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at);
+
+	CREATE TABLE IF NOT EXISTS notification_opt_outs (
+		user_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		PRIMARY KEY (user_id, type)
+	);
+
+	CREATE TABLE IF NOT EXISTS notification_state (
+		stream TEXT PRIMARY KEY,
+		last_rowid INTEGER NOT NULL
+	);
+
+	-- open_lendings has the loans that have not been returned, transferred or
+	-- reported lost. due_date is the one set when the loan was opened.
+	CREATE VIEW IF NOT EXISTS open_lendings AS
+	SELECT
+		le.lending_id,
+		le.book_id,
+		le.borrower_id,
+		le.due_date,
+		le.occurred_at as borrowed_at
+	FROM lending_events le
+	WHERE le.event_type IN ('borrowed', 'lent')
+		AND NOT EXISTS (
+			SELECT 1
+			FROM lending_events closed
+			WHERE closed.lending_id = le.lending_id
+				AND closed.event_type IN ('returned', 'force_returned', 'transferred', 'lost')
+		);
+
+	CREATE VIEW IF NOT EXISTS deleted_books AS
+	SELECT book_id, MAX(occurred_at) as deleted_at
+	FROM book_events
+	WHERE event_type = 'deleted'
+	GROUP BY book_id;
+
+	-- latest_books has the latest details of each book since it was last deleted.
+	-- Events in the same second are ordered as they were recorded.
+	CREATE VIEW IF NOT EXISTS latest_books AS
+	SELECT book_id, code, title, authors, publisher, published_date, thumbnail_url, created_at, updated_at
+	FROM (
+		SELECT
+			e1.book_id,
+			e1.code,
+			e1.title,
+			e1.authors,
+			e1.publisher,
+			e1.published_date,
+			e1.thumbnail_url,
+			(SELECT MIN(e_created.occurred_at)
+			 FROM book_events e_created
+			 WHERE e_created.book_id = e1.book_id
+			   AND e_created.event_type = 'created'
+			   AND e_created.occurred_at > COALESCE(d.deleted_at, '1970-01-01T00:00:00Z')
+			) as created_at,
+			e1.occurred_at as updated_at,
+			ROW_NUMBER() OVER (PARTITION BY e1.book_id ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+		FROM book_events e1
+		LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+		WHERE e1.event_type IN ('created', 'updated')
+			AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+	)
+	WHERE rn = 1;
+
+	-- book_classifications has the latest tags_changed, category_changed and
+	-- shelf_changed event of each book, one row per event type.
+	CREATE VIEW IF NOT EXISTS book_classifications AS
+	SELECT book_id, event_type, tags, category_id, shelf_location
+	FROM (
+		SELECT
+			e1.book_id,
+			e1.event_type,
+			e1.tags,
+			e1.category_id,
+			e1.shelf_location,
+			ROW_NUMBER() OVER (PARTITION BY e1.book_id, e1.event_type ORDER BY e1.occurred_at DESC, e1.rowid DESC) as rn
+		FROM book_events e1
+		LEFT JOIN deleted_books d ON e1.book_id = d.book_id
+		WHERE e1.event_type IN ('tags_changed', 'category_changed', 'shelf_changed')
+			AND (d.deleted_at IS NULL OR e1.occurred_at > d.deleted_at)
+	)
+	WHERE rn = 1;
+
+	-- current_lendings has the open loan of each book, with its latest due date.
+	CREATE VIEW IF NOT EXISTS current_lendings AS
+	SELECT lending_id, book_id, borrower_id, borrower_name, borrowed_at, due_date
+	FROM (
+		SELECT
+			ol.lending_id,
+			ol.book_id,
+			ol.borrower_id,
+			ue.name as borrower_name,
+			ol.borrowed_at,
+			(SELECT due.due_date
+			 FROM lending_events due
+			 WHERE due.lending_id = ol.lending_id
+			   AND due.event_type IN ('borrowed', 'lent', 'due_date_extended')
+			   AND due.due_date IS NOT NULL
+			 ORDER BY due.occurred_at DESC
+			 LIMIT 1
+			) as due_date,
+			ROW_NUMBER() OVER (PARTITION BY ol.book_id ORDER BY ol.borrowed_at DESC) as rn
+		FROM open_lendings ol
+		INNER JOIN user_events ue ON ue.user_id = ol.borrower_id AND ue.event_type = 'created'
+		LEFT JOIN deleted_books d ON ol.book_id = d.book_id
+		WHERE d.deleted_at IS NULL OR ol.borrowed_at > d.deleted_at
+	)
+	WHERE rn = 1;
+
+	-- borrow_counts counts the loans of each book since it was last deleted.
+	CREATE VIEW IF NOT EXISTS borrow_counts AS
+	SELECT le.book_id, COUNT(*) as borrow_count
+	FROM lending_events le
+	LEFT JOIN deleted_books d ON le.book_id = d.book_id
+	WHERE le.event_type IN ('borrowed', 'lent')
+		AND (d.deleted_at IS NULL OR le.occurred_at > d.deleted_at)
+	GROUP BY le.book_id;
+
+	-- book_list_items joins the current state of each book for the book list, the
+	-- search and the facets, which filter it with the same conditions.
+	CREATE VIEW IF NOT EXISTS book_list_items AS
+	SELECT
+		lb.book_id,
+		lb.code,
+		lb.title,
+		lb.authors,
+		lb.publisher,
+		lb.published_date,
+		lb.thumbnail_url,
+		lb.created_at,
+		lb.updated_at,
+		cl.lending_id,
+		cl.borrower_id,
+		cl.borrower_name,
+		cl.borrowed_at,
+		cl.due_date,
+		bt.tags,
+		bcat.category_id,
+		ce.name as category_name,
+		ce.code as category_code,
+		bs.shelf_location,
+		COALESCE(bc.borrow_count, 0) as borrow_count
+	FROM latest_books lb
+	LEFT JOIN current_lendings cl ON lb.book_id = cl.book_id
+	LEFT JOIN book_classifications bt ON lb.book_id = bt.book_id AND bt.event_type = 'tags_changed'
+	LEFT JOIN book_classifications bcat ON lb.book_id = bcat.book_id AND bcat.event_type = 'category_changed'
+	LEFT JOIN book_classifications bs ON lb.book_id = bs.book_id AND bs.event_type = 'shelf_changed'
+	LEFT JOIN category_events ce ON bcat.category_id = ce.category_id AND ce.event_type = 'created'
+	LEFT JOIN borrow_counts bc ON lb.book_id = bc.book_id;
 	`
 	_, err := database.Exec(schema)
 	return err
 }
 
+// openDB opens the database at DATABASE_PATH, or an in-memory database when it is not set.
+func openDB() (*sql.DB, error) {
+	path := os.Getenv("DATABASE_PATH")
+	if path == "" {
+		database, err := sql.Open("sqlite", ":memory:")
+		if err != nil {
+			return nil, err
+		}
+		// Every connection to :memory: opens a separate database.
+		database.SetMaxOpenConns(1)
+		return database, nil
+	}
+	return sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+}
+
+// projections lists the read models that are rebuilt from the event tables after a restore.
+func projections(bookSearchIndex *books.BookSearchIndex, loanStats *stats.LoanStatsProjection, notifications *notification.Dispatcher) []backup.Projection {
+	return []backup.Projection{
+		{Name: "book_search", Rebuild: bookSearchIndex.Rebuild},
+		{Name: "loan_stats", Rebuild: loanStats.Rebuild},
+		{Name: "notifications", Rebuild: notifications.Rebuild},
+	}
+}
+
+// parseLibraryCalendar reads the days the library is closed from the environment.
+func parseLibraryCalendar() (calendar.Calendar, error) {
+	return calendar.Parse(os.Getenv("LIBRARY_CLOSED_WEEKDAYS"), os.Getenv("LIBRARY_JAPANESE_HOLIDAYS"), os.Getenv("LIBRARY_CLOSURES"))
+}
+
+func newBookInfoSources(queries *bookcode.Queries) ([]bookcodeDomain.NamedBookInfoSource, error) {
+	googleBooksFetcher, err := bookcode.NewGoogleBooksFetcher()
+	if err != nil {
+		return nil, err
+	}
+	openBDFetcher, err := bookcode.NewOpenBDFetcher()
+	if err != nil {
+		return nil, err
+	}
+	return []bookcodeDomain.NamedBookInfoSource{
+		{Name: bookcode.SourceHolocron, Lookup: bookcode.DBCacheSource(queries)},
+		{Name: bookcode.SourceGoogleBooks, Lookup: bookcode.ExternalAPISource(googleBooksFetcher.Fetch, bookDomain.BookInfoFromGoogleBooks)},
+		{Name: bookcode.SourceOpenBD, Lookup: bookcode.ExternalAPISource(openBDFetcher.Fetch, bookDomain.BookInfoFromOpenBD)},
+	}, nil
+}
+
+func runMetadataRefresh(ctx context.Context, service *bookcode.MetadataRefreshService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := service.RefreshAll(ctx)
+			if err != nil {
+				log.Printf("metadata refresh failed: %v", err)
+				continue
+			}
+			log.Printf("metadata refresh: checked %d books, updated %d", report.Checked, report.Updated)
+		}
+	}
+}
+
+func runNotificationDispatch(ctx context.Context, dispatcher *notification.Dispatcher, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := dispatcher.Dispatch(ctx); err != nil {
+				log.Printf("notification dispatch failed: %v", err)
+			}
+		}
+	}
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	ctx := context.Background()
 
 	shutdown, err := tracing.Init("holocron")
@@ -132,7 +711,7 @@ func main() {
 		}
 	}()
 
-	database, err := sql.Open("sqlite", ":memory:")
+	database, err := openDB()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -154,39 +733,149 @@ func main() {
 	userQueries := user.New(database)
 	booksQueries := books.New(database)
 	bookcodeQueries := bookcode.New(database)
+	coverQueries := cover.New(database)
 	bookQueries := book.New(database)
 	lendingQueries := lending.New(database)
+	categoryQueries := category.New(database)
+	seriesQueries := series.New(database)
 
-	googleBooksFetcher, err := bookcode.NewGoogleBooksFetcher()
+	bookInfoSources, err := newBookInfoSources(bookcodeQueries)
 	if err != nil {
 		log.Fatal(err)
 	}
-	openBDFetcher, err := bookcode.NewOpenBDFetcher()
-	if err != nil {
+
+	metadataRefreshService := bookcode.NewMetadataRefreshService(bookcodeQueries, bookInfoSources)
+
+	getCoverService := cover.NewGetCoverService(coverQueries, cover.NewCoverFetcher().Fetch)
+
+	// The search index is rebuilt on startup so that changes to the search
+	// normalization also apply to books indexed by an earlier version.
+	bookSearchIndex := books.NewBookSearchIndex(database)
+	if err := bookSearchIndex.Rebuild(ctx); err != nil {
 		log.Fatal(err)
 	}
 
-	bookInfoSources := []bookcodeDomain.BookInfoSource{
-		bookcode.DBCacheSource(bookcodeQueries),
-		bookcode.ExternalAPISource(googleBooksFetcher.Fetch, bookDomain.BookInfoFromGoogleBooks),
-		bookcode.ExternalAPISource(openBDFetcher.Fetch, bookDomain.BookInfoFromOpenBD),
+	libraryCalendar, err := parseLibraryCalendar()
+	if err != nil {
+		log.Fatalf("invalid library calendar: %v", err)
+	}
+	// Loans due in the coming year need the holidays up to then.
+	if err := libraryCalendar.CheckHolidays(time.Now().AddDate(1, 0, 0)); err != nil {
+		log.Printf("library calendar: %v; list the missing holidays in LIBRARY_CLOSURES", err)
 	}
+	loanStats := stats.NewLoanStatsProjection(database, libraryCalendar)
 
-	borrowBookService := lending.NewBorrowBookService(lendingQueries, bookQueries)
-	returnBookService := lending.NewReturnBookService(lendingQueries, bookQueries)
+	roles := auth.NewRoles(os.Getenv("ADMIN_USER_IDS"), os.Getenv("LIBRARIAN_USER_IDS"))
+	// Without CURSOR_SECRET, list cursors are signed with a random key and
+	// stop being valid when the server restarts.
+	cursorCodec := cursor.NewCodec(os.Getenv("CURSOR_SECRET"))
+
+	borrowBookService := lending.NewBorrowBookService(lendingQueries, bookQueries, libraryCalendar)
+	returnBookService := lending.NewReturnBookService(lendingQueries, bookQueries, libraryCalendar)
+	batchLendingService := lending.NewBatchLendingService(database, borrowBookService, returnBookService, func(tx *sql.Tx) lending.BookQueries {
+		return bookQueries.WithTx(tx)
+	})
+	bookConditionService := lending.NewBookConditionService(database, returnBookService, func(tx *sql.Tx) lending.BookEventQueries {
+		return bookQueries.WithTx(tx)
+	})
+	librarianLendingService := lending.NewLibrarianLendingService(database, lendingQueries, bookQueries, userQueries, libraryCalendar)
+	lendingHistoryService := lending.NewLendingHistoryService(lendingQueries, bookQueries, libraryCalendar)
+	auditService := audit.NewAuditService(database)
+	statsService := stats.NewStatsService(stats.New(database), loanStats, libraryCalendar)
+	labelService := label.NewLabelService(label.New(database))
+	feedService := feed.NewFeedService(feed.New(database), lendingQueries, libraryCalendar)
+	// The first dispatch marks the events recorded so far as seen, so that
+	// only events from now on are announced.
+	notificationDispatcher := notification.NewDispatcher(database, notification.DefaultProducers()...)
+	if err := notificationDispatcher.Dispatch(ctx); err != nil {
+		log.Fatal(err)
+	}
+	notificationService := notification.NewNotificationService(notification.New(database), notificationDispatcher)
 
 	srv := &server{
-		createUserHandler:       user.NewCreateUserHandler(userQueries, firebaseAuth),
-		getMyBorrowingHandler:   user.NewGetMyBorrowingHandler(lendingQueries),
-		createBookHandler:       books.NewCreateBookHandler(booksQueries),
-		createBookByCodeHandler: bookcode.NewCreateBookByCodeHandler(bookcodeQueries, bookInfoSources),
-		listBooksHandler:        books.NewListBooksHandler(booksQueries),
-		getBookHandler:          book.NewGetBookHandler(bookQueries),
-		updateBookHandler:       book.NewUpdateBookHandler(bookQueries),
-		deleteBookHandler:       book.NewDeleteBookHandler(bookQueries),
-		borrowBookHandler:       lending.NewBorrowBookHandler(borrowBookService),
-		returnBookHandler:       lending.NewReturnBookHandler(returnBookService, bookQueries),
+		createUserHandler:          user.NewCreateUserHandler(userQueries, firebaseAuth),
+		getMyBorrowingHandler:      user.NewGetMyBorrowingHandler(lendingQueries),
+		issueFeedHandler:           feed.NewIssueFeedHandler(feedService),
+		revokeFeedHandler:          feed.NewRevokeFeedHandler(feedService),
+		dueDatesFeedHandler:        feed.NewDueDatesFeedHandler(feedService),
+		listNotificationsHandler:   notification.NewListNotificationsHandler(notificationService),
+		readNotificationHandler:    notification.NewMarkNotificationReadHandler(notificationService),
+		markAllReadHandler:         notification.NewMarkAllNotificationsReadHandler(notificationService),
+		deleteNotificationHandler:  notification.NewDeleteNotificationHandler(notificationService),
+		getPreferencesHandler:      notification.NewGetNotificationPreferencesHandler(notificationService),
+		updatePreferencesHandler:   notification.NewUpdateNotificationPreferencesHandler(notificationService),
+		createBookHandler:          books.NewCreateBookHandler(database, booksQueries, seriesQueries),
+		createBookByCodeHandler:    bookcode.NewCreateBookByCodeHandler(database, bookcodeQueries, seriesQueries, bookcodeDomain.SourceLookups(bookInfoSources)),
+		getBookInfoHandler:         bookcode.NewGetBookInfoHandler(bookcodeQueries, bookInfoSources),
+		importBooksHandler:         bulkimport.NewImportBooksHandler(bulkimport.NewImportBooksService(database, bookInfoSources)),
+		exportBooksHandler:         export.NewExportBooksHandler(export.NewExportBooksService(export.New(database))),
+		refreshAllMetadataHandler:  bookcode.NewRefreshAllBookMetadataHandler(metadataRefreshService, roles),
+		refreshBookMetadataHandler: bookcode.NewRefreshBookMetadataHandler(metadataRefreshService),
+		uploadCoverHandler:         cover.NewUploadCoverHandler(cover.NewUploadCoverService(coverQueries)),
+		getCoverHandler:            cover.NewGetCoverHandler(getCoverService),
+		listBooksHandler:           books.NewListBooksHandler(booksQueries, bookSearchIndex, cursorCodec, libraryCalendar),
+		getBookHandler:             book.NewGetBookHandler(bookQueries),
+		updateBookHandler:          book.NewUpdateBookHandler(bookQueries, seriesQueries),
+		deleteBookHandler:          book.NewDeleteBookHandler(bookQueries),
+		setBookTagsHandler:         book.NewSetBookTagsHandler(bookQueries),
+		setBookCategoryHandler:     book.NewSetBookCategoryHandler(bookQueries),
+		setBookShelfHandler:        book.NewSetBookShelfLocationHandler(bookQueries),
+		listBookConditionsHandler:  book.NewListBookConditionsHandler(bookQueries),
+		assignAccessionHandler:     book.NewAssignAccessionNumberHandler(database, bookQueries),
+		getLabelHandler:            label.NewGetLabelHandler(labelService),
+		getLabelSheetHandler:       label.NewGetLabelSheetHandler(labelService),
+		listCategoriesHandler:      category.NewListCategoriesHandler(categoryQueries),
+		createCategoryHandler:      category.NewCreateCategoryHandler(categoryQueries),
+		seedCategoriesHandler:      category.NewSeedCategoriesHandler(categoryQueries),
+		getSeriesHandler:           series.NewGetSeriesHandler(seriesQueries),
+		borrowBookHandler:          lending.NewBorrowBookHandler(borrowBookService),
+		returnBookHandler:          lending.NewReturnBookHandler(bookConditionService, bookQueries),
+		scanLendingHandler:         lending.NewScanHandler(lending.NewScanService(lendingQueries, bookQueries, borrowBookService, returnBookService)),
+		batchBorrowHandler:         lending.NewBatchBorrowHandler(batchLendingService),
+		batchReturnHandler:         lending.NewBatchReturnHandler(batchLendingService),
+		lendBookHandler:            lending.NewLendBookHandler(librarianLendingService, roles),
+		forceReturnHandler:         lending.NewForceReturnHandler(librarianLendingService, roles),
+		transferLoanHandler:        lending.NewTransferLoanHandler(librarianLendingService, roles),
+		reportLostHandler:          lending.NewReportLostHandler(bookConditionService, roles),
+		listMyLendingsHandler:      lending.NewListMyLendingsHandler(lendingHistoryService),
+		listBookLendingsHandler:    lending.NewListBookLendingsHandler(lendingHistoryService),
+		startAuditHandler:          audit.NewStartAuditHandler(auditService, roles),
+		getAuditHandler:            audit.NewGetAuditHandler(auditService, roles),
+		scanAuditHandler:           audit.NewScanAuditHandler(auditService, roles),
+		finishAuditHandler:         audit.NewFinishAuditHandler(auditService, roles),
+		statsSummaryHandler:        stats.NewSummaryHandler(statsService, roles),
+		mostBorrowedBooksHandler:   stats.NewMostBorrowedBooksHandler(statsService, roles),
+		activeBorrowersHandler:     stats.NewActiveBorrowersHandler(statsService, roles),
+		booksNotBorrowedHandler:    stats.NewBooksNotBorrowedHandler(statsService, roles),
+		statsTimeSeriesHandler:     stats.NewTimeSeriesHandler(statsService, roles),
+		backupHandler:              backup.NewBackupHandler(backup.NewBackupService(database), roles),
+		restoreHandler:             backup.NewRestoreHandler(backup.NewRestoreService(database, projections(bookSearchIndex, loanStats, notificationDispatcher)...), roles),
+	}
+
+	if interval := os.Getenv("METADATA_REFRESH_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			log.Fatalf("invalid METADATA_REFRESH_INTERVAL: %v", err)
+		}
+		refreshCtx, stopRefresh := context.WithCancel(ctx)
+		defer stopRefresh()
+		go runMetadataRefresh(refreshCtx, metadataRefreshService, d)
+	}
+
+	// Due dates come closer without any event, so the dispatcher also runs
+	// on a schedule. Lending and book events are dispatched on the next tick
+	// or when the user opens the inbox, whichever comes first.
+	notificationInterval := time.Minute
+	if interval := os.Getenv("NOTIFICATION_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			log.Fatalf("invalid NOTIFICATION_INTERVAL: %q", interval)
+		}
+		notificationInterval = d
 	}
+	dispatchCtx, stopDispatch := context.WithCancel(ctx)
+	defer stopDispatch()
+	go runNotificationDispatch(dispatchCtx, notificationDispatcher, notificationInterval)
 
 	allowedOrigin := os.Getenv("ALLOWED_ORIGIN")
 	if allowedOrigin == "" {
@@ -210,7 +899,7 @@ func main() {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodOptions {
 			w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 			w.Header().Set("Access-Control-Max-Age", "3600")
 			w.WriteHeader(http.StatusNoContent)